@@ -10,7 +10,7 @@ let g:bnf#source = extend(get(g:, 'bnf#source', {}), {
             \ 'ready': 1,
             \ 'priority': 9,
             \ 'mark': 'bnf',
-            \ 'scope': ['bnf'],
+            \ 'scope': ['antlr4', 'bnf', 'ebnf', 'peg', 'w3c', 'yacc'],
             \ 'complete_pattern': '<',
             \ 'on_complete': 'bnf#on_complete',
             \ 'on_warmup': 'bnf#on_warmup',
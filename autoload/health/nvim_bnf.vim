@@ -0,0 +1,25 @@
+"   filename: nvim_bnf.vim
+
+" Health check for :checkhealth nvim_bnf in NeoVim before 0.8.
+function! health#nvim_bnf#check() abort
+    call health#report_start('nvim-bnf')
+
+    try
+        let l:report = BNFHealthCheck()
+    catch
+        call health#report_error('plugin is not available: ' . v:exception)
+        return
+    endtry
+
+    for l:item in l:report
+        if l:item.level ==# 'ok'
+            call health#report_ok(l:item.message)
+        elseif l:item.level ==# 'warn'
+            call health#report_warn(l:item.message)
+        elseif l:item.level ==# 'error'
+            call health#report_error(l:item.message)
+        else
+            call health#report_info(l:item.message)
+        endif
+    endfor
+endfunction
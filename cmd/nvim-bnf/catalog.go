@@ -0,0 +1,37 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/daskol/nvim-bnf/pkg/i18n"
+)
+
+// runCatalog prints template of message catalog with all known messages. It
+// is a starting point for translation. Option --merge fills template with
+// translations of existing catalog.
+func runCatalog(args []string) int {
+	var flags = flag.NewFlagSet("catalog", flag.ExitOnError)
+	var merge = flags.String("merge", "", "Fill template with translations")
+	flags.Parse(args)
+
+	var template = i18n.Template()
+	if *merge != "" {
+		var catalog, err = i18n.Load(*merge)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to load catalog: %s\n", err)
+			return 2
+		}
+		for msgid := range template {
+			template[msgid] = catalog[msgid]
+		}
+	}
+
+	var enc = json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	enc.Encode(template)
+	return 0
+}
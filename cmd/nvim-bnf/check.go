@@ -0,0 +1,361 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+	"github.com/daskol/nvim-bnf/pkg/explain"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+	"github.com/daskol/nvim-bnf/pkg/workspace"
+)
+
+// checkRecord is a diagnostic bound to a line of a file. Line numbers are
+// one-based while range is zero-based byte offsets within the line.
+type checkRecord struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+	parser.Diagnostic
+	Note string `json:"note,omitempty"`
+}
+
+// newCheckRecord creates record of diagnostic at one-based line. Column is
+// one-based and it is counted in characters while range of diagnostic is in
+// bytes. Start position of diagnostic is moved to the line as well.
+func newCheckRecord(
+	filename string, line int, text []byte, diag parser.Diagnostic,
+) checkRecord {
+	diag.Start.Line = line - 1
+	diag.Start.Col = parser.Column(text, diag.Range.Begin)
+	return checkRecord{
+		File:       filename,
+		Line:       line,
+		Column:     diag.Start.Col + 1,
+		Diagnostic: diag,
+	}
+}
+
+// runCheck parses grammar files line by line in the same way as the editor
+// does and reports diagnostics. It exits with non-zero status if there is any
+// error. With option --diff grammar files staged in git index are checked.
+// With option --target constructs which could not be expressed in another
+// dialect are reported as warnings. With option --explain every diagnostic is
+// followed by an educational note. Rules which are non-productive,
+// unreachable, or repeated are always reported as warnings and option
+// --strict makes warnings fail the check as well. With option
+// --implicit-alternation repeated definitions of a non-terminal are treated
+// as its alternatives. With option --tabstop columns are counted on screen
+// where tabs are expanded to the given width.
+func runCheck(args []string) int {
+	var flags = flag.NewFlagSet("check", flag.ExitOnError)
+	var format = flags.String("format", "text", "Set output format: text, json")
+	var dialect = flags.String("dialect", "", dialectUsage())
+	var diff = flags.Bool("diff", false, "Check files staged in git index")
+	var target = flags.String("target", "", "Report constructs which "+
+		"could not be expressed in target dialect")
+	var explainMode = flags.Bool("explain", false, "Explain diagnostics")
+	var strict = flags.Bool("strict", false, "Fail on warnings as well")
+	var implicit = flags.Bool("implicit-alternation", false, "Treat "+
+		"repeated definitions of a non-terminal as alternatives")
+	var tabstop = flags.Int("tabstop", 0, "Expand tabs to width in columns")
+	flags.Parse(args)
+
+	var targetDialect, ok = parser.LookupDialect(*target)
+	if *target != "" && !ok {
+		fmt.Fprintf(os.Stderr, "unknown target dialect: %s\n", *target)
+		return 2
+	}
+
+	var filenames, read, err = sourceFiles(flags.Args(), *diff)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to list staged files: %s\n", err)
+		return 2
+	}
+
+	var records = []checkRecord{}
+	for _, filename := range filenames {
+		var notation, ok = parser.LookupDialect(*dialect)
+		if !ok {
+			notation = parser.DetectDialect(filename)
+		}
+
+		if content, err := read(filename); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read %s: %s\n", filename, err)
+			return 2
+		} else {
+			var recs = checkSource(notation, filename, content)
+			recs = append(recs,
+				checkGrammar(notation, filename, content, *implicit)...)
+			if *target != "" {
+				recs = append(recs, checkPortability(notation,
+					targetDialect, filename, content)...)
+			}
+			expandTabs(recs, splitLines(content), *tabstop)
+			records = append(records, recs...)
+		}
+	}
+
+	if *explainMode {
+		for idx := range records {
+			records[idx].Note = explain.Note(records[idx].Code)
+		}
+	}
+
+	if !printRecords(*format, records) {
+		fmt.Fprintf(os.Stderr, "unknown output format: %s\n", *format)
+		return 2
+	}
+
+	for _, rec := range records {
+		if rec.Severity == parser.SeverityError || *strict {
+			return 1
+		}
+	}
+	return 0
+}
+
+// expandTabs recounts columns of records of a file on screen where tabs are
+// expanded to tabstop. Nothing is changed if tabstop is not positive.
+func expandTabs(records []checkRecord, lines [][]byte, tabstop int) {
+	if tabstop <= 0 {
+		return
+	}
+	for idx := range records {
+		var rec = &records[idx]
+		var line = lineAt(lines, rec.Line-1)
+		rec.Start.Col = parser.VirtualColumn(line, rec.Range.Begin, tabstop)
+		rec.Column = rec.Start.Col + 1
+	}
+}
+
+// printRecords writes records to standard output in text or JSON format. It
+// returns false if format is unknown.
+func printRecords(format string, records []checkRecord) bool {
+	switch format {
+	case "json":
+		var enc = json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(records)
+	case "text":
+		for _, rec := range records {
+			fmt.Printf("%s:%d:%d: %s: %s [%s]%s\n", rec.File, rec.Line,
+				rec.Column, rec.Severity, rec.Message, rec.Code,
+				formatAddress(rec.Address))
+			if rec.Note != "" {
+				fmt.Printf("    %s\n", rec.Note)
+			}
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// formatAddress renders address of diagnostic as a suffix of text output.
+func formatAddress(addr *parser.Address) string {
+	switch {
+	case addr == nil:
+		return ""
+	case addr.Alternative < 0:
+		return fmt.Sprintf(" in <%s>", addr.Rule)
+	default:
+		return fmt.Sprintf(" in <%s> alternative %d", addr.Rule,
+			addr.Alternative+1)
+	}
+}
+
+// checkSource reports syntax errors of a source. Sources in single-line
+// dialects are parsed with stream, so that broken lines are reported one by
+// one while the rest of lines are still checked.
+func checkSource(
+	dialect parser.Dialect, filename string, content []byte,
+) []checkRecord {
+	if dialect.Multiline() {
+		return checkDocument(dialect, filename, content)
+	}
+
+	var lines = splitLines(content)
+	var stream = parser.NewDialectStream(dialect, bytes.NewReader(content))
+	var records []checkRecord
+	for {
+		var _, err = stream.Next()
+		if err == io.EOF || stream.Err() != nil {
+			break
+		} else if err != nil {
+			var line = stream.Line()
+			records = append(records, newCheckRecord(filename, line+1,
+				lineAt(lines, line), stream.Diagnostic(err)))
+		}
+	}
+	return records
+}
+
+// checkDocument checks a source in multiline dialect as a whole and converts
+// offsets of diagnostics to line and column numbers.
+func checkDocument(
+	dialect parser.Dialect, filename string, content []byte,
+) []checkRecord {
+	var lines = splitLines(content)
+	var source, index = parser.JoinLines(lines)
+	var ast, err = parser.ParseDialect(dialect, source)
+	var diags []parser.Diagnostic
+	if err != nil {
+		diags = append(diags, parser.NewDiagnostic(err))
+	} else {
+		diags = parser.Diagnostics(ast)
+	}
+
+	var records []checkRecord
+	for _, diag := range diags {
+		var line, col = index.Locate(diag.Range.Begin)
+		diag.Range.End += col - diag.Range.Begin
+		diag.Range.Begin = col
+		records = append(records,
+			newCheckRecord(filename, line+1, lineAt(lines, line), diag))
+	}
+	return records
+}
+
+// lineAt returns line by its zero-based index or nothing if it is out of
+// range.
+func lineAt(lines [][]byte, idx int) []byte {
+	if idx < 0 || idx >= len(lines) {
+		return nil
+	}
+	return lines[idx]
+}
+
+// checkPortability reports constructs of a grammar which have no counterpart
+// in target dialect as well as left recursion if target dialect does not
+// allow it.
+func checkPortability(
+	dialect, target parser.Dialect, filename string, content []byte,
+) []checkRecord {
+	var lines = splitLines(content)
+	var _, index = parser.JoinLines(lines)
+	var grammar = analysis.NewGrammar(dialect, lines)
+
+	var records []checkRecord
+	for _, rule := range grammar.Rules {
+		var diags = rule.Portability(target)
+		diags = append(diags, rule.LeftRecursion(target)...)
+		records = append(records,
+			ruleRecords(dialect, filename, lines, index, rule, diags)...)
+	}
+	return records
+}
+
+// checkGrammar reports rules which never derive a string of terminals, which
+// are unreachable from the start rule, or which are repeated.
+func checkGrammar(
+	dialect parser.Dialect, filename string, content []byte, implicit bool,
+) []checkRecord {
+	var lines = splitLines(content)
+	var _, index = parser.JoinLines(lines)
+	var grammar = newGrammar(dialect, filename, lines)
+	var liveness = grammar.Liveness()
+	var duplicates = grammar.Duplicates(implicit)
+
+	var records []checkRecord
+	for _, rule := range grammar.Rules {
+		var diags = liveness.Diagnostics(rule)
+		diags = append(diags, duplicates[rule]...)
+		records = append(records,
+			ruleRecords(dialect, filename, lines, index, rule, diags)...)
+	}
+	return records
+}
+
+// includes keeps files which are included by checked grammars.
+var includes = workspace.New()
+
+// newGrammar collects rules of a file together with rules of files which it
+// includes. Included files which could not be loaded are reported to stderr.
+func newGrammar(
+	dialect parser.Dialect, filename string, lines [][]byte,
+) *analysis.Grammar {
+	var grammar = analysis.NewGrammar(dialect, lines)
+	var rules, err = includes.Rules(filepath.Dir(filename), lines)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to include file in %s: %s\n",
+			filename, err)
+	}
+	grammar.Included = rules
+	return grammar
+}
+
+// ruleRecords binds diagnostics of a rule to lines. Ranges of diagnostics are
+// relative to the line of a rule unless dialect is multiline.
+func ruleRecords(
+	dialect parser.Dialect, filename string, lines [][]byte,
+	index parser.LineIndex, rule *analysis.Rule, diags []parser.Diagnostic,
+) []checkRecord {
+	var records []checkRecord
+	for _, diag := range diags {
+		var line = rule.Line
+		if dialect.Multiline() {
+			var col int
+			line, col = index.Locate(diag.Range.Begin)
+			diag.Range.End += col - diag.Range.Begin
+			diag.Range.Begin = col
+		}
+		records = append(records,
+			newCheckRecord(filename, line+1, lineAt(lines, line), diag))
+	}
+	return records
+}
+
+// sourceFiles returns files to process and a function to read them. These are
+// either files staged in git index or files from command line. Standard input
+// is read if there are no files at all.
+func sourceFiles(args []string, staged bool) (
+	[]string, func(string) ([]byte, error), error,
+) {
+	if staged {
+		var filenames, err = stagedFiles()
+		return filenames, readStaged, err
+	}
+
+	if len(args) == 0 {
+		args = []string{"-"}
+	}
+	return args, readSource, nil
+}
+
+// readSource reads content of a file or standard input if filename is "-".
+func readSource(filename string) ([]byte, error) {
+	var reader, err = openSource(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return ioutil.ReadAll(reader)
+}
+
+// openSource opens a file or standard input if filename is "-".
+func openSource(filename string) (io.ReadCloser, error) {
+	if filename == "-" {
+		return ioutil.NopCloser(os.Stdin), nil
+	}
+	return os.Open(filename)
+}
+
+// splitLines splits content into lines without trailing new line characters.
+// Line breaks are either LF or CRLF.
+func splitLines(content []byte) [][]byte {
+	var lines = bytes.Split(content, []byte{'\n'})
+	if last := len(lines) - 1; len(lines[last]) == 0 {
+		lines = lines[:last]
+	}
+	for idx, line := range lines {
+		lines[idx] = bytes.TrimSuffix(line, []byte{'\r'})
+	}
+	return lines
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/daskol/nvim-bnf/pkg/codegen"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+// runCodegen generates a recursive descent parser skeleton from a grammar
+// file and prints it to standard output. Grammar is checked first:
+// references to undefined non-terminals, left recursion, and alternatives
+// with common prefixes are reported and nothing is generated unless option
+// --force is set.
+func runCodegen(args []string) int {
+	var flags = flag.NewFlagSet("codegen", flag.ExitOnError)
+	var lang = flags.String("lang", "go", "Set target language: go")
+	var dialect = flags.String("dialect", "", dialectUsage())
+	var pkg = flags.String("package", "parser", "Set name of package")
+	var force = flags.Bool("force", false, "Generate even if grammar is "+
+		"not LL")
+	flags.Parse(args)
+
+	if flags.NArg() > 1 {
+		fmt.Fprintf(os.Stderr, "too many files to generate from\n")
+		return 2
+	}
+
+	var filename = flags.Arg(0)
+	if filename == "" {
+		filename = "-"
+	}
+
+	var notation, ok = parser.LookupDialect(*dialect)
+	if !ok {
+		notation = parser.DetectDialect(filename)
+	}
+
+	var content, err = readSource(filename)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read %s: %s\n", filename, err)
+		return 2
+	}
+
+	var lines = splitLines(content)
+	var _, index = parser.JoinLines(lines)
+	var grammar = newGrammar(notation, filename, lines)
+
+	var problems = codegen.Check(grammar)
+	for _, problem := range problems {
+		var diags = []parser.Diagnostic{problem.Diagnostic}
+		for _, rec := range ruleRecords(notation, filename, lines, index,
+			problem.Rule, diags) {
+			fmt.Fprintf(os.Stderr, "%s:%d:%d: %s: %s [%s]%s\n", rec.File,
+				rec.Line, rec.Column, rec.Severity, rec.Message, rec.Code,
+				formatAddress(rec.Address))
+		}
+	}
+
+	if len(problems) > 0 && !*force {
+		return 1
+	}
+
+	var opts = codegen.Options{Package: *pkg}
+	code, err := codegen.Generate(codegen.Language(*lang), grammar, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to generate parser: %s\n", err)
+		return 2
+	}
+
+	os.Stdout.Write(code)
+	return 0
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+// Command is an entry point of a subcommand. It takes the rest of command line
+// arguments and returns exit status.
+type Command func(args []string) int
+
+var commands = map[string]Command{
+	"catalog":   runCatalog,
+	"check":     runCheck,
+	"cnf":       runCNF,
+	"codegen":   runCodegen,
+	"convert":   runConvert,
+	"coverage":  runCoverage,
+	"doc":       runDoc,
+	"emit":      runEmit,
+	"factor":    runFactor,
+	"fmt":       runFmt,
+	"highlight": runHighlight,
+	"hook":      runHook,
+	"import":    runImport,
+	"lint":      runLint,
+	"parse":     runParse,
+	"simplify":  runSimplify,
+}
+
+func runCommand(name string, args []string) int {
+	if cmd, ok := commands[name]; ok {
+		return cmd(args)
+	}
+
+	fmt.Fprintf(os.Stderr, "unknown command: %s\n", name)
+	return 2
+}
+
+// dialectUsage returns usage of option --dialect which lists supported
+// dialects in lexicographical order.
+func dialectUsage() string {
+	var names []string
+	for _, dialect := range parser.Dialects() {
+		names = append(names, string(dialect))
+	}
+	return "Set grammar notation: " + strings.Join(names, ", ")
+}
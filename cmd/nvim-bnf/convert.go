@@ -0,0 +1,194 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/daskol/nvim-bnf/pkg/convert"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+// runConvert rewrites a grammar file in another dialect and prints it to
+// standard output. Source dialect is derived from file extension unless it is
+// set with option --from. Constructs which could not be expressed in target
+// dialect are reported and nothing is printed unless option --force is set.
+func runConvert(args []string) int {
+	var flags = flag.NewFlagSet("convert", flag.ExitOnError)
+	var from = flags.String("from", "", "Set source notation")
+	var to = flags.String("to", "", "Set target notation")
+	var force = flags.Bool("force", false, "Convert even if it is lossy")
+	flags.Parse(args)
+
+	if flags.NArg() > 1 {
+		fmt.Fprintf(os.Stderr, "too many files to convert\n")
+		return 2
+	}
+
+	var filename = flags.Arg(0)
+	if filename == "" {
+		filename = "-"
+	}
+
+	var source, ok = parser.LookupDialect(*from)
+	if *from == "" {
+		source = parser.DetectDialect(filename)
+	} else if !ok {
+		fmt.Fprintf(os.Stderr, "unknown source dialect: %s\n", *from)
+		return 2
+	}
+
+	var target, known = parser.LookupDialect(*to)
+	if !known {
+		fmt.Fprintf(os.Stderr, "unknown target dialect: %s\n%s\n", *to,
+			dialectUsage())
+		return 2
+	}
+
+	var content, err = readSource(filename)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read %s: %s\n", filename, err)
+		return 2
+	}
+
+	if !reportPortability(source, target, filename, content) && !*force {
+		return 1
+	}
+
+	converted, err := convert.Convert(source, target, content)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to convert %s: %s\n", filename, err)
+		return 1
+	}
+
+	os.Stdout.Write(converted)
+	return 0
+}
+
+// reportPortability prints constructs of a grammar which could not be
+// expressed in target dialect. It reports whether there are none.
+func reportPortability(
+	source, target parser.Dialect, filename string, content []byte,
+) bool {
+	var records = checkPortability(source, target, filename, content)
+	for _, rec := range records {
+		fmt.Fprintf(os.Stderr, "%s:%d:%d: %s: %s [%s]%s\n", rec.File,
+			rec.Line, rec.Range.Begin+1, rec.Severity, rec.Message, rec.Code,
+			formatAddress(rec.Address))
+	}
+	return len(records) == 0
+}
+
+// runEmit translates a grammar file into input of a parser generator (ANTLR4
+// or Bison) and prints it to standard output. Name of ANTLR grammar defaults
+// to name of the file. Like convert, it refuses lossy translation unless
+// option --force is set.
+func runEmit(args []string) int {
+	var flags = flag.NewFlagSet("emit", flag.ExitOnError)
+	var from = flags.String("from", "", "Set source notation")
+	var to = flags.String("to", "", "Set generator: antlr4, yacc")
+	var name = flags.String("name", "", "Set name of ANTLR grammar")
+	var force = flags.Bool("force", false, "Emit even if it is lossy")
+	flags.Parse(args)
+
+	if flags.NArg() > 1 {
+		fmt.Fprintf(os.Stderr, "too many files to emit\n")
+		return 2
+	}
+
+	var filename = flags.Arg(0)
+	if filename == "" {
+		filename = "-"
+	}
+
+	var source, ok = parser.LookupDialect(*from)
+	if *from == "" {
+		source = parser.DetectDialect(filename)
+	} else if !ok {
+		fmt.Fprintf(os.Stderr, "unknown source dialect: %s\n", *from)
+		return 2
+	}
+
+	var target = parser.Dialect(*to)
+	if target != parser.DialectANTLR && target != parser.DialectYacc {
+		fmt.Fprintf(os.Stderr, "unknown generator: %s\n", *to)
+		return 2
+	}
+
+	if *name == "" {
+		*name = strings.TrimSuffix(filepath.Base(filename),
+			filepath.Ext(filename))
+	}
+
+	var content, err = readSource(filename)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read %s: %s\n", filename, err)
+		return 2
+	}
+
+	if !reportPortability(source, target, filename, content) && !*force {
+		return 1
+	}
+
+	emitted, err := convert.Emit(source, target, content, *name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to emit %s: %s\n", filename, err)
+		return 1
+	}
+
+	os.Stdout.Write(emitted)
+	return 0
+}
+
+// runImport translates a grammar of a parser generator (ANTLR4 or Bison) into
+// plain BNF and prints it to standard output. Actions, precedence annotations,
+// and lexer commands are dropped. Lines of the result which are still not
+// valid BNF are reported but the result is printed anyway, so it could be
+// fixed in editor.
+func runImport(args []string) int {
+	var flags = flag.NewFlagSet("import", flag.ExitOnError)
+	var from = flags.String("from", "", "Set generator: antlr4, yacc")
+	flags.Parse(args)
+
+	if flags.NArg() > 1 {
+		fmt.Fprintf(os.Stderr, "too many files to import\n")
+		return 2
+	}
+
+	var filename = flags.Arg(0)
+	if filename == "" {
+		filename = "-"
+	}
+
+	var source = parser.Dialect(*from)
+	if *from == "" {
+		source = parser.DetectDialect(filename)
+	}
+	if source != parser.DialectANTLR && source != parser.DialectYacc {
+		fmt.Fprintf(os.Stderr, "unknown generator: %s\n", source)
+		return 2
+	}
+
+	var content, err = readSource(filename)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read %s: %s\n", filename, err)
+		return 2
+	}
+
+	imported, err := convert.Import(source, content)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to import %s: %s\n", filename, err)
+		return 1
+	}
+
+	var records = checkSource(parser.DialectBNF, "<stdout>", imported)
+	for _, rec := range records {
+		fmt.Fprintf(os.Stderr, "%s:%d:%d: %s: %s [%s]\n", rec.File, rec.Line,
+			rec.Column, rec.Severity, rec.Message, rec.Code)
+	}
+
+	os.Stdout.Write(imported)
+	return 0
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/daskol/nvim-bnf/pkg/language"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+// runCoverage recognizes every file of a corpus with a grammar and reports
+// rules and alternatives which none of files exercises. A single trailing line
+// break of a file is not part of input. Files which do not belong to language
+// of the start rule are reported as well.
+func runCoverage(args []string) int {
+	var flags = flag.NewFlagSet("coverage", flag.ExitOnError)
+	var corpus = flags.String("corpus", "", "Set directory of inputs")
+	var dialect = flags.String("dialect", "", dialectUsage())
+	var start = flags.String("rule", "", "Set rule which derives inputs")
+	flags.Parse(args)
+
+	if *corpus == "" {
+		fmt.Fprintf(os.Stderr, "directory of corpus is not set\n")
+		return 2
+	} else if flags.NArg() > 1 {
+		fmt.Fprintf(os.Stderr, "too many grammars to cover\n")
+		return 2
+	}
+
+	var filename = flags.Arg(0)
+	if filename == "" {
+		filename = "-"
+	}
+
+	var notation, ok = parser.LookupDialect(*dialect)
+	if !ok {
+		notation = parser.DetectDialect(filename)
+	}
+
+	var content, err = readSource(filename)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read %s: %s\n", filename, err)
+		return 2
+	}
+
+	var grammar = newGrammar(notation, filename, splitLines(content))
+	var lang = language.New(notation, grammar)
+	var rule = strings.TrimSuffix(strings.TrimPrefix(*start, "<"), ">")
+	if rule == "" {
+		rule = grammar.StartSymbol()
+	}
+	if !lang.Defines(rule) {
+		fmt.Fprintf(os.Stderr, "there is no rule <%s>\n", rule)
+		return 2
+	}
+
+	inputs, err := corpusFiles(*corpus)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to list corpus: %s\n", err)
+		return 2
+	}
+
+	var status = 0
+	var hits = make(map[parser.Node]int)
+	for _, input := range inputs {
+		var text, err = ioutil.ReadFile(input)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read %s: %s\n", input, err)
+			return 2
+		}
+
+		text = bytes.TrimSuffix(text, []byte{'\n'})
+		text = bytes.TrimSuffix(text, []byte{'\r'})
+		if ok, _ := lang.Cover(rule, string(text), hits); !ok {
+			fmt.Printf("%s: input does not belong to <%s>\n", input, rule)
+			status = 1
+		}
+	}
+
+	var total, covered = 0, 0
+	for _, rule := range grammar.Rules {
+		var alts = parser.Alternatives(rule.Statement.Rule.Right())
+		var missed []int
+		for idx, alt := range alts {
+			if hits[alt] == 0 {
+				missed = append(missed, idx)
+			}
+		}
+		total += len(alts)
+		covered += len(alts) - len(missed)
+
+		switch {
+		case len(missed) == 0:
+			continue
+		case len(missed) == len(alts):
+			fmt.Printf("%s:%d: rule <%s> is never exercised\n", filename,
+				rule.Line+1, rule.Name)
+		default:
+			for _, idx := range missed {
+				fmt.Printf("%s:%d: alternative %d of rule <%s> is never "+
+					"exercised\n", filename, rule.Line+1, idx+1, rule.Name)
+			}
+		}
+		status = 1
+	}
+
+	if total > 0 {
+		fmt.Printf("%d of %d alternatives are covered by %d inputs "+
+			"(%.1f%%)\n", covered, total, len(inputs),
+			100*float64(covered)/float64(total))
+	}
+	return status
+}
+
+// corpusFiles lists regular files of a directory and its subdirectories in
+// lexicographical order. Hidden files and directories are skipped.
+func corpusFiles(dir string) ([]string, error) {
+	var files []string
+	var err = filepath.Walk(dir, func(
+		path string, info os.FileInfo, err error,
+	) error {
+		switch {
+		case err != nil:
+			return err
+		case path != dir && strings.HasPrefix(info.Name(), "."):
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+		case info.Mode().IsRegular():
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
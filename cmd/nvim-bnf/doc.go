@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/daskol/nvim-bnf/pkg/docgen"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+// runDoc renders reference documentation of a grammar file in Markdown or
+// HTML and prints it to standard output. Title of document defaults to name
+// of the file.
+func runDoc(args []string) int {
+	var flags = flag.NewFlagSet("doc", flag.ExitOnError)
+	var format = flags.String("format", "markdown",
+		"Set output format: markdown, html")
+	var dialect = flags.String("dialect", "", dialectUsage())
+	var title = flags.String("title", "", "Set title of document")
+	flags.Parse(args)
+
+	if flags.NArg() > 1 {
+		fmt.Fprintf(os.Stderr, "too many files to document\n")
+		return 2
+	}
+
+	var filename = flags.Arg(0)
+	if filename == "" {
+		filename = "-"
+	}
+
+	var notation, ok = parser.LookupDialect(*dialect)
+	if !ok {
+		notation = parser.DetectDialect(filename)
+	}
+
+	if *title == "" {
+		*title = strings.TrimSuffix(filepath.Base(filename),
+			filepath.Ext(filename))
+		if filename == "-" {
+			*title = "Grammar"
+		}
+	}
+
+	var content, err = readSource(filename)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read %s: %s\n", filename, err)
+		return 2
+	}
+
+	var lines = splitLines(content)
+	doc, err := docgen.Render(docgen.Format(*format), *title, notation, lines)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unknown format: %s\n", *format)
+		return 2
+	}
+
+	os.Stdout.Write(doc)
+	return 0
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/daskol/nvim-bnf/pkg/format"
+	"github.com/daskol/nvim-bnf/pkg/lint"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+// runFmt formats grammar files and prints them to standard output. With
+// option -w files are rewritten in place instead. With option --check files
+// are not changed but names of files which are not formatted are printed and
+// exit status is non-zero. Standard input is formatted to standard output.
+// Byte order mark and line breaks of files are kept. Style of layout is read
+// from .editorconfig and option --style overrides it. With option --fix
+// findings of auto-fixable lint rules are fixed before formatting.
+func runFmt(args []string) int {
+	var flags = flag.NewFlagSet("fmt", flag.ExitOnError)
+	var check = flags.Bool("check", false, "Report unformatted files only")
+	var write = flags.Bool("w", false, "Write result to files in place")
+	var dialect = flags.String("dialect", "", dialectUsage())
+	var diff = flags.Bool("diff", false, "Format files staged in git index")
+	var style = flags.String("style", "", "Layout of rules: compact or aligned")
+	var fix = flags.Bool("fix", false, "Fix findings of auto-fixable rules")
+	flags.Parse(args)
+
+	var named, ok = format.LookupStyle(*style)
+	if *style != "" && !ok {
+		fmt.Fprintf(os.Stderr, "unknown style %s\n", *style)
+		return 2
+	}
+
+	if *diff && !*check {
+		fmt.Fprintf(os.Stderr, "staged files could be checked only\n")
+		return 2
+	}
+
+	var filenames, read, err = sourceFiles(flags.Args(), *diff)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to list staged files: %s\n", err)
+		return 2
+	}
+
+	var status = 0
+	for _, filename := range filenames {
+		var notation, ok = parser.LookupDialect(*dialect)
+		if !ok {
+			notation = parser.DetectDialect(filename)
+		}
+
+		var content, err = read(filename)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read %s: %s\n", filename, err)
+			return 2
+		}
+
+		var source = content
+		if *fix {
+			var cfg, err = lintConfig("", filename)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "failed to load config: %s\n", err)
+				return 2
+			}
+			var fixed, _ = lint.Apply(notation, splitLines(content), cfg)
+			source = bytes.Join(fixed, format.LineBreak(content))
+		}
+
+		var layout = format.LoadStyle(filename)
+		if *style != "" {
+			layout.Align = named.Align
+		}
+
+		var formatted = format.SourceStyle(notation, source, layout)
+		switch {
+		case *check:
+			if !bytes.Equal(content, formatted) {
+				fmt.Println(filename)
+				status = 1
+			}
+		case !*write || filename == "-":
+			os.Stdout.Write(formatted)
+		case !bytes.Equal(content, formatted):
+			if err := ioutil.WriteFile(filename, formatted, 0644); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to write %s: %s\n", filename,
+					err)
+				return 2
+			}
+		}
+	}
+
+	return status
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os/exec"
+	"strings"
+
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+// git runs git command and returns its output. Error message of git is
+// returned as error on failure.
+func git(args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	var cmd = exec.Command("git", args...)
+	cmd.Stderr = &stderr
+
+	var output, err = cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, errors.New(msg)
+		}
+		return nil, err
+	}
+
+	return output, nil
+}
+
+// stagedFiles returns grammar files which are added, copied, modified, or
+// renamed in git index. Paths are relative to the root of repository.
+func stagedFiles() ([]string, error) {
+	var output, err = git("diff", "--cached", "--name-only", "-z",
+		"--diff-filter=ACMR")
+	if err != nil {
+		return nil, err
+	}
+
+	var filenames []string
+	for _, filename := range strings.Split(string(output), "\x00") {
+		if _, ok := parser.LookupExtension(filename); ok {
+			filenames = append(filenames, filename)
+		}
+	}
+	return filenames, nil
+}
+
+// readStaged reads content of a file which is staged in git index.
+func readStaged(filename string) ([]byte, error) {
+	return git("show", ":"+filename)
+}
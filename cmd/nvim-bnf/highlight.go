@@ -0,0 +1,66 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/daskol/nvim-bnf/pkg/highlighting"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+// runHighlight renders a grammar file with syntax highlighting and prints it
+// to standard output. Lexemes are classified in the same way as they are in
+// editor. With option --fragment only a `<pre>` block is printed, so it could
+// be embedded into a page which has its own style sheet.
+func runHighlight(args []string) int {
+	var flags = flag.NewFlagSet("highlight", flag.ExitOnError)
+	var format = flags.String("format", "html", "Set output format: html")
+	var dialect = flags.String("dialect", "", dialectUsage())
+	var fragment = flags.Bool("fragment", false,
+		"Print highlighted block without page and style sheet")
+	var css = flags.Bool("css", false, "Print style sheet only")
+	flags.Parse(args)
+
+	if *format != "html" {
+		fmt.Fprintf(os.Stderr, "unknown format: %s\n", *format)
+		return 2
+	}
+
+	if *css {
+		fmt.Print(highlighting.HTMLStyleSheet())
+		return 0
+	}
+
+	if flags.NArg() > 1 {
+		fmt.Fprintf(os.Stderr, "too many files to highlight\n")
+		return 2
+	}
+
+	var filename = flags.Arg(0)
+	if filename == "" {
+		filename = "-"
+	}
+
+	var notation, ok = parser.LookupDialect(*dialect)
+	if !ok {
+		notation = parser.DetectDialect(filename)
+	}
+
+	var content, err = readSource(filename)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read %s: %s\n", filename, err)
+		return 2
+	}
+
+	var lines = splitLines(content)
+	if *fragment {
+		os.Stdout.Write(highlighting.HighlightHTML(notation, lines))
+	} else {
+		var title = filepath.Base(filename)
+		os.Stdout.Write(highlighting.HighlightHTMLPage(title, notation,
+			lines))
+	}
+	return 0
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// hookMarker identifies pre-commit hooks which are installed by nvim-bnf.
+const hookMarker = "# Installed by nvim-bnf hook install."
+
+// preCommitHook checks and formats grammar files which are staged in git
+// index. It relies on nvim-bnf in PATH as the plugin itself does.
+const preCommitHook = `#!/bin/sh
+` + hookMarker + `
+# Check syntax and formatting of staged grammar files.
+nvim-bnf check --diff || exit 1
+if ! nvim-bnf fmt --check --diff; then
+    echo "nvim-bnf: files above are not formatted; run nvim-bnf fmt -w" >&2
+    exit 1
+fi
+`
+
+// runHook manages git hooks. The only supported action is install which
+// writes pre-commit hook to the current repository.
+func runHook(args []string) int {
+	var flags = flag.NewFlagSet("hook", flag.ExitOnError)
+	var force = flags.Bool("force", false, "Overwrite existing hook")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: nvim-bnf hook [--force] install\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 || flags.Arg(0) != "install" {
+		flags.Usage()
+		return 2
+	}
+
+	if filename, err := installHook(*force); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to install hook: %s\n", err)
+		return 1
+	} else {
+		fmt.Printf("pre-commit hook is installed to %s\n", filename)
+		return 0
+	}
+}
+
+// installHook writes pre-commit hook to hooks directory of repository. Hook
+// which is not installed by nvim-bnf is not overwritten unless forced.
+func installHook(force bool) (string, error) {
+	var output, err = git("rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", err
+	}
+
+	var dir = strings.TrimSpace(string(output))
+	var filename = filepath.Join(dir, "pre-commit")
+
+	if content, err := ioutil.ReadFile(filename); err == nil && !force &&
+		!bytes.Contains(content, []byte(hookMarker)) {
+		return "", fmt.Errorf("%s already exists; use --force", filename)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	var content = []byte(preCommitHook)
+	if err := ioutil.WriteFile(filename, content, 0755); err != nil {
+		return "", err
+	}
+
+	// Permissions of existing file are not changed on write.
+	return filename, os.Chmod(filename, 0755)
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/daskol/nvim-bnf/pkg/lint"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+// runLint checks grammar files against lint rules and reports findings in
+// the same format as check does. Configuration is read from file set with
+// option --config or from .bnflint.toml which is looked up in directory of a
+// grammar and its parents. It exits with non-zero status if there is any
+// finding.
+func runLint(args []string) int {
+	var flags = flag.NewFlagSet("lint", flag.ExitOnError)
+	var format = flags.String("format", "text", "Set output format: text, json")
+	var dialect = flags.String("dialect", "", dialectUsage())
+	var config = flags.String("config", "", "Set path to configuration file")
+	var list = flags.Bool("list", false, "List available lint rules")
+	flags.Parse(args)
+
+	if *list {
+		for _, rule := range lint.Rules() {
+			fmt.Println(rule.Name())
+		}
+		return 0
+	}
+
+	var filenames = flags.Args()
+	if len(filenames) == 0 {
+		filenames = []string{"-"}
+	}
+
+	var records = []checkRecord{}
+	for _, filename := range filenames {
+		var notation, ok = parser.LookupDialect(*dialect)
+		if !ok {
+			notation = parser.DetectDialect(filename)
+		}
+
+		var cfg, err = lintConfig(*config, filename)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to load config: %s\n", err)
+			return 2
+		}
+
+		content, err := readSource(filename)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read %s: %s\n", filename, err)
+			return 2
+		}
+
+		records = append(records,
+			lintSource(notation, filename, content, cfg)...)
+	}
+
+	if !printRecords(*format, records) {
+		fmt.Fprintf(os.Stderr, "unknown output format: %s\n", *format)
+		return 2
+	}
+
+	if len(records) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// lintConfig loads configuration from a file or looks it up next to a
+// grammar. Default configuration is used if there is no configuration file.
+func lintConfig(filename, source string) (*lint.Config, error) {
+	if filename == "" {
+		var dir = "."
+		if source != "-" {
+			dir = filepath.Dir(source)
+		}
+		filename = lint.FindConfig(dir)
+	}
+
+	if filename == "" {
+		return lint.NewConfig(), nil
+	}
+	return lint.LoadConfig(filename)
+}
+
+// lintSource runs lint rules against a grammar and binds findings to lines.
+func lintSource(
+	dialect parser.Dialect, filename string, content []byte,
+	cfg *lint.Config,
+) []checkRecord {
+	var lines = splitLines(content)
+	var _, index = parser.JoinLines(lines)
+	var grammar = newGrammar(dialect, filename, lines)
+
+	var records []checkRecord
+	for _, finding := range lint.LintSource(dialect, lines, grammar, cfg) {
+		var diag, line = finding.Diagnostic, finding.Line()
+		if dialect.Multiline() && finding.Rule != nil {
+			var col int
+			line, col = index.Locate(diag.Range.Begin)
+			diag.Range.End += col - diag.Range.Begin
+			diag.Range.Begin = col
+		}
+		records = append(records,
+			newCheckRecord(filename, line+1, lineAt(lines, line), diag))
+	}
+	return records
+}
@@ -4,15 +4,27 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"log"
 	"os"
 	"path"
+	"runtime"
+	"runtime/pprof"
+	"strings"
 
 	"github.com/daskol/nvim-bnf/pkg/highlighting"
+	"github.com/daskol/nvim-bnf/pkg/i18n"
 	"github.com/daskol/nvim-bnf/pkg/logging"
 )
 
+var flagCatalog string
+var flagCPUProfile string
+var flagFilePatterns string
 var flagGenManifest bool
+var flagLogFormat string
+var flagManifest string
+var flagMemProfile string
+var flagMetricsAddr string
 var flagPluginHost string
 var flagVerbosity string
 var logger = logging.Get()
@@ -23,34 +35,150 @@ func init() {
 		"gen-manifest",
 		false,
 		"Trigger manifest generation instead of running of plugin")
+	flag.StringVar(
+		&flagManifest,
+		"manifest",
+		"",
+		"Print manifest for plugin host and exit")
 	flag.StringVar(
 		&flagPluginHost,
 		"host",
 		path.Base(os.Args[0]),
 		"Set host name for manifest generator")
+	flag.StringVar(
+		&flagFilePatterns,
+		"file-patterns",
+		"",
+		"Set comma-separated extra file patterns for manifest generator")
+	flag.StringVar(
+		&flagCatalog,
+		"catalog",
+		"",
+		"Load translations of messages from catalog file")
 	flag.StringVar(
 		&flagVerbosity,
 		"verbosity",
 		"info",
 		"Set logging level: debug, info, notice, warning, error")
+	flag.StringVar(
+		&flagLogFormat,
+		"log-format",
+		"",
+		"Set format of log records: text or json")
+	flag.StringVar(
+		&flagCPUProfile,
+		"cpuprofile",
+		"",
+		"Write CPU profile to file")
+	flag.StringVar(
+		&flagMemProfile,
+		"memprofile",
+		"",
+		"Write memory profile to file on exit")
+	flag.StringVar(
+		&flagMetricsAddr,
+		"metrics-addr",
+		"",
+		"Serve metrics over HTTP on address (e.g. localhost:6060)")
 	flag.Parse()
 }
 
 func main() {
-	defer func() {
-		if err := logger.Close(); err != nil {
-			log.Printf("error occured during logger closing: %s", err)
+	var status = profile(run)
+	if err := logger.Close(); err != nil {
+		log.Printf("error occured during logger closing: %s", err)
+	}
+	os.Exit(status)
+}
+
+func run() int {
+	logger.SetLevel(flagVerbosity).SetFormat(flagLogFormat)
+
+	if flagCatalog != "" {
+		if catalog, err := i18n.Load(flagCatalog); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to load catalog: %s\n", err)
+			return 2
+		} else {
+			i18n.Use(catalog)
 		}
-	}()
+	}
 
-	logger.SetLevel(flagVerbosity)
+	if flag.NArg() > 0 {
+		return runCommand(flag.Arg(0), flag.Args()[1:])
+	}
 
 	switch {
+	case flagManifest != "":
+		os.Stdout.Write(genManifest(flagManifest))
 	case flagGenManifest:
-		os.Stdout.Write(highlighting.GenManifest(flagPluginHost))
-	case !flagGenManifest:
+		os.Stdout.Write(genManifest(flagPluginHost))
+	default:
+		if flagMetricsAddr != "" {
+			go serveMetrics(flagMetricsAddr)
+		}
 		if err := highlighting.RunPlugin(); err != nil {
 			logger.Errorf("plugin was failed: %s", err)
+			return 1
 		}
 	}
+
+	return 0
+}
+
+// profile runs a function under CPU profiler and writes memory profile after
+// it if profiles are requested with options --cpuprofile and --memprofile.
+func profile(fn func() int) int {
+	if flagCPUProfile != "" {
+		var file, err = os.Create(flagCPUProfile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to create CPU profile: %s\n", err)
+			return 2
+		}
+		defer file.Close()
+
+		if err := pprof.StartCPUProfile(file); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to start CPU profile: %s\n", err)
+			return 2
+		}
+		defer pprof.StopCPUProfile()
+	}
+
+	var status = fn()
+
+	if flagMemProfile != "" {
+		var file, err = os.Create(flagMemProfile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to create memory profile: %s\n",
+				err)
+			return 2
+		}
+		defer file.Close()
+
+		runtime.GC()
+		if err := pprof.WriteHeapProfile(file); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write memory profile: %s\n",
+				err)
+			return 2
+		}
+	}
+
+	return status
+}
+
+// serveMetrics serves metrics of plugin. Failure of listener does not stop
+// plugin.
+func serveMetrics(addr string) {
+	logger.Infof("serve metrics on %s", addr)
+	if err := highlighting.ServeMetrics(addr); err != nil {
+		logger.Errorf("failed to serve metrics: %s", err)
+	}
+}
+
+// genManifest generates manifest of remote plugin for host. Extra file
+// patterns are taken from option --file-patterns.
+func genManifest(host string) []byte {
+	var patterns = highlighting.MergePatterns(
+		highlighting.DefaultFilePatterns,
+		strings.Split(flagFilePatterns, ",")...)
+	return highlighting.GenManifest(host, patterns)
 }
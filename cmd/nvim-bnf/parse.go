@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+// runParse parses a grammar file and prints its parse tree. Grammars in
+// single-line dialects are parsed line by line while multiline ones are parsed
+// as a whole. With option --json the tree is printed in JSON together with
+// positions of nodes, so it could be consumed by external tools. It exits with
+// non-zero status if any rule could not be parsed semantically. With option
+// --tokens tokens of lossless syntax tree are printed instead, i.e. lexemes
+// together with whitespaces, comments, and punctuation. With option --stream
+// rules are printed one by one as soon as they are parsed, so that huge
+// generated grammars are parsed with bounded memory.
+func runParse(args []string) int {
+	var flags = flag.NewFlagSet("parse", flag.ExitOnError)
+	var dialect = flags.String("dialect", "", dialectUsage())
+	var asJSON = flags.Bool("json", false, "Print parse tree in JSON")
+	var tokens = flags.Bool("tokens", false, "Print lossless token stream")
+	var stream = flags.Bool("stream", false, "Print rules one by one")
+	flags.Parse(args)
+
+	if flags.NArg() > 1 {
+		fmt.Fprintf(os.Stderr, "too many files to parse\n")
+		return 2
+	} else if *stream && *tokens {
+		fmt.Fprintf(os.Stderr, "options --stream and --tokens conflict\n")
+		return 2
+	}
+
+	var filename = flags.Arg(0)
+	if filename == "" {
+		filename = "-"
+	}
+
+	var notation, ok = parser.LookupDialect(*dialect)
+	if *dialect == "" {
+		notation = parser.DetectDialect(filename)
+	} else if !ok {
+		fmt.Fprintf(os.Stderr, "unknown dialect: %s\n", *dialect)
+		return 2
+	}
+
+	if *stream {
+		return streamRules(filename, notation, *asJSON)
+	} else if !*tokens && !notation.Multiline() {
+		return parseLines(filename, notation, *asJSON)
+	}
+
+	var content, err = readSource(filename)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read %s: %s\n", filename, err)
+		return 2
+	}
+
+	if *tokens {
+		return printTokens(filename, notation, content)
+	}
+
+	var source, _ = parser.JoinLines(splitLines(content))
+	ast, err := parser.ParseDialect(notation, source)
+	if err != nil {
+		var diag = parser.NewDiagnostic(err)
+		fmt.Fprintf(os.Stderr, "%s:%s: %s\n", filename, diag.Start,
+			diag.Message)
+		return 1
+	}
+
+	if *asJSON {
+		var enc = json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(ast)
+	} else {
+		ast.Dump(os.Stdout)
+	}
+
+	if err := ast.Error(); err != nil {
+		var diag = parser.NewDiagnostic(err)
+		fmt.Fprintf(os.Stderr, "%s:%s: %s\n", filename, diag.Start,
+			diag.Message)
+		return 1
+	}
+	return 0
+}
+
+// parseLines parses a grammar file in single-line dialect line by line and
+// prints its parse tree. Broken lines are reported and skipped, so that they
+// do not hide the rules which follow them.
+func parseLines(filename string, dialect parser.Dialect, asJSON bool) int {
+	if !asJSON {
+		return streamRules(filename, dialect, false)
+	}
+
+	var tree struct {
+		Semantic   bool              `json:"semantic"`
+		Statements []json.RawMessage `json:"statements,omitempty"`
+	}
+
+	var status = eachRule(filename, dialect, func(s *parser.Stream,
+		stmt *parser.Statement) {
+		var data, _ = s.Marshal(stmt)
+		tree.Statements = append(tree.Statements, data)
+	})
+	if status == 2 {
+		return status
+	}
+
+	tree.Semantic = status == 0
+	var enc = json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.Encode(tree)
+	return status
+}
+
+// streamRules parses a grammar file rule by rule and prints every rule as
+// soon as it is parsed. With asJSON rules are printed in JSON one per line.
+// Lines which could not be parsed are reported and skipped, so it exits with
+// non-zero status if there is any.
+func streamRules(filename string, dialect parser.Dialect, asJSON bool) int {
+	var out = bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+
+	return eachRule(filename, dialect, func(s *parser.Stream,
+		stmt *parser.Statement) {
+		if asJSON {
+			var data, _ = s.Marshal(stmt)
+			out.Write(append(data, '\n'))
+		} else {
+			s.Dump(out, stmt)
+		}
+	})
+}
+
+// eachRule parses a grammar file rule by rule and passes every rule to emit.
+// Lines which could not be parsed are reported to standard error. It returns
+// exit status.
+func eachRule(
+	filename string, dialect parser.Dialect,
+	emit func(*parser.Stream, *parser.Statement),
+) int {
+	var reader, err = openSource(filename)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read %s: %s\n", filename, err)
+		return 2
+	}
+	defer reader.Close()
+
+	var stream = parser.NewDialectStream(dialect, reader)
+	var status = 0
+	for {
+		var stmt, err = stream.Next()
+		switch {
+		case err == io.EOF:
+			return status
+		case stream.Err() != nil:
+			fmt.Fprintf(os.Stderr, "failed to read %s: %s\n", filename, err)
+			return 2
+		case err != nil:
+			var diag = parser.NewDiagnostic(err)
+			fmt.Fprintf(os.Stderr, "%s:%s: %s\n", filename, diag.Start,
+				diag.Message)
+			status = 1
+		default:
+			emit(stream, stmt)
+		}
+	}
+}
+
+// printTokens prints tokens of lossless syntax tree of a source one per line
+// with their positions and kinds.
+func printTokens(filename string, dialect parser.Dialect, source []byte) int {
+	var tree = parser.ParseLossless(dialect, source)
+	var index = parser.NewLineIndex(source)
+	for _, token := range tree.Tokens() {
+		var pos = index.Position(source, token.Range.Begin)
+		fmt.Printf("%s\t%s\t%q\n", pos, token.Kind, tree.Text(token))
+	}
+
+	if err := tree.Error(); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", filename, err)
+		return 1
+	}
+	return 0
+}
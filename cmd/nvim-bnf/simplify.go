@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+// runSimplify inlines rules which are referenced once and removes identity
+// rules of a grammar in classic BNF and prints result to standard output.
+func runSimplify(args []string) int {
+	return runTransform("simplify", analysis.Simplify, args)
+}
+
+// runFactor factors out common prefixes of alternatives of a grammar in
+// classic BNF and prints result to standard output.
+func runFactor(args []string) int {
+	return runTransform("factor", analysis.LeftFactor, args)
+}
+
+// runCNF converts a grammar in classic BNF into Chomsky Normal Form and
+// prints result to standard output.
+func runCNF(args []string) int {
+	return runTransform("cnf", analysis.ChomskyNormalForm, args)
+}
+
+// runTransform applies transformation to a grammar file in classic BNF and
+// prints result to standard output. With option --diff only difference
+// between original and transformed grammar is printed. Grammar is not
+// transformed at all if any line could not be parsed since rules of such
+// lines would be lost.
+func runTransform(
+	name string, transform func([][]byte) [][]byte, args []string,
+) int {
+	var flags = flag.NewFlagSet(name, flag.ExitOnError)
+	var diff = flags.Bool("diff", false, "Print difference only")
+	flags.Parse(args)
+
+	if flags.NArg() > 1 {
+		fmt.Fprintf(os.Stderr, "too many files to transform\n")
+		return 2
+	}
+
+	var filename = flags.Arg(0)
+	if filename == "" {
+		filename = "-"
+	}
+
+	if dialect := parser.DetectDialect(filename); dialect != parser.DialectBNF {
+		fmt.Fprintf(os.Stderr, "dialect %s could not be transformed\n",
+			dialect)
+		return 2
+	}
+
+	var content, err = readSource(filename)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read %s: %s\n", filename, err)
+		return 2
+	}
+
+	if !reportErrors(filename, content) {
+		return 1
+	}
+
+	var lines = splitLines(content)
+	var transformed = transform(lines)
+	if !*diff {
+		for _, line := range transformed {
+			fmt.Printf("%s\n", line)
+		}
+		return 0
+	}
+
+	fmt.Printf("--- %s\n+++ %s\n", filename, filename)
+	for _, line := range analysis.DiffLines(toStrings(lines),
+		toStrings(transformed)) {
+		fmt.Println(line)
+	}
+	return 0
+}
+
+// reportErrors reports lines of a grammar in classic BNF which could not be
+// parsed to standard error. It returns true if there is no such line.
+func reportErrors(filename string, content []byte) bool {
+	var stream = parser.NewStream(bytes.NewReader(content))
+	var ok = true
+	for {
+		var _, err = stream.Next()
+		if err == io.EOF {
+			return ok
+		} else if err != nil {
+			var diag = parser.NewDiagnostic(err)
+			fmt.Fprintf(os.Stderr, "%s:%s: %s\n", filename, diag.Start,
+				diag.Message)
+			ok = false
+		}
+	}
+}
+
+func toStrings(lines [][]byte) []string {
+	var strs = make([]string, len(lines))
+	for idx, line := range lines {
+		strs[idx] = string(line)
+	}
+	return strs
+}
@@ -2,3 +2,7 @@
 
 " Here we just set filetype.
 au! BufRead,BufNewFile *.bnf set filetype=bnf
+au! BufRead,BufNewFile *.ebnf set filetype=ebnf
+au! BufRead,BufNewFile *.g4 set filetype=antlr4
+au! BufRead,BufNewFile *.w3c set filetype=w3c
+au! BufRead,BufNewFile *.peg set filetype=peg
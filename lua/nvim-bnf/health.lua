@@ -0,0 +1,28 @@
+-- Health check for :checkhealth nvim-bnf.
+
+local M = {}
+
+function M.check()
+  local health = vim.health
+  health.start('nvim-bnf')
+
+  local ok, report = pcall(vim.fn.BNFHealthCheck)
+  if not ok then
+    health.error('plugin is not available: ' .. tostring(report))
+    return
+  end
+
+  for _, item in ipairs(report) do
+    if item.level == 'ok' then
+      health.ok(item.message)
+    elseif item.level == 'warn' then
+      health.warn(item.message)
+    elseif item.level == 'error' then
+      health.error(item.message)
+    else
+      health.info(item.message)
+    end
+  end
+end
+
+return M
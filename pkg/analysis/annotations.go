@@ -0,0 +1,177 @@
+package analysis
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+// annotation returns text which follows tag `@name` in a comment. For
+// example, it is `use <expr>` for comment `; @deprecated use <expr>`.
+func annotation(comment []byte, name string) (string, bool) {
+	var tag = []byte("@" + name)
+	var idx = bytes.Index(comment, tag)
+	if idx < 0 {
+		return "", false
+	}
+
+	var text = string(comment[idx+len(tag):])
+	if text != "" && text[0] != ' ' && text[0] != '\t' {
+		return "", false
+	}
+
+	text = strings.TrimSpace(text)
+	text = strings.TrimSpace(strings.TrimSuffix(text, "*)"))
+	text = strings.TrimSpace(strings.TrimSuffix(text, "*/"))
+	return text, true
+}
+
+// Deprecation is an annotation like `; @deprecated use <expr>` of a rule.
+// Note is text of annotation and Replacement is a name of rule which should
+// be used instead. Replacement is empty if there is no suggestion.
+type Deprecation struct {
+	Note        string
+	Replacement string
+}
+
+// ParseDeprecation parses text of `@deprecated` annotation. Replacement is
+// suggested with `use <name>` or `use name`.
+func ParseDeprecation(note string) *Deprecation {
+	var deprecation = &Deprecation{Note: note}
+	var fields = strings.Fields(note)
+	if len(fields) >= 2 && strings.EqualFold(fields[0], "use") {
+		var name = strings.TrimRight(fields[1], ".,;")
+		name = strings.TrimSuffix(strings.TrimPrefix(name, "<"), ">")
+		deprecation.Replacement = name
+	}
+	return deprecation
+}
+
+// ParseWeights parses text of `@weight` annotation which lists weights of
+// alternatives of a rule separated with spaces or commas, e.g. `0.8` or
+// `3, 1`. A single weight applies to every alternative. It returns nil if
+// some weight is not a non-negative number.
+func ParseWeights(text string) []float64 {
+	var fields = strings.FieldsFunc(text, func(char rune) bool {
+		return char == ',' || char == ' ' || char == '\t'
+	})
+
+	var weights []float64
+	for _, field := range fields {
+		var weight, err = strconv.ParseFloat(field, 64)
+		if err != nil || weight < 0 {
+			return nil
+		}
+		weights = append(weights, weight)
+	}
+	return weights
+}
+
+// Weight returns weight of an alternative of a rule. It is 1 unless the rule
+// is annotated with `@weight`.
+func (r *Rule) Weight(alt int) float64 {
+	switch {
+	case len(r.Weights) == 1:
+		return r.Weights[0]
+	case alt >= 0 && alt < len(r.Weights):
+		return r.Weights[alt]
+	default:
+		return 1
+	}
+}
+
+// Annotate attaches documentation, `@deprecated`, and `@weight` annotations
+// to rules of a document. An annotation is either in comment lines right
+// above a rule or in comment at the end of the last line of a rule.
+func (g *Grammar) Annotate(dialect parser.Dialect, lines [][]byte) {
+	var source, index = parser.JoinLines(lines)
+	for _, rule := range g.Rules {
+		rule.Statement.Doc = docOf(lines, rule.Line)
+		rule.Deprecation = nil
+		if note, ok := annotationOf(dialect, lines, source, index, rule,
+			"deprecated"); ok {
+			rule.Deprecation = ParseDeprecation(note)
+		}
+		rule.Weights = nil
+		if text, ok := annotationOf(dialect, lines, source, index, rule,
+			"weight"); ok {
+			rule.Weights = ParseWeights(text)
+		}
+	}
+}
+
+// docOf returns documentation of a rule on a line. It is text of consecutive
+// comment lines right above the rule without comment delimiters. Annotations
+// and directives are not part of documentation.
+func docOf(lines [][]byte, line int) string {
+	var first = line
+	for first > 0 && first <= len(lines) && isComment(lines[first-1]) {
+		first--
+	}
+
+	var paragraphs []string
+	for _, line := range lines[first:line] {
+		var text = commentText(line)
+		if strings.HasPrefix(text, "@") || strings.HasPrefix(text, "%") {
+			continue
+		}
+		paragraphs = append(paragraphs, text)
+	}
+	return strings.TrimSpace(strings.Join(paragraphs, "\n"))
+}
+
+// commentText strips delimiters of a comment line.
+func commentText(line []byte) string {
+	var text = string(bytes.TrimSpace(line))
+	for _, prefix := range commentPrefixes {
+		if strings.HasPrefix(text, prefix) {
+			text = strings.TrimLeft(text[len(prefix):], prefix[len(prefix)-1:])
+			break
+		}
+	}
+	text = strings.TrimSuffix(strings.TrimSpace(text), "*)")
+	text = strings.TrimSuffix(strings.TrimSpace(text), "*/")
+	return strings.TrimSpace(text)
+}
+
+// annotationOf returns text of annotation `@name` of a rule.
+func annotationOf(
+	dialect parser.Dialect, lines [][]byte, source []byte,
+	index parser.LineIndex, rule *Rule, name string,
+) (string, bool) {
+	// Text after the right-hand side of a rule on its last line could be a
+	// comment only.
+	var base = 0
+	if !dialect.Multiline() && rule.Line < len(index) {
+		base = index[rule.Line]
+	}
+	var end = base + parser.Span(rule.Statement.Rule.Right()).End
+	if end <= len(source) {
+		var row, _ = index.Locate(end)
+		var tail = source[end : index[row]+len(lines[row])]
+		if note, ok := annotation(tail, name); ok {
+			return note, true
+		}
+	}
+
+	for row := rule.Line - 1; row >= 0 && isComment(lines[row]); row-- {
+		if note, ok := annotation(lines[row], name); ok {
+			return note, true
+		}
+	}
+	return "", false
+}
+
+// Deprecated returns annotations of deprecated rules by their names.
+// Annotations of included rules are not taken into account.
+func (g *Grammar) Deprecated() map[string]*Deprecation {
+	var deprecated = make(map[string]*Deprecation)
+	for _, rule := range g.Rules {
+		if rule.Deprecation != nil {
+			deprecated[rule.Name] = rule.Deprecation
+		}
+	}
+	return deprecated
+}
@@ -0,0 +1,112 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+func TestDeprecated(t *testing.T) {
+	var lines = [][]byte{
+		[]byte(`<s> ::= <a> | <b> | <c>`),
+		[]byte(`; @deprecated use <s>`),
+		[]byte(`; Letter a.`),
+		[]byte(`<a> ::= "a"`),
+		[]byte(`;@deprecated`),
+		[]byte(`<b> ::= "b"`),
+		[]byte(`; @deprecatedness is not an annotation`),
+		[]byte(`<c> ::= "c"`),
+	}
+
+	var grammar = NewGrammar(parser.DialectBNF, lines)
+	var deprecated = grammar.Deprecated()
+	var expected = map[string]*Deprecation{
+		"a": {Note: "use <s>", Replacement: "s"},
+		"b": {},
+	}
+	if !reflect.DeepEqual(deprecated, expected) {
+		t.Errorf("wrong deprecated rules: %v", deprecated)
+	}
+
+	// Annotation could follow a rule on the same line.
+	lines = [][]byte{
+		[]byte(`s = a | b ;`),
+		[]byte(`a = "a" ; (* @deprecated use b *)`),
+		[]byte(`b = "b" ;`),
+	}
+	grammar = NewGrammar(parser.DialectEBNF, lines)
+	deprecated = grammar.Deprecated()
+	expected = map[string]*Deprecation{
+		"a": {Note: "use b", Replacement: "b"},
+	}
+	if !reflect.DeepEqual(deprecated, expected) {
+		t.Errorf("wrong deprecated rules: %v", deprecated)
+	}
+}
+
+func TestWeights(t *testing.T) {
+	var lines = [][]byte{
+		[]byte(`; @weight 0.8`),
+		[]byte(`<s> ::= <a>`),
+		[]byte(`; @weight 3, 1`),
+		[]byte(`<a> ::= "a" | "b" | "c"`),
+		[]byte(`; @weight -1`),
+		[]byte(`<b> ::= "b"`),
+	}
+
+	var grammar = NewGrammar(parser.DialectBNF, lines)
+	var weights = [][]float64{{0.8}, {3, 1}, nil}
+	for idx, rule := range grammar.Rules {
+		if !reflect.DeepEqual(rule.Weights, weights[idx]) {
+			t.Errorf("wrong weights of <%s>: %v", rule.Name, rule.Weights)
+		}
+	}
+
+	var rule = grammar.Rules[1]
+	for alt, weight := range []float64{3, 1, 1} {
+		if actual := rule.Weight(alt); actual != weight {
+			t.Errorf("wrong weight of alternative %d: %g", alt, actual)
+		}
+	}
+	if weight := grammar.Rules[0].Weight(5); weight != 0.8 {
+		t.Errorf("wrong weight of rule: %g", weight)
+	}
+}
+
+func TestDoc(t *testing.T) {
+	var lines = [][]byte{
+		[]byte(`; %start <s>`),
+		[]byte(`<s> ::= <a> | <b>`),
+		[]byte(`; Unrelated comment.`),
+		[]byte(``),
+		[]byte(`;; Letter a.`),
+		[]byte(`; @deprecated use <b>`),
+		[]byte(`; It is the first letter.`),
+		[]byte(`<a> ::= "a"`),
+		[]byte(`<b> ::= "b"`),
+	}
+
+	var grammar = NewGrammar(parser.DialectBNF, lines)
+	var docs = make(map[string]string)
+	for _, rule := range grammar.Rules {
+		docs[rule.Name] = rule.Doc()
+	}
+	var expected = map[string]string{
+		"s": "",
+		"a": "Letter a.\nIt is the first letter.",
+		"b": "",
+	}
+	if !reflect.DeepEqual(docs, expected) {
+		t.Errorf("wrong documentation: %q", docs)
+	}
+
+	lines = [][]byte{
+		[]byte(`(* Sequence of letters. *)`),
+		[]byte(`s = { "a" } ;`),
+	}
+	grammar = NewGrammar(parser.DialectEBNF, lines)
+	if doc := grammar.Rules[0].Doc(); doc != "Sequence of letters." {
+		t.Errorf("wrong documentation: %q", doc)
+	}
+}
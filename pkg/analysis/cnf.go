@@ -0,0 +1,267 @@
+package analysis
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// cnfGrammar is a grammar which is being converted to Chomsky Normal Form.
+// Every rule is a list of alternatives which are lists of rendered terms.
+type cnfGrammar struct {
+	start string
+	names []string
+	rules map[string][][]string
+	taken map[string]bool
+}
+
+// ChomskyNormalForm converts a grammar written in classic BNF into Chomsky
+// Normal Form where every alternative is either a pair of non-terminals or a
+// single terminal. Only start symbol (the first rule unless it is declared
+// with `; %start`) could derive empty string. If start symbol is referenced
+// on the right-hand side, then a new start rule is introduced. Result is
+// rendered one rule per line while comments and layout of source are lost.
+func ChomskyNormalForm(lines [][]byte) [][]byte {
+	var prods = newProductions(lines)
+	var g = newCNFGrammar(prods)
+	if g.start = startOf(prods, lines); g.start == "" {
+		return nil
+	}
+
+	g.separateStart()
+	g.separateTerminals()
+	g.binarize()
+	g.eliminateEpsilons()
+	g.eliminateUnits()
+
+	var result [][]byte
+	for _, name := range g.names {
+		if alts := g.rules[name]; len(alts) > 0 {
+			var prod = production{name: name, alts: alts}
+			result = append(result, []byte(prod.render()))
+		}
+	}
+	return result
+}
+
+func newCNFGrammar(prods []*production) *cnfGrammar {
+	var g = &cnfGrammar{
+		rules: make(map[string][][]string),
+		taken: make(map[string]bool),
+	}
+	for _, prod := range prods {
+		for _, alt := range prod.alts {
+			g.add(prod.name, dropEpsilons(alt))
+		}
+	}
+	return g
+}
+
+// add appends alternatives to a rule. Duplicate alternatives are skipped.
+func (g *cnfGrammar) add(name string, alts ...[]string) {
+	if !g.taken[name] {
+		g.taken[name] = true
+		g.names = append(g.names, name)
+	}
+
+	for _, alt := range alts {
+		if indexOfAlt(g.rules[name], alt) < 0 {
+			g.rules[name] = append(g.rules[name], alt)
+		}
+	}
+}
+
+// fresh returns a name of a new rule which does not clash with other ones.
+func (g *cnfGrammar) fresh(base string) string {
+	var name = base
+	for idx := 1; g.taken[name]; idx++ {
+		name = base + "-" + strconv.Itoa(idx)
+	}
+	return name
+}
+
+// separateStart introduces new start rule if start symbol is referenced by
+// some rule, so the start symbol does not appear on right-hand sides.
+func (g *cnfGrammar) separateStart() {
+	var term = "<" + g.start + ">"
+	for _, name := range g.names {
+		for _, alt := range g.rules[name] {
+			if indexOf(alt, term) >= 0 {
+				var start = g.fresh(g.start + "-start")
+				g.add(start, []string{term})
+				g.start = start
+				g.names = append([]string{start},
+					g.names[:len(g.names)-1]...)
+				return
+			}
+		}
+	}
+}
+
+// separateTerminals replaces terminals in alternatives of two or more terms
+// with non-terminals which derive them.
+func (g *cnfGrammar) separateTerminals() {
+	var symbols = make(map[string]string)
+	for _, name := range g.names {
+		for _, alt := range g.rules[name] {
+			if len(alt) < 2 {
+				continue
+			}
+			for idx, term := range alt {
+				if isNonTerminal(term) || term == epsilon {
+					continue
+				}
+				if _, ok := symbols[term]; !ok {
+					var symbol = g.fresh(terminalName(term))
+					g.add(symbol, []string{term})
+					symbols[term] = "<" + symbol + ">"
+				}
+				alt[idx] = symbols[term]
+			}
+		}
+	}
+}
+
+// binarize splits alternatives of three or more terms into chains of rules
+// with two terms.
+func (g *cnfGrammar) binarize() {
+	// Rules which are added in the loop are split as well.
+	for i := 0; i < len(g.names); i++ {
+		var name = g.names[i]
+		for idx, alt := range g.rules[name] {
+			if len(alt) > 2 {
+				var rest = g.fresh(name + "-rest")
+				g.add(rest, append([]string{}, alt[1:]...))
+				g.rules[name][idx] = []string{alt[0], "<" + rest + ">"}
+			}
+		}
+	}
+}
+
+// eliminateEpsilons removes empty alternatives. Every alternative which
+// contains nullable non-terminals is accompanied with alternatives where they
+// are omitted. Start symbol keeps empty alternative if it is nullable.
+func (g *cnfGrammar) eliminateEpsilons() {
+	var nullable = g.nullable()
+	for _, name := range g.names {
+		var alts [][]string
+		for _, alt := range g.rules[name] {
+			for _, variant := range omitNullable(alt, nullable) {
+				if len(variant) > 0 && indexOfAlt(alts, variant) < 0 {
+					alts = append(alts, variant)
+				}
+			}
+		}
+		if name == g.start && nullable[name] {
+			alts = append(alts, []string{epsilon})
+		}
+		g.rules[name] = alts
+	}
+}
+
+// nullable returns set of non-terminals which derive empty string.
+func (g *cnfGrammar) nullable() map[string]bool {
+	var nullable = make(map[string]bool)
+	for changed := true; changed; {
+		changed = false
+		for _, name := range g.names {
+			if nullable[name] {
+				continue
+			}
+			for _, alt := range g.rules[name] {
+				if isNullable(alt, nullable) {
+					nullable[name] = true
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	return nullable
+}
+
+// eliminateUnits replaces alternatives which consist of a single non-terminal
+// with alternatives of that non-terminal.
+func (g *cnfGrammar) eliminateUnits() {
+	var rules = make(map[string][][]string, len(g.rules))
+	for _, name := range g.names {
+		var alts [][]string
+		var visited = map[string]bool{name: true}
+		var queue = []string{name}
+		for len(queue) > 0 {
+			var head = queue[0]
+			queue = queue[1:]
+			for _, alt := range g.rules[head] {
+				if len(alt) == 1 && isNonTerminal(alt[0]) {
+					var target = strings.Trim(alt[0], "<>")
+					if !visited[target] {
+						visited[target] = true
+						queue = append(queue, target)
+					}
+				} else if indexOfAlt(alts, alt) < 0 {
+					alts = append(alts, alt)
+				}
+			}
+		}
+		rules[name] = alts
+	}
+	g.rules = rules
+}
+
+// omitNullable returns all variants of an alternative where any subset of
+// nullable non-terminals is omitted. Empty literals are always omitted.
+func omitNullable(alt []string, nullable map[string]bool) [][]string {
+	var variants = [][]string{{}}
+	for _, term := range alt {
+		if term == epsilon {
+			continue
+		}
+		var next [][]string
+		for _, variant := range variants {
+			var with = append(append([]string{}, variant...), term)
+			next = append(next, with)
+			if isNonTerminal(term) && nullable[strings.Trim(term, "<>")] {
+				next = append(next, variant)
+			}
+		}
+		variants = next
+	}
+	return variants
+}
+
+func isNullable(alt []string, nullable map[string]bool) bool {
+	for _, term := range alt {
+		switch {
+		case term == epsilon:
+		case isNonTerminal(term) && nullable[strings.Trim(term, "<>")]:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func isNonTerminal(term string) bool {
+	return strings.HasPrefix(term, "<")
+}
+
+// terminalName makes a name of rule which derives a terminal. Name contains
+// terminal itself if it consists of letters and digits.
+func terminalName(term string) string {
+	var content = term[1 : len(term)-1]
+	for _, char := range content {
+		if !unicode.IsLetter(char) && !unicode.IsDigit(char) {
+			return "t"
+		}
+	}
+	return "t-" + content
+}
+
+func indexOfAlt(alts [][]string, alt []string) int {
+	for idx, other := range alts {
+		if strings.Join(other, " ") == strings.Join(alt, " ") {
+			return idx
+		}
+	}
+	return -1
+}
@@ -0,0 +1,47 @@
+package analysis
+
+import "testing"
+
+func TestChomskyNormalForm(t *testing.T) {
+	var cases = []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name: "Long",
+			input: "<s> ::= <a> <b> <c>\n<a> ::= \"a\"\n<b> ::= \"b\"\n" +
+				"<c> ::= \"c\"",
+			expected: "<s> ::= <a> <s-rest>\n<a> ::= \"a\"\n<b> ::= \"b\"\n" +
+				"<c> ::= \"c\"\n<s-rest> ::= <b> <c>",
+		},
+		{
+			name:  "Terminals",
+			input: "<s> ::= \"(\" <s> \")\" | \"x\"",
+			expected: "<s-start> ::= <t> <s-rest> | \"x\"\n" +
+				"<s> ::= <t> <s-rest> | \"x\"\n<t> ::= \"(\"\n" +
+				"<t-1> ::= \")\"\n<s-rest> ::= <s> <t-1>",
+		},
+		{
+			name:  "Epsilon",
+			input: "<s> ::= <a> <b>\n<a> ::= \"a\" | \"\"\n<b> ::= \"b\" | <a>",
+			expected: "<s> ::= <a> <b> | \"\" | \"a\" | \"b\"\n" +
+				"<a> ::= \"a\"\n<b> ::= \"b\" | \"a\"",
+		},
+		{
+			name:  "Group",
+			input: "<s> ::= ( \"a\" | \"b\" ) \"c\"",
+			expected: "<s> ::= <s-group> <t-c>\n" +
+				"<s-group> ::= \"a\" | \"b\"\n<t-c> ::= \"c\"",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var actual = joinLines(ChomskyNormalForm(splitLines(c.input)))
+			if actual != c.expected {
+				t.Errorf("wrong normal form:\n%s", actual)
+			}
+		})
+	}
+}
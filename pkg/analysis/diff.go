@@ -0,0 +1,166 @@
+package analysis
+
+import (
+	"strings"
+)
+
+// Op is a type of edit operation.
+type Op int
+
+const (
+	OpEqual Op = iota
+	OpDelete
+	OpInsert
+)
+
+// Edit is an element of edit script which transforms one sequence into
+// another. Index A refers to the first sequence and index B refers to the
+// second one. Index which is not relevant for operation is -1.
+type Edit struct {
+	Op Op
+	A  int
+	B  int
+}
+
+// Diff computes the shortest edit script between two sequences of strings
+// based on the longest common subsequence.
+func Diff(a, b []string) []Edit {
+	// Table lcs[i][j] holds length of LCS of suffixes a[i:] and b[j:].
+	var lcs = make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var edits []Edit
+	var i, j = 0, 0
+
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			edits = append(edits, Edit{OpEqual, i, j})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			edits = append(edits, Edit{OpDelete, i, -1})
+			i++
+		default:
+			edits = append(edits, Edit{OpInsert, -1, j})
+			j++
+		}
+	}
+
+	for ; i < len(a); i++ {
+		edits = append(edits, Edit{OpDelete, i, -1})
+	}
+
+	for ; j < len(b); j++ {
+		edits = append(edits, Edit{OpInsert, -1, j})
+	}
+
+	return edits
+}
+
+// Changes marks terms of alternatives of a rule which differ from another
+// rule. Its shape is the same as the shape of alternatives of a rule.
+type Changes [][]bool
+
+// CompareRules compares normalized definitions of two rules structurally. At
+// first alternatives are aligned as a whole and then terms of unmatched
+// alternatives are compared pairwise. It returns changed terms of the first
+// and the second rule correspondingly.
+func CompareRules(a, b *Rule) (Changes, Changes) {
+	var altsA, altsB = a.Alternatives(), b.Alternatives()
+	var changesA, changesB = newChanges(altsA), newChanges(altsB)
+	var deleted, inserted []int
+
+	// Compare pending unmatched alternatives term by term. Alternatives
+	// without a counterpart are changed entirely.
+	var flush = func() {
+		for k := 0; k < len(deleted) || k < len(inserted); k++ {
+			switch {
+			case k >= len(inserted):
+				markAll(changesA[deleted[k]])
+			case k >= len(deleted):
+				markAll(changesB[inserted[k]])
+			default:
+				var i, j = deleted[k], inserted[k]
+				for _, edit := range Diff(altsA[i], altsB[j]) {
+					switch edit.Op {
+					case OpDelete:
+						changesA[i][edit.A] = true
+					case OpInsert:
+						changesB[j][edit.B] = true
+					}
+				}
+			}
+		}
+		deleted, inserted = deleted[:0], inserted[:0]
+	}
+
+	var keysA, keysB = joinAlternatives(altsA), joinAlternatives(altsB)
+	for _, edit := range Diff(keysA, keysB) {
+		switch edit.Op {
+		case OpEqual:
+			flush()
+		case OpDelete:
+			deleted = append(deleted, edit.A)
+		case OpInsert:
+			inserted = append(inserted, edit.B)
+		}
+	}
+
+	flush()
+	return changesA, changesB
+}
+
+func joinAlternatives(alts [][]string) []string {
+	var keys = make([]string, len(alts))
+	for idx, alt := range alts {
+		keys[idx] = strings.Join(alt, " ")
+	}
+	return keys
+}
+
+func newChanges(alts [][]string) Changes {
+	var changes = make(Changes, len(alts))
+	for idx, alt := range alts {
+		changes[idx] = make([]bool, len(alt))
+	}
+	return changes
+}
+
+func markAll(flags []bool) {
+	for idx := range flags {
+		flags[idx] = true
+	}
+}
+
+// DiffLines renders difference of two documents line by line. Every line of
+// result is prefixed with space if it is kept, with minus if it is deleted,
+// and with plus if it is inserted.
+func DiffLines(a, b []string) []string {
+	var lines = make([]string, 0, len(a))
+	for _, edit := range Diff(a, b) {
+		switch edit.Op {
+		case OpEqual:
+			lines = append(lines, " "+a[edit.A])
+		case OpDelete:
+			lines = append(lines, "-"+a[edit.A])
+		case OpInsert:
+			lines = append(lines, "+"+b[edit.B])
+		}
+	}
+	return lines
+}
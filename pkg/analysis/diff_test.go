@@ -0,0 +1,44 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+func TestDiff(t *testing.T) {
+	var a = []string{"a", "b", "c", "d"}
+	var b = []string{"a", "c", "e", "d"}
+	var edits = Diff(a, b)
+	var expected = []Edit{
+		{OpEqual, 0, 0},
+		{OpDelete, 1, -1},
+		{OpEqual, 2, 1},
+		{OpInsert, -1, 2},
+		{OpEqual, 3, 3},
+	}
+
+	if !reflect.DeepEqual(edits, expected) {
+		t.Errorf("wrong edit script: %v", edits)
+	}
+}
+
+func TestCompareRules(t *testing.T) {
+	var grammar = NewGrammar(parser.DialectBNF, [][]byte{
+		[]byte(`<a> ::= <x> "y" | <z> | "w"`),
+		[]byte(`<b> ::= <x> "q" | <z>`),
+	})
+
+	var changesA, changesB = CompareRules(grammar.Rules[0], grammar.Rules[1])
+	var expectedA = Changes{{false, true}, {false}, {true}}
+	var expectedB = Changes{{false, true}, {false}}
+
+	if !reflect.DeepEqual(changesA, expectedA) {
+		t.Errorf("wrong changes of the first rule: %v", changesA)
+	}
+
+	if !reflect.DeepEqual(changesB, expectedB) {
+		t.Errorf("wrong changes of the second rule: %v", changesB)
+	}
+}
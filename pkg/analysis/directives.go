@@ -0,0 +1,90 @@
+package analysis
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// directive returns arguments of a directive like `%start syntax` which is
+// written either in a comment (e.g. `; %start <syntax>`) or as is (Yacc).
+func directive(line []byte, name string) (string, bool) {
+	var text = bytes.TrimSpace(line)
+	if isComment(text) {
+		text = bytes.TrimLeft(text, "(*/#;")
+		text = bytes.TrimSpace(text)
+	}
+
+	var prefix = []byte("%" + name)
+	if !bytes.HasPrefix(text, prefix) {
+		return "", false
+	}
+
+	var args = string(text[len(prefix):])
+	if args != "" && args[0] != ' ' && args[0] != '\t' {
+		return "", false
+	}
+
+	args = strings.TrimSpace(args)
+	args = strings.TrimSpace(strings.TrimSuffix(args, "*)"))
+	args = strings.TrimSpace(strings.TrimSuffix(args, "*/"))
+	return args, true
+}
+
+// StartDirective returns start symbol which is declared with a magic comment
+// like `; %start <syntax>` in any dialect or with directive `%start syntax` of
+// Yacc. The first declaration wins. It returns empty string if start symbol
+// is not declared.
+func StartDirective(lines [][]byte) string {
+	for _, line := range lines {
+		var args, ok = directive(line, "start")
+		if !ok {
+			continue
+		}
+
+		var fields = strings.Fields(args)
+		if len(fields) == 0 {
+			continue
+		}
+
+		var name = strings.TrimSuffix(strings.TrimPrefix(fields[0], "<"), ">")
+		if name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// IncludeDirectives returns paths of files which are included with magic
+// comments like `; %include "common.bnf"` in order of their appearance.
+// Quotes around path are optional.
+func IncludeDirectives(lines [][]byte) []string {
+	var paths []string
+	for _, line := range lines {
+		var args, ok = directive(line, "include")
+		if !ok || args == "" {
+			continue
+		}
+
+		if path, err := strconv.Unquote(args); err == nil {
+			args = path
+		}
+		if args != "" {
+			paths = append(paths, args)
+		}
+	}
+	return paths
+}
+
+// StartSymbol returns name of start symbol of a grammar. It is either
+// declared explicitly or it is the first rule.
+func (g *Grammar) StartSymbol() string {
+	switch {
+	case g.Start != "":
+		return g.Start
+	case len(g.Rules) > 0:
+		return g.Rules[0].Name
+	default:
+		return ""
+	}
+}
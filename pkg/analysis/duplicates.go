@@ -0,0 +1,101 @@
+package analysis
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/daskol/nvim-bnf/pkg/i18n"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+// Codes of diagnostics about repeated definitions.
+const (
+	CodeDuplicateRule        = "W008"
+	CodeDuplicateAlternative = "W009"
+)
+
+// Duplicates reports rules which define a non-terminal once again and
+// alternatives which are repeated within a rule. If implicit is set, then
+// repeated definitions are treated as implicit alternation. They are not
+// reported but their alternatives are compared as if they belong to a single
+// rule. Diagnostics are grouped by rules.
+func (g *Grammar) Duplicates(implicit bool) map[*Rule][]parser.Diagnostic {
+	var diags = make(map[*Rule][]parser.Diagnostic)
+	var defs = make(map[string]*Rule)
+	var seen = make(map[string]map[string]bool)
+
+	for _, rule := range g.Rules {
+		var first, defined = defs[rule.Name]
+		if !defined {
+			defs[rule.Name] = rule
+		} else if !implicit {
+			var addr = rule.Address(-1)
+			diags[rule] = append(diags[rule], parser.Diagnostic{
+				Severity: parser.SeverityWarning,
+				Range:    parser.Span(rule.Statement.Rule.Left()),
+				Code:     CodeDuplicateRule,
+				Message: i18n.Sprintf("rule <%s> is already defined on "+
+					"line %d", rule.Name, first.Line+1),
+				Address: &addr,
+			})
+		}
+
+		// Alternatives are compared within a definition unless definitions
+		// are merged.
+		var alts = seen[rule.Name]
+		if alts == nil || !implicit {
+			alts = make(map[string]bool)
+			seen[rule.Name] = alts
+		}
+
+		var rhs = rule.Statement.Rule.Right()
+		for idx, alt := range parser.Alternatives(rhs) {
+			var key = fingerprint(alt)
+			if !alts[key] {
+				alts[key] = true
+				continue
+			}
+			var addr = rule.Address(idx)
+			diags[rule] = append(diags[rule], parser.Diagnostic{
+				Severity: parser.SeverityWarning,
+				Range:    parser.Span(alt),
+				Code:     CodeDuplicateAlternative,
+				Message: i18n.Sprintf("alternative %d of rule <%s> is "+
+					"repeated", idx+1, rule.Name),
+				Address: &addr,
+			})
+		}
+	}
+
+	return diags
+}
+
+// fingerprint renders structure of a subtree, so subtrees which differ in
+// layout or quotes only have the same fingerprint.
+func fingerprint(node parser.Node) string {
+	if node == nil {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(parser.Kind(node))
+	switch node := node.(type) {
+	case *parser.GroupExpression:
+		sb.WriteString(" " + strconv.Itoa(int(node.Kind)))
+	case *parser.RepetitionExpression:
+		sb.WriteString(" " + strconv.Itoa(node.Min) + " " +
+			strconv.Itoa(node.Max))
+	case *parser.PredicateExpression:
+		sb.WriteString(" " + strconv.FormatBool(node.Negative))
+	case *parser.SpecialSequence:
+		sb.WriteString(" " + string(node.Name))
+	default:
+		if term := RenderTerm(node); term != "" {
+			sb.WriteString(" " + term)
+		}
+	}
+
+	sb.WriteString("(" + fingerprint(node.Left()) + ", " +
+		fingerprint(node.Right()) + ")")
+	return sb.String()
+}
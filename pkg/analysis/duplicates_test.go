@@ -0,0 +1,49 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+func TestDuplicates(t *testing.T) {
+	var lines = [][]byte{
+		[]byte(`<s> ::= <a> | "x" | 'x' | <a> "x"`),
+		[]byte(`<a> ::= "a"`),
+		[]byte(`<a> ::= "b" | "a"`),
+	}
+
+	var grammar = NewGrammar(parser.DialectBNF, lines)
+
+	var tests = []struct {
+		implicit bool
+		expected []string
+	}{
+		{false, []string{"s:3:" + CodeDuplicateAlternative,
+			"a:0:" + CodeDuplicateRule}},
+		{true, []string{"s:3:" + CodeDuplicateAlternative,
+			"a:2:" + CodeDuplicateAlternative}},
+	}
+
+	for _, test := range tests {
+		var duplicates = grammar.Duplicates(test.implicit)
+		var codes []string
+		for _, rule := range grammar.Rules {
+			for _, diag := range duplicates[rule] {
+				var addr = diag.Address
+				codes = append(codes, addr.Rule+":"+
+					string(rune('1'+addr.Alternative))+":"+diag.Code)
+			}
+		}
+
+		if len(codes) != len(test.expected) {
+			t.Errorf("wrong diagnostics: %v", codes)
+			continue
+		}
+		for idx := range codes {
+			if codes[idx] != test.expected[idx] {
+				t.Errorf("wrong diagnostic #%d: %s", idx, codes[idx])
+			}
+		}
+	}
+}
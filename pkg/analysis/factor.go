@@ -0,0 +1,116 @@
+package analysis
+
+import (
+	"strconv"
+)
+
+// LeftFactor factors out common prefixes of alternatives of rules of a
+// grammar written in classic BNF. Alternatives like `x y | x z` are replaced
+// with `x <a-tail>` where new rule `<a-tail> ::= y | z` is inserted right
+// after the factored rule. New rules are factored as well, so the result has
+// no alternatives of a rule which start with the same term. Lines of rules
+// which are not changed are kept as is.
+func LeftFactor(lines [][]byte) [][]byte {
+	var prods = newProductions(lines)
+	var names = make(map[string]bool)
+	for _, prod := range prods {
+		names[prod.name] = true
+	}
+
+	// Name of auxiliary rule should not clash with existing names.
+	var newName = func(base string) string {
+		var name = base + "-tail"
+		for idx := 2; names[name]; idx++ {
+			name = base + "-tail-" + strconv.Itoa(idx)
+		}
+		names[name] = true
+		return name
+	}
+
+	for _, prod := range prods {
+		var pending = []*production{prod}
+		for len(pending) > 0 {
+			var head = pending[0]
+			pending = pending[1:]
+			for tail := factorOnce(head, newName); tail != nil; {
+				head.changed = true
+				prod.added = append(prod.added, tail)
+				pending = append(pending, tail)
+				tail = factorOnce(head, newName)
+			}
+		}
+	}
+
+	return rewriteLines(lines, prods)
+}
+
+// factorOnce factors out common prefix of the first group of alternatives of
+// a production which start with the same term. It returns auxiliary rule or
+// nil if there is nothing to factor out.
+func factorOnce(
+	prod *production, newName func(string) string,
+) *production {
+	for i, alt := range prod.alts {
+		if len(alt) == 0 || alt[0] == epsilon {
+			continue
+		}
+
+		// Collect alternatives which start with the same term.
+		var group = []int{i}
+		for j := i + 1; j < len(prod.alts); j++ {
+			if other := prod.alts[j]; len(other) > 0 && other[0] == alt[0] {
+				group = append(group, j)
+			}
+		}
+		if len(group) < 2 {
+			continue
+		}
+
+		var prefix = alt
+		for _, j := range group[1:] {
+			prefix = commonPrefix(prefix, prod.alts[j])
+		}
+
+		var tail = &production{name: newName(prod.name)}
+		for _, j := range group {
+			var rest = append([]string{}, prod.alts[j][len(prefix):]...)
+			if len(rest) == 0 {
+				rest = []string{epsilon}
+			}
+			tail.alts = append(tail.alts, rest)
+		}
+
+		var factored = append(append([]string{}, prefix...),
+			"<"+tail.name+">")
+		var alts = [][]string{}
+		for j, alt := range prod.alts {
+			switch {
+			case j == i:
+				alts = append(alts, factored)
+			case !contains(group, j):
+				alts = append(alts, alt)
+			}
+		}
+
+		prod.alts = alts
+		return tail
+	}
+	return nil
+}
+
+func commonPrefix(a, b []string) []string {
+	var n int
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return a[:n]
+}
+
+func contains(ints []int, value int) bool {
+	for _, v := range ints {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package analysis
+
+import "testing"
+
+func TestLeftFactor(t *testing.T) {
+	var cases = []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:  "Prefix",
+			input: "<a> ::= \"x\" \"y\" | \"x\" \"z\" | \"w\"\n<b> ::= <a>",
+			expected: "<a> ::= \"x\" <a-tail> | \"w\"\n" +
+				"<a-tail> ::= \"y\" | \"z\"\n" +
+				"<b> ::= <a>",
+		},
+		{
+			name:  "Empty",
+			input: "<if> ::= \"if\" <e> | \"if\" <e> \"else\" <e>",
+			expected: "<if> ::= \"if\" <e> <if-tail>\n" +
+				"<if-tail> ::= \"\" | \"else\" <e>",
+		},
+		{
+			name: "Nested",
+			input: "<a> ::= \"x\" \"y\" | \"x\" \"z\" \"u\" | " +
+				"\"x\" \"z\" \"v\"",
+			expected: "<a> ::= \"x\" <a-tail>\n" +
+				"<a-tail> ::= \"y\" | \"z\" <a-tail-tail>\n" +
+				"<a-tail-tail> ::= \"u\" | \"v\"",
+		},
+		{
+			name:  "Clash",
+			input: "<a> ::= \"x\" | \"x\" \"y\"\n<a-tail> ::= \"z\"",
+			expected: "<a> ::= \"x\" <a-tail-2>\n" +
+				"<a-tail-2> ::= \"\" | \"y\"\n" +
+				"<a-tail> ::= \"z\"",
+		},
+		{
+			name:     "Nothing",
+			input:    "<a> ::= \"x\" | \"y\" ; comment",
+			expected: "<a> ::= \"x\" | \"y\" ; comment",
+		},
+		{
+			name:  "Group",
+			input: "<a> ::= \"x\" ( \"y\" | \"z\" ) | \"x\" \"w\"",
+			expected: "<a> ::= \"x\" <a-tail>\n" +
+				"<a-group> ::= \"y\" | \"z\"\n" +
+				"<a-tail> ::= <a-group> | \"w\"",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var actual = joinLines(LeftFactor(splitLines(c.input)))
+			if actual != c.expected {
+				t.Errorf("wrong factorization:\n%s", actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,322 @@
+// Package analysis contains static analyses and transformations of grammars
+// which are built on top of parse trees.
+package analysis
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+// Rule is a production rule of a grammar together with a line of document
+// where it is defined. File is a path to included file which defines the rule
+// or empty string if the rule is defined in the document itself. Deprecation
+// is set if the rule is annotated with `@deprecated` (see Deprecated). Weights
+// are set if the rule is annotated with `@weight` (see ParseWeights).
+type Rule struct {
+	Name        string
+	Line        int
+	File        string
+	Statement   *parser.Statement
+	Deprecation *Deprecation
+	Weights     []float64
+}
+
+// Doc returns documentation of a rule which is written in comment lines right
+// above it. It is empty if the rule is not documented.
+func (r *Rule) Doc() string {
+	return r.Statement.Doc
+}
+
+// References returns names of non-terminals which are used on the right-hand
+// side of a rule in order of their appearance.
+func (r *Rule) References() []string {
+	var names []string
+	walk(r.Statement.Rule.Right(), func(node parser.Node) {
+		if node, ok := node.(*parser.NonTerminal); ok {
+			names = append(names, string(node.Name))
+		}
+	})
+	return names
+}
+
+// Alternatives returns normalized right-hand side of a rule. It is a list of
+// alternatives where each alternative is a list of rendered terms.
+func (r *Rule) Alternatives() [][]string {
+	var alts [][]string
+	for _, alt := range parser.Alternatives(r.Statement.Rule.Right()) {
+		alts = append(alts, renderTerms(alt))
+	}
+	return alts
+}
+
+// AlternativeAt returns index of alternative which contains byte offset. If
+// offset is outside of right-hand side then it returns -1.
+func (r *Rule) AlternativeAt(offset int) int {
+	for idx, alt := range parser.Alternatives(r.Statement.Rule.Right()) {
+		if span := parser.Span(alt); offset >= span.Begin && offset < span.End {
+			return idx
+		}
+	}
+	return -1
+}
+
+// AlternativeRanges returns byte ranges of every alternative of a rule.
+func (r *Rule) AlternativeRanges() []parser.Range {
+	var ranges []parser.Range
+	for _, alt := range parser.Alternatives(r.Statement.Rule.Right()) {
+		ranges = append(ranges, parser.Span(alt))
+	}
+	return ranges
+}
+
+// Address returns address of an alternative of a rule. Index -1 refers to
+// the rule as a whole.
+func (r *Rule) Address(alt int) parser.Address {
+	return parser.Address{Rule: r.Name, Alternative: alt}
+}
+
+// Grammar is an ordered collection of production rules of a document. Start
+// is a start symbol which is declared explicitly (see StartDirective).
+// Included are rules of files which are included to the document (see
+// IncludeDirectives). They resolve references but they are not reported.
+// Dialect is a notation of the document which predefines some symbols.
+type Grammar struct {
+	Rules    []*Rule
+	Start    string
+	Included []*Rule
+	Dialect  parser.Dialect
+}
+
+// NewGrammar parses every line of a document written in some dialect and
+// collects production rules. Lines which could not be parsed semantically are
+// skipped. Documents in multiline dialects are parsed as a whole, so offsets
+// of their rules are relative to the beginning of document.
+func NewGrammar(dialect parser.Dialect, lines [][]byte) *Grammar {
+	if dialect.Multiline() {
+		var grammar = newGrammarFromDocument(dialect, lines)
+		grammar.Start = StartDirective(lines)
+		grammar.Dialect = dialect
+		grammar.Annotate(dialect, lines)
+		return grammar
+	}
+
+	var grammar = Grammar{Start: StartDirective(lines), Dialect: dialect}
+
+	for idx, line := range lines {
+		var ast, err = parser.ParseDialect(dialect, line)
+		if err != nil {
+			continue
+		}
+
+		for _, stmt := range ast.Statements() {
+			if rule := NewRule(stmt, idx); rule != nil {
+				grammar.Rules = append(grammar.Rules, rule)
+			}
+		}
+	}
+
+	grammar.Annotate(dialect, lines)
+	return &grammar
+}
+
+func newGrammarFromDocument(
+	dialect parser.Dialect, lines [][]byte,
+) *Grammar {
+	var grammar Grammar
+	var source, index = parser.JoinLines(lines)
+	var ast, err = parser.ParseDialect(dialect, source)
+	if err != nil {
+		return &grammar
+	}
+
+	for _, stmt := range ast.Statements() {
+		if stmt.Rule == nil {
+			continue
+		}
+
+		var line, _ = index.Locate(parser.Span(stmt.Rule.Left()).Begin)
+		if rule := NewRule(stmt, line); rule != nil {
+			grammar.Rules = append(grammar.Rules, rule)
+		}
+	}
+
+	return &grammar
+}
+
+// NoRules returns number of production rules in a grammar.
+func (g *Grammar) NoRules() int {
+	return len(g.Rules)
+}
+
+// NoReferences counts references to every non-terminal of a grammar. Symbols
+// which are defined but never referenced are presented with zero count.
+func (g *Grammar) NoReferences() map[string]int {
+	var counts = make(map[string]int)
+	for _, rule := range g.Rules {
+		counts[rule.Name] += 0
+		for _, name := range rule.References() {
+			counts[name]++
+		}
+	}
+	return counts
+}
+
+// Definition returns the first definition of a non-terminal in the document
+// or in included files. It returns nil if there is no such rule.
+func (g *Grammar) Definition(name string) *Rule {
+	if rule := g.Lookup(name); rule != nil {
+		return rule
+	}
+	for _, rule := range g.Included {
+		if rule.Name == name {
+			return rule
+		}
+	}
+	return nil
+}
+
+// Defines reports whether a non-terminal is defined in the document or in
+// included files.
+func (g *Grammar) Defines(name string) bool {
+	return g.Definition(name) != nil
+}
+
+// Lookup returns the first definition of a non-terminal or nil if there is no
+// such rule.
+func (g *Grammar) Lookup(name string) *Rule {
+	for _, rule := range g.Rules {
+		if rule.Name == name {
+			return rule
+		}
+	}
+	return nil
+}
+
+// NewRule creates a rule from a statement on a line of document. It returns
+// nil if statement does not define a non-terminal.
+func NewRule(stmt *parser.Statement, line int) *Rule {
+	if stmt == nil || stmt.Rule == nil {
+		return nil
+	}
+
+	if lhs, ok := stmt.Rule.Left().(*parser.NonTerminal); !ok {
+		return nil
+	} else {
+		return &Rule{Name: string(lhs.Name), Line: line, Statement: stmt}
+	}
+}
+
+// RenderTerm returns textual representation of a terminal or non-terminal. It
+// prefers double quotes for terminals unless terminal contains them. Empty
+// alternative is rendered as empty literal regardless of its notation.
+func RenderTerm(node parser.Node) string {
+	switch node := node.(type) {
+	case *parser.NonTerminal:
+		return "<" + string(node.Name) + ">"
+	case *parser.Terminal:
+		if strings.ContainsRune(string(node.Name), '"') {
+			return "'" + string(node.Name) + "'"
+		} else {
+			return `"` + string(node.Name) + `"`
+		}
+	case *parser.CharacterClass:
+		return "[" + string(node.Name) + "]"
+	case *parser.Epsilon:
+		return `""`
+	default:
+		return ""
+	}
+}
+
+// renderTerms renders terms of an alternative. Groups are rendered as single
+// terms together with their brackets. So are repetitions, predicates, and
+// exceptions together with their operators.
+func renderTerms(node parser.Node) []string {
+	switch node := node.(type) {
+	case nil:
+		return nil
+	case *parser.GroupExpression:
+		var alts []string
+		for _, alt := range parser.Alternatives(node.Left()) {
+			alts = append(alts, strings.Join(renderTerms(alt), " "))
+		}
+		var brackets = groupBrackets[node.Kind]
+		return []string{brackets[0] + strings.Join(alts, " | ") + brackets[1]}
+	case *parser.RepetitionExpression:
+		var operand = renderOperand(node.Left())
+		return []string{operand + repetitionOperator(node.Min, node.Max)}
+	case *parser.PredicateExpression:
+		var operator = "&"
+		if node.Negative {
+			operator = "!"
+		}
+		return []string{operator + renderOperand(node.Right())}
+	case *parser.ExceptionExpression:
+		if node.Left() == nil {
+			return []string{"~" + renderOperand(node.Right())}
+		}
+		return []string{renderOperand(node.Left()) + " - " +
+			renderOperand(node.Right())}
+	case *parser.AlternativeExpression, *parser.CompoundExpression:
+		return append(renderTerms(node.Left()), renderTerms(node.Right())...)
+	default:
+		if term := RenderTerm(node); term != "" {
+			return []string{term}
+		}
+		return nil
+	}
+}
+
+// renderOperand renders operand of an operator as a single term. Sequence
+// of terms is parenthesized.
+func renderOperand(node parser.Node) string {
+	var terms = renderTerms(node)
+	if len(terms) == 1 {
+		return terms[0]
+	}
+	return "(" + strings.Join(terms, " ") + ")"
+}
+
+// repetitionOperator renders bounds of repetition in the same way as W3C
+// notation does. Max is negative if there is no upper bound.
+func repetitionOperator(min, max int) string {
+	switch {
+	case min == 0 && max < 0:
+		return "*"
+	case min == 1 && max < 0:
+		return "+"
+	case min == 0 && max == 1:
+		return "?"
+	case max < 0:
+		return "{" + strconv.Itoa(min) + ",}"
+	case min == max:
+		return "{" + strconv.Itoa(min) + "}"
+	default:
+		return "{" + strconv.Itoa(min) + "," + strconv.Itoa(max) + "}"
+	}
+}
+
+// groupBrackets are brackets of kinds of groups.
+var groupBrackets = map[parser.GroupKind][2]string{
+	parser.GroupParen:      {"(", ")"},
+	parser.GroupOptional:   {"[", "]"},
+	parser.GroupRepetition: {"{", "}"},
+}
+
+// walk traverses subtree in pre-order and calls visitor on every node.
+func walk(node parser.Node, visit func(parser.Node)) {
+	switch node.(type) {
+	case nil:
+		return
+	case *parser.AlternativeExpression, *parser.CompoundExpression,
+		*parser.ExceptionExpression, *parser.GroupExpression,
+		*parser.PredicateExpression, *parser.RepetitionExpression:
+		visit(node)
+		walk(node.Left(), visit)
+		walk(node.Right(), visit)
+	default:
+		visit(node)
+	}
+}
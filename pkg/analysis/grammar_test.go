@@ -0,0 +1,121 @@
+package analysis
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+func TestGrammar(t *testing.T) {
+	var lines = [][]byte{
+		[]byte(`<syntax> ::= <rule> | <rule> <syntax>`),
+		[]byte(``),
+		[]byte(`<rule>   ::= <name> "::=" <expr>`),
+		[]byte(`<broken> ::= "`),
+		[]byte(`<name>   ::= "a" | "b"`),
+	}
+
+	var grammar = NewGrammar(parser.DialectBNF, lines)
+
+	if norules := grammar.NoRules(); norules != 3 {
+		t.Fatalf("wrong number of rules: %d", norules)
+	}
+
+	if line := grammar.Rules[2].Line; line != 4 {
+		t.Errorf("wrong line of the last rule: %d", line)
+	}
+
+	var refs = grammar.NoReferences()
+	var expected = map[string]int{
+		"syntax": 1, "rule": 2, "name": 1, "expr": 1,
+	}
+
+	if len(refs) != len(expected) {
+		t.Errorf("wrong number of symbols: %v", refs)
+	}
+
+	for name, count := range expected {
+		if refs[name] != count {
+			t.Errorf("wrong reference count of %s: %d", name, refs[name])
+		}
+	}
+
+	var rule = grammar.Rules[0]
+	if idx := rule.AlternativeAt(16); idx != 0 {
+		t.Errorf("wrong alternative at offset 16: %d", idx)
+	}
+	if idx := rule.AlternativeAt(22); idx != 1 {
+		t.Errorf("wrong alternative at offset 22: %d", idx)
+	}
+	if idx := rule.AlternativeAt(3); idx != -1 {
+		t.Errorf("offset 3 is outside of rhs: %d", idx)
+	}
+
+	var metrics = grammar.Metrics()
+	var expectedMetrics = Metrics{
+		Rules: 3, Alternatives: 5, Undefined: 1, Unreferenced: 0,
+	}
+	if metrics != expectedMetrics {
+		t.Errorf("wrong metrics: %+v", metrics)
+	}
+
+	if names := grammar.Undefined(); len(names) != 1 || names[0] != "expr" {
+		t.Errorf("wrong undefined symbols: %v", names)
+	}
+}
+
+func TestAlternativesGroup(t *testing.T) {
+	var lines = [][]byte{[]byte(`<a> ::= ("x" | <b> "y") <c> | "z"`)}
+	var grammar = NewGrammar(parser.DialectBNF, lines)
+	if grammar.NoRules() != 1 {
+		t.Fatalf("wrong number of rules: %d", grammar.NoRules())
+	}
+
+	var alts = grammar.Rules[0].Alternatives()
+	if len(alts) != 2 || len(alts[0]) != 2 {
+		t.Fatalf("wrong alternatives: %q", alts)
+	}
+	if alts[0][0] != `("x" | <b> "y")` {
+		t.Errorf("wrong rendering of group: %s", alts[0][0])
+	}
+}
+
+func TestAlternativesOperators(t *testing.T) {
+	var tests = []struct {
+		dialect  parser.Dialect
+		source   string
+		expected []string
+	}{
+		{
+			parser.DialectW3C, `list ::= item* "end"`,
+			[]string{`<item>*`, `"end"`},
+		},
+		{parser.DialectW3C, `list ::= item+`, []string{`<item>+`}},
+		{parser.DialectW3C, `list ::= (item ",")?`, []string{`(<item> ",")?`}},
+		{parser.DialectW3C, `list ::= item{2,3}`, []string{`<item>{2,3}`}},
+		{parser.DialectW3C, `list ::= item{2,}`, []string{`<item>{2,}`}},
+		{parser.DialectW3C, `char ::= a - b`, []string{`<a> - <b>`}},
+		{parser.DialectPEG, `a <- !b c`, []string{`!<b>`, `<c>`}},
+		{parser.DialectPEG, `a <- &b c`, []string{`&<b>`, `<c>`}},
+		{
+			parser.DialectANTLR, "grammar g;\na : ~B c ;",
+			[]string{`~<B>`, `<c>`},
+		},
+	}
+
+	for _, test := range tests {
+		var lines = bytes.Split([]byte(test.source), []byte{'\n'})
+		var grammar = NewGrammar(test.dialect, lines)
+		if grammar.NoRules() != 1 {
+			t.Errorf("%s: wrong number of rules: %d", test.source,
+				grammar.NoRules())
+			continue
+		}
+		var alts = grammar.Rules[0].Alternatives()
+		if len(alts) != 1 || !reflect.DeepEqual(alts[0], test.expected) {
+			t.Errorf("%s: wrong alternatives: %q", test.source, alts)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package analysis
+
+import (
+	"github.com/daskol/nvim-bnf/pkg/i18n"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+// Codes of diagnostics about rules which are useless in a grammar.
+const (
+	CodeNonProductive = "W006"
+	CodeUnreachable   = "W007"
+)
+
+// Liveness describes which non-terminals of a grammar are nullable (derive
+// empty string), productive (derive some string of terminals), and reachable
+// from the start symbol.
+type Liveness struct {
+	Nullable   map[string]bool
+	Productive map[string]bool
+	Reachable  map[string]bool
+
+	dialect parser.Dialect
+}
+
+// Liveness computes nullable, productive, and reachable non-terminals of a
+// grammar with fixed-point iterations. Rules of included files are taken
+// into account as well. Symbols which are predefined by dialect of grammar
+// are productive terminals.
+func (g *Grammar) Liveness() *Liveness {
+	var l = &Liveness{
+		Nullable:   make(map[string]bool),
+		Productive: make(map[string]bool),
+		Reachable:  make(map[string]bool),
+		dialect:    g.Dialect,
+	}
+
+	var rules = append(append([]*Rule{}, g.Rules...), g.Included...)
+	for changed := true; changed; {
+		changed = false
+		for _, rule := range rules {
+			var rhs = rule.Statement.Rule.Right()
+			if !l.Nullable[rule.Name] && l.nullable(rhs) {
+				l.Nullable[rule.Name] = true
+				changed = true
+			}
+			if !l.Productive[rule.Name] && l.productive(rhs) {
+				l.Productive[rule.Name] = true
+				changed = true
+			}
+		}
+	}
+
+	var start = g.StartSymbol()
+	if start == "" {
+		return l
+	}
+
+	// Non-terminal could be defined with several rules.
+	var defs = make(map[string][]*Rule)
+	for _, rule := range rules {
+		defs[rule.Name] = append(defs[rule.Name], rule)
+	}
+
+	var queue = []string{start}
+	l.Reachable[queue[0]] = true
+	for len(queue) > 0 {
+		var name = queue[0]
+		queue = queue[1:]
+		for _, rule := range defs[name] {
+			for _, ref := range rule.References() {
+				if !l.Reachable[ref] {
+					l.Reachable[ref] = true
+					queue = append(queue, ref)
+				}
+			}
+		}
+	}
+
+	return l
+}
+
+// Diagnostics reports a rule if it is non-productive or unreachable from the
+// start rule. Range of diagnostics is the left-hand side of the rule.
+func (l *Liveness) Diagnostics(rule *Rule) []parser.Diagnostic {
+	var diags []parser.Diagnostic
+	var addr = rule.Address(-1)
+	var span = parser.Span(rule.Statement.Rule.Left())
+
+	if !l.Productive[rule.Name] {
+		diags = append(diags, parser.Diagnostic{
+			Severity: parser.SeverityWarning,
+			Range:    span,
+			Code:     CodeNonProductive,
+			Message: i18n.Sprintf("rule <%s> never derives a string of "+
+				"terminals", rule.Name),
+			Address: &addr,
+		})
+	}
+
+	if !l.Reachable[rule.Name] {
+		diags = append(diags, parser.Diagnostic{
+			Severity: parser.SeverityWarning,
+			Range:    span,
+			Code:     CodeUnreachable,
+			Message: i18n.Sprintf("rule <%s> is unreachable from start rule",
+				rule.Name),
+			Address: &addr,
+		})
+	}
+
+	return diags
+}
+
+// nullable reports whether a subtree derives empty string.
+func (l *Liveness) nullable(node parser.Node) bool {
+	switch node := node.(type) {
+	case *parser.Epsilon, *parser.PredicateExpression:
+		return true
+	case *parser.Terminal:
+		return len(node.Name) == 0
+	case *parser.NonTerminal:
+		return l.Nullable[string(node.Name)]
+	case *parser.AlternativeExpression:
+		return l.nullable(node.Left()) || l.nullable(node.Right())
+	case *parser.CompoundExpression:
+		return l.nullable(node.Left()) && l.nullable(node.Right())
+	case *parser.GroupExpression:
+		return node.Kind != parser.GroupParen || l.nullable(node.Left())
+	case *parser.RepetitionExpression:
+		return node.Min == 0 || l.nullable(node.Left())
+	case *parser.ExceptionExpression:
+		return node.Left() != nil && l.nullable(node.Left())
+	default:
+		return false
+	}
+}
+
+// productive reports whether a subtree derives some string of terminals.
+func (l *Liveness) productive(node parser.Node) bool {
+	switch node := node.(type) {
+	case nil:
+		return false
+	case *parser.NonTerminal:
+		var name = string(node.Name)
+		return l.Productive[name] || l.dialect.Builtin(name)
+	case *parser.AlternativeExpression:
+		return l.productive(node.Left()) || l.productive(node.Right())
+	case *parser.CompoundExpression:
+		return l.productive(node.Left()) && l.productive(node.Right())
+	case *parser.GroupExpression:
+		return node.Kind != parser.GroupParen || l.productive(node.Left())
+	case *parser.RepetitionExpression:
+		return node.Min == 0 || l.productive(node.Left())
+	case *parser.ExceptionExpression:
+		return node.Left() == nil || l.productive(node.Left())
+	default:
+		// Terminals, character classes, wildcards, special sequences,
+		// empty alternatives, and predicates are always productive.
+		return true
+	}
+}
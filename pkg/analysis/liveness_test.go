@@ -0,0 +1,184 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+func TestLiveness(t *testing.T) {
+	var lines = [][]byte{
+		[]byte(`<s> ::= <a> | <b> "x"`),
+		[]byte(`<a> ::= "" | "a" <a>`),
+		[]byte(`<b> ::= "b" <b>`),
+		[]byte(`<c> ::= "c"`),
+	}
+
+	var grammar = NewGrammar(parser.DialectBNF, lines)
+	var liveness = grammar.Liveness()
+
+	var tests = []struct {
+		name       string
+		nullable   bool
+		productive bool
+		reachable  bool
+	}{
+		{"s", true, true, true},
+		{"a", true, true, true},
+		{"b", false, false, true},
+		{"c", false, true, false},
+	}
+
+	for _, test := range tests {
+		if liveness.Nullable[test.name] != test.nullable {
+			t.Errorf("wrong nullability of <%s>", test.name)
+		}
+		if liveness.Productive[test.name] != test.productive {
+			t.Errorf("wrong productivity of <%s>", test.name)
+		}
+		if liveness.Reachable[test.name] != test.reachable {
+			t.Errorf("wrong reachability of <%s>", test.name)
+		}
+	}
+
+	var codes []string
+	for _, rule := range grammar.Rules {
+		for _, diag := range liveness.Diagnostics(rule) {
+			if diag.Severity != parser.SeverityWarning {
+				t.Errorf("wrong severity of diagnostic: %s", diag.Severity)
+			}
+			if diag.Range.Begin != 0 || diag.Range.End != 3 {
+				t.Errorf("wrong range of diagnostic: %v", diag.Range)
+			}
+			codes = append(codes, diag.Address.Rule+":"+diag.Code)
+		}
+	}
+
+	var expected = []string{"b:" + CodeNonProductive, "c:" + CodeUnreachable}
+	if len(codes) != len(expected) {
+		t.Fatalf("wrong diagnostics: %v", codes)
+	}
+	for idx := range codes {
+		if codes[idx] != expected[idx] {
+			t.Errorf("wrong diagnostic #%d: %s", idx, codes[idx])
+		}
+	}
+}
+
+func TestLivenessStartDirective(t *testing.T) {
+	var lines = [][]byte{
+		[]byte(`; %start <b>`),
+		[]byte(`<a> ::= <b> "a"`),
+		[]byte(`<b> ::= "b"`),
+	}
+
+	var grammar = NewGrammar(parser.DialectBNF, lines)
+	if start := grammar.StartSymbol(); start != "b" {
+		t.Fatalf("wrong start symbol: %q", start)
+	}
+
+	var liveness = grammar.Liveness()
+	if liveness.Reachable["a"] || !liveness.Reachable["b"] {
+		t.Errorf("wrong reachability: %v", liveness.Reachable)
+	}
+}
+
+func TestStartDirective(t *testing.T) {
+	var tests = []struct {
+		line  string
+		start string
+	}{
+		{`; %start <syntax>`, "syntax"},
+		{`;%start syntax`, "syntax"},
+		{`(* %start syntax *)`, "syntax"},
+		{`(* %start syntax*)`, "syntax"},
+		{`// %start expr`, "expr"},
+		{`%start expr`, "expr"},
+		{`; %start`, ""},
+		{`; start <syntax>`, ""},
+		{`<a> ::= "%start"`, ""},
+	}
+
+	for _, test := range tests {
+		var lines = [][]byte{[]byte(test.line)}
+		if start := StartDirective(lines); start != test.start {
+			t.Errorf("%s: wrong start symbol: %q", test.line, start)
+		}
+	}
+}
+
+func TestLivenessIncluded(t *testing.T) {
+	var common = NewGrammar(parser.DialectBNF, [][]byte{
+		[]byte(`<digit> ::= "0" | "1"`),
+	})
+
+	var grammar = NewGrammar(parser.DialectBNF, [][]byte{
+		[]byte(`; %include "common.bnf"`),
+		[]byte(`<number> ::= <digit> | <digit> <number>`),
+	})
+	grammar.Included = common.Rules
+
+	if !grammar.Liveness().Productive["number"] {
+		t.Errorf("rule which references included rule is not productive")
+	}
+	if names := grammar.Undefined(); len(names) != 0 {
+		t.Errorf("included rules are undefined: %v", names)
+	}
+}
+
+func TestLivenessBuiltins(t *testing.T) {
+	var tests = []struct {
+		dialect parser.Dialect
+		lines   []string
+		name    string
+	}{
+		{parser.DialectANTLR, []string{
+			`grammar calc;`,
+			`prog : ID EOF ;`,
+			`ID : [a-z]+ ;`,
+		}, "prog"},
+		{parser.DialectYacc, []string{
+			`stmt : 'x' ';'`,
+			`     | error ';'`,
+			`     ;`,
+		}, "stmt"},
+	}
+
+	for _, test := range tests {
+		var lines [][]byte
+		for _, line := range test.lines {
+			lines = append(lines, []byte(line))
+		}
+
+		var grammar = NewGrammar(test.dialect, lines)
+		var liveness = grammar.Liveness()
+		if !liveness.Productive[test.name] {
+			t.Errorf("%s: rule which references builtin is not productive",
+				test.dialect)
+		}
+		for _, rule := range grammar.Rules {
+			for _, diag := range liveness.Diagnostics(rule) {
+				t.Errorf("%s: unexpected diagnostic: %s", test.dialect,
+					diag.Message)
+			}
+		}
+		if names := grammar.Undefined(); len(names) != 0 {
+			t.Errorf("%s: builtins are undefined: %v", test.dialect, names)
+		}
+	}
+}
+
+func TestIncludeDirectives(t *testing.T) {
+	var lines = [][]byte{
+		[]byte(`; %include "common.bnf"`),
+		[]byte(`(* %include lib/digits.ebnf *)`),
+		[]byte(`; %includes "other.bnf"`),
+		[]byte(`; %include`),
+	}
+
+	var paths = IncludeDirectives(lines)
+	if len(paths) != 2 || paths[0] != "common.bnf" ||
+		paths[1] != "lib/digits.ebnf" {
+		t.Errorf("wrong included paths: %q", paths)
+	}
+}
@@ -0,0 +1,51 @@
+package analysis
+
+import (
+	"github.com/daskol/nvim-bnf/pkg/i18n"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+// CodeCommonPrefix is a code of diagnostic about alternatives which start
+// with the same symbol.
+const CodeCommonPrefix = "W011"
+
+// CommonPrefixes reports alternatives of a rule which start with the same
+// terminal or non-terminal as one of the preceding alternatives. Parser could
+// not choose between them by looking at the next symbol, so such rules should
+// be left-factored first (see LeftFactor).
+func (r *Rule) CommonPrefixes() []parser.Diagnostic {
+	var diags []parser.Diagnostic
+	var firsts = make(map[string]int)
+	for idx, alt := range parser.Alternatives(r.Statement.Rule.Right()) {
+		var first = leftmost(alt)
+		var term = RenderTerm(first)
+		if term == "" {
+			continue
+		}
+
+		var prev, ok = firsts[term]
+		if !ok {
+			firsts[term] = idx
+			continue
+		}
+
+		var addr = r.Address(idx)
+		diags = append(diags, parser.Diagnostic{
+			Severity: parser.SeverityWarning,
+			Range:    parser.Span(first),
+			Code:     CodeCommonPrefix,
+			Message: i18n.Sprintf("alternatives %d and %d of rule <%s> "+
+				"start with %s", prev+1, idx+1, r.Name, term),
+			Address: &addr,
+		})
+	}
+	return diags
+}
+
+// LLConflicts reports constructs of a rule which prevent parsing it with
+// recursive descent parser without backtracking: left recursion and
+// alternatives with common prefixes.
+func (r *Rule) LLConflicts() []parser.Diagnostic {
+	var diags = r.leftRecursion(i18n.T("recursive descent parser"))
+	return append(diags, r.CommonPrefixes()...)
+}
@@ -0,0 +1,37 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+func TestLLConflicts(t *testing.T) {
+	var lines = [][]byte{
+		[]byte(`<s> ::= <s> "+" <t> | <t>`),
+		[]byte(`<t> ::= "x" "y" | "z" | "x" "z"`),
+		[]byte(`<u> ::= "x" | <t>`),
+	}
+	var grammar = NewGrammar(parser.DialectBNF, lines)
+
+	var diags = grammar.Rules[0].LLConflicts()
+	if len(diags) != 1 || diags[0].Code != CodeLeftRecursion ||
+		diags[0].Message != "left recursion is not supported in "+
+			"recursive descent parser" {
+		t.Errorf("wrong diagnostics: %+v", diags)
+	}
+
+	diags = grammar.Rules[1].LLConflicts()
+	if len(diags) != 1 || diags[0].Code != CodeCommonPrefix ||
+		diags[0].Range.Begin != 24 || diags[0].Address.Alternative != 2 {
+		t.Fatalf("wrong diagnostics: %+v", diags)
+	}
+	if msg := "alternatives 1 and 3 of rule <t> start with \"x\""; msg !=
+		diags[0].Message {
+		t.Errorf("wrong message: %s", diags[0].Message)
+	}
+
+	if diags = grammar.Rules[2].LLConflicts(); len(diags) != 0 {
+		t.Errorf("wrong diagnostics: %+v", diags)
+	}
+}
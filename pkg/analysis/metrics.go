@@ -0,0 +1,55 @@
+package analysis
+
+// Metrics is a summary of grammar health. Undefined counts distinct
+// non-terminals which are referenced but never defined while Unreferenced
+// counts rules which are never referenced except the start one.
+type Metrics struct {
+	Rules        int `json:"rules"`
+	Alternatives int `json:"alternatives"`
+	Undefined    int `json:"undefined"`
+	Unreferenced int `json:"unreferenced"`
+}
+
+// Metrics computes summary metrics of a grammar.
+func (g *Grammar) Metrics() Metrics {
+	var metrics = Metrics{Rules: g.NoRules()}
+	var defined = make(map[string]bool)
+
+	for _, rule := range g.Rules {
+		defined[rule.Name] = true
+		metrics.Alternatives += len(rule.Alternatives())
+	}
+
+	metrics.Undefined = len(g.Undefined())
+	for name, count := range g.NoReferences() {
+		if defined[name] && count == 0 && name != g.StartSymbol() {
+			metrics.Unreferenced++
+		}
+	}
+
+	return metrics
+}
+
+// Undefined returns names of non-terminals which are referenced but defined
+// neither in the document nor in included files nor by dialect in order of
+// their first reference.
+func (g *Grammar) Undefined() []string {
+	var defined = make(map[string]bool)
+	for _, rule := range g.Rules {
+		defined[rule.Name] = true
+	}
+	for _, rule := range g.Included {
+		defined[rule.Name] = true
+	}
+
+	var names []string
+	for _, rule := range g.Rules {
+		for _, name := range rule.References() {
+			if !defined[name] && !g.Dialect.Builtin(name) {
+				defined[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,111 @@
+package analysis
+
+import (
+	"github.com/daskol/nvim-bnf/pkg/i18n"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+// Codes of diagnostics about constructs which could not be expressed in a
+// target dialect.
+const (
+	CodePredicate      = "W001"
+	CodeWildcard       = "W002"
+	CodeCharacterClass = "W003"
+	CodeException      = "W004"
+	CodeLeftRecursion  = "W005"
+)
+
+// construct describes a syntactic construct and dialects which support it.
+type construct struct {
+	code      string
+	name      string
+	dialects  []parser.Dialect
+	matchNode func(parser.Node) bool
+}
+
+var constructs = []construct{
+	{
+		CodePredicate, "syntactic predicate",
+		[]parser.Dialect{parser.DialectPEG},
+		func(node parser.Node) bool {
+			var _, ok = node.(*parser.PredicateExpression)
+			return ok
+		},
+	},
+	{
+		CodeWildcard, "wildcard",
+		[]parser.Dialect{parser.DialectANTLR, parser.DialectPEG},
+		func(node parser.Node) bool {
+			var _, ok = node.(*parser.Wildcard)
+			return ok
+		},
+	},
+	{
+		CodeCharacterClass, "character class",
+		[]parser.Dialect{
+			parser.DialectANTLR, parser.DialectPEG, parser.DialectW3C,
+		},
+		func(node parser.Node) bool {
+			var _, ok = node.(*parser.CharacterClass)
+			return ok
+		},
+	},
+	{
+		CodeException, "exception",
+		[]parser.Dialect{
+			parser.DialectANTLR, parser.DialectEBNF, parser.DialectW3C,
+		},
+		func(node parser.Node) bool {
+			var _, ok = node.(*parser.ExceptionExpression)
+			return ok
+		},
+	},
+}
+
+func (c *construct) supportedBy(dialect parser.Dialect) bool {
+	for _, supported := range c.dialects {
+		if supported == dialect {
+			return true
+		}
+	}
+	return false
+}
+
+// Portability reports constructs of a rule which have no counterpart in target
+// dialect. Ranges of diagnostics are in the same coordinates as the rule.
+func (r *Rule) Portability(target parser.Dialect) []parser.Diagnostic {
+	var diags []parser.Diagnostic
+	var alts = parser.Alternatives(r.Statement.Rule.Right())
+
+	for idx, alt := range alts {
+		walk(alt, func(node parser.Node) {
+			for _, c := range constructs {
+				if !c.matchNode(node) || c.supportedBy(target) {
+					continue
+				}
+
+				var addr = r.Address(idx)
+				diags = append(diags, parser.Diagnostic{
+					Severity: parser.SeverityWarning,
+					Range:    parser.Span(node),
+					Code:     c.code,
+					Message: i18n.Sprintf("%s is not supported in %s",
+						i18n.T(c.name), target),
+					Address: &addr,
+				})
+			}
+		})
+	}
+
+	return diags
+}
+
+// Portability reports constructs of all rules of a grammar which have no
+// counterpart in target dialect.
+func (g *Grammar) Portability(target parser.Dialect) []parser.Diagnostic {
+	var diags []parser.Diagnostic
+	for _, rule := range g.Rules {
+		diags = append(diags, rule.Portability(target)...)
+	}
+	return diags
+}
@@ -0,0 +1,78 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+func TestPortability(t *testing.T) {
+	var lines = [][]byte{
+		[]byte(`Primary <- Ident !Arrow`),
+		[]byte(`         / [a-z] / .`),
+		[]byte(`Arrow   <- '<-'`),
+	}
+
+	var grammar = NewGrammar(parser.DialectPEG, lines)
+	if norules := grammar.NoRules(); norules != 2 {
+		t.Fatalf("wrong number of rules: %d", norules)
+	}
+
+	if diags := grammar.Portability(parser.DialectPEG); len(diags) != 0 {
+		t.Errorf("grammar is portable to its own dialect: %v", diags)
+	}
+
+	var diags = grammar.Portability(parser.DialectANTLR)
+	if len(diags) != 1 {
+		t.Fatalf("wrong number of diagnostics: %v", diags)
+	} else if diag := diags[0]; diag.Code != CodePredicate {
+		t.Errorf("wrong code of diagnostic: %s", diag.Code)
+	} else if diag.Range.Begin != 17 || diag.Range.End != 23 {
+		t.Errorf("wrong range of diagnostic: %v", diag.Range)
+	} else if *diag.Address != (parser.Address{Rule: "Primary"}) {
+		t.Errorf("wrong address of diagnostic: %v", diag.Address)
+	}
+
+	var codes []string
+	for _, diag := range grammar.Portability(parser.DialectBNF) {
+		codes = append(codes, diag.Code)
+	}
+
+	var expected = []string{CodePredicate, CodeCharacterClass, CodeWildcard}
+	if len(codes) != len(expected) {
+		t.Fatalf("wrong diagnostics: %v", codes)
+	}
+	for idx := range codes {
+		if codes[idx] != expected[idx] {
+			t.Errorf("wrong code of diagnostic #%d: %s", idx, codes[idx])
+		}
+	}
+}
+
+func TestLeftRecursion(t *testing.T) {
+	var lines = [][]byte{
+		[]byte(`<expr> ::= <term> | <expr> "+" <term>`),
+		[]byte(`<term> ::= "x" <term> | "x"`),
+	}
+
+	var grammar = NewGrammar(parser.DialectBNF, lines)
+	var expr, term = grammar.Rules[0], grammar.Rules[1]
+	if diags := expr.LeftRecursion(parser.DialectBNF); diags != nil {
+		t.Errorf("left recursion is allowed in BNF: %v", diags)
+	}
+
+	var diags = expr.LeftRecursion(parser.DialectPEG)
+	if len(diags) != 1 {
+		t.Fatalf("wrong number of diagnostics: %v", diags)
+	} else if diag := diags[0]; diag.Code != CodeLeftRecursion {
+		t.Errorf("wrong code of diagnostic: %s", diag.Code)
+	} else if diag.Range.Begin != 20 || diag.Range.End != 26 {
+		t.Errorf("wrong range of diagnostic: %v", diag.Range)
+	} else if diag.Address.Alternative != 1 {
+		t.Errorf("wrong address of diagnostic: %v", diag.Address)
+	}
+
+	if diags := term.LeftRecursion(parser.DialectPEG); diags != nil {
+		t.Errorf("right recursion is reported: %v", diags)
+	}
+}
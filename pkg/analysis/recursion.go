@@ -0,0 +1,52 @@
+package analysis
+
+import (
+	"github.com/daskol/nvim-bnf/pkg/i18n"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+// LeftRecursion reports alternatives of a rule which start with the rule
+// itself. Recursive descent parsers could not handle them, so they are
+// reported only if target dialect is parsed in this way (i.e. PEG).
+func (r *Rule) LeftRecursion(target parser.Dialect) []parser.Diagnostic {
+	if target != parser.DialectPEG {
+		return nil
+	}
+	return r.leftRecursion(string(target))
+}
+
+// leftRecursion reports alternatives of a rule which start with the rule
+// itself. Kind is a name of parser which could not handle them.
+func (r *Rule) leftRecursion(kind string) []parser.Diagnostic {
+	var diags []parser.Diagnostic
+	var alts = parser.Alternatives(r.Statement.Rule.Right())
+	for idx, alt := range alts {
+		var nonterm, ok = leftmost(alt).(*parser.NonTerminal)
+		if !ok || string(nonterm.Name) != r.Name {
+			continue
+		}
+
+		var addr = r.Address(idx)
+		diags = append(diags, parser.Diagnostic{
+			Severity: parser.SeverityWarning,
+			Range:    parser.Span(nonterm),
+			Code:     CodeLeftRecursion,
+			Message: i18n.Sprintf("%s is not supported in %s",
+				i18n.T("left recursion"), kind),
+			Address: &addr,
+		})
+	}
+	return diags
+}
+
+// leftmost returns the first term of a sequence. Recursive descent parsers
+// (e.g. PEG) loop forever if it refers to the rule itself.
+func leftmost(node parser.Node) parser.Node {
+	for {
+		if seq, ok := node.(*parser.CompoundExpression); ok {
+			node = seq.Left()
+		} else {
+			return node
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package analysis
+
+import (
+	"bytes"
+
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+// Rename replaces name of non-terminal in its definitions and references with
+// another one and returns updated lines together with number of replaced
+// occurrences. Delimiters of non-terminals like angle brackets, comments, and
+// layout are kept as is. Lines which are not changed are shared with input.
+func Rename(
+	dialect parser.Dialect, lines [][]byte, from, to string,
+) ([][]byte, int) {
+	var source = bytes.Join(lines, []byte{'\n'})
+	var tree = parser.ParseLossless(dialect, source)
+
+	var edits []parser.TextEdit
+	for _, token := range tree.Tokens() {
+		var term, ok = token.Node.(*parser.NonTerminal)
+		if !ok || string(term.Name) != from {
+			continue
+		}
+
+		// Name is looked up within text of token in order to keep
+		// delimiters.
+		var text = tree.Text(token)
+		var edit = parser.TextEdit{Range: token.Range, Text: []byte(to)}
+		if idx := bytes.Index(text, term.Name); idx >= 0 {
+			edit.Range.Begin += idx
+			edit.Range.End = edit.Range.Begin + len(term.Name)
+		}
+		edits = append(edits, edit)
+	}
+
+	if len(edits) == 0 {
+		return lines, 0
+	}
+
+	var renamed, err = parser.ApplyEdits(source, edits)
+	if err != nil {
+		return lines, 0
+	}
+
+	var result = bytes.Split(renamed, []byte{'\n'})
+	if len(result) != len(lines) {
+		return lines, 0
+	}
+	for idx := range result {
+		if bytes.Equal(result[idx], lines[idx]) {
+			result[idx] = lines[idx]
+		}
+	}
+	return result, len(edits)
+}
@@ -0,0 +1,57 @@
+package analysis
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+func TestRename(t *testing.T) {
+	var cases = []struct {
+		dialect  parser.Dialect
+		input    string
+		expected string
+		count    int
+	}{
+		{
+			dialect: parser.DialectBNF,
+			input: "<expr> ::= <term> | <expr> \"+\" <term>\n" +
+				"; <term> in comment is kept.\n" +
+				"<term> ::= \"x\"",
+			expected: "<expr> ::= <factor> | <expr> \"+\" <factor>\n" +
+				"; <term> in comment is kept.\n" +
+				"<factor> ::= \"x\"",
+			count: 3,
+		},
+		{
+			dialect: parser.DialectEBNF,
+			input: "expr = term,\n" +
+				"  {\"+\", term}; (* term *)\n" +
+				"term = \"x\";",
+			expected: "expr = factor,\n" +
+				"  {\"+\", factor}; (* term *)\n" +
+				"factor = \"x\";",
+			count: 3,
+		},
+		{
+			dialect:  parser.DialectBNF,
+			input:    "<expr> ::= \"term\"",
+			expected: "<expr> ::= \"term\"",
+			count:    0,
+		},
+	}
+
+	for _, test := range cases {
+		var lines = bytes.Split([]byte(test.input), []byte{'\n'})
+		var renamed, count = Rename(test.dialect, lines, "term", "factor")
+		var actual = string(bytes.Join(renamed, []byte{'\n'}))
+		if actual != test.expected {
+			t.Errorf("wrong renaming in %s:\n%s", test.dialect, actual)
+		}
+		if count != test.count {
+			t.Errorf("wrong number of occurrences in %s: %d", test.dialect,
+				count)
+		}
+	}
+}
@@ -0,0 +1,313 @@
+package analysis
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+// epsilon is a rendered empty alternative.
+const epsilon = `""`
+
+// production is a rule of classic BNF which is being transformed. Its
+// alternatives are lists of rendered terms. Rules which are added by
+// transformation are placed right after the production. Parent is set for
+// rules which groups of another production are expanded to.
+type production struct {
+	name    string
+	rule    *Rule
+	alts    [][]string
+	changed bool
+	removed bool
+	added   []*production
+	parent  *production
+}
+
+// newProductions parses a document in classic BNF into productions. Groups
+// are expanded to new rules which follow productions in the list, so that
+// every term of an alternative is a terminal or a non-terminal.
+func newProductions(lines [][]byte) []*production {
+	var grammar = NewGrammar(parser.DialectBNF, lines)
+	var names = make(map[string]bool)
+	for _, rule := range grammar.Rules {
+		names[rule.Name] = true
+	}
+
+	// Name of group rule should not clash with existing names.
+	var newName = func(base string) string {
+		var name = base + "-group"
+		for idx := 2; names[name]; idx++ {
+			name = base + "-group-" + strconv.Itoa(idx)
+		}
+		names[name] = true
+		return name
+	}
+
+	var prods = make([]*production, 0, len(grammar.Rules))
+	for _, rule := range grammar.Rules {
+		var prod = &production{name: rule.Name, rule: rule}
+		prod.alts = expandGroups(prod, rule.Statement.Rule.Right(), newName)
+		prods = append(prods, prod)
+		prods = append(prods, prod.added...)
+	}
+	return prods
+}
+
+// expandGroups renders alternatives of an expression where groups are
+// expanded to new rules.
+func expandGroups(
+	prod *production, node parser.Node, newName func(string) string,
+) [][]string {
+	var alts [][]string
+	for _, alt := range parser.Alternatives(node) {
+		alts = append(alts, expandTerms(prod, alt, newName))
+	}
+	return alts
+}
+
+// expandTerms renders terms of an alternative. Every group is replaced with a
+// reference to a new rule which is added to production. Optional group
+// derives empty string as well and repeated one refers to itself.
+func expandTerms(
+	prod *production, node parser.Node, newName func(string) string,
+) []string {
+	switch node := node.(type) {
+	case *parser.CompoundExpression:
+		return append(expandTerms(prod, node.Left(), newName),
+			expandTerms(prod, node.Right(), newName)...)
+	case *parser.GroupExpression:
+		var aux = &production{name: newName(prod.name), parent: prod}
+		var ref = "<" + aux.name + ">"
+		prod.changed = true
+		prod.added = append(prod.added, aux)
+		aux.alts = expandGroups(prod, node.Left(), newName)
+		switch node.Kind {
+		case parser.GroupOptional:
+			aux.alts = append(aux.alts, []string{epsilon})
+		case parser.GroupRepetition:
+			for idx := range aux.alts {
+				aux.alts[idx] = append(aux.alts[idx], ref)
+			}
+			aux.alts = append(aux.alts, []string{epsilon})
+		}
+		return []string{ref}
+	default:
+		return renderTerms(node)
+	}
+}
+
+func (p *production) render() string {
+	var alts = make([]string, len(p.alts))
+	for idx, alt := range p.alts {
+		alts[idx] = strings.Join(alt, " ")
+	}
+	return "<" + p.name + "> ::= " + strings.Join(alts, " | ")
+}
+
+// references counts references to a non-terminal in a production.
+func (p *production) references(term string) int {
+	var count int
+	for _, alt := range p.alts {
+		for _, t := range alt {
+			if t == term {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// Simplify minimizes a grammar written in classic BNF. Identity rules like
+// `<a> ::= <b>` are removed and references to them are replaced with their
+// right-hand sides. Non-terminals which are referenced exactly once are
+// inlined if they have the only alternative or if they are referenced by an
+// alternative which consists of the non-terminal alone. Start rule (the first
+// one unless it is declared with `; %start`) is never removed. Lines of rules
+// which are not changed are kept as is together with comments and blank
+// lines.
+func Simplify(lines [][]byte) [][]byte {
+	var prods = newProductions(lines)
+	var defs = make(map[string]int)
+	for _, prod := range prods {
+		defs[prod.name]++
+	}
+
+	var start = startOf(prods, lines)
+
+	// Rules which are defined once could be removed except for start one.
+	var removable = func(prod *production) bool {
+		return prod.name != start && !prod.removed && defs[prod.name] == 1
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for _, prod := range prods {
+			if !removable(prod) {
+				continue
+			}
+			if inlineIdentity(prods, prod) || inlineSingleUse(prods, prod) {
+				prod.removed = true
+				changed = true
+			}
+		}
+	}
+
+	return rewriteLines(lines, prods)
+}
+
+// startOf returns start symbol of productions. It is the first one unless it
+// is declared explicitly.
+func startOf(prods []*production, lines [][]byte) string {
+	if start := StartDirective(lines); start != "" {
+		return start
+	} else if len(prods) > 0 {
+		return prods[0].name
+	}
+	return ""
+}
+
+// rewriteLines renders changed productions in place of their lines, drops
+// lines of removed ones, and inserts added rules after them. Every rule of
+// classic BNF occupies a line of its own.
+func rewriteLines(lines [][]byte, prods []*production) [][]byte {
+	var rewrites = make(map[int]*production)
+	for _, prod := range prods {
+		if prod.parent == nil &&
+			(prod.changed || prod.removed || len(prod.added) > 0) {
+			rewrites[prod.rule.Line] = prod
+		}
+	}
+
+	var result [][]byte
+	for idx, line := range lines {
+		var prod, ok = rewrites[idx]
+		switch {
+		case !ok:
+			result = append(result, line)
+			continue
+		case prod.changed && !prod.removed:
+			result = append(result, []byte(prod.render()))
+		case !prod.removed:
+			result = append(result, line)
+		}
+		result = appendAdded(result, prod)
+	}
+	return result
+}
+
+// appendAdded renders rules which are added to a production unless they are
+// removed. Rules which are added to them follow them.
+func appendAdded(result [][]byte, prod *production) [][]byte {
+	for _, added := range prod.added {
+		if !added.removed {
+			result = append(result, []byte(added.render()))
+		}
+		result = appendAdded(result, added)
+	}
+	return result
+}
+
+// inlineIdentity replaces references to identity rule with the only
+// non-terminal of its right-hand side.
+func inlineIdentity(prods []*production, identity *production) bool {
+	var term = "<" + identity.name + ">"
+	if len(identity.alts) != 1 || len(identity.alts[0]) != 1 {
+		return false
+	}
+
+	var target = identity.alts[0][0]
+	if !strings.HasPrefix(target, "<") || target == term {
+		return false
+	}
+
+	for _, prod := range prods {
+		if prod.removed || prod == identity {
+			continue
+		}
+		for _, alt := range prod.alts {
+			for idx := range alt {
+				if alt[idx] == term {
+					alt[idx] = target
+					prod.changed = true
+				}
+			}
+		}
+	}
+	return true
+}
+
+// inlineSingleUse substitutes a rule which is referenced exactly once into
+// the place of reference.
+func inlineSingleUse(prods []*production, rule *production) bool {
+	var term = "<" + rule.name + ">"
+	if rule.references(term) > 0 {
+		return false
+	}
+
+	var user *production
+	for _, prod := range prods {
+		if prod.removed || prod == rule {
+			continue
+		}
+		switch prod.references(term) {
+		case 0:
+		case 1:
+			if user != nil {
+				return false
+			}
+			user = prod
+		default:
+			return false
+		}
+	}
+
+	if user == nil {
+		return false
+	}
+
+	for idx, alt := range user.alts {
+		var pos = indexOf(alt, term)
+		switch {
+		case pos < 0:
+			continue
+		case len(rule.alts) == 1:
+			var seq = append([]string{}, alt[:pos]...)
+			seq = append(seq, rule.alts[0]...)
+			seq = append(seq, alt[pos+1:]...)
+			user.alts[idx] = dropEpsilons(seq)
+		case len(alt) == 1:
+			var alts = append([][]string{}, user.alts[:idx]...)
+			alts = append(alts, rule.alts...)
+			user.alts = append(alts, user.alts[idx+1:]...)
+		default:
+			return false
+		}
+		user.changed = true
+		return true
+	}
+	return false
+}
+
+// dropEpsilons removes empty terms from a sequence unless it is empty itself.
+func dropEpsilons(seq []string) []string {
+	var res = seq[:0]
+	for _, term := range seq {
+		if term != epsilon {
+			res = append(res, term)
+		}
+	}
+	if len(res) == 0 {
+		return []string{epsilon}
+	}
+	return res
+}
+
+func indexOf(terms []string, term string) int {
+	for idx := range terms {
+		if terms[idx] == term {
+			return idx
+		}
+	}
+	return -1
+}
@@ -0,0 +1,94 @@
+package analysis
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSimplify(t *testing.T) {
+	var cases = []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name: "Identity",
+			input: "<expr> ::= <term> | <expr> \"+\" <term>\n" +
+				"<term> ::= <atom>\n" +
+				"<atom> ::= \"x\" | \"(\" <expr> \")\"",
+			expected: "<expr> ::= <atom> | <expr> \"+\" <atom>\n" +
+				"<atom> ::= \"x\" | \"(\" <expr> \")\"",
+		},
+		{
+			name: "SingleAlternative",
+			input: "<list> ::= <head> <tail>\n" +
+				"; comment\n" +
+				"<head> ::= \"[\" <item>\n" +
+				"<tail> ::= \"]\" | \",\" <item> <tail>\n" +
+				"<item> ::= \"x\" | \"y\"",
+			expected: "<list> ::= \"[\" <item> <tail>\n" +
+				"; comment\n" +
+				"<tail> ::= \"]\" | \",\" <item> <tail>\n" +
+				"<item> ::= \"x\" | \"y\"",
+		},
+		{
+			name: "SoleReference",
+			input: "<a> ::= <b> | \"c\"\n" +
+				"<b> ::= \"d\" | \"\"",
+			expected: "<a> ::= \"d\" | \"\" | \"c\"",
+		},
+		{
+			name: "Distribution",
+			input: "<a> ::= \"x\" <b>\n" +
+				"<b> ::= \"y\" | \"z\"",
+			expected: "<a> ::= \"x\" <b>\n" +
+				"<b> ::= \"y\" | \"z\"",
+		},
+		{
+			name:     "StartRule",
+			input:    "<a> ::= <b>\n<b> ::= <a> \"c\" | \"d\"",
+			expected: "<a> ::= <a> \"c\" | \"d\"",
+		},
+		{
+			name:  "Group",
+			input: "<a> ::= ( \"x\" \"y\" ) <b>\n<b> ::= ( <c> | \"z\" )",
+			expected: "<a> ::= \"x\" \"y\" <b-group>\n" +
+				"<b-group> ::= <c> | \"z\"",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var lines = splitLines(c.input)
+			var actual = joinLines(Simplify(lines))
+			if actual != c.expected {
+				t.Errorf("wrong simplification:\n%s", actual)
+			}
+		})
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	var a = []string{"a", "b", "c"}
+	var b = []string{"a", "c", "d"}
+	var actual = strings.Join(DiffLines(a, b), ",")
+	if actual != " a,-b, c,+d" {
+		t.Errorf("wrong diff: %s", actual)
+	}
+}
+
+func splitLines(text string) [][]byte {
+	var lines [][]byte
+	for _, line := range strings.Split(text, "\n") {
+		lines = append(lines, []byte(line))
+	}
+	return lines
+}
+
+func joinLines(lines [][]byte) string {
+	var strs = make([]string, len(lines))
+	for idx, line := range lines {
+		strs[idx] = string(line)
+	}
+	return strings.Join(strs, "\n")
+}
@@ -0,0 +1,196 @@
+package analysis
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+// SortMode is an order of rules.
+type SortMode string
+
+const (
+	// SortAlphabetical orders rules by their names.
+	SortAlphabetical SortMode = "alpha"
+	// SortTopological places definition of a rule before its uses.
+	SortTopological SortMode = "topo"
+	// SortReferences orders rules by number of references in descending
+	// order.
+	SortReferences SortMode = "refs"
+)
+
+// ErrUnknownSortMode is returned if order of rules is not supported.
+var ErrUnknownSortMode = errors.New("analysis: unknown sort mode")
+
+// commentPrefixes are openers of comments of all dialects.
+var commentPrefixes = []string{"(*", "/*", "//", "#", ";"}
+
+// chunk is a rule together with comments which precede it.
+type chunk struct {
+	name  string
+	lines [][]byte
+}
+
+// SortRules reorders rules of a document. Groups of lines separated with
+// blank lines are sorted independently, so blank-line grouping is preserved.
+// Comments which immediately precede a rule are moved together with it while
+// lines of a group before its first rule and its comments stay in place.
+func SortRules(
+	dialect parser.Dialect, lines [][]byte, mode SortMode,
+) ([][]byte, error) {
+	switch mode {
+	case SortAlphabetical, SortTopological, SortReferences:
+	default:
+		return nil, ErrUnknownSortMode
+	}
+
+	var grammar = NewGrammar(dialect, lines)
+	var starts = make(map[int]string)
+	for _, rule := range grammar.Rules {
+		if _, ok := starts[rule.Line]; !ok {
+			starts[rule.Line] = rule.Name
+		}
+	}
+
+	var result = make([][]byte, 0, len(lines))
+	for begin := 0; begin < len(lines); {
+		if isBlank(lines[begin]) {
+			result = append(result, lines[begin])
+			begin++
+			continue
+		}
+
+		var end = begin
+		for end < len(lines) && !isBlank(lines[end]) {
+			end++
+		}
+
+		var header, chunks = splitGroup(lines[begin:end], begin, starts)
+		sortChunks(grammar, chunks, mode)
+		result = append(result, header...)
+		for _, chunk := range chunks {
+			result = append(result, chunk.lines...)
+		}
+		begin = end
+	}
+
+	return result, nil
+}
+
+// splitGroup splits a group of non-blank lines into chunks of rules. Lines
+// which precede the first chunk are returned as header. Offset is index of
+// the first line of the group in document.
+func splitGroup(
+	group [][]byte, offset int, starts map[int]string,
+) ([][]byte, []chunk) {
+	var bounds []int
+	var prev = -1
+	for idx := range group {
+		if _, ok := starts[offset+idx]; !ok {
+			continue
+		}
+
+		// Comments right before a rule belong to it.
+		var begin = idx
+		for begin-1 > prev && isComment(group[begin-1]) {
+			begin--
+		}
+		bounds = append(bounds, begin)
+		prev = idx
+	}
+
+	if len(bounds) == 0 {
+		return group, nil
+	}
+
+	var chunks = make([]chunk, len(bounds))
+	for i, begin := range bounds {
+		var end = len(group)
+		if i+1 < len(bounds) {
+			end = bounds[i+1]
+		}
+		chunks[i].lines = group[begin:end]
+		for idx := begin; idx < end; idx++ {
+			if name, ok := starts[offset+idx]; ok {
+				chunks[i].name = name
+				break
+			}
+		}
+	}
+	return group[:bounds[0]], chunks
+}
+
+func sortChunks(grammar *Grammar, chunks []chunk, mode SortMode) {
+	switch mode {
+	case SortAlphabetical:
+		sort.SliceStable(chunks, func(i, j int) bool {
+			var a, b = chunks[i].name, chunks[j].name
+			if strings.EqualFold(a, b) {
+				return a < b
+			}
+			return strings.ToLower(a) < strings.ToLower(b)
+		})
+	case SortReferences:
+		var refs = grammar.NoReferences()
+		sort.SliceStable(chunks, func(i, j int) bool {
+			return refs[chunks[i].name] > refs[chunks[j].name]
+		})
+	case SortTopological:
+		copy(chunks, topologicalOrder(grammar, chunks))
+	}
+}
+
+// topologicalOrder places chunks in post-order of depth-first traversal of
+// references, so a rule is defined before rules which use it. Cycles are
+// broken in original order of rules.
+func topologicalOrder(grammar *Grammar, chunks []chunk) []chunk {
+	var byName = make(map[string][]int)
+	for idx, chunk := range chunks {
+		byName[chunk.name] = append(byName[chunk.name], idx)
+	}
+
+	var order = make([]chunk, 0, len(chunks))
+	var visited = make(map[string]bool)
+	var visit func(name string)
+	visit = func(name string) {
+		if visited[name] {
+			return
+		}
+		visited[name] = true
+		for _, rule := range grammar.Rules {
+			if rule.Name != name {
+				continue
+			}
+			for _, ref := range rule.References() {
+				if _, ok := byName[ref]; ok {
+					visit(ref)
+				}
+			}
+		}
+		for _, idx := range byName[name] {
+			order = append(order, chunks[idx])
+		}
+	}
+
+	for _, chunk := range chunks {
+		visit(chunk.name)
+	}
+	return order
+}
+
+func isBlank(line []byte) bool {
+	return len(bytes.TrimSpace(line)) == 0
+}
+
+func isComment(line []byte) bool {
+	var trimmed = bytes.TrimSpace(line)
+	for _, prefix := range commentPrefixes {
+		if bytes.HasPrefix(trimmed, []byte(prefix)) {
+			return true
+		}
+	}
+	return false
+}
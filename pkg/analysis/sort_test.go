@@ -0,0 +1,74 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+func TestSortRules(t *testing.T) {
+	var input = "; Expressions.\n" +
+		"\n" +
+		"<expr> ::= <term> | <expr> \"+\" <term>\n" +
+		"; Terms are products.\n" +
+		"<term> ::= <atom> | <term> \"*\" <atom>\n" +
+		"<atom> ::= <digit> | \"(\" <expr> \")\"\n" +
+		"\n" +
+		"<digit> ::= \"0\" | \"1\""
+
+	var cases = []struct {
+		mode     SortMode
+		expected string
+	}{
+		{
+			mode: SortAlphabetical,
+			expected: "; Expressions.\n" +
+				"\n" +
+				"<atom> ::= <digit> | \"(\" <expr> \")\"\n" +
+				"<expr> ::= <term> | <expr> \"+\" <term>\n" +
+				"; Terms are products.\n" +
+				"<term> ::= <atom> | <term> \"*\" <atom>\n" +
+				"\n" +
+				"<digit> ::= \"0\" | \"1\"",
+		},
+		{
+			mode: SortTopological,
+			expected: "; Expressions.\n" +
+				"\n" +
+				"<atom> ::= <digit> | \"(\" <expr> \")\"\n" +
+				"; Terms are products.\n" +
+				"<term> ::= <atom> | <term> \"*\" <atom>\n" +
+				"<expr> ::= <term> | <expr> \"+\" <term>\n" +
+				"\n" +
+				"<digit> ::= \"0\" | \"1\"",
+		},
+		{
+			mode: SortReferences,
+			expected: "; Expressions.\n" +
+				"\n" +
+				"; Terms are products.\n" +
+				"<term> ::= <atom> | <term> \"*\" <atom>\n" +
+				"<expr> ::= <term> | <expr> \"+\" <term>\n" +
+				"<atom> ::= <digit> | \"(\" <expr> \")\"\n" +
+				"\n" +
+				"<digit> ::= \"0\" | \"1\"",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(string(c.mode), func(t *testing.T) {
+			var sorted, err = SortRules(parser.DialectBNF,
+				splitLines(input), c.mode)
+			if err != nil {
+				t.Fatalf("failed to sort rules: %s", err)
+			}
+			if actual := joinLines(sorted); actual != c.expected {
+				t.Errorf("wrong order of rules:\n%s", actual)
+			}
+		})
+	}
+
+	if _, err := SortRules(parser.DialectBNF, nil, "size"); err == nil {
+		t.Errorf("unknown mode is accepted")
+	}
+}
@@ -0,0 +1,36 @@
+package analysis
+
+import (
+	"github.com/daskol/nvim-bnf/pkg/i18n"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+// CodeUndefined is a code of diagnostic about reference to a non-terminal
+// which is never defined.
+const CodeUndefined = "W010"
+
+// UndefinedReferences reports every reference to a non-terminal which is
+// defined neither in grammar nor in included files nor by dialect. Diagnostics
+// are grouped by rules where references occur and they point to references.
+func (g *Grammar) UndefinedReferences() map[*Rule][]parser.Diagnostic {
+	var diags = make(map[*Rule][]parser.Diagnostic)
+	for _, rule := range g.Rules {
+		walk(rule.Statement.Rule.Right(), func(node parser.Node) {
+			var ref, ok = node.(*parser.NonTerminal)
+			if !ok || g.Defines(string(ref.Name)) ||
+				g.Dialect.Builtin(string(ref.Name)) {
+				return
+			}
+			var addr = rule.Address(rule.AlternativeAt(ref.Begin))
+			diags[rule] = append(diags[rule], parser.Diagnostic{
+				Severity: parser.SeverityWarning,
+				Range:    parser.Range{Begin: ref.Begin, End: ref.End},
+				Code:     CodeUndefined,
+				Message: i18n.Sprintf("non-terminal <%s> is not defined",
+					ref.Name),
+				Address: &addr,
+			})
+		})
+	}
+	return diags
+}
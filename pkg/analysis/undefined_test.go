@@ -0,0 +1,35 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+func TestUndefinedReferences(t *testing.T) {
+	var lines = [][]byte{
+		[]byte(`<s> ::= <a> | <b> <c>`),
+		[]byte(`<a> ::= "a" <c>`),
+	}
+
+	var grammar = NewGrammar(parser.DialectBNF, lines)
+	var undefined = grammar.UndefinedReferences()
+
+	var diags = undefined[grammar.Rules[0]]
+	if len(diags) != 2 {
+		t.Fatalf("wrong diagnostics: %v", diags)
+	}
+	if diags[0].Code != CodeUndefined || diags[0].Range.Begin != 14 ||
+		diags[0].Range.End != 17 || diags[0].Address.Alternative != 1 {
+		t.Errorf("wrong diagnostic: %+v", diags[0])
+	}
+	if len(undefined[grammar.Rules[1]]) != 1 {
+		t.Errorf("wrong diagnostics: %v", undefined[grammar.Rules[1]])
+	}
+
+	// References to included rules are resolved.
+	grammar.Included = []*Rule{{Name: "b"}, {Name: "c"}}
+	if undefined = grammar.UndefinedReferences(); len(undefined) != 0 {
+		t.Errorf("included rules are not taken into account: %v", undefined)
+	}
+}
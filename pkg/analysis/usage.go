@@ -0,0 +1,57 @@
+package analysis
+
+import (
+	"sort"
+)
+
+// Usage summarizes how a non-terminal is used in a grammar. References is the
+// number of references to the non-terminal and ReferencedBy lists distinct
+// names of rules which refer to it (including itself) in lexicographical
+// order.
+type Usage struct {
+	Name         string
+	Line         int
+	References   int
+	ReferencedBy []string
+	Reachable    bool
+}
+
+// Usages reports usage of every non-terminal which is defined in a grammar in
+// order of the first definitions. Rules of included files are not reported
+// but their references are taken into account.
+func (g *Grammar) Usages() []Usage {
+	var counts = make(map[string]int)
+	var referrers = make(map[string]map[string]bool)
+	for _, rule := range append(append([]*Rule{}, g.Rules...), g.Included...) {
+		for _, name := range rule.References() {
+			counts[name]++
+			if referrers[name] == nil {
+				referrers[name] = make(map[string]bool)
+			}
+			referrers[name][rule.Name] = true
+		}
+	}
+
+	var liveness = g.Liveness()
+	var usages []Usage
+	var seen = make(map[string]bool)
+	for _, rule := range g.Rules {
+		if seen[rule.Name] {
+			continue
+		}
+		seen[rule.Name] = true
+
+		var usage = Usage{
+			Name:       rule.Name,
+			Line:       rule.Line,
+			References: counts[rule.Name],
+			Reachable:  liveness.Reachable[rule.Name],
+		}
+		for name := range referrers[rule.Name] {
+			usage.ReferencedBy = append(usage.ReferencedBy, name)
+		}
+		sort.Strings(usage.ReferencedBy)
+		usages = append(usages, usage)
+	}
+	return usages
+}
@@ -0,0 +1,27 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+func TestUsages(t *testing.T) {
+	var lines = [][]byte{
+		[]byte(`<expr> ::= <term> | <expr> "+" <term>`),
+		[]byte(`<term> ::= "x" | "(" <expr> ")"`),
+		[]byte(`<term> ::= <num>`),
+		[]byte(`<unused> ::= <term>`),
+	}
+
+	var grammar = NewGrammar(parser.DialectBNF, lines)
+	var expected = []Usage{
+		{"expr", 0, 2, []string{"expr", "term"}, true},
+		{"term", 1, 3, []string{"expr", "unused"}, true},
+		{"unused", 3, 0, nil, false},
+	}
+	if usages := grammar.Usages(); !reflect.DeepEqual(usages, expected) {
+		t.Errorf("wrong usages: %v", usages)
+	}
+}
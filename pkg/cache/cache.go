@@ -0,0 +1,176 @@
+// Package cache persists parse trees of grammar files on disk, so reopening
+// a large project does not parse every file again. Entries are keyed by paths
+// of files and an entry is invalidated as soon as content of its file changes.
+package cache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"expvar"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+// version of format of entries. Entries of other versions are ignored.
+const version = 1
+
+// entry is a cached grammar of a file together with hash of its content.
+type entry struct {
+	Version int
+	Dialect parser.Dialect
+	Hash    []byte
+	Rules   []entryRule
+}
+
+type entryRule struct {
+	Line      int
+	Statement *parser.Statement
+}
+
+// Lookups of cache are counted, so that hit rate is exposed among metrics of
+// plugin.
+var (
+	metricHits   = expvar.NewInt("nvim_bnf_cache_hits_total")
+	metricMisses = expvar.NewInt("nvim_bnf_cache_misses_total")
+)
+
+// Cache is a directory of cached grammars. It is safe for concurrent use
+// since entries are replaced atomically.
+type Cache struct {
+	dir string
+}
+
+// New creates a cache in a directory. Directory is created on demand.
+func New(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+// DefaultDir returns directory of cache under user cache directory (e.g.
+// $XDG_CACHE_HOME/nvim-bnf/ast).
+func DefaultDir() string {
+	var dir, err = os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "nvim-bnf", "ast")
+}
+
+// Dir returns directory of cache.
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// Load returns cached grammar of a file if content of the file is not
+// changed since the grammar was stored.
+func (c *Cache) Load(
+	path string, dialect parser.Dialect, content []byte,
+) (*analysis.Grammar, bool) {
+	var grammar, ok = c.load(path, dialect, content)
+	if ok {
+		metricHits.Add(1)
+	} else {
+		metricMisses.Add(1)
+	}
+	return grammar, ok
+}
+
+func (c *Cache) load(
+	path string, dialect parser.Dialect, content []byte,
+) (*analysis.Grammar, bool) {
+	var data, err = ioutil.ReadFile(c.filename(path))
+	if err != nil {
+		return nil, false
+	}
+
+	var e entry
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&e); err != nil {
+		return nil, false
+	}
+
+	if e.Version != version || e.Dialect != dialect ||
+		!bytes.Equal(e.Hash, hash(content)) {
+		return nil, false
+	}
+
+	var lines = bytes.Split(content, []byte{'\n'})
+	var grammar = &analysis.Grammar{
+		Start:   analysis.StartDirective(lines),
+		Dialect: dialect,
+	}
+	for _, rule := range e.Rules {
+		if rule := analysis.NewRule(rule.Statement, rule.Line); rule != nil {
+			grammar.Rules = append(grammar.Rules, rule)
+		}
+	}
+	grammar.Annotate(dialect, lines)
+	return grammar, true
+}
+
+// Store saves grammar of a file. Previous entry of the file is replaced.
+func (c *Cache) Store(
+	path string, dialect parser.Dialect, content []byte,
+	grammar *analysis.Grammar,
+) error {
+	var e = entry{Version: version, Dialect: dialect, Hash: hash(content)}
+	for _, rule := range grammar.Rules {
+		e.Rules = append(e.Rules, entryRule{rule.Line, rule.Statement})
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&e); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return err
+	}
+
+	// Entry is written to a temporary file first, so readers never see a
+	// partially written entry.
+	var tmp, err = ioutil.TempFile(c.dir, "entry")
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), c.filename(path))
+}
+
+// Invalidate removes entry of a file.
+func (c *Cache) Invalidate(path string) error {
+	var err = os.Remove(c.filename(path))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// filename returns name of entry of a file. It is derived from absolute path
+// of the file.
+func (c *Cache) filename(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return filepath.Join(c.dir, hex.EncodeToString(hash([]byte(path)))+".gob")
+}
+
+func hash(data []byte) []byte {
+	var sum = sha256.Sum256(data)
+	return sum[:]
+}
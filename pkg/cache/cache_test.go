@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+func TestCache(t *testing.T) {
+	var dir, err = ioutil.TempDir("", "cache")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	var content = []byte("; %start <b>\n<a> ::= <b> \"a\"\n<b> ::= \"b\"\n")
+	var lines = [][]byte{
+		[]byte(`; %start <b>`),
+		[]byte(`<a> ::= <b> "a"`),
+		[]byte(`<b> ::= "b"`),
+	}
+	var grammar = analysis.NewGrammar(parser.DialectBNF, lines)
+
+	var cache = New(dir)
+	if _, ok := cache.Load("a.bnf", parser.DialectBNF, content); ok {
+		t.Fatalf("entry is loaded before it is stored")
+	}
+
+	if err := cache.Store("a.bnf", parser.DialectBNF, content,
+		grammar); err != nil {
+		t.Fatalf("failed to store entry: %s", err)
+	}
+
+	cached, ok := cache.Load("a.bnf", parser.DialectBNF, content)
+	if !ok {
+		t.Fatalf("failed to load entry")
+	}
+	if !reflect.DeepEqual(cached, grammar) {
+		t.Errorf("wrong grammar is loaded: %+v", cached)
+	}
+
+	var changed = append(content, "<c> ::= \"c\"\n"...)
+	if _, ok := cache.Load("a.bnf", parser.DialectBNF, changed); ok {
+		t.Errorf("entry is not invalidated on change")
+	}
+	if _, ok := cache.Load("a.bnf", parser.DialectEBNF, content); ok {
+		t.Errorf("entry of other dialect is loaded")
+	}
+
+	if err := cache.Invalidate("a.bnf"); err != nil {
+		t.Fatalf("failed to invalidate entry: %s", err)
+	}
+	if _, ok := cache.Load("a.bnf", parser.DialectBNF, content); ok {
+		t.Errorf("entry is loaded after invalidation")
+	}
+}
@@ -0,0 +1,141 @@
+// Package codegen generates parser skeletons from grammars. A skeleton is a
+// recursive descent parser where every rule has its own type of node and
+// parse function which body is left to be written.
+package codegen
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+// Language is a target language of generated code.
+type Language string
+
+// Supported target languages.
+const (
+	LanguageGo Language = "go"
+)
+
+var (
+	// ErrUnknownLanguage is returned if target language is not supported.
+	ErrUnknownLanguage = errors.New("codegen: unknown language")
+	// ErrNoRules is returned if a grammar has no rules at all.
+	ErrNoRules = errors.New("codegen: grammar has no rules")
+)
+
+// Options customizes generated code.
+type Options struct {
+	// Package is a name of package of generated code.
+	Package string
+}
+
+// Problem is a diagnostic of a rule which makes generated parser incorrect.
+type Problem struct {
+	Rule *analysis.Rule
+	parser.Diagnostic
+}
+
+// Check reports problems of a grammar which should be fixed before parser is
+// generated: references to undefined non-terminals, left recursion, and
+// alternatives with common prefixes. Problems are ordered by rules.
+func Check(grammar *analysis.Grammar) []Problem {
+	var problems []Problem
+	var undefined = grammar.UndefinedReferences()
+	for _, rule := range grammar.Rules {
+		var diags = append([]parser.Diagnostic{}, undefined[rule]...)
+		diags = append(diags, rule.LLConflicts()...)
+		sort.SliceStable(diags, func(i, j int) bool {
+			return diags[i].Range.Begin < diags[j].Range.Begin
+		})
+		for _, diag := range diags {
+			problems = append(problems, Problem{rule, diag})
+		}
+	}
+	return problems
+}
+
+// Generate renders parser skeleton of a grammar in target language. It does
+// not check grammar (see Check).
+func Generate(
+	lang Language, grammar *analysis.Grammar, opts Options,
+) ([]byte, error) {
+	var rules = order(grammar)
+	if len(rules) == 0 {
+		return nil, ErrNoRules
+	}
+
+	switch lang {
+	case LanguageGo:
+		return generateGo(rules, opts)
+	default:
+		return nil, ErrUnknownLanguage
+	}
+}
+
+// symbol is a non-terminal together with all rules which define it.
+type symbol struct {
+	name  string
+	rules []*analysis.Rule
+}
+
+// doc returns documentation of the first documented definition of symbol.
+func (s *symbol) doc() string {
+	for _, rule := range s.rules {
+		if doc := rule.Doc(); doc != "" {
+			return doc
+		}
+	}
+	return ""
+}
+
+// alternatives returns alternatives of all definitions of symbol.
+func (s *symbol) alternatives() [][]string {
+	var alts [][]string
+	for _, rule := range s.rules {
+		alts = append(alts, rule.Alternatives()...)
+	}
+	return alts
+}
+
+// order lists symbols of a grammar in order of depth-first traversal from
+// start symbol, so a parse function precedes functions which it calls.
+// Unreachable symbols follow in order of their definitions.
+func order(grammar *analysis.Grammar) []*symbol {
+	var symbols = make(map[string]*symbol)
+	var names []string
+	for _, rule := range grammar.Rules {
+		var sym, ok = symbols[rule.Name]
+		if !ok {
+			sym = &symbol{name: rule.Name}
+			symbols[rule.Name] = sym
+			names = append(names, rule.Name)
+		}
+		sym.rules = append(sym.rules, rule)
+	}
+
+	var ordered []*symbol
+	var visited = make(map[string]bool)
+	var visit func(name string)
+	visit = func(name string) {
+		var sym, ok = symbols[name]
+		if !ok || visited[name] {
+			return
+		}
+		visited[name] = true
+		ordered = append(ordered, sym)
+		for _, rule := range sym.rules {
+			for _, ref := range rule.References() {
+				visit(ref)
+			}
+		}
+	}
+
+	visit(grammar.StartSymbol())
+	for _, name := range names {
+		visit(name)
+	}
+	return ordered
+}
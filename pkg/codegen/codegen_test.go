@@ -0,0 +1,81 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+func TestCheck(t *testing.T) {
+	var lines = [][]byte{
+		[]byte(`<s> ::= <s> "+" <t> | <t>`),
+		[]byte(`<t> ::= "x" <u> | "x"`),
+	}
+	var grammar = analysis.NewGrammar(parser.DialectBNF, lines)
+
+	var codes []string
+	for _, problem := range Check(grammar) {
+		codes = append(codes, problem.Rule.Name+":"+problem.Code)
+	}
+	var expected = "s:W005 t:W010 t:W011"
+	if actual := strings.Join(codes, " "); actual != expected {
+		t.Errorf("wrong problems: %s", actual)
+	}
+}
+
+func TestGenerateGo(t *testing.T) {
+	var lines = [][]byte{
+		[]byte(`<digit> ::= "0" | "1"`),
+		[]byte(`; Sequence of digits.`),
+		[]byte(`<digit-seq> ::= <digit> <digit-tail>`),
+		[]byte(`<digit-tail> ::= <digit-seq> | ""`),
+		[]byte(`<parser> ::= "p"`),
+		[]byte(`; %start <digit-seq>`),
+	}
+	var grammar = analysis.NewGrammar(parser.DialectBNF, lines)
+	var code, err = Generate(LanguageGo, grammar, Options{Package: "digits"})
+	if err != nil {
+		t.Fatalf("failed to generate: %s", err)
+	}
+
+	var source = string(code)
+	for _, part := range []string{
+		"package digits\n",
+		"func (p *Parser) Parse() (*DigitSeq, error) {\n",
+		"// DigitSeq is a node of rule <digit-seq>.\n//\n" +
+			"// Sequence of digits.\n//\n" +
+			"//\t<digit-seq> ::= <digit> <digit-tail>\n" +
+			"type DigitSeq struct {\n",
+		"//\t<digit> ::= \"0\"\n//\t        | \"1\"\n",
+		"func (p *Parser) parseDigitTail() (*DigitTail, error) {\n" +
+			"\t// TODO: Alternative 1: <digit-seq>\n" +
+			"\t// TODO: Alternative 2: \"\"\n" +
+			"\treturn nil, p.errorf(\"rule <digit-tail> is not " +
+			"implemented\")\n}\n",
+		"type Parser2 struct {\n",
+	} {
+		if !strings.Contains(source, part) {
+			t.Errorf("there is no %q in code:\n%s", part, source)
+		}
+	}
+
+	// Parse functions follow depth-first order from start symbol.
+	var seq = strings.Index(source, "parseDigitSeq() (")
+	var digit = strings.Index(source, "parseDigit() (")
+	var tail = strings.Index(source, "parseDigitTail() (")
+	var other = strings.Index(source, "parseParser2() (")
+	if !(seq < digit && digit < tail && tail < other) {
+		t.Errorf("wrong order of parse functions: %d %d %d %d", seq, digit,
+			tail, other)
+	}
+
+	if _, err := Generate("rust", grammar, Options{}); err == nil {
+		t.Errorf("unknown language is accepted")
+	}
+	if _, err := Generate(LanguageGo, &analysis.Grammar{},
+		Options{}); err != ErrNoRules {
+		t.Errorf("wrong error: %v", err)
+	}
+}
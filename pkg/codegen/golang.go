@@ -0,0 +1,148 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strings"
+	"unicode"
+)
+
+// goHeader is a runtime of generated parser in Go: parser state and helpers
+// which parse functions are built of.
+const goHeader = `
+// Parser is a recursive descent parser of the grammar. It keeps input and the
+// current position in it.
+type Parser struct {
+	input []byte
+	pos   int
+}
+
+// NewParser creates a parser of input.
+func NewParser(input []byte) *Parser {
+	return &Parser{input: input}
+}
+
+// literal consumes text if input continues with it.
+func (p *Parser) literal(text string) bool {
+	if !bytes.HasPrefix(p.input[p.pos:], []byte(text)) {
+		return false
+	}
+	p.pos += len(text)
+	return true
+}
+
+// errorf returns an error at the current position of input.
+func (p *Parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("offset %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+`
+
+// generateGo renders parser skeleton in Go. The first symbol is the start
+// one.
+func generateGo(symbols []*symbol, opts Options) ([]byte, error) {
+	var pkg = opts.Package
+	if pkg == "" {
+		pkg = "parser"
+	}
+
+	var names = goNames(symbols)
+	var start = symbols[0]
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by nvim-bnf codegen. " +
+		"Bodies of parse functions are to be written.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", pkg)
+	buf.WriteString("import (\n\t\"bytes\"\n\t\"fmt\"\n)\n")
+	buf.WriteString(goHeader)
+
+	fmt.Fprintf(&buf, "\n// Parse parses the whole input as <%s>.\n",
+		start.name)
+	fmt.Fprintf(&buf, "func (p *Parser) Parse() (*%s, error) {\n",
+		names[start])
+	fmt.Fprintf(&buf, "\tvar node, err = p.parse%s()\n", names[start])
+	buf.WriteString("\tif err != nil {\n\t\treturn nil, err\n\t}\n")
+	buf.WriteString("\tif p.pos != len(p.input) {\n")
+	buf.WriteString("\t\treturn nil, p.errorf(\"unexpected input\")\n\t}\n")
+	buf.WriteString("\treturn node, nil\n}\n")
+
+	for _, sym := range symbols {
+		writeGoSymbol(&buf, sym, names[sym])
+	}
+
+	return format.Source(buf.Bytes())
+}
+
+// writeGoSymbol writes type of node of symbol and its parse function.
+func writeGoSymbol(buf *bytes.Buffer, sym *symbol, name string) {
+	var alts = sym.alternatives()
+
+	fmt.Fprintf(buf, "\n// %s is a node of rule <%s>.\n", name, sym.name)
+	if doc := sym.doc(); doc != "" {
+		buf.WriteString("//\n")
+		for _, line := range strings.Split(doc, "\n") {
+			fmt.Fprintf(buf, "// %s\n", line)
+		}
+	}
+	buf.WriteString("//\n")
+	for idx, alt := range alts {
+		var lhs = "<" + sym.name + "> ::="
+		if idx > 0 {
+			lhs = strings.Repeat(" ", len(sym.name)+3) + "|"
+		}
+		fmt.Fprintf(buf, "//\t%s %s\n", lhs, strings.Join(alt, " "))
+	}
+	fmt.Fprintf(buf, "type %s struct {\n", name)
+	buf.WriteString("\t// TODO: Add fields for terms of alternatives.\n}\n")
+
+	fmt.Fprintf(buf, "\n// parse%s parses rule <%s>.\n", name, sym.name)
+	fmt.Fprintf(buf, "func (p *Parser) parse%s() (*%s, error) {\n", name,
+		name)
+	for idx, alt := range alts {
+		fmt.Fprintf(buf, "\t// TODO: Alternative %d: %s\n", idx+1,
+			strings.Join(alt, " "))
+	}
+	fmt.Fprintf(buf, "\treturn nil, p.errorf(%q)\n}\n",
+		"rule <"+sym.name+"> is not implemented")
+}
+
+// goNames assigns unique exported identifiers to symbols. Names of rules like
+// `digit-seq` are turned into identifiers like `DigitSeq`.
+func goNames(symbols []*symbol) map[*symbol]string {
+	var used = map[string]bool{"Parser": true, "NewParser": true}
+	var names = make(map[*symbol]string)
+	for _, sym := range symbols {
+		var base = goIdent(sym.name)
+		var name = base
+		for suffix := 2; used[name]; suffix++ {
+			name = fmt.Sprintf("%s%d", base, suffix)
+		}
+		used[name] = true
+		names[sym] = name
+	}
+	return names
+}
+
+// goIdent converts name of rule to exported identifier. Characters other
+// than letters and digits separate words.
+func goIdent(name string) string {
+	var ident []rune
+	var upper = true
+	for _, char := range name {
+		switch {
+		case unicode.IsLetter(char) || unicode.IsDigit(char):
+			if upper {
+				char = unicode.ToUpper(char)
+			}
+			ident = append(ident, char)
+			upper = false
+		default:
+			upper = true
+		}
+	}
+
+	if len(ident) == 0 || !unicode.IsLetter(ident[0]) {
+		ident = append([]rune("Rule"), ident...)
+	}
+	return string(ident)
+}
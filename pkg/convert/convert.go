@@ -0,0 +1,197 @@
+// Package convert rewrites grammars from one dialect into another one.
+package convert
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+// item is an element of a document. It is either a production rule, a
+// comment, or a blank line between them.
+type item struct {
+	name    string
+	rhs     *node
+	comment string
+	blank   bool
+}
+
+// Convert rewrites a grammar written in one dialect into another one. Comments
+// and blank lines between rules are preserved. Recursive rules like `<list>
+// ::= <item> | <item> <list>` are turned into repetitions if target dialect
+// supports them. Constructs which have no counterpart in target dialect (see
+// analysis.Grammar.Portability) are kept as is.
+func Convert(from, to parser.Dialect, source []byte) ([]byte, error) {
+	var syntax, ok = syntaxOf(to)
+	if !ok {
+		return nil, parser.ErrUnknownDialect
+	}
+
+	var items, err = read(from, source)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	if syntax.header != "" {
+		lines = append(lines, syntax.header, "")
+	}
+	var emitter = newEmitter(to, syntax, items)
+	return join(append(lines, render(emitter, items)...)), nil
+}
+
+// render renders items of a document in target dialect of emitter.
+func render(emitter *emitter, items []item) []string {
+	var syntax = emitter.syntax
+	var lines []string
+	for _, item := range items {
+		switch {
+		case item.blank:
+			lines = append(lines, "")
+		case item.rhs == nil:
+			lines = append(lines, syntax.renderComment(item.comment)...)
+		default:
+			var rhs = item.rhs
+			if syntax.repetition {
+				rhs = unrollRecursion(item.name, rhs)
+			}
+			lines = append(lines, emitter.renderRule(item.name, rhs)...)
+		}
+	}
+	return lines
+}
+
+// join joins lines of a document. Document ends with new line unless it is
+// empty.
+func join(lines []string) []byte {
+	if len(lines) == 0 {
+		return nil
+	}
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
+
+// read parses a document and splits it into items. Documents in single-line
+// dialects are parsed line by line.
+func read(dialect parser.Dialect, source []byte) ([]item, error) {
+	source = bytes.Replace(source, []byte("\r\n"), []byte{'\n'}, -1)
+	var lines = bytes.Split(source, []byte{'\n'})
+	if last := len(lines) - 1; len(lines[last]) == 0 {
+		lines = lines[:last]
+	}
+
+	if dialect.Multiline() {
+		return readDocument(dialect, lines)
+	}
+
+	var items []item
+	for idx, line := range lines {
+		var trimmed = bytes.TrimSpace(line)
+		switch {
+		case len(trimmed) == 0:
+			items = append(items, item{blank: true})
+			continue
+		case trimmed[0] == ';':
+			var text = string(trimmed[1:])
+			items = append(items, item{comment: strings.TrimSpace(text)})
+			continue
+		}
+
+		var ast, err = parser.ParseDialect(dialect, line)
+		if err == nil {
+			err = ast.Error()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", idx+1, err)
+		}
+
+		var lower = lowering{dialect, line}
+		for _, stmt := range ast.Statements() {
+			items = append(items, lower.statement(stmt))
+		}
+	}
+
+	return items, nil
+}
+
+// readDocument parses a document in multiline dialect as a whole. Blank lines
+// between statements are kept as blank items.
+func readDocument(dialect parser.Dialect, lines [][]byte) ([]item, error) {
+	var source, _ = parser.JoinLines(lines)
+	var ast, err = parser.ParseDialect(dialect, source)
+	if err == nil {
+		err = ast.Error()
+	}
+	if err != nil {
+		var diag = parser.NewDiagnostic(err)
+		return nil, fmt.Errorf("line %d: %s", diag.Start.Line+1, err)
+	}
+
+	var items []item
+	var lower = lowering{dialect, source}
+	var prev = -1
+
+	for _, stmt := range ast.Statements() {
+		var span parser.Range
+		if stmt.Rule != nil {
+			span.Begin = parser.Span(stmt.Rule.Left()).Begin
+			span.End = parser.Span(stmt.Rule.Right()).End
+		} else {
+			span = parser.Range{Begin: stmt.Comment.Begin,
+				End: stmt.Comment.End}
+		}
+
+		if prev >= 0 && prev < span.Begin &&
+			hasBlankLine(source[prev:span.Begin]) {
+			items = append(items, item{blank: true})
+		}
+		if span.End > prev {
+			prev = span.End
+		}
+
+		items = append(items, lower.statement(stmt))
+	}
+
+	return items, nil
+}
+
+// hasBlankLine reports whether a gap between statements contains a line
+// which consists of whitespaces only.
+func hasBlankLine(gap []byte) bool {
+	var lines = bytes.Split(gap, []byte{'\n'})
+	for idx := 1; idx < len(lines)-1; idx++ {
+		if len(bytes.TrimSpace(lines[idx])) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// stripComment removes delimiters of comment in any dialect. Leading
+// asterisks of lines of block comments are removed as well.
+func stripComment(text string) string {
+	var delimiters = [][2]string{
+		{"(*", "*)"}, {"/*", "*/"}, {"//", ""}, {"#", ""}, {";", ""},
+	}
+	for _, pair := range delimiters {
+		if !strings.HasPrefix(text, pair[0]) {
+			continue
+		}
+
+		text = strings.TrimPrefix(text, pair[0])
+		text = strings.TrimSuffix(text, pair[1])
+		if pair[1] == "" {
+			break
+		}
+
+		var lines = strings.Split(text, "\n")
+		for idx, line := range lines {
+			line = strings.TrimSpace(line)
+			lines[idx] = strings.TrimSpace(strings.TrimLeft(line, "*"))
+		}
+		text = strings.Join(lines, "\n")
+		break
+	}
+	return strings.TrimSpace(text)
+}
@@ -0,0 +1,163 @@
+package convert
+
+import (
+	"testing"
+
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+func TestConvert(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		from, to parser.Dialect
+		source   string
+		expected string
+	}{
+		{
+			name: "RightRecursion",
+			from: parser.DialectBNF,
+			to:   parser.DialectEBNF,
+			source: "; Lists.\n" +
+				"<list> ::= <item> | <item> <list>\n" +
+				"\n" +
+				"<opt> ::= \"\" | <item> \",\" <opt>\n",
+			expected: "(* Lists. *)\n" +
+				"list = item, {item} ;\n" +
+				"\n" +
+				"opt = {item, \",\"} ;\n",
+		},
+		{
+			name:     "LeftRecursion",
+			from:     parser.DialectBNF,
+			to:       parser.DialectANTLR,
+			source:   `<expr> ::= <term> | <expr> "+" <term>`,
+			expected: "expr : term ('+' term)* ;\n",
+		},
+		{
+			name: "AuxiliaryRules",
+			from: parser.DialectEBNF,
+			to:   parser.DialectBNF,
+			source: "args = [arg, {',', arg}] ;\n" +
+				"arg = 'a' | ('b' | 'c'), 'd' ;\n",
+			expected: "<args> ::= <args-1>\n" +
+				"<args-1> ::= <arg> <args-2> | \"\"\n" +
+				"<args-2> ::= \"\" | \",\" <arg> <args-2>\n" +
+				"<arg> ::= \"a\" | <arg-1> \"d\"\n" +
+				"<arg-1> ::= \"b\" | \"c\"\n",
+		},
+		{
+			name:   "Yacc",
+			from:   parser.DialectW3C,
+			to:     parser.DialectYacc,
+			source: `list ::= item+ ";"?`,
+			expected: "%%\n\nlist : item list_1 list_2 ;\n" +
+				"list_1 : %empty | list_1 item ;\n" +
+				"list_2 : ';' | %empty ;\n",
+		},
+		{
+			name:     "Predicate",
+			from:     parser.DialectPEG,
+			to:       parser.DialectPEG,
+			source:   `Char <- '\\' [nt] / !'\\' .`,
+			expected: "Char <- \"\\\\\" [nt] / !\"\\\\\" .\n",
+		},
+		{
+			name:     "Exception",
+			from:     parser.DialectW3C,
+			to:       parser.DialectPEG,
+			source:   `char ::= letter - 'x'`,
+			expected: "char <- !\"x\" letter\n",
+		},
+		{
+			name:     "Unportable",
+			from:     parser.DialectPEG,
+			to:       parser.DialectEBNF,
+			source:   `End <- !.`,
+			expected: "End = ? !. ? ;\n",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			var source = []byte(test.source)
+			var result, err = Convert(test.from, test.to, source)
+			if err != nil {
+				t.Fatalf("failed to convert: %s", err)
+			}
+			if string(result) != test.expected {
+				t.Errorf("wrong result:\n%s\nexpected:\n%s", result,
+					test.expected)
+			}
+		})
+	}
+}
+
+func TestConvertError(t *testing.T) {
+	var source = []byte("<a> ::= <b>\n<b> ::= \"\n")
+	var _, err = Convert(parser.DialectBNF, parser.DialectEBNF, source)
+	if err == nil {
+		t.Errorf("error is expected for malformed grammar")
+	} else if err.Error()[:7] != "line 2:" {
+		t.Errorf("wrong error: %s", err)
+	}
+}
+
+func TestEscape(t *testing.T) {
+	for _, literal := range []string{`\n\t`, `\\`, `\'"`, `\u0041`} {
+		if escaped := escape(unescape(literal), '\''); escaped != literal {
+			t.Errorf("wrong round trip of %s: %s", literal, escaped)
+		}
+	}
+}
+
+func TestRenderStub(t *testing.T) {
+	var testCases = []struct {
+		dialect  parser.Dialect
+		expected string
+	}{
+		{parser.DialectANTLR, "expr : ;"},
+		{parser.DialectBNF, `<expr> ::= ""`},
+		{parser.DialectEBNF, `expr = "" ;`},
+		{parser.DialectPEG, `expr <- ""`},
+		{parser.DialectYacc, "expr : %empty ;"},
+		{parser.Notation(parser.Operators{Definition: []string{"->"}}),
+			`<expr> -> ""`},
+	}
+
+	for _, testCase := range testCases {
+		var stub, err = RenderStub(testCase.dialect, "expr")
+		if err != nil {
+			t.Errorf("failed to render stub in %s: %s", testCase.dialect, err)
+		} else if stub != testCase.expected {
+			t.Errorf("wrong stub in %s: %s", testCase.dialect, stub)
+		}
+	}
+}
+
+func TestRenderPlaceholder(t *testing.T) {
+	var line, err = RenderPlaceholder(parser.DialectANTLR, "expr")
+	if err != nil {
+		t.Fatalf("failed to render placeholder: %s", err)
+	} else if line != "expr : 'TODO' ;" {
+		t.Errorf("wrong placeholder: %q", line)
+	}
+
+	line, _ = RenderPlaceholder(parser.DialectBNF, "expr")
+	if line != `<expr> ::= "TODO"` {
+		t.Errorf("wrong placeholder: %q", line)
+	}
+}
+
+func TestRenderSkeleton(t *testing.T) {
+	var line, col, err = RenderSkeleton(parser.DialectANTLR, "expr")
+	if err != nil {
+		t.Fatalf("failed to render skeleton: %s", err)
+	} else if line != "expr : ;" || col != 7 {
+		t.Errorf("wrong skeleton: %q at %d", line, col)
+	}
+
+	line, col, _ = RenderSkeleton(parser.DialectBNF, "expr")
+	if line != "<expr> ::= " || col != len(line) {
+		t.Errorf("wrong skeleton: %q at %d", line, col)
+	}
+}
@@ -0,0 +1,186 @@
+package convert
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+// keywordsANTLR are reserved words of ANTLR which could not be names of
+// rules.
+var keywordsANTLR = map[string]bool{
+	"catch": true, "channels": true, "finally": true, "fragment": true,
+	"grammar": true, "import": true, "lexer": true, "locals": true,
+	"mode": true, "options": true, "parser": true, "private": true,
+	"protected": true, "public": true, "returns": true, "throws": true,
+	"tokens": true,
+}
+
+// nameANTLR renders name of parser rule of ANTLR. Names of parser rules start
+// with lowercase letter since uppercase ones denote lexer rules, so leading
+// capitals are lowered like `XMLParser` becomes `xmlParser`.
+func nameANTLR(name string) string {
+	var ident = identifier(name)
+	var upper = 0
+	for upper < len(ident) && ident[upper] >= 'A' && ident[upper] <= 'Z' {
+		upper++
+	}
+	if upper > 1 && upper < len(ident) &&
+		ident[upper] >= 'a' && ident[upper] <= 'z' {
+		upper-- // The last capital begins the next word.
+	}
+	ident = strings.ToLower(ident[:upper]) + ident[upper:]
+
+	if ident == "" || ident[0] < 'a' || ident[0] > 'z' {
+		ident = "r" + ident
+	}
+	if keywordsANTLR[ident] {
+		ident += "_"
+	}
+	return ident
+}
+
+// nameBison renders name of symbol of Bison. Besides letters, digits, and
+// underscores, names of Bison could contain periods and dashes but they could
+// not start with a digit or a dash.
+func nameBison(name string) string {
+	var ident = strings.Map(func(char rune) rune {
+		switch {
+		case char >= 'a' && char <= 'z', char >= 'A' && char <= 'Z',
+			char >= '0' && char <= '9', char == '_', char == '.',
+			char == '-':
+			return char
+		default:
+			return '_'
+		}
+	}, name)
+	if ident == "" || ident[0] >= '0' && ident[0] <= '9' || ident[0] == '-' {
+		ident = "r" + ident
+	}
+	return ident
+}
+
+// Emit translates a grammar into input of a parser generator, i.e. ANTLR4
+// grammar (.g4) or Bison grammar (.y). Unlike Convert, the result is ready
+// to be fed to a generator. ANTLR grammar is named after name and names of
+// rules become names of parser rules. Since ANTLR requires a lexer rule for
+// every token, a grammar which refers to undefined rules is not emitted.
+// Bison grammar declares its start symbol, undefined symbols, and literals of
+// several characters as tokens, so that a scanner could return them.
+func Emit(from, to parser.Dialect, source []byte, name string) ([]byte, error) {
+	var items, err = read(from, source)
+	if err != nil {
+		return nil, err
+	}
+
+	var syntax = *syntaxes[to]
+	switch to {
+	case parser.DialectANTLR:
+		// Names of rules of ANTLR grammar are already conventional.
+		if from != parser.DialectANTLR {
+			syntax.name = nameANTLR
+		}
+		var emitter = newEmitter(to, &syntax, items)
+		if len(emitter.undefined) > 0 {
+			return nil, fmt.Errorf("rules are undefined: %s",
+				strings.Join(emitter.undefined, ", "))
+		}
+		if name = identifier(name); name == "" {
+			name = "Grammar"
+		}
+		var lines = []string{"grammar " + name + ";", ""}
+		return join(append(lines, render(emitter, items)...)), nil
+	case parser.DialectYacc:
+		syntax.name = nameBison
+		var emitter = newEmitter(to, &syntax, items)
+		var tokens = newTokenTable(emitter.names)
+		syntax.literal = tokens.literal
+		var rules = render(emitter, items)
+		var lines []string
+		for _, name := range emitter.undefined {
+			lines = append(lines, "%token "+emitter.name(name))
+		}
+		lines = append(lines, tokens.declarations()...)
+		if start := startSymbol(source, items); start != "" {
+			lines = append(lines, "%start "+emitter.name(start))
+		}
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, "%%", "")
+		return join(append(lines, rules...)), nil
+	default:
+		return nil, parser.ErrUnknownDialect
+	}
+}
+
+// startSymbol returns start symbol which is declared in a document or name of
+// the first rule.
+func startSymbol(source []byte, items []item) string {
+	var lines = bytes.Split(source, []byte{'\n'})
+	if start := analysis.StartDirective(lines); start != "" {
+		return start
+	}
+	for _, item := range items {
+		if item.rhs != nil {
+			return item.name
+		}
+	}
+	return ""
+}
+
+// tokenTable assigns names of tokens to literals of several characters which
+// Bison requires to be declared. Single characters are character literals
+// which need no declaration. Names of tokens never clash with used names of
+// symbols.
+type tokenTable struct {
+	names    map[string]string
+	literals []string
+	used     map[string]bool
+}
+
+func newTokenTable(used map[string]bool) *tokenTable {
+	return &tokenTable{names: make(map[string]string), used: used}
+}
+
+// literal quotes literal and registers it as a token if it has several
+// characters.
+func (t *tokenTable) literal(value string) string {
+	var quoted = quoteYacc(value)
+	if len(value) > 1 {
+		if _, ok := t.names[value]; !ok {
+			t.names[value] = t.reserve(value)
+			t.literals = append(t.literals, value)
+		}
+	}
+	return quoted
+}
+
+// reserve chooses name of token of literal. Keywords like `while` become
+// WHILE while other literals are numbered.
+func (t *tokenTable) reserve(value string) string {
+	var base = fmt.Sprintf("TOKEN_%d", len(t.literals)+1)
+	var ident = identifier(value)
+	if ident == value && (value[0] < '0' || value[0] > '9') {
+		base = strings.ToUpper(ident)
+	}
+
+	var name = base
+	for suffix := 2; t.used[name]; suffix++ {
+		name = fmt.Sprintf("%s_%d", base, suffix)
+	}
+	t.used[name] = true
+	return name
+}
+
+// declarations returns declarations of tokens in order of their appearance.
+func (t *tokenTable) declarations() []string {
+	var lines []string
+	for _, value := range t.literals {
+		lines = append(lines, "%token "+t.names[value]+" "+quoteYacc(value))
+	}
+	return lines
+}
@@ -0,0 +1,147 @@
+package convert
+
+import (
+	"testing"
+
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+func TestEmit(t *testing.T) {
+	var source = []byte(`; %start <Program>
+<Stmt> ::= "while" <Expr> "do" <Stmt> | <Expr> ";"
+<Program> ::= <Stmt> | <Stmt> <Program>
+<Expr> ::= "x" ":=" "1" | "grammar"
+<grammar> ::= "while"
+<GRAMMAR> ::= "x"
+`)
+
+	var testCases = []struct {
+		name     string
+		to       parser.Dialect
+		expected string
+	}{
+		{
+			name: "ANTLR",
+			to:   parser.DialectANTLR,
+			expected: `grammar calc;
+
+// %start <Program>
+stmt : ('while' expr 'do')* expr ';' ;
+program : stmt stmt* ;
+expr : 'x' ':=' '1' | 'grammar' ;
+grammar_ : 'while' ;
+grammar_2 : 'x' ;
+`,
+		},
+		{
+			name: "Bison",
+			to:   parser.DialectYacc,
+			expected: `%token WHILE "while"
+%token DO "do"
+%token TOKEN_3 ":="
+%token GRAMMAR_2 "grammar"
+%start Program
+
+%%
+
+/* %start <Program> */
+Stmt : "while" Expr "do" Stmt | Expr ';' ;
+Program : Stmt | Stmt Program ;
+Expr : 'x' ":=" '1' | "grammar" ;
+grammar : "while" ;
+GRAMMAR : 'x' ;
+`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			var result, err = Emit(parser.DialectBNF, test.to, source, "calc")
+			if err != nil {
+				t.Fatalf("failed to emit: %s", err)
+			}
+			if string(result) != test.expected {
+				t.Errorf("wrong result:\n%s\nexpected:\n%s", result,
+					test.expected)
+			}
+		})
+	}
+
+	if _, err := Emit(parser.DialectBNF, parser.DialectEBNF, source,
+		"calc"); err != parser.ErrUnknownDialect {
+		t.Errorf("wrong error: %v", err)
+	}
+}
+
+func TestEmitNames(t *testing.T) {
+	var source = []byte(`XMLParser = EOL, ZIP code, ZIP_code, rule, tail;
+EOL = "x";
+ZIP code = '"';
+ZIP_code = "it's";
+rule = "a\b" | "A";
+tail = Number | "while";
+`)
+
+	var testCases = []struct {
+		name     string
+		to       parser.Dialect
+		expected string
+	}{
+		{
+			name: "ANTLR",
+			to:   parser.DialectANTLR,
+			expected: `grammar names;
+
+xmlParser : eol zip_code zip_code_2 rule tail ;
+eol : 'x' ;
+zip_code : '"' ;
+zip_code_2 : 'it\'s' ;
+rule : 'a\\b' | 'A' ;
+tail : number | 'while' ;
+number : 'n' ;
+`,
+		},
+		{
+			name: "Bison",
+			to:   parser.DialectYacc,
+			expected: `%token Number
+%token TOKEN_1 "it's"
+%token TOKEN_2 "a\\b"
+%token WHILE "while"
+%start XMLParser
+
+%%
+
+XMLParser : EOL ZIP_code ZIP_code_2 rule tail ;
+EOL : 'x' ;
+ZIP_code : '"' ;
+ZIP_code_2 : "it's" ;
+rule : "a\\b" | 'A' ;
+tail : Number | "while" ;
+`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			var input = source
+			if test.to == parser.DialectANTLR {
+				input = append(input, "Number = \"n\";\n"...)
+			}
+			var result, err = Emit(parser.DialectEBNF, test.to, input,
+				"names")
+			if err != nil {
+				t.Fatalf("failed to emit: %s", err)
+			}
+			if string(result) != test.expected {
+				t.Errorf("wrong result:\n%s\nexpected:\n%s", result,
+					test.expected)
+			}
+		})
+	}
+
+	if _, err := Emit(parser.DialectEBNF, parser.DialectANTLR, source,
+		"names"); err == nil {
+		t.Errorf("undefined rule is not reported")
+	}
+}
@@ -0,0 +1,157 @@
+package convert
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+// maxClassSize is the largest number of characters of a character class which
+// is expanded into alternatives of literals.
+const maxClassSize = 128
+
+// Import translates input of a parser generator, i.e. ANTLR4 grammar (.g4) or
+// Bison grammar (.y), into plain BNF. Semantic actions are dropped by parsers
+// already while precedence annotations, lexer commands, and labels of
+// alternatives are dropped here since they do not affect language. Character
+// classes and ranges of at most maxClassSize characters are expanded into
+// alternatives of literals. Other constructs which have no counterpart in BNF
+// are kept as is like in Convert.
+func Import(from parser.Dialect, source []byte) ([]byte, error) {
+	var items, err = read(from, source)
+	if err != nil {
+		return nil, err
+	}
+
+	for idx := range items {
+		if items[idx].rhs != nil {
+			items[idx].rhs = plain(items[idx].rhs)
+		}
+	}
+	var syntax = syntaxes[parser.DialectBNF]
+	var emitter = newEmitter(parser.DialectBNF, syntax, items)
+	return join(render(emitter, items)), nil
+}
+
+// plain rewrites a subtree so that it could be expressed in BNF: special
+// sequences are removed and character classes are expanded.
+func plain(n *node) *node {
+	switch n.kind {
+	case kindSpecial:
+		return &node{kind: kindEmpty}
+	case kindClass:
+		var chars, ok = classChars(n.text)
+		if !ok {
+			return n
+		}
+		var choice = &node{kind: kindChoice, origin: n.origin}
+		for _, char := range chars {
+			choice.children = append(choice.children,
+				&node{kind: kindLiteral, text: string(char)})
+		}
+		if len(chars) == 1 {
+			return choice.children[0]
+		}
+		return choice
+	case kindSequence:
+		var terms = make([]*node, len(n.children))
+		for idx, child := range n.children {
+			terms[idx] = plain(child)
+		}
+		return newSequence(terms)
+	case kindChoice:
+		// Expanded classes are merged into alternatives of choice.
+		var choice = &node{kind: kindChoice, origin: n.origin}
+		for _, child := range n.children {
+			var alt = plain(child)
+			choice.children = append(choice.children, alt.alternatives()...)
+		}
+		return choice
+	}
+
+	for idx, child := range n.children {
+		if child != nil {
+			n.children[idx] = plain(child)
+		}
+	}
+	return n
+}
+
+// classEscapes are escape sequences in character sets of ANTLR.
+var classEscapes = map[byte]rune{
+	'n': '\n', 'r': '\r', 't': '\t', 'f': '\f', 'b': '\b',
+}
+
+// classChars lists characters of a character class like `a-z_` or a range
+// like `'a'..'z'`. It reports false if class is too large or it could not be
+// expanded, e.g. it contains Unicode escapes or properties.
+func classChars(text string) ([]rune, bool) {
+	if strings.HasPrefix(text, "'") {
+		var sep = strings.Index(text, "'..'")
+		if sep < 0 || !strings.HasSuffix(text, "'") {
+			return nil, false
+		}
+		var lo, hi = unescape(text[1:sep]), unescape(text[sep+4 : len(text)-1])
+		if utf8.RuneCountInString(lo) != 1 || utf8.RuneCountInString(hi) != 1 {
+			return nil, false
+		}
+		var first, _ = utf8.DecodeRuneInString(lo)
+		var last, _ = utf8.DecodeRuneInString(hi)
+		return charRange(nil, first, last)
+	}
+
+	var set []rune
+	for text != "" {
+		var char, ok = classChar(&text)
+		if !ok {
+			return nil, false
+		}
+		if len(text) < 2 || text[0] != '-' {
+			set = append(set, char)
+			continue
+		}
+
+		text = text[1:]
+		var last, _ = classChar(&text)
+		if set, ok = charRange(set, char, last); !ok {
+			return nil, false
+		}
+	}
+	return set, len(set) > 0 && len(set) <= maxClassSize
+}
+
+// classChar consumes a character of character set. Escape sequences are
+// resolved.
+func classChar(text *string) (rune, bool) {
+	var char, size = utf8.DecodeRuneInString(*text)
+	if char != '\\' {
+		*text = (*text)[size:]
+		return char, true
+	}
+	if len(*text) < 2 {
+		return 0, false
+	}
+
+	var escaped = (*text)[1]
+	*text = (*text)[2:]
+	switch {
+	case escaped == 'u' || escaped == 'p' || escaped == 'P':
+		return 0, false
+	case classEscapes[escaped] != 0:
+		return classEscapes[escaped], true
+	default:
+		return rune(escaped), true
+	}
+}
+
+// charRange appends characters from first to last inclusively.
+func charRange(set []rune, first, last rune) ([]rune, bool) {
+	if last < first || int(last-first)+len(set) >= maxClassSize {
+		return nil, false
+	}
+	for char := first; char <= last; char++ {
+		set = append(set, char)
+	}
+	return set, true
+}
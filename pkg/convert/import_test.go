@@ -0,0 +1,100 @@
+package convert
+
+import (
+	"testing"
+
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+func TestImport(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		from     parser.Dialect
+		source   string
+		expected string
+	}{
+		{
+			name: "ANTLR",
+			from: parser.DialectANTLR,
+			source: `grammar Calc;
+expr : expr '+' NUM # Add | NUM ;
+NUM : '0'..'3' [a\-] ;
+WS : [ \t] -> skip ;
+ANY : [\u0000-￿] ;
+`,
+			expected: `<expr> ::= <expr> "+" <NUM> | <NUM>
+<NUM> ::= <NUM-1> <NUM-2>
+<NUM-1> ::= "0" | "1" | "2" | "3"
+<NUM-2> ::= "a" | "-"
+<WS> ::= " " | "\t"
+<ANY> ::= [\u0000-￿]
+`,
+		},
+		{
+			name: "Names",
+			from: parser.DialectANTLR,
+			source: `grammar Names;
+grammar_ : a_b a_b_ ;
+a_b : 'x' ;
+a_b_ : 'y' ;
+`,
+			expected: `<grammar> ::= <a-b> <a-b-2>
+<a-b> ::= "x"
+<a-b-2> ::= "y"
+`,
+		},
+		{
+			name: "Bison",
+			from: parser.DialectYacc,
+			source: `%token NUM
+%%
+expr : expr '-' expr { $$ = $1 - $3; }
+     | '-' expr %prec NEG { $$ = -$2; }
+     | NUM
+     ;
+`,
+			expected: `<expr> ::= <expr> "-" <expr> | "-" <expr> | <NUM>
+`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			var result, err = Import(test.from, []byte(test.source))
+			if err != nil {
+				t.Fatalf("failed to import: %s", err)
+			}
+			if string(result) != test.expected {
+				t.Errorf("wrong result:\n%s\nexpected:\n%s", result,
+					test.expected)
+			}
+		})
+	}
+}
+
+func TestClassChars(t *testing.T) {
+	var testCases = []struct {
+		class    string
+		expected string
+		ok       bool
+	}{
+		{`a-c_`, "abc_", true},
+		{`\]\\\t`, "]\\\t", true},
+		{`'x'..'z'`, "xyz", true},
+		{`'\''..'\''`, "'", true},
+		{`A`, "A", true},
+		{``, "", false},
+		{`\p{L}`, "", false},
+		{`z-a`, "", false},
+		{`\u0000-￿`, "", false},
+		{`'a'..'zz'`, "", false},
+	}
+
+	for _, test := range testCases {
+		var chars, ok = classChars(test.class)
+		if ok != test.ok || string(chars) != test.expected {
+			t.Errorf("wrong characters of %s: %q, %v", test.class,
+				string(chars), ok)
+		}
+	}
+}
@@ -0,0 +1,281 @@
+package convert
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+// kind is a kind of node of dialect-independent right-hand side.
+type kind int
+
+const (
+	kindEmpty kind = iota
+	kindName
+	kindLiteral
+	kindClass
+	kindWildcard
+	kindSpecial
+	kindChoice
+	kindSequence
+	kindRepeat
+	kindPredicate
+	kindException
+)
+
+// node is a dialect-independent presentation of right-hand side of a rule.
+// Text is a name of non-terminal, value of literal, content of character
+// class or special sequence. Origin is a source text of node which is used if
+// node could not be expressed in target dialect. Repetition bounds are kept in
+// min and max where max is -1 if repetition is unbounded. The first child of
+// exception is nil if there is nothing to exclude from (e.g. `~'x'` in
+// ANTLR).
+type node struct {
+	kind     kind
+	text     string
+	origin   string
+	min, max int
+	negative bool
+	children []*node
+}
+
+// key returns textual presentation of a subtree which is used to compare
+// subtrees.
+func (n *node) key() string {
+	if n == nil {
+		return "nil"
+	}
+
+	var parts = []string{
+		strconv.Itoa(int(n.kind)), strconv.Quote(n.text),
+		strconv.Itoa(n.min), strconv.Itoa(n.max),
+		strconv.FormatBool(n.negative),
+	}
+	for _, child := range n.children {
+		parts = append(parts, child.key())
+	}
+	return "(" + strings.Join(parts, " ") + ")"
+}
+
+// references counts references to a non-terminal in a subtree.
+func (n *node) references(name string) int {
+	switch {
+	case n == nil:
+		return 0
+	case n.kind == kindName && n.text == name:
+		return 1
+	}
+
+	var count = 0
+	for _, child := range n.children {
+		count += child.references(name)
+	}
+	return count
+}
+
+// refs appends names of non-terminals of a subtree in order of their
+// appearance.
+func (n *node) refs(names []string) []string {
+	switch {
+	case n == nil:
+		return names
+	case n.kind == kindName:
+		return append(names, n.text)
+	}
+
+	for _, child := range n.children {
+		names = child.refs(names)
+	}
+	return names
+}
+
+// alternatives returns alternatives of a node. A node which is not a choice is
+// the only alternative.
+func (n *node) alternatives() []*node {
+	if n.kind == kindChoice {
+		return n.children
+	}
+	return []*node{n}
+}
+
+// terms returns terms of a node. A node which is not a sequence is the only
+// term.
+func (n *node) terms() []*node {
+	switch n.kind {
+	case kindSequence:
+		return n.children
+	case kindEmpty:
+		return nil
+	default:
+		return []*node{n}
+	}
+}
+
+// newSequence builds a sequence of terms. Nested sequences are flattened and
+// empty terms are skipped.
+func newSequence(terms []*node) *node {
+	var children []*node
+	for _, term := range terms {
+		children = append(children, term.terms()...)
+	}
+
+	switch len(children) {
+	case 0:
+		return &node{kind: kindEmpty}
+	case 1:
+		return children[0]
+	default:
+		return &node{kind: kindSequence, children: children}
+	}
+}
+
+// unrollRecursion turns rule which consists of a base alternative and a
+// directly recursive one into repetition. Right recursion `A ::= B | C A` is
+// turned into `{C} B` and left recursion `A ::= B | A C` is turned into `B
+// {C}`. Rule is returned as is if it does not match any of the patterns.
+func unrollRecursion(name string, rhs *node) *node {
+	var alts = rhs.alternatives()
+	if len(alts) != 2 || rhs.references(name) != 1 {
+		return rhs
+	}
+
+	var base, rec = alts[0], alts[1]
+	if base.references(name) != 0 {
+		base, rec = rec, base
+	}
+
+	var terms = rec.terms()
+	if len(terms) < 2 {
+		return rhs
+	}
+
+	var head, tail = terms[0], terms[len(terms)-1]
+	switch {
+	case tail.kind == kindName && tail.text == name:
+		var repeat = newRepeat(0, -1, newSequence(terms[:len(terms)-1]))
+		// Pattern `A ::= B | B A` is rendered as `B {B}` rather than `{B} B`.
+		if repeat.children[0].key() == base.key() {
+			return newSequence([]*node{base, repeat})
+		}
+		return newSequence([]*node{repeat, base})
+	case head.kind == kindName && head.text == name:
+		var repeat = newRepeat(0, -1, newSequence(terms[1:]))
+		return newSequence([]*node{base, repeat})
+	default:
+		return rhs
+	}
+}
+
+func newRepeat(min, max int, operand *node) *node {
+	return &node{
+		kind: kindRepeat, min: min, max: max, children: []*node{operand},
+	}
+}
+
+// lowering converts parse tree of some dialect to dialect-independent nodes.
+type lowering struct {
+	dialect parser.Dialect
+	source  []byte
+}
+
+func (l *lowering) statement(stmt *parser.Statement) item {
+	if stmt.Rule == nil {
+		var comment = stmt.Comment
+		var text = string(l.source[comment.Begin:comment.End])
+		return item{comment: stripComment(text)}
+	}
+
+	var name string
+	if lhs, ok := stmt.Rule.Left().(*parser.NonTerminal); ok {
+		name = string(lhs.Name)
+	}
+	return item{name: name, rhs: l.node(stmt.Rule.Right())}
+}
+
+func (l *lowering) node(root parser.Node) *node {
+	if root == nil {
+		return nil
+	}
+
+	var res = l.lower(root)
+	if span := parser.Span(root); span.Begin >= 0 {
+		res.origin = string(l.source[span.Begin:span.End])
+	}
+	return res
+}
+
+func (l *lowering) lower(root parser.Node) *node {
+	switch root := root.(type) {
+	case *parser.AlternativeExpression:
+		var choice = &node{kind: kindChoice}
+		for _, alt := range parser.Alternatives(root) {
+			choice.children = append(choice.children, l.node(alt))
+		}
+		return choice
+	case *parser.CompoundExpression:
+		return newSequence([]*node{
+			l.node(root.Left()), l.node(root.Right()),
+		})
+	case *parser.GroupExpression:
+		var operand = l.node(root.Left())
+		switch root.Kind {
+		case parser.GroupOptional:
+			return newRepeat(0, 1, operand)
+		case parser.GroupRepetition:
+			return newRepeat(0, -1, operand)
+		default:
+			return operand
+		}
+	case *parser.RepetitionExpression:
+		return newRepeat(root.Min, root.Max, l.node(root.Left()))
+	case *parser.PredicateExpression:
+		return &node{
+			kind:     kindPredicate,
+			negative: root.Negative,
+			children: []*node{l.node(root.Right())},
+		}
+	case *parser.ExceptionExpression:
+		return &node{
+			kind:     kindException,
+			children: []*node{l.node(root.Left()), l.node(root.Right())},
+		}
+	case *parser.Terminal:
+		return l.terminal(root)
+	case *parser.NonTerminal:
+		return &node{kind: kindName, text: string(root.Name)}
+	case *parser.CharacterClass:
+		return &node{kind: kindClass, text: string(root.Name)}
+	case *parser.Wildcard:
+		return &node{kind: kindWildcard}
+	case *parser.Epsilon:
+		return &node{kind: kindEmpty}
+	case *parser.SpecialSequence:
+		if root.Begin == root.End || string(root.Name) == "%empty" {
+			return &node{kind: kindEmpty}
+		}
+		return &node{kind: kindSpecial, text: string(root.Name)}
+	default:
+		return &node{kind: kindEmpty}
+	}
+}
+
+// terminal converts terminal to literal with escape sequences resolved. Tokens
+// of Yacc which are declared with identifiers are converted to names.
+func (l *lowering) terminal(term *parser.Terminal) *node {
+	var value = string(term.Name)
+	switch l.dialect {
+	case parser.DialectYacc:
+		if char := l.source[term.Begin]; char != '"' && char != '\'' {
+			return &node{kind: kindName, text: value}
+		}
+		value = unescape(value)
+	case parser.DialectANTLR, parser.DialectPEG:
+		value = unescape(value)
+	}
+
+	if value == "" {
+		return &node{kind: kindEmpty}
+	}
+	return &node{kind: kindLiteral, text: value}
+}
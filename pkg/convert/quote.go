@@ -0,0 +1,166 @@
+package convert
+
+import (
+	"strings"
+)
+
+// escapes maps characters of escape sequences like in C to their values.
+var escapes = map[byte]byte{
+	'n': '\n', 'r': '\r', 't': '\t', '\\': '\\', '\'': '\'', '"': '"',
+}
+
+// unescape resolves escape sequences of literal like in C. Unknown escape
+// sequences (e.g. `\u0041` in ANTLR) are kept as is.
+func unescape(literal string) string {
+	var builder strings.Builder
+	for idx := 0; idx < len(literal); idx++ {
+		var char = literal[idx]
+		if char == '\\' && idx+1 < len(literal) {
+			if value, ok := escapes[literal[idx+1]]; ok {
+				builder.WriteByte(value)
+				idx++
+				continue
+			}
+		}
+		builder.WriteByte(char)
+	}
+	return builder.String()
+}
+
+// escape is the inverse of unescape. Backslash is kept as is only if it
+// begins numeric escape sequence like `\u0041` which unescape keeps as is.
+// Otherwise, it is escaped since any other backslash begins escape sequence
+// in C and ANTLR (e.g. `\b` is a backspace).
+func escape(value string, quote byte) string {
+	var builder strings.Builder
+	for idx := 0; idx < len(value); idx++ {
+		switch char := value[idx]; char {
+		case '\\':
+			if numericEscape(value[idx:]) {
+				builder.WriteByte(char)
+			} else {
+				builder.WriteString(`\\`)
+			}
+		case '\n':
+			builder.WriteString(`\n`)
+		case '\r':
+			builder.WriteString(`\r`)
+		case '\t':
+			builder.WriteString(`\t`)
+		case quote:
+			builder.WriteByte('\\')
+			builder.WriteByte(char)
+		default:
+			builder.WriteByte(char)
+		}
+	}
+	return builder.String()
+}
+
+// numericEscape reports whether text starts with escape sequence of a code of
+// character, i.e. `\uXXXX` of ANTLR, `\xXX` or octal `\NNN` of C.
+func numericEscape(text string) bool {
+	switch {
+	case len(text) < 2:
+		return false
+	case text[1] >= '0' && text[1] <= '7':
+		return true
+	case text[1] == 'x':
+		return len(text) > 2 && isHexDigit(text[2])
+	case text[1] == 'u':
+		if len(text) < 6 {
+			return false
+		}
+		for idx := 2; idx < 6; idx++ {
+			if !isHexDigit(text[idx]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+func isHexDigit(char byte) bool {
+	return char >= '0' && char <= '9' || char >= 'a' && char <= 'f' ||
+		char >= 'A' && char <= 'F'
+}
+
+// controls replaces control characters which could not be placed in literals
+// of dialects without escape sequences.
+var controls = strings.NewReplacer("\n", `\n`, "\r", `\r`, "\t", `\t`)
+
+// quotePlain quotes literal in dialects without escape sequences. Double
+// quotes are preferred unless literal contains them. Control characters are
+// written as escape sequences like in C though it is not lossless.
+func quotePlain(value string) string {
+	value = controls.Replace(value)
+	if strings.ContainsRune(value, '"') {
+		return "'" + value + "'"
+	}
+	return `"` + value + `"`
+}
+
+// quoteC quotes literal with escape sequences like in C. Double quotes are
+// preferred unless literal contains them.
+func quoteC(value string) string {
+	if strings.ContainsRune(value, '"') && !strings.ContainsRune(value, '\'') {
+		return "'" + escape(value, '\'') + "'"
+	}
+	return `"` + escape(value, '"') + `"`
+}
+
+// quoteANTLR quotes literal in single quotes since it is the only quoting in
+// ANTLR.
+func quoteANTLR(value string) string {
+	return "'" + escape(value, '\'') + "'"
+}
+
+// quoteYacc quotes single characters as character literals and the rest as
+// string literals of Bison.
+func quoteYacc(value string) string {
+	if len(value) == 1 {
+		return "'" + escape(value, '\'') + "'"
+	}
+	return `"` + escape(value, '"') + `"`
+}
+
+// identifier turns a name into identifier which consists of letters, digits,
+// and underscores. Other characters are replaced with underscores.
+func identifier(name string) string {
+	return strings.Map(func(char rune) rune {
+		switch {
+		case char >= 'a' && char <= 'z', char >= 'A' && char <= 'Z',
+			char >= '0' && char <= '9', char == '_':
+			return char
+		default:
+			return '_'
+		}
+	}, name)
+}
+
+// nameBNF renders non-terminal of BNF. Name of non-terminal consists of
+// letters, digits, and hyphens, so underscores become hyphens unless they
+// are leading or trailing ones like in `rule_` of ANTLR.
+func nameBNF(name string) string {
+	var ident = strings.Trim(identifier(name), "_")
+	if ident == "" {
+		ident = "r"
+	}
+	return "<" + strings.Replace(ident, "_", "-", -1) + ">"
+}
+
+// nameEBNF renders meta identifier of EBNF. Spaces are allowed in meta
+// identifiers as well as hyphens and underscores.
+func nameEBNF(name string) string {
+	return strings.Map(func(char rune) rune {
+		switch {
+		case char >= 'a' && char <= 'z', char >= 'A' && char <= 'Z',
+			char >= '0' && char <= '9', char == '_', char == '-', char == ' ':
+			return char
+		default:
+			return '_'
+		}
+	}, name)
+}
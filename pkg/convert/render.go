@@ -0,0 +1,478 @@
+package convert
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+// syntax describes notation of target dialect. Dialects without operators and
+// brackets (i.e. BNF and Yacc) express groups, options, and repetitions with
+// auxiliary rules.
+type syntax struct {
+	header    string // line which precedes rules
+	define    string // separator of name and right-hand side
+	terminate string // terminator of rule
+	alternate string // separator of alternatives
+	concat    string // separator of terms
+	empty     string // empty alternative
+
+	// Dialect has operators `?`, `*`, and `+`.
+	operators bool
+	// Dialect has brackets `[ ... ]` and `{ ... }`.
+	brackets bool
+	// Dialect has no empty alternative so options are used instead.
+	noEmpty bool
+	// Dialect has repetitions which are preferred to recursion. Ordered
+	// choice of PEG changes meaning of recursion, so it is kept as is.
+	repetition bool
+
+	name    func(string) string
+	literal func(string) string
+	comment [2]string
+}
+
+var syntaxes = map[parser.Dialect]*syntax{
+	parser.DialectANTLR: {
+		define: " : ", terminate: " ;", alternate: " | ", concat: " ",
+		operators: true, repetition: true,
+		name: identifier, literal: quoteANTLR, comment: [2]string{"//", ""},
+	},
+	parser.DialectBNF: {
+		define: " ::= ", alternate: " | ", concat: " ", empty: `""`,
+		name: nameBNF, literal: quotePlain, comment: [2]string{";", ""},
+	},
+	parser.DialectEBNF: {
+		define: " = ", terminate: " ;", alternate: " | ", concat: ", ",
+		empty: `""`, brackets: true, noEmpty: true, repetition: true,
+		name: nameEBNF, literal: quotePlain, comment: [2]string{"(*", "*)"},
+	},
+	parser.DialectPEG: {
+		define: " <- ", alternate: " / ", concat: " ", operators: true,
+		name: identifier, literal: quoteC, comment: [2]string{"#", ""},
+	},
+	parser.DialectW3C: {
+		define: " ::= ", alternate: " | ", concat: " ", empty: `""`,
+		operators: true, noEmpty: true, repetition: true,
+		name: identifier, literal: quotePlain, comment: [2]string{"/*", "*/"},
+	},
+	parser.DialectYacc: {
+		header: "%%", define: " : ", terminate: " ;", alternate: " | ",
+		concat: " ", empty: "%empty",
+		name: identifier, literal: quoteYacc, comment: [2]string{"/*", "*/"},
+	},
+}
+
+// syntaxOf returns syntax of a dialect. Notations of BNF with custom
+// operators are rendered with the first spellings of their operators.
+func syntaxOf(dialect parser.Dialect) (*syntax, bool) {
+	if syntax, ok := syntaxes[dialect]; ok {
+		return syntax, true
+	}
+
+	var ops, ok = dialect.Operators()
+	if !ok {
+		return nil, false
+	}
+	var custom = *syntaxes[parser.DialectBNF]
+	custom.define = " " + ops.Definition[0] + " "
+	custom.alternate = " " + ops.Alternation[0] + " "
+	return &custom, true
+}
+
+// renderComment renders every line of a comment.
+func (s *syntax) renderComment(text string) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		var parts = []string{s.comment[0], strings.TrimSpace(line)}
+		if s.comment[1] != "" {
+			parts = append(parts, s.comment[1])
+		}
+		lines = append(lines, strings.TrimSpace(strings.Join(parts, " ")))
+	}
+	return lines
+}
+
+// emitter renders rules in target dialect. It introduces auxiliary rules
+// with unique names if it is needed. Names of rules are renamed one-to-one:
+// distinct names which are spelled the same in target dialect get numeric
+// suffixes.
+type emitter struct {
+	dialect parser.Dialect
+	syntax  *syntax
+	names   map[string]bool   // names which are taken in target dialect
+	renamed map[string]string // names in target dialect by source names
+
+	// Names of non-terminals which are referred to but never defined.
+	undefined []string
+
+	rule  string   // name of the current rule in source dialect
+	count int      // number of auxiliary rules of the current rule
+	aux   []string // definitions of auxiliary rules
+}
+
+func newEmitter(
+	dialect parser.Dialect, syntax *syntax, items []item,
+) *emitter {
+	var e = &emitter{
+		dialect: dialect,
+		syntax:  syntax,
+		names:   make(map[string]bool),
+		renamed: make(map[string]string),
+	}
+
+	// Defined rules take their names first, so that references to undefined
+	// ones get suffixes on collision.
+	var refs []string
+	for _, item := range items {
+		if item.rhs != nil {
+			e.name(item.name)
+			refs = item.rhs.refs(refs)
+		}
+	}
+	for _, ref := range refs {
+		if _, ok := e.renamed[ref]; !ok {
+			e.undefined = append(e.undefined, ref)
+			e.name(ref)
+		}
+	}
+	return e
+}
+
+// name renders name of a rule in target dialect. The same source name is
+// always rendered in the same way while distinct ones never clash.
+func (e *emitter) name(source string) string {
+	if name, ok := e.renamed[source]; ok {
+		return name
+	}
+
+	var name = e.syntax.name(source)
+	var base = strings.TrimRight(source, "_")
+	for suffix := 2; e.names[name]; suffix++ {
+		name = e.syntax.name(base + "_" + strconv.Itoa(suffix))
+	}
+	e.names[name] = true
+	e.renamed[source] = name
+	return name
+}
+
+// renderRule renders a rule followed by its auxiliary rules.
+func (e *emitter) renderRule(name string, rhs *node) []string {
+	e.rule = name
+	e.count = 0
+	e.aux = nil
+
+	var line = e.renderDefinition(e.name(name), rhs)
+	return append([]string{line}, e.aux...)
+}
+
+func (e *emitter) renderDefinition(name string, rhs *node) string {
+	var line = name + e.syntax.define + e.renderChoice(rhs)
+	return strings.TrimRight(line, " ") + e.syntax.terminate
+}
+
+// auxiliary defines an auxiliary rule and returns reference to it.
+func (e *emitter) auxiliary(rhs *node) string {
+	return e.define(e.reserve(), rhs)
+}
+
+// renderChoice renders node in context where alternatives do not require
+// parentheses.
+func (e *emitter) renderChoice(n *node) string {
+	var alts = n.alternatives()
+	var empty = false
+	var parts []string
+
+	for _, alt := range alts {
+		if alt.kind == kindEmpty && e.syntax.noEmpty {
+			empty = true
+			continue
+		}
+		parts = append(parts, e.renderSequence(alt))
+	}
+
+	if empty && len(parts) > 0 {
+		var rest = &node{kind: kindChoice}
+		for _, alt := range alts {
+			if alt.kind != kindEmpty {
+				rest.children = append(rest.children, alt)
+			}
+		}
+		if len(rest.children) == 1 {
+			rest = rest.children[0]
+		}
+		return e.renderTerm(newRepeat(0, 1, rest))
+	} else if empty {
+		return e.syntax.empty
+	}
+
+	// Separator is trimmed next to empty alternatives.
+	var result = parts[0]
+	for idx := 1; idx < len(parts); idx++ {
+		var sep = e.syntax.alternate
+		if parts[idx-1] == "" {
+			sep = strings.TrimLeft(sep, " ")
+		}
+		if parts[idx] == "" {
+			sep = strings.TrimRight(sep, " ")
+		}
+		result += sep + parts[idx]
+	}
+	return result
+}
+
+func (e *emitter) renderSequence(n *node) string {
+	if n.kind == kindEmpty {
+		return e.syntax.empty
+	}
+
+	var parts []string
+	for _, term := range n.terms() {
+		if part := e.renderTerm(term); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, e.syntax.concat)
+}
+
+// renderTerm renders node in context of a term of sequence.
+func (e *emitter) renderTerm(n *node) string {
+	switch n.kind {
+	case kindEmpty:
+		return e.syntax.empty
+	case kindName:
+		return e.name(n.text)
+	case kindLiteral:
+		return e.syntax.literal(n.text)
+	case kindClass:
+		if e.syntax.operators {
+			return "[" + n.text + "]"
+		}
+	case kindWildcard:
+		if e.dialect == parser.DialectANTLR || e.dialect == parser.DialectPEG {
+			return "."
+		}
+	case kindChoice, kindSequence:
+		return e.renderGroup(n)
+	case kindRepeat:
+		return e.renderRepeat(n)
+	case kindPredicate:
+		if e.dialect == parser.DialectPEG {
+			return predicateOperator(n) + e.renderOperand(n.children[0])
+		}
+	case kindException:
+		if rendered, ok := e.renderException(n); ok {
+			return rendered
+		}
+	case kindSpecial:
+		// Labels of alternatives in ANTLR do not affect language.
+		if strings.HasPrefix(n.origin, "#") &&
+			e.dialect != parser.DialectANTLR {
+			return ""
+		}
+	}
+	return e.renderOrigin(n)
+}
+
+// renderGroup renders choice or sequence which is a term of sequence.
+func (e *emitter) renderGroup(n *node) string {
+	switch {
+	case n.kind == kindSequence:
+		return e.renderSequence(n)
+	case e.syntax.brackets || e.syntax.operators:
+		return "(" + e.renderChoice(n) + ")"
+	default:
+		return e.auxiliary(n)
+	}
+}
+
+// renderOperand renders node in context of operand of unary operator.
+func (e *emitter) renderOperand(n *node) string {
+	switch n.kind {
+	case kindChoice, kindSequence, kindRepeat, kindPredicate, kindException:
+		return "(" + e.renderChoice(n) + ")"
+	default:
+		return e.renderTerm(n)
+	}
+}
+
+func (e *emitter) renderRepeat(n *node) string {
+	var operand = n.children[0]
+	var min, max = n.min, n.max
+
+	// Bounded repetitions other than options are expanded into sequence of
+	// mandatory terms followed by options or unbounded repetition.
+	if min > 1 || max > 1 {
+		var terms []*node
+		for idx := 0; idx < min; idx++ {
+			terms = append(terms, operand)
+		}
+		if max < 0 {
+			terms = append(terms, newRepeat(0, -1, operand))
+		}
+		for idx := min; idx < max; idx++ {
+			terms = append(terms, newRepeat(0, 1, operand))
+		}
+		return e.renderSequence(&node{kind: kindSequence, children: terms})
+	}
+
+	switch {
+	case min == 1 && max == 1:
+		return e.renderTerm(operand)
+	case e.syntax.operators:
+		return e.renderOperand(operand) + repeatOperator(min, max)
+	case e.syntax.brackets && max == 1:
+		return "[" + e.renderChoice(operand) + "]"
+	case e.syntax.brackets && min == 0:
+		return "{" + e.renderChoice(operand) + "}"
+	case e.syntax.brackets:
+		return e.renderTerm(operand) + e.syntax.concat +
+			"{" + e.renderChoice(operand) + "}"
+	}
+
+	// Dialects without repetitions use auxiliary rules.
+	var empty = &node{kind: kindEmpty}
+	var self = &node{kind: kindName}
+	var alts []*node
+
+	switch {
+	case max == 1:
+		alts = []*node{operand, empty}
+	case e.dialect == parser.DialectYacc:
+		// Left recursion is preferred by LALR parsers.
+		alts = []*node{empty, newSequence([]*node{self, operand})}
+	default:
+		alts = []*node{empty, newSequence([]*node{operand, self})}
+	}
+
+	// Name of auxiliary rule is not known in advance, so it is set after
+	// name is allocated.
+	var name = e.reserve()
+	self.text = name
+	var rule = e.define(name, &node{kind: kindChoice, children: alts})
+
+	if min == 1 {
+		return e.renderTerm(operand) + e.syntax.concat + rule
+	}
+	return rule
+}
+
+// reserve allocates unique name for auxiliary rule. Name is unique in
+// target dialect.
+func (e *emitter) reserve() string {
+	for {
+		e.count++
+		var name = e.rule + "_" + strconv.Itoa(e.count)
+		var _, taken = e.renamed[name]
+		if rendered := e.syntax.name(name); !taken && !e.names[rendered] {
+			e.names[rendered] = true
+			e.renamed[name] = rendered
+			return name
+		}
+	}
+}
+
+// define defines auxiliary rule with reserved name and returns reference to
+// it. Definition of the rule precedes definitions of auxiliary rules which it
+// refers to.
+func (e *emitter) define(name string, rhs *node) string {
+	var idx = len(e.aux)
+	var rendered = e.name(name)
+	e.aux = append(e.aux, "")
+	e.aux[idx] = e.renderDefinition(rendered, rhs)
+	return rendered
+}
+
+func (e *emitter) renderException(n *node) (string, bool) {
+	var left, right = n.children[0], n.children[1]
+	switch {
+	case e.dialect == parser.DialectPEG && left != nil:
+		return "!" + e.renderOperand(right) + " " + e.renderOperand(left), true
+	case e.dialect == parser.DialectANTLR && left == nil:
+		return "~" + e.renderOperand(right), true
+	case e.dialect == parser.DialectEBNF && left != nil,
+		e.dialect == parser.DialectW3C && left != nil:
+		return e.renderOperand(left) + " - " + e.renderOperand(right), true
+	default:
+		return "", false
+	}
+}
+
+// renderOrigin renders source text of node which could not be expressed in
+// target dialect. In EBNF it is a special sequence.
+func (e *emitter) renderOrigin(n *node) string {
+	if e.dialect == parser.DialectEBNF && !strings.HasPrefix(n.origin, "?") {
+		return "? " + n.origin + " ?"
+	}
+	return n.origin
+}
+
+func predicateOperator(n *node) string {
+	if n.negative {
+		return "!"
+	}
+	return "&"
+}
+
+func repeatOperator(min, max int) string {
+	switch {
+	case max == 1:
+		return "?"
+	case min == 0:
+		return "*"
+	default:
+		return "+"
+	}
+}
+
+// RenderStub renders a rule of a dialect which has empty right-hand side. It
+// is a placeholder for a definition of an undefined non-terminal.
+func RenderStub(dialect parser.Dialect, name string) (string, error) {
+	var syntax, ok = syntaxOf(dialect)
+	if !ok {
+		return "", parser.ErrUnknownDialect
+	}
+
+	// Parsing expression could not be empty, so empty literal is used.
+	var empty = syntax.empty
+	if dialect == parser.DialectPEG {
+		empty = syntax.literal("")
+	}
+
+	var line = syntax.name(name) + syntax.define + empty
+	return strings.TrimRight(line, " ") + syntax.terminate, nil
+}
+
+// RenderPlaceholder renders a rule of a dialect which right-hand side is a
+// literal TODO. Unlike stub, it is easy to find placeholders which are left.
+func RenderPlaceholder(dialect parser.Dialect, name string) (string, error) {
+	var syntax, ok = syntaxOf(dialect)
+	if !ok {
+		return "", parser.ErrUnknownDialect
+	}
+
+	var line = syntax.name(name) + syntax.define + syntax.literal("TODO")
+	return line + syntax.terminate, nil
+}
+
+// Alternation returns operator which separates alternatives in a dialect.
+func Alternation(dialect parser.Dialect) string {
+	if syntax, ok := syntaxOf(dialect); ok {
+		return strings.TrimSpace(syntax.alternate)
+	}
+	return "|"
+}
+
+// RenderSkeleton renders a beginning of a rule of a dialect which right-hand
+// side is to be typed. It returns the line and byte offset where right-hand
+// side starts.
+func RenderSkeleton(dialect parser.Dialect, name string) (string, int, error) {
+	var syntax, ok = syntaxOf(dialect)
+	if !ok {
+		return "", 0, parser.ErrUnknownDialect
+	}
+
+	var head = syntax.name(name) + syntax.define
+	return head + strings.TrimLeft(syntax.terminate, " "), len(head), nil
+}
@@ -0,0 +1,296 @@
+// Package docgen renders reference documentation of a grammar, so a language
+// specification could be published from the same source file. Every rule is
+// rendered with its source, documentation comments, and links to rules which
+// it references and which reference it.
+package docgen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+	"github.com/daskol/nvim-bnf/pkg/format"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+// Format is an output format of documentation.
+type Format string
+
+// Supported formats of documentation.
+const (
+	FormatMarkdown Format = "markdown"
+	FormatHTML     Format = "html"
+)
+
+// ErrUnknownFormat is returned if output format is not supported.
+var ErrUnknownFormat = errors.New("docgen: unknown format")
+
+// Entry is documentation of a rule. References and ReferencedBy are names of
+// rules in lexicographical order without duplicates.
+type Entry struct {
+	Name         string
+	Anchor       string
+	Source       string
+	Doc          string
+	Deprecation  *analysis.Deprecation
+	References   []string
+	ReferencedBy []string
+}
+
+// Page is documentation of a grammar. Entries are in order of definitions of
+// rules in a document.
+type Page struct {
+	Title   string
+	Dialect parser.Dialect
+	Start   string
+	Entries []*Entry
+
+	defined map[string]*Entry
+}
+
+// NewPage collects documentation of rules of a document. The first
+// definition of a rule wins if it is defined several times.
+func NewPage(title string, dialect parser.Dialect, lines [][]byte) *Page {
+	var grammar = analysis.NewGrammar(dialect, lines)
+	var page = &Page{
+		Title:   title,
+		Dialect: dialect,
+		Start:   grammar.Start,
+		defined: make(map[string]*Entry),
+	}
+
+	var rules []*analysis.Rule
+	var anchors = make(map[string]bool)
+	for _, rule := range grammar.Rules {
+		if _, ok := page.defined[rule.Name]; ok {
+			continue
+		}
+
+		// Distinct names could map to the same anchor, e.g. `a-b` and `a_b`.
+		var id = anchor(rule.Name)
+		for suffix := 2; anchors[id]; suffix++ {
+			id = fmt.Sprintf("%s-%d", anchor(rule.Name), suffix)
+		}
+		anchors[id] = true
+
+		var entry = &Entry{
+			Name:        rule.Name,
+			Anchor:      id,
+			Source:      ruleSource(dialect, lines, rule),
+			Doc:         rule.Doc(),
+			Deprecation: rule.Deprecation,
+		}
+		page.Entries = append(page.Entries, entry)
+		page.defined[rule.Name] = entry
+		rules = append(rules, rule)
+	}
+
+	for idx, rule := range rules {
+		var entry = page.Entries[idx]
+		var seen = make(map[string]bool)
+		for _, name := range rule.References() {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			entry.References = append(entry.References, name)
+			if ref, ok := page.defined[name]; ok && name != rule.Name {
+				ref.ReferencedBy = append(ref.ReferencedBy, rule.Name)
+			}
+		}
+	}
+
+	for _, entry := range page.Entries {
+		sort.Strings(entry.References)
+		sort.Strings(entry.ReferencedBy)
+	}
+	return page
+}
+
+// Index returns entries in lexicographical order of names of rules.
+func (p *Page) Index() []*Entry {
+	var entries = append([]*Entry{}, p.Entries...)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+	return entries
+}
+
+// Render renders documentation of a document in a format.
+func Render(
+	output Format, title string, dialect parser.Dialect, lines [][]byte,
+) ([]byte, error) {
+	var page = NewPage(title, dialect, lines)
+	switch output {
+	case FormatMarkdown:
+		return page.Markdown(), nil
+	case FormatHTML:
+		return page.HTML(), nil
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+// Markdown renders documentation as a Markdown document. Rules are rendered
+// in fenced code blocks and anchors of rules are explicit HTML anchors, so
+// links work with any renderer.
+func (p *Page) Markdown() []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# %s\n\n", p.Title)
+	if p.Start != "" {
+		fmt.Fprintf(&buf, "Start symbol is %s.\n\n", p.markdownLink(p.Start))
+	}
+
+	buf.WriteString("## Index\n\n")
+	for _, entry := range p.Index() {
+		fmt.Fprintf(&buf, "- %s\n", p.markdownLink(entry.Name))
+	}
+
+	buf.WriteString("\n## Rules\n")
+	for _, entry := range p.Entries {
+		fmt.Fprintf(&buf, "\n<a id=\"%s\"></a>\n\n### `%s`\n\n", entry.Anchor,
+			entry.Name)
+		if entry.Doc != "" {
+			fmt.Fprintf(&buf, "%s\n\n", entry.Doc)
+		}
+		if entry.Deprecation != nil {
+			fmt.Fprintf(&buf, "> **Deprecated.** %s\n\n",
+				entry.Deprecation.Note)
+		}
+		fmt.Fprintf(&buf, "```%s\n%s\n```\n", p.Dialect, entry.Source)
+		if len(entry.References) > 0 {
+			fmt.Fprintf(&buf, "\nReferences: %s\n",
+				p.markdownLinks(entry.References))
+		}
+		if len(entry.ReferencedBy) > 0 {
+			fmt.Fprintf(&buf, "\nReferenced by: %s\n",
+				p.markdownLinks(entry.ReferencedBy))
+		}
+	}
+	return buf.Bytes()
+}
+
+// markdownLink renders name of rule as a link to its definition. Undefined
+// rules are not links.
+func (p *Page) markdownLink(name string) string {
+	if entry, ok := p.defined[name]; ok {
+		return fmt.Sprintf("[`%s`](#%s)", name, entry.Anchor)
+	}
+	return "`" + name + "`"
+}
+
+func (p *Page) markdownLinks(names []string) string {
+	var links = make([]string, len(names))
+	for idx, name := range names {
+		links[idx] = p.markdownLink(name)
+	}
+	return strings.Join(links, ", ")
+}
+
+// HTML renders documentation as a standalone HTML document.
+func (p *Page) HTML() []byte {
+	var title = html.EscapeString(p.Title)
+	var buf bytes.Buffer
+	buf.WriteString("<!DOCTYPE html>\n<html>\n<head>\n")
+	buf.WriteString("<meta charset=\"utf-8\">\n")
+	fmt.Fprintf(&buf, "<title>%s</title>\n", title)
+	buf.WriteString("</head>\n<body>\n")
+	fmt.Fprintf(&buf, "<h1>%s</h1>\n", title)
+	if p.Start != "" {
+		fmt.Fprintf(&buf, "<p>Start symbol is %s.</p>\n", p.htmlLink(p.Start))
+	}
+
+	buf.WriteString("<h2>Index</h2>\n<ul>\n")
+	for _, entry := range p.Index() {
+		fmt.Fprintf(&buf, "<li>%s</li>\n", p.htmlLink(entry.Name))
+	}
+	buf.WriteString("</ul>\n<h2>Rules</h2>\n")
+
+	for _, entry := range p.Entries {
+		fmt.Fprintf(&buf, "<h3 id=\"%s\"><code>%s</code></h3>\n",
+			entry.Anchor, html.EscapeString(entry.Name))
+		for _, paragraph := range strings.Split(entry.Doc, "\n") {
+			if paragraph != "" {
+				fmt.Fprintf(&buf, "<p>%s</p>\n", html.EscapeString(paragraph))
+			}
+		}
+		if entry.Deprecation != nil {
+			fmt.Fprintf(&buf, "<p><strong>Deprecated.</strong> %s</p>\n",
+				html.EscapeString(entry.Deprecation.Note))
+		}
+		fmt.Fprintf(&buf, "<pre><code class=\"language-%s\">%s</code>"+
+			"</pre>\n", p.Dialect, html.EscapeString(entry.Source))
+		if len(entry.References) > 0 {
+			fmt.Fprintf(&buf, "<p>References: %s</p>\n",
+				p.htmlLinks(entry.References))
+		}
+		if len(entry.ReferencedBy) > 0 {
+			fmt.Fprintf(&buf, "<p>Referenced by: %s</p>\n",
+				p.htmlLinks(entry.ReferencedBy))
+		}
+	}
+	buf.WriteString("</body>\n</html>\n")
+	return buf.Bytes()
+}
+
+// htmlLink renders name of rule as a link to its definition. Undefined rules
+// are not links.
+func (p *Page) htmlLink(name string) string {
+	var code = "<code>" + html.EscapeString(name) + "</code>"
+	if entry, ok := p.defined[name]; ok {
+		return fmt.Sprintf("<a href=\"#%s\">%s</a>", entry.Anchor, code)
+	}
+	return code
+}
+
+func (p *Page) htmlLinks(names []string) string {
+	var links = make([]string, len(names))
+	for idx, name := range names {
+		links[idx] = p.htmlLink(name)
+	}
+	return strings.Join(links, ", ")
+}
+
+// anchor derives identifier of an anchor of a rule from its name. Characters
+// other than letters and digits are replaced with hyphens.
+func anchor(name string) string {
+	var id = []rune("rule-")
+	for _, char := range strings.ToLower(name) {
+		switch {
+		case char >= 'a' && char <= 'z', char >= '0' && char <= '9':
+			id = append(id, char)
+		default:
+			id = append(id, '-')
+		}
+	}
+	return string(id)
+}
+
+// ruleSource returns source text of a rule. Rules of single-line dialects are
+// formatted canonically while rules of multi-line dialects are taken as is
+// from the first to the last line of a rule.
+func ruleSource(
+	dialect parser.Dialect, lines [][]byte, rule *analysis.Rule,
+) string {
+	if !dialect.Multiline() {
+		return string(format.Line(dialect, lines[rule.Line]))
+	}
+
+	var _, index = parser.JoinLines(lines)
+	var expr = rule.Statement.Rule
+	var first, _ = index.Locate(parser.Span(expr.Left()).Begin)
+	var last, _ = index.Locate(parser.Span(expr.Right()).End)
+	if last >= len(lines) {
+		last = len(lines) - 1
+	}
+
+	var source []string
+	for _, line := range lines[first : last+1] {
+		source = append(source, strings.TrimRight(string(line), " \t"))
+	}
+	return strings.Join(source, "\n")
+}
@@ -0,0 +1,115 @@
+package docgen
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+var lines = [][]byte{
+	[]byte(`; %start <expr>`),
+	[]byte(`; Sum of terms.`),
+	[]byte(`<expr>  ::= <term> | <expr> "+" <term>`),
+	[]byte(`; @deprecated use <expr>`),
+	[]byte(`<term> ::= <digit> | <ident>`),
+	[]byte(`<digit> ::= "0" | "1"`),
+	[]byte(`<Digit> ::= "0"`),
+}
+
+func TestNewPage(t *testing.T) {
+	var page = NewPage("calc", parser.DialectBNF, lines)
+	if page.Start != "expr" {
+		t.Errorf("wrong start symbol: %s", page.Start)
+	}
+
+	var expr = page.Entries[0]
+	var expected = &Entry{
+		Name:       "expr",
+		Anchor:     "rule-expr",
+		Source:     `<expr> ::= <term> | <expr> "+" <term>`,
+		Doc:        "Sum of terms.",
+		References: []string{"expr", "term"},
+	}
+	if !reflect.DeepEqual(expr, expected) {
+		t.Errorf("wrong entry:\n%+v\n%+v", expr, expected)
+	}
+
+	var term = page.Entries[1]
+	if term.Deprecation == nil || term.Deprecation.Replacement != "expr" {
+		t.Errorf("deprecation is not documented: %+v", term)
+	}
+	if !reflect.DeepEqual(term.ReferencedBy, []string{"expr"}) {
+		t.Errorf("wrong referrers: %v", term.ReferencedBy)
+	}
+
+	var anchors []string
+	for _, entry := range page.Entries[2:] {
+		anchors = append(anchors, entry.Anchor)
+	}
+	if !reflect.DeepEqual(anchors, []string{"rule-digit", "rule-digit-2"}) {
+		t.Errorf("wrong anchors: %v", anchors)
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	var doc, err = Render(FormatMarkdown, "calc", parser.DialectBNF, lines)
+	if err != nil {
+		t.Fatalf("failed to render: %s", err)
+	}
+
+	for _, part := range []string{
+		"# calc\n",
+		"- [`Digit`](#rule-digit-2)\n",
+		"<a id=\"rule-term\"></a>\n\n### `term`\n",
+		"Sum of terms.\n\n```bnf\n<expr> ::= <term>",
+		"> **Deprecated.** use <expr>\n",
+		"References: [`digit`](#rule-digit), `ident`\n",
+		"Referenced by: [`term`](#rule-term)\n",
+	} {
+		if !strings.Contains(string(doc), part) {
+			t.Errorf("there is no %q in document:\n%s", part, doc)
+		}
+	}
+}
+
+func TestHTML(t *testing.T) {
+	var doc, err = Render(FormatHTML, "calc", parser.DialectBNF, lines)
+	if err != nil {
+		t.Fatalf("failed to render: %s", err)
+	}
+
+	for _, part := range []string{
+		"<title>calc</title>",
+		"<h3 id=\"rule-expr\"><code>expr</code></h3>",
+		"&lt;expr&gt; ::= &lt;term&gt;",
+		"<a href=\"#rule-term\"><code>term</code></a>",
+	} {
+		if !strings.Contains(string(doc), part) {
+			t.Errorf("there is no %q in document:\n%s", part, doc)
+		}
+	}
+
+	if _, err := Render("pdf", "calc", parser.DialectBNF, lines); err == nil {
+		t.Errorf("unknown format is rendered")
+	}
+}
+
+func TestRuleSourceMultiline(t *testing.T) {
+	var lines = [][]byte{
+		[]byte(`(* Letters. *)`),
+		[]byte(`s = "a"`),
+		[]byte(`  | "b" ;   `),
+	}
+	var page = NewPage("s", parser.DialectEBNF, lines)
+	if len(page.Entries) != 1 {
+		t.Fatalf("wrong number of entries: %d", len(page.Entries))
+	}
+	if src := page.Entries[0].Source; src != "s = \"a\"\n  | \"b\" ;" {
+		t.Errorf("wrong source: %q", src)
+	}
+	if doc := page.Entries[0].Doc; doc != "Letters." {
+		t.Errorf("wrong doc: %q", doc)
+	}
+}
@@ -0,0 +1,70 @@
+// Package explain contains short educational notes on diagnostics. Notes are
+// looked up by code of diagnostic and they are aimed at students who are
+// learning how to write grammars.
+package explain
+
+import (
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+	"github.com/daskol/nvim-bnf/pkg/i18n"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+// notes maps codes of diagnostics to explanations in English. Every note
+// should be listed among messages of package i18n.
+var notes = map[string]string{
+	parser.CodeUnknown: "Parser could not recognize the statement. " +
+		"Check that it has the form of a production rule.",
+	parser.CodeUnexpectedChar: "Tokens of a rule are non-terminals, " +
+		"terminals and operators of the dialect. Any other character " +
+		"breaks the rule.",
+	parser.CodeUnexpectedEOL: "The rule ended too early. An operator or " +
+		"a quote is probably left without its operand or closing pair.",
+	parser.CodeEmptyRule: "A rule needs a right-hand side. Write an empty " +
+		"terminal explicitly if the rule should match nothing.",
+	parser.CodeNoStatements: "A grammar is a list of production rules " +
+		"which define non-terminals in terms of other symbols.",
+	parser.CodeInvalidBounds: "Repetition matches from the lower bound " +
+		"to the upper bound copies of its operand, so the lower bound " +
+		"could not exceed the upper one.",
+	parser.CodeUnclosedGroup: "A group opened with ( needs a matching ) " +
+		"on the same line. Close it or remove the bracket.",
+	analysis.CodePredicate: "Predicates &e and !e of PEG look ahead " +
+		"without consuming input. Context-free notations have no way " +
+		"to express them.",
+	analysis.CodeWildcard: "Wildcard matches any single character. " +
+		"Other notations need explicit alternatives of characters.",
+	analysis.CodeCharacterClass: "Character class is a shorthand for " +
+		"alternatives of single characters. Other notations need to " +
+		"list them.",
+	analysis.CodeException: "Exception a - b matches strings of a which " +
+		"are not matched by b. It is not context-free in general.",
+	analysis.CodeLeftRecursion: "A recursive descent parser calls a rule " +
+		"to match its own first symbol, so a left-recursive rule " +
+		"never consumes input and loops forever. Rewrite A ::= A b | c " +
+		"as repetition c {b}.",
+	analysis.CodeNonProductive: "Every derivation of the rule keeps a " +
+		"non-terminal which never turns into terminals. Add an " +
+		"alternative which does not depend on the rule itself.",
+	analysis.CodeUnreachable: "No derivation of the start rule, the " +
+		"first one, ever reaches the rule. Reference it or remove it.",
+	analysis.CodeDuplicateRule: "A non-terminal is defined by several " +
+		"rules. Join their right-hand sides with alternation unless " +
+		"repeated definitions are meant to be alternatives.",
+	analysis.CodeDuplicateAlternative: "The same alternative is written " +
+		"twice in a rule. It adds nothing to the language, so remove " +
+		"one of them.",
+	analysis.CodeUndefined: "The non-terminal is referenced but no rule " +
+		"defines it. Define it or fix a typo in its name.",
+	analysis.CodeCommonPrefix: "Alternatives start with the same symbol, " +
+		"so a parser could not choose one of them by the next token. " +
+		"Factor out the common prefix into an auxiliary rule.",
+}
+
+// Note returns translated explanation of a diagnostic code. It returns empty
+// string if there is no note for the code.
+func Note(code string) string {
+	if note, ok := notes[code]; ok {
+		return i18n.T(note)
+	}
+	return ""
+}
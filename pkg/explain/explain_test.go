@@ -0,0 +1,38 @@
+package explain
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+	"github.com/daskol/nvim-bnf/pkg/i18n"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+func TestNote(t *testing.T) {
+	var codes = []string{
+		parser.CodeUnknown, parser.CodeUnexpectedChar,
+		parser.CodeUnexpectedEOL, parser.CodeEmptyRule,
+		parser.CodeNoStatements, analysis.CodePredicate,
+		analysis.CodeWildcard, analysis.CodeCharacterClass,
+		analysis.CodeException, analysis.CodeLeftRecursion,
+	}
+	for _, code := range codes {
+		if Note(code) == "" {
+			t.Errorf("there is no note for %s", code)
+		}
+	}
+
+	if note := Note("X999"); note != "" {
+		t.Errorf("note for unknown code: %s", note)
+	}
+
+	// Notes are translated, so they should be known to message catalog.
+	var msgids = i18n.Messages()
+	for code, note := range notes {
+		var idx = sort.SearchStrings(msgids, note)
+		if idx == len(msgids) || msgids[idx] != note {
+			t.Errorf("note for %s is missing in catalog", code)
+		}
+	}
+}
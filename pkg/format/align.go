@@ -0,0 +1,206 @@
+package format
+
+import (
+	"bytes"
+	"unicode/utf8"
+
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+// alignRules pads left-hand sides of adjacent rules of a single-line dialect,
+// so that their definition operators are in the same column and their bodies
+// start at the same column. Blank lines and comments separate blocks of
+// rules which are aligned independently.
+func alignRules(dialect parser.Dialect, lines [][]byte, style Style) {
+	var block []int
+	var flush = func() {
+		var width, column = 0, style.Column
+		for _, idx := range block {
+			var lhs, op, _ = operator(dialect, lines[idx])
+			if lhs := utf8.RuneCount(lines[idx][:lhs]); width < lhs {
+				width = lhs
+			}
+			if body := width + 1 + utf8.RuneCount(op) + 1; column < body {
+				column = body
+			}
+		}
+		for _, idx := range block {
+			lines[idx] = alignRule(dialect, lines[idx], width, column)
+		}
+		block = block[:0]
+	}
+
+	for idx, line := range lines {
+		if _, _, ok := operator(dialect, line); ok {
+			block = append(block, idx)
+		} else {
+			flush()
+		}
+	}
+	flush()
+}
+
+// alignRule renders a formatted rule with its left-hand side padded to width
+// and its body started at column.
+func alignRule(dialect parser.Dialect, line []byte, width, column int) []byte {
+	var lhs, op, _ = operator(dialect, line)
+	var body = bytes.TrimPrefix(line[lhs+1+len(op):], []byte{' '})
+
+	var result = append([]byte{}, line[:lhs]...)
+	result = append(result, spaces(width-utf8.RuneCount(line[:lhs])+1)...)
+	result = append(result, op...)
+	if len(body) == 0 {
+		return result
+	}
+	result = append(result, spaces(column-width-1-utf8.RuneCount(op))...)
+	return append(result, body...)
+}
+
+// operator returns end of left-hand side and definition operator of a line
+// which is a single production rule formatted by Line.
+func operator(dialect parser.Dialect, line []byte) (int, []byte, bool) {
+	var ast, err = parser.ParseDialect(dialect, line)
+	if err != nil || ast.Error() != nil {
+		return 0, nil, false
+	}
+
+	var stmts = ast.Statements()
+	if len(stmts) != 1 || stmts[0].Rule == nil {
+		return 0, nil, false
+	}
+
+	var lhs = parser.Span(stmts[0].Rule.Left())
+	return lhs.End, stmts[0].Rule.Name, true
+}
+
+// alignAlternatives puts every top-level alternative of rules of a multiline
+// dialect on its own line, so that alternation operators are right under the
+// definition operator of their rule and bodies of alternatives start at the
+// same column. Comments and layout of alternatives themselves are kept as
+// is. Document is not changed if it could not be parsed.
+func alignAlternatives(
+	dialect parser.Dialect, source []byte, style Style,
+) []byte {
+	var tree = parser.ParseLossless(dialect, source)
+	if tree.Error() != nil {
+		return source
+	}
+
+	var operators = make(map[parser.Node]int)
+	for idx, token := range tree.Tokens() {
+		if token.Kind == parser.TokenOperator {
+			operators[token.Node] = idx
+		}
+	}
+
+	var edits []parser.TextEdit
+	for _, stmt := range tree.Statements() {
+		if stmt.Rule == nil {
+			continue
+		}
+		var def, ok = operators[stmt.Rule]
+		if !ok {
+			continue
+		}
+
+		var ops = []int{def}
+		for _, alt := range alternatives(stmt.Rule.Right(), nil) {
+			if idx, ok := operators[alt]; ok {
+				ops = append(ops, idx)
+			}
+		}
+		edits = append(edits, alignRuleTokens(tree, ops, style)...)
+	}
+
+	var aligned, err = parser.ApplyEdits(source, edits)
+	if err != nil {
+		return source
+	}
+	return aligned
+}
+
+// alignRuleTokens returns edits of whitespaces around operators of a rule.
+// The first operator is the definition one and the rest are alternation
+// operators in order of their appearance.
+func alignRuleTokens(
+	tree *parser.SyntaxTree, ops []int, style Style,
+) []parser.TextEdit {
+	var tokens = tree.Tokens()
+	var def = tokens[ops[0]].Range
+	var begin = bytes.LastIndexByte(tree.Source()[:def.Begin], '\n') + 1
+	var indent = utf8.RuneCount(tree.Source()[begin:def.Begin])
+	var column = indent + utf8.RuneCount(tree.Text(tokens[ops[0]])) + 1
+	if column < style.Column {
+		column = style.Column
+	}
+
+	var edits []parser.TextEdit
+	for pos, idx := range ops {
+		if pos > 0 {
+			var before = blank(tokens, idx, -1)
+			var end = tokens[idx].Range.Begin
+			var text = bytes.Repeat([]byte{' '}, indent+1)
+			text[0] = '\n'
+			edits = append(edits, parser.TextEdit{
+				Range: parser.Range{Begin: before, End: end},
+				Text:  text,
+			})
+		}
+
+		// Body of an empty alternative is whitespace before the next
+		// operator which is already replaced.
+		var after = blank(tokens, idx, 1)
+		var next = tokens[idx].Range.End
+		if after == len(tree.Source()) || pos+1 < len(ops) &&
+			after == tokens[ops[pos+1]].Range.Begin {
+			continue
+		}
+		var width = utf8.RuneCount(tree.Text(tokens[idx]))
+		edits = append(edits, parser.TextEdit{
+			Range: parser.Range{Begin: next, End: after},
+			Text:  spaces(column - indent - width),
+		})
+	}
+	return edits
+}
+
+// alternatives collects alternation operators of the top level of a rule,
+// i.e. ones which are not enclosed in groups.
+func alternatives(node parser.Node, alts []parser.Node) []parser.Node {
+	if alt, ok := node.(*parser.AlternativeExpression); ok {
+		alts = alternatives(alt.Left(), alts)
+		alts = append(alts, alt)
+		return alternatives(alt.Right(), alts)
+	}
+	return alts
+}
+
+// blank returns offset where a run of spaces and line breaks which adjoins a
+// token from the left (dir < 0) or from the right (dir > 0) ends.
+func blank(tokens []parser.SyntaxToken, idx, dir int) int {
+	var offset = tokens[idx].Range.End
+	if dir < 0 {
+		offset = tokens[idx].Range.Begin
+	}
+	for idx += dir; idx >= 0 && idx < len(tokens); idx += dir {
+		var token = tokens[idx]
+		if token.Kind != parser.TokenSpace &&
+			token.Kind != parser.TokenNewline {
+			break
+		}
+		if dir < 0 {
+			offset = token.Range.Begin
+		} else {
+			offset = token.Range.End
+		}
+	}
+	return offset
+}
+
+// spaces returns padding of n spaces but at least one.
+func spaces(n int) []byte {
+	if n < 1 {
+		n = 1
+	}
+	return bytes.Repeat([]byte{' '}, n)
+}
@@ -0,0 +1,132 @@
+// Package format implements canonical layout of grammars which is enforced by
+// fmt subcommand.
+package format
+
+import (
+	"bytes"
+
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+// Source formats a document written in some dialect. Lines are formatted
+// independently and the document ends with exactly one new line. Byte order
+// mark and line breaks of the document are kept: all lines end with CRLF if
+// the first one does and with LF otherwise.
+func Source(dialect parser.Dialect, source []byte) []byte {
+	return SourceStyle(dialect, source, DefaultStyle)
+}
+
+// SourceStyle formats a document in the same way as Source does and then
+// lays rules out according to style.
+func SourceStyle(dialect parser.Dialect, source []byte, style Style) []byte {
+	var bom []byte
+	if bytes.HasPrefix(source, byteOrderMark) {
+		bom, source = byteOrderMark, source[len(byteOrderMark):]
+	}
+	var eol = LineBreak(source)
+
+	var lines = bytes.Split(source, []byte{'\n'})
+	for idx, line := range lines {
+		lines[idx] = Line(dialect, bytes.TrimSuffix(line, []byte{'\r'}))
+	}
+
+	switch {
+	case !style.Align:
+	case dialect.Multiline():
+		var source = bytes.Join(lines, []byte{'\n'})
+		source = alignAlternatives(dialect, source, style)
+		lines = bytes.Split(source, []byte{'\n'})
+	default:
+		alignRules(dialect, lines, style)
+	}
+
+	// Drop trailing blank lines.
+	var end = len(lines)
+	for end > 0 && len(lines[end-1]) == 0 {
+		end--
+	}
+
+	if end == 0 {
+		return nil
+	}
+
+	var formatted = append([]byte{}, bom...)
+	formatted = append(formatted, bytes.Join(lines[:end], eol)...)
+	return append(formatted, eol...)
+}
+
+// byteOrderMark is UTF-8 encoding of U+FEFF.
+var byteOrderMark = []byte{0xef, 0xbb, 0xbf}
+
+// LineBreak returns CRLF if the first line of a source ends with it and LF
+// otherwise.
+func LineBreak(source []byte) []byte {
+	if idx := bytes.IndexByte(source, '\n'); idx > 0 && source[idx-1] == '\r' {
+		return []byte{'\r', '\n'}
+	}
+	return []byte{'\n'}
+}
+
+// Line formats a single line. Trailing whitespaces are removed from every
+// line. Besides, production rules of single-line dialects are rendered with
+// exactly one space between lexemes. Lines which could not be parsed
+// semantically are kept as is.
+func Line(dialect parser.Dialect, line []byte) []byte {
+	line = bytes.TrimRight(line, " \t")
+	if dialect.Multiline() {
+		return line
+	}
+
+	var ast, err = parser.ParseDialect(dialect, line)
+	if err != nil || ast.Error() != nil {
+		return line
+	}
+
+	var stmts = ast.Statements()
+	if len(stmts) != 1 || stmts[0].Rule == nil {
+		return line
+	}
+
+	return renderRule(line, stmts[0].Rule)
+}
+
+// renderRule renders production rule with its lexemes and operators taken
+// from the source line as is in order to keep quoting. Lexemes are separated
+// with exactly one space except for ones right inside of parentheses.
+func renderRule(line []byte, rule *parser.AssignmentExpression) []byte {
+	var lhs = parser.Span(rule.Left())
+	var parts = [][]byte{line[lhs.Begin:lhs.End], rule.Name}
+	parts = lexemes(parts, line, rule.Right())
+
+	var result []byte
+	for idx, part := range parts {
+		if idx > 0 && string(parts[idx-1]) != "(" && string(part) != ")" {
+			result = append(result, ' ')
+		}
+		result = append(result, part...)
+	}
+	return result
+}
+
+// lexemes appends lexemes and operators of a subtree in order of their
+// appearance.
+func lexemes(parts [][]byte, line []byte, node parser.Node) [][]byte {
+	switch node := node.(type) {
+	case nil:
+		return parts
+	case *parser.Terminal, *parser.NonTerminal, *parser.Epsilon:
+		var span = parser.Span(node)
+		return append(parts, line[span.Begin:span.End])
+	case *parser.AlternativeExpression:
+		parts = lexemes(parts, line, node.Left())
+		parts = append(parts, node.Name)
+		return lexemes(parts, line, node.Right())
+	case *parser.GroupExpression:
+		parts = append(parts, []byte{'('})
+		parts = lexemes(parts, line, node.Left())
+		return append(parts, []byte{')'})
+	default:
+		parts = lexemes(parts, line, node.Left())
+		return lexemes(parts, line, node.Right())
+	}
+}
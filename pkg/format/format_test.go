@@ -0,0 +1,115 @@
+package format
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+func TestLine(t *testing.T) {
+	var cases = []struct {
+		input    string
+		expected string
+	}{
+		{`<a>::=<b>  "c"|'d'  `, `<a> ::= <b> "c" | 'd'`},
+		{`<a> ::= <b>`, `<a> ::= <b>`},
+		{`  ; comment  `, `  ; comment`},
+		{`<a> ::= | <b>`, `<a> ::= | <b>`},
+		{`<a>::=""|ε  |<empty>`, `<a> ::= "" | ε | <empty>`},
+		{`<a>::=( "x"|("y") )  <b>`, `<a> ::= ("x" | ("y")) <b>`},
+		{``, ``},
+	}
+
+	for _, c := range cases {
+		var actual = string(Line(parser.DialectBNF, []byte(c.input)))
+		if actual != c.expected {
+			t.Errorf("wrong formatting of %q: %q", c.input, actual)
+		}
+	}
+}
+
+func TestSource(t *testing.T) {
+	var source = []byte("<a> ::=  <b>\n\n<b>::=\"c\"  \n\n\n")
+	var expected = "<a> ::= <b>\n\n<b> ::= \"c\"\n"
+
+	if actual := string(Source(parser.DialectBNF, source)); actual != expected {
+		t.Errorf("wrong formatting: %q", actual)
+	}
+
+	if actual := Source(parser.DialectBNF, []byte("\n\n")); actual != nil {
+		t.Errorf("blank document is not empty: %q", actual)
+	}
+
+	source = []byte("a  ::=  b  \n")
+	var actual = string(Source(parser.DialectW3C, source))
+	if actual != "a  ::=  b\n" {
+		t.Errorf("wrong formatting of multiline dialect: %q", actual)
+	}
+}
+
+func TestSourceDeterministic(t *testing.T) {
+	var unix = []byte("<a>::=<b> | \"c\"  \n<b> ::= 'd'\n")
+	var windows = []byte("<a>::=<b> | \"c\"  \r\n<b> ::= 'd'\r\n")
+	var expected = Source(parser.DialectBNF, unix)
+
+	var crlf = bytes.Replace(expected, []byte{'\n'}, []byte("\r\n"), -1)
+	if actual := Source(parser.DialectBNF, windows); string(actual) !=
+		string(crlf) {
+		t.Errorf("line endings are not kept: %q", actual)
+	}
+
+	var bom = append([]byte("\xef\xbb\xbf"), windows...)
+	if actual := Source(parser.DialectBNF, bom); string(actual) !=
+		"\xef\xbb\xbf"+string(crlf) {
+		t.Errorf("byte order mark is not kept: %q", actual)
+	}
+
+	if again := Source(parser.DialectBNF, expected); string(again) !=
+		string(expected) {
+		t.Errorf("formatting is not idempotent: %q", again)
+	}
+}
+
+func TestSourceAligned(t *testing.T) {
+	var style = Style{Align: true}
+	var cases = []struct {
+		dialect  parser.Dialect
+		source   string
+		expected string
+	}{
+		{
+			parser.DialectBNF,
+			"<a> ::= <b> | <c>\n<long> ::= \"x\"\n\n<d> ::= <e>\n",
+			"<a>    ::= <b> | <c>\n<long> ::= \"x\"\n\n<d> ::= <e>\n",
+		},
+		{
+			parser.DialectEBNF,
+			"expr = term | expr, \"+\", term (* sum *) | ( a | b ) ;\n",
+			"expr = term\n     | expr, \"+\", term (* sum *)\n" +
+				"     | ( a | b ) ;\n",
+		},
+		{
+			parser.DialectW3C,
+			"a ::= b\n| c\n",
+			"a ::= b\n  |   c\n",
+		},
+	}
+
+	for _, c := range cases {
+		var actual = SourceStyle(c.dialect, []byte(c.source), style)
+		if string(actual) != c.expected {
+			t.Errorf("wrong alignment of %q: %q", c.source, actual)
+		}
+		if again := SourceStyle(c.dialect, actual, style); string(again) !=
+			string(actual) {
+			t.Errorf("alignment is not idempotent: %q", again)
+		}
+	}
+
+	style.Column = 12
+	var actual = SourceStyle(parser.DialectW3C, []byte("a ::= b | c\n"), style)
+	if string(actual) != "a ::=       b\n  |         c\n" {
+		t.Errorf("bodies are not padded to column: %q", actual)
+	}
+}
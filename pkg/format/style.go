@@ -0,0 +1,164 @@
+package format
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// EditorConfigName is a name of file with settings of editors which is looked
+// up in the directory of a grammar and its parents.
+const EditorConfigName = ".editorconfig"
+
+// Style is a layout of production rules.
+type Style struct {
+	// Align puts alternatives of a rule on separate lines with `|` right
+	// under its definition operator. Rules of single-line dialects could not
+	// span lines, so definition operators of adjacent rules are aligned
+	// instead.
+	Align bool
+	// Column is the smallest column where bodies of alternatives start in
+	// aligned style. Bodies start right after the operator if it is zero.
+	Column int
+}
+
+// DefaultStyle is compact layout where lexemes are separated with exactly one
+// space.
+var DefaultStyle = Style{}
+
+// styles are names of layouts which option bnf_format_style takes.
+var styles = map[string]bool{"compact": false, "aligned": true}
+
+// LookupStyle returns style by its name, i.e. compact or aligned.
+func LookupStyle(name string) (Style, bool) {
+	var align, ok = styles[strings.ToLower(name)]
+	return Style{Align: align}, ok
+}
+
+// LoadStyle reads style of a grammar file from .editorconfig files in its
+// directory and its parents up to the one which is marked as root. Sections
+// which match the file set options bnf_format_style (compact or aligned) and
+// bnf_format_column. Nearer files and later sections take precedence. Files
+// which could not be read are ignored.
+func LoadStyle(filename string) Style {
+	var path, err = filepath.Abs(filename)
+	if err != nil {
+		path = filename
+	}
+
+	var configs []string
+	for dir := filepath.Dir(path); ; {
+		var config = filepath.Join(dir, EditorConfigName)
+		if root, err := readEditorConfig(config, nil, ""); err == nil {
+			configs = append(configs, config)
+			if root {
+				break
+			}
+		}
+		var parent = filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	var opts = make(map[string]string)
+	for idx := len(configs) - 1; idx >= 0; idx-- {
+		readEditorConfig(configs[idx], opts, path)
+	}
+
+	var style = DefaultStyle
+	if value, ok := LookupStyle(opts["bnf_format_style"]); ok {
+		style.Align = value.Align
+	}
+	if column, err := strconv.Atoi(opts["bnf_format_column"]); err == nil &&
+		column > 0 {
+		style.Column = column
+	}
+	return style
+}
+
+// readEditorConfig reads options of sections of .editorconfig which match
+// path into opts. It reports whether the file is marked as root. Only
+// properties of preamble are read if opts is nil.
+func readEditorConfig(
+	config string, opts map[string]string, path string,
+) (bool, error) {
+	var file, err = os.Open(config)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	var root, preamble, matched = false, true, false
+	var scanner = bufio.NewScanner(file)
+	for scanner.Scan() {
+		var line = strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || line[0] == '#' || line[0] == ';':
+			continue
+		case line[0] == '[' && strings.HasSuffix(line, "]"):
+			if opts == nil {
+				return root, nil
+			}
+			preamble = false
+			matched = matchSection(line[1:len(line)-1], filepath.Dir(config),
+				path)
+			continue
+		}
+
+		var eq = strings.IndexByte(line, '=')
+		if eq < 0 {
+			continue
+		}
+		var key = strings.ToLower(strings.TrimSpace(line[:eq]))
+		var value = strings.TrimSpace(line[eq+1:])
+		switch {
+		case preamble && key == "root":
+			root = strings.EqualFold(value, "true")
+		case matched && opts != nil:
+			opts[key] = value
+		}
+	}
+	return root, scanner.Err()
+}
+
+// matchSection reports whether a glob of section of .editorconfig in
+// directory dir matches path. Globs without slashes match base names of
+// files. Only `*`, `?`, character classes, and lists of alternatives like
+// `*.{bnf,ebnf}` are supported.
+func matchSection(glob, dir, path string) bool {
+	var name = filepath.Base(path)
+	if strings.Contains(glob, "/") {
+		var rel, err = filepath.Rel(dir, path)
+		if err != nil {
+			return false
+		}
+		glob, name = strings.TrimPrefix(glob, "/"), filepath.ToSlash(rel)
+	}
+
+	for _, pattern := range expandBraces(glob) {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// expandBraces expands the first list of alternatives of a glob recursively.
+func expandBraces(glob string) []string {
+	var begin = strings.IndexByte(glob, '{')
+	var end = strings.IndexByte(glob, '}')
+	if begin < 0 || end < begin {
+		return []string{glob}
+	}
+
+	var globs []string
+	for _, alt := range strings.Split(glob[begin+1:end], ",") {
+		var expanded = glob[:begin] + alt + glob[end+1:]
+		globs = append(globs, expandBraces(expanded)...)
+	}
+	return globs
+}
@@ -0,0 +1,58 @@
+package format
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadStyle(t *testing.T) {
+	var root, err = ioutil.TempDir("", "nvim-bnf-format")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	var dir = filepath.Join(root, "spec")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	var configs = map[string]string{
+		root: "root = true\n\n[*.{bnf,ebnf}]\nbnf_format_style = aligned\n" +
+			"bnf_format_column = 16\n",
+		dir: "# Nearer file wins.\n[*.ebnf]\nbnf_format_style = compact\n",
+	}
+	for dir, content := range configs {
+		var filename = filepath.Join(dir, EditorConfigName)
+		var err = ioutil.WriteFile(filename, []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var cases = []struct {
+		filename string
+		expected Style
+	}{
+		{filepath.Join(root, "a.bnf"), Style{true, 16}},
+		{filepath.Join(dir, "a.bnf"), Style{true, 16}},
+		{filepath.Join(dir, "a.ebnf"), Style{false, 16}},
+		{filepath.Join(dir, "a.g4"), DefaultStyle},
+	}
+	for _, c := range cases {
+		if actual := LoadStyle(c.filename); actual != c.expected {
+			t.Errorf("wrong style of %s: %+v", c.filename, actual)
+		}
+	}
+}
+
+func TestLookupStyle(t *testing.T) {
+	if style, ok := LookupStyle("Aligned"); !ok || !style.Align {
+		t.Errorf("aligned style is not found: %+v", style)
+	}
+	if _, ok := LookupStyle("fancy"); ok {
+		t.Errorf("unknown style is found")
+	}
+}
@@ -0,0 +1,451 @@
+package highlighting
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+	"github.com/daskol/nvim-bnf/pkg/convert"
+	"github.com/daskol/nvim-bnf/pkg/i18n"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+	"github.com/neovim/go-client/nvim"
+)
+
+// Kinds of code actions.
+const (
+	ActionDefine    = "define"
+	ActionDefineAll = "define-all"
+	ActionRemove    = "remove"
+	ActionMerge     = "merge"
+	ActionReplace   = "replace-deprecated"
+)
+
+// Edit replaces zero-based half-open range of lines with new lines. Empty
+// range inserts lines before Begin.
+type Edit struct {
+	Begin int      `msgpack:"begin"`
+	End   int      `msgpack:"end"`
+	Lines []string `msgpack:"lines"`
+}
+
+// CodeAction is a quick fix which is applicable at cursor. Edits of an action
+// do not overlap.
+type CodeAction struct {
+	Kind  string `msgpack:"kind"`
+	Title string `msgpack:"title"`
+	Edits []Edit `msgpack:"edits"`
+}
+
+// CodeActions returns quick fixes which are applicable at zero-based line and
+// byte column of a document: definition of undefined non-terminal under
+// cursor, removal of a rule which is never referenced, merge of repeated
+// definitions of a non-terminal, and definition of all undefined
+// non-terminals with placeholders. References to a deprecated rule under
+// cursor are replaced with the rule which its annotation suggests.
+func CodeActions(
+	dialect parser.Dialect, lines [][]byte, row, col int,
+) []CodeAction {
+	var grammar = analysis.NewGrammar(dialect, lines)
+	var source, index = parser.JoinLines(lines)
+	if row < 0 || row >= len(index) {
+		return nil
+	}
+
+	var actions []CodeAction
+	var offset = index[row] + col
+	var rule = ruleAt(grammar, lines, row)
+
+	var name = referenceAt(dialect, grammar, index, offset)
+	if name != "" && !grammar.Defines(name) {
+		if action, ok := defineAction(dialect, grammar, lines, name,
+			rule); ok {
+			actions = append(actions, action)
+		}
+	}
+
+	if name == "" && rule != nil {
+		name = rule.Name
+	}
+	if action, ok := replaceAction(dialect, grammar, lines, index,
+		name); ok {
+		actions = append(actions, action)
+	}
+
+	if rule != nil {
+		actions = append(actions,
+			ruleActions(dialect, grammar, lines, source, index, rule)...)
+	}
+
+	if action, ok := defineAllAction(dialect, grammar, lines); ok {
+		actions = append(actions, action)
+	}
+
+	return actions
+}
+
+// ruleActions returns actions which are applicable to a rule under cursor.
+func ruleActions(
+	dialect parser.Dialect, grammar *analysis.Grammar, lines [][]byte,
+	source []byte, index parser.LineIndex, rule *analysis.Rule,
+) []CodeAction {
+	var refs = 0
+	var defs []*analysis.Rule
+	for _, other := range grammar.Rules {
+		if other.Name == rule.Name {
+			defs = append(defs, other)
+			continue
+		}
+		for _, name := range other.References() {
+			if name == rule.Name {
+				refs++
+			}
+		}
+	}
+
+	var actions []CodeAction
+	if refs == 0 && rule.Name != grammar.StartSymbol() {
+		var begin, end = ruleExtent(grammar, rule, lines)
+		actions = append(actions, CodeAction{
+			Kind:  ActionRemove,
+			Title: i18n.Sprintf("Remove unused rule <%s>", rule.Name),
+			Edits: []Edit{{Begin: begin, End: end}},
+		})
+	}
+
+	if len(defs) > 1 {
+		actions = append(actions,
+			mergeAction(dialect, grammar, lines, source, index, defs))
+	}
+
+	return actions
+}
+
+// referenceAt returns name of non-terminal on the right-hand side of a rule
+// which spans an offset in joined lines.
+func referenceAt(
+	dialect parser.Dialect, grammar *analysis.Grammar,
+	index parser.LineIndex, offset int,
+) string {
+	var name string
+	for _, rule := range grammar.Rules {
+		var base = ruleBase(dialect, index, rule)
+		var rhs = rule.Statement.Rule.Right()
+		parser.Walk(rhs, func(cursor *parser.Cursor) error {
+			var node, ok = cursor.Node.(*parser.NonTerminal)
+			if ok && base+node.Begin <= offset && offset < base+node.End {
+				name = string(node.Name)
+				return parser.StopWalk
+			}
+			return nil
+		}, nil)
+		if name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// ruleBase returns offset of a line of a rule in joined lines. Offsets of
+// rules of multiline dialects are relative to the whole document already.
+func ruleBase(
+	dialect parser.Dialect, index parser.LineIndex, rule *analysis.Rule,
+) int {
+	if dialect.Multiline() || rule.Line >= len(index) {
+		return 0
+	}
+	return index[rule.Line]
+}
+
+// defineAction inserts a stub of undefined non-terminal after the rule which
+// references it or at the end of document.
+func defineAction(
+	dialect parser.Dialect, grammar *analysis.Grammar, lines [][]byte,
+	name string, rule *analysis.Rule,
+) (CodeAction, bool) {
+	var stub, err = convert.RenderStub(dialect, name)
+	if err != nil {
+		return CodeAction{}, false
+	}
+
+	var at = len(lines)
+	if rule != nil {
+		_, at = ruleExtent(grammar, rule, lines)
+	}
+
+	return CodeAction{
+		Kind:  ActionDefine,
+		Title: i18n.Sprintf("Define <%s>", name),
+		Edits: []Edit{{Begin: at, End: at, Lines: []string{stub}}},
+	}, true
+}
+
+// defineAllAction appends placeholders of all undefined non-terminals to
+// the end of document. Placeholders are separated from rules with a blank
+// line.
+func defineAllAction(
+	dialect parser.Dialect, grammar *analysis.Grammar, lines [][]byte,
+) (CodeAction, bool) {
+	var names = grammar.Undefined()
+	if len(names) == 0 {
+		return CodeAction{}, false
+	}
+
+	var stubs []string
+	if n := len(lines); n > 0 && len(bytes.TrimSpace(lines[n-1])) > 0 {
+		stubs = append(stubs, "")
+	}
+
+	for _, name := range names {
+		var stub, err = convert.RenderPlaceholder(dialect, name)
+		if err != nil {
+			return CodeAction{}, false
+		}
+		stubs = append(stubs, stub)
+	}
+
+	var end = len(lines)
+	return CodeAction{
+		Kind:  ActionDefineAll,
+		Title: i18n.T("Define all undefined non-terminals"),
+		Edits: []Edit{{Begin: end, End: end, Lines: stubs}},
+	}, true
+}
+
+// replaceAction replaces all references to a deprecated rule with the rule
+// which is suggested by its annotation.
+func replaceAction(
+	dialect parser.Dialect, grammar *analysis.Grammar, lines [][]byte,
+	index parser.LineIndex, name string,
+) (CodeAction, bool) {
+	var def = grammar.Definition(name)
+	if def == nil || def.Deprecation == nil ||
+		def.Deprecation.Replacement == "" {
+		return CodeAction{}, false
+	}
+
+	// References are collected by lines, so every line is rewritten once.
+	var replacement = def.Deprecation.Replacement
+	var refs = make(map[int][]parser.Range)
+	var rows []int
+	for _, rule := range grammar.Rules {
+		var base = ruleBase(dialect, index, rule)
+		walkReferences(rule, name, func(node *parser.NonTerminal) {
+			var row, col = index.Locate(base + node.Begin)
+			if refs[row] == nil {
+				rows = append(rows, row)
+			}
+			refs[row] = append(refs[row],
+				parser.Range{Begin: col, End: col + node.End - node.Begin})
+		})
+	}
+
+	if len(rows) == 0 {
+		return CodeAction{}, false
+	}
+
+	var edits []Edit
+	for _, row := range rows {
+		var line = string(lines[row])
+		var ranges = refs[row]
+		sort.Slice(ranges, func(i, j int) bool {
+			return ranges[i].Begin > ranges[j].Begin
+		})
+		for _, rng := range ranges {
+			var token = strings.Replace(line[rng.Begin:rng.End], name,
+				replacement, 1)
+			line = line[:rng.Begin] + token + line[rng.End:]
+		}
+		edits = append(edits, Edit{Begin: row, End: row + 1,
+			Lines: []string{line}})
+	}
+
+	return CodeAction{
+		Kind:  ActionReplace,
+		Title: i18n.Sprintf("Replace <%s> with <%s>", name, replacement),
+		Edits: edits,
+	}, true
+}
+
+// walkReferences calls visitor on every reference to a non-terminal on the
+// right-hand side of a rule.
+func walkReferences(
+	rule *analysis.Rule, name string, visit func(*parser.NonTerminal),
+) {
+	var rhs = rule.Statement.Rule.Right()
+	parser.Walk(rhs, func(cursor *parser.Cursor) error {
+		if node, ok := cursor.Node.(*parser.NonTerminal); ok &&
+			string(node.Name) == name {
+			visit(node)
+		}
+		return nil
+	}, nil)
+}
+
+// mergeAction appends right-hand sides of repeated definitions to the first
+// definition as alternatives and removes the rest definitions.
+func mergeAction(
+	dialect parser.Dialect, grammar *analysis.Grammar, lines [][]byte,
+	source []byte, index parser.LineIndex, defs []*analysis.Rule,
+) CodeAction {
+	var alts []string
+	for _, def := range defs[1:] {
+		var span = parser.Span(def.Statement.Rule.Right())
+		var base = ruleBase(dialect, index, def)
+		var text = string(source[base+span.Begin : base+span.End])
+		alts = append(alts, strings.Join(strings.Fields(text), " "))
+	}
+
+	// Alternatives are inserted right after the last term of the first
+	// definition, so terminator of the rule is kept.
+	var first = defs[0]
+	var span = parser.Span(first.Statement.Rule.Right())
+	var row, col = index.Locate(ruleBase(dialect, index, first) + span.End)
+	var op = " " + convert.Alternation(dialect) + " "
+	var line = string(lines[row][:col]) + op + strings.Join(alts, op) +
+		string(lines[row][col:])
+
+	var edits = []Edit{{Begin: row, End: row + 1, Lines: []string{line}}}
+	for _, def := range defs[1:] {
+		var begin, end = ruleExtent(grammar, def, lines)
+		edits = append(edits, Edit{Begin: begin, End: end})
+	}
+
+	return CodeAction{
+		Kind:  ActionMerge,
+		Title: i18n.Sprintf("Merge definitions of <%s>", first.Name),
+		Edits: edits,
+	}
+}
+
+// ApplyEdits applies edits to lines of a document. It is the same as applying
+// them to a buffer.
+func ApplyEdits(lines []string, edits []Edit) []string {
+	for _, edit := range sortEdits(edits) {
+		var tail = append([]string{}, lines[edit.End:]...)
+		lines = append(append(lines[:edit.Begin], edit.Lines...), tail...)
+	}
+	return lines
+}
+
+// sortEdits orders edits from the bottom of document to its top, so applying
+// an edit does not shift lines of the rest edits.
+func sortEdits(edits []Edit) []Edit {
+	var sorted = append([]Edit{}, edits...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Begin > sorted[j].Begin
+	})
+	return sorted
+}
+
+// HandleCodeActions returns code actions which are applicable at cursor in
+// the current buffer.
+func (h *Highlighter) HandleCodeActions(args []interface{}) (
+	[]CodeAction, error,
+) {
+	logger.Debugf("HandleCodeActions(%v)", args)
+
+	var _, actions, err = h.codeActions()
+	return actions, err
+}
+
+// HandleApplyAction applies a code action which is applicable at cursor in
+// the current buffer. Action is specified by its index in the list returned
+// by BNFCodeActions or by its title.
+func (h *Highlighter) HandleApplyAction(args []interface{}) error {
+	logger.Debugf("HandleApplyAction(%v)", args)
+
+	if len(args) != 1 {
+		return newError(CodeInvalidArgs, "exactly one argument is expected")
+	}
+
+	var buf, actions, err = h.codeActions()
+	if err != nil {
+		return err
+	}
+
+	var action *CodeAction
+	switch arg := args[0].(type) {
+	case int64:
+		if arg >= 0 && int(arg) < len(actions) {
+			action = &actions[arg]
+		}
+	case string:
+		for idx := range actions {
+			if actions[idx].Title == arg {
+				action = &actions[idx]
+			}
+		}
+	default:
+		return newError(CodeInvalidArgs, "wrong argument type")
+	}
+
+	if action == nil {
+		return newError(CodeUnknownAction, "there is no such action")
+	}
+
+	var batch = h.nvim.NewBatch()
+	for _, edit := range sortEdits(action.Edits) {
+		var lines = make([][]byte, len(edit.Lines))
+		for idx, line := range edit.Lines {
+			lines[idx] = []byte(line)
+		}
+		batch.SetBufferLines(buf, edit.Begin, edit.End, true, lines)
+	}
+	return execute(batch)
+}
+
+// HandleDefineUndefinedCommand appends placeholders like `<foo> ::= "TODO"`
+// of all non-terminals which are referenced but never defined in the current
+// buffer, so a grammar could be sketched top-down.
+func (h *Highlighter) HandleDefineUndefinedCommand() error {
+	logger.Debugf("HandleDefineUndefinedCommand()")
+
+	var buf, err = CurrentBuffer(h.nvim)
+	if err != nil {
+		return err
+	}
+
+	lines, err := BufferLines(h.nvim, buf, 0, -1, true)
+	if err != nil {
+		return err
+	}
+
+	var grammar = analysis.NewGrammar(h.dialectOf(buf), lines)
+	var action, ok = defineAllAction(h.dialectOf(buf), grammar, lines)
+	if !ok {
+		return nil
+	}
+
+	var edit = action.Edits[0]
+	var stubs = make([][]byte, len(edit.Lines))
+	for idx, line := range edit.Lines {
+		stubs[idx] = []byte(line)
+	}
+	return SetBufferLines(h.nvim, buf, edit.Begin, edit.End, true, stubs)
+}
+
+// codeActions returns the current buffer and code actions at cursor.
+func (h *Highlighter) codeActions() (nvim.Buffer, []CodeAction, error) {
+	var buf, err = CurrentBuffer(h.nvim)
+	if err != nil {
+		return buf, nil, err
+	}
+
+	cursor, err := WindowCursor(h.nvim, 0)
+	if err != nil {
+		return buf, nil, err
+	}
+
+	lines, err := BufferLines(h.nvim, buf, 0, -1, true)
+	if err != nil {
+		return buf, nil, err
+	}
+
+	var actions = CodeActions(h.dialectOf(buf), lines, cursor[0]-1,
+		cursor[1])
+	if actions == nil {
+		actions = []CodeAction{}
+	}
+	return buf, actions, nil
+}
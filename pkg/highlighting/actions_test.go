@@ -0,0 +1,129 @@
+package highlighting
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+func TestCodeActions(t *testing.T) {
+	var source = []string{
+		`<expr> ::= <term> | <expr> "+" <term>`,
+		`<term> ::= <digit>`,
+		`<term> ::= "(" <expr> ")"`,
+		`<unused> ::= "x"`,
+		``,
+		`<digits> ::= <digit> <digits>`,
+	}
+
+	var testCases = []struct {
+		name     string
+		row, col int
+		kinds    string
+		expected []string
+	}{
+		{
+			name: "Define",
+			row:  1, col: 12,
+			kinds: "define merge define-all",
+			expected: []string{
+				`<expr> ::= <term> | <expr> "+" <term>`,
+				`<term> ::= <digit>`,
+				`<digit> ::= ""`,
+				`<term> ::= "(" <expr> ")"`,
+				`<unused> ::= "x"`,
+				``,
+				`<digits> ::= <digit> <digits>`,
+			},
+		},
+		{
+			name: "DefineAll",
+			row:  4, col: 0,
+			kinds: "define-all",
+			expected: append(append([]string{}, source...), ``,
+				`<digit> ::= "TODO"`),
+		},
+		{
+			name: "Remove",
+			row:  3, col: 0,
+			kinds: "remove define-all",
+			expected: []string{
+				`<expr> ::= <term> | <expr> "+" <term>`,
+				`<term> ::= <digit>`,
+				`<term> ::= "(" <expr> ")"`,
+				``,
+				`<digits> ::= <digit> <digits>`,
+			},
+		},
+		{
+			name: "Merge",
+			row:  2, col: 0,
+			kinds: "merge define-all",
+			expected: []string{
+				`<expr> ::= <term> | <expr> "+" <term>`,
+				`<term> ::= <digit> | "(" <expr> ")"`,
+				`<unused> ::= "x"`,
+				``,
+				`<digits> ::= <digit> <digits>`,
+			},
+		},
+	}
+
+	var lines = make([][]byte, len(source))
+	for idx, line := range source {
+		lines[idx] = []byte(line)
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var actions = CodeActions(parser.DialectBNF, lines,
+				testCase.row, testCase.col)
+
+			var kinds []string
+			for _, action := range actions {
+				kinds = append(kinds, action.Kind)
+			}
+			if strings.Join(kinds, " ") != testCase.kinds {
+				t.Fatalf("wrong actions: %v", kinds)
+			}
+
+			var doc = append([]string{}, source...)
+			var result = ApplyEdits(doc, actions[0].Edits)
+			if strings.Join(result, "\n") !=
+				strings.Join(testCase.expected, "\n") {
+				t.Errorf("wrong result:\n%s", strings.Join(result, "\n"))
+			}
+		})
+	}
+}
+
+func TestReplaceDeprecated(t *testing.T) {
+	var source = []string{
+		`<s> ::= <old> | <old> "," <s>`,
+		`; @deprecated use <new>`,
+		`<old> ::= "x"`,
+		`<new> ::= "x" | "y" <old>`,
+	}
+
+	var lines = make([][]byte, len(source))
+	for idx, line := range source {
+		lines[idx] = []byte(line)
+	}
+
+	var actions = CodeActions(parser.DialectBNF, lines, 0, 9)
+	if len(actions) == 0 || actions[0].Kind != ActionReplace {
+		t.Fatalf("wrong actions: %v", actions)
+	}
+
+	var result = ApplyEdits(append([]string{}, source...), actions[0].Edits)
+	var expected = []string{
+		`<s> ::= <new> | <new> "," <s>`,
+		`; @deprecated use <new>`,
+		`<old> ::= "x"`,
+		`<new> ::= "x" | "y" <new>`,
+	}
+	if strings.Join(result, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("wrong result:\n%s", strings.Join(result, "\n"))
+	}
+}
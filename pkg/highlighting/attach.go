@@ -0,0 +1,109 @@
+package highlighting
+
+import (
+	"time"
+
+	"github.com/neovim/go-client/nvim"
+)
+
+var errAttached = newError(CodeAttachment, "buffer is already attached")
+var errNotAttached = newError(CodeAttachment, "buffer is not attached")
+
+// HandleAttachCommand attaches plugin to the current buffer regardless of its
+// name. Dialect of the buffer is detected in the same way as for buffers which
+// are attached automatically.
+func (h *Highlighter) HandleAttachCommand() error {
+	logger.Debugf("HandleAttachCommand()")
+
+	if err := h.setupNamespace(); err != nil {
+		return err
+	}
+
+	var buf, err = CurrentBuffer(h.nvim)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := DocIndex.Get(buf); ok {
+		return errAttached
+	}
+
+	if err := AttachToBuffer(h.nvim, &buf); err != nil {
+		return err
+	}
+
+	h.awaitInitialLines(buf)
+	logger.Infof("buffer %s was attached to plugin", buf)
+	return nil
+}
+
+// HandleDetachCommand detaches plugin from the current buffer and removes all
+// highlights and annotations of the buffer.
+func (h *Highlighter) HandleDetachCommand() error {
+	logger.Debugf("HandleDetachCommand()")
+
+	var buf, err = CurrentBuffer(h.nvim)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := DocIndex.Get(buf); !ok {
+		return errNotAttached
+	}
+
+	if err := DetachFromBuffer(h.nvim, &buf); err != nil {
+		return err
+	}
+
+	h.forget(buf)
+
+	logger.Infof("buffer %s was detached from plugin", buf)
+	return nil
+}
+
+// initialLinesTimeout is how long initial buf_lines event is awaited after a
+// buffer is attached. Lines are pulled from the buffer if the event is not
+// delivered in time.
+const initialLinesTimeout = 500 * time.Millisecond
+
+// awaitInitialLines loads content of an attached buffer if initial buf_lines
+// event is not delivered, so that buffer is not left without document until
+// the first change.
+func (h *Highlighter) awaitInitialLines(buf nvim.Buffer) {
+	time.AfterFunc(initialLinesTimeout, func() {
+		if _, ok := DocIndex.Get(buf); ok || h.stopped() {
+			return
+		}
+
+		logger.Warnf("initial lines of buffer %s were not received", buf)
+		if err := h.loadBuffer(buf); err != nil {
+			logger.Errorf("failed to load buffer %s: %s", buf, err)
+		}
+	})
+}
+
+// loadBuffer pulls lines of a buffer and handles them as if they came with
+// initial buf_lines event.
+func (h *Highlighter) loadBuffer(buf nvim.Buffer) error {
+	var lines [][]byte
+	var tick int
+	var batch = h.nvim.NewBatch()
+	BufferContent(batch, buf, &lines, &tick)
+	if err := execute(batch); err != nil {
+		return err
+	}
+
+	h.HandleBufLinesEvent(&buf, tick, 0, -1, lines, false)
+	return nil
+}
+
+// clearBuffer removes highlights, virtual text and extmarks of all namespaces
+// in the same way as they are cleared on rehighlighting.
+func (h *Highlighter) clearBuffer(buf nvim.Buffer) error {
+	var batch = h.nvim.NewBatch()
+	batch.ClearBufferHighlight(buf, -1, 0, -1)
+	if h.diagnostics != 0 {
+		ResetDiagnostics(batch, buf, h.diagnostics)
+	}
+	return execute(batch)
+}
@@ -0,0 +1,226 @@
+package highlighting
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+	"github.com/daskol/nvim-bnf/pkg/i18n"
+)
+
+// uncommitted is a commit hash which git uses for lines which are not
+// committed yet.
+const uncommitted = "0000000000000000000000000000000000000000"
+
+// BlameLine is an origin of a line of a file according to git blame.
+type BlameLine struct {
+	Line    int
+	Commit  string
+	Author  string
+	Summary string
+}
+
+// String renders origin of a line in a short form suitable for virtual text.
+func (b *BlameLine) String() string {
+	if b.Commit == uncommitted {
+		return i18n.T("Not committed yet")
+	}
+	return fmt.Sprintf("%s %s · %s", b.Commit[:7], b.Author, b.Summary)
+}
+
+// Blame runs git blame for zero-based half-open range of lines of a file.
+// Content of a buffer is passed to git instead of file on disk, so unsaved
+// changes are blamed as not committed.
+func Blame(filename string, lines [][]byte, begin, end int) (
+	[]BlameLine, error,
+) {
+	var dir, base = filepath.Split(filename)
+	if dir == "" {
+		dir = "."
+	}
+	var span = strconv.Itoa(begin+1) + "," + strconv.Itoa(end)
+	var cmd = exec.Command("git", "blame", "--porcelain", "-L", span,
+		"--contents", "-", "--", base)
+	var stderr bytes.Buffer
+
+	cmd.Dir = dir
+	var content = append(bytes.Join(lines, []byte{'\n'}), '\n')
+	cmd.Stdin = bytes.NewReader(content)
+	cmd.Stderr = &stderr
+
+	var output, err = cmd.Output()
+	if err != nil {
+		var msg = strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return nil, errors.New("nvim-bnf: git blame failed: " + msg)
+	}
+
+	return parseBlame(output)
+}
+
+// parseBlame parses output of git blame in porcelain format. Line numbers are
+// converted to zero-based ones.
+func parseBlame(output []byte) ([]BlameLine, error) {
+	var result []BlameLine
+	var commits = make(map[string]*BlameLine)
+	var current *BlameLine
+	var scanner = bufio.NewScanner(bytes.NewReader(output))
+
+	for scanner.Scan() {
+		var line = scanner.Text()
+
+		switch {
+		case strings.HasPrefix(line, "\t"):
+			if current == nil {
+				return nil, errors.New("nvim-bnf: malformed git blame output")
+			}
+			result = append(result, *current)
+			current = nil
+		case current == nil:
+			var fields = strings.Fields(line)
+			if len(fields) < 3 {
+				return nil, errors.New("nvim-bnf: malformed git blame output")
+			}
+
+			var lineno, err = strconv.Atoi(fields[2])
+			if err != nil {
+				return nil, errors.New("nvim-bnf: malformed git blame output")
+			}
+
+			var commit, ok = commits[fields[0]]
+			if !ok {
+				commit = &BlameLine{Commit: fields[0]}
+				commits[fields[0]] = commit
+			}
+
+			current = &BlameLine{Line: lineno - 1, Commit: commit.Commit}
+			current.Author = commit.Author
+			current.Summary = commit.Summary
+		case strings.HasPrefix(line, "author "):
+			current.Author = strings.TrimPrefix(line, "author ")
+			commits[current.Commit].Author = current.Author
+		case strings.HasPrefix(line, "summary "):
+			current.Summary = strings.TrimPrefix(line, "summary ")
+			commits[current.Commit].Summary = current.Summary
+		}
+	}
+
+	return result, scanner.Err()
+}
+
+// HandleBlameRuleCommand annotates lines of a rule with their last commit and
+// author. Rule is either specified by name or it is a rule under cursor. Bang
+// removes all annotations.
+func (h *Highlighter) HandleBlameRuleCommand(args []string, bang bool) error {
+	logger.Debugf("HandleBlameRuleCommand(%v, %t)", args, bang)
+
+	var buf, err = CurrentBuffer(h.nvim)
+	if err != nil {
+		return err
+	}
+
+	nsID, err := CreateNamespace(h.nvim, "nvim-bnf-blame")
+	if err != nil {
+		return err
+	}
+
+	if err := ClearBufferHighlight(h.nvim, buf, nsID, 0, -1); err != nil {
+		return err
+	} else if bang {
+		return nil
+	}
+
+	lines, err := BufferLines(h.nvim, buf, 0, -1, true)
+	if err != nil {
+		return err
+	}
+
+	var grammar = analysis.NewGrammar(h.dialectOf(buf), lines)
+	var rule *analysis.Rule
+
+	if len(args) > 0 {
+		var name = strings.TrimSuffix(strings.TrimPrefix(args[0], "<"), ">")
+		if rule = grammar.Lookup(name); rule == nil {
+			return newError(CodeUnknownRule, "there is no rule "+args[0])
+		}
+	} else if cursor, err := WindowCursor(h.nvim, 0); err != nil {
+		return err
+	} else if rule = ruleAt(grammar, lines, cursor[0]-1); rule == nil {
+		return newError(CodeUnknownRule, "there is no rule under cursor")
+	}
+
+	filename, err := BufferName(h.nvim, buf)
+	if err != nil {
+		return err
+	}
+
+	var begin, end = ruleExtent(grammar, rule, lines)
+	blames, err := Blame(filename, lines, begin, end)
+	if err != nil {
+		return err
+	}
+
+	var batch = h.nvim.NewBatch()
+	for _, blame := range blames {
+		var res int
+		var chunks = []Chunk{NewChunk(blame.String(), "Comment")}
+		if blame.Line == rule.Line {
+			var prefix = formatAlternatives(len(rule.AlternativeRanges()))
+			chunks = append([]Chunk{NewChunk(prefix, "LineNr")}, chunks...)
+		}
+		h.caps.SetVirtualText(batch, &buf, nsID, blame.Line, chunks, NoOpts,
+			&res)
+	}
+
+	return execute(batch)
+}
+
+// ruleExtent returns zero-based half-open range of lines which a rule
+// occupies. A rule lasts until the next rule or the end of document and
+// trailing blank lines do not belong to it.
+func ruleExtent(
+	grammar *analysis.Grammar, rule *analysis.Rule, lines [][]byte,
+) (int, int) {
+	var end = len(lines)
+	for _, other := range grammar.Rules {
+		if other.Line > rule.Line && other.Line < end {
+			end = other.Line
+		}
+	}
+
+	for end-1 > rule.Line && len(bytes.TrimSpace(lines[end-1])) == 0 {
+		end--
+	}
+
+	return rule.Line, end
+}
+
+// ruleAt returns a rule which occupies a line or nil.
+func ruleAt(
+	grammar *analysis.Grammar, lines [][]byte, line int,
+) *analysis.Rule {
+	for _, rule := range grammar.Rules {
+		if begin, end := ruleExtent(grammar, rule, lines); line >= begin &&
+			line < end {
+			return rule
+		}
+	}
+	return nil
+}
+
+// formatAlternatives renders numbers of alternatives which are annotated.
+func formatAlternatives(noalts int) string {
+	if noalts <= 1 {
+		return i18n.T("alt 1 ")
+	} else {
+		return i18n.Sprintf("alts 1-%d ", noalts)
+	}
+}
@@ -0,0 +1,205 @@
+package highlighting
+
+import (
+	"fmt"
+
+	"github.com/neovim/go-client/nvim"
+)
+
+// API levels of NeoVim releases which introduced features used by plugin.
+const (
+	apiLevelExtmarks   = 7  // NeoVim 0.5: highlights and virtual text.
+	apiLevelDiagnostic = 8  // NeoVim 0.6: vim.diagnostic and signs.
+	apiLevelDefaultHl  = 10 // NeoVim 0.8: nvim_set_hl() with default.
+)
+
+// Capabilities describes which API of NeoVim is available, so highlighting
+// degrades gracefully on older versions. Nil capabilities stand for the
+// latest API.
+type Capabilities struct {
+	// APILevel is api_level of NeoVim which plugin is running against.
+	APILevel int
+
+	// Extmarks reports whether extmarks carry highlights of ranges and
+	// virtual text. Otherwise nvim_buf_add_highlight() and
+	// nvim_buf_set_virtual_text() are used.
+	Extmarks bool
+
+	// Signs reports whether extmarks could place signs and conceal text.
+	Signs bool
+
+	// Diagnostic reports whether vim.diagnostic is available.
+	Diagnostic bool
+
+	// SetHighlight reports whether highlight groups could be defined with
+	// nvim_set_hl() without overriding customized ones. Otherwise they are
+	// defined with :highlight default.
+	SetHighlight bool
+}
+
+// NewCapabilities derives capabilities from API level and names of API
+// functions.
+func NewCapabilities(level int, functions []string) *Capabilities {
+	var caps = &Capabilities{APILevel: level}
+	for _, name := range functions {
+		switch name {
+		case "nvim_buf_set_extmark":
+			caps.Extmarks = level >= apiLevelExtmarks
+		case "nvim_set_hl":
+			caps.SetHighlight = level >= apiLevelDefaultHl
+		}
+	}
+	caps.Signs = caps.Extmarks && level >= apiLevelDiagnostic
+	caps.Diagnostic = level >= apiLevelDiagnostic
+	return caps
+}
+
+// QueryCapabilities requests nvim_get_api_info() and derives capabilities
+// from its metadata.
+func QueryCapabilities(v *nvim.Nvim) (*Capabilities, error) {
+	var info []interface{}
+	if err := request(v, true, "nvim_get_api_info", &info); err != nil {
+		return nil, err
+	}
+
+	if len(info) != 2 {
+		return nil, fmt.Errorf("unexpected API info of size %d", len(info))
+	}
+
+	var metadata, _ = info[1].(map[string]interface{})
+	var version, _ = metadata["version"].(map[string]interface{})
+	var level, ok = toInt(version["api_level"])
+	if !ok {
+		return nil, fmt.Errorf("API info has no api_level")
+	}
+
+	var functions []string
+	var items, _ = metadata["functions"].([]interface{})
+	for _, item := range items {
+		var function, _ = item.(map[string]interface{})
+		if name, ok := function["name"].(string); ok {
+			functions = append(functions, name)
+		}
+	}
+	return NewCapabilities(level, functions), nil
+}
+
+// String returns human-readable summary of capabilities.
+func (c *Capabilities) String() string {
+	if c == nil {
+		return "latest API"
+	}
+	return fmt.Sprintf("API level %d (extmarks=%t, signs=%t, "+
+		"vim.diagnostic=%t, nvim_set_hl=%t)", c.APILevel, c.Extmarks,
+		c.Signs, c.Diagnostic, c.SetHighlight)
+}
+
+func (c *Capabilities) extmarks() bool {
+	return c == nil || c.Extmarks
+}
+
+// DefineHighlight defines highlight group unless it is defined already in
+// batch mode. It falls back to :highlight default on older NeoVim.
+func (c *Capabilities) DefineHighlight(b *nvim.Batch, group HighlightGroup) {
+	if c != nil && !c.SetHighlight {
+		b.Command(group.Command())
+		return
+	}
+	b.Request("nvim_set_hl", nil, 0, group.Name, group.Definition())
+}
+
+// SetVirtualText shows virtual text at the end of line in batch mode. It is
+// an extmark unless NeoVim predates them.
+func (c *Capabilities) SetVirtualText(
+	b *nvim.Batch, buf *nvim.Buffer, nsID int, line int, chunks []Chunk,
+	opts map[string]interface{}, result *int,
+) {
+	if !c.extmarks() {
+		SetVirtualText(b, buf, nsID, line, chunks, opts, result)
+		return
+	}
+
+	var extmarkOpts = map[string]interface{}{"virt_text": chunks}
+	for key, value := range opts {
+		extmarkOpts[key] = value
+	}
+	SetExtmark(b, buf, nsID, line, 0, extmarkOpts, result)
+}
+
+// Highlight highlights a byte range of line with a group in batch mode.
+func (c *Capabilities) Highlight(
+	b *nvim.Batch, buf nvim.Buffer, nsID int, group string, line, begin,
+	end int, result *int,
+) {
+	if !c.extmarks() {
+		b.AddBufferHighlight(buf, nsID, group, line, begin, end, result)
+		return
+	}
+
+	var opts = map[string]interface{}{
+		"end_col":  end,
+		"hl_group": group,
+	}
+	SetExtmark(b, &buf, nsID, line, begin, opts, result)
+}
+
+// Conceal displays a byte range of line as a symbol in batch mode. Nothing is
+// concealed if extmarks could not conceal text.
+func (c *Capabilities) Conceal(
+	b *nvim.Batch, buf nvim.Buffer, nsID int, line, begin, end int,
+	symbol string, result *int,
+) {
+	if c != nil && !c.Signs {
+		return
+	}
+
+	var opts = map[string]interface{}{
+		"end_col": end,
+		"conceal": symbol,
+	}
+	SetExtmark(b, &buf, nsID, line, begin, opts, result)
+}
+
+// SetSign places a sign in batch mode. Signs are not placed at all if
+// extmarks could not carry them.
+func (c *Capabilities) SetSign(
+	b *nvim.Batch, buf nvim.Buffer, nsID int, line int, sign Sign,
+	result *int,
+) {
+	if c != nil && !c.Signs {
+		return
+	}
+
+	var opts = map[string]interface{}{
+		"sign_text":     sign.Text,
+		"sign_hl_group": sign.Group,
+		"priority":      sign.Priority,
+	}
+	SetExtmark(b, &buf, nsID, line, 0, opts, result)
+}
+
+// setupCapabilities queries capabilities of NeoVim once. The oldest API is
+// assumed if they could not be queried.
+func (h *Highlighter) setupCapabilities() {
+	var caps, err = QueryCapabilities(h.nvim)
+	if err != nil {
+		logger.Warnf("failed to query capabilities: %s", err)
+		caps = NewCapabilities(0, nil)
+	}
+	logger.Infof("NeoVim capabilities: %s", caps)
+	h.caps = caps
+}
+
+// toInt converts integer which is decoded from msgpack.
+func toInt(value interface{}) (int, bool) {
+	switch value := value.(type) {
+	case int64:
+		return int(value), true
+	case uint64:
+		return int(value), true
+	case int:
+		return value, true
+	default:
+		return 0, false
+	}
+}
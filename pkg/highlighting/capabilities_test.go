@@ -0,0 +1,40 @@
+package highlighting
+
+import (
+	"testing"
+)
+
+func TestNewCapabilities(t *testing.T) {
+	var functions = []string{
+		"nvim_buf_add_highlight", "nvim_buf_set_extmark", "nvim_set_hl",
+	}
+	var tests = []struct {
+		level      int
+		functions  []string
+		extmarks   bool
+		signs      bool
+		diagnostic bool
+		setHl      bool
+	}{
+		{0, nil, false, false, false, false},
+		{6, functions, false, false, false, false},
+		{7, functions, true, false, false, false},
+		{8, functions, true, true, true, false},
+		{10, functions, true, true, true, true},
+		{11, functions[:1], false, false, true, false},
+	}
+
+	for _, test := range tests {
+		var caps = NewCapabilities(test.level, test.functions)
+		if caps.Extmarks != test.extmarks || caps.Signs != test.signs ||
+			caps.Diagnostic != test.diagnostic ||
+			caps.SetHighlight != test.setHl {
+			t.Errorf("wrong capabilities of level %d: %s", test.level, caps)
+		}
+	}
+
+	var caps *Capabilities
+	if !caps.extmarks() {
+		t.Errorf("nil capabilities should stand for the latest API")
+	}
+}
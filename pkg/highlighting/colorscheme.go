@@ -0,0 +1,112 @@
+package highlighting
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/neovim/go-client/nvim"
+)
+
+// HighlightGroup is a highlight group of plugin. It is either a link to
+// another group or a set of attributes like undercurl together with color of
+// special (e.g. undercurl).
+type HighlightGroup struct {
+	Name    string
+	Link    string
+	Attrs   []string
+	Special string
+}
+
+// Command returns :highlight default command which defines the group.
+func (g HighlightGroup) Command() string {
+	if g.Link != "" {
+		return "highlight default link " + g.Name + " " + g.Link
+	}
+
+	var attrs = strings.Join(g.Attrs, ",")
+	var cmd = "highlight default " + g.Name + " cterm=" + attrs +
+		" gui=" + attrs
+	if g.Special != "" {
+		cmd += " guisp=" + g.Special
+	}
+	return cmd
+}
+
+// Definition returns definition of the group for nvim_set_hl(). It does not
+// override the group if it is already defined.
+func (g HighlightGroup) Definition() map[string]interface{} {
+	var def = map[string]interface{}{"default": true}
+	if g.Link != "" {
+		def["link"] = g.Link
+		return def
+	}
+
+	var cterm = make(map[string]interface{})
+	for _, attr := range g.Attrs {
+		def[attr] = true
+		cterm[attr] = true
+	}
+	def["cterm"] = cterm
+	if g.Special != "" {
+		def["sp"] = g.Special
+	}
+	return def
+}
+
+// HighlightGroups returns highlight groups of plugin with their defaults.
+// Generic groups of lexemes like BnfNonTerminal are linked to builtin groups
+// while specific ones like BnfReference are linked to generic ones, so that
+// every group could be overridden individually.
+func HighlightGroups() []HighlightGroup {
+	var groups = []HighlightGroup{
+		{Name: "BnfErrorUnderline", Attrs: []string{"undercurl"},
+			Special: "Red"},
+		{Name: "BnfWarningUnderline", Attrs: []string{"undercurl"},
+			Special: "Orange"},
+		{Name: "BnfStartSymbol", Link: "Title"},
+		{Name: "BnfInlayHint", Link: "Comment"},
+		{Name: "BnfRefHighlight", Link: "Visual"},
+		{Name: "BnfDeprecated", Attrs: []string{"strikethrough"}},
+	}
+	for _, link := range tokenGroups {
+		groups = append(groups, HighlightGroup{Name: link[0], Link: link[1]})
+	}
+	for idx, link := range rainbowLinks {
+		groups = append(groups,
+			HighlightGroup{Name: rainbowGroup(idx), Link: link})
+	}
+	return append(groups,
+		HighlightGroup{Name: "BnfErrorSign", Link: "ErrorMsg"},
+		HighlightGroup{Name: "BnfWarningSign", Link: "WarningMsg"},
+		HighlightGroup{Name: "BnfInfoSign", Link: "Comment"})
+}
+
+// defineHighlights defines highlight groups of plugin in batch mode.
+func defineHighlights(batch *nvim.Batch, caps *Capabilities) {
+	for _, group := range HighlightGroups() {
+		caps.DefineHighlight(batch, group)
+	}
+}
+
+// registerColorScheme defines autocommand which notifies plugin as soon as
+// colorscheme is changed. It is not in manifest since it should not start
+// plugin.
+func (h *Highlighter) registerColorScheme() {
+	var cmd = fmt.Sprintf("autocmd nvim-bnf ColorScheme * "+
+		"call rpcnotify(%d, 'nvim_bnf_color_scheme')", h.nvim.ChannelID())
+	if err := Command(h.nvim, cmd); err != nil {
+		logger.Errorf("failed to register ColorScheme: %s", err)
+	}
+}
+
+// HandleColorSchemeEvent defines highlight groups of plugin again since
+// colorscheme clears all groups which it does not define on loading.
+func (h *Highlighter) HandleColorSchemeEvent() {
+	logger.Debugf("HandleColorSchemeEvent()")
+
+	var batch = h.nvim.NewBatch()
+	defineHighlights(batch, h.caps)
+	if err := execute(batch); err != nil {
+		logger.Warnf("failed to define highlight groups: %s", err)
+	}
+}
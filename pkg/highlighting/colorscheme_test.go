@@ -0,0 +1,44 @@
+package highlighting
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHighlightGroups(t *testing.T) {
+	var groups = make(map[string]HighlightGroup)
+	for _, group := range HighlightGroups() {
+		groups[group.Name] = group
+	}
+
+	for _, name := range []string{
+		"BnfOperator", "BnfTerminal", "BnfNonTerminal", "BnfRuleName",
+		"BnfComment", "BnfRefHighlight", "BnfErrorSign",
+	} {
+		if _, ok := groups[name]; !ok {
+			t.Errorf("group %s is not defined", name)
+		}
+	}
+
+	var group = groups["BnfReference"]
+	if cmd := group.Command(); cmd != "highlight default link "+
+		"BnfReference BnfNonTerminal" {
+		t.Errorf("wrong command: %s", cmd)
+	}
+
+	group = groups["BnfErrorUnderline"]
+	if cmd := group.Command(); cmd != "highlight default "+
+		"BnfErrorUnderline cterm=undercurl gui=undercurl guisp=Red" {
+		t.Errorf("wrong command: %s", cmd)
+	}
+
+	var expected = map[string]interface{}{
+		"default":   true,
+		"undercurl": true,
+		"cterm":     map[string]interface{}{"undercurl": true},
+		"sp":        "Red",
+	}
+	if def := group.Definition(); !reflect.DeepEqual(def, expected) {
+		t.Errorf("wrong definition: %v", def)
+	}
+}
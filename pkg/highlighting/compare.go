@@ -0,0 +1,119 @@
+package highlighting
+
+import (
+	"strings"
+
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+	"github.com/neovim/go-client/nvim"
+)
+
+// HandleCompareRulesCommand shows normalized definitions of two rules side by
+// side and highlights terms which differ.
+func (h *Highlighter) HandleCompareRulesCommand(args []string) error {
+	logger.Debugf("HandleCompareRulesCommand(%v)", args)
+
+	if len(args) != 2 {
+		return newError(CodeInvalidArgs, "exactly two rule names are expected")
+	}
+
+	if err := h.setupNamespace(); err != nil {
+		return err
+	}
+
+	var buf, err = CurrentBuffer(h.nvim)
+	if err != nil {
+		return err
+	}
+
+	lines, err := BufferLines(h.nvim, buf, 0, -1, true)
+	if err != nil {
+		return err
+	}
+
+	var grammar = analysis.NewGrammar(h.dialectOf(buf), lines)
+	var rules [2]*analysis.Rule
+
+	for idx, arg := range args {
+		var name = strings.TrimSuffix(strings.TrimPrefix(arg, "<"), ">")
+		if rules[idx] = grammar.Lookup(name); rules[idx] == nil {
+			return newError(CodeUnknownRule, "there is no rule "+arg)
+		}
+	}
+
+	var changesA, changesB = analysis.CompareRules(rules[0], rules[1])
+
+	left, err := h.renderComparison(rules[0], changesA, "DiffDelete")
+	if err != nil {
+		return err
+	}
+
+	right, err := h.renderComparison(rules[1], changesB, "DiffAdd")
+	if err != nil {
+		return err
+	}
+
+	if _, err := h.openWindow("split", left); err != nil {
+		return err
+	}
+
+	_, err = h.openWindow("vsplit", right)
+	return err
+}
+
+// renderComparison creates scratch buffer with normalized definition of a rule
+// where every alternative is placed on its own line. Changed terms are
+// highlighted with a group.
+func (h *Highlighter) renderComparison(
+	rule *analysis.Rule, changes analysis.Changes, group string,
+) (nvim.Buffer, error) {
+	var lines, spans = renderRule(rule)
+
+	var buf, err = h.newScratchBuffer(lines)
+	if err != nil {
+		return buf, err
+	}
+
+	var batch = h.nvim.NewBatch()
+	for row, alt := range spans {
+		for idx, span := range alt {
+			if changes[row][idx] {
+				var res int
+				batch.AddBufferHighlight(
+					buf, h.namespace, group, row, span[0], span[1], &res,
+				)
+			}
+		}
+	}
+
+	return buf, execute(batch)
+}
+
+// renderRule renders normalized definition of a rule with one alternative per
+// line. It returns lines and column spans of terms for every alternative.
+func renderRule(rule *analysis.Rule) ([][]byte, [][][2]int) {
+	var head = "<" + rule.Name + "> ::= "
+	var indent = strings.Repeat(" ", len(head)-2) + "| "
+	var alts = rule.Alternatives()
+	var lines = make([][]byte, len(alts))
+	var spans = make([][][2]int, len(alts))
+
+	for row, alt := range alts {
+		var line = indent
+		if row == 0 {
+			line = head
+		}
+
+		for idx, term := range alt {
+			if idx > 0 {
+				line += " "
+			}
+			var span = [2]int{len(line), len(line) + len(term)}
+			spans[row] = append(spans[row], span)
+			line += term
+		}
+
+		lines[row] = []byte(line)
+	}
+
+	return lines, spans
+}
@@ -0,0 +1,114 @@
+package highlighting
+
+import (
+	"github.com/neovim/go-client/nvim"
+)
+
+// concealSymbols are math-style replacements of operators which are shown in
+// conceal mode.
+var concealSymbols = map[string]string{
+	"::=": "≔",
+	"|":   "│",
+}
+
+// ConcealSpan is a byte range of a line which is displayed as a symbol.
+type ConcealSpan struct {
+	Begin  int
+	End    int
+	Symbol string
+}
+
+// ConcealSpans returns operators of a line which are replaced with pretty
+// symbols in conceal mode.
+func ConcealSpans(line []byte, tokens []Token) []ConcealSpan {
+	var spans []ConcealSpan
+	for _, token := range tokens {
+		if token.Type != TokenOperator || token.End > len(line) {
+			continue
+		}
+		var text = string(line[token.Begin:token.End])
+		if symbol, ok := concealSymbols[text]; ok {
+			spans = append(spans, ConcealSpan{token.Begin, token.End, symbol})
+		}
+	}
+	return spans
+}
+
+// concealLine conceals operators of a line in batch mode.
+func (d *Document) concealLine(
+	batch *nvim.Batch, buf nvim.Buffer, row int, tokens []Token,
+) {
+	for _, span := range ConcealSpans(d.Lines[row], tokens) {
+		var res int
+		d.caps.Conceal(batch, buf, d.namespace, row, span.Begin, span.End,
+			span.Symbol, &res)
+	}
+}
+
+// concealEnabled reports whether operators of a buffer are concealed. It is
+// set per buffer with b:bnf_conceal and globally with g:bnf_conceal.
+func (h *Highlighter) concealEnabled(buf nvim.Buffer) bool {
+	var enabled int
+	var expr = "get(g:, 'bnf_conceal', 0)"
+	if err := Eval(h.nvim, expr, &enabled); err != nil {
+		logger.Warnf("failed to get g:bnf_conceal: %s", err)
+	}
+
+	var err = CallFunction(h.nvim, "getbufvar", &enabled, int(buf),
+		"bnf_conceal", enabled)
+	if err != nil {
+		logger.Warnf("failed to get b:bnf_conceal of %s: %s", buf, err)
+	}
+	return enabled != 0
+}
+
+// HandleToggleConcealCommand shows operators of the current buffer as
+// math-style symbols like `≔` or shows them as is back. Concealed text is
+// shown as is on the line under cursor.
+func (h *Highlighter) HandleToggleConcealCommand() error {
+	logger.Debugf("HandleToggleConcealCommand()")
+
+	var buf, doc, err = h.currentDocument()
+	if err != nil {
+		return err
+	}
+
+	var enabled = !h.concealEnabled(buf)
+	var value = 0
+	if enabled {
+		value = 1
+	}
+
+	if err := SetBufferVar(h.nvim, buf, "bnf_conceal", value); err != nil {
+		return err
+	}
+
+	// Concealed text is hidden only if window allows it.
+	if enabled {
+		var level int
+		var win = nvim.Window(0)
+		err = WindowOption(h.nvim, win, "conceallevel", &level)
+		if err == nil && level == 0 {
+			err = SetWindowOption(h.nvim, win, "conceallevel", 2)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	doc.Lock()
+	doc.conceal = enabled
+	doc.Unlock()
+
+	h.pipeline().Submit(&highlightJob{
+		buf:   buf,
+		doc:   doc,
+		tick:  doc.Tick(),
+		to:    -1,
+		event: newEvent("toggle_conceal", buf),
+	})
+
+	logger.Infof("conceal of buffer %s was toggled: enabled=%t", buf,
+		enabled)
+	return nil
+}
@@ -0,0 +1,23 @@
+package highlighting
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConcealSpans(t *testing.T) {
+	var line = []byte(`<a> ::= "x" | <b>`)
+	var tokens = []Token{
+		{0, 3, TokenDefinition, 0},
+		{4, 7, TokenOperator, 0},
+		{8, 11, TokenTerminal, 0},
+		{12, 13, TokenOperator, 0},
+		{14, 17, TokenReference, 0},
+	}
+
+	var spans = ConcealSpans(line, tokens)
+	var expected = []ConcealSpan{{4, 7, "≔"}, {12, 13, "│"}}
+	if !reflect.DeepEqual(spans, expected) {
+		t.Errorf("wrong spans: %v", spans)
+	}
+}
@@ -0,0 +1,70 @@
+package highlighting
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+	"github.com/daskol/nvim-bnf/pkg/convert"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+// nl is a separator of lines of a buffer.
+var nl = []byte{'\n'}
+
+// cr is a carriage return which precedes nl in files edited on Windows.
+var cr = []byte{'\r'}
+
+// HandleConvertCommand rewrites the grammar of the current buffer in another
+// dialect and opens result in a scratch buffer. Conversion is refused if some
+// constructs could not be expressed in target dialect unless it is forced with
+// bang.
+func (h *Highlighter) HandleConvertCommand(args []string, bang bool) error {
+	logger.Debugf("HandleConvertCommand(%v, %t)", args, bang)
+
+	if len(args) != 1 {
+		return newError(CodeInvalidArgs, "target dialect is expected")
+	}
+
+	var target, ok = parser.LookupDialect(args[0])
+	if !ok {
+		return newError(CodeUnknownDialect, "unknown dialect "+args[0])
+	}
+
+	var buf, err = CurrentBuffer(h.nvim)
+	if err != nil {
+		return err
+	}
+
+	lines, err := BufferLines(h.nvim, buf, 0, -1, true)
+	if err != nil {
+		return err
+	}
+
+	var source = h.dialectOf(buf)
+	var grammar = analysis.NewGrammar(source, lines)
+	if diags := grammar.Portability(target); len(diags) > 0 && !bang {
+		return newError(CodeUnportable, fmt.Sprintf("%d constructs could "+
+			"not be expressed in %s (use ! to convert anyway)", len(diags),
+			target))
+	}
+
+	content, err := convert.Convert(source, target, bytes.Join(lines, nl))
+	if err != nil {
+		return err
+	}
+
+	var converted = bytes.Split(bytes.TrimSuffix(content, nl), nl)
+	view, err := h.newScratchBuffer(converted)
+	if err != nil {
+		return err
+	}
+
+	var filetype = string(target)
+	if err := SetBufferOption(h.nvim, view, "filetype", filetype); err != nil {
+		return err
+	}
+
+	_, err = h.openWindow("split", view)
+	return err
+}
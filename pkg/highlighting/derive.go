@@ -0,0 +1,262 @@
+package highlighting
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+	"github.com/daskol/nvim-bnf/pkg/i18n"
+	"github.com/daskol/nvim-bnf/pkg/language"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+	"github.com/neovim/go-client/nvim"
+)
+
+// deriveMappings are buffer-local mappings of a derivation window. Count
+// of expansion chooses an alternative.
+var deriveMappings = []string{
+	"nnoremap <buffer> <silent> <CR> :<C-U>call BNFDeriveExpand(v:count)<CR>",
+	"nnoremap <buffer> <silent> u :call BNFDeriveUndo()<CR>",
+}
+
+// HandleDeriveCommand opens a scratch split with derivation of sentential
+// forms from a rule (the start symbol by default). Every line is a form and
+// the last one is the current form. A non-terminal under cursor on the last
+// line (or the leftmost one) is expanded with <CR> and the last step is
+// reverted with u. State of derivation is kept by window.
+func (h *Highlighter) HandleDeriveCommand(args []string) error {
+	logger.Debugf("HandleDeriveCommand(%v)", args)
+
+	var buf, err = CurrentBuffer(h.nvim)
+	if err != nil {
+		return err
+	}
+
+	lines, err := BufferLines(h.nvim, buf, 0, -1, true)
+	if err != nil {
+		return err
+	}
+
+	var dialect = h.dialectOf(buf)
+	var grammar = analysis.NewGrammar(dialect, lines)
+	if start := h.startSymbol(); start != "" {
+		grammar.Start = start
+	}
+
+	var rule = grammar.StartSymbol()
+	if len(args) > 0 {
+		rule = strings.TrimSuffix(strings.TrimPrefix(args[0], "<"), ">")
+	}
+	derivation, err := language.New(dialect, grammar).Derive(rule)
+	if err != nil {
+		return newError(CodeUnknownRule, "there is no rule <"+rule+">")
+	}
+
+	view, err := h.newScratchBuffer(formatDerivation(derivation))
+	if err != nil {
+		return err
+	}
+
+	win, err := h.openWindow("split", view)
+	if err != nil {
+		return err
+	}
+
+	var batch = h.nvim.NewBatch()
+	for _, mapping := range deriveMappings {
+		batch.Command(mapping)
+	}
+	if err := execute(batch); err != nil {
+		return err
+	}
+
+	h.putDerivation(win, derivation)
+	return nil
+}
+
+// HandleDeriveExpand expands a non-terminal of the current form of derivation
+// in the current window. Optional argument is one-based index of alternative.
+// User is asked to choose an alternative if it is not given and there are
+// several of them.
+func (h *Highlighter) HandleDeriveExpand(args []interface{}) error {
+	logger.Debugf("HandleDeriveExpand(%v)", args)
+
+	var choice int64
+	if len(args) > 0 {
+		choice, _ = args[0].(int64)
+	}
+
+	var win, derivation, err = h.currentDerivation()
+	if err != nil {
+		return err
+	}
+
+	cursor, err := WindowCursor(h.nvim, win)
+	if err != nil {
+		return err
+	}
+
+	var col = -1
+	if cursor[0] == len(derivation.Forms()) {
+		col = cursor[1]
+	}
+	var idx = expandableAt(derivation, col)
+	if idx < 0 {
+		var msg = i18n.T("There are no non-terminals to expand.")
+		return WriteOut(h.nvim, msg+"\n")
+	}
+
+	var choices = derivation.Choices(idx)
+	if choice == 0 && len(choices) == 1 {
+		choice = 1
+	} else if choice == 0 {
+		var items = []string{i18n.Sprintf("Expand %s with:",
+			derivation.Form()[idx])}
+		for num, symbols := range choices {
+			items = append(items, strconv.Itoa(num+1)+". "+
+				formatSymbols(symbols))
+		}
+		if err := Prompt(h.nvim, "inputlist", &choice, items); err != nil {
+			return err
+		}
+	}
+
+	if choice < 1 || int(choice) > len(choices) {
+		return nil
+	}
+	if err := derivation.Expand(idx, int(choice)-1); err != nil {
+		return err
+	}
+	return h.renderDerivation(win, derivation)
+}
+
+// HandleDeriveUndo reverts the last expansion of derivation in the current
+// window.
+func (h *Highlighter) HandleDeriveUndo(args []interface{}) error {
+	logger.Debugf("HandleDeriveUndo(%v)", args)
+
+	var win, derivation, err = h.currentDerivation()
+	if err != nil {
+		return err
+	}
+
+	if derivation.Undo() {
+		return h.renderDerivation(win, derivation)
+	}
+	return nil
+}
+
+// putDerivation binds derivation to a window. Derivations of windows which
+// are closed already are forgotten.
+func (h *Highlighter) putDerivation(
+	win nvim.Window, derivation *language.Derivation,
+) {
+	h.derivationGuard.Lock()
+	defer h.derivationGuard.Unlock()
+
+	if h.derivations == nil {
+		h.derivations = make(map[nvim.Window]*language.Derivation)
+	}
+	for other := range h.derivations {
+		var num int
+		var err = CallFunction(h.nvim, "win_id2win", &num, int(other))
+		if err == nil && num == 0 {
+			delete(h.derivations, other)
+		}
+	}
+	h.derivations[win] = derivation
+}
+
+// currentDerivation returns derivation of the current window.
+func (h *Highlighter) currentDerivation() (
+	nvim.Window, *language.Derivation, error,
+) {
+	var win, err = CurrentWindow(h.nvim)
+	if err != nil {
+		return win, nil, err
+	}
+
+	h.derivationGuard.Lock()
+	defer h.derivationGuard.Unlock()
+	if derivation, ok := h.derivations[win]; ok {
+		return win, derivation, nil
+	}
+	return win, nil, newError(CodeInvalidArgs,
+		"there is no derivation in the current window")
+}
+
+// renderDerivation replaces content of derivation window with forms of
+// derivation and moves cursor to the current form.
+func (h *Highlighter) renderDerivation(
+	win nvim.Window, derivation *language.Derivation,
+) error {
+	var buf, err = WindowBuffer(h.nvim, win)
+	if err != nil {
+		return err
+	}
+
+	var lines = formatDerivation(derivation)
+	var batch = h.nvim.NewBatch()
+	batch.SetBufferOption(buf, "modifiable", true)
+	batch.SetBufferLines(buf, 0, -1, true, lines)
+	batch.SetBufferOption(buf, "modifiable", false)
+	if err := execute(batch); err != nil {
+		return err
+	}
+	return SetWindowCursor(h.nvim, win, [2]int{len(lines), 0})
+}
+
+// derivationPrefix precedes every form except for the first one.
+const derivationPrefix = "=> "
+
+// formatDerivation renders every form of derivation on its own line.
+func formatDerivation(derivation *language.Derivation) [][]byte {
+	var lines [][]byte
+	for idx, form := range derivation.Forms() {
+		var line = formatSymbols(form)
+		if idx > 0 {
+			line = derivationPrefix + line
+		}
+		lines = append(lines, []byte(line))
+	}
+	return lines
+}
+
+// formatSymbols joins symbols with spaces. Empty sequence is rendered as
+// empty literal.
+func formatSymbols(symbols []language.Symbol) string {
+	if len(symbols) == 0 {
+		return `""`
+	}
+	var texts = make([]string, len(symbols))
+	for idx, symbol := range symbols {
+		texts[idx] = symbol.String()
+	}
+	return strings.Join(texts, " ")
+}
+
+// expandableAt returns index of symbol of the current form which is rendered
+// at a byte column of the last line. The leftmost expandable symbol is
+// returned if there is no expandable symbol at the column. It returns -1 if
+// no symbol could be expanded.
+func expandableAt(derivation *language.Derivation, col int) int {
+	var pos = 0
+	if len(derivation.Forms()) > 1 {
+		pos = len(derivationPrefix)
+	}
+
+	var leftmost = -1
+	for idx, symbol := range derivation.Form() {
+		var span = parser.Range{Begin: pos, End: pos + len(symbol.String())}
+		pos = span.End + 1
+		if len(derivation.Choices(idx)) == 0 {
+			continue
+		}
+		if col >= span.Begin && col < span.End {
+			return idx
+		}
+		if leftmost < 0 {
+			leftmost = idx
+		}
+	}
+	return leftmost
+}
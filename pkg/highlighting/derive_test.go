@@ -0,0 +1,43 @@
+package highlighting
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+	"github.com/daskol/nvim-bnf/pkg/language"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+func TestExpandableAt(t *testing.T) {
+	var lines = [][]byte{
+		[]byte(`<sum> ::= <num> "+" <num>`),
+		[]byte(`<num> ::= "0" | "1"`),
+	}
+	var grammar = analysis.NewGrammar(parser.DialectBNF, lines)
+	var derivation, err = language.New(parser.DialectBNF, grammar).
+		Derive("sum")
+	if err != nil {
+		t.Fatalf("failed to derive: %s", err)
+	}
+
+	if idx := expandableAt(derivation, -1); idx != 0 {
+		t.Errorf("wrong symbol of the first form: %d", idx)
+	}
+	if err := derivation.Expand(0, 0); err != nil {
+		t.Fatalf("failed to expand: %s", err)
+	}
+
+	// Line is `=> <num> "+" <num>`.
+	var testCases = map[int]int{-1: 0, 3: 0, 10: 0, 13: 2, 17: 2}
+	for col, expected := range testCases {
+		if idx := expandableAt(derivation, col); idx != expected {
+			t.Errorf("wrong symbol at column %d: %d", col, idx)
+		}
+	}
+
+	var forms = bytes.Join(formatDerivation(derivation), []byte{'\n'})
+	if string(forms) != "<sum>\n=> <num> \"+\" <num>" {
+		t.Errorf("wrong derivation: %s", forms)
+	}
+}
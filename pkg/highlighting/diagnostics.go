@@ -0,0 +1,131 @@
+package highlighting
+
+import (
+	"sort"
+
+	"github.com/daskol/nvim-bnf/pkg/lint"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+	"github.com/neovim/go-client/nvim"
+)
+
+// VimDiagnostic is a diagnostic in the form which vim.diagnostic.set()
+// accepts. Lines and columns are zero-based and columns are counted in bytes.
+type VimDiagnostic struct {
+	Line     int    `msgpack:"lnum"`
+	Col      int    `msgpack:"col"`
+	EndLine  int    `msgpack:"end_lnum"`
+	EndCol   int    `msgpack:"end_col"`
+	Severity int    `msgpack:"severity"`
+	Message  string `msgpack:"message"`
+	Code     string `msgpack:"code"`
+	Source   string `msgpack:"source"`
+}
+
+// newVimDiagnostic converts diagnostic on zero-based line. Severities of
+// vim.diagnostic are numbered from 1 for errors to 4 for hints.
+func newVimDiagnostic(row int, diag parser.Diagnostic) VimDiagnostic {
+	var severity = 1
+	switch diag.Severity {
+	case parser.SeverityWarning:
+		severity = 2
+	case parser.SeverityInfo:
+		severity = 3
+	case parser.SeverityHint:
+		severity = 4
+	}
+
+	return VimDiagnostic{
+		Line:     row,
+		Col:      diag.Range.Begin,
+		EndLine:  row,
+		EndCol:   diag.Range.End,
+		Severity: severity,
+		Message:  diag.Message,
+		Code:     diag.Code,
+		Source:   "nvim-bnf",
+	}
+}
+
+// lineDiagnostic is a diagnostic bound to zero-based line of document. Range
+// of diagnostic is relative to the line.
+type lineDiagnostic struct {
+	Line int
+	parser.Diagnostic
+}
+
+// collectDiagnostics gathers parsing errors of document and findings about
+// its rules. Diagnostics are ordered by their positions. Document should be
+// locked.
+func (d *Document) collectDiagnostics(
+	findings []lint.Finding,
+) []lineDiagnostic {
+	var diags []lineDiagnostic
+	if d.Dialect().Multiline() {
+		var errs, index = d.documentDiagnostics()
+		for _, diag := range errs {
+			var row, col = index.Locate(diag.Range.Begin)
+			diag.Range.End += col - diag.Range.Begin
+			diag.Range.Begin = col
+			diags = append(diags, lineDiagnostic{row, diag})
+		}
+	} else {
+		for row, line := range d.Lines {
+			var ast, err = d.parse(line)
+			if err != nil {
+				var diag = parser.NewDiagnostic(err)
+				diags = append(diags, lineDiagnostic{row, diag})
+				continue
+			}
+			for _, diag := range parser.Diagnostics(ast) {
+				diag = brokenSuffix(d.Dialect(), ast, diag)
+				diags = append(diags, lineDiagnostic{row, diag})
+			}
+		}
+	}
+
+	var index = d.lineIndex()
+	for _, finding := range findings {
+		var row, diag = d.locateFinding(index, finding)
+		diags = append(diags, lineDiagnostic{row, diag})
+	}
+
+	sort.SliceStable(diags, func(i, j int) bool {
+		if diags[i].Line != diags[j].Line {
+			return diags[i].Line < diags[j].Line
+		}
+		return diags[i].Range.Begin < diags[j].Range.Begin
+	})
+	return diags
+}
+
+// publishDiagnostics replaces diagnostics of document in vim.diagnostic, so
+// NeoVim shows them with virtual text, signs, and floats on its own. Warnings
+// about rules are published unless their namespace is zero.
+func (d *Document) publishDiagnostics(batch *nvim.Batch, buf nvim.Buffer) {
+	var findings []lint.Finding
+	if d.warnings != 0 {
+		findings = d.findings()
+	}
+
+	var diags = []VimDiagnostic{}
+	for _, diag := range d.collectDiagnostics(findings) {
+		diags = append(diags, newVimDiagnostic(diag.Line, diag.Diagnostic))
+	}
+	SetDiagnostics(batch, buf, d.diagnostics, diags)
+}
+
+// vimDiagnosticEnabled reports whether diagnostics are published with
+// vim.diagnostic. It is available since NeoVim 0.6 and it is disabled with
+// g:bnf_vim_diagnostic option.
+func (h *Highlighter) vimDiagnosticEnabled() bool {
+	if h.caps != nil && !h.caps.Diagnostic {
+		return false
+	}
+
+	var enabled = 1
+	var expr = "get(g:, 'bnf_vim_diagnostic', 1)"
+	if err := Eval(h.nvim, expr, &enabled); err != nil {
+		logger.Warnf("failed to get g:bnf_vim_diagnostic: %s", err)
+	}
+	return enabled != 0
+}
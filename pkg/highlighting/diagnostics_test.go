@@ -0,0 +1,32 @@
+package highlighting
+
+import (
+	"testing"
+
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+func TestCollectDiagnostics(t *testing.T) {
+	var doc = &Document{
+		dialect: parser.DialectEBNF,
+		Lines: [][]byte{
+			[]byte(`expr = term ;`),
+			[]byte(`term = "a"`),
+			[]byte(`  | "b" $ ;`),
+		},
+	}
+
+	var diags = doc.collectDiagnostics(nil)
+	if len(diags) != 1 {
+		t.Fatalf("wrong diagnostics: %v", diags)
+	}
+	if diags[0].Line != 2 || diags[0].Range.Begin != 8 {
+		t.Errorf("diagnostic is not moved to its line: %+v", diags[0])
+	}
+
+	var diag = newVimDiagnostic(diags[0].Line, diags[0].Diagnostic)
+	if diag.Line != 2 || diag.EndLine != 2 || diag.Col != 8 ||
+		diag.Severity != 1 || diag.Source != "nvim-bnf" {
+		t.Errorf("wrong vim diagnostic: %+v", diag)
+	}
+}
@@ -1,24 +1,135 @@
 package highlighting
 
 import (
+	"bytes"
+	"context"
 	"errors"
+	"reflect"
 	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+	"github.com/daskol/nvim-bnf/pkg/explain"
+	"github.com/daskol/nvim-bnf/pkg/lint"
 	"github.com/daskol/nvim-bnf/pkg/parser"
 	"github.com/neovim/go-client/nvim"
 )
 
-var DocIndex = make(map[nvim.Buffer]*Document)
+var DocIndex = NewRegistry()
 var NonTerminalIndex = make(map[string]uint)
 
+// nonTerminalGuard protects NonTerminalIndex which is updated while documents
+// of different buffers are highlighted concurrently.
+var nonTerminalGuard sync.Mutex
+
 // Document is a mirrored content of NeoVim buffer. This object provides
 // human-readable interface for document management, hightlighting and
-// versioning.
+// versioning. Document should be locked while it is read or modified.
 type Document struct {
+	sync.Mutex
+
 	Lines [][]byte
 
-	batch  *nvim.Batch
-	buffer *nvim.Buffer
+	batch     *nvim.Batch
+	buffer    *nvim.Buffer
+	dialect   parser.Dialect
+	namespace int
+	explain   bool
+
+	// warnings is a namespace of warnings about dead and repeated rules.
+	// They concern a grammar as a whole, so they are refreshed apart from
+	// hunks. Zero namespace disables them. Repeated definitions are not
+	// reported if they are treated as implicit alternation.
+	warnings int
+	implicit bool
+
+	// lint is a configuration of linter. Findings of linter are shown among
+	// warnings if a document has configuration file.
+	lint *lint.Config
+
+	// hints is a namespace of inlay hints with reference counts of rules. Zero
+	// namespace disables them. Hints which are shown are kept by lines, so
+	// only changed ones are refreshed. Nil map forces full refresh.
+	hints  int
+	hinted map[int]string
+
+	// signs enables markers in sign column on lines with diagnostics.
+	signs bool
+
+	// conceal enables display of operators as math-style symbols.
+	conceal bool
+
+	// rainbow enables coloring of brackets of nested groups by depth.
+	rainbow bool
+
+	// caps are capabilities of NeoVim which select API of annotations.
+	caps *Capabilities
+
+	// diagnostics is a namespace of vim.diagnostic. Diagnostics are published
+	// with vim.diagnostic.set() instead of virtual text unless it is zero.
+	diagnostics int
+
+	// status is a summary of document which is shown in statusline. It is
+	// computed on demand and dropped as soon as document is changed.
+	status *Status
+
+	// docs is documentation of rules by their names which is shown in hover
+	// and completion. It is dropped as soon as document is changed.
+	docs map[string]string
+
+	// deprecated are rules which are annotated with `@deprecated` by their
+	// names. Their definitions and references are highlighted distinctly.
+	deprecated map[string]*analysis.Deprecation
+
+	// start is a start symbol which is set with g:bnf_start_symbol option. It
+	// overrides declaration of start symbol in document.
+	start string
+
+	// analyzed caches passes over the whole document, so that they are not
+	// repeated for every hunk. It is valid for a single changedtick and it is
+	// dropped as soon as document is changed.
+	analyzed *analyzed
+
+	// tick is the latest changedtick of buffer. It is accessed atomically,
+	// so it could be read without lock while document is highlighted.
+	tick int64
+
+	// symbols counts non-terminals which the document added to completion
+	// index, so they could be dropped when the document is forgotten. Lines
+	// contribute to it independently, so contribution of a line is withdrawn
+	// as soon as the line is changed or removed.
+	symbols         map[string]uint
+	lineSymbols     [][]string
+	includedSymbols []string
+
+	// lightweight is set if document exceeds limits on size when it is
+	// loaded. Such document is kept in sync but it is highlighted only on
+	// demand.
+	lightweight bool
+
+	// filename is a path to file of document. Files which are included with
+	// `; %include` are looked up relative to its directory.
+	filename string
+}
+
+// analyzed keeps results of passes over the whole document at some
+// changedtick. Every result is computed on demand.
+type analyzed struct {
+	tick int
+
+	grammar    *analysis.Grammar
+	findings   []lint.Finding
+	deprecated map[string]*analysis.Deprecation
+	hints      map[int]string
+
+	// diags are diagnostics of document in multiline dialect. Their ranges
+	// are relative to the beginning of document.
+	diags []parser.Diagnostic
+	index parser.LineIndex
+
+	linted, annotated, parsed bool
 }
 
 // Get returns line in document if it exists.
@@ -30,34 +141,135 @@ func (d *Document) Get(idx int) ([]byte, bool) {
 	}
 }
 
+// Dialect returns notation in which document is written. It is classic BNF
+// unless another dialect is set explicitly.
+func (d *Document) Dialect() parser.Dialect {
+	if d.dialect == "" {
+		return parser.DialectBNF
+	}
+	return d.dialect
+}
+
+// Lightweight reports whether document is highlighted only on demand since
+// it is too large.
+func (d *Document) Lightweight() bool {
+	return d.lightweight
+}
+
+// Tick returns the latest changedtick of buffer.
+func (d *Document) Tick() int {
+	return int(atomic.LoadInt64(&d.tick))
+}
+
+// SetTick records changedtick of buffer. Ticks older than the latest one are
+// ignored.
+func (d *Document) SetTick(tick int) {
+	for {
+		var prev = atomic.LoadInt64(&d.tick)
+		if int64(tick) <= prev {
+			return
+		}
+		if atomic.CompareAndSwapInt64(&d.tick, prev, int64(tick)) {
+			return
+		}
+	}
+}
+
 // NoLines returns number of lines in document.
 func (d *Document) NoLines() int {
 	return len(d.Lines)
 }
 
-// Update updates document with a hunk of lines.
+// invalidate drops everything which is computed from document, so that it
+// is computed again on the next highlighting.
+func (d *Document) invalidate() {
+	d.hinted = nil
+	d.status = nil
+	d.docs = nil
+	d.deprecated = nil
+	d.analyzed = nil
+}
+
+// Update replaces lines of document from line from up to line to exclusively
+// with a hunk of lines and returns range of the hunk in the updated document.
+// Negative to stands for the end of document, so that the whole document is
+// loaded with from = 0 and to = -1. Bounds which are out of document are
+// clamped to it. Carriage returns which are left from CRLF line breaks are
+// dropped while byte order mark is kept, so that columns match the buffer.
 func (d *Document) Update(lines [][]byte, from, to int) (int, int) {
 	var nolines = len(lines)
-	var firstLines = d.Lines[:from]
-	var lastLines [][]byte
+	if from < 0 {
+		from = 0
+	} else if from > len(d.Lines) {
+		from = len(d.Lines)
+	}
+	if to < 0 || to > len(d.Lines) {
+		to = len(d.Lines)
+	} else if to < from {
+		to = from
+	}
 
-	if to < len(d.Lines) {
-		lastLines = d.Lines[to:]
+	// Hints are attached to lines, so they are misplaced if lines are added
+	// or removed.
+	if to-from != nolines {
+		d.hinted = nil
 	}
 
-	lines = append(firstLines, lines...)
-	lines = append(lines, lastLines...)
-	d.Lines = lines
+	d.spliceSymbols(from, to, nolines)
+	d.status = nil
+	d.docs = nil
+	d.analyzed = nil
+
+	// Lines are copied to a new slice since appending to head of old one
+	// could overwrite its tail before it is copied.
+	var size = from + nolines + len(d.Lines) - to
+	var spliced = make([][]byte, 0, size)
+	spliced = append(spliced, d.Lines[:from]...)
+	for _, line := range lines {
+		spliced = append(spliced, bytes.TrimSuffix(line, cr))
+	}
+	spliced = append(spliced, d.Lines[to:]...)
+	d.Lines = spliced
 	return from, from + nolines
 }
 
+// NoDiagnostics returns total number of diagnostics of all lines.
+func (d *Document) NoDiagnostics() int {
+	if d.Dialect().Multiline() {
+		var diags, _ = d.documentDiagnostics()
+		return len(diags)
+	}
+
+	var count = 0
+	for _, line := range d.Lines {
+		if ast, err := d.parse(line); err != nil {
+			count++
+		} else {
+			count += len(parser.Diagnostics(ast))
+		}
+	}
+	return count
+}
+
 // Hightlight adds hightlight to buffer for an entire document.
-func (d *Document) Hightlight(v *nvim.Nvim, buf nvim.Buffer) {
-	d.HightlightHunk(v, buf, 0, d.NoLines())
+func (d *Document) Hightlight(
+	ctx context.Context, v *nvim.Nvim, buf nvim.Buffer,
+) error {
+	return d.HightlightHunk(ctx, v, buf, 0, d.NoLines())
 }
 
-// HightlightHunk adds hightlight to a chunk of lines of a buffer.
-func (d *Document) HightlightHunk(v *nvim.Nvim, buf nvim.Buffer, from, to int) {
+// batchLines limits number of lines which highlights are sent in a single
+// batch, so memory stays flat and UI is updated incrementally for huge
+// documents.
+const batchLines = 500
+
+// HightlightHunk adds hightlight to a chunk of lines of a buffer. Highlights
+// are sent in batches of bounded size. Highlighting stops as soon as context
+// is cancelled and batches which are not sent yet are dropped in this case.
+// It stops on the first batch which fails as well.
+func (d *Document) HightlightHunk(
+	ctx context.Context, v *nvim.Nvim, buf nvim.Buffer, from, to int,
+) error {
 	if from < 0 {
 		from = 0
 	}
@@ -66,26 +278,49 @@ func (d *Document) HightlightHunk(v *nvim.Nvim, buf nvim.Buffer, from, to int) {
 		to = d.NoLines()
 	}
 
+	// Deprecation of a rule changes highlights of its references anywhere, so
+	// the whole document is highlighted if deprecated rules are changed.
+	var deprecated = d.deprecatedRules()
+	if !reflect.DeepEqual(deprecated, d.deprecated) {
+		d.deprecated = deprecated
+		from, to = 0, d.NoLines()
+	}
+
 	logger.Debugf("hightlight hunk from %d to %d", from, to)
 	var batch = v.NewBatch()
+	var start = d.startSymbol()
 
 	for line := from; line != to; line++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if line != from && (line-from)%batchLines == 0 {
+			observeBatch(batchLines)
+			if err := execute(batch); err != nil {
+				return err
+			}
+			batch = v.NewBatch()
+		}
+
 		var ast, err = d.parse(d.Lines[line])
 
 		// Skip the line if it causes parsing errors.
 		if err != nil {
+			d.setLineSymbols(line, nil)
+			d.markFailure(batch, buf, line)
 			continue
 		}
 
 		// Update completion index.
-		switch err := d.updateCompletionIndex(ast); err {
+		switch err := d.updateCompletionIndex(line, ast); err {
 		case nil, parser.ErrNoStatements:
 		default:
 			logger.Warnf("failed to update completion index: %s", err)
 		}
 
 		// Hightlight line and set up annotated text.
-		switch err := d.hightlightLine(batch, buf, line, ast); err {
+		switch err := d.hightlightLine(batch, buf, line, ast, start); err {
 		case nil, parser.ErrNoStatements:
 		default:
 			logger.Warnf(
@@ -94,11 +329,180 @@ func (d *Document) HightlightHunk(v *nvim.Nvim, buf nvim.Buffer, from, to int) {
 		}
 	}
 
-	if err := batch.Execute(); err != nil {
-		logger.Errorf("failed to execute batch RPC call: %s", err)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if d.Dialect().Multiline() && d.diagnostics == 0 {
+		d.annotateDocument(batch, buf)
+	}
+
+	if d.warnings != 0 || d.hints != 0 || d.diagnostics != 0 {
+		d.setIncludedSymbols(d.grammar().Included)
+		if d.diagnostics != 0 {
+			d.publishDiagnostics(batch, buf)
+		} else if d.warnings != 0 {
+			d.annotateGrammar(batch, buf)
+		}
+		if d.hints != 0 {
+			// Highlighting of a line clears hints on it as well.
+			for row := from; row != to; row++ {
+				delete(d.hinted, row)
+			}
+			d.annotateHints(batch, buf)
+		}
+	}
+
+	if from != to {
+		observeBatch((to-from-1)%batchLines + 1)
+	} else {
+		observeBatch(0)
+	}
+	return execute(batch)
+}
+
+// annotateDocument shows diagnostics of a document written in multiline
+// dialect. Diagnostics could not be attributed to a hunk, so all of them are
+// refreshed.
+func (d *Document) annotateDocument(batch *nvim.Batch, buf nvim.Buffer) {
+	batch.ClearBufferHighlight(buf, d.namespace, 0, -1)
+
+	var diags, index = d.documentDiagnostics()
+	for _, diag := range diags {
+		var res int
+		var row, col = index.Locate(diag.Range.Begin)
+		var chunks = d.diagnosticChunks(diag)
+
+		diag.Range.End += col - diag.Range.Begin
+		diag.Range.Begin = col
+		d.caps.SetVirtualText(batch, &buf, d.namespace, row, chunks, NoOpts,
+			&res)
+		d.underlineDiagnostic(batch, buf, d.namespace, row, diag)
+		d.placeSign(batch, buf, d.namespace, row, diag.Severity)
 	}
 }
 
+// annotateGrammar warns about rules which are non-productive, unreachable
+// from the start rule, or repeated as well as about findings of linter. Any
+// edit could revive or kill a rule elsewhere, so warnings of the whole
+// document are refreshed.
+func (d *Document) annotateGrammar(batch *nvim.Batch, buf nvim.Buffer) {
+	batch.ClearBufferHighlight(buf, d.warnings, 0, -1)
+
+	var index = d.lineIndex()
+	for _, finding := range d.findings() {
+		var res int
+		var row, diag = d.locateFinding(index, finding)
+		var chunks = d.diagnosticChunks(diag)
+		d.caps.SetVirtualText(batch, &buf, d.warnings, row, chunks, NoOpts,
+			&res)
+		d.underlineDiagnostic(batch, buf, d.warnings, row, diag)
+		d.placeSign(batch, buf, d.warnings, row, diag.Severity)
+	}
+}
+
+// findings returns warnings about rules which are non-productive,
+// unreachable, or repeated together with findings of linter. They are cached
+// until document is changed, so callers could append to them but they should
+// not modify them.
+func (d *Document) findings() []lint.Finding {
+	var cache = d.cache()
+	if !cache.linted {
+		cache.findings = d.collectFindings(d.grammar())
+		cache.linted = true
+	}
+	return cache.findings[:len(cache.findings):len(cache.findings)]
+}
+
+func (d *Document) collectFindings(
+	grammar *analysis.Grammar,
+) []lint.Finding {
+	var liveness = grammar.Liveness()
+	var duplicates = grammar.Duplicates(d.implicit)
+	var findings []lint.Finding
+	for _, rule := range grammar.Rules {
+		var diags = liveness.Diagnostics(rule)
+		diags = append(diags, duplicates[rule]...)
+		for _, diag := range diags {
+			var finding = lint.Finding{Rule: rule, Diagnostic: diag}
+			findings = append(findings, finding)
+		}
+	}
+	if d.lint != nil {
+		findings = append(findings,
+			lint.LintSource(d.Dialect(), d.Lines, grammar, d.lint)...)
+	}
+	return findings
+}
+
+// locateFinding returns line of a finding and its diagnostic where range is
+// relative to the line. Ranges of findings of multiline dialects are relative
+// to the whole document.
+func (d *Document) locateFinding(
+	index parser.LineIndex, finding lint.Finding,
+) (int, parser.Diagnostic) {
+	var diag, row = finding.Diagnostic, finding.Line()
+	if d.Dialect().Multiline() && finding.Rule != nil {
+		var col int
+		row, col = index.Locate(diag.Range.Begin)
+		diag.Range.End += col - diag.Range.Begin
+		diag.Range.Begin = col
+	}
+	return row, diag
+}
+
+// cache returns results of passes over document at the latest changedtick.
+// They are dropped if buffer is changed since they were computed.
+func (d *Document) cache() *analyzed {
+	if d.analyzed == nil || d.analyzed.tick != d.Tick() {
+		d.analyzed = &analyzed{tick: d.Tick()}
+	}
+	return d.analyzed
+}
+
+// grammar collects production rules of document together with rules of
+// included files. Start symbol which is set with option takes precedence
+// over declaration in document. Grammar is shared until document is changed,
+// so it should not be modified.
+func (d *Document) grammar() *analysis.Grammar {
+	var cache = d.cache()
+	if cache.grammar == nil {
+		cache.grammar = analysis.NewGrammar(d.Dialect(), d.Lines)
+		cache.grammar.Included = d.includedRules()
+		if d.start != "" {
+			cache.grammar.Start = d.start
+		}
+	}
+	return cache.grammar
+}
+
+// lineIndex returns index of lines of document joined with new lines.
+func (d *Document) lineIndex() parser.LineIndex {
+	var cache = d.cache()
+	if cache.index == nil {
+		_, cache.index = parser.JoinLines(d.Lines)
+	}
+	return cache.index
+}
+
+// documentDiagnostics parses document as a whole and returns its diagnostics
+// together with index of lines.
+func (d *Document) documentDiagnostics() (
+	[]parser.Diagnostic, parser.LineIndex,
+) {
+	var cache = d.cache()
+	if !cache.parsed {
+		var source, _ = parser.JoinLines(d.Lines)
+		if ast, err := d.parse(source); err != nil {
+			cache.diags = []parser.Diagnostic{parser.NewDiagnostic(err)}
+		} else {
+			cache.diags = parser.Diagnostics(ast)
+		}
+		cache.parsed = true
+	}
+	return cache.diags, d.lineIndex()
+}
+
 func (d *Document) parse(line []byte) (*parser.AST, error) {
 	var ast *parser.AST
 	var err error
@@ -110,7 +514,11 @@ func (d *Document) parse(line []byte) (*parser.AST, error) {
 		}
 	}()
 
-	if ast, err = parser.Parse(line); err != nil {
+	var start = time.Now()
+	ast, err = parser.ParseDialect(d.Dialect(), line)
+	parseTimings.Observe(time.Since(start))
+
+	if err != nil {
 		logger.Warnf("failed to parse: %s", err)
 		return nil, err
 	} else {
@@ -118,46 +526,53 @@ func (d *Document) parse(line []byte) (*parser.AST, error) {
 	}
 }
 
+// startSymbol returns start symbol of document. It is set with an option,
+// declared with `; %start`, or it is the first rule of document.
+func (d *Document) startSymbol() string {
+	return d.grammar().StartSymbol()
+}
+
+// deprecatedRules returns rules of document which are annotated with
+// `@deprecated`. It returns nil if there are no such rules.
+func (d *Document) deprecatedRules() map[string]*analysis.Deprecation {
+	var cache = d.cache()
+	if !cache.annotated {
+		if deprecated := d.grammar().Deprecated(); len(deprecated) != 0 {
+			cache.deprecated = deprecated
+		}
+		cache.annotated = true
+	}
+	return cache.deprecated
+}
+
 func (d *Document) hightlightLine(
 	batch *nvim.Batch,
 	buf nvim.Buffer,
 	row int,
 	ast *parser.AST,
+	start string,
 ) error {
 	batch.ClearBufferHighlight(buf, -1, row, row+1)
 
-	// Traverse abstract tree and hightlight lexemes.
-	var nonodes, err = ast.Traverse(func(node parser.Node) error {
-		var grp string
-		var begin, end, res int
-
-		switch node := node.(type) {
-		case *parser.AssignmentExpression:
-			grp = "Operator"
-			begin = node.Begin
-			end = node.End
-		case *parser.Terminal:
-			grp = "String"
-			begin = node.Begin
-			end = node.End
-		case *parser.NonTerminal:
-			grp = "Identifier"
-			begin = node.Begin
-			end = node.End
-		case *parser.AlternativeExpression:
-			grp = "Operator"
-			begin = node.Begin
-			end = node.End
-		case *parser.Comment:
-			grp = "Comment"
-		default:
-			return nil
+	// Classify lexemes and hightlight them according to their classes.
+	var tokens, nonodes, err = LineTokens(d.Dialect(), ast, start,
+		d.deprecated)
+	var rainbow map[int]string
+	if d.rainbow {
+		rainbow = RainbowGroups(ast)
+	}
+	for _, token := range tokens {
+		var res int
+		var grp = token.Group()
+		if group, ok := rainbow[token.Begin]; ok &&
+			token.Type == TokenDelimiter {
+			grp = group
 		}
-
-		batch.AddBufferHighlight(buf, 0, grp, row, begin, end, &res)
-
-		return nil
-	})
+		batch.AddBufferHighlight(buf, 0, grp, row, token.Begin, token.End, &res)
+	}
+	if d.conceal {
+		d.concealLine(batch, buf, row, tokens)
+	}
 
 	// If error was occured during traversing then exit.
 	if err != nil {
@@ -169,28 +584,179 @@ func (d *Document) hightlightLine(
 		return nil
 	}
 
-	// Update virtual text with error annotation.
-	if err := ast.Error(); err != nil {
+	// Update virtual text with error annotations. Lines of multiline dialects
+	// are not self-contained, so they are annotated as a whole document.
+	// Diagnostics which are published to vim.diagnostic are annotated by
+	// NeoVim itself.
+	if d.Dialect().Multiline() || d.diagnostics != 0 {
+		return nil
+	}
+
+	for _, diag := range parser.Diagnostics(ast) {
+		diag = brokenSuffix(d.Dialect(), ast, diag)
 		var res = 0
-		var text = "syn: " + err.Error()
-		if err, ok := err.(*parser.DescError); ok {
-			text = err.String()
-		}
-		var chunks = []Chunk{NewChunk(text, "Error")}
-		SetVirtualText(batch, &buf, 0, row, chunks, NoOpts, &res)
+		var chunks = d.diagnosticChunks(diag)
+		d.caps.SetVirtualText(batch, &buf, d.namespace, row, chunks, NoOpts,
+			&res)
+		d.underlineDiagnostic(batch, buf, d.namespace, row, diag)
+		d.placeSign(batch, buf, d.namespace, row, diag.Severity)
 	}
 
 	return nil
 }
 
-func (d *Document) updateCompletionIndex(ast *parser.AST) error {
+// diagnosticChunks renders diagnostic as virtual text. In explain mode it is
+// followed by an educational note on the diagnostic.
+func (d *Document) diagnosticChunks(diag parser.Diagnostic) []Chunk {
+	var grp = "Error"
+	if diag.Severity == parser.SeverityWarning {
+		grp = "WarningMsg"
+	}
+
+	var text = diag.Code + ": " + diag.Message
+	var chunks = []Chunk{NewChunk(text, grp)}
+	if note := explain.Note(diag.Code); d.explain && note != "" {
+		chunks = append(chunks, NewChunk(" "+note, "Comment"))
+	}
+	return chunks
+}
+
+// underlineDiagnostic marks the offending byte range of a line with undercurl
+// in a namespace.
+func (d *Document) underlineDiagnostic(
+	batch *nvim.Batch,
+	buf nvim.Buffer,
+	ns int,
+	row int,
+	diag parser.Diagnostic,
+) {
+	var line, ok = d.Get(row)
+	if !ok || len(line) == 0 {
+		return
+	}
+
+	// Parser could fail at the end of line. In this case there is nothing to
+	// underline so we mark the last character instead.
+	var begin, end = diag.Range.Begin, diag.Range.End
+	if begin >= len(line) {
+		begin = len(line) - 1
+	}
+	if end > len(line) || end <= begin {
+		end = begin + 1
+	}
+
+	var grp = "BnfErrorUnderline"
+	if diag.Severity == parser.SeverityWarning {
+		grp = "BnfWarningUnderline"
+	}
+
+	var res int
+	d.caps.Highlight(batch, buf, ns, grp, row, begin, end, &res)
+}
+
+func (d *Document) updateCompletionIndex(row int, ast *parser.AST) error {
+	var names []string
 	var _, err = ast.Traverse(func(node parser.Node) error {
 		if node, ok := node.(*parser.NonTerminal); ok {
-			var counter = NonTerminalIndex[string(node.Name)]
-			NonTerminalIndex[string(node.Name)] = counter + 1
+			names = append(names, string(node.Name))
 		}
 
 		return nil
 	})
+	d.setLineSymbols(row, names)
 	return err
 }
+
+// setLineSymbols replaces contribution of a line to completion index.
+func (d *Document) setLineSymbols(row int, names []string) {
+	for len(d.lineSymbols) <= row {
+		d.lineSymbols = append(d.lineSymbols, nil)
+	}
+
+	var prev = d.lineSymbols[row]
+	d.lineSymbols[row] = names
+
+	nonTerminalGuard.Lock()
+	defer nonTerminalGuard.Unlock()
+	for _, name := range prev {
+		d.unregisterSymbol(name)
+	}
+	for _, name := range names {
+		d.registerSymbol(name)
+	}
+}
+
+// spliceSymbols withdraws contributions of lines from one to another (not
+// inclusive) and reserves empty contributions of a number of lines which
+// replace them.
+func (d *Document) spliceSymbols(from, to, nolines int) {
+	// Contributions are aligned with lines, so lines which are not
+	// highlighted yet get empty ones.
+	if pad := len(d.Lines) - len(d.lineSymbols); pad > 0 {
+		d.lineSymbols = append(d.lineSymbols, make([][]string, pad)...)
+	}
+	if from > len(d.lineSymbols) {
+		from = len(d.lineSymbols)
+	}
+	if to > len(d.lineSymbols) {
+		to = len(d.lineSymbols)
+	}
+
+	nonTerminalGuard.Lock()
+	for _, names := range d.lineSymbols[from:to] {
+		for _, name := range names {
+			d.unregisterSymbol(name)
+		}
+	}
+	nonTerminalGuard.Unlock()
+
+	var tail = append([][]string{}, d.lineSymbols[to:]...)
+	d.lineSymbols = append(d.lineSymbols[:from], make([][]string, nolines)...)
+	d.lineSymbols = append(d.lineSymbols, tail...)
+}
+
+// registerSymbol adds non-terminal to completion index on behalf of the
+// document. It should be called with nonTerminalGuard held.
+func (d *Document) registerSymbol(name string) {
+	if d.symbols == nil {
+		d.symbols = make(map[string]uint)
+	}
+	d.symbols[name]++
+	NonTerminalIndex[name]++
+}
+
+// unregisterSymbol removes a reference to non-terminal from completion index
+// on behalf of the document. Entries without references are removed. It
+// should be called with nonTerminalGuard held.
+func (d *Document) unregisterSymbol(name string) {
+	if d.symbols[name] == 0 {
+		return
+	}
+
+	if d.symbols[name]--; d.symbols[name] == 0 {
+		delete(d.symbols, name)
+	}
+
+	if NonTerminalIndex[name] <= 1 {
+		delete(NonTerminalIndex, name)
+	} else {
+		NonTerminalIndex[name]--
+	}
+}
+
+// dropSymbols removes non-terminals of the document from completion index.
+// Non-terminals which are used by other documents are kept.
+func (d *Document) dropSymbols() {
+	nonTerminalGuard.Lock()
+	defer nonTerminalGuard.Unlock()
+	for name, count := range d.symbols {
+		if NonTerminalIndex[name] <= count {
+			delete(NonTerminalIndex, name)
+		} else {
+			NonTerminalIndex[name] -= count
+		}
+	}
+	d.symbols = nil
+	d.lineSymbols = nil
+	d.includedSymbols = nil
+}
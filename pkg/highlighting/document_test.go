@@ -0,0 +1,161 @@
+package highlighting
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// splice is a reference implementation of Document.Update.
+func splice(lines, hunk [][]byte, from, to int) [][]byte {
+	var result [][]byte
+	result = append(result, lines[:from]...)
+	result = append(result, hunk...)
+	return append(result, lines[to:]...)
+}
+
+func makeLines(prefix string, n int) [][]byte {
+	var lines = make([][]byte, n)
+	for idx := range lines {
+		lines[idx] = []byte(fmt.Sprintf("<%s%d> ::= \"x\"", prefix, idx))
+	}
+	return lines
+}
+
+func TestDocumentUpdate(t *testing.T) {
+	var tests = []struct {
+		name     string
+		lines    int
+		hunk     int
+		from, to int
+		expected [2]int
+		result   [][]byte
+	}{
+		{"load", 0, 3, 0, -1, [2]int{0, 3}, makeLines("h", 3)},
+		{"reload", 2, 1, 0, -1, [2]int{0, 1}, makeLines("h", 1)},
+		{
+			"insert", 3, 1, 1, 1, [2]int{1, 2},
+			[][]byte{makeLines("l", 3)[0], makeLines("h", 1)[0],
+				makeLines("l", 3)[1], makeLines("l", 3)[2]},
+		},
+		{"delete", 3, 0, 0, 2, [2]int{0, 0}, makeLines("l", 3)[2:]},
+		{
+			"replace", 2, 1, 1, 2, [2]int{1, 2},
+			[][]byte{makeLines("l", 2)[0], makeLines("h", 1)[0]},
+		},
+		{
+			"out of range", 2, 1, 5, 7, [2]int{2, 3},
+			append(makeLines("l", 2), makeLines("h", 1)...),
+		},
+		{"inverted", 2, 0, 1, 0, [2]int{1, 1}, makeLines("l", 2)},
+	}
+
+	for _, test := range tests {
+		var doc = &Document{Lines: makeLines("l", test.lines)}
+		var from, to = doc.Update(makeLines("h", test.hunk), test.from,
+			test.to)
+		if [2]int{from, to} != test.expected {
+			t.Errorf("%s: wrong range of hunk: [%d, %d)", test.name, from, to)
+		}
+		if len(test.result) == 0 {
+			test.result = nil
+		}
+		if len(doc.Lines) == 0 {
+			doc.Lines = nil
+		}
+		if !reflect.DeepEqual(doc.Lines, test.result) {
+			t.Errorf("%s: wrong lines: %q", test.name, doc.Lines)
+		}
+	}
+}
+
+func TestDocumentUpdateCRLF(t *testing.T) {
+	var doc = &Document{}
+	doc.Update([][]byte{
+		[]byte("\ufeff<a> ::= <b>\r"),
+		[]byte("<b> ::= \"c\"\r"),
+	}, 0, -1)
+
+	var expected = [][]byte{
+		[]byte("\ufeff<a> ::= <b>"),
+		[]byte("<b> ::= \"c\""),
+	}
+	if !reflect.DeepEqual(doc.Lines, expected) {
+		t.Errorf("wrong lines: %q", doc.Lines)
+	}
+	if count := doc.NoDiagnostics(); count != 0 {
+		t.Errorf("lines are not parsed: %d diagnostics", count)
+	}
+}
+
+func TestDocumentCache(t *testing.T) {
+	var doc = &Document{}
+	doc.SetTick(1)
+	doc.Update([][]byte{
+		[]byte("<a> ::= <b>"),
+		[]byte("; @deprecated"),
+		[]byte("<b> ::= \"c\""),
+	}, 0, -1)
+
+	var grammar = doc.grammar()
+	if doc.grammar() != grammar {
+		t.Errorf("grammar is not cached")
+	}
+	if start := doc.startSymbol(); start != "a" {
+		t.Errorf("wrong start symbol: %q", start)
+	}
+	if deprecated := doc.deprecatedRules(); deprecated["b"] == nil {
+		t.Errorf("wrong deprecated rules: %v", deprecated)
+	}
+
+	doc.SetTick(2)
+	if doc.grammar() == grammar {
+		t.Errorf("grammar is not dropped on new changedtick")
+	}
+
+	grammar = doc.grammar()
+	doc.Update([][]byte{[]byte("<d> ::= \"e\"")}, 0, -1)
+	if doc.grammar() == grammar {
+		t.Errorf("grammar is not dropped on update")
+	}
+	if start := doc.startSymbol(); start != "d" {
+		t.Errorf("wrong start symbol after update: %q", start)
+	}
+	if deprecated := doc.deprecatedRules(); deprecated != nil {
+		t.Errorf("wrong deprecated rules after update: %v", deprecated)
+	}
+}
+
+func TestDocumentUpdateRandom(t *testing.T) {
+	var rnd = rand.New(rand.NewSource(42))
+	var doc = &Document{}
+	var model [][]byte
+
+	for step := 0; step < 1000; step++ {
+		var from = rnd.Intn(len(model) + 1)
+		var to = from + rnd.Intn(len(model)-from+1)
+		var hunk = makeLines(fmt.Sprintf("s%d_", step), rnd.Intn(4))
+
+		// Capacity of lines is reserved, so that appending to them in place
+		// would corrupt the tail.
+		doc.Lines = append(make([][]byte, 0, 2*len(doc.Lines)+4),
+			doc.Lines...)
+		doc.Update(hunk, from, to)
+		model = splice(model, hunk, from, to)
+
+		if len(doc.Lines) != len(model) {
+			t.Fatalf("step %d: wrong number of lines: %d != %d", step,
+				len(doc.Lines), len(model))
+		}
+		for idx := range model {
+			if string(doc.Lines[idx]) != string(model[idx]) {
+				t.Fatalf("step %d: wrong line %d: %q", step, idx,
+					doc.Lines[idx])
+			}
+		}
+		if len(doc.lineSymbols) != len(doc.Lines) {
+			t.Fatalf("step %d: symbols are not aligned with lines", step)
+		}
+	}
+}
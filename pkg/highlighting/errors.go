@@ -0,0 +1,75 @@
+package highlighting
+
+import (
+	"reflect"
+)
+
+// Codes of errors which are returned to NeoVim from RPC handlers.
+const (
+	CodeInternal       = "R000"
+	CodeInvalidArgs    = "R001"
+	CodeUnknownRule    = "R002"
+	CodeUnknownDialect = "R003"
+	CodeAttachment     = "R004"
+	CodeUnportable     = "R005"
+	CodeUnknownAction  = "R006"
+	CodeTimeout        = "R007"
+)
+
+// Error is a failure of RPC handler. It is returned to NeoVim as a string of
+// the form `nvim-bnf: R001: message`, so callers which wrap handlers with
+// pcall could tell failures apart by code.
+type Error struct {
+	Code    string
+	Message string
+}
+
+func newError(code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	return "nvim-bnf: " + e.Code + ": " + e.Message
+}
+
+// asError converts an arbitrary error to structured one. Errors which are not
+// structured yet come from NeoVim API or operating system, so they are
+// internal ones.
+func asError(err error) error {
+	switch err := err.(type) {
+	case nil:
+		return nil
+	case *Error:
+		return err
+	default:
+		return newError(CodeInternal, err.Error())
+	}
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// withErrors wraps RPC handler so that error which it returns is always a
+// structured one. Handler should return error as its last result.
+func withErrors(handler interface{}) interface{} {
+	var fn = reflect.ValueOf(handler)
+	var typ = fn.Type()
+	if typ.NumOut() == 0 || typ.Out(typ.NumOut()-1) != errorType {
+		panic("nvim-bnf: handler does not return error: " + typ.String())
+	}
+
+	return reflect.MakeFunc(typ, func(args []reflect.Value) []reflect.Value {
+		var results []reflect.Value
+		if typ.IsVariadic() {
+			results = fn.CallSlice(args)
+		} else {
+			results = fn.Call(args)
+		}
+
+		var last = len(results) - 1
+		if err, ok := results[last].Interface().(error); ok {
+			results[last] = reflect.New(errorType).Elem()
+			results[last].Set(reflect.ValueOf(asError(err)))
+		}
+		return results
+	}).Interface()
+}
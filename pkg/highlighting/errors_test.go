@@ -0,0 +1,37 @@
+package highlighting
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWithErrors(t *testing.T) {
+	var handler = func(args []string) error {
+		switch len(args) {
+		case 0:
+			return nil
+		case 1:
+			return newError(CodeUnknownRule, "there is no rule "+args[0])
+		default:
+			return errors.New("nvim-bnf: result is false")
+		}
+	}
+
+	var wrapped = withErrors(handler).(func([]string) error)
+	if err := wrapped(nil); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	var err = wrapped([]string{"expr"})
+	var expected = "nvim-bnf: R002: there is no rule expr"
+	if err, ok := err.(*Error); !ok || err.Code != CodeUnknownRule {
+		t.Errorf("wrong error: %#v", err)
+	} else if msg := err.Error(); msg != expected {
+		t.Errorf("wrong error message: %s", msg)
+	}
+
+	err = wrapped([]string{"a", "b"})
+	if err, ok := err.(*Error); !ok || err.Code != CodeInternal {
+		t.Errorf("plain error is not internal one: %#v", err)
+	}
+}
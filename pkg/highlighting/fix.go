@@ -0,0 +1,37 @@
+package highlighting
+
+import (
+	"fmt"
+
+	"github.com/daskol/nvim-bnf/pkg/i18n"
+	"github.com/daskol/nvim-bnf/pkg/lint"
+)
+
+// HandleFixCommand fixes findings of auto-fixable lint rules in the current
+// buffer, e.g. trailing whitespaces or quoting of terminals. Rules are
+// configured with .bnflint.toml, and default options are used if there is no
+// configuration. Buffer is rewritten with a single call, so the change is
+// undone at once.
+func (h *Highlighter) HandleFixCommand() error {
+	logger.Debugf("HandleFixCommand()")
+
+	var buf, err = CurrentBuffer(h.nvim)
+	if err != nil {
+		return err
+	}
+
+	lines, err := BufferLines(h.nvim, buf, 0, -1, true)
+	if err != nil {
+		return err
+	}
+
+	var cfg = h.lintConfig(buf)
+	var fixed, count = lint.Apply(h.dialectOf(buf), lines, cfg)
+	if count > 0 {
+		if err := SetBufferLines(h.nvim, buf, 0, -1, true, fixed); err != nil {
+			return err
+		}
+	}
+	return WriteOut(h.nvim, fmt.Sprintf(i18n.T("Fixed %d findings.")+"\n",
+		count))
+}
@@ -0,0 +1,66 @@
+package highlighting
+
+import (
+	"bytes"
+
+	"github.com/daskol/nvim-bnf/pkg/format"
+)
+
+// HandleFormatCommand formats the current buffer in the same way as nvim-bnf
+// fmt does. Style of layout is read from .editorconfig of a file and options
+// g:bnf_format_style (compact or aligned) and g:bnf_format_column override
+// it. Buffer is rewritten with a single call, so the change is undone at
+// once.
+func (h *Highlighter) HandleFormatCommand() error {
+	logger.Debugf("HandleFormatCommand()")
+
+	var buf, err = CurrentBuffer(h.nvim)
+	if err != nil {
+		return err
+	}
+
+	lines, err := BufferLines(h.nvim, buf, 0, -1, true)
+	if err != nil {
+		return err
+	}
+
+	style, err := h.formatStyle(h.bufferName(buf))
+	if err != nil {
+		return err
+	}
+
+	var source = bytes.Join(lines, nl)
+	var formatted = format.SourceStyle(h.dialectOf(buf), source, style)
+	formatted = bytes.TrimSuffix(formatted, nl)
+	if bytes.Equal(source, formatted) {
+		return nil
+	}
+	return SetBufferLines(h.nvim, buf, 0, -1, true, bytes.Split(formatted, nl))
+}
+
+// formatStyle returns style of layout of a file.
+func (h *Highlighter) formatStyle(filename string) (format.Style, error) {
+	var style = format.DefaultStyle
+	if filename != "" {
+		style = format.LoadStyle(filename)
+	}
+
+	var name string
+	var expr = "get(g:, 'bnf_format_style', '')"
+	if err := Eval(h.nvim, expr, &name); err != nil {
+		logger.Warnf("failed to get g:bnf_format_style: %s", err)
+	} else if value, ok := format.LookupStyle(name); ok {
+		style.Align = value.Align
+	} else if name != "" {
+		return style, newError(CodeInvalidArgs, "unknown style "+name)
+	}
+
+	var column int
+	expr = "get(g:, 'bnf_format_column', 0)"
+	if err := Eval(h.nvim, expr, &column); err != nil {
+		logger.Warnf("failed to get g:bnf_format_column: %s", err)
+	} else if column > 0 {
+		style.Column = column
+	}
+	return style, nil
+}
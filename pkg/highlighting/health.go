@@ -0,0 +1,110 @@
+package highlighting
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Version of the plugin. It is set at link time with
+// `-ldflags "-X github.com/daskol/nvim-bnf/pkg/highlighting.Version=..."`.
+var Version = "devel"
+
+// timingsLimit is a number of recent highlights which timings are kept.
+const timingsLimit = 64
+
+// Timings is a ring buffer of durations of recent highlights.
+type Timings struct {
+	guard   sync.Mutex
+	samples []time.Duration
+	next    int
+}
+
+// Add records duration of a highlight and drops the oldest one beyond limit.
+func (t *Timings) Add(elapsed time.Duration) {
+	t.guard.Lock()
+	defer t.guard.Unlock()
+	if len(t.samples) < timingsLimit {
+		t.samples = append(t.samples, elapsed)
+	} else {
+		t.samples[t.next] = elapsed
+	}
+	t.next = (t.next + 1) % timingsLimit
+}
+
+// Stats returns number of samples together with their mean and maximal
+// durations.
+func (t *Timings) Stats() (int, time.Duration, time.Duration) {
+	t.guard.Lock()
+	defer t.guard.Unlock()
+
+	var total, max time.Duration
+	for _, sample := range t.samples {
+		total += sample
+		if sample > max {
+			max = sample
+		}
+	}
+
+	if len(t.samples) == 0 {
+		return 0, 0, 0
+	}
+	return len(t.samples), total / time.Duration(len(t.samples)), max
+}
+
+// HealthItem is a line of health report. Level is one of ok, info, warn, or
+// error.
+type HealthItem struct {
+	Level   string `msgpack:"level"`
+	Message string `msgpack:"message"`
+}
+
+// HandleHealthCheck reports state of the plugin for :checkhealth.
+func (h *Highlighter) HandleHealthCheck(args []interface{}) (
+	[]HealthItem, error,
+) {
+	logger.Debugf("HandleHealthCheck(%v)", args)
+
+	var report = []HealthItem{
+		{"info", "version " + Version},
+		{"ok", fmt.Sprintf("RPC channel %d is connected",
+			h.nvim.ChannelID())},
+	}
+
+	if _, err := logger.Infof("health check is requested"); err != nil {
+		var msg = fmt.Sprintf("logging is not available: %s", err)
+		report = append(report, HealthItem{"warn", msg})
+	} else {
+		report = append(report, HealthItem{"ok", "logging is available"})
+	}
+
+	report = append(report, HealthItem{"info", "NeoVim capabilities: " +
+		h.caps.String()})
+
+	var bufs = DocIndex.Buffers()
+	report = append(report, HealthItem{"info",
+		fmt.Sprintf("%d buffers are attached", len(bufs))})
+	for _, buf := range bufs {
+		var doc, ok = DocIndex.Get(buf)
+		if !ok {
+			continue
+		}
+		var name, err = BufferName(h.nvim, buf)
+		if err != nil {
+			return nil, err
+		}
+		report = append(report, HealthItem{"info", fmt.Sprintf(
+			"buffer %d (%s) is parsed as %s at changedtick %d", buf, name,
+			doc.Dialect(), doc.Tick())})
+	}
+
+	if count, mean, max := h.timings.Stats(); count == 0 {
+		report = append(report, HealthItem{"info", "nothing is highlighted"})
+	} else {
+		report = append(report, HealthItem{"info", fmt.Sprintf(
+			"%d recent highlights took %s on average and %s at most",
+			count, mean, max)})
+	}
+
+	return report, nil
+}
@@ -0,0 +1,29 @@
+package highlighting
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimings(t *testing.T) {
+	var timings Timings
+	if count, _, _ := timings.Stats(); count != 0 {
+		t.Errorf("there are timings without samples: %d", count)
+	}
+
+	for idx := 1; idx <= timingsLimit+2; idx++ {
+		timings.Add(time.Duration(idx) * time.Millisecond)
+	}
+
+	// The first two samples are dropped, so the rest are from 3 to 66.
+	var count, mean, max = timings.Stats()
+	if count != timingsLimit {
+		t.Errorf("wrong number of samples: %d", count)
+	}
+	if expected := (timingsLimit + 5) * time.Millisecond / 2; mean != expected {
+		t.Errorf("wrong mean: %s", mean)
+	}
+	if max != (timingsLimit+2)*time.Millisecond {
+		t.Errorf("wrong max: %s", max)
+	}
+}
@@ -4,8 +4,18 @@ package highlighting
 
 import (
 	"os"
-
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/daskol/nvim-bnf/pkg/cache"
+	"github.com/daskol/nvim-bnf/pkg/i18n"
+	"github.com/daskol/nvim-bnf/pkg/language"
+	"github.com/daskol/nvim-bnf/pkg/lint"
 	"github.com/daskol/nvim-bnf/pkg/logging"
+	"github.com/daskol/nvim-bnf/pkg/parser"
 	"github.com/neovim/go-client/nvim"
 	"github.com/neovim/go-client/nvim/plugin"
 )
@@ -13,11 +23,12 @@ import (
 var logger = logging.Get()
 
 // GenManifest generates a remote plugin manifest. It is parametrized with
-// plugin host name. In this particular case host name is name of plugin
-// binary.
-func GenManifest(host string) []byte {
+// plugin host name and patterns of files which plugin attaches to. In this
+// particular case host name is name of plugin binary.
+func GenManifest(host string, patterns []string) []byte {
 	hl := new(Highlighter)
 	hl.plugin = plugin.New(nil)
+	hl.patterns = patterns
 	hl.registerVimLExtHandlers()
 	return hl.plugin.Manifest(host)
 }
@@ -43,24 +54,83 @@ func RunPlugin() error {
 		return err
 	}
 
-	return hl.Serve()
+	// Autocommands for custom patterns and exit are defined as soon as plugin
+	// starts serving. They are not in manifest since they should not start
+	// plugin.
+	go func() {
+		hl.setupLogging()
+		hl.setupCallPolicy()
+		hl.registerFilePatterns()
+		hl.registerBufUnload()
+		hl.registerVimLeave()
+		hl.registerColorScheme()
+		hl.registerSettingsRefresh()
+	}()
+
+	hl.closeOnSignal()
+	err = hl.Serve()
+	hl.shutdown()
+	return err
 }
 
 // Highlighter is an implementation of semantic hightlighting for BNF. It
 // manages all RPC request and response between NeoVim instance and BNF parser.
 type Highlighter struct {
-	nvim   *nvim.Nvim
-	plugin *plugin.Plugin
+	nvim       *nvim.Nvim
+	plugin     *plugin.Plugin
+	namespace  int
+	warnings   int
+	hints      int
+	references int
+	history    *History
+	patterns   []string
+	stopping   int32
+	timings    Timings
+
+	pipelineOnce sync.Once
+	workers      *Pipeline
+
+	// linesGuard serializes handling of buf_lines events with lines which
+	// are pulled from buffers when initial event is not delivered in time.
+	linesGuard sync.Mutex
+
+	// settings caches options which are consulted on every buf_lines event.
+	// It is nil until options are read.
+	settings      *settings
+	settingsGuard sync.Mutex
+
+	// preview is a floating window which lists non-terminals while they are
+	// typed. Zero window means that preview is closed.
+	preview      nvim.Window
+	previewGuard sync.Mutex
+
+	// derivations are states of :BNFDerive by windows which show them.
+	derivations     map[nvim.Window]*language.Derivation
+	derivationGuard sync.Mutex
+
+	// caps are capabilities of NeoVim which are queried on setup.
+	caps *Capabilities
+
+	// diagnostics is a namespace of vim.diagnostic where diagnostics are
+	// published instead of virtual text. It is zero if vim.diagnostic is not
+	// available or disabled.
+	diagnostics int
 }
 
 func (h *Highlighter) HandleBufReadEvent(buf nvim.Buffer, filename string) {
 	logger.Debugf("HandleBufReadEvent(%s)", filename)
 
+	if err := h.setupNamespace(); err != nil {
+		logger.Errorf("failed to create namespace: %s", err)
+		return
+	}
+
 	if err := AttachToBuffer(h.nvim, &buf); err != nil {
 		logger.Errorf("failed to attach to buffer: %s", err)
 		return
 	}
 
+	h.awaitInitialLines(buf)
 	logger.Infof("buffer %s was attached to plugin", buf)
 }
 
@@ -73,92 +143,444 @@ func (h *Highlighter) HandleBufLinesEvent(
 		buf, changedTick, firstLine, lastLine, more,
 	)
 
+	if h.stopped() {
+		return
+	}
+
+	h.linesGuard.Lock()
+	defer h.linesGuard.Unlock()
+
+	var event = newEvent("buf_lines", *buf).WithFields(logging.Fields{
+		"changedtick": changedTick,
+		"first_line":  firstLine,
+		"last_line":   lastLine,
+	})
+	event.Debugf("event is received")
+
+	// Documents are kept in sync even if plugin is disabled, so they could be
+	// highlighted as soon as it is enabled.
+	var enabled = h.enabled()
+
 	if lastLine == -1 {
-		doc := &Document{Lines: data}
-		doc.Hightlight(h.nvim, *buf)
-		DocIndex[*buf] = doc
+		// Lines which are pulled on attach could be older than lines of
+		// events which are already handled.
+		if prev, ok := DocIndex.Get(*buf); ok && changedTick < prev.Tick() {
+			event.Debugf("stale content is ignored")
+			return
+		}
+
+		doc := &Document{
+			Lines:       data,
+			namespace:   h.namespace,
+			warnings:    h.warnings,
+			lightweight: h.limits().Exceeded(data),
+			caps:        h.caps,
+			diagnostics: h.diagnostics,
+		}
+		h.configure(doc, *buf)
+		doc.SetTick(changedTick)
+
+		// Buffer is reloaded or its lines are pulled on attach before initial
+		// event comes, so symbols of the previous document are withdrawn.
+		if prev, ok := DocIndex.Get(*buf); ok {
+			prev.Lock()
+			prev.dropSymbols()
+			prev.Unlock()
+		}
+		DocIndex.Put(*buf, doc)
+		if doc.lightweight {
+			h.warnLightweight(*buf)
+		} else if enabled {
+			h.pipeline().Submit(&highlightJob{
+				buf:   *buf,
+				doc:   doc,
+				tick:  changedTick,
+				to:    -1,
+				event: event,
+			})
+		}
 	} else {
-		var doc, ok = DocIndex[*buf]
+		var doc, ok = DocIndex.Get(*buf)
 
 		if !ok {
-			logger.Warnf("unknown buffer: %s", buf)
+			event.Warnf("unknown buffer: %s", buf)
 			return
 		}
 
+		// Stale highlighting of the whole document holds the lock, so it is
+		// aborted first. Document is updated in order of events while
+		// highlighting is deferred to pipeline.
+		doc.SetTick(changedTick)
+		enabled = enabled && !doc.lightweight
+		if enabled {
+			h.pipeline().Cancel(*buf, changedTick)
+		}
+		doc.Lock()
 		var from, to = doc.Update(data, firstLine, lastLine)
-		doc.HightlightHunk(h.nvim, *buf, from, to)
+		doc.Unlock()
+
+		if enabled {
+			h.pipeline().Submit(&highlightJob{
+				buf:     *buf,
+				doc:     doc,
+				tick:    changedTick,
+				from:    from,
+				to:      to,
+				event:   event,
+				shifted: len(data) != lastLine-firstLine,
+			})
+		}
 	}
 }
 
-func (p *Highlighter) HandleBufDetachEvent(buf *nvim.Buffer) {
+// configure sets up a document of a buffer according to options.
+func (h *Highlighter) configure(doc *Document, buf nvim.Buffer) {
+	doc.dialect = h.detectDialect(buf)
+	doc.hints = h.hintsNamespace()
+	doc.signs = h.signsEnabled()
+	doc.conceal = h.concealEnabled(buf)
+	doc.rainbow = h.rainbowEnabled()
+	doc.implicit = h.implicitAlternation()
+	doc.lint = h.lintConfig(buf)
+	doc.start = h.startSymbol()
+	doc.filename = h.bufferName(buf)
+	doc.explain = h.explainMode()
+}
+
+func (h *Highlighter) HandleBufDetachEvent(buf *nvim.Buffer) {
 	logger.Debugf("HandleBufDetachEvent(%s)", buf)
 
-	if err := DetachFromBuffer(p.nvim, buf); err != nil {
-		logger.Errorf("failed to detac buffer: %s", err)
-		return
+	// Buffer is already detached on NeoVim side (e.g. it was unloaded or
+	// detached with :BNFDetach), so only its document should be forgotten.
+	if h.forget(*buf) {
+		newEvent("buf_detach", *buf).Infof(
+			"buffer %d was detached from plugin", buf)
 	}
-
-	logger.Infof("buffer %d was detached from plugin", buf)
 }
 
 func (h *Highlighter) HandleBufChangedTickEvent(
 	buf nvim.Buffer, changedTick int,
 ) {
 	logger.Debugf("HandleBufChangedTickEvent(%s, %d)", buf, changedTick)
+	newEvent("buf_changedtick", buf).
+		WithFields(logging.Fields{"changedtick": changedTick}).
+		Debugf("event is received")
 }
 
-func (h *Highlighter) HandleNcm2OnWarmup(args []interface{}) {
-	if len(args) != 1 {
-		logger.Errorf("HandleNcm2OnWarmup(): too few arguments")
-		return
+// eventID is a counter of RPC events which is used as correlation ID.
+var eventID uint64
+
+// newEvent creates log entry which tags all records of an RPC event with the
+// same unique ID. It allows to tell apart records of concurrent events.
+func newEvent(name string, buf nvim.Buffer) *logging.Entry {
+	return logger.WithFields(logging.Fields{
+		"id":     atomic.AddUint64(&eventID, 1),
+		"event":  name,
+		"buffer": int(buf),
+	})
+}
+
+func (h *Highlighter) HandleNcm2OnWarmup(args []interface{}) error {
+	var ctx, err = ncm2Context(args)
+	if err != nil {
+		return err
 	}
 
-	var ctx, ok = args[0].(map[string]interface{})
+	logger.Debugf("HandleNcm2OnWarmup(%s)", ctx)
+	return nil
+}
 
-	if !ok {
-		logger.Errorf("HandleNcm2OnWarmup(): wrong argument type")
-		return
+func (h *Highlighter) HandleNcm2OnComplete(args []interface{}) error {
+	var ctx, err = ncm2Context(args)
+	if err != nil || !h.enabled() {
+		return err
 	}
 
-	logger.Debugf("HandleNcm2OnWarmup(%s)", ctx)
+	return h.handleNCM2OnComplete(ctx)
 }
 
-func (h *Highlighter) HandleNcm2OnComplete(args []interface{}) {
+// ncm2Context extracts completion context from arguments of NCM2 callback.
+func ncm2Context(args []interface{}) (map[string]interface{}, error) {
 	if len(args) != 1 {
-		logger.Errorf("HandleNcm2OnComplete(): too few arguments")
-		return
+		return nil, newError(CodeInvalidArgs, "exactly one argument is "+
+			"expected")
 	}
 
 	var ctx, ok = args[0].(map[string]interface{})
-
 	if !ok {
-		logger.Errorf("HandleNcm2OnComplete(): wrong argument type")
-		return
+		return nil, newError(CodeInvalidArgs, "wrong argument type")
+	}
+
+	if _, ok := ctx["startccol"].(int64); !ok {
+		return nil, newError(CodeInvalidArgs, "context has no startccol")
 	}
 
-	h.handleNCM2OnComplete(ctx)
+	return ctx, nil
 }
 
+// getCompletions returns known non-terminals in lexicographical order.
+// Documentation of rules is shown in info of completion items.
 func (h *Highlighter) getCompletions() []map[string]interface{} {
-	var matches = make([]map[string]interface{}, 0, len(NonTerminalIndex))
+	nonTerminalGuard.Lock()
+	var words = make([]string, 0, len(NonTerminalIndex))
 	for word := range NonTerminalIndex {
-		matches = append(matches, map[string]interface{}{
-			"word": word,
-		})
+		words = append(words, word)
+	}
+	nonTerminalGuard.Unlock()
+	sort.Strings(words)
+
+	var docs = completionDocs()
+	var matches = make([]map[string]interface{}, 0, len(words))
+	for _, word := range words {
+		var match = map[string]interface{}{"word": word}
+		if doc, ok := docs[word]; ok {
+			match["info"] = doc
+		}
+		matches = append(matches, match)
 	}
 	return matches
 }
 
-func (h *Highlighter) handleNCM2OnComplete(ctx map[string]interface{}) {
+func (h *Highlighter) handleNCM2OnComplete(
+	ctx map[string]interface{},
+) error {
 	logger.Debugf("HandleNcm2OnComplete(%s)", ctx)
 	var startccol = ctx["startccol"].(int64)
 	var matches = h.getCompletions()
-	var err = h.nvim.Call("ncm2#complete", nil, ctx, startccol, matches)
+	return Call(h.nvim, "ncm2#complete", nil, ctx, startccol, matches)
+}
+
+// detectDialect determines grammar notation of a buffer. Dialect is taken from
+// g:bnf_dialect option if it is set. Otherwise, it is derived from filetype or
+// buffer name. Classic BNF could be written with custom operators which are
+// set with buffer-local options (see operators).
+func (h *Highlighter) detectDialect(buf nvim.Buffer) parser.Dialect {
+	var dialect = h.baseDialect(buf)
+	if dialect != parser.DialectBNF {
+		return dialect
+	}
+	return parser.Notation(parser.Operators{
+		Definition:  h.operators(buf, "bnf_definition_operator"),
+		Alternation: h.operators(buf, "bnf_alternation"),
+	})
+}
+
+func (h *Highlighter) baseDialect(buf nvim.Buffer) parser.Dialect {
+	var name string
+	if err := Eval(h.nvim, "get(g:, 'bnf_dialect', '')", &name); err != nil {
+		logger.Warnf("failed to get g:bnf_dialect: %s", err)
+	} else if dialect, ok := parser.LookupDialect(name); ok {
+		return dialect
+	} else if name != "" {
+		logger.Warnf("unknown dialect in g:bnf_dialect: %s", name)
+	}
+
+	var filetype string
+	if err := BufferOption(h.nvim, buf, "filetype", &filetype); err != nil {
+		logger.Warnf("failed to get filetype of %s: %s", buf, err)
+	} else if dialect, ok := parser.LookupDialect(filetype); ok {
+		return dialect
+	}
+
+	if filename, err := BufferName(h.nvim, buf); err != nil {
+		logger.Warnf("failed to get name of %s: %s", buf, err)
+		return parser.DialectBNF
+	} else {
+		return parser.DetectDialect(filename)
+	}
+}
+
+// tabstop returns width of tabs which columns on screen are counted with.
+// Option g:bnf_tabstop overrides buffer-local option tabstop.
+func (h *Highlighter) tabstop(buf nvim.Buffer) int {
+	var tabstop int
+	if err := Eval(h.nvim, "get(g:, 'bnf_tabstop', 0)", &tabstop); err != nil {
+		logger.Warnf("failed to get g:bnf_tabstop: %s", err)
+	} else if tabstop > 0 {
+		return tabstop
+	}
+
+	if err := BufferOption(h.nvim, buf, "tabstop", &tabstop); err != nil {
+		logger.Warnf("failed to get tabstop of %s: %s", buf, err)
+	}
+	return tabstop
+}
+
+// operators returns spellings of an operator of BNF from buffer-local option
+// like b:bnf_definition_operator. Option is either a string or a list of
+// strings. Default operators are used if option is not set.
+func (h *Highlighter) operators(buf nvim.Buffer, option string) []string {
+	var value interface{}
+	if err := CallFunction(h.nvim, "getbufvar", &value, int(buf), option,
+		""); err != nil {
+		logger.Warnf("failed to get b:%s of %s: %s", option, buf, err)
+		return nil
+	}
+
+	switch value := value.(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		var spellings []string
+		for _, item := range value {
+			if spelling, ok := item.(string); ok {
+				spellings = append(spellings, spelling)
+			}
+		}
+		return spellings
+	default:
+		return nil
+	}
+}
 
+// explainMode reports whether diagnostics are accompanied with educational
+// notes. It is enabled with g:bnf_explain option.
+func (h *Highlighter) explainMode() bool {
+	var explain int
+	if err := Eval(h.nvim, "get(g:, 'bnf_explain', 0)", &explain); err != nil {
+		logger.Warnf("failed to get g:bnf_explain: %s", err)
+	}
+	return explain != 0
+}
+
+// implicitAlternation reports whether repeated definitions of a non-terminal
+// are alternatives of a single rule. It is set with g:bnf_implicit_alternation
+// option.
+func (h *Highlighter) implicitAlternation() bool {
+	var implicit int
+	var expr = "get(g:, 'bnf_implicit_alternation', 0)"
+	if err := Eval(h.nvim, expr, &implicit); err != nil {
+		logger.Warnf("failed to get g:bnf_implicit_alternation: %s", err)
+	}
+	return implicit != 0
+}
+
+// startSymbol returns start symbol which is set with g:bnf_start_symbol
+// option. Angle brackets around its name are optional.
+func (h *Highlighter) startSymbol() string {
+	var start string
+	var expr = "get(g:, 'bnf_start_symbol', '')"
+	if err := Eval(h.nvim, expr, &start); err != nil {
+		logger.Warnf("failed to get g:bnf_start_symbol: %s", err)
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(start, "<"), ">")
+}
+
+// lintConfig loads configuration of linter which is located next to a buffer
+// or in parent directories. It returns nil if there is no configuration, so
+// linter is disabled.
+func (h *Highlighter) lintConfig(buf nvim.Buffer) *lint.Config {
+	var filename, err = BufferName(h.nvim, buf)
+	if err != nil || filename == "" {
+		return nil
+	}
+
+	if filename = lint.FindConfig(filepath.Dir(filename)); filename == "" {
+		return nil
+	}
+
+	cfg, err := lint.LoadConfig(filename)
 	if err != nil {
-		logger.Errorf("failed call ncm2#complete: %s", err)
+		logger.Warnf("failed to load lint config: %s", err)
+		return nil
+	}
+	return cfg
+}
+
+// dialectOf returns dialect of a buffer. If buffer is not attached then
+// dialect is detected.
+func (h *Highlighter) dialectOf(buf nvim.Buffer) parser.Dialect {
+	if doc, ok := DocIndex.Get(buf); ok {
+		return doc.Dialect()
+	}
+	return h.detectDialect(buf)
+}
+
+// setupNamespace lazily creates namespace for extmarks and defines highlight
+// groups for them. Also, it loads message catalog. It could not be done in
+// advance since RPC calls are not possible until plugin starts serving.
+func (h *Highlighter) setupNamespace() error {
+	if h.namespace != 0 {
+		return nil
+	}
+
+	h.setupCapabilities()
+
+	var err error
+	if h.namespace, err = CreateNamespace(h.nvim, "nvim-bnf"); err != nil {
+		return err
+	}
+
+	var name = "nvim-bnf-warnings"
+	if h.warnings, err = CreateNamespace(h.nvim, name); err != nil {
+		return err
+	}
+
+	if h.hints, err = CreateNamespace(h.nvim, "nvim-bnf-hints"); err != nil {
+		return err
+	}
+
+	name = "nvim-bnf-references"
+	if h.references, err = CreateNamespace(h.nvim, name); err != nil {
+		return err
+	}
+
+	if h.vimDiagnosticEnabled() {
+		name = "nvim-bnf-diagnostics"
+		if h.diagnostics, err = CreateNamespace(h.nvim, name); err != nil {
+			return err
+		}
+	}
+
+	h.setupCatalog()
+	h.setupCache()
+
+	var batch = h.nvim.NewBatch()
+	defineHighlights(batch, h.caps)
+	return execute(batch)
+}
+
+// setupCache enables on-disk cache of parse trees of included files unless
+// g:bnf_cache option is zero. Directory of cache is set with g:bnf_cache_dir.
+func (h *Highlighter) setupCache() {
+	var enabled = 1
+	if err := Eval(h.nvim, "get(g:, 'bnf_cache', 1)", &enabled); err != nil {
+		logger.Warnf("failed to get g:bnf_cache: %s", err)
+	}
+	if enabled == 0 {
 		return
 	}
+
+	var dir string
+	var expr = "expand(get(g:, 'bnf_cache_dir', ''))"
+	if err := Eval(h.nvim, expr, &dir); err != nil {
+		logger.Warnf("failed to get g:bnf_cache_dir: %s", err)
+	}
+	if dir == "" {
+		dir = cache.DefaultDir()
+	}
+
+	Workspace.SetCache(cache.New(dir))
+	logger.Infof("cache of parse trees is in %s", dir)
+}
+
+// setupCatalog loads message catalog from file which is set with
+// g:bnf_catalog option. Messages are in English if there is no catalog.
+func (h *Highlighter) setupCatalog() {
+	var filename string
+	var expr = "expand(get(g:, 'bnf_catalog', ''))"
+	if err := Eval(h.nvim, expr, &filename); err != nil {
+		logger.Warnf("failed to get g:bnf_catalog: %s", err)
+		return
+	} else if filename == "" {
+		return
+	}
+
+	if catalog, err := i18n.Load(filename); err != nil {
+		logger.Errorf("failed to load message catalog: %s", err)
+	} else {
+		i18n.Use(catalog)
+	}
 }
 
 func (h *Highlighter) Serve() error {
@@ -171,15 +593,137 @@ func (h *Highlighter) registerHandlers() error {
 }
 
 func (h *Highlighter) registerAutocmdHandlers() {
+	var filePattern = strings.Join(h.patterns, ",")
+	if len(h.patterns) == 0 {
+		filePattern = strings.Join(DefaultFilePatterns, ",")
+	}
+
 	// Register autocommands.
 	for _, event := range []string{"BufRead", "BufNewFile"} {
 		var opts = &plugin.AutocmdOptions{
 			Event:   event,
 			Group:   "nvim-bnf",
-			Pattern: "*.bnf",
+			Pattern: filePattern,
 			Eval:    `expand("<afile>")`,
 		}
-		h.plugin.HandleAutocmd(opts, h.HandleBufReadEvent)
+		h.plugin.HandleAutocmd(opts, h.withRecover(event,
+			h.HandleBufReadEvent))
+	}
+
+	h.plugin.HandleAutocmd(&plugin.AutocmdOptions{
+		Event:   "BufWritePost",
+		Group:   "nvim-bnf",
+		Pattern: filePattern,
+		Eval:    bufEventEval,
+	}, h.withRecover("BufWritePost", h.HandleBufWriteEvent))
+
+	h.plugin.HandleAutocmd(&plugin.AutocmdOptions{
+		Event:   "TextChangedI",
+		Group:   "nvim-bnf",
+		Pattern: filePattern,
+		Eval:    cursorEventEval,
+	}, h.withRecover("TextChangedI", h.HandleTextChangedIEvent))
+
+	h.plugin.HandleAutocmd(&plugin.AutocmdOptions{
+		Event:   "CursorHold",
+		Group:   "nvim-bnf",
+		Pattern: filePattern,
+		Eval:    cursorHoldEventEval,
+	}, h.withRecover("CursorHold", h.HandleCursorHoldEvent))
+
+	h.plugin.HandleAutocmd(&plugin.AutocmdOptions{
+		Event:   "InsertLeave",
+		Group:   "nvim-bnf",
+		Pattern: filePattern,
+	}, h.withRecover("InsertLeave", h.HandleInsertLeaveEvent))
+}
+
+func (h *Highlighter) registerCommandHandlers() {
+	type CmdOpts = plugin.CommandOptions
+	var commands = []struct {
+		opts    CmdOpts
+		handler interface{}
+	}{
+		{CmdOpts{Name: "BNFAttach"}, h.HandleAttachCommand},
+		{
+			CmdOpts{Name: "BNFBlameRule", NArgs: "?", Bang: true},
+			h.HandleBlameRuleCommand,
+		},
+		{
+			CmdOpts{Name: "BNFCompareRules", NArgs: "+"},
+			h.HandleCompareRulesCommand,
+		},
+		{
+			CmdOpts{Name: "BNFConvert", NArgs: "1", Bang: true},
+			h.HandleConvertCommand,
+		},
+		{
+			CmdOpts{Name: "BNFDefineUndefined"},
+			h.HandleDefineUndefinedCommand,
+		},
+		{CmdOpts{Name: "BNFDerive", NArgs: "?"}, h.HandleDeriveCommand},
+		{CmdOpts{Name: "BNFDetach"}, h.HandleDetachCommand},
+		{CmdOpts{Name: "BNFFix"}, h.HandleFixCommand},
+		{CmdOpts{Name: "BNFFormat"}, h.HandleFormatCommand},
+		{
+			CmdOpts{Name: "BNFGotoDefinition"},
+			h.HandleGotoDefinitionCommand,
+		},
+		{CmdOpts{Name: "BNFHighlight"}, h.HandleHighlightCommand},
+		{
+			CmdOpts{Name: "BNFHighlightRange", Range: "."},
+			h.HandleHighlightRangeCommand,
+		},
+		{
+			CmdOpts{Name: "BNFHighlightToggle"},
+			h.HandleHighlightToggleCommand,
+		},
+		{CmdOpts{Name: "BNFHover"}, h.HandleHoverCommand},
+		{
+			CmdOpts{Name: "BNFLeftFactor", Bang: true},
+			h.HandleLeftFactorCommand,
+		},
+		{CmdOpts{Name: "BNFLineInfo"}, h.HandleLineInfoCommand},
+		{CmdOpts{Name: "BNFNewRule", NArgs: "1"}, h.HandleNewRuleCommand},
+		{CmdOpts{Name: "BNFQuickfix"}, h.HandleQuickfixCommand},
+		{CmdOpts{Name: "BNFQuiz", NArgs: "?"}, h.HandleQuizCommand},
+		{
+			CmdOpts{Name: "BNFRename", NArgs: "1", Bang: true},
+			h.HandleRenameCommand,
+		},
+		{
+			CmdOpts{Name: "BNFShowTree", Range: "."},
+			h.HandleShowTreeCommand,
+		},
+		{
+			CmdOpts{Name: "BNFSimplify", Bang: true},
+			h.HandleSimplifyCommand,
+		},
+		{
+			CmdOpts{Name: "BNFSortRules", NArgs: "?"},
+			h.HandleSortRulesCommand,
+		},
+		{CmdOpts{Name: "BNFStats"}, h.HandleStatsCommand},
+		{
+			CmdOpts{Name: "BNFToggleConceal"},
+			h.HandleToggleConcealCommand,
+		},
+		{
+			CmdOpts{Name: "BNFToggleHints"},
+			h.HandleToggleHintsCommand,
+		},
+		{CmdOpts{Name: "BNFTrend"}, h.HandleTrendCommand},
+		{
+			CmdOpts{Name: "BNFUsageReport"},
+			h.HandleUsageReportCommand,
+		},
+		{CmdOpts{Name: "BNFView"}, h.HandleViewCommand},
+	}
+
+	for _, cmd := range commands {
+		var opts = cmd.opts
+		var handler = withErrors(cmd.handler)
+		h.plugin.HandleCommand(&opts, h.withRecover(opts.Name, handler))
 	}
 }
 
@@ -191,11 +735,21 @@ func (h *Highlighter) registerEventHandlers() error {
 		{"nvim_buf_changedtick_event", h.HandleBufChangedTickEvent},
 		{"nvim_buf_detach_event", h.HandleBufDetachEvent},
 		{"nvim_buf_lines_event", h.HandleBufLinesEvent},
+		{"nvim_bnf_buf_read", h.HandlePatternBufReadEvent},
+		{"nvim_bnf_buf_unload", h.HandleBufUnloadEvent},
+		{"nvim_bnf_buf_write", h.HandleBufWriteEvent},
+		{"nvim_bnf_color_scheme", h.HandleColorSchemeEvent},
+		{"nvim_bnf_cursor_hold", h.HandleCursorHoldEvent},
+		{"nvim_bnf_insert_leave", h.HandleInsertLeaveEvent},
+		{"nvim_bnf_settings", h.HandleSettingsEvent},
+		{"nvim_bnf_text_changed_i", h.HandleTextChangedIEvent},
+		{"nvim_bnf_vim_leave", h.HandleVimLeaveEvent},
 	}
 
 	// Register event handlers during loading in operational mode.
 	for _, event := range eventHandlers {
-		var err = h.nvim.RegisterHandler(event.name, event.handler)
+		var handler = h.withRecover(event.name, event.handler)
+		var err = h.nvim.RegisterHandler(event.name, handler)
 		if err != nil {
 			return err
 		}
@@ -210,17 +764,28 @@ func (h *Highlighter) registerFunctionHandlers() {
 		name    string
 		handler interface{}
 	}{
+		{"BNFApplyAction", h.HandleApplyAction},
+		{"BNFCodeActions", h.HandleCodeActions},
+		{"BNFDeriveExpand", h.HandleDeriveExpand},
+		{"BNFDeriveUndo", h.HandleDeriveUndo},
+		{"BNFHealthCheck", h.HandleHealthCheck},
 		{"BNFNcm2OnWarmup", h.HandleNcm2OnWarmup},
 		{"BNFNcm2OnComplete", h.HandleNcm2OnComplete},
+		{"BNFStatus", h.HandleStatus},
+		{"BNFWorkspaceSymbols", h.HandleWorkspaceSymbols},
 	}
 
-	// Register event handlers during loading in operational mode.
+	// Functions are synchronous since they return errors, so callers could
+	// handle failures.
 	for _, proc := range functions {
-		h.plugin.HandleFunction(&FuncOpts{Name: proc.name}, proc.handler)
+		var opts = FuncOpts{Name: proc.name}
+		var handler = withErrors(proc.handler)
+		h.plugin.HandleFunction(&opts, h.withRecover(opts.Name, handler))
 	}
 }
 
 func (h *Highlighter) registerVimLExtHandlers() {
 	h.registerAutocmdHandlers()
+	h.registerCommandHandlers()
 	h.registerFunctionHandlers()
 }
@@ -0,0 +1,119 @@
+package highlighting
+
+import (
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+	"github.com/neovim/go-client/nvim"
+)
+
+// ReferenceHints returns inlay hints like "• 7 refs" by zero-based lines
+// where rules are defined. Every definition of a non-terminal is hinted.
+func ReferenceHints(grammar *analysis.Grammar) map[int]string {
+	var refs = grammar.NoReferences()
+	var hints = make(map[int]string)
+	for _, rule := range grammar.Rules {
+		if _, ok := hints[rule.Line]; !ok {
+			hints[rule.Line] = "• " + formatRefs(refs[rule.Name])
+		}
+	}
+	return hints
+}
+
+// annotateHints shows reference counts at the end of lines where rules are
+// defined. Hints are updated incrementally: only lines which hints are
+// changed since the previous call are refreshed.
+func (d *Document) annotateHints(batch *nvim.Batch, buf nvim.Buffer) {
+	var cache = d.cache()
+	if cache.hints == nil {
+		cache.hints = ReferenceHints(d.grammar())
+	}
+
+	var hints = cache.hints
+	if d.hinted == nil {
+		batch.ClearBufferHighlight(buf, d.hints, 0, -1)
+		d.hinted = make(map[int]string)
+	}
+
+	for row := range d.hinted {
+		if _, ok := hints[row]; !ok {
+			batch.ClearBufferHighlight(buf, d.hints, row, row+1)
+			delete(d.hinted, row)
+		}
+	}
+
+	for row, hint := range hints {
+		if d.hinted[row] == hint {
+			continue
+		}
+
+		var res int
+		var chunks = []Chunk{NewChunk(hint, "BnfInlayHint")}
+		batch.ClearBufferHighlight(buf, d.hints, row, row+1)
+		d.caps.SetVirtualText(batch, &buf, d.hints, row, chunks, NoOpts, &res)
+		d.hinted[row] = hint
+	}
+}
+
+// HandleToggleHintsCommand shows or hides inlay hints with reference counts
+// of rules by flipping g:bnf_hints option.
+func (h *Highlighter) HandleToggleHintsCommand() error {
+	logger.Debugf("HandleToggleHintsCommand()")
+
+	if err := h.setupNamespace(); err != nil {
+		return err
+	}
+
+	var enabled = !h.hintsEnabled()
+	var value = 0
+	if enabled {
+		value = 1
+	}
+
+	if err := SetVar(h.nvim, "bnf_hints", value); err != nil {
+		return err
+	}
+
+	for _, buf := range DocIndex.Buffers() {
+		var doc, ok = DocIndex.Get(buf)
+		if !ok {
+			continue
+		}
+
+		var batch = h.nvim.NewBatch()
+		doc.Lock()
+		doc.hinted = nil
+		if enabled {
+			doc.hints = h.hints
+			doc.annotateHints(batch, buf)
+		} else {
+			batch.ClearBufferHighlight(buf, doc.hints, 0, -1)
+			doc.hints = 0
+		}
+		doc.Unlock()
+
+		if err := execute(batch); err != nil {
+			return err
+		}
+	}
+
+	logger.Infof("inlay hints were toggled: enabled=%t", enabled)
+	return nil
+}
+
+// hintsEnabled reports whether inlay hints are shown. They are enabled with
+// g:bnf_hints option.
+func (h *Highlighter) hintsEnabled() bool {
+	var enabled int
+	if err := Eval(h.nvim, "get(g:, 'bnf_hints', 0)", &enabled); err != nil {
+		logger.Warnf("failed to get g:bnf_hints: %s", err)
+	}
+	return enabled != 0
+}
+
+// hintsNamespace returns namespace of inlay hints if they are enabled or zero
+// otherwise.
+func (h *Highlighter) hintsNamespace() int {
+	if h.hintsEnabled() {
+		return h.hints
+	}
+	return 0
+}
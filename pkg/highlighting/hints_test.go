@@ -0,0 +1,29 @@
+package highlighting
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+func TestReferenceHints(t *testing.T) {
+	var lines = [][]byte{
+		[]byte(`<s> ::= <a> <b> | <a>`),
+		[]byte(``),
+		[]byte(`<a> ::= "a" | <a> "a"`),
+		[]byte(`<b> ::= "b"`),
+	}
+
+	var grammar = analysis.NewGrammar(parser.DialectBNF, lines)
+	var hints = ReferenceHints(grammar)
+	var expected = map[int]string{
+		0: "• 0 refs",
+		2: "• 3 refs",
+		3: "• 1 ref",
+	}
+	if !reflect.DeepEqual(hints, expected) {
+		t.Errorf("wrong hints: %v", hints)
+	}
+}
@@ -0,0 +1,146 @@
+package highlighting
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+)
+
+// historyLimit is the maximal number of snapshots kept per file.
+const historyLimit = 100
+
+// Snapshot is a state of grammar health at the moment of saving.
+type Snapshot struct {
+	Time        time.Time        `json:"time"`
+	Diagnostics int              `json:"diagnostics"`
+	Metrics     analysis.Metrics `json:"metrics"`
+}
+
+// Issues returns total number of issues of a snapshot. The less the number of
+// issues the healthier the grammar.
+func (s *Snapshot) Issues() int {
+	return s.Diagnostics + s.Metrics.Undefined + s.Metrics.Unreferenced
+}
+
+// History is a rolling history of snapshots per file. It could be persisted
+// in a file in order to be shared across sessions.
+type History struct {
+	Files map[string][]Snapshot `json:"files"`
+
+	filename string
+}
+
+// NewHistory creates an empty history. If filename is not empty then history
+// is loaded from and saved to the file.
+func NewHistory(filename string) *History {
+	return &History{
+		Files:    make(map[string][]Snapshot),
+		filename: filename,
+	}
+}
+
+// Load reads persisted history. Missing file is not an error.
+func (h *History) Load() error {
+	if h.filename == "" {
+		return nil
+	}
+
+	var bytes, err = ioutil.ReadFile(h.filename)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(bytes, h)
+}
+
+// Save writes history to its file if history is persistent. Output is
+// indented and files are sorted, so history could be kept in version control.
+func (h *History) Save() error {
+	if h.filename == "" {
+		return nil
+	}
+
+	var bytes, err = h.MarshalIndent()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(h.filename), 0755); err != nil {
+		return err
+	}
+
+	// File is replaced atomically, so it is never left truncated if plugin
+	// is terminated while writing.
+	var tmp = h.filename + ".tmp"
+	if err := ioutil.WriteFile(tmp, bytes, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, h.filename)
+}
+
+// MarshalIndent encodes history to indented JSON which ends with new line.
+func (h *History) MarshalIndent() ([]byte, error) {
+	var bytes, err = json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(bytes, '\n'), nil
+}
+
+// Append adds snapshot of a file and drops the oldest snapshots beyond limit.
+func (h *History) Append(filename string, snapshot Snapshot) {
+	var snapshots = append(h.Files[filename], snapshot)
+	if len(snapshots) > historyLimit {
+		snapshots = snapshots[len(snapshots)-historyLimit:]
+	}
+	h.Files[filename] = snapshots
+}
+
+// Report renders a table of snapshots of a file and a verdict whether grammar
+// is getting healthier. Time is rendered in UTC in order to get the same
+// report regardless of time zone.
+func (h *History) Report(filename string) [][]byte {
+	var snapshots = h.Files[filename]
+	var lines = [][]byte{
+		[]byte(fmt.Sprintf("Trend of %s (%d snapshots)", filename,
+			len(snapshots))),
+		[]byte(""),
+		[]byte(fmt.Sprintf("%-19s %11s %5s %12s %9s %12s", "time (UTC)",
+			"diagnostics", "rules", "alternatives", "undefined",
+			"unreferenced")),
+	}
+
+	for _, s := range snapshots {
+		var line = fmt.Sprintf("%-19s %11d %5d %12d %9d %12d",
+			s.Time.UTC().Format("2006-01-02 15:04:05"), s.Diagnostics,
+			s.Metrics.Rules, s.Metrics.Alternatives, s.Metrics.Undefined,
+			s.Metrics.Unreferenced)
+		lines = append(lines, []byte(line))
+	}
+
+	if len(snapshots) < 2 {
+		return append(lines, []byte(""), []byte("Not enough snapshots yet."))
+	}
+
+	var first = snapshots[0].Issues()
+	var last = snapshots[len(snapshots)-1].Issues()
+	var verdict string
+	switch {
+	case last < first:
+		verdict = "healthier"
+	case last > first:
+		verdict = "worse"
+	default:
+		verdict = "stable"
+	}
+
+	var summary = fmt.Sprintf("Issues: %d -> %d (%s)", first, last, verdict)
+	return append(lines, []byte(""), []byte(summary))
+}
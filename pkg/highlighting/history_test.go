@@ -0,0 +1,89 @@
+package highlighting
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+)
+
+func newTestHistory() *History {
+	var history = NewHistory("")
+	var zone = time.FixedZone("UTC+3", 3*60*60)
+	var moment = time.Date(2019, 5, 1, 12, 0, 0, 0, zone)
+
+	for idx, filename := range []string{"b.bnf", "a.bnf", "c.bnf", "a.bnf"} {
+		history.Append(filename, Snapshot{
+			Time:        moment.Add(time.Duration(idx) * time.Hour),
+			Diagnostics: idx,
+			Metrics:     analysis.Metrics{Rules: idx + 1},
+		})
+	}
+
+	return history
+}
+
+func TestHistoryReport(t *testing.T) {
+	var report = bytes.Join(newTestHistory().Report("a.bnf"), []byte{'\n'})
+	var expected = "Trend of a.bnf (2 snapshots)\n" +
+		"\n" +
+		"time (UTC)          diagnostics rules alternatives " +
+		"undefined unreferenced\n" +
+		"2019-05-01 10:00:00           1     2            0 " +
+		"        0            0\n" +
+		"2019-05-01 12:00:00           3     4            0 " +
+		"        0            0\n" +
+		"\n" +
+		"Issues: 1 -> 3 (worse)"
+
+	if string(report) != expected {
+		t.Errorf("wrong report:\n%s", report)
+	}
+}
+
+func TestHistoryMarshalIndent(t *testing.T) {
+	var first, err = newTestHistory().MarshalIndent()
+	if err != nil {
+		t.Fatalf("failed to marshal history: %s", err)
+	}
+
+	for idx := 0; idx != 10; idx++ {
+		if again, _ := newTestHistory().MarshalIndent(); !bytes.Equal(first,
+			again) {
+			t.Fatalf("output differs between runs:\n%s\n%s", first, again)
+		}
+	}
+
+	var a = bytes.Index(first, []byte(`"a.bnf"`))
+	var b = bytes.Index(first, []byte(`"b.bnf"`))
+	var c = bytes.Index(first, []byte(`"c.bnf"`))
+	if a > b || b > c {
+		t.Errorf("files are not sorted:\n%s", first)
+	}
+
+	if !bytes.HasSuffix(first, []byte("}\n")) {
+		t.Errorf("output does not end with new line")
+	}
+}
+
+func TestGetCompletions(t *testing.T) {
+	var backup = NonTerminalIndex
+	defer func() {
+		NonTerminalIndex = backup
+	}()
+
+	NonTerminalIndex = map[string]uint{"expr": 1, "term": 2, "digit": 1}
+	var matches = new(Highlighter).getCompletions()
+	var expected = []string{"digit", "expr", "term"}
+
+	if len(matches) != len(expected) {
+		t.Fatalf("wrong number of completions: %d", len(matches))
+	}
+
+	for idx, match := range matches {
+		if match["word"] != expected[idx] {
+			t.Errorf("wrong completion #%d: %s", idx, match["word"])
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package highlighting
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+)
+
+// HoverLines describes a rule in hover float: its definition followed by its
+// documentation and deprecation note if there are any.
+func HoverLines(rule *analysis.Rule) []string {
+	var lines = []string{
+		"<" + rule.Name + "> ::= " +
+			strings.Join(renderAlternatives(rule), " | "),
+	}
+	if doc := rule.Doc(); doc != "" {
+		lines = append(lines, "")
+		lines = append(lines, strings.Split(doc, "\n")...)
+	}
+	if rule.Deprecation != nil {
+		lines = append(lines, "", "@deprecated "+rule.Deprecation.Note)
+	}
+	return lines
+}
+
+// ruleDocs returns documentation of rules of document and included files by
+// names of rules. Undocumented rules are omitted. Document should be locked.
+func (d *Document) ruleDocs() map[string]string {
+	if d.docs != nil {
+		return d.docs
+	}
+
+	var grammar = d.grammar()
+	var docs = make(map[string]string)
+	for _, rules := range [][]*analysis.Rule{grammar.Included, grammar.Rules} {
+		for _, rule := range rules {
+			if doc := rule.Doc(); doc != "" {
+				docs[rule.Name] = doc
+			}
+		}
+	}
+	d.docs = docs
+	return docs
+}
+
+// completionDocs collects documentation of rules of all attached buffers.
+func completionDocs() map[string]string {
+	var docs = make(map[string]string)
+	for _, buf := range DocIndex.Buffers() {
+		var doc, ok = DocIndex.Get(buf)
+		if !ok {
+			continue
+		}
+
+		doc.Lock()
+		for name, text := range doc.ruleDocs() {
+			docs[name] = text
+		}
+		doc.Unlock()
+	}
+	return docs
+}
+
+// HandleHoverCommand shows definition and documentation of non-terminal
+// under cursor in a float. The float is closed as soon as cursor moves.
+func (h *Highlighter) HandleHoverCommand() error {
+	logger.Debugf("HandleHoverCommand()")
+
+	var buf, err = CurrentBuffer(h.nvim)
+	if err != nil {
+		return err
+	}
+
+	cursor, err := WindowCursor(h.nvim, 0)
+	if err != nil {
+		return err
+	}
+
+	lines, err := BufferLines(h.nvim, buf, 0, -1, true)
+	if err != nil {
+		return err
+	}
+
+	var dialect = h.dialectOf(buf)
+	var name = SymbolAt(dialect, lines, cursor[0]-1, cursor[1])
+	if name == "" {
+		return newError(CodeInvalidArgs, "there is no non-terminal at cursor")
+	}
+
+	var grammar = analysis.NewGrammar(dialect, lines)
+	var dir = includeDir(h.bufferName(buf))
+	grammar.Included, err = Workspace.Rules(dir, lines)
+	if err != nil {
+		logger.Warnf("failed to load included files: %s", err)
+	}
+
+	var rule = grammar.Definition(name)
+	if rule == nil {
+		return newError(CodeUnknownRule, "there is no rule <"+name+">")
+	}
+
+	if err := h.showPreview(HoverLines(rule)); err != nil {
+		return err
+	}
+
+	h.previewGuard.Lock()
+	var win = h.preview
+	h.previewGuard.Unlock()
+	return Command(h.nvim, fmt.Sprintf("autocmd CursorMoved,BufLeave <buffer> "+
+		"++once silent! call nvim_win_close(%d, v:true)", win))
+}
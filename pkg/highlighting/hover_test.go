@@ -0,0 +1,29 @@
+package highlighting
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+func TestHoverLines(t *testing.T) {
+	var lines = [][]byte{
+		[]byte(`; Either letter.`),
+		[]byte(`; @deprecated use <c>`),
+		[]byte(`<s> ::= "a" | "b"`),
+	}
+	var grammar = analysis.NewGrammar(parser.DialectBNF, lines)
+	var expected = []string{
+		`<s> ::= "a" | "b"`,
+		``,
+		`Either letter.`,
+		``,
+		`@deprecated use <c>`,
+	}
+	if actual := HoverLines(grammar.Rules[0]); !reflect.DeepEqual(actual,
+		expected) {
+		t.Errorf("wrong hover: %q", actual)
+	}
+}
@@ -0,0 +1,103 @@
+package highlighting
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"sort"
+
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+// htmlStyles are CSS declarations of groups which highlight groups of tokens
+// are linked to by default (see tokenGroups).
+var htmlStyles = map[string]string{
+	"Identifier":     "color: #005f87;",
+	"String":         "color: #008700;",
+	"Operator":       "color: #af5f00;",
+	"Comment":        "color: #808080; font-style: italic;",
+	"Character":      "color: #d70087;",
+	"Special":        "color: #875fd7;",
+	"Delimiter":      "color: #5f5f5f;",
+	"Constant":       "color: #af0000;",
+	"BnfStartSymbol": "color: #005f87; font-weight: bold;",
+	"BnfDeprecated":  "text-decoration: line-through;",
+}
+
+// HTMLStyleSheet returns CSS rules for classes of tokens. Every class is named
+// after highlight group of a token like `BnfReference`, so colors could be
+// overridden per group.
+func HTMLStyleSheet() string {
+	var buf bytes.Buffer
+	buf.WriteString("pre.bnf { background: #fafafa; padding: 0.5em; }\n")
+	for _, link := range tokenGroups {
+		fmt.Fprintf(&buf, ".bnf .%s { %s }\n", link[0], htmlStyles[link[1]])
+	}
+	return buf.String()
+}
+
+// HighlightHTML renders lines of a document in a `<pre>` block where lexemes
+// are wrapped into spans with classes of their highlight groups. Tokens are
+// classified in the same way as they are in editor.
+func HighlightHTML(dialect parser.Dialect, lines [][]byte) []byte {
+	var grammar = analysis.NewGrammar(dialect, lines)
+	var start = grammar.StartSymbol()
+	var deprecated = grammar.Deprecated()
+
+	var buf bytes.Buffer
+	buf.WriteString("<pre class=\"bnf\"><code>")
+	for idx, line := range lines {
+		if idx > 0 {
+			buf.WriteByte('\n')
+		}
+
+		var ast, err = parser.ParseDialect(dialect, line)
+		if err != nil {
+			buf.WriteString(html.EscapeString(string(line)))
+			continue
+		}
+
+		var tokens, _, _ = LineTokens(dialect, ast, start, deprecated)
+		writeHTMLLine(&buf, line, tokens)
+	}
+	buf.WriteString("</code></pre>\n")
+	return buf.Bytes()
+}
+
+// HighlightHTMLPage renders a standalone HTML page with highlighted lines of
+// a document and a style sheet of token classes.
+func HighlightHTMLPage(
+	title string, dialect parser.Dialect, lines [][]byte,
+) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("<!DOCTYPE html>\n<html>\n<head>\n")
+	buf.WriteString("<meta charset=\"utf-8\">\n")
+	fmt.Fprintf(&buf, "<title>%s</title>\n", html.EscapeString(title))
+	fmt.Fprintf(&buf, "<style>\n%s</style>\n", HTMLStyleSheet())
+	buf.WriteString("</head>\n<body>\n")
+	buf.Write(HighlightHTML(dialect, lines))
+	buf.WriteString("</body>\n</html>\n")
+	return buf.Bytes()
+}
+
+// writeHTMLLine writes a line with tokens wrapped into spans. Tokens which
+// overlap preceding ones are not wrapped.
+func writeHTMLLine(buf *bytes.Buffer, line []byte, tokens []Token) {
+	sort.SliceStable(tokens, func(i, j int) bool {
+		return tokens[i].Begin < tokens[j].Begin
+	})
+
+	var pos = 0
+	for _, token := range tokens {
+		if token.Begin < pos || token.End > len(line) ||
+			token.Begin >= token.End {
+			continue
+		}
+		buf.WriteString(html.EscapeString(string(line[pos:token.Begin])))
+		fmt.Fprintf(buf, "<span class=\"%s\">%s</span>", token.Group(),
+			html.EscapeString(string(line[token.Begin:token.End])))
+		pos = token.End
+	}
+	buf.WriteString(html.EscapeString(string(line[pos:])))
+}
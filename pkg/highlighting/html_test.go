@@ -0,0 +1,40 @@
+package highlighting
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+func TestHighlightHTML(t *testing.T) {
+	var lines = [][]byte{
+		[]byte(`<s> ::= <a> | "<&>"`),
+		[]byte(`; @deprecated`),
+		[]byte(`<a> ::= "a"`),
+		[]byte(`<b> ::= <`),
+	}
+	var actual = string(HighlightHTML(parser.DialectBNF, lines))
+	var expected = `<pre class="bnf"><code>` +
+		`<span class="BnfDefinitionStart">&lt;s&gt;</span> ` +
+		`<span class="BnfOperator">::=</span> ` +
+		`<span class="BnfReferenceDeprecated">&lt;a&gt;</span> ` +
+		`<span class="BnfOperator">|</span> ` +
+		`<span class="BnfTerminal">&#34;&lt;&amp;&gt;&#34;</span>` + "\n" +
+		`<span class="BnfComment">; @deprecated</span>` + "\n" +
+		`<span class="BnfDefinitionDeprecated">&lt;a&gt;</span> ` +
+		`<span class="BnfOperator">::=</span> ` +
+		`<span class="BnfTerminal">&#34;a&#34;</span>` + "\n" +
+		`<span class="BnfDefinition">&lt;b&gt;</span> ` +
+		`<span class="BnfOperator">::=</span> &lt;` +
+		"</code></pre>\n"
+	if actual != expected {
+		t.Errorf("wrong html:\n%s\n%s", actual, expected)
+	}
+
+	var css = HTMLStyleSheet()
+	if !strings.Contains(css, ".bnf .BnfReferenceDeprecated { "+
+		"text-decoration: line-through; }") {
+		t.Errorf("there is no class of deprecated references:\n%s", css)
+	}
+}
@@ -0,0 +1,220 @@
+package highlighting
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+	"github.com/daskol/nvim-bnf/pkg/workspace"
+	"github.com/neovim/go-client/nvim"
+)
+
+// Workspace keeps files which are included by documents with `; %include`
+// directives.
+var Workspace = workspace.New()
+
+// includedRules returns rules of files which are included by document.
+func (d *Document) includedRules() []*analysis.Rule {
+	var rules, err = Workspace.Rules(d.directory(), d.Lines)
+	if err != nil {
+		logger.Warnf("failed to load included files: %s", err)
+	}
+	return rules
+}
+
+// includes reports whether document includes a file directly or through
+// other included files.
+func (d *Document) includes(path string) bool {
+	var files, _ = Workspace.Includes(d.directory(), d.Lines)
+	for _, file := range files {
+		if file.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
+// setIncludedSymbols replaces contribution of included files to completion
+// index.
+func (d *Document) setIncludedSymbols(rules []*analysis.Rule) {
+	var names = make([]string, 0, len(rules))
+	for _, rule := range rules {
+		names = append(names, rule.Name)
+	}
+
+	nonTerminalGuard.Lock()
+	defer nonTerminalGuard.Unlock()
+	for _, name := range d.includedSymbols {
+		d.unregisterSymbol(name)
+	}
+	for _, name := range names {
+		d.registerSymbol(name)
+	}
+	d.includedSymbols = names
+}
+
+// refreshIncluders reloads a file which is written and refreshes warnings,
+// hints, and completion of documents which include it. Lines of documents
+// are not highlighted again.
+func (h *Highlighter) refreshIncluders(path string) {
+	if !Workspace.Invalidate(path) {
+		return
+	}
+
+	for _, buf := range DocIndex.Buffers() {
+		var doc, ok = DocIndex.Get(buf)
+		if !ok {
+			continue
+		}
+
+		doc.Lock()
+		if doc.includes(path) {
+			doc.status = nil
+			doc.docs = nil
+			doc.analyzed = nil
+			var ctx = context.Background()
+			if err := doc.HightlightHunk(ctx, h.nvim, buf, 0, 0); err != nil {
+				logger.Errorf("failed to highlight %s: %s", buf, err)
+			}
+		}
+		doc.Unlock()
+	}
+}
+
+// directory returns directory of file of document. Included files are looked
+// up relative to it.
+func (d *Document) directory() string {
+	return includeDir(d.filename)
+}
+
+// includeDir returns directory where files which are included by a file are
+// looked up. It is the current directory if there is no file.
+func includeDir(filename string) string {
+	if filename == "" {
+		return ""
+	}
+	return filepath.Dir(filename)
+}
+
+// bufferName returns name of a file of a buffer or empty string if buffer
+// has no file.
+func (h *Highlighter) bufferName(buf nvim.Buffer) string {
+	var filename, err = BufferName(h.nvim, buf)
+	if err != nil {
+		logger.Warnf("failed to get name of %s: %s", buf, err)
+	}
+	return filename
+}
+
+// SymbolAt returns name of non-terminal at zero-based line and byte column of
+// a document. It returns empty string if there is no non-terminal there.
+func SymbolAt(dialect parser.Dialect, lines [][]byte, row, col int) string {
+	if row < 0 || row >= len(lines) {
+		return ""
+	}
+
+	var source, offset = lines[row], col
+	if dialect.Multiline() {
+		var index parser.LineIndex
+		source, index = parser.JoinLines(lines)
+		offset += index[row]
+	}
+
+	var ast, err = parser.ParseDialect(dialect, source)
+	if err != nil {
+		return ""
+	}
+
+	var name string
+	ast.Traverse(func(node parser.Node) error {
+		var term, ok = node.(*parser.NonTerminal)
+		if ok && term.Begin <= offset && offset < term.End {
+			name = string(term.Name)
+		}
+		return nil
+	})
+	return name
+}
+
+// rulePosition returns zero-based line and byte column where left-hand side
+// of a rule begins in lines of document which defines it.
+func rulePosition(
+	dialect parser.Dialect, lines [][]byte, rule *analysis.Rule,
+) (int, int) {
+	var begin = parser.Span(rule.Statement.Rule.Left()).Begin
+	if !dialect.Multiline() {
+		return rule.Line, begin
+	}
+	var _, index = parser.JoinLines(lines)
+	return index.Locate(begin)
+}
+
+// HandleGotoDefinitionCommand moves cursor to definition of non-terminal
+// under cursor. Definition is looked up in the current buffer, in included
+// files which are opened in the current window, and then in other attached
+// buffers.
+func (h *Highlighter) HandleGotoDefinitionCommand() error {
+	logger.Debugf("HandleGotoDefinitionCommand()")
+
+	var buf, err = CurrentBuffer(h.nvim)
+	if err != nil {
+		return err
+	}
+
+	cursor, err := WindowCursor(h.nvim, 0)
+	if err != nil {
+		return err
+	}
+
+	lines, err := BufferLines(h.nvim, buf, 0, -1, true)
+	if err != nil {
+		return err
+	}
+
+	var dialect = h.dialectOf(buf)
+	var name = SymbolAt(dialect, lines, cursor[0]-1, cursor[1])
+	if name == "" {
+		return newError(CodeInvalidArgs, "there is no non-terminal at cursor")
+	}
+
+	var grammar = analysis.NewGrammar(dialect, lines)
+	var dir = includeDir(h.bufferName(buf))
+	grammar.Included, err = Workspace.Rules(dir, lines)
+	if err != nil {
+		logger.Warnf("failed to load included files: %s", err)
+	}
+
+	var rule = grammar.Definition(name)
+	if rule == nil {
+		if ok, err := h.gotoSymbol(buf, name); ok || err != nil {
+			return err
+		}
+		return newError(CodeUnknownRule, "there is no rule <"+name+">")
+	}
+
+	// Position is saved to jump list, so it is possible to jump back.
+	if err := Command(h.nvim, "normal! m'"); err != nil {
+		return err
+	}
+
+	if rule.File != "" {
+		var file, err = Workspace.Load(rule.File)
+		if err != nil {
+			return err
+		}
+
+		var path string
+		err = CallFunction(h.nvim, "fnameescape", &path, file.Path)
+		if err != nil {
+			return err
+		}
+		if err := Command(h.nvim, "edit "+path); err != nil {
+			return err
+		}
+		dialect, lines = file.Dialect, file.Lines
+	}
+
+	var row, col = rulePosition(dialect, lines, rule)
+	return SetWindowCursor(h.nvim, nvim.Window(0), [2]int{row + 1, col})
+}
@@ -0,0 +1,32 @@
+package highlighting
+
+import (
+	"testing"
+
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+func TestSymbolAt(t *testing.T) {
+	var lines = [][]byte{
+		[]byte(`<expr> ::= <term> | <expr> "+" <term>`),
+		[]byte(`<term> ::= "x"`),
+	}
+
+	var tests = []struct {
+		row, col int
+		name     string
+	}{
+		{0, 1, "expr"},
+		{0, 12, "term"},
+		{0, 9, ""},
+		{1, 0, "term"},
+		{2, 0, ""},
+	}
+
+	for _, test := range tests {
+		var name = SymbolAt(parser.DialectBNF, lines, test.row, test.col)
+		if name != test.name {
+			t.Errorf("wrong symbol at %d:%d: %q", test.row, test.col, name)
+		}
+	}
+}
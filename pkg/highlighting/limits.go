@@ -0,0 +1,75 @@
+package highlighting
+
+import (
+	"github.com/daskol/nvim-bnf/pkg/i18n"
+	"github.com/neovim/go-client/nvim"
+)
+
+// Limits are sizes of a document beyond which it is handled in lightweight
+// mode: it is not highlighted on changes but only with :BNFHighlight, so that
+// huge generated grammars do not freeze editor. Non-positive limit is not
+// checked.
+type Limits struct {
+	MaxLines int
+	MaxSize  int
+}
+
+// DefaultLimits are used if g:bnf_max_lines or g:bnf_max_file_size are not
+// set.
+var DefaultLimits = Limits{MaxLines: 20000, MaxSize: 1 << 20}
+
+// Exceeded reports whether a document of lines exceeds any of limits. Size of
+// a document includes line breaks.
+func (l Limits) Exceeded(lines [][]byte) bool {
+	if l.MaxLines > 0 && len(lines) > l.MaxLines {
+		return true
+	} else if l.MaxSize <= 0 {
+		return false
+	}
+
+	var size = 0
+	for _, line := range lines {
+		if size += len(line) + 1; size > l.MaxSize {
+			return true
+		}
+	}
+	return false
+}
+
+// limits returns cached limits of documents.
+func (h *Highlighter) limits() Limits {
+	return h.cachedSettings().limits
+}
+
+// readLimits reads limits of documents from g:bnf_max_lines and
+// g:bnf_max_file_size options.
+func (h *Highlighter) readLimits() Limits {
+	var limits = DefaultLimits
+	var expr = "get(g:, 'bnf_max_lines', -1)"
+	var value int
+	if err := Eval(h.nvim, expr, &value); err != nil {
+		logger.Warnf("failed to get g:bnf_max_lines: %s", err)
+	} else if value != -1 {
+		limits.MaxLines = value
+	}
+
+	expr = "get(g:, 'bnf_max_file_size', -1)"
+	if err := Eval(h.nvim, expr, &value); err != nil {
+		logger.Warnf("failed to get g:bnf_max_file_size: %s", err)
+	} else if value != -1 {
+		limits.MaxSize = value
+	}
+	return limits
+}
+
+// warnLightweight tells user that a buffer is too large to be highlighted
+// automatically.
+func (h *Highlighter) warnLightweight(buf nvim.Buffer) {
+	logger.Infof("buffer %s exceeds limits and is handled in lightweight "+
+		"mode", buf)
+	var msg = i18n.T("Grammar is too large, so it is highlighted only " +
+		"with :BNFHighlight.")
+	if err := WriteOut(h.nvim, msg+"\n"); err != nil {
+		logger.Warnf("failed to warn about lightweight mode: %s", err)
+	}
+}
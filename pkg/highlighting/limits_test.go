@@ -0,0 +1,26 @@
+package highlighting
+
+import (
+	"testing"
+)
+
+func TestLimitsExceeded(t *testing.T) {
+	var lines = [][]byte{[]byte("<a> ::= <b>"), []byte("<b> ::= \"x\"")}
+	var tests = []struct {
+		limits   Limits
+		exceeded bool
+	}{
+		{Limits{}, false},
+		{Limits{MaxLines: 2}, false},
+		{Limits{MaxLines: 1}, true},
+		{Limits{MaxSize: 24}, false},
+		{Limits{MaxSize: 23}, true},
+		{Limits{MaxLines: -1, MaxSize: -1}, false},
+	}
+
+	for _, test := range tests {
+		if exceeded := test.limits.Exceeded(lines); exceeded != test.exceeded {
+			t.Errorf("wrong result for %+v: %t", test.limits, exceeded)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package highlighting
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/daskol/nvim-bnf/pkg/i18n"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+// ParseMode tells which parser produced a parse tree of a line.
+type ParseMode int
+
+const (
+	// ParseFailed means that neither semantic nor syntactic parser accepted
+	// a line, so it is not highlighted at all.
+	ParseFailed ParseMode = iota
+	// ParseSemantic means that a line is parsed into production rules, so
+	// its lexemes are highlighted according to their roles.
+	ParseSemantic
+	// ParseSyntactic means that semantic parser rejected a line and lexemes
+	// are recognized by fallback parser, so the line is highlighted "flat".
+	ParseSyntactic
+)
+
+var parseModes = []string{"failed", "semantic", "syntactic"}
+
+func (m ParseMode) String() string {
+	return parseModes[m]
+}
+
+// LineInfo describes how a line of a document is parsed. Prefix is length of
+// a valid prefix of the line which is highlighted. Errors are diagnostics of
+// parser which ranges are relative to the line while their start columns are
+// columns on screen. Documents in multiline dialects are parsed as a whole,
+// so mode is the mode of the document.
+type LineInfo struct {
+	Line    int
+	Length  int
+	Mode    ParseMode
+	Lexemes int
+	Prefix  int
+	Errors  []parser.Diagnostic
+}
+
+// DescribeLine parses a line of a document in the same way as highlighting
+// does and reports which parser accepted it and what semantic parser
+// rejected. Tabs are expanded to tabstop in columns of errors.
+func DescribeLine(
+	dialect parser.Dialect, lines [][]byte, row, tabstop int,
+) LineInfo {
+	var info = LineInfo{Line: row, Length: len(lines[row])}
+	var source, index = lines[row], parser.LineIndex{0}
+	if dialect.Multiline() {
+		source, index = parser.JoinLines(lines)
+	}
+
+	// Diagnostics of multiline dialects are located in the whole document.
+	var collect = func(diags []parser.Diagnostic) {
+		for _, diag := range diags {
+			var line, col = index.Locate(diag.Range.Begin)
+			if dialect.Multiline() && line != row {
+				continue
+			}
+			diag.Range.End += col - diag.Range.Begin
+			diag.Range.Begin = col
+			diag.Start = parser.Position{
+				Line:   row,
+				Col:    parser.VirtualColumn(lines[row], col, tabstop),
+				Offset: col,
+			}
+			info.Errors = append(info.Errors, diag)
+		}
+	}
+
+	var ast, err = parser.ParseDialect(dialect, source)
+	if err != nil {
+		collect([]parser.Diagnostic{parser.NewDiagnostic(err)})
+		return info
+	}
+
+	info.Mode = ParseSyntactic
+	if ast.Semantic() {
+		info.Mode = ParseSemantic
+	}
+
+	info.Prefix = info.Length
+	if !dialect.Multiline() {
+		var tokens, _, _ = LineTokens(dialect, ast, "", nil)
+		info.Lexemes = len(tokens)
+		if prefix := ast.Prefix(); prefix < info.Prefix {
+			info.Prefix = prefix
+		}
+	}
+
+	var diags = parser.Diagnostics(ast)
+	for idx := range diags {
+		diags[idx] = brokenSuffix(dialect, ast, diags[idx])
+	}
+	collect(diags)
+	return info
+}
+
+// String renders description of a line as a short report.
+func (info LineInfo) String() string {
+	var builder strings.Builder
+	switch info.Mode {
+	case ParseSemantic:
+		builder.WriteString(i18n.Sprintf("Line %d is parsed by semantic "+
+			"parser.", info.Line+1) + "\n")
+	case ParseSyntactic:
+		builder.WriteString(i18n.Sprintf("Line %d is rejected by semantic "+
+			"parser, so it is highlighted without roles of lexemes.",
+			info.Line+1) + "\n")
+	case ParseFailed:
+		builder.WriteString(i18n.Sprintf("Line %d could not be parsed, so it "+
+			"is not highlighted.", info.Line+1) + "\n")
+		return builder.String() + info.errors()
+	}
+
+	if info.Lexemes > 0 {
+		builder.WriteString(i18n.Sprintf("%d lexemes are highlighted.",
+			info.Lexemes) + "\n")
+	}
+	if info.Prefix < info.Length {
+		builder.WriteString(i18n.Sprintf("Valid prefix is %d of %d bytes.",
+			info.Prefix, info.Length) + "\n")
+	}
+
+	return builder.String() + info.errors()
+}
+
+// errors renders diagnostics of a line in the same way as compilers do.
+func (info LineInfo) errors() string {
+	var builder strings.Builder
+	for _, diag := range info.Errors {
+		fmt.Fprintf(&builder, "%d:%d: %s: %s\n", info.Line+1,
+			diag.Start.Col+1, diag.Code, diag.Message)
+	}
+	return builder.String()
+}
+
+// HandleLineInfoCommand shows how the line under cursor is parsed: whether
+// semantic parser accepted it or it is highlighted by fallback parser, and
+// what semantic parser rejected.
+func (h *Highlighter) HandleLineInfoCommand() error {
+	logger.Debugf("HandleLineInfoCommand()")
+
+	var buf, err = CurrentBuffer(h.nvim)
+	if err != nil {
+		return err
+	}
+
+	cursor, err := WindowCursor(h.nvim, 0)
+	if err != nil {
+		return err
+	}
+
+	lines, err := BufferLines(h.nvim, buf, 0, -1, true)
+	if err != nil {
+		return err
+	}
+
+	var row = cursor[0] - 1
+	if row < 0 || row >= len(lines) {
+		return newError(CodeInvalidArgs, "cursor is out of buffer")
+	}
+
+	var info = DescribeLine(h.dialectOf(buf), lines, row, h.tabstop(buf))
+	return WriteOut(h.nvim, info.String())
+}
@@ -0,0 +1,54 @@
+package highlighting
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+func TestDescribeLine(t *testing.T) {
+	var lines = [][]byte{
+		[]byte(`<a> ::= <b> | "c"`),
+		[]byte(`<b> ::= <c> ) <d>`),
+	}
+
+	var info = DescribeLine(parser.DialectBNF, lines, 0, 8)
+	if info.Mode != ParseSemantic || len(info.Errors) != 0 ||
+		info.Prefix != info.Length || info.Lexemes != 5 {
+		t.Errorf("wrong description of valid line: %+v", info)
+	}
+
+	info = DescribeLine(parser.DialectBNF, lines, 1, 8)
+	if info.Mode != ParseSyntactic || info.Prefix != 12 ||
+		info.Lexemes != 3 || len(info.Errors) != 1 {
+		t.Fatalf("wrong description of broken line: %+v", info)
+	}
+	if rng := info.Errors[0].Range; rng != (parser.Range{Begin: 12, End: 17}) {
+		t.Errorf("wrong range of error: %v", rng)
+	}
+	if report := info.String(); !strings.Contains(report,
+		"Line 2 is rejected by semantic parser") ||
+		!strings.Contains(report, "2:13: E001:") {
+		t.Errorf("wrong report: %q", report)
+	}
+
+	lines = [][]byte{[]byte(`a = "x" ;`), []byte(`b = "y" ) ;`)}
+	info = DescribeLine(parser.DialectEBNF, lines, 1, 8)
+	if len(info.Errors) != 1 || info.Errors[0].Range.Begin != 8 {
+		t.Errorf("error of multiline document is not located: %+v", info)
+	}
+	info = DescribeLine(parser.DialectEBNF, lines, 0, 8)
+	if len(info.Errors) != 0 {
+		t.Errorf("error is reported on another line: %+v", info)
+	}
+
+	lines = [][]byte{[]byte("\t<a>\t::=\t<b> )")}
+	info = DescribeLine(parser.DialectBNF, lines, 0, 4)
+	if info.Mode != ParseSyntactic || len(info.Errors) != 1 {
+		t.Fatalf("wrong description of tab-indented line: %+v", info)
+	}
+	if diag := info.Errors[0]; diag.Range.Begin != 13 || diag.Start.Col != 16 {
+		t.Errorf("wrong column of error: %+v", diag)
+	}
+}
@@ -0,0 +1,46 @@
+package highlighting
+
+import (
+	"os"
+
+	"github.com/daskol/nvim-bnf/pkg/logging"
+)
+
+// setupLogging applies g:bnf_log_format option and redirects log messages to
+// file from g:bnf_log_file option. Environment variables NVIM_BNF_LOG_FORMAT
+// and NVIM_BNF_LOG take precedence since they are applied before connection
+// to NeoVim is established.
+func (h *Highlighter) setupLogging() {
+	if os.Getenv("NVIM_BNF_LOG_FORMAT") == "" {
+		var format string
+		var expr = "get(g:, 'bnf_log_format', '')"
+		if err := Eval(h.nvim, expr, &format); err != nil {
+			logger.Warnf("failed to get g:bnf_log_format: %s", err)
+		}
+		logger.SetFormat(format)
+	}
+
+	if os.Getenv("NVIM_BNF_LOG") != "" {
+		return
+	}
+
+	var filename string
+	var expr = "expand(get(g:, 'bnf_log_file', ''))"
+	if err := Eval(h.nvim, expr, &filename); err != nil {
+		logger.Warnf("failed to get g:bnf_log_file: %s", err)
+		return
+	} else if filename == "" {
+		return
+	}
+
+	var file, err = logging.OpenFile(filename)
+	if err != nil {
+		logger.Errorf("failed to open log file %s: %s", filename, err)
+		return
+	}
+
+	if err := logger.SetCollector(file); err != nil {
+		logger.Warnf("failed to close previous log collector: %s", err)
+	}
+	logger.Infof("log messages are written to %s", filename)
+}
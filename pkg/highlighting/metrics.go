@@ -0,0 +1,109 @@
+package highlighting
+
+import (
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// metricPrefix is a prefix of names of expvar variables which are exposed as
+// metrics of plugin. Other packages publish their counters with it as well.
+const metricPrefix = "nvim_bnf_"
+
+// Counters of plugin internals. They are published with expvar, so that they
+// are available as JSON at /debug/vars and in text format of Prometheus at
+// /metrics of metrics listener.
+var (
+	metricEvents           = expvar.NewMap("nvim_bnf_events_total")
+	metricBatches          = expvar.NewInt("nvim_bnf_batches_total")
+	metricBatchLines       = expvar.NewInt("nvim_bnf_batch_lines_total")
+	metricHighlights       = expvar.NewInt("nvim_bnf_highlights_total")
+	metricHighlightSeconds = expvar.NewFloat(
+		"nvim_bnf_highlight_seconds_total")
+)
+
+func init() {
+	expvar.Publish("nvim_bnf_parse_seconds", expvar.Func(func() interface{} {
+		var counts, total = parseTimings.Snapshot()
+		return map[string]interface{}{
+			"buckets": counts,
+			"sum":     total.Seconds(),
+		}
+	}))
+}
+
+// observeBatch counts batch RPC call which carries highlights of lines.
+func observeBatch(lines int) {
+	metricBatches.Add(1)
+	metricBatchLines.Add(int64(lines))
+}
+
+// ServeMetrics serves metrics of plugin over HTTP on address until it fails.
+// Metrics are available in text format of Prometheus at /metrics and as JSON
+// of expvar at /debug/vars.
+func ServeMetrics(addr string) error {
+	var mux = http.NewServeMux()
+	mux.Handle("/debug/vars", expvar.Handler())
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		if err := WriteMetrics(w); err != nil {
+			logger.Warnf("failed to write metrics: %s", err)
+		}
+	})
+	return http.ListenAndServe(addr, mux)
+}
+
+// WriteMetrics writes metrics of plugin in text format of Prometheus. Names
+// of counters end with _total while other variables are gauges. Maps become
+// counters with label name.
+func WriteMetrics(w io.Writer) error {
+	var lines []string
+	expvar.Do(func(kv expvar.KeyValue) {
+		if !strings.HasPrefix(kv.Key, metricPrefix) {
+			return
+		}
+
+		var kind = "gauge"
+		if strings.HasSuffix(kv.Key, "_total") {
+			kind = "counter"
+		}
+
+		switch value := kv.Value.(type) {
+		case *expvar.Int, *expvar.Float:
+			lines = append(lines, "# TYPE "+kv.Key+" "+kind,
+				kv.Key+" "+value.String())
+		case *expvar.Map:
+			lines = append(lines, "# TYPE "+kv.Key+" "+kind)
+			value.Do(func(item expvar.KeyValue) {
+				lines = append(lines, fmt.Sprintf("%s{name=%q} %s", kv.Key,
+					item.Key, item.Value.String()))
+			})
+		}
+	})
+
+	lines = append(lines, histogramMetric("nvim_bnf_parse_seconds",
+		&parseTimings)...)
+	_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+	return err
+}
+
+// histogramMetric renders histogram of durations with cumulative buckets.
+func histogramMetric(name string, hist *Histogram) []string {
+	var counts, total = hist.Snapshot()
+	var lines = []string{"# TYPE " + name + " histogram"}
+	var cumulative uint64
+	for idx, count := range counts {
+		cumulative += count
+		var bound = "+Inf"
+		if idx < len(histogramBounds) {
+			bound = fmt.Sprint(histogramBounds[idx].Seconds())
+		}
+		lines = append(lines, fmt.Sprintf("%s_bucket{le=%q} %d", name, bound,
+			cumulative))
+	}
+	return append(lines,
+		fmt.Sprintf("%s_sum %g", name, total.Seconds()),
+		fmt.Sprintf("%s_count %d", name, cumulative))
+}
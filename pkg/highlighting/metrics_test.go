@@ -0,0 +1,54 @@
+package highlighting
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteMetrics(t *testing.T) {
+	var handler = recoverHandler("BNFTest", func() {}, func(string) {})
+	handler.(func())()
+	observeBatch(3)
+
+	var buf bytes.Buffer
+	if err := WriteMetrics(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	var output = buf.String()
+	for _, line := range []string{
+		"# TYPE nvim_bnf_batch_lines_total counter",
+		`nvim_bnf_events_total{name="BNFTest"} 1`,
+		"# TYPE nvim_bnf_parse_seconds histogram",
+		`nvim_bnf_parse_seconds_bucket{le="+Inf"} `,
+	} {
+		if !strings.Contains(output, line) {
+			t.Errorf("metrics do not contain %q:\n%s", line, output)
+		}
+	}
+}
+
+func TestHistogramMetric(t *testing.T) {
+	var hist Histogram
+	hist.Observe(5 * time.Microsecond)
+	hist.Observe(time.Second)
+
+	var lines = histogramMetric("test_seconds", &hist)
+	var expected = []string{
+		"# TYPE test_seconds histogram",
+		`test_seconds_bucket{le="1e-05"} 1`,
+	}
+	for idx, line := range expected {
+		if lines[idx] != line {
+			t.Errorf("wrong line %d: %q", idx, lines[idx])
+		}
+	}
+	if last := lines[len(lines)-1]; last != "test_seconds_count 2" {
+		t.Errorf("wrong count: %q", last)
+	}
+	if inf := lines[len(lines)-3]; inf != `test_seconds_bucket{le="+Inf"} 2` {
+		t.Errorf("wrong last bucket: %q", inf)
+	}
+}
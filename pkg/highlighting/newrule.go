@@ -0,0 +1,79 @@
+package highlighting
+
+import (
+	"strings"
+
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+	"github.com/daskol/nvim-bnf/pkg/convert"
+	"github.com/neovim/go-client/nvim"
+)
+
+// HandleNewRuleCommand inserts a skeleton of a rule right after the first
+// rule which references it or at the end of the buffer. Cursor is placed
+// where right-hand side starts and insert mode is started. Non-terminal is
+// registered for completion as soon as the inserted line is highlighted.
+func (h *Highlighter) HandleNewRuleCommand(args []string) error {
+	logger.Debugf("HandleNewRuleCommand(%v)", args)
+
+	var name = strings.TrimSuffix(strings.TrimPrefix(args[0], "<"), ">")
+	if name == "" {
+		return newError(CodeInvalidArgs, "name of rule is empty")
+	}
+
+	var buf, err = CurrentBuffer(h.nvim)
+	if err != nil {
+		return err
+	}
+
+	lines, err := BufferLines(h.nvim, buf, 0, -1, true)
+	if err != nil {
+		return err
+	}
+
+	var dialect = h.dialectOf(buf)
+	var grammar = analysis.NewGrammar(dialect, lines)
+	if grammar.Defines(name) {
+		return newError(CodeInvalidArgs, "rule <"+name+"> is already defined")
+	}
+
+	line, col, err := convert.RenderSkeleton(dialect, name)
+	if err != nil {
+		return newError(CodeUnknownDialect, err.Error())
+	}
+
+	var row = skeletonRow(grammar, lines, name)
+	var skeleton = [][]byte{[]byte(line)}
+	err = SetBufferLines(h.nvim, buf, row, row, true, skeleton)
+	if err != nil {
+		return err
+	}
+
+	// Insert mode starts before cursor, so cursor beyond the end of line is
+	// turned into appending.
+	var insert = "startinsert"
+	if col >= len(line) {
+		insert = "startinsert!"
+	}
+
+	var pos = [2]int{row + 1, col}
+	if err := SetWindowCursor(h.nvim, nvim.Window(0), pos); err != nil {
+		return err
+	}
+	return Command(h.nvim, insert)
+}
+
+// skeletonRow returns line where a new rule is inserted. It is the line after
+// extent of the first rule which references the rule or the end of document.
+func skeletonRow(
+	grammar *analysis.Grammar, lines [][]byte, name string,
+) int {
+	for _, rule := range grammar.Rules {
+		for _, ref := range rule.References() {
+			if ref == name {
+				var _, end = ruleExtent(grammar, rule, lines)
+				return end
+			}
+		}
+	}
+	return len(lines)
+}
@@ -0,0 +1,28 @@
+package highlighting
+
+import (
+	"testing"
+
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+func TestSkeletonRow(t *testing.T) {
+	var lines = [][]byte{
+		[]byte(`<expr> ::= <term>`),
+		[]byte(`        | <expr> "+" <term>`),
+		[]byte(``),
+		[]byte(`<term> ::= <digit>`),
+	}
+	var grammar = analysis.NewGrammar(parser.DialectBNF, lines)
+
+	if row := skeletonRow(grammar, lines, "term"); row != 2 {
+		t.Errorf("wrong row of referenced rule: %d", row)
+	}
+	if row := skeletonRow(grammar, lines, "digit"); row != 4 {
+		t.Errorf("wrong row of referenced rule: %d", row)
+	}
+	if row := skeletonRow(grammar, lines, "factor"); row != 4 {
+		t.Errorf("wrong row of unreferenced rule: %d", row)
+	}
+}
@@ -0,0 +1,93 @@
+package highlighting
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/neovim/go-client/nvim"
+)
+
+// DefaultFilePatterns match names of grammar files which plugin attaches to
+// out of the box.
+var DefaultFilePatterns = []string{
+	"*.bnf", "*.ebnf", "*.g4", "*.peg", "*.w3c", "*.y", "*.yy",
+}
+
+// patternGroup is an autocommand group for patterns which are registered at
+// runtime.
+const patternGroup = "nvim-bnf-patterns"
+
+// unknownPatternsEval is an expression which is evaluated to patterns of
+// g:bnf_file_patterns which have no autocommands in manifest.
+const unknownPatternsEval = "filter(" +
+	"copy(get(g:, 'bnf_file_patterns', [])), " +
+	"{_, p -> !exists('#nvim-bnf#BufRead#' . p)})"
+
+// MergePatterns appends patterns to defaults omitting empty and duplicated
+// ones.
+func MergePatterns(defaults []string, patterns ...string) []string {
+	var merged = make([]string, 0, len(defaults)+len(patterns))
+	var seen = make(map[string]bool)
+	for _, group := range [][]string{defaults, patterns} {
+		for _, pattern := range group {
+			pattern = strings.TrimSpace(pattern)
+			if pattern == "" || seen[pattern] {
+				continue
+			}
+			seen[pattern] = true
+			merged = append(merged, pattern)
+		}
+	}
+	return merged
+}
+
+// HandlePatternBufReadEvent attaches plugin to a buffer which name matches a
+// pattern from g:bnf_file_patterns.
+func (h *Highlighter) HandlePatternBufReadEvent(ev *BufEvent) {
+	logger.Debugf("HandlePatternBufReadEvent(%d, %s)", ev.Buffer, ev.Filename)
+	h.HandleBufReadEvent(nvim.Buffer(ev.Buffer), ev.Filename)
+}
+
+// registerFilePatterns reads g:bnf_file_patterns and defines autocommands for
+// patterns which are missing in manifest. Buffers which are already loaded and
+// match the patterns are attached immediately.
+func (h *Highlighter) registerFilePatterns() {
+	var patterns []string
+	if err := Eval(h.nvim, unknownPatternsEval, &patterns); err != nil {
+		logger.Warnf("failed to get g:bnf_file_patterns: %s", err)
+		return
+	} else if patterns = MergePatterns(nil, patterns...); len(patterns) == 0 {
+		return
+	}
+
+	var pattern = strings.Join(patterns, ",")
+	var notify = func(event, method, eval string) string {
+		var args = ""
+		if eval != "" {
+			args = ", " + eval
+		}
+		return fmt.Sprintf("autocmd %s %s %s call rpcnotify(%d, '%s'%s)",
+			patternGroup, event, pattern, h.nvim.ChannelID(), method, args)
+	}
+
+	var cmds = []string{
+		"augroup " + patternGroup,
+		"autocmd!",
+		notify("BufRead,BufNewFile", "nvim_bnf_buf_read", bufEventEval),
+		notify("BufWritePost", "nvim_bnf_buf_write", bufEventEval),
+		notify("TextChangedI", "nvim_bnf_text_changed_i", cursorEventEval),
+		notify("CursorHold", "nvim_bnf_cursor_hold", cursorHoldEventEval),
+		notify("InsertLeave", "nvim_bnf_insert_leave", ""),
+		"augroup END",
+		"doautoall " + patternGroup + " BufRead",
+	}
+
+	for _, cmd := range cmds {
+		if err := Command(h.nvim, cmd); err != nil {
+			logger.Errorf("failed to register file patterns: %s", err)
+			return
+		}
+	}
+
+	logger.Infof("file patterns were registered: %s", pattern)
+}
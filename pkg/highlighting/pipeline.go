@@ -0,0 +1,282 @@
+package highlighting
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/daskol/nvim-bnf/pkg/logging"
+	"github.com/neovim/go-client/nvim"
+)
+
+// pipelineQueueSize is a capacity of queue of buffers with pending jobs.
+const pipelineQueueSize = 64
+
+// highlightJob is a request to highlight lines of a document from the first
+// line (inclusive) to the last one (exclusive). Negative last line means the
+// whole document.
+type highlightJob struct {
+	buf   nvim.Buffer
+	doc   *Document
+	tick  int
+	from  int
+	to    int
+	event *logging.Entry
+
+	// shifted reports whether lines after the hunk are moved by the change
+	// which produced the job.
+	shifted bool
+
+	// ctx is cancelled when job becomes stale while it is running.
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// supersede merges a pending job into a newer one of the same buffer. Ranges
+// are merged only if the newer change does not move lines. Otherwise the
+// whole document is highlighted since the range of the pending job could be
+// stale.
+func (j *highlightJob) supersede(pending *highlightJob) {
+	if pending.to < 0 || j.to < 0 || j.shifted {
+		j.from, j.to = 0, -1
+		return
+	}
+
+	if pending.from < j.from {
+		j.from = pending.from
+	}
+	if pending.to > j.to {
+		j.to = pending.to
+	}
+}
+
+// Pipeline is a pool of workers which highlight documents off the RPC
+// goroutine. There is at most one pending job per buffer: a job which is not
+// started yet is superseded by a job with newer changedtick. Jobs of the same
+// buffer are never run concurrently, so they are applied in order. Running
+// job of the whole document is aborted by a newer change of the buffer.
+type Pipeline struct {
+	guard   sync.Mutex
+	pending map[nvim.Buffer]*highlightJob
+	running map[nvim.Buffer]*highlightJob
+	aborted map[nvim.Buffer]bool
+	queue   chan nvim.Buffer
+	closed  bool
+	run     func(job *highlightJob)
+	wg      sync.WaitGroup
+
+	// sending is held for reading while buffers are sent to queue, so that
+	// queue is never closed in the middle of sending. Buffers are not sent
+	// under guard since workers need it to drain queue.
+	sending sync.RWMutex
+}
+
+// NewPipeline starts a pool of workers which run jobs with a function. Number
+// of workers is a number of CPUs if size is not positive.
+func NewPipeline(size int, run func(job *highlightJob)) *Pipeline {
+	if size <= 0 {
+		size = runtime.NumCPU()
+	}
+
+	var p = &Pipeline{
+		pending: make(map[nvim.Buffer]*highlightJob),
+		running: make(map[nvim.Buffer]*highlightJob),
+		aborted: make(map[nvim.Buffer]bool),
+		queue:   make(chan nvim.Buffer, pipelineQueueSize),
+		run:     run,
+	}
+
+	p.wg.Add(size)
+	for idx := 0; idx < size; idx++ {
+		go p.work()
+	}
+	return p
+}
+
+// Submit enqueues a job. A pending job of the same buffer is superseded.
+func (p *Pipeline) Submit(job *highlightJob) {
+	p.sending.RLock()
+	defer p.sending.RUnlock()
+
+	p.guard.Lock()
+	if p.closed {
+		p.guard.Unlock()
+		return
+	}
+
+	// Job which was aborted is not redone partially, so the whole document
+	// is highlighted again.
+	if p.aborted[job.buf] {
+		job.from, job.to = 0, -1
+		delete(p.aborted, job.buf)
+	}
+
+	var pending, ok = p.pending[job.buf]
+	if ok {
+		job.supersede(pending)
+		pending.event.Debugf("job is superseded by changedtick %d", job.tick)
+	}
+	p.pending[job.buf] = job
+
+	// Buffer is queued only once. Buffer which is running is requeued by
+	// its worker.
+	var enqueue = !ok && p.running[job.buf] == nil
+	p.guard.Unlock()
+
+	if enqueue {
+		p.queue <- job.buf
+	}
+}
+
+// Cancel aborts running highlighting of the whole document of a buffer if it
+// is older than changedtick. It should be called before the document is
+// locked for update since running job holds the lock. Highlighting of a hunk
+// is not aborted since it is cheap and its range is not redone otherwise.
+func (p *Pipeline) Cancel(buf nvim.Buffer, tick int) bool {
+	p.guard.Lock()
+	defer p.guard.Unlock()
+	var job = p.running[buf]
+	if job == nil || job.to >= 0 || job.tick >= tick {
+		return false
+	}
+	if job.ctx.Err() == nil {
+		job.cancel()
+		p.aborted[buf] = true
+		job.event.Debugf("job is aborted by changedtick %d", tick)
+	}
+	return true
+}
+
+// Close stops workers after jobs which are queued already are done.
+func (p *Pipeline) Close() {
+	p.sending.Lock()
+	p.guard.Lock()
+	if p.closed {
+		p.guard.Unlock()
+		p.sending.Unlock()
+		return
+	}
+	p.closed = true
+	p.guard.Unlock()
+
+	close(p.queue)
+	p.sending.Unlock()
+	p.wg.Wait()
+}
+
+func (p *Pipeline) work() {
+	defer p.wg.Done()
+	for buf := range p.queue {
+		for job := p.take(buf); job != nil; job = p.next(buf) {
+			p.runSafe(job)
+		}
+	}
+}
+
+// take marks buffer as running and returns its pending job.
+func (p *Pipeline) take(buf nvim.Buffer) *highlightJob {
+	p.guard.Lock()
+	defer p.guard.Unlock()
+	var job = p.pending[buf]
+	delete(p.pending, buf)
+	p.start(job)
+	return job
+}
+
+// start marks job as running. It should be called with lock held.
+func (p *Pipeline) start(job *highlightJob) {
+	if job == nil {
+		return
+	}
+	job.ctx, job.cancel = context.WithCancel(context.Background())
+	p.running[job.buf] = job
+}
+
+// next returns a job which was submitted while the previous job of the buffer
+// was running. Buffer is not running anymore if there is no such job.
+func (p *Pipeline) next(buf nvim.Buffer) *highlightJob {
+	p.guard.Lock()
+	defer p.guard.Unlock()
+	if prev := p.running[buf]; prev != nil {
+		prev.cancel()
+	}
+
+	var job = p.pending[buf]
+	delete(p.pending, buf)
+	if job == nil {
+		delete(p.running, buf)
+	}
+	p.start(job)
+	return job
+}
+
+// runSafe runs a job and recovers from panic, so the worker is not lost.
+func (p *Pipeline) runSafe(job *highlightJob) {
+	defer func() {
+		if reason := recover(); reason != nil {
+			job.event.Errorf("highlighting panicked: %v", reason)
+		}
+	}()
+	p.run(job)
+}
+
+// forgotten reports whether document of a job is not indexed anymore.
+func forgotten(job *highlightJob) bool {
+	if doc, ok := DocIndex.Get(job.buf); !ok || doc != job.doc {
+		job.event.Debugf("job of forgotten document is dropped")
+		return true
+	}
+	return false
+}
+
+// pipeline lazily starts highlighting pipeline. Number of workers is taken
+// from g:bnf_workers option.
+func (h *Highlighter) pipeline() *Pipeline {
+	h.pipelineOnce.Do(func() {
+		var size int
+		var expr = "get(g:, 'bnf_workers', 0)"
+		if err := Eval(h.nvim, expr, &size); err != nil {
+			logger.Warnf("failed to get g:bnf_workers: %s", err)
+		}
+		h.workers = NewPipeline(size, h.highlight)
+	})
+	return h.workers
+}
+
+// highlight runs highlighting job with document locked. Jobs of documents
+// which were forgotten in the meantime are dropped.
+func (h *Highlighter) highlight(job *highlightJob) {
+	if h.stopped() || forgotten(job) {
+		return
+	}
+
+	// Document could be forgotten while its lock is awaited.
+	job.doc.Lock()
+	defer job.doc.Unlock()
+	if forgotten(job) {
+		return
+	}
+
+	var start = time.Now()
+	var err error
+	if job.to < 0 {
+		err = job.doc.Hightlight(job.ctx, h.nvim, job.buf)
+	} else {
+		err = job.doc.HightlightHunk(job.ctx, h.nvim, job.buf, job.from,
+			job.to)
+	}
+
+	if err != nil && job.ctx.Err() != nil {
+		job.event.WithDuration(start).Debugf("highlighting is aborted: %s",
+			err)
+		return
+	} else if err != nil {
+		job.event.WithDuration(start).Errorf("failed to highlight: %s", err)
+		return
+	}
+	h.timings.Add(time.Since(start))
+	metricHighlights.Add(1)
+	metricHighlightSeconds.Add(time.Since(start).Seconds())
+	job.event.WithDuration(start).Debugf("hunk is highlighted")
+}
@@ -0,0 +1,132 @@
+package highlighting
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/neovim/go-client/nvim"
+)
+
+func TestPipeline(t *testing.T) {
+	var guard sync.Mutex
+	var ticks = make(map[nvim.Buffer][]int)
+	var block = make(chan struct{})
+
+	var pipeline = NewPipeline(4, func(job *highlightJob) {
+		<-block
+		guard.Lock()
+		ticks[job.buf] = append(ticks[job.buf], job.tick)
+		guard.Unlock()
+	})
+
+	var event = logger.WithFields(nil)
+	for tick := 1; tick <= 5; tick++ {
+		for buf := nvim.Buffer(1); buf <= 3; buf++ {
+			pipeline.Submit(&highlightJob{
+				buf:   buf,
+				tick:  tick,
+				from:  tick,
+				to:    tick + 1,
+				event: event,
+			})
+		}
+	}
+
+	close(block)
+	pipeline.Close()
+
+	// The first job of a buffer could be taken by worker before the other
+	// ones are submitted while the rest are superseded by the last one.
+	for buf := nvim.Buffer(1); buf <= 3; buf++ {
+		var seq = ticks[buf]
+		if len(seq) == 0 || len(seq) > 2 || seq[len(seq)-1] != 5 {
+			t.Errorf("wrong jobs of buffer %d: %v", buf, seq)
+		}
+		for idx := 1; idx < len(seq); idx++ {
+			if seq[idx-1] >= seq[idx] {
+				t.Errorf("jobs of buffer %d are out of order: %v", buf, seq)
+			}
+		}
+	}
+}
+
+func TestSupersede(t *testing.T) {
+	var job = &highlightJob{from: 5, to: 6}
+	job.supersede(&highlightJob{from: 2, to: 3})
+	if job.from != 2 || job.to != 6 {
+		t.Errorf("wrong merged range: [%d, %d)", job.from, job.to)
+	}
+
+	job = &highlightJob{from: 5, to: 7, shifted: true}
+	job.supersede(&highlightJob{from: 8, to: 9})
+	if job.from != 0 || job.to != -1 {
+		t.Errorf("shifted range is not widened: [%d, %d)", job.from, job.to)
+	}
+}
+
+func TestPipelineCancel(t *testing.T) {
+	var started = make(chan *highlightJob)
+	var aborted = make(chan bool)
+	var done = make(chan *highlightJob, 1)
+
+	var pipeline = NewPipeline(1, func(job *highlightJob) {
+		if job.tick == 1 {
+			started <- job
+			<-job.ctx.Done()
+			aborted <- true
+		} else {
+			done <- job
+		}
+	})
+	defer pipeline.Close()
+
+	var event = logger.WithFields(nil)
+	pipeline.Submit(&highlightJob{buf: 1, tick: 1, to: -1, event: event})
+	<-started
+
+	if pipeline.Cancel(1, 1) {
+		t.Errorf("job is cancelled by the same changedtick")
+	}
+	if !pipeline.Cancel(1, 2) {
+		t.Fatalf("stale job is not cancelled")
+	}
+	<-aborted
+
+	// Job of a hunk is widened since the whole document was not highlighted.
+	pipeline.Submit(&highlightJob{buf: 1, tick: 2, from: 3, to: 4,
+		event: event})
+	if job := <-done; job.from != 0 || job.to != -1 {
+		t.Errorf("job is not widened: [%d, %d)", job.from, job.to)
+	}
+}
+
+func TestPipelineSubmitClose(t *testing.T) {
+	var event = logger.WithFields(nil)
+	for round := 0; round < 100; round++ {
+		var pipeline = NewPipeline(1, func(job *highlightJob) {})
+		var started, done sync.WaitGroup
+		for buf := nvim.Buffer(1); buf <= 8; buf++ {
+			started.Add(1)
+			done.Add(1)
+			go func(buf nvim.Buffer) {
+				defer done.Done()
+				for tick := 0; tick < 100; tick++ {
+					pipeline.Submit(&highlightJob{
+						buf:   buf + nvim.Buffer(8*tick),
+						tick:  tick,
+						to:    -1,
+						event: event,
+					})
+					if tick == 0 {
+						started.Done()
+					}
+				}
+			}(buf)
+		}
+
+		// Pipeline is closed while buffers are sent to its queue.
+		started.Wait()
+		pipeline.Close()
+		done.Wait()
+	}
+}
@@ -0,0 +1,199 @@
+package highlighting
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+	"github.com/neovim/go-client/nvim"
+)
+
+// previewLimit is the maximal number of non-terminals in preview.
+const previewLimit = 10
+
+// previewWidth is the maximal width of preview window.
+const previewWidth = 80
+
+// CursorEvent describes the current line of a buffer and zero-based byte
+// column of cursor in it.
+type CursorEvent struct {
+	Buffer int    `msgpack:"buffer"`
+	Line   string `msgpack:"line"`
+	Col    int    `msgpack:"col"`
+}
+
+// cursorEventEval is an expression which is evaluated to CursorEvent.
+const cursorEventEval = `{"buffer": str2nr(expand("<abuf>")), ` +
+	`"line": getline("."), "col": col(".") - 1}`
+
+// PreviewPrefix returns prefix of non-terminal which is being typed on the
+// right-hand side of a rule in classic BNF, i.e. text between the nearest `<`
+// before cursor and cursor. Prefix is empty right after `<` is typed.
+func PreviewPrefix(line string, col int) (string, bool) {
+	if col < 0 || col > len(line) {
+		return "", false
+	}
+
+	var text = line[:col]
+	var lt = strings.LastIndexByte(text, '<')
+	if lt < 0 || !strings.Contains(text[:lt], "::=") {
+		return "", false
+	}
+
+	// Opening bracket of non-terminal should not be in a string.
+	if strings.Count(text[:lt], `"`)%2 != 0 ||
+		strings.Count(text[:lt], `'`)%2 != 0 {
+		return "", false
+	}
+
+	var prefix = text[lt+1:]
+	for _, char := range prefix {
+		if !isNameChar(char) {
+			return "", false
+		}
+	}
+	return prefix, true
+}
+
+func isNameChar(char rune) bool {
+	return char == '-' || char >= '0' && char <= '9' ||
+		char >= 'a' && char <= 'z' || char >= 'A' && char <= 'Z'
+}
+
+// PreviewLines lists symbols which start with a prefix together with their
+// definitions in a grammar. Symbols which are not defined are listed by
+// their names only. At most previewLimit symbols are listed.
+func PreviewLines(
+	grammar *analysis.Grammar, symbols []string, prefix string,
+) []string {
+	var names []string
+	for _, name := range symbols {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if len(names) > previewLimit {
+		names = names[:previewLimit]
+	}
+
+	var lines = make([]string, 0, len(names))
+	for _, name := range names {
+		var line = "<" + name + ">"
+		if rule := grammar.Definition(name); rule != nil {
+			line += " ::= " + strings.Join(renderAlternatives(rule), " | ")
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func renderAlternatives(rule *analysis.Rule) []string {
+	var alts []string
+	for _, alt := range rule.Alternatives() {
+		alts = append(alts, strings.Join(alt, " "))
+	}
+	return alts
+}
+
+// HandleTextChangedIEvent shows a floating preview of non-terminals which
+// match the one being typed on the right-hand side of a rule together with
+// their definitions. Preview is closed as soon as cursor leaves a
+// non-terminal.
+func (h *Highlighter) HandleTextChangedIEvent(ev *CursorEvent) {
+	logger.Debugf("HandleTextChangedIEvent(%d, %d)", ev.Buffer, ev.Col)
+
+	var buf = nvim.Buffer(ev.Buffer)
+	var doc, ok = DocIndex.Get(buf)
+	if !ok || doc.Dialect() != parser.DialectBNF || doc.Lightweight() ||
+		!h.enabled() {
+		h.closePreview()
+		return
+	}
+
+	prefix, ok := PreviewPrefix(ev.Line, ev.Col)
+	if !ok {
+		h.closePreview()
+		return
+	}
+
+	doc.Lock()
+	var symbols = make([]string, 0, len(doc.symbols))
+	for name := range doc.symbols {
+		symbols = append(symbols, name)
+	}
+	var lines = PreviewLines(doc.grammar(), symbols, prefix)
+	doc.Unlock()
+
+	if err := h.showPreview(lines); err != nil {
+		logger.Warnf("failed to show preview: %s", err)
+	}
+}
+
+// HandleInsertLeaveEvent closes preview of non-terminals.
+func (h *Highlighter) HandleInsertLeaveEvent() {
+	logger.Debugf("HandleInsertLeaveEvent()")
+	h.closePreview()
+}
+
+// showPreview replaces preview window with a new one which shows lines below
+// cursor. Preview is closed if there is nothing to show.
+func (h *Highlighter) showPreview(lines []string) error {
+	h.closePreview()
+	if len(lines) == 0 {
+		return nil
+	}
+
+	var width = 1
+	var content = make([][]byte, len(lines))
+	for idx, line := range lines {
+		if n := utf8.RuneCountInString(line); n > width {
+			width = n
+		}
+		content[idx] = []byte(line)
+	}
+	if width > previewWidth {
+		width = previewWidth
+	}
+
+	var buf, err = h.newScratchBuffer(content)
+	if err != nil {
+		return err
+	}
+
+	win, err := OpenFloatingWindow(h.nvim, buf, map[string]interface{}{
+		"relative":  "cursor",
+		"row":       1,
+		"col":       0,
+		"width":     width,
+		"height":    len(lines),
+		"style":     "minimal",
+		"focusable": false,
+	})
+	if err != nil {
+		return err
+	}
+
+	h.previewGuard.Lock()
+	h.preview = win
+	h.previewGuard.Unlock()
+	return nil
+}
+
+// closePreview closes preview window if it is open.
+func (h *Highlighter) closePreview() {
+	h.previewGuard.Lock()
+	var win = h.preview
+	h.preview = 0
+	h.previewGuard.Unlock()
+
+	if win == 0 {
+		return
+	}
+
+	if err := CloseWindow(h.nvim, win); err != nil {
+		logger.Warnf("failed to close preview: %s", err)
+	}
+}
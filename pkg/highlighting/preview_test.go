@@ -0,0 +1,51 @@
+package highlighting
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+func TestPreviewPrefix(t *testing.T) {
+	var tests = []struct {
+		line   string
+		col    int
+		prefix string
+		ok     bool
+	}{
+		{`<a> ::= <`, 9, "", true},
+		{`<a> ::= <ex`, 11, "ex", true},
+		{`<a> ::= <expr-`, 14, "expr-", true},
+		{`<a> ::= <expr> "x"`, 14, "", false},
+		{`<a> ::= "<ex`, 12, "", false},
+		{`<ex`, 3, "", false},
+		{`<a> ::= <b c`, 12, "", false},
+	}
+
+	for _, test := range tests {
+		var prefix, ok = PreviewPrefix(test.line, test.col)
+		if prefix != test.prefix || ok != test.ok {
+			t.Errorf("%s: wrong prefix: %q %t", test.line, prefix, ok)
+		}
+	}
+}
+
+func TestPreviewLines(t *testing.T) {
+	var lines = [][]byte{
+		[]byte(`<expr> ::= <term> | <expr> "+" <term>`),
+		[]byte(`<term> ::= "x"`),
+	}
+
+	var grammar = analysis.NewGrammar(parser.DialectBNF, lines)
+	var symbols = []string{"term", "expr", "exp-list"}
+	var expected = []string{
+		`<exp-list>`,
+		`<expr> ::= <term> | <expr> "+" <term>`,
+	}
+	var preview = PreviewLines(grammar, symbols, "exp")
+	if !reflect.DeepEqual(preview, expected) {
+		t.Errorf("wrong preview: %q", preview)
+	}
+}
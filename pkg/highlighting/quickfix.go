@@ -0,0 +1,98 @@
+package highlighting
+
+import (
+	"github.com/daskol/nvim-bnf/pkg/lint"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+	"github.com/neovim/go-client/nvim"
+)
+
+// QuickfixItem is a diagnostic of a buffer in the form of item of quickfix
+// list. Line and columns are one-based and columns are counted in bytes.
+type QuickfixItem struct {
+	Buffer int    `msgpack:"bufnr"`
+	Line   int    `msgpack:"lnum"`
+	Col    int    `msgpack:"col"`
+	EndCol int    `msgpack:"end_col"`
+	Type   string `msgpack:"type"`
+	Text   string `msgpack:"text"`
+}
+
+// newQuickfixItem converts diagnostic on zero-based line to quickfix item.
+func newQuickfixItem(
+	buf nvim.Buffer, row int, diag parser.Diagnostic,
+) QuickfixItem {
+	var text = diag.Message
+	if diag.Code != "" {
+		text = diag.Code + ": " + text
+	}
+
+	var kind = "E"
+	switch diag.Severity {
+	case parser.SeverityWarning:
+		kind = "W"
+	case parser.SeverityInfo:
+		kind = "I"
+	case parser.SeverityHint:
+		kind = "N"
+	}
+
+	return QuickfixItem{
+		Buffer: int(buf),
+		Line:   row + 1,
+		Col:    diag.Range.Begin + 1,
+		EndCol: diag.Range.End + 1,
+		Type:   kind,
+		Text:   text,
+	}
+}
+
+// quickfixItems gathers all diagnostics of document: parsing errors, warnings
+// about rules, references to undefined non-terminals, and findings of linter.
+// Items are ordered by their positions. Document should be locked.
+func (d *Document) quickfixItems(buf nvim.Buffer) []QuickfixItem {
+	var grammar = d.grammar()
+	var findings = d.findings()
+	var undefined = grammar.UndefinedReferences()
+	for _, rule := range grammar.Rules {
+		for _, diag := range undefined[rule] {
+			var finding = lint.Finding{Rule: rule, Diagnostic: diag}
+			findings = append(findings, finding)
+		}
+	}
+
+	var items = []QuickfixItem{}
+	for _, diag := range d.collectDiagnostics(findings) {
+		items = append(items, newQuickfixItem(buf, diag.Line, diag.Diagnostic))
+	}
+	return items
+}
+
+// HandleQuickfixCommand fills quickfix list with all diagnostics of the
+// current buffer and opens quickfix window if there are any.
+func (h *Highlighter) HandleQuickfixCommand() error {
+	logger.Debugf("HandleQuickfixCommand()")
+
+	var buf, err = CurrentBuffer(h.nvim)
+	if err != nil {
+		return err
+	}
+
+	var doc, ok = DocIndex.Get(buf)
+	if !ok {
+		return errNotAttached
+	}
+
+	doc.Lock()
+	var items = doc.quickfixItems(buf)
+	doc.Unlock()
+
+	var res int
+	var what = map[string]interface{}{
+		"title": ":BNFQuickfix",
+		"items": items,
+	}
+	if err := Call(h.nvim, "setqflist", &res, []int{}, " ", what); err != nil {
+		return err
+	}
+	return Command(h.nvim, "cwindow")
+}
@@ -0,0 +1,37 @@
+package highlighting
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+func TestQuickfixItems(t *testing.T) {
+	var doc = &Document{
+		dialect: parser.DialectBNF,
+		Lines: [][]byte{
+			[]byte(`<s> ::= <a> <b>`),
+			[]byte(`<a> ::= "a" $`),
+		},
+	}
+
+	var items = doc.quickfixItems(3)
+	var kinds []string
+	for _, item := range items {
+		if item.Buffer != 3 {
+			t.Errorf("wrong buffer: %+v", item)
+		}
+		kinds = append(kinds, item.Type+item.Text[:4])
+	}
+
+	// Rule <a> fails to parse semantically, so both <a> and <b> are not
+	// defined and <s> is non-productive.
+	var expected = []string{"WW006", "WW010", "WW010", "EE001"}
+	if !reflect.DeepEqual(kinds, expected) {
+		t.Errorf("wrong items: %v", items)
+	}
+	if item := items[3]; item.Line != 2 || item.Col != 13 {
+		t.Errorf("wrong position of parsing error: %+v", item)
+	}
+}
@@ -0,0 +1,164 @@
+package highlighting
+
+import (
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+	"github.com/daskol/nvim-bnf/pkg/i18n"
+	"github.com/daskol/nvim-bnf/pkg/language"
+)
+
+// quizQuestions is a number of generated strings which user should classify.
+const quizQuestions = 5
+
+// quizDepth limits depth of derivation of generated strings.
+const quizDepth = 6
+
+// quizQuestion is a string together with the right answer whether it belongs
+// to a language.
+type quizQuestion struct {
+	value string
+	valid bool
+}
+
+// HandleQuizCommand asks user whether generated strings belong to language of
+// a rule and then asks to type a string of the language. Answers are checked
+// with recognizer and score is shown at the end. Rule is the start symbol if
+// it is omitted.
+func (h *Highlighter) HandleQuizCommand(args []string) error {
+	logger.Debugf("HandleQuizCommand(%v)", args)
+
+	var buf, err = CurrentBuffer(h.nvim)
+	if err != nil {
+		return err
+	}
+
+	lines, err := BufferLines(h.nvim, buf, 0, -1, true)
+	if err != nil {
+		return err
+	}
+
+	var dialect = h.dialectOf(buf)
+	var grammar = analysis.NewGrammar(dialect, lines)
+	if start := h.startSymbol(); start != "" {
+		grammar.Start = start
+	}
+
+	var lang = language.New(dialect, grammar)
+	var rule = grammar.StartSymbol()
+	if len(args) > 0 {
+		rule = strings.TrimSuffix(strings.TrimPrefix(args[0], "<"), ">")
+	}
+	if !lang.Defines(rule) {
+		return newError(CodeUnknownRule, "there is no rule <"+rule+">")
+	}
+
+	var rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	questions, err := newQuiz(lang, rule, rng)
+	if err != nil {
+		return err
+	}
+
+	var report []string
+	var score = 0
+	for _, question := range questions {
+		var answer, err = h.ask(i18n.Sprintf("%q belongs to <%s>? [y/n] ",
+			question.value, rule))
+		if err != nil {
+			return err
+		}
+
+		var yes = strings.HasPrefix(strings.ToLower(answer), "y")
+		var mark = "- "
+		if yes == question.valid {
+			mark = "+ "
+			score++
+		}
+		report = append(report, mark+formatMembership(question, rule))
+	}
+
+	answer, err := h.ask(i18n.Sprintf("Type a string which belongs to <%s>: ",
+		rule))
+	if err != nil {
+		return err
+	}
+
+	var question = quizQuestion{value: answer}
+	question.valid, _ = lang.Recognize(rule, answer)
+	if question.valid {
+		score++
+		report = append(report, "+ "+formatMembership(question, rule))
+	} else {
+		report = append(report, "- "+formatMembership(question, rule))
+	}
+
+	report = append(report, i18n.Sprintf("Score: %d of %d", score,
+		len(questions)+1))
+	return WriteOut(h.nvim, "\n"+strings.Join(report, "\n")+"\n")
+}
+
+// ask prompts user for an answer on command line.
+func (h *Highlighter) ask(prompt string) (string, error) {
+	var answer string
+	var err = Prompt(h.nvim, "input", &answer, prompt)
+	return answer, err
+}
+
+func formatMembership(question quizQuestion, rule string) string {
+	if question.valid {
+		return i18n.Sprintf("%q belongs to <%s>", question.value, rule)
+	}
+	return i18n.Sprintf("%q does not belong to <%s>", question.value, rule)
+}
+
+// newQuiz generates strings of a language and their mutations which do not
+// belong to the language. About a half of questions are negative ones.
+func newQuiz(
+	lang *language.Language, rule string, rng *rand.Rand,
+) ([]quizQuestion, error) {
+	var questions []quizQuestion
+	for len(questions) < quizQuestions {
+		var value, err = lang.Generate(rule, rng, quizDepth)
+		if err != nil {
+			return nil, err
+		}
+
+		// Predicates are ignored by generator, so the string is checked.
+		var question = quizQuestion{value: value}
+		if question.valid, err = lang.Recognize(rule, value); err != nil {
+			return nil, err
+		}
+
+		if rng.Intn(2) == 0 {
+			var mutant = mutate(value, rng)
+			if ok, _ := lang.Recognize(rule, mutant); !ok {
+				question = quizQuestion{value: mutant}
+			}
+		}
+
+		questions = append(questions, question)
+	}
+	return questions, nil
+}
+
+// mutate randomly deletes, duplicates, or swaps characters of a string.
+func mutate(value string, rng *rand.Rand) string {
+	var chars = []rune(value)
+	if len(chars) == 0 {
+		return "?"
+	}
+
+	var idx = rng.Intn(len(chars))
+	switch rng.Intn(3) {
+	case 0:
+		chars = append(chars[:idx], chars[idx+1:]...)
+	case 1:
+		chars = append(chars[:idx+1], chars[idx:]...)
+	default:
+		var other = rng.Intn(len(chars))
+		chars[idx], chars[other] = chars[other], chars[idx]
+	}
+	return string(chars)
+}
@@ -0,0 +1,53 @@
+package highlighting
+
+import (
+	"fmt"
+
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+// rainbowLinks are default links of highlight groups of brackets of nested
+// groups. Groups are cycled as groups are nested deeper.
+var rainbowLinks = []string{
+	"Special", "Function", "Type", "Constant", "Statement", "PreProc",
+}
+
+// rainbowGroup returns highlight group of brackets of a group at zero-based
+// depth of nesting, e.g. BnfRainbow1 for outermost groups.
+func rainbowGroup(depth int) string {
+	return fmt.Sprintf("BnfRainbow%d", depth%len(rainbowLinks)+1)
+}
+
+// RainbowGroups returns highlight groups of opening and closing brackets of
+// grouped expressions of a parse tree by their offsets. Group depends on how
+// deeply group is nested into other groups.
+func RainbowGroups(ast *parser.AST) map[int]string {
+	var groups = make(map[int]string)
+	ast.Walk(func(cursor *parser.Cursor) error {
+		var group, ok = cursor.Node.(*parser.GroupExpression)
+		if !ok {
+			return nil
+		}
+
+		var depth = 0
+		for _, parent := range cursor.Parents {
+			if _, ok := parent.(*parser.GroupExpression); ok {
+				depth++
+			}
+		}
+		groups[group.Begin] = rainbowGroup(depth)
+		groups[group.End-1] = rainbowGroup(depth)
+		return nil
+	}, nil)
+	return groups
+}
+
+// rainbowEnabled reports whether brackets of nested groups are colored by
+// depth. It is enabled with g:bnf_rainbow option.
+func (h *Highlighter) rainbowEnabled() bool {
+	var enabled int
+	if err := Eval(h.nvim, "get(g:, 'bnf_rainbow', 0)", &enabled); err != nil {
+		logger.Warnf("failed to get g:bnf_rainbow: %s", err)
+	}
+	return enabled != 0
+}
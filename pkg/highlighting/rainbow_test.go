@@ -0,0 +1,30 @@
+package highlighting
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+func TestRainbowGroups(t *testing.T) {
+	var line = []byte(`a = ("x", ["y", {"z"}]) | ("w");`)
+	var ast, err = parser.ParseDialect(parser.DialectEBNF, line)
+	if err != nil {
+		t.Fatalf("failed to parse: %s", err)
+	}
+
+	var expected = map[int]string{
+		4: "BnfRainbow1", 22: "BnfRainbow1",
+		10: "BnfRainbow2", 21: "BnfRainbow2",
+		16: "BnfRainbow3", 20: "BnfRainbow3",
+		26: "BnfRainbow1", 30: "BnfRainbow1",
+	}
+	if groups := RainbowGroups(ast); !reflect.DeepEqual(groups, expected) {
+		t.Errorf("wrong groups: %v", groups)
+	}
+
+	if group := rainbowGroup(len(rainbowLinks)); group != "BnfRainbow1" {
+		t.Errorf("groups are not cycled: %s", group)
+	}
+}
@@ -0,0 +1,62 @@
+package highlighting
+
+import (
+	"fmt"
+	"reflect"
+	"runtime/debug"
+)
+
+// withRecover wraps RPC handler so that panic in it does not kill plugin
+// host. Panic is logged together with stack trace and user is notified about
+// it. Handlers which return error fail with internal error while the other
+// ones return zero values.
+func (h *Highlighter) withRecover(
+	name string, handler interface{},
+) interface{} {
+	return recoverHandler(name, handler, func(msg string) {
+		if err := Notify(h.nvim, msg, NotifyError); err != nil {
+			logger.Errorf("failed to notify about panic: %s", err)
+		}
+	})
+}
+
+func recoverHandler(
+	name string, handler interface{}, notify func(msg string),
+) interface{} {
+	var fn = reflect.ValueOf(handler)
+	var typ = fn.Type()
+
+	return reflect.MakeFunc(typ, func(args []reflect.Value) (
+		results []reflect.Value,
+	) {
+		metricEvents.Add(name, 1)
+		defer func() {
+			var reason = recover()
+			if reason == nil {
+				return
+			}
+
+			logger.Errorf("handler %s panicked: %v\n%s", name, reason,
+				debug.Stack())
+
+			var msg = fmt.Sprintf("handler %s panicked: %v", name, reason)
+			var err = newError(CodeInternal, msg)
+			notify(err.Error())
+
+			results = make([]reflect.Value, typ.NumOut())
+			for idx := range results {
+				results[idx] = reflect.Zero(typ.Out(idx))
+			}
+			if last := typ.NumOut() - 1; last >= 0 &&
+				typ.Out(last) == errorType {
+				results[last] = reflect.New(errorType).Elem()
+				results[last].Set(reflect.ValueOf(err))
+			}
+		}()
+
+		if typ.IsVariadic() {
+			return fn.CallSlice(args)
+		}
+		return fn.Call(args)
+	}).Interface()
+}
@@ -0,0 +1,44 @@
+package highlighting
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRecoverHandler(t *testing.T) {
+	var notes []string
+	var notify = func(msg string) {
+		notes = append(notes, msg)
+	}
+
+	var handler = func(args []string) (int, error) {
+		return len(args[0]), nil
+	}
+
+	var wrapped = recoverHandler("BNFQuiz", handler, notify).(func(
+		[]string) (int, error))
+	if res, err := wrapped([]string{"expr"}); res != 4 || err != nil {
+		t.Errorf("wrong result: %d, %v", res, err)
+	}
+
+	var res, err = wrapped(nil)
+	if res != 0 {
+		t.Errorf("result is not zero: %d", res)
+	}
+	if err, ok := err.(*Error); !ok || err.Code != CodeInternal {
+		t.Errorf("panic is not internal error: %#v", err)
+	}
+
+	if len(notes) != 1 || !strings.Contains(notes[0], "BNFQuiz panicked") {
+		t.Errorf("wrong notifications: %v", notes)
+	}
+
+	var event = recoverHandler("nvim_buf_lines_event", func() {
+		panic("nil map")
+	}, notify).(func())
+	event()
+
+	if len(notes) != 2 {
+		t.Errorf("panic in event handler is not notified")
+	}
+}
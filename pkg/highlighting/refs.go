@@ -0,0 +1,122 @@
+package highlighting
+
+import (
+	"fmt"
+
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+	"github.com/neovim/go-client/nvim"
+)
+
+// CursorHoldEvent describes zero-based position of cursor which rests in a
+// buffer.
+type CursorHoldEvent struct {
+	Buffer int `msgpack:"buffer"`
+	Row    int `msgpack:"row"`
+	Col    int `msgpack:"col"`
+}
+
+// cursorHoldEventEval is an expression which is evaluated to CursorHoldEvent.
+const cursorHoldEventEval = `{"buffer": str2nr(expand("<abuf>")), ` +
+	`"row": line(".") - 1, "col": col(".") - 1}`
+
+// LineSpan is a half-open range of bytes of a line.
+type LineSpan struct {
+	Row   int
+	Begin int
+	End   int
+}
+
+// HandleCursorHoldEvent highlights definitions of a non-terminal under cursor
+// and all references to it with BnfRefHighlight group. Highlights are cleared
+// as soon as cursor moves. It is disabled with g:bnf_ref_highlight option.
+func (h *Highlighter) HandleCursorHoldEvent(ev *CursorHoldEvent) {
+	logger.Debugf("HandleCursorHoldEvent(%d, %d, %d)", ev.Buffer, ev.Row,
+		ev.Col)
+
+	var buf = nvim.Buffer(ev.Buffer)
+	var doc, ok = DocIndex.Get(buf)
+	if !ok || doc.Lightweight() || !h.enabled() || !h.refHighlightEnabled() {
+		return
+	}
+
+	doc.Lock()
+	var spans = ReferenceSpans(doc.Dialect(), doc.Lines, ev.Row, ev.Col)
+	doc.Unlock()
+
+	if len(spans) == 0 {
+		return
+	}
+
+	var batch = h.nvim.NewBatch()
+	batch.ClearBufferHighlight(buf, h.references, 0, -1)
+	for _, span := range spans {
+		var res int
+		h.caps.Highlight(batch, buf, h.references, "BnfRefHighlight",
+			span.Row, span.Begin, span.End, &res)
+	}
+
+	// Highlights are cleared by NeoVim itself, so that plugin host is not
+	// notified on every move of cursor.
+	batch.Command(fmt.Sprintf("autocmd nvim-bnf CursorMoved,CursorMovedI,"+
+		"BufLeave <buffer=%d> ++once call nvim_buf_clear_namespace(%d, %d, "+
+		"0, -1)", buf, buf, h.references))
+	if err := execute(batch); err != nil {
+		logger.Warnf("failed to highlight references: %s", err)
+	}
+}
+
+// refHighlightEnabled checks whether references should be highlighted when
+// cursor rests on a non-terminal. It is enabled by default.
+func (h *Highlighter) refHighlightEnabled() bool {
+	var enabled = 1
+	var expr = "get(g:, 'bnf_ref_highlight', 1)"
+	if err := Eval(h.nvim, expr, &enabled); err != nil {
+		logger.Warnf("failed to get g:bnf_ref_highlight: %s", err)
+	}
+	return enabled != 0
+}
+
+// ReferenceSpans returns spans to highlight if cursor is on a non-terminal at
+// zero-based row and byte column: whole lines of rules which define the
+// non-terminal and every reference to it. There is nothing to highlight if
+// cursor is not on a non-terminal.
+func ReferenceSpans(
+	dialect parser.Dialect, lines [][]byte, row, col int,
+) []LineSpan {
+	var name = SymbolAt(dialect, lines, row, col)
+	if name == "" {
+		return nil
+	}
+
+	var _, index = parser.JoinLines(lines)
+	var locate = func(rule *analysis.Rule, offset int) (int, int) {
+		if dialect.Multiline() {
+			return index.Locate(offset)
+		}
+		return rule.Line, offset
+	}
+
+	var spans []LineSpan
+	var grammar = analysis.NewGrammar(dialect, lines)
+	for _, rule := range grammar.Rules {
+		var rhs = rule.Statement.Rule.Right()
+		if rule.Name == name {
+			var last, _ = locate(rule, parser.Span(rhs).End)
+			for line := rule.Line; line <= last && line < len(lines); line++ {
+				spans = append(spans, LineSpan{line, 0, len(lines[line])})
+			}
+		}
+
+		parser.Walk(rhs, func(cursor *parser.Cursor) error {
+			var ref, ok = cursor.Node.(*parser.NonTerminal)
+			if ok && string(ref.Name) == name {
+				var line, begin = locate(rule, ref.Begin)
+				spans = append(spans,
+					LineSpan{line, begin, begin + ref.End - ref.Begin})
+			}
+			return nil
+		}, nil)
+	}
+	return spans
+}
@@ -0,0 +1,45 @@
+package highlighting
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+func TestReferenceSpans(t *testing.T) {
+	var lines = [][]byte{
+		[]byte(`<expr> ::= <term> | <expr> "+" <term>`),
+		[]byte(`<term> ::= "x"`),
+	}
+
+	var spans = ReferenceSpans(parser.DialectBNF, lines, 0, 12)
+	var expected = []LineSpan{{0, 11, 17}, {0, 31, 37}, {1, 0, 14}}
+	if !reflect.DeepEqual(spans, expected) {
+		t.Errorf("wrong spans of term: %v", spans)
+	}
+
+	spans = ReferenceSpans(parser.DialectBNF, lines, 0, 1)
+	expected = []LineSpan{{0, 0, 37}, {0, 20, 26}}
+	if !reflect.DeepEqual(spans, expected) {
+		t.Errorf("wrong spans of expr: %v", spans)
+	}
+
+	if spans = ReferenceSpans(parser.DialectBNF, lines, 0, 9); spans != nil {
+		t.Errorf("spans outside of non-terminal: %v", spans)
+	}
+}
+
+func TestReferenceSpansMultiline(t *testing.T) {
+	var lines = [][]byte{
+		[]byte(`expr = term,`),
+		[]byte(`  {"+", term};`),
+		[]byte(`term = "x";`),
+	}
+
+	var spans = ReferenceSpans(parser.DialectEBNF, lines, 1, 8)
+	var expected = []LineSpan{{0, 7, 11}, {1, 8, 12}, {2, 0, 11}}
+	if !reflect.DeepEqual(spans, expected) {
+		t.Errorf("wrong spans of term: %v", spans)
+	}
+}
@@ -0,0 +1,75 @@
+package highlighting
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/neovim/go-client/nvim"
+)
+
+// Registry is an index of documents by buffers. It is safe for concurrent use
+// since go-client could run RPC handlers concurrently. Documents themselves
+// should be locked before they are read or modified.
+type Registry struct {
+	guard sync.RWMutex
+	docs  map[nvim.Buffer]*Document
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{docs: make(map[nvim.Buffer]*Document)}
+}
+
+// Get returns document of a buffer if the buffer is attached.
+func (r *Registry) Get(buf nvim.Buffer) (*Document, bool) {
+	r.guard.RLock()
+	defer r.guard.RUnlock()
+	var doc, ok = r.docs[buf]
+	return doc, ok
+}
+
+// Put adds or replaces document of a buffer.
+func (r *Registry) Put(buf nvim.Buffer, doc *Document) {
+	r.guard.Lock()
+	defer r.guard.Unlock()
+	r.docs[buf] = doc
+}
+
+// Delete forgets document of a buffer. It reports whether the buffer was
+// attached.
+func (r *Registry) Delete(buf nvim.Buffer) bool {
+	r.guard.Lock()
+	defer r.guard.Unlock()
+	var _, ok = r.docs[buf]
+	delete(r.docs, buf)
+	return ok
+}
+
+// Len returns number of attached buffers.
+func (r *Registry) Len() int {
+	r.guard.RLock()
+	defer r.guard.RUnlock()
+	return len(r.docs)
+}
+
+// Buffers returns snapshot of attached buffers in ascending order, so they
+// could be iterated without holding lock of registry.
+func (r *Registry) Buffers() []nvim.Buffer {
+	r.guard.RLock()
+	defer r.guard.RUnlock()
+	var bufs = make([]nvim.Buffer, 0, len(r.docs))
+	for buf := range r.docs {
+		bufs = append(bufs, buf)
+	}
+	sort.Slice(bufs, func(i, j int) bool {
+		return bufs[i] < bufs[j]
+	})
+	return bufs
+}
+
+// Reset forgets all documents.
+func (r *Registry) Reset() {
+	r.guard.Lock()
+	defer r.guard.Unlock()
+	r.docs = make(map[nvim.Buffer]*Document)
+}
@@ -0,0 +1,99 @@
+package highlighting
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/neovim/go-client/nvim"
+)
+
+func TestRegistry(t *testing.T) {
+	var registry = NewRegistry()
+	var wg sync.WaitGroup
+	for idx := 1; idx <= 16; idx++ {
+		wg.Add(1)
+		go func(buf nvim.Buffer) {
+			defer wg.Done()
+			registry.Put(buf, &Document{})
+			if _, ok := registry.Get(buf); !ok {
+				t.Errorf("buffer %d is not registered", buf)
+			}
+			if buf%2 == 0 && !registry.Delete(buf) {
+				t.Errorf("buffer %d is not deleted", buf)
+			}
+		}(nvim.Buffer(idx))
+	}
+	wg.Wait()
+
+	var bufs = registry.Buffers()
+	if len(bufs) != 8 || registry.Len() != 8 {
+		t.Fatalf("wrong number of buffers: %v", bufs)
+	}
+	for idx, buf := range bufs {
+		if buf != nvim.Buffer(2*idx+1) {
+			t.Errorf("wrong buffer #%d: %d", idx, buf)
+		}
+	}
+
+	if registry.Delete(2) {
+		t.Errorf("deleted buffer is deleted again")
+	}
+
+	registry.Reset()
+	if registry.Len() != 0 {
+		t.Errorf("registry is not empty after reset")
+	}
+}
+
+func TestDropSymbols(t *testing.T) {
+	var backup = NonTerminalIndex
+	defer func() {
+		NonTerminalIndex = backup
+	}()
+
+	NonTerminalIndex = map[string]uint{"expr": 3, "term": 1, "digit": 2}
+	var doc = &Document{symbols: map[string]uint{"expr": 1, "term": 1}}
+	doc.dropSymbols()
+
+	var expected = map[string]uint{"expr": 2, "digit": 2}
+	if len(NonTerminalIndex) != len(expected) {
+		t.Fatalf("wrong completion index: %v", NonTerminalIndex)
+	}
+	for name, count := range expected {
+		if NonTerminalIndex[name] != count {
+			t.Errorf("wrong count of %s: %d", name, NonTerminalIndex[name])
+		}
+	}
+}
+
+func TestLineSymbols(t *testing.T) {
+	var backup = NonTerminalIndex
+	defer func() {
+		NonTerminalIndex = backup
+	}()
+
+	NonTerminalIndex = map[string]uint{"expr": 1}
+	var doc = &Document{}
+	doc.setLineSymbols(0, []string{"expr", "term"})
+	doc.setLineSymbols(1, []string{"term", "digit"})
+	doc.setLineSymbols(2, []string{"digit"})
+
+	// The first line is edited and the second one is removed.
+	doc.spliceSymbols(0, 2, 1)
+	doc.setLineSymbols(0, []string{"expr", "factor"})
+
+	var expected = map[string]uint{"expr": 2, "factor": 1, "digit": 1}
+	if len(NonTerminalIndex) != len(expected) {
+		t.Fatalf("wrong completion index: %v", NonTerminalIndex)
+	}
+	for name, count := range expected {
+		if NonTerminalIndex[name] != count {
+			t.Errorf("wrong count of %s: %d", name, NonTerminalIndex[name])
+		}
+	}
+
+	doc.dropSymbols()
+	if len(NonTerminalIndex) != 1 || NonTerminalIndex["expr"] != 1 {
+		t.Errorf("wrong completion index: %v", NonTerminalIndex)
+	}
+}
@@ -0,0 +1,223 @@
+package highlighting
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+	"github.com/daskol/nvim-bnf/pkg/i18n"
+	"github.com/neovim/go-client/nvim"
+)
+
+// renamedFile is content of a grammar file which is not opened after renaming.
+type renamedFile struct {
+	path  string
+	lines [][]byte
+	count int
+}
+
+// HandleRenameCommand renames non-terminal under cursor in all attached
+// buffers. With bang, grammar files under the current directory which match
+// file patterns and are not opened are renamed as well. They are written
+// directly to disk after confirmation.
+func (h *Highlighter) HandleRenameCommand(args []string, bang bool) error {
+	logger.Debugf("HandleRenameCommand(%v, %t)", args, bang)
+
+	var name = strings.TrimSuffix(strings.TrimPrefix(args[0], "<"), ">")
+	if name == "" {
+		return newError(CodeInvalidArgs, "name of rule is empty")
+	}
+
+	var buf, err = CurrentBuffer(h.nvim)
+	if err != nil {
+		return err
+	}
+
+	cursor, err := WindowCursor(h.nvim, 0)
+	if err != nil {
+		return err
+	}
+
+	lines, err := BufferLines(h.nvim, buf, 0, -1, true)
+	if err != nil {
+		return err
+	}
+
+	var symbol = SymbolAt(h.dialectOf(buf), lines, cursor[0]-1, cursor[1])
+	if symbol == "" {
+		return newError(CodeInvalidArgs, "there is no non-terminal at cursor")
+	} else if symbol == name {
+		return nil
+	}
+
+	var bufs = DocIndex.Buffers()
+	if _, ok := DocIndex.Get(buf); !ok {
+		bufs = append([]nvim.Buffer{buf}, bufs...)
+	}
+
+	var total, files = 0, 0
+	var opened = make(map[string]bool)
+	for _, buf := range bufs {
+		if filename := h.bufferName(buf); filename != "" {
+			if abs, err := filepath.Abs(filename); err == nil {
+				opened[abs] = true
+			}
+		}
+
+		var count, err = h.renameBuffer(buf, symbol, name)
+		if err != nil {
+			return err
+		} else if count > 0 {
+			total += count
+			files++
+		}
+	}
+
+	if bang {
+		var count, nofiles, err = h.renameFiles(opened, symbol, name)
+		if err != nil {
+			return err
+		}
+		total += count
+		files += nofiles
+	}
+
+	return WriteOut(h.nvim, fmt.Sprintf(i18n.T("Renamed %d occurrences "+
+		"in %d files.")+"\n", total, files))
+}
+
+// renameBuffer renames non-terminal in a buffer and returns number of renamed
+// occurrences. Only changed lines are replaced, so that marks and highlights
+// of the rest of the buffer are kept.
+func (h *Highlighter) renameBuffer(
+	buf nvim.Buffer, from, to string,
+) (int, error) {
+	var lines, err = BufferLines(h.nvim, buf, 0, -1, true)
+	if err != nil {
+		return 0, err
+	}
+
+	var renamed, count = analysis.Rename(h.dialectOf(buf), lines, from, to)
+	if count == 0 {
+		return 0, nil
+	}
+
+	var batch = h.nvim.NewBatch()
+	for idx, line := range renamed {
+		if !bytes.Equal(line, lines[idx]) {
+			batch.SetBufferLines(buf, idx, idx+1, true, [][]byte{line})
+		}
+	}
+	return count, execute(batch)
+}
+
+// renameFiles renames non-terminal in grammar files under the current
+// directory which are not opened. Files are written only if user confirms
+// it. It returns number of renamed occurrences and number of written files.
+func (h *Highlighter) renameFiles(
+	opened map[string]bool, from, to string,
+) (int, int, error) {
+	var cwd string
+	if err := CallFunction(h.nvim, "getcwd", &cwd); err != nil {
+		return 0, 0, err
+	}
+
+	var patterns []string
+	var expr = "get(g:, 'bnf_file_patterns', [])"
+	if err := Eval(h.nvim, expr, &patterns); err != nil {
+		logger.Warnf("failed to get g:bnf_file_patterns: %s", err)
+	}
+	patterns = MergePatterns(DefaultFilePatterns, patterns...)
+
+	var paths, err = grammarFiles(cwd, patterns)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var total int
+	var changes []renamedFile
+	for _, path := range paths {
+		if opened[path] {
+			continue
+		}
+
+		var file, err = Workspace.Load(path)
+		if err != nil {
+			logger.Warnf("failed to load %s: %s", path, err)
+			continue
+		}
+
+		var lines, count = analysis.Rename(file.Dialect, file.Lines, from, to)
+		if count > 0 {
+			changes = append(changes, renamedFile{path, lines, count})
+			total += count
+		}
+	}
+
+	if len(changes) == 0 {
+		return 0, 0, nil
+	}
+
+	var choice int
+	var msg = fmt.Sprintf(i18n.T("Rename %d occurrences in %d files which "+
+		"are not opened?"), total, len(changes))
+	err = Prompt(h.nvim, "confirm", &choice, msg, "&Yes\n&No", 2)
+	if err != nil {
+		return 0, 0, err
+	} else if choice != 1 {
+		return 0, 0, nil
+	}
+
+	for _, change := range changes {
+		var info, err = os.Stat(change.path)
+		if err != nil {
+			return 0, 0, err
+		}
+		var content = bytes.Join(change.lines, nl)
+		err = ioutil.WriteFile(change.path, content, info.Mode())
+		if err != nil {
+			return 0, 0, err
+		}
+		h.refreshIncluders(change.path)
+	}
+	return total, len(changes), nil
+}
+
+// grammarFiles returns absolute paths of files under a directory which names
+// match any of patterns. Hidden files and directories are skipped.
+func grammarFiles(root string, patterns []string) ([]string, error) {
+	var paths []string
+	var err = filepath.Walk(root, func(
+		path string, info os.FileInfo, err error,
+	) error {
+		if err != nil {
+			return err
+		}
+
+		var name = info.Name()
+		if path != root && strings.HasPrefix(name, ".") {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		} else if info.IsDir() {
+			return nil
+		}
+
+		for _, pattern := range patterns {
+			if ok, _ := filepath.Match(pattern, name); ok {
+				if abs, err := filepath.Abs(path); err == nil {
+					path = abs
+				}
+				paths = append(paths, path)
+				break
+			}
+		}
+		return nil
+	})
+	return paths, err
+}
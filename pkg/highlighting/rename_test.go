@@ -0,0 +1,42 @@
+package highlighting
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGrammarFiles(t *testing.T) {
+	var root, err = ioutil.TempDir("", "nvim-bnf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	for _, name := range []string{
+		"expr.bnf", "notes.txt", "sub/term.ebnf", ".git/hidden.bnf",
+	} {
+		var path = filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	paths, err := grammarFiles(root, DefaultFilePatterns)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var expected = []string{
+		filepath.Join(root, "expr.bnf"),
+		filepath.Join(root, "sub/term.ebnf"),
+	}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("wrong grammar files: %v", paths)
+	}
+}
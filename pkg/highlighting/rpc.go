@@ -26,6 +26,235 @@ func SetVirtualText(
 	b.Request("nvim_buf_set_virtual_text", result, args...)
 }
 
+// SetExtmark places extended mark to a buffer in batch mode. Options are
+// passed as is, so they could contain end position and highlight group.
+func SetExtmark(
+	b *nvim.Batch, buf *nvim.Buffer, nsID int, line, col int,
+	opts map[string]interface{}, result *int,
+) {
+	var args = []interface{}{buf, nsID, line, col, opts}
+	b.Request("nvim_buf_set_extmark", result, args...)
+}
+
+// SetDiagnostics publishes diagnostics of a buffer with vim.diagnostic.set()
+// in batch mode. It replaces diagnostics of the namespace which were set
+// before.
+func SetDiagnostics(
+	b *nvim.Batch, buf nvim.Buffer, nsID int, diags []VimDiagnostic,
+) {
+	var code = "vim.diagnostic.set(...)"
+	var args = []interface{}{nsID, int(buf), diags}
+	b.Request("nvim_exec_lua", nil, code, args)
+}
+
+// ResetDiagnostics removes diagnostics of a namespace in a buffer with
+// vim.diagnostic.reset() in batch mode.
+func ResetDiagnostics(b *nvim.Batch, buf nvim.Buffer, nsID int) {
+	var code = "vim.diagnostic.reset(...)"
+	var args = []interface{}{nsID, int(buf)}
+	b.Request("nvim_exec_lua", nil, code, args)
+}
+
+// CreateNamespace creates a new namespace or gets an existing one by its name.
+func CreateNamespace(v *nvim.Nvim, name string) (int, error) {
+	var nsID int
+	var err = request(v, true, "nvim_create_namespace", &nsID, name)
+	if err != nil {
+		return 0, err
+	}
+	return nsID, nil
+}
+
+// BufferContent requests lines of a buffer together with its changedtick in
+// batch mode, so that they are consistent.
+func BufferContent(
+	b *nvim.Batch, buf nvim.Buffer, lines *[][]byte, tick *int,
+) {
+	b.Request("nvim_buf_get_lines", lines, buf, 0, -1, true)
+	b.Request("nvim_buf_get_changedtick", tick, buf)
+}
+
+// Eval evaluates a Vim expression with call policy. Expressions which read
+// options are safe to repeat, so evaluation is retried on timeout.
+func Eval(v *nvim.Nvim, expr string, result interface{}) error {
+	return request(v, true, "nvim_eval", result, expr)
+}
+
+// CallFunction calls a Vim function which has no side effects with call
+// policy, so it is retried on timeout as well.
+func CallFunction(
+	v *nvim.Nvim, fname string, result interface{}, args ...interface{},
+) error {
+	if args == nil {
+		args = []interface{}{}
+	}
+	return request(v, true, "nvim_call_function", result, fname, args)
+}
+
+// BufferOption gets buffer-local option with call policy.
+func BufferOption(
+	v *nvim.Nvim, buf nvim.Buffer, name string, result interface{},
+) error {
+	return request(v, true, "nvim_buf_get_option", result, buf, name)
+}
+
+// BufferName gets full file name of a buffer with call policy.
+func BufferName(v *nvim.Nvim, buf nvim.Buffer) (string, error) {
+	var name string
+	var err = request(v, true, "nvim_buf_get_name", &name, buf)
+	return name, err
+}
+
+// SetBufferOption sets buffer-local option with call policy.
+func SetBufferOption(
+	v *nvim.Nvim, buf nvim.Buffer, name string, value interface{},
+) error {
+	return request(v, true, "nvim_buf_set_option", nil, buf, name, value)
+}
+
+// SetBufferVar sets buffer-local variable with call policy.
+func SetBufferVar(
+	v *nvim.Nvim, buf nvim.Buffer, name string, value interface{},
+) error {
+	return request(v, true, "nvim_buf_set_var", nil, buf, name, value)
+}
+
+// SetVar sets global variable with call policy.
+func SetVar(v *nvim.Nvim, name string, value interface{}) error {
+	return request(v, true, "nvim_set_var", nil, name, value)
+}
+
+// CurrentBuffer gets the current buffer with call policy.
+func CurrentBuffer(v *nvim.Nvim) (nvim.Buffer, error) {
+	var buf nvim.Buffer
+	var err = request(v, true, "nvim_get_current_buf", &buf)
+	return buf, err
+}
+
+// SetCurrentBuffer shows a buffer in the current window with call policy.
+func SetCurrentBuffer(v *nvim.Nvim, buf nvim.Buffer) error {
+	return request(v, true, "nvim_set_current_buf", nil, buf)
+}
+
+// CreateBuffer creates a new buffer with call policy. Request is not retried
+// since every attempt creates a buffer.
+func CreateBuffer(v *nvim.Nvim, listed, scratch bool) (nvim.Buffer, error) {
+	var buf nvim.Buffer
+	var err = request(v, false, "nvim_create_buf", &buf, listed, scratch)
+	return buf, err
+}
+
+// IsBufferValid reports whether a buffer still exists with call policy.
+func IsBufferValid(v *nvim.Nvim, buf nvim.Buffer) (bool, error) {
+	var valid bool
+	var err = request(v, true, "nvim_buf_is_valid", &valid, buf)
+	return valid, err
+}
+
+// BufferLines gets lines of a buffer in range with call policy.
+func BufferLines(
+	v *nvim.Nvim, buf nvim.Buffer, start, end int, strict bool,
+) ([][]byte, error) {
+	var lines [][]byte
+	var err = request(v, true, "nvim_buf_get_lines", &lines, buf, start, end,
+		strict)
+	return lines, err
+}
+
+// SetBufferLines replaces lines of a buffer in range with call policy. It is
+// not retried since replacement of an empty range inserts lines every time.
+func SetBufferLines(
+	v *nvim.Nvim, buf nvim.Buffer, start, end int, strict bool,
+	lines [][]byte,
+) error {
+	return request(v, false, "nvim_buf_set_lines", nil, buf, start, end,
+		strict, lines)
+}
+
+// ClearBufferHighlight removes highlights of a namespace in range of lines
+// with call policy.
+func ClearBufferHighlight(
+	v *nvim.Nvim, buf nvim.Buffer, nsID, start, end int,
+) error {
+	return request(v, true, "nvim_buf_clear_highlight", nil, buf, nsID,
+		start, end)
+}
+
+// CurrentWindow gets the current window with call policy.
+func CurrentWindow(v *nvim.Nvim) (nvim.Window, error) {
+	var win nvim.Window
+	var err = request(v, true, "nvim_get_current_win", &win)
+	return win, err
+}
+
+// WindowBuffer gets buffer of a window with call policy.
+func WindowBuffer(v *nvim.Nvim, win nvim.Window) (nvim.Buffer, error) {
+	var buf nvim.Buffer
+	var err = request(v, true, "nvim_win_get_buf", &buf, win)
+	return buf, err
+}
+
+// WindowCursor gets one-based row and zero-based column of cursor in a window
+// with call policy.
+func WindowCursor(v *nvim.Nvim, win nvim.Window) ([2]int, error) {
+	var pos [2]int
+	var err = request(v, true, "nvim_win_get_cursor", &pos, win)
+	return pos, err
+}
+
+// SetWindowCursor moves cursor in a window with call policy.
+func SetWindowCursor(v *nvim.Nvim, win nvim.Window, pos [2]int) error {
+	return request(v, true, "nvim_win_set_cursor", nil, win, pos)
+}
+
+// WindowOption gets window-local option with call policy.
+func WindowOption(
+	v *nvim.Nvim, win nvim.Window, name string, result interface{},
+) error {
+	return request(v, true, "nvim_win_get_option", result, win, name)
+}
+
+// SetWindowOption sets window-local option with call policy.
+func SetWindowOption(
+	v *nvim.Nvim, win nvim.Window, name string, value interface{},
+) error {
+	return request(v, true, "nvim_win_set_option", nil, win, name, value)
+}
+
+// Command executes an Ex command with call policy. Commands could have side
+// effects, so they are never retried.
+func Command(v *nvim.Nvim, cmd string) error {
+	return request(v, false, "nvim_command", nil, cmd)
+}
+
+// Call calls a Vim function which has side effects with call policy. It is
+// not retried unlike CallFunction.
+func Call(
+	v *nvim.Nvim, fname string, result interface{}, args ...interface{},
+) error {
+	if args == nil {
+		args = []interface{}{}
+	}
+	return request(v, false, "nvim_call_function", result, fname, args)
+}
+
+// Prompt calls a Vim function which waits for user input like input() or
+// confirm(). User answers as long as they want, so neither timeout nor retries
+// apply to it.
+func Prompt(
+	v *nvim.Nvim, fname string, result interface{}, args ...interface{},
+) error {
+	if args == nil {
+		args = []interface{}{}
+	}
+	return v.Request("nvim_call_function", result, fname, args)
+}
+
+// WriteOut writes a message to the message area with call policy.
+func WriteOut(v *nvim.Nvim, msg string) error {
+	return request(v, false, "nvim_out_write", nil, msg)
+}
+
 // AttachToBuffer attaches plugin to buffer's updates. This method is temporary
 // until it is supported in official Golang client.
 func AttachToBuffer(v *nvim.Nvim, buf *nvim.Buffer) error {
@@ -36,7 +265,8 @@ func AttachToBuffer(v *nvim.Nvim, buf *nvim.Buffer) error {
 		map[string]interface{}{},
 	}
 
-	if err := v.Request("nvim_buf_attach", &result, args...); err != nil {
+	var err = request(v, false, "nvim_buf_attach", &result, args...)
+	if err != nil {
 		return err
 	}
 
@@ -53,7 +283,8 @@ func DetachFromBuffer(v *nvim.Nvim, buf *nvim.Buffer) error {
 	var result bool
 	var args = []interface{}{buf}
 
-	if err := v.Request("nvim_buf_detach", &result, args...); err != nil {
+	var err = request(v, false, "nvim_buf_detach", &result, args...)
+	if err != nil {
 		return err
 	}
 
@@ -63,3 +294,35 @@ func DetachFromBuffer(v *nvim.Nvim, buf *nvim.Buffer) error {
 
 	return nil
 }
+
+// Log levels of notifications (see vim.log.levels).
+const (
+	NotifyInfo  = 2
+	NotifyWarn  = 3
+	NotifyError = 4
+)
+
+// Notify shows a message to user with vim.notify() which could be overridden
+// by notification plugins. This method is temporary until it is supported in
+// official Golang client.
+func Notify(v *nvim.Nvim, msg string, level int) error {
+	return request(v, false, "nvim_notify", nil, msg, level, NoOpts)
+}
+
+// OpenFloatingWindow shows a buffer in a floating window which is placed with
+// a config (see nvim_open_win). Window is not entered. This method is
+// temporary until it is supported in official Golang client.
+func OpenFloatingWindow(
+	v *nvim.Nvim, buf nvim.Buffer, config map[string]interface{},
+) (nvim.Window, error) {
+	var win nvim.Window
+	var err = request(v, false, "nvim_open_win", &win, buf, false,
+		config)
+	return win, err
+}
+
+// CloseWindow closes a window even if its buffer is modified. This method is
+// temporary until it is supported in official Golang client.
+func CloseWindow(v *nvim.Nvim, win nvim.Window) error {
+	return request(v, false, "nvim_win_close", nil, win, true)
+}
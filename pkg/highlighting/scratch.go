@@ -0,0 +1,38 @@
+package highlighting
+
+import (
+	"github.com/neovim/go-client/nvim"
+)
+
+// newScratchBuffer creates unlisted read-only buffer which is wiped out as
+// soon as it is hidden.
+func (h *Highlighter) newScratchBuffer(lines [][]byte) (nvim.Buffer, error) {
+	var buf, err = CreateBuffer(h.nvim, false, true)
+	if err != nil {
+		return buf, err
+	}
+
+	var batch = h.nvim.NewBatch()
+	batch.SetBufferLines(buf, 0, -1, true, lines)
+	batch.SetBufferOption(buf, "buftype", "nofile")
+	batch.SetBufferOption(buf, "bufhidden", "wipe")
+	batch.SetBufferOption(buf, "modifiable", false)
+	batch.SetBufferOption(buf, "readonly", true)
+	return buf, execute(batch)
+}
+
+// openWindow shows a buffer in a new window which is created with split
+// command (e.g. split or vsplit) and returns the window.
+func (h *Highlighter) openWindow(split string, buf nvim.Buffer) (
+	nvim.Window, error,
+) {
+	if err := Command(h.nvim, split); err != nil {
+		return 0, err
+	}
+
+	if err := SetCurrentBuffer(h.nvim, buf); err != nil {
+		return 0, err
+	}
+
+	return CurrentWindow(h.nvim)
+}
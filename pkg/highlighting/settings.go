@@ -0,0 +1,88 @@
+package highlighting
+
+import (
+	"fmt"
+)
+
+// settings are global options which are consulted on every change of a
+// buffer. They are cached, so that handling of buf_lines events never waits
+// for NeoVim. Cache is refreshed when NeoVim enters or when any g:bnf_*
+// variable changes, and :BNFHighlightToggle updates it directly.
+type settings struct {
+	enabled bool
+	limits  Limits
+}
+
+// readSettings reads options from NeoVim.
+func (h *Highlighter) readSettings() settings {
+	return settings{enabled: h.readEnabled(), limits: h.readLimits()}
+}
+
+// cachedSettings returns cached options. They are read from NeoVim once if
+// they are not cached yet.
+func (h *Highlighter) cachedSettings() settings {
+	h.settingsGuard.Lock()
+	defer h.settingsGuard.Unlock()
+	if h.settings == nil {
+		var settings = h.readSettings()
+		h.settings = &settings
+	}
+	return *h.settings
+}
+
+// refreshSettings reads options from NeoVim again and replaces cached ones.
+func (h *Highlighter) refreshSettings() {
+	var settings = h.readSettings()
+	h.settingsGuard.Lock()
+	h.settings = &settings
+	h.settingsGuard.Unlock()
+}
+
+// setEnabled updates cached value of g:bnf_enabled option.
+func (h *Highlighter) setEnabled(enabled bool) {
+	var settings = h.cachedSettings()
+	settings.enabled = enabled
+	h.settingsGuard.Lock()
+	h.settings = &settings
+	h.settingsGuard.Unlock()
+}
+
+// enabled reports whether plugin is enabled with g:bnf_enabled option. It is
+// enabled by default. Cached value is used.
+func (h *Highlighter) enabled() bool {
+	return h.cachedSettings().enabled
+}
+
+// readEnabled reads g:bnf_enabled option.
+func (h *Highlighter) readEnabled() bool {
+	var enabled = 1
+	if err := Eval(h.nvim, "get(g:, 'bnf_enabled', 1)", &enabled); err != nil {
+		logger.Warnf("failed to get g:bnf_enabled: %s", err)
+	}
+	return enabled != 0
+}
+
+// registerSettingsRefresh makes NeoVim notify plugin when it enters and when
+// g:bnf_* variables are changed, so that cached options are read again.
+// Options are read right away as well since they could be set before.
+func (h *Highlighter) registerSettingsRefresh() {
+	var notify = fmt.Sprintf("rpcnotify(%d, 'nvim_bnf_settings')",
+		h.nvim.ChannelID())
+	var cmds = []string{
+		"autocmd nvim-bnf VimEnter * call " + notify,
+		"call dictwatcheradd(g:, 'bnf_*', {dict, key, change -> " +
+			notify + "})",
+	}
+	for _, cmd := range cmds {
+		if err := Command(h.nvim, cmd); err != nil {
+			logger.Errorf("failed to register refresh of options: %s", err)
+		}
+	}
+	h.refreshSettings()
+}
+
+// HandleSettingsEvent reads cached options again.
+func (h *Highlighter) HandleSettingsEvent() {
+	logger.Debugf("HandleSettingsEvent()")
+	h.refreshSettings()
+}
@@ -0,0 +1,25 @@
+package highlighting
+
+import (
+	"testing"
+)
+
+func TestSettingsCache(t *testing.T) {
+	// Highlighter has no connection to NeoVim, so cached options are used.
+	var h = &Highlighter{
+		settings: &settings{enabled: true, limits: DefaultLimits},
+	}
+
+	if !h.enabled() {
+		t.Errorf("cached option is not used")
+	} else if limits := h.limits(); limits != DefaultLimits {
+		t.Errorf("wrong limits: %+v", limits)
+	}
+
+	h.setEnabled(false)
+	if h.enabled() {
+		t.Errorf("cached option is not updated")
+	} else if limits := h.limits(); limits != DefaultLimits {
+		t.Errorf("limits are lost on update: %+v", limits)
+	}
+}
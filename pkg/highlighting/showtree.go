@@ -0,0 +1,52 @@
+package highlighting
+
+import (
+	"bytes"
+
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+// HandleShowTreeCommand renders parse tree of a range of lines (the current
+// line by default) in a scratch split. The buffer is parsed as a whole, so
+// only statements which begin within the range are shown. Every node is
+// annotated with one-based positions where it begins and ends.
+func (h *Highlighter) HandleShowTreeCommand(lines []int) error {
+	logger.Debugf("HandleShowTreeCommand(%v)", lines)
+
+	if len(lines) != 2 || lines[0] < 1 || lines[1] < lines[0] {
+		return newError(CodeInvalidArgs, "range of lines is expected")
+	}
+
+	var buf, err = CurrentBuffer(h.nvim)
+	if err != nil {
+		return err
+	}
+
+	content, err := BufferLines(h.nvim, buf, 0, -1, true)
+	if err != nil {
+		return err
+	}
+
+	var source, _ = parser.JoinLines(content)
+	ast, err := parser.ParseDialect(h.dialectOf(buf), source)
+	if err != nil {
+		return err
+	}
+
+	var dump bytes.Buffer
+	if err := ast.DumpLines(&dump, lines[0]-1, lines[1]-1); err != nil {
+		return err
+	}
+	if err := ast.Error(); err != nil {
+		dump.WriteString("\n" + err.Error() + "\n")
+	}
+
+	var tree = bytes.Split(bytes.TrimSuffix(dump.Bytes(), nl), nl)
+	view, err := h.newScratchBuffer(tree)
+	if err != nil {
+		return err
+	}
+
+	_, err = h.openWindow("split", view)
+	return err
+}
@@ -0,0 +1,79 @@
+package highlighting
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+)
+
+// HandleVimLeaveEvent detaches buffers and persists caches before NeoVim
+// exits. The handler is called with rpcrequest, so NeoVim waits for it while
+// channel is still open.
+func (h *Highlighter) HandleVimLeaveEvent() error {
+	logger.Debugf("HandleVimLeaveEvent()")
+
+	atomic.StoreInt32(&h.stopping, 1)
+	for _, buf := range DocIndex.Buffers() {
+		var buf = buf
+		if err := DetachFromBuffer(h.nvim, &buf); err != nil {
+			logger.Warnf("failed to detach buffer %s: %s", buf, err)
+		}
+	}
+
+	return h.flush()
+}
+
+// registerVimLeave defines autocommand which calls HandleVimLeaveEvent.
+func (h *Highlighter) registerVimLeave() {
+	var cmd = fmt.Sprintf("autocmd nvim-bnf VimLeavePre * "+
+		"call rpcrequest(%d, 'nvim_bnf_vim_leave')", h.nvim.ChannelID())
+	if err := Command(h.nvim, cmd); err != nil {
+		logger.Errorf("failed to register VimLeavePre: %s", err)
+	}
+}
+
+// stopped reports whether plugin is shutting down. Pending highlighting is
+// cancelled in this case since NeoVim is not going to show it.
+func (h *Highlighter) stopped() bool {
+	return atomic.LoadInt32(&h.stopping) != 0
+}
+
+// flush persists caches which are kept in memory.
+func (h *Highlighter) flush() error {
+	if h.history == nil {
+		return nil
+	}
+	return h.history.Save()
+}
+
+// shutdown releases resources after channel to NeoVim is closed. Unlike
+// HandleVimLeaveEvent, it is not possible to make RPC calls here.
+func (h *Highlighter) shutdown() {
+	atomic.StoreInt32(&h.stopping, 1)
+	// Pipeline is not started after this point, so it is safe to read it.
+	h.pipelineOnce.Do(func() {})
+	if h.workers != nil {
+		h.workers.Close()
+	}
+	if err := h.flush(); err != nil {
+		logger.Errorf("failed to flush caches: %s", err)
+	}
+	DocIndex.Reset()
+	logger.Infof("plugin was shut down")
+}
+
+// closeOnSignal closes channel to NeoVim on termination signals, so serving
+// stops and plugin shuts down gracefully rather than abruptly.
+func (h *Highlighter) closeOnSignal() {
+	var signals = make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	go func() {
+		var sig = <-signals
+		logger.Infof("signal %s was received", sig)
+		if err := h.nvim.Close(); err != nil {
+			logger.Errorf("failed to close channel: %s", err)
+		}
+	}()
+}
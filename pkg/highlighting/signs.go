@@ -0,0 +1,60 @@
+package highlighting
+
+import (
+	"github.com/daskol/nvim-bnf/pkg/parser"
+	"github.com/neovim/go-client/nvim"
+)
+
+// Sign is a marker in sign column of a line with diagnostics. Priority of
+// errors is higher, so an error sign wins if a line has both.
+type Sign struct {
+	Text     string
+	Group    string
+	Priority int
+}
+
+// SignOf returns marker of diagnostic of given severity.
+func SignOf(severity parser.Severity) Sign {
+	switch severity {
+	case parser.SeverityError:
+		return Sign{Text: "E>", Group: "BnfErrorSign", Priority: 20}
+	case parser.SeverityWarning:
+		return Sign{Text: "W>", Group: "BnfWarningSign", Priority: 15}
+	default:
+		return Sign{Text: "I>", Group: "BnfInfoSign", Priority: 10}
+	}
+}
+
+// placeSign marks a line in sign column, so diagnostics are visible even if
+// virtual text is truncated. It does nothing unless signs are enabled and
+// supported.
+func (d *Document) placeSign(
+	batch *nvim.Batch, buf nvim.Buffer, ns, row int, severity parser.Severity,
+) {
+	if !d.signs {
+		return
+	}
+
+	var res int
+	d.caps.SetSign(batch, buf, ns, row, SignOf(severity), &res)
+}
+
+// markFailure marks a line of line-oriented dialect which could not be parsed
+// at all. Previous marker of the line is removed in any case.
+func (d *Document) markFailure(batch *nvim.Batch, buf nvim.Buffer, row int) {
+	if d.Dialect().Multiline() || d.diagnostics != 0 {
+		return
+	}
+	batch.ClearBufferHighlight(buf, d.namespace, row, row+1)
+	d.placeSign(batch, buf, d.namespace, row, parser.SeverityError)
+}
+
+// signsEnabled reports whether lines with diagnostics are marked in sign
+// column. They are disabled with g:bnf_signs option.
+func (h *Highlighter) signsEnabled() bool {
+	var enabled = 1
+	if err := Eval(h.nvim, "get(g:, 'bnf_signs', 1)", &enabled); err != nil {
+		logger.Warnf("failed to get g:bnf_signs: %s", err)
+	}
+	return enabled != 0
+}
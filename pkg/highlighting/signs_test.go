@@ -0,0 +1,21 @@
+package highlighting
+
+import (
+	"testing"
+
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+func TestSignOf(t *testing.T) {
+	var err = SignOf(parser.SeverityError)
+	var warn = SignOf(parser.SeverityWarning)
+	if err.Text != "E>" || err.Group != "BnfErrorSign" {
+		t.Errorf("wrong error sign: %+v", err)
+	}
+	if warn.Text != "W>" || warn.Group != "BnfWarningSign" {
+		t.Errorf("wrong warning sign: %+v", warn)
+	}
+	if err.Priority <= warn.Priority {
+		t.Errorf("error sign should take precedence over warning one")
+	}
+}
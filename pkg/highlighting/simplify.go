@@ -0,0 +1,76 @@
+package highlighting
+
+import (
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+// HandleSimplifyCommand inlines rules of the current buffer which are
+// referenced once and removes identity rules. Without bang it only previews
+// difference in a scratch split while with bang it applies changes to the
+// buffer.
+func (h *Highlighter) HandleSimplifyCommand(bang bool) error {
+	logger.Debugf("HandleSimplifyCommand(%t)", bang)
+	return h.transform(analysis.Simplify, bang)
+}
+
+// HandleLeftFactorCommand factors out common prefixes of alternatives of rules
+// of the current buffer. Likewise :BNFSimplify, it previews changes unless
+// bang is set.
+func (h *Highlighter) HandleLeftFactorCommand(bang bool) error {
+	logger.Debugf("HandleLeftFactorCommand(%t)", bang)
+	return h.transform(analysis.LeftFactor, bang)
+}
+
+// transform applies transformation to grammar of the current buffer in
+// classic BNF or previews difference in a scratch split.
+func (h *Highlighter) transform(
+	transform func([][]byte) [][]byte, bang bool,
+) error {
+	var buf, err = CurrentBuffer(h.nvim)
+	if err != nil {
+		return err
+	}
+
+	if dialect := h.dialectOf(buf); dialect != parser.DialectBNF {
+		return newError(CodeUnknownDialect, "dialect "+string(dialect)+
+			" could not be transformed")
+	}
+
+	lines, err := BufferLines(h.nvim, buf, 0, -1, true)
+	if err != nil {
+		return err
+	}
+
+	var transformed = transform(lines)
+	if bang {
+		return SetBufferLines(h.nvim, buf, 0, -1, true, transformed)
+	}
+
+	var diff = analysis.DiffLines(stringLines(lines),
+		stringLines(transformed))
+	var preview = make([][]byte, len(diff))
+	for idx, line := range diff {
+		preview[idx] = []byte(line)
+	}
+
+	view, err := h.newScratchBuffer(preview)
+	if err != nil {
+		return err
+	}
+
+	if err := SetBufferOption(h.nvim, view, "filetype", "diff"); err != nil {
+		return err
+	}
+
+	_, err = h.openWindow("split", view)
+	return err
+}
+
+func stringLines(lines [][]byte) []string {
+	var strs = make([]string, len(lines))
+	for idx, line := range lines {
+		strs[idx] = string(line)
+	}
+	return strs
+}
@@ -0,0 +1,40 @@
+package highlighting
+
+import (
+	"bytes"
+
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+)
+
+// HandleSortRulesCommand reorders rules of the current buffer alphabetically
+// (alpha, default), so definitions precede their uses (topo), or by number of
+// references (refs). Buffer is rewritten with a single call, so the change is
+// undone at once.
+func (h *Highlighter) HandleSortRulesCommand(args []string) error {
+	logger.Debugf("HandleSortRulesCommand(%v)", args)
+
+	var mode = analysis.SortAlphabetical
+	if len(args) > 0 {
+		mode = analysis.SortMode(args[0])
+	}
+
+	var buf, err = CurrentBuffer(h.nvim)
+	if err != nil {
+		return err
+	}
+
+	lines, err := BufferLines(h.nvim, buf, 0, -1, true)
+	if err != nil {
+		return err
+	}
+
+	sorted, err := analysis.SortRules(h.dialectOf(buf), lines, mode)
+	if err != nil {
+		return newError(CodeInvalidArgs, "unknown sort mode "+string(mode))
+	}
+
+	if bytes.Equal(bytes.Join(lines, nl), bytes.Join(sorted, nl)) {
+		return nil
+	}
+	return SetBufferLines(h.nvim, buf, 0, -1, true, sorted)
+}
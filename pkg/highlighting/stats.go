@@ -0,0 +1,110 @@
+package highlighting
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/daskol/nvim-bnf/pkg/i18n"
+)
+
+// histogramBounds are upper bounds of buckets of Histogram. The last bucket
+// has no bound.
+var histogramBounds = []time.Duration{
+	10 * time.Microsecond,
+	30 * time.Microsecond,
+	100 * time.Microsecond,
+	300 * time.Microsecond,
+	time.Millisecond,
+	3 * time.Millisecond,
+	10 * time.Millisecond,
+	30 * time.Millisecond,
+	100 * time.Millisecond,
+}
+
+// Histogram counts durations in buckets of exponentially growing width. It is
+// cheap enough to be updated on every parsing of a line.
+type Histogram struct {
+	guard  sync.Mutex
+	counts [10]uint64
+	total  time.Duration
+}
+
+// Observe adds duration to the histogram.
+func (h *Histogram) Observe(elapsed time.Duration) {
+	var idx = len(histogramBounds)
+	for i, bound := range histogramBounds {
+		if elapsed < bound {
+			idx = i
+			break
+		}
+	}
+
+	h.guard.Lock()
+	defer h.guard.Unlock()
+	h.counts[idx]++
+	h.total += elapsed
+}
+
+// Snapshot returns counts of buckets and total duration of all samples.
+func (h *Histogram) Snapshot() ([]uint64, time.Duration) {
+	h.guard.Lock()
+	defer h.guard.Unlock()
+	var counts = make([]uint64, len(h.counts))
+	copy(counts, h.counts[:])
+	return counts, h.total
+}
+
+// String renders histogram as a table with a bar per bucket.
+func (h *Histogram) String() string {
+	var counts, total = h.Snapshot()
+	var sum, max uint64
+	for _, count := range counts {
+		sum += count
+		if count > max {
+			max = count
+		}
+	}
+
+	if sum == 0 {
+		return i18n.Sprintf("There are no samples.") + "\n"
+	}
+
+	var builder strings.Builder
+	for idx, count := range counts {
+		var label = "≥ " + histogramBounds[len(histogramBounds)-1].String()
+		if idx < len(histogramBounds) {
+			label = "< " + histogramBounds[idx].String()
+		}
+		var bar = strings.Repeat("#", int(40*count/max))
+		fmt.Fprintf(&builder, "%8s %8d %s\n", label, count, bar)
+	}
+	builder.WriteString(i18n.Sprintf("%d samples took %s on average", sum,
+		total/time.Duration(sum)))
+	builder.WriteString("\n")
+	return builder.String()
+}
+
+// parseTimings is a histogram of durations of parsing of lines and documents.
+var parseTimings Histogram
+
+// HandleStatsCommand shows histogram of parsing durations and timings of
+// recent highlights.
+func (h *Highlighter) HandleStatsCommand() error {
+	logger.Debugf("HandleStatsCommand()")
+
+	var builder strings.Builder
+	builder.WriteString(i18n.Sprintf("Parsing") + "\n")
+	builder.WriteString(parseTimings.String())
+
+	if count, mean, max := h.timings.Stats(); count > 0 {
+		builder.WriteString(i18n.Sprintf("Highlighting") + "\n")
+		builder.WriteString(i18n.Sprintf(
+			"%d recent highlights took %s on average and %s at most",
+			count, mean, max))
+		builder.WriteString("\n")
+	}
+
+	return WriteOut(h.nvim, builder.String())
+}
@@ -0,0 +1,31 @@
+package highlighting
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHistogram(t *testing.T) {
+	var hist Histogram
+	if str := hist.String(); !strings.Contains(str, "no samples") {
+		t.Errorf("empty histogram is rendered wrong: %s", str)
+	}
+
+	hist.Observe(5 * time.Microsecond)
+	hist.Observe(10 * time.Microsecond)
+	hist.Observe(2 * time.Millisecond)
+	hist.Observe(time.Second)
+
+	var counts, total = hist.Snapshot()
+	var expected = []uint64{1, 1, 0, 0, 0, 1, 0, 0, 0, 1}
+	for idx, count := range counts {
+		if count != expected[idx] {
+			t.Errorf("wrong count of bucket #%d: %d", idx, count)
+		}
+	}
+
+	if total != time.Second+2*time.Millisecond+15*time.Microsecond {
+		t.Errorf("wrong total: %s", total)
+	}
+}
@@ -0,0 +1,127 @@
+package highlighting
+
+import (
+	"fmt"
+
+	"github.com/daskol/nvim-bnf/pkg/i18n"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+	"github.com/neovim/go-client/nvim"
+)
+
+// Status is a summary of a buffer which is embedded into statusline. Text is
+// a compact rendering of the rest fields like `bnf 12 rules E:1 W:2`. It is
+// empty if buffer is not attached.
+type Status struct {
+	Attached bool   `msgpack:"attached"`
+	Dialect  string `msgpack:"dialect"`
+	Rules    int    `msgpack:"rules"`
+	Errors   int    `msgpack:"errors"`
+	Warnings int    `msgpack:"warnings"`
+	Text     string `msgpack:"text"`
+}
+
+// formatStatus renders summary of a buffer. Counters of diagnostics are
+// omitted if they are zero.
+func formatStatus(status Status) string {
+	if !status.Attached {
+		return ""
+	}
+
+	var rules = i18n.Sprintf("%d rules", status.Rules)
+	if status.Rules == 1 {
+		rules = i18n.T("1 rule")
+	}
+
+	var text = status.Dialect + " " + rules
+	if status.Errors > 0 {
+		text += fmt.Sprintf(" E:%d", status.Errors)
+	}
+	if status.Warnings > 0 {
+		text += fmt.Sprintf(" W:%d", status.Warnings)
+	}
+	return text
+}
+
+// summary returns status of document. It is computed on the first request
+// after document is changed, so statusline does not trigger analysis on every
+// redraw. Document should be locked.
+func (d *Document) summary() Status {
+	if d.status != nil {
+		return *d.status
+	}
+
+	var grammar = d.grammar()
+	var status = Status{
+		Attached: true,
+		Dialect:  string(d.Dialect()),
+		Rules:    len(grammar.Rules),
+	}
+
+	var count = func(diags []parser.Diagnostic) {
+		for _, diag := range diags {
+			switch diag.Severity {
+			case parser.SeverityError:
+				status.Errors++
+			case parser.SeverityWarning:
+				status.Warnings++
+			}
+		}
+	}
+
+	if d.Dialect().Multiline() {
+		var diags, _ = d.documentDiagnostics()
+		count(diags)
+	} else {
+		for _, line := range d.Lines {
+			if ast, err := d.parse(line); err != nil {
+				status.Errors++
+			} else {
+				count(parser.Diagnostics(ast))
+			}
+		}
+	}
+
+	for _, finding := range d.findings() {
+		count([]parser.Diagnostic{finding.Diagnostic})
+	}
+
+	status.Text = formatStatus(status)
+	d.status = &status
+	return status
+}
+
+// HandleStatus returns summary of a buffer for statusline. Optional argument
+// is a buffer number where zero stands for the current buffer, so it could be
+// used as `%{BNFStatus().text}`.
+func (h *Highlighter) HandleStatus(args []interface{}) (Status, error) {
+	logger.Debugf("HandleStatus(%v)", args)
+
+	var buf nvim.Buffer
+	switch len(args) {
+	case 0:
+	case 1:
+		var bufnr, ok = args[0].(int64)
+		if !ok {
+			return Status{}, newError(CodeInvalidArgs, "wrong argument type")
+		}
+		buf = nvim.Buffer(bufnr)
+	default:
+		return Status{}, newError(CodeInvalidArgs, "too many arguments")
+	}
+
+	if buf == 0 {
+		var err error
+		if buf, err = CurrentBuffer(h.nvim); err != nil {
+			return Status{}, err
+		}
+	}
+
+	var doc, ok = DocIndex.Get(buf)
+	if !ok {
+		return Status{}, nil
+	}
+
+	doc.Lock()
+	defer doc.Unlock()
+	return doc.summary(), nil
+}
@@ -0,0 +1,46 @@
+package highlighting
+
+import (
+	"testing"
+
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+func TestDocumentSummary(t *testing.T) {
+	var doc = &Document{
+		dialect: parser.DialectBNF,
+		Lines: [][]byte{
+			[]byte(`<s> ::= <a> <c> "x"`),
+			[]byte(`<a> ::= "a"`),
+			[]byte(`<b> ::= "b"`),
+			[]byte(`<c> ::= "c" $`),
+		},
+	}
+
+	var status = doc.summary()
+	if !status.Attached || status.Dialect != "bnf" || status.Rules != 3 {
+		t.Errorf("wrong summary: %+v", status)
+	}
+	if status.Errors != 1 || status.Warnings != 2 {
+		t.Errorf("wrong number of diagnostics: %+v", status)
+	}
+	if status.Text != "bnf 3 rules E:1 W:2" {
+		t.Errorf("wrong text: %q", status.Text)
+	}
+
+	// Summary is cached until document is changed.
+	doc.Update([][]byte{[]byte(`<c> ::= "c"`)}, 3, 4)
+	if status = doc.summary(); status.Rules != 4 || status.Errors != 0 {
+		t.Errorf("summary is not refreshed: %+v", status)
+	}
+}
+
+func TestFormatStatus(t *testing.T) {
+	var status = Status{Attached: true, Dialect: "ebnf", Rules: 1}
+	if text := formatStatus(status); text != "ebnf 1 rule" {
+		t.Errorf("wrong text: %q", text)
+	}
+	if text := formatStatus(Status{}); text != "" {
+		t.Errorf("detached buffer has status: %q", text)
+	}
+}
@@ -0,0 +1,129 @@
+package highlighting
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+	"github.com/neovim/go-client/nvim"
+)
+
+// Symbol is a definition of non-terminal in some buffer. Fields are named
+// after items of quickfix list, so symbols could be passed to setqflist()
+// as is. Line and column are one-based.
+type Symbol struct {
+	Name     string `msgpack:"name"`
+	Buffer   int    `msgpack:"bufnr"`
+	Filename string `msgpack:"filename"`
+	Line     int    `msgpack:"lnum"`
+	Col      int    `msgpack:"col"`
+	Text     string `msgpack:"text"`
+}
+
+// DocumentSymbols returns definitions of non-terminals in lines of a buffer.
+func DocumentSymbols(
+	buf nvim.Buffer, filename string, dialect parser.Dialect, lines [][]byte,
+) []Symbol {
+	var grammar = analysis.NewGrammar(dialect, lines)
+	var symbols = make([]Symbol, 0, len(grammar.Rules))
+	for _, rule := range grammar.Rules {
+		var row, col = rulePosition(dialect, lines, rule)
+		symbols = append(symbols, Symbol{
+			Name:     rule.Name,
+			Buffer:   int(buf),
+			Filename: filename,
+			Line:     row + 1,
+			Col:      col + 1,
+			Text:     "<" + rule.Name + ">",
+		})
+	}
+	return symbols
+}
+
+// FilterSymbols returns symbols which names contain a query regardless of
+// case. Symbols are ordered by names and then by their locations.
+func FilterSymbols(symbols []Symbol, query string) []Symbol {
+	var filtered = []Symbol{}
+	query = strings.ToLower(query)
+	for _, symbol := range symbols {
+		if strings.Contains(strings.ToLower(symbol.Name), query) {
+			filtered = append(filtered, symbol)
+		}
+	}
+
+	sort.SliceStable(filtered, func(i, j int) bool {
+		var a, b = filtered[i], filtered[j]
+		switch {
+		case a.Name != b.Name:
+			return a.Name < b.Name
+		case a.Filename != b.Filename:
+			return a.Filename < b.Filename
+		default:
+			return a.Line < b.Line
+		}
+	})
+	return filtered
+}
+
+// workspaceSymbols collects definitions of non-terminals in all attached
+// buffers.
+func (h *Highlighter) workspaceSymbols() []Symbol {
+	var symbols []Symbol
+	for _, buf := range DocIndex.Buffers() {
+		var doc, ok = DocIndex.Get(buf)
+		if !ok {
+			continue
+		}
+
+		doc.Lock()
+		symbols = append(symbols, DocumentSymbols(buf, doc.filename,
+			doc.Dialect(), doc.Lines)...)
+		doc.Unlock()
+	}
+	return symbols
+}
+
+// HandleWorkspaceSymbols returns definitions of non-terminals in all attached
+// buffers. Optional argument is a query which names of non-terminals should
+// contain.
+func (h *Highlighter) HandleWorkspaceSymbols(args []interface{}) (
+	[]Symbol, error,
+) {
+	logger.Debugf("HandleWorkspaceSymbols(%v)", args)
+
+	var query string
+	switch len(args) {
+	case 0:
+	case 1:
+		var ok bool
+		if query, ok = args[0].(string); !ok {
+			return nil, newError(CodeInvalidArgs, "wrong argument type")
+		}
+	default:
+		return nil, newError(CodeInvalidArgs, "too many arguments")
+	}
+
+	return FilterSymbols(h.workspaceSymbols(), query), nil
+}
+
+// gotoSymbol moves cursor to definition of non-terminal in another attached
+// buffer. It reports whether there is such definition.
+func (h *Highlighter) gotoSymbol(current nvim.Buffer, name string) (
+	bool, error,
+) {
+	for _, symbol := range FilterSymbols(h.workspaceSymbols(), name) {
+		if symbol.Name != name || nvim.Buffer(symbol.Buffer) == current {
+			continue
+		}
+
+		var buf = nvim.Buffer(symbol.Buffer)
+		if err := SetCurrentBuffer(h.nvim, buf); err != nil {
+			return false, err
+		}
+
+		var pos = [2]int{symbol.Line, symbol.Col - 1}
+		return true, SetWindowCursor(h.nvim, nvim.Window(0), pos)
+	}
+	return false, nil
+}
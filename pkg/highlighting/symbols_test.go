@@ -0,0 +1,36 @@
+package highlighting
+
+import (
+	"testing"
+
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+func TestWorkspaceSymbols(t *testing.T) {
+	var symbols = DocumentSymbols(1, "expr.bnf", parser.DialectBNF, [][]byte{
+		[]byte(`<expr> ::= <term> | <expr> "+" <term>`),
+		[]byte(`  <Term> ::= <factor>`),
+	})
+	symbols = append(symbols, DocumentSymbols(2, "factor.ebnf",
+		parser.DialectEBNF, [][]byte{
+			[]byte(`factor`),
+			[]byte(`  = "x" ;`),
+		})...)
+
+	var filtered = FilterSymbols(symbols, "T")
+	if len(filtered) != 2 {
+		t.Fatalf("wrong number of symbols: %d", len(filtered))
+	}
+
+	var term = filtered[0]
+	if term.Name != "Term" || term.Buffer != 1 || term.Line != 2 ||
+		term.Col != 3 {
+		t.Errorf("wrong symbol: %+v", term)
+	}
+
+	var factor = filtered[1]
+	if factor.Name != "factor" || factor.Filename != "factor.ebnf" ||
+		factor.Line != 1 || factor.Col != 1 {
+		t.Errorf("wrong symbol: %+v", factor)
+	}
+}
@@ -0,0 +1,161 @@
+package highlighting
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/neovim/go-client/nvim"
+)
+
+// errTimeout is returned if NeoVim does not respond to an RPC call in time.
+var errTimeout = newError(CodeTimeout, "request to NeoVim timed out")
+
+// CallPolicy limits duration of RPC calls to NeoVim, so that a hung response
+// does not block handling of events forever. Zero timeout disables limit.
+// Calls which are safe to repeat are retried at most Retries times on
+// timeout. Calls which take longer than Slow are logged.
+type CallPolicy struct {
+	Timeout time.Duration
+	Retries int
+	Slow    time.Duration
+}
+
+// DefaultCallPolicy is used unless g:bnf_rpc_timeout or g:bnf_rpc_retries
+// are set.
+var DefaultCallPolicy = CallPolicy{
+	Timeout: 5 * time.Second,
+	Retries: 2,
+	Slow:    200 * time.Millisecond,
+}
+
+// callPolicy is policy of all RPC calls of plugin. It is replaced when
+// options are read.
+var callPolicy = struct {
+	sync.RWMutex
+	CallPolicy
+}{CallPolicy: DefaultCallPolicy}
+
+func currentPolicy() CallPolicy {
+	callPolicy.RLock()
+	defer callPolicy.RUnlock()
+	return callPolicy.CallPolicy
+}
+
+func setPolicy(policy CallPolicy) {
+	callPolicy.Lock()
+	defer callPolicy.Unlock()
+	callPolicy.CallPolicy = policy
+}
+
+// Run runs a call once. It returns errTimeout if call does not finish in time
+// while call itself is left running in background.
+func (p CallPolicy) Run(name string, call func() error) error {
+	var start = time.Now()
+	defer func() {
+		if elapsed := time.Since(start); p.Slow > 0 && elapsed > p.Slow {
+			logger.Warnf("slow call %s took %s", name, elapsed)
+		}
+	}()
+
+	if p.Timeout <= 0 {
+		return call()
+	}
+
+	var done = make(chan error, 1)
+	go func() {
+		done <- call()
+	}()
+
+	var timer = time.NewTimer(p.Timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		logger.Errorf("call %s timed out after %s", name, p.Timeout)
+		return errTimeout
+	}
+}
+
+// Retry runs a call which is safe to repeat and runs it again on timeout at
+// most Retries times.
+func (p CallPolicy) Retry(name string, call func() error) error {
+	var err error
+	for attempt := 0; attempt <= p.Retries; attempt++ {
+		if err = p.Run(name, call); err != errTimeout {
+			return err
+		}
+	}
+	return err
+}
+
+// execute sends batch RPC call with call policy. Batches are never retried
+// since they could be applied partially.
+func execute(batch *nvim.Batch) error {
+	return currentPolicy().Run("batch", batch.Execute)
+}
+
+// request sends RPC request with call policy. Request is retried on timeout
+// if it is idempotent. Result is decoded into a fresh value on every attempt,
+// so that responses of abandoned attempts never touch it.
+func request(
+	v *nvim.Nvim, idempotent bool, method string, result interface{},
+	args ...interface{},
+) error {
+	var guard sync.Mutex
+	var settled bool
+	var call = func() error {
+		if result == nil {
+			return v.Request(method, nil, args...)
+		}
+
+		var value = reflect.New(reflect.TypeOf(result).Elem())
+		if err := v.Request(method, value.Interface(), args...); err != nil {
+			return err
+		}
+
+		guard.Lock()
+		defer guard.Unlock()
+		if !settled {
+			settled = true
+			reflect.ValueOf(result).Elem().Set(value.Elem())
+		}
+		return nil
+	}
+
+	var policy = currentPolicy()
+	var err error
+	if idempotent {
+		err = policy.Retry(method, call)
+	} else {
+		err = policy.Run(method, call)
+	}
+
+	// Result is settled either by a successful attempt or by nobody.
+	guard.Lock()
+	settled = true
+	guard.Unlock()
+	return err
+}
+
+// setupCallPolicy reads timeout of RPC calls in milliseconds and number of
+// retries from g:bnf_rpc_timeout and g:bnf_rpc_retries options.
+func (h *Highlighter) setupCallPolicy() {
+	var policy = DefaultCallPolicy
+	var timeout, retries = -1, -1
+	var expr = "get(g:, 'bnf_rpc_timeout', -1)"
+	if err := Eval(h.nvim, expr, &timeout); err != nil {
+		logger.Warnf("failed to get g:bnf_rpc_timeout: %s", err)
+	} else if timeout >= 0 {
+		policy.Timeout = time.Duration(timeout) * time.Millisecond
+	}
+
+	expr = "get(g:, 'bnf_rpc_retries', -1)"
+	if err := Eval(h.nvim, expr, &retries); err != nil {
+		logger.Warnf("failed to get g:bnf_rpc_retries: %s", err)
+	} else if retries >= 0 {
+		policy.Retries = retries
+	}
+	setPolicy(policy)
+}
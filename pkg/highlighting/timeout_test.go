@@ -0,0 +1,60 @@
+package highlighting
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCallPolicyRun(t *testing.T) {
+	var policy = CallPolicy{Timeout: 10 * time.Millisecond}
+	var hang = make(chan struct{})
+	defer close(hang)
+
+	var err = policy.Run("hang", func() error {
+		<-hang
+		return nil
+	})
+	if err != errTimeout {
+		t.Errorf("hung call is not timed out: %v", err)
+	}
+
+	var failure = errors.New("failure")
+	err = policy.Run("fail", func() error { return failure })
+	if err != failure {
+		t.Errorf("wrong error of call: %v", err)
+	}
+
+	policy.Timeout = 0
+	if err := policy.Run("ok", func() error { return nil }); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestCallPolicyRetry(t *testing.T) {
+	var policy = CallPolicy{Timeout: 10 * time.Millisecond, Retries: 2}
+	var hang = make(chan struct{})
+	defer close(hang)
+
+	var attempts int32
+	var err = policy.Retry("flaky", func() error {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			<-hang
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("call is not retried: %v", err)
+	} else if n := atomic.LoadInt32(&attempts); n != 3 {
+		t.Errorf("wrong number of attempts: %d", n)
+	}
+
+	policy.Retries = 1
+	if err := policy.Retry("hang", func() error {
+		<-hang
+		return nil
+	}); err != errTimeout {
+		t.Errorf("retries are not bounded: %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+package highlighting
+
+import (
+	"context"
+
+	"github.com/neovim/go-client/nvim"
+)
+
+// HandleHighlightToggleCommand enables or disables plugin by flipping
+// g:bnf_enabled option. Highlights of attached buffers are removed when plugin
+// is disabled and they are restored when it is enabled again.
+func (h *Highlighter) HandleHighlightToggleCommand() error {
+	logger.Debugf("HandleHighlightToggleCommand()")
+
+	if err := h.setupNamespace(); err != nil {
+		return err
+	}
+
+	var enabled = !h.enabled()
+	var value = 0
+	if enabled {
+		value = 1
+	}
+
+	if err := SetVar(h.nvim, "bnf_enabled", value); err != nil {
+		return err
+	}
+	h.setEnabled(enabled)
+
+	for _, buf := range DocIndex.Buffers() {
+		var doc, ok = DocIndex.Get(buf)
+		if !ok {
+			continue
+		}
+
+		doc.Lock()
+		if enabled {
+			doc.Hightlight(context.Background(), h.nvim, buf)
+		}
+		doc.Unlock()
+
+		if !enabled {
+			if err := h.clearBuffer(buf); err != nil {
+				return err
+			}
+		}
+	}
+
+	logger.Infof("plugin was toggled: enabled=%t", enabled)
+	return nil
+}
+
+// HandleHighlightCommand parses and highlights the current buffer entirely.
+// Options which are captured when buffer is attached, e.g. g:bnf_explain or
+// dialect, are read again. It is the only way to highlight buffers which are
+// handled in lightweight mode.
+func (h *Highlighter) HandleHighlightCommand() error {
+	logger.Debugf("HandleHighlightCommand()")
+
+	var buf, doc, err = h.currentDocument()
+	if err != nil {
+		return err
+	}
+
+	doc.Lock()
+	h.configure(doc, buf)
+	doc.invalidate()
+	doc.Unlock()
+
+	h.pipeline().Submit(&highlightJob{
+		buf:   buf,
+		doc:   doc,
+		tick:  doc.Tick(),
+		to:    -1,
+		event: newEvent("highlight", buf),
+	})
+	return nil
+}
+
+// HandleHighlightRangeCommand parses and highlights a range of lines of the
+// current buffer.
+func (h *Highlighter) HandleHighlightRangeCommand(lines []int) error {
+	logger.Debugf("HandleHighlightRangeCommand(%v)", lines)
+
+	if len(lines) != 2 || lines[0] < 1 || lines[1] < lines[0] {
+		return newError(CodeInvalidArgs, "range of lines is expected")
+	}
+
+	var buf, doc, err = h.currentDocument()
+	if err != nil {
+		return err
+	}
+
+	h.pipeline().Submit(&highlightJob{
+		buf:   buf,
+		doc:   doc,
+		tick:  doc.Tick(),
+		from:  lines[0] - 1,
+		to:    lines[1],
+		event: newEvent("highlight_range", buf),
+	})
+	return nil
+}
+
+// currentDocument returns the current buffer and its document.
+func (h *Highlighter) currentDocument() (nvim.Buffer, *Document, error) {
+	var buf, err = CurrentBuffer(h.nvim)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var doc, ok = DocIndex.Get(buf)
+	if !ok {
+		return 0, nil, errNotAttached
+	}
+	return buf, doc, nil
+}
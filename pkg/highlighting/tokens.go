@@ -0,0 +1,216 @@
+package highlighting
+
+import (
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+// TokenType is a semantic class of a lexeme. Highlight group of a token is
+// named after its type like `BnfReference`.
+type TokenType string
+
+// Types of tokens.
+const (
+	TokenDefinition TokenType = "Definition"
+	TokenReference  TokenType = "Reference"
+	TokenTerminal   TokenType = "Terminal"
+	TokenOperator   TokenType = "Operator"
+	TokenComment    TokenType = "Comment"
+	TokenCharacter  TokenType = "Character"
+	TokenSpecial    TokenType = "Special"
+	TokenDelimiter  TokenType = "Delimiter"
+)
+
+// TokenModifier is a set of flags which refine type of token.
+type TokenModifier uint
+
+// Modifiers of tokens in order of their precedence.
+const (
+	// ModifierDeprecated marks definitions and references of rules which
+	// are annotated with `@deprecated`.
+	ModifierDeprecated TokenModifier = 1 << iota
+	// ModifierStart marks definition of start rule.
+	ModifierStart
+	// ModifierLexer marks lexer rules of ANTLR which names are uppercase.
+	ModifierLexer
+)
+
+// modifierNames are suffixes of highlight groups of modifiers.
+var modifierNames = []struct {
+	modifier TokenModifier
+	name     string
+}{
+	{ModifierDeprecated, "Deprecated"},
+	{ModifierStart, "Start"},
+	{ModifierLexer, "Lexer"},
+}
+
+// Token is a classified lexeme of a line. Range is in bytes.
+type Token struct {
+	Begin     int
+	End       int
+	Type      TokenType
+	Modifiers TokenModifier
+}
+
+// Group returns highlight group of a token. It is the group of its type with
+// suffix of modifier of the highest precedence, e.g. BnfReferenceDeprecated.
+func (t Token) Group() string {
+	var group = "Bnf" + string(t.Type)
+	for _, modifier := range modifierNames {
+		if t.Modifiers&modifier.modifier != 0 {
+			return group + modifier.name
+		}
+	}
+	return group
+}
+
+// tokenGroups are default links of highlight groups of tokens. They keep
+// colors of builtin syntax groups. Definitions and references are linked to
+// generic groups of rule names and non-terminals.
+var tokenGroups = [][2]string{
+	{"BnfRuleName", "Identifier"},
+	{"BnfNonTerminal", "Identifier"},
+	{"BnfDefinition", "BnfRuleName"},
+	{"BnfReference", "BnfNonTerminal"},
+	{"BnfTerminal", "String"},
+	{"BnfOperator", "Operator"},
+	{"BnfComment", "Comment"},
+	{"BnfCharacter", "Character"},
+	{"BnfSpecial", "Special"},
+	{"BnfDelimiter", "Delimiter"},
+	{"BnfDefinitionStart", "BnfStartSymbol"},
+	{"BnfDefinitionLexer", "Constant"},
+	{"BnfReferenceLexer", "Constant"},
+	{"BnfDefinitionDeprecated", "BnfDeprecated"},
+	{"BnfReferenceDeprecated", "BnfDeprecated"},
+}
+
+// LineTokens classifies lexemes of a parse tree of a line. Start is a start
+// symbol and deprecated are names of deprecated rules. It returns number of
+// visited nodes as well. If semantic parsing of a line fails then only
+// lexemes of its valid prefix and comments are returned, so the broken suffix
+// is not highlighted as if it were correct.
+func LineTokens(
+	dialect parser.Dialect, ast *parser.AST, start string,
+	deprecated map[string]*analysis.Deprecation,
+) ([]Token, int, error) {
+	var tokens, nonodes, err = lineTokens(dialect, ast, start, deprecated)
+	if dialect.Multiline() {
+		return tokens, nonodes, err
+	}
+
+	var prefix = ast.Prefix()
+	var valid = tokens[:0]
+	for _, token := range tokens {
+		if token.End <= prefix || token.Type == TokenComment {
+			valid = append(valid, token)
+		}
+	}
+	return valid, nonodes, err
+}
+
+// brokenSuffix extends range of parsing error of a line to the end of the
+// last lexeme which follows it, so the whole suffix which is not highlighted
+// is marked.
+func brokenSuffix(
+	dialect parser.Dialect, ast *parser.AST, diag parser.Diagnostic,
+) parser.Diagnostic {
+	var prefix = ast.Prefix()
+	if dialect.Multiline() || diag.Range.Begin != prefix {
+		return diag
+	}
+
+	var tokens, _, _ = lineTokens(dialect, ast, "", nil)
+	for _, token := range tokens {
+		if token.Type != TokenComment && token.End > diag.Range.End {
+			diag.Range.End = token.End
+		}
+	}
+	return diag
+}
+
+func lineTokens(
+	dialect parser.Dialect, ast *parser.AST, start string,
+	deprecated map[string]*analysis.Deprecation,
+) ([]Token, int, error) {
+	// Lexemes of syntactic tree are distinct from nodes of statements, so
+	// left-hand sides are matched by their offsets. Syntactic tree has no
+	// statements, so a non-terminal right before assignment is a left-hand
+	// side as well.
+	var heads = make(map[int]bool)
+	for _, stmt := range ast.Statements() {
+		if stmt.Rule != nil {
+			heads[parser.Span(stmt.Rule.Left()).Begin] = true
+		}
+	}
+
+	var tokens []Token
+	var names = make(map[int]string)
+	var define = func(idx int) {
+		tokens[idx].Type = TokenDefinition
+		if start != "" && names[idx] == start {
+			tokens[idx].Modifiers |= ModifierStart
+		}
+	}
+
+	var nonodes, err = ast.Traverse(func(node parser.Node) error {
+		var token Token
+		switch node := node.(type) {
+		case *parser.NonTerminal:
+			var name = string(node.Name)
+			names[len(tokens)] = name
+			token = Token{node.Begin, node.End, TokenReference, 0}
+			if _, ok := deprecated[name]; ok {
+				token.Modifiers |= ModifierDeprecated
+			}
+			// Lexer rules of ANTLR are distinguished by uppercase names.
+			if dialect == parser.DialectANTLR && len(name) > 0 &&
+				name[0] >= 'A' && name[0] <= 'Z' {
+				token.Modifiers |= ModifierLexer
+			}
+		case *parser.Terminal:
+			token = Token{node.Begin, node.End, TokenTerminal, 0}
+		case *parser.AssignmentExpression:
+			token = Token{node.Begin, node.End, TokenOperator, 0}
+			if last := len(tokens) - 1; last >= 0 &&
+				tokens[last].Type == TokenReference {
+				define(last)
+			}
+		case *parser.AlternativeExpression:
+			token = Token{node.Begin, node.End, TokenOperator, 0}
+		case *parser.RepetitionExpression:
+			token = Token{node.Begin, node.End, TokenOperator, 0}
+		case *parser.ExceptionExpression:
+			token = Token{node.Begin, node.End, TokenOperator, 0}
+		case *parser.PredicateExpression:
+			token = Token{node.Begin, node.End, TokenOperator, 0}
+		case *parser.CharacterClass:
+			token = Token{node.Begin, node.End, TokenCharacter, 0}
+		case *parser.SpecialSequence:
+			token = Token{node.Begin, node.End, TokenSpecial, 0}
+		case *parser.Wildcard:
+			token = Token{node.Begin, node.End, TokenSpecial, 0}
+		case *parser.Epsilon:
+			token = Token{node.Begin, node.End, TokenSpecial, 0}
+		case *parser.Comment:
+			token = Token{node.Begin, node.End, TokenComment, 0}
+		case *parser.GroupExpression:
+			// Opening and closing brackets are distinct tokens.
+			tokens = append(tokens,
+				Token{node.Begin, node.Begin + 1, TokenDelimiter, 0})
+			token = Token{node.End - 1, node.End, TokenDelimiter, 0}
+		default:
+			return nil
+		}
+		tokens = append(tokens, token)
+		return nil
+	})
+
+	for idx, token := range tokens {
+		if token.Type == TokenReference && heads[token.Begin] {
+			define(idx)
+		}
+	}
+	return tokens, nonodes, err
+}
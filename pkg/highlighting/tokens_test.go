@@ -0,0 +1,102 @@
+package highlighting
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+func TestLineTokens(t *testing.T) {
+	var line = []byte(`<s> ::= <old> "a" | <s> ; comment`)
+	var ast, err = parser.ParseDialect(parser.DialectBNF, line)
+	if err != nil {
+		t.Fatalf("failed to parse: %s", err)
+	}
+
+	var deprecated = map[string]*analysis.Deprecation{
+		"old": analysis.ParseDeprecation("use <new>"),
+	}
+	tokens, _, err := LineTokens(parser.DialectBNF, ast, "s", deprecated)
+	if err != nil {
+		t.Fatalf("failed to classify tokens: %s", err)
+	}
+
+	var groups = make(map[string]string)
+	for _, token := range tokens {
+		groups[string(line[token.Begin:token.End])] = token.Group()
+	}
+
+	var expected = map[string]string{
+		"<s>":       "BnfReference",
+		"::=":       "BnfOperator",
+		"<old>":     "BnfReferenceDeprecated",
+		`"a"`:       "BnfTerminal",
+		"|":         "BnfOperator",
+		"; comment": "BnfComment",
+	}
+	for text, group := range expected {
+		if groups[text] != group {
+			t.Errorf("wrong group of %s: %s", text, groups[text])
+		}
+	}
+
+	// Definition of start rule is distinguished from its references.
+	if group := tokens[0].Group(); group != "BnfDefinitionStart" {
+		t.Errorf("wrong group of definition: %s", group)
+	}
+}
+
+func TestLineTokensGroup(t *testing.T) {
+	var line = []byte(`<a> ::= ("x" | <b>) "y"`)
+	var ast, err = parser.ParseDialect(parser.DialectBNF, line)
+	if err != nil {
+		t.Fatalf("failed to parse: %s", err)
+	}
+
+	tokens, _, err := LineTokens(parser.DialectBNF, ast, "", nil)
+	if err != nil {
+		t.Fatalf("failed to classify tokens: %s", err)
+	}
+
+	var delimiters []string
+	for _, token := range tokens {
+		if token.Type == TokenDelimiter {
+			delimiters = append(delimiters, string(line[token.Begin:token.End]))
+		}
+	}
+	if len(delimiters) != 2 || delimiters[0] != "(" || delimiters[1] != ")" {
+		t.Errorf("wrong delimiters: %v", delimiters)
+	}
+}
+
+func TestLineTokensBrokenSuffix(t *testing.T) {
+	var line = []byte(`<a> ::= <b> ) <c> | "x" ; note`)
+	var ast, err = parser.ParseDialect(parser.DialectBNF, line)
+	if err != nil {
+		t.Fatalf("failed to parse: %s", err)
+	}
+
+	tokens, _, err := LineTokens(parser.DialectBNF, ast, "", nil)
+	if err != nil {
+		t.Fatalf("failed to classify tokens: %s", err)
+	}
+
+	var lexemes []string
+	for _, token := range tokens {
+		lexemes = append(lexemes, string(line[token.Begin:token.End]))
+	}
+	if actual := strings.Join(lexemes, " "); actual != "<a> ::= <b> ; note" {
+		t.Errorf("wrong lexemes of valid prefix: %s", actual)
+	}
+
+	var diags = parser.Diagnostics(ast)
+	if len(diags) != 1 {
+		t.Fatalf("wrong number of diagnostics: %v", diags)
+	}
+	var diag = brokenSuffix(parser.DialectBNF, ast, diags[0])
+	if diag.Range != (parser.Range{Begin: 12, End: 23}) {
+		t.Errorf("wrong range of broken suffix: %v", diag.Range)
+	}
+}
@@ -0,0 +1,101 @@
+package highlighting
+
+import (
+	"time"
+
+	"github.com/neovim/go-client/nvim"
+)
+
+// BufEvent is an evaluated context of buffer autocommands.
+type BufEvent struct {
+	Buffer   int    `msgpack:"buffer"`
+	Filename string `msgpack:"filename"`
+}
+
+// bufEventEval is an expression which is evaluated to BufEvent.
+const bufEventEval = `{"buffer": str2nr(expand("<abuf>")), ` +
+	`"filename": expand("<afile>:p")}`
+
+// HandleBufWriteEvent records snapshot of grammar metrics after saving.
+// Documents which include the file are refreshed as well.
+func (h *Highlighter) HandleBufWriteEvent(ev *BufEvent) {
+	logger.Debugf("HandleBufWriteEvent(%d, %s)", ev.Buffer, ev.Filename)
+
+	if !h.enabled() {
+		return
+	}
+
+	h.refreshIncluders(ev.Filename)
+
+	var doc, ok = DocIndex.Get(nvim.Buffer(ev.Buffer))
+	if !ok {
+		logger.Warnf("unknown buffer: %d", ev.Buffer)
+		return
+	}
+
+	var history, err = h.getHistory()
+	if err != nil {
+		logger.Errorf("failed to load history: %s", err)
+		return
+	}
+
+	doc.Lock()
+	var grammar = doc.grammar()
+	var diagnostics = doc.NoDiagnostics()
+	doc.Unlock()
+
+	history.Append(ev.Filename, Snapshot{
+		Time:        time.Now().UTC(),
+		Diagnostics: diagnostics,
+		Metrics:     grammar.Metrics(),
+	})
+
+	if err := history.Save(); err != nil {
+		logger.Errorf("failed to save history: %s", err)
+	}
+}
+
+// HandleTrendCommand shows history of grammar health of the current buffer.
+func (h *Highlighter) HandleTrendCommand() error {
+	logger.Debugf("HandleTrendCommand()")
+
+	var history, err = h.getHistory()
+	if err != nil {
+		return err
+	}
+
+	var filename string
+	if err := Eval(h.nvim, `expand("%:p")`, &filename); err != nil {
+		return err
+	}
+
+	report, err := h.newScratchBuffer(history.Report(filename))
+	if err != nil {
+		return err
+	}
+
+	_, err = h.openWindow("split", report)
+	return err
+}
+
+// getHistory lazily creates history of grammar health. History is persisted
+// if g:bnf_history_file option is set.
+func (h *Highlighter) getHistory() (*History, error) {
+	if h.history != nil {
+		return h.history, nil
+	}
+
+	var filename string
+	var expr = "expand(get(g:, 'bnf_history_file', ''))"
+	if err := Eval(h.nvim, expr, &filename); err != nil {
+		return nil, err
+	}
+
+	var history = NewHistory(filename)
+	if err := history.Load(); err != nil {
+		return nil, err
+	}
+
+	h.history = history
+	return history, nil
+}
@@ -0,0 +1,53 @@
+package highlighting
+
+import (
+	"fmt"
+
+	"github.com/neovim/go-client/nvim"
+)
+
+// HandleBufUnloadEvent forgets document of a buffer which is unloaded or
+// wiped out. Events of buffers which are not attached are ignored.
+func (h *Highlighter) HandleBufUnloadEvent(buf int) {
+	logger.Debugf("HandleBufUnloadEvent(%d)", buf)
+
+	if h.forget(nvim.Buffer(buf)) {
+		newEvent("buf_unload", nvim.Buffer(buf)).Infof(
+			"buffer %d was unloaded", buf)
+	}
+}
+
+// registerBufUnload defines autocommands which call HandleBufUnloadEvent.
+// They are not in manifest since unloading of a buffer should not start
+// plugin host.
+func (h *Highlighter) registerBufUnload() {
+	var cmd = fmt.Sprintf("autocmd nvim-bnf BufUnload,BufWipeout * "+
+		"call rpcnotify(%d, 'nvim_bnf_buf_unload', str2nr(expand('<abuf>')))",
+		h.nvim.ChannelID())
+	if err := Command(h.nvim, cmd); err != nil {
+		logger.Errorf("failed to register BufUnload: %s", err)
+	}
+}
+
+// forget removes document of a buffer from index, drops its non-terminals
+// from completion index, and clears its highlights if the buffer still
+// exists. It reports whether the buffer was attached.
+func (h *Highlighter) forget(buf nvim.Buffer) bool {
+	var doc, ok = DocIndex.Get(buf)
+	if !ok || !DocIndex.Delete(buf) {
+		return false
+	}
+
+	doc.Lock()
+	doc.dropSymbols()
+	doc.Unlock()
+
+	if valid, err := IsBufferValid(h.nvim, buf); err != nil {
+		logger.Warnf("failed to check buffer %d: %s", buf, err)
+	} else if valid {
+		if err := h.clearBuffer(buf); err != nil {
+			logger.Warnf("failed to clear buffer %d: %s", buf, err)
+		}
+	}
+	return true
+}
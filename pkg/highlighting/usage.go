@@ -0,0 +1,88 @@
+package highlighting
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+	"github.com/daskol/nvim-bnf/pkg/i18n"
+)
+
+// HandleUsageReportCommand opens a scratch split with a table of rules of the
+// current buffer. Every rule is listed with the number of references to it,
+// whether it is reachable from the start symbol, and names of rules which
+// refer to it.
+func (h *Highlighter) HandleUsageReportCommand() error {
+	logger.Debugf("HandleUsageReportCommand()")
+
+	var buf, err = CurrentBuffer(h.nvim)
+	if err != nil {
+		return err
+	}
+
+	lines, err := BufferLines(h.nvim, buf, 0, -1, true)
+	if err != nil {
+		return err
+	}
+
+	var grammar = analysis.NewGrammar(h.dialectOf(buf), lines)
+	if start := h.startSymbol(); start != "" {
+		grammar.Start = start
+	}
+
+	view, err := h.newScratchBuffer(formatUsages(grammar.Usages()))
+	if err != nil {
+		return err
+	}
+
+	_, err = h.openWindow("split", view)
+	return err
+}
+
+// formatUsages renders usages of rules as a table with aligned columns.
+func formatUsages(usages []analysis.Usage) [][]byte {
+	var rows = [][]string{{
+		i18n.T("Rule"), i18n.T("Line"), i18n.T("Refs"), i18n.T("Reachable"),
+		i18n.T("Referenced by"),
+	}}
+	for _, usage := range usages {
+		var reachable = i18n.T("no")
+		if usage.Reachable {
+			reachable = i18n.T("yes")
+		}
+		var referrers = make([]string, len(usage.ReferencedBy))
+		for idx, name := range usage.ReferencedBy {
+			referrers[idx] = "<" + name + ">"
+		}
+		rows = append(rows, []string{
+			"<" + usage.Name + ">",
+			fmt.Sprint(usage.Line + 1),
+			fmt.Sprint(usage.References),
+			reachable,
+			strings.Join(referrers, ", "),
+		})
+	}
+
+	var widths = make([]int, len(rows[0]))
+	for _, row := range rows {
+		for col, cell := range row {
+			if width := len([]rune(cell)); width > widths[col] {
+				widths[col] = width
+			}
+		}
+	}
+
+	var lines = make([][]byte, len(rows))
+	for idx, row := range rows {
+		var line strings.Builder
+		for col, cell := range row {
+			line.WriteString(cell)
+			if col+1 < len(row) {
+				var pad = widths[col] - len([]rune(cell)) + 2
+				line.WriteString(strings.Repeat(" ", pad))
+			}
+		}
+		lines[idx] = []byte(strings.TrimRight(line.String(), " "))
+	}
+	return lines
+}
@@ -0,0 +1,29 @@
+package highlighting
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+)
+
+func TestFormatUsages(t *testing.T) {
+	var usages = []analysis.Usage{
+		{
+			Name:         "expr",
+			References:   2,
+			ReferencedBy: []string{"expr", "term"},
+			Reachable:    true,
+		},
+		{Name: "unused", Line: 3},
+	}
+
+	var table = bytes.Join(formatUsages(usages), []byte{'\n'})
+	var expected = "" +
+		"Rule      Line  Refs  Reachable  Referenced by\n" +
+		"<expr>    1     2     yes        <expr>, <term>\n" +
+		"<unused>  4     0     no"
+	if string(table) != expected {
+		t.Errorf("wrong table:\n%s", table)
+	}
+}
@@ -0,0 +1,180 @@
+package highlighting
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strconv"
+
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+	"github.com/daskol/nvim-bnf/pkg/i18n"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+	"github.com/daskol/nvim-bnf/pkg/workspace"
+	"github.com/neovim/go-client/nvim"
+)
+
+// viewFoldExpr folds every section of a grammar. Sections are groups of lines
+// separated with blank lines.
+const viewFoldExpr = `getline(v:lnum) =~ '^\s*$' ? 0 : 1`
+
+// HandleViewCommand opens the grammar of the current buffer in a read-only
+// scratch buffer. Included files are inlined after the grammar. Every rule is
+// annotated with its number, the number of references to it, and the file it
+// is included from. Sections of grammar are folded.
+func (h *Highlighter) HandleViewCommand() error {
+	logger.Debugf("HandleViewCommand()")
+
+	if err := h.setupNamespace(); err != nil {
+		return err
+	}
+
+	var buf, err = CurrentBuffer(h.nvim)
+	if err != nil {
+		return err
+	}
+
+	lines, err := BufferLines(h.nvim, buf, 0, -1, true)
+	if err != nil {
+		return err
+	}
+
+	var dialect = h.dialectOf(buf)
+	var dir = includeDir(h.bufferName(buf))
+	files, err := Workspace.Includes(dir, lines)
+	if err != nil {
+		logger.Warnf("failed to load included files: %s", err)
+	}
+	lines, sources := inlineIncludes(dialect, dir, lines, files)
+
+	view, err := h.newScratchBuffer(lines)
+	if err != nil {
+		return err
+	}
+
+	if err := h.renderView(view, dialect, lines, sources); err != nil {
+		return err
+	}
+
+	return h.openView(view)
+}
+
+// renderView hightlights lines of grammar in a view buffer and adds lenses
+// with rule numbers, reference counts, and files of included rules.
+func (h *Highlighter) renderView(
+	view nvim.Buffer, dialect parser.Dialect, lines [][]byte,
+	sources []viewSource,
+) error {
+	// Lenses should be added after hightlighting since hightlighting of a
+	// line clears everything on the line.
+	var doc = &Document{
+		Lines:     lines,
+		dialect:   dialect,
+		namespace: h.namespace,
+		explain:   h.explainMode(),
+	}
+	// Symbols of the view are registered in NonTerminalIndex on
+	// highlighting, so they are withdrawn since the view is not a document.
+	defer doc.dropSymbols()
+	if err := doc.Hightlight(context.Background(), h.nvim, view); err != nil {
+		return err
+	}
+
+	var grammar = analysis.NewGrammar(dialect, lines)
+	var refs = grammar.NoReferences()
+
+	var batch = h.nvim.NewBatch()
+
+	for idx, rule := range grammar.Rules {
+		var res int
+		var row = rule.Line
+		var chunks = []Chunk{
+			NewChunk("#"+strconv.Itoa(idx+1), "LineNr"),
+			NewChunk(" · "+formatRefs(refs[rule.Name]), "Comment"),
+		}
+		if path := sourceOf(sources, row); path != "" {
+			chunks = append(chunks, NewChunk(" · "+path, "Directory"))
+		}
+		h.caps.SetVirtualText(batch, &view, h.namespace, row, chunks, NoOpts,
+			&res)
+	}
+
+	return execute(batch)
+}
+
+// viewSource is an included file which lines of a view come from starting
+// with a line.
+type viewSource struct {
+	line int
+	path string
+}
+
+// inlineIncludes appends lines of files which are included by a document to
+// lines of the document. Files are separated with blank lines, so every file
+// is folded on its own. Paths of files are relative to a directory of the
+// document. Files in other dialects are not inlined since they could not be
+// highlighted as a part of the document.
+func inlineIncludes(
+	dialect parser.Dialect, dir string, lines [][]byte,
+	files []*workspace.File,
+) ([][]byte, []viewSource) {
+	var sources []viewSource
+	for _, file := range files {
+		if file.Dialect != dialect {
+			continue
+		}
+
+		var included = file.Lines
+		for len(included) > 0 &&
+			len(bytes.TrimSpace(included[len(included)-1])) == 0 {
+			included = included[:len(included)-1]
+		}
+		if len(included) == 0 {
+			continue
+		}
+
+		var path = file.Path
+		if rel, err := filepath.Rel(dir, path); err == nil && dir != "" {
+			path = rel
+		}
+
+		lines = append(lines, []byte{})
+		sources = append(sources, viewSource{len(lines), path})
+		lines = append(lines, included...)
+	}
+	return lines, sources
+}
+
+// sourceOf returns path of included file which a line of a view comes from.
+// It is empty for lines of the document itself.
+func sourceOf(sources []viewSource, line int) string {
+	var path string
+	for _, source := range sources {
+		if source.line > line {
+			break
+		}
+		path = source.path
+	}
+	return path
+}
+
+// openView shows a view buffer in a new split window and sets up folding.
+func (h *Highlighter) openView(view nvim.Buffer) error {
+	var win, err = h.openWindow("split", view)
+	if err != nil {
+		return err
+	}
+
+	var batch = h.nvim.NewBatch()
+	batch.SetWindowOption(win, "foldmethod", "expr")
+	batch.SetWindowOption(win, "foldexpr", viewFoldExpr)
+	batch.SetWindowOption(win, "foldenable", true)
+	return execute(batch)
+}
+
+func formatRefs(norefs int) string {
+	if norefs == 1 {
+		return i18n.T("1 ref")
+	} else {
+		return i18n.Sprintf("%d refs", norefs)
+	}
+}
@@ -0,0 +1,41 @@
+package highlighting
+
+import (
+	"testing"
+
+	"github.com/daskol/nvim-bnf/pkg/parser"
+	"github.com/daskol/nvim-bnf/pkg/workspace"
+)
+
+func TestInlineIncludes(t *testing.T) {
+	var lines = [][]byte{
+		[]byte(`; %include "common.bnf"`),
+		[]byte(`<number> ::= <digit> | <digit> <number>`),
+	}
+	var files = []*workspace.File{
+		{
+			Path:    "/spec/common.bnf",
+			Dialect: parser.DialectBNF,
+			Lines:   [][]byte{[]byte(`<digit> ::= "0" | "1"`), {}},
+		},
+		{
+			Path:    "/spec/lexer.ebnf",
+			Dialect: parser.DialectEBNF,
+			Lines:   [][]byte{[]byte(`digit = "0" | "1" ;`)},
+		},
+	}
+
+	var view, sources = inlineIncludes(parser.DialectBNF, "/spec", lines,
+		files)
+	if len(view) != 4 || len(view[2]) != 0 ||
+		string(view[3]) != `<digit> ::= "0" | "1"` {
+		t.Fatalf("wrong lines of view: %q", view)
+	}
+
+	var expected = []string{"", "", "", "common.bnf"}
+	for line, path := range expected {
+		if source := sourceOf(sources, line); source != path {
+			t.Errorf("wrong source of line %d: %q", line, source)
+		}
+	}
+}
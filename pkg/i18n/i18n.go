@@ -0,0 +1,76 @@
+// Package i18n provides catalogs of translated messages. Messages are
+// identified with their English text or format string, so English is the
+// default language and untranslated messages fall back to it.
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"sync"
+)
+
+// Catalog maps English messages to their translations.
+type Catalog map[string]string
+
+// catalog is a catalog which is currently in use.
+var catalog Catalog
+
+var guard sync.RWMutex
+
+// Load reads catalog from JSON file which is an object with English messages
+// as keys and their translations as values.
+func Load(filename string) (Catalog, error) {
+	var bytes, err = ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var catalog Catalog
+	if err := json.Unmarshal(bytes, &catalog); err != nil {
+		return nil, fmt.Errorf("i18n: invalid catalog %s: %s", filename, err)
+	}
+	return catalog, nil
+}
+
+// Use sets catalog for translation of messages. Nil catalog resets language
+// to English.
+func Use(c Catalog) {
+	guard.Lock()
+	defer guard.Unlock()
+	catalog = c
+}
+
+// T translates a message. Message is returned as is if there is no
+// translation.
+func T(msgid string) string {
+	guard.RLock()
+	defer guard.RUnlock()
+	if msgstr, ok := catalog[msgid]; ok && msgstr != "" {
+		return msgstr
+	}
+	return msgid
+}
+
+// Sprintf translates format string and formats message according to it.
+func Sprintf(format string, args ...interface{}) string {
+	return fmt.Sprintf(T(format), args...)
+}
+
+// Template returns catalog of all known messages without translations. It is
+// a starting point for translators.
+func Template() Catalog {
+	var template = make(Catalog, len(messages))
+	for _, msgid := range messages {
+		template[msgid] = ""
+	}
+	return template
+}
+
+// Messages returns all known messages in lexicographical order.
+func Messages() []string {
+	var msgids = append([]string{}, messages...)
+	sort.Strings(msgids)
+	return msgids
+}
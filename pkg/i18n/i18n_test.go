@@ -0,0 +1,55 @@
+package i18n
+
+import (
+	"testing"
+)
+
+func TestCatalog(t *testing.T) {
+	var catalog, err = Load("testdata/ru.json")
+	if err != nil {
+		t.Fatalf("failed to load catalog: %s", err)
+	}
+
+	Use(catalog)
+	defer Use(nil)
+
+	if msg := Sprintf("%s is expected", T("term")); msg != "ожидается терм" {
+		t.Errorf("wrong translation: %s", msg)
+	}
+
+	if msg := Sprintf("%d refs", 3); msg != "ссылок: 3" {
+		t.Errorf("wrong translation: %s", msg)
+	}
+
+	// Empty translation and missing one fall back to English.
+	var msgid = "bnf: unexpected character"
+	if msg := T(msgid); msg != msgid {
+		t.Errorf("empty translation is used: %s", msg)
+	}
+	if msg := T("wildcard"); msg != "wildcard" {
+		t.Errorf("unknown translation is used: %s", msg)
+	}
+
+	Use(nil)
+	if msg := Sprintf("%d refs", 3); msg != "3 refs" {
+		t.Errorf("wrong default message: %s", msg)
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	var template = Template()
+	var msgids = Messages()
+	if len(template) != len(msgids) {
+		t.Fatalf("messages are duplicated: %d != %d", len(template),
+			len(msgids))
+	}
+
+	for idx, msgid := range msgids {
+		if template[msgid] != "" {
+			t.Errorf("template is not empty for %q", msgid)
+		}
+		if idx > 0 && msgids[idx-1] >= msgid {
+			t.Errorf("messages are not sorted: %q", msgid)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package i18n
+
+// messages lists all messages which are shown to user in diagnostics and
+// virtual text. It should be kept in sync with messages passed to T and
+// Sprintf.
+var messages = []string{
+	// Diagnostics of parser.
+	"%s is expected",
+	"bnf: lower bound of repetition exceeds upper one",
+	"bnf: rule is empty",
+	"bnf: there is no production statements",
+	"bnf: unclosed '('",
+	"bnf: unexpected character",
+	"argument",
+	"code point",
+	"directive",
+	"element",
+	"identifier",
+	"integer",
+	"literal",
+	"meta identifier",
+	"name",
+	"non-terminal",
+	"rule name",
+	"term",
+	"terminal",
+	"terminal or non-terminal",
+	"terminal or non-terminal or EOL",
+	"'/' or term",
+	"'|' or term",
+
+	// Diagnostics of analysis.
+	"%s is not supported in %s",
+	"alternative %d of rule <%s> is repeated",
+	"alternatives %d and %d of rule <%s> start with %s",
+	"character class",
+	"exception",
+	"left recursion",
+	"non-terminal <%s> is not defined",
+	"recursive descent parser",
+	"rule <%s> is already defined on line %d",
+	"rule <%s> is unreachable from start rule",
+	"rule <%s> never derives a string of terminals",
+	"syntactic predicate",
+	"wildcard",
+
+	// Findings of linter.
+	"name of rule <%s> does not match %q",
+	"naming pattern %q is invalid",
+	"rule <%s> has %d alternatives while at most %d are allowed",
+	"start rule <%s> is not defined",
+	"terminal rule <%s> is never referenced",
+	"lexemes are separated with several spaces",
+	"terminal %s should be quoted with %s quotes",
+	"trailing whitespace",
+
+	// Code actions.
+	"Define <%s>",
+	"Define all undefined non-terminals",
+	"Merge definitions of <%s>",
+	"Remove unused rule <%s>",
+	"Replace <%s> with <%s>",
+
+	// Virtual text.
+	"%d refs",
+	"1 ref",
+	"Not committed yet",
+	"alt 1 ",
+	"alts 1-%d ",
+
+	// Derivation.
+	"Expand %s with:",
+	"There are no non-terminals to expand.",
+
+	// Quiz.
+	"%q belongs to <%s>",
+	"%q belongs to <%s>? [y/n] ",
+	"%q does not belong to <%s>",
+	"Score: %d of %d",
+	"Type a string which belongs to <%s>: ",
+
+	// Lightweight mode.
+	"Grammar is too large, so it is highlighted only with :BNFHighlight.",
+
+	// Renaming.
+	"Rename %d occurrences in %d files which are not opened?",
+	"Renamed %d occurrences in %d files.",
+
+	// Fixes.
+	"Fixed %d findings.",
+
+	// Information about lines.
+	"%d lexemes are highlighted.",
+	"Line %d could not be parsed, so it is not highlighted.",
+	"Line %d is parsed by semantic parser.",
+	"Line %d is rejected by semantic parser, so it is highlighted without " +
+		"roles of lexemes.",
+	"Valid prefix is %d of %d bytes.",
+
+	// Usage report.
+	"Line",
+	"Reachable",
+	"Referenced by",
+	"Refs",
+	"Rule",
+	"no",
+	"yes",
+
+	// Statusline.
+	"%d rules",
+	"1 rule",
+
+	// Statistics.
+	"%d recent highlights took %s on average and %s at most",
+	"%d samples took %s on average",
+	"Highlighting",
+	"Parsing",
+	"There are no samples.",
+
+	// Explanations of diagnostics.
+	"A grammar is a list of production rules which define " +
+		"non-terminals in terms of other symbols.",
+	"A group opened with ( needs a matching ) on the same line. " +
+		"Close it or remove the bracket.",
+	"A non-terminal is defined by several rules. Join their " +
+		"right-hand sides with alternation unless repeated " +
+		"definitions are meant to be alternatives.",
+	"A recursive descent parser calls a rule to match its own " +
+		"first symbol, so a left-recursive rule never consumes input " +
+		"and loops forever. Rewrite A ::= A b | c as repetition c {b}.",
+	"A rule needs a right-hand side. Write an empty terminal " +
+		"explicitly if the rule should match nothing.",
+	"Alternatives start with the same symbol, so a parser could not " +
+		"choose one of them by the next token. Factor out the common " +
+		"prefix into an auxiliary rule.",
+	"Character class is a shorthand for alternatives of single " +
+		"characters. Other notations need to list them.",
+	"Every derivation of the rule keeps a non-terminal which never " +
+		"turns into terminals. Add an alternative which does not " +
+		"depend on the rule itself.",
+	"Exception a - b matches strings of a which are not matched by " +
+		"b. It is not context-free in general.",
+	"No derivation of the start rule, the first one, ever reaches " +
+		"the rule. Reference it or remove it.",
+	"Parser could not recognize the statement. Check that it has " +
+		"the form of a production rule.",
+	"Predicates &e and !e of PEG look ahead without consuming " +
+		"input. Context-free notations have no way to express them.",
+	"Repetition matches from the lower bound to the upper bound " +
+		"copies of its operand, so the lower bound could not exceed " +
+		"the upper one.",
+	"The non-terminal is referenced but no rule defines it. Define " +
+		"it or fix a typo in its name.",
+	"The rule ended too early. An operator or a quote is probably " +
+		"left without its operand or closing pair.",
+	"The same alternative is written twice in a rule. It adds " +
+		"nothing to the language, so remove one of them.",
+	"Tokens of a rule are non-terminals, terminals and operators " +
+		"of the dialect. Any other character breaks the rule.",
+	"Wildcard matches any single character. Other notations need " +
+		"explicit alternatives of characters.",
+}
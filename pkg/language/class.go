@@ -0,0 +1,96 @@
+package language
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// class is a set of characters which is given with ranges. It is either a
+// character class like `[a-z_]` or `[^"]` or a range of ANTLR like
+// `'a'..'z'`.
+type class struct {
+	negated bool
+	ranges  [][2]rune
+}
+
+// parseClass parses content of a character class. Code points of W3C (e.g.
+// `#x20`) and escape sequences are resolved.
+func parseClass(text string) *class {
+	var cls class
+	if lo, hi, ok := parseRange(text); ok {
+		cls.ranges = append(cls.ranges, [2]rune{lo, hi})
+		return &cls
+	}
+
+	if strings.HasPrefix(text, "^") {
+		cls.negated = true
+		text = text[1:]
+	}
+
+	for len(text) > 0 {
+		var lo, size = classChar(text)
+		text = text[size:]
+		var hi = lo
+		if len(text) > 1 && text[0] == '-' {
+			hi, size = classChar(text[1:])
+			text = text[1+size:]
+		}
+		cls.ranges = append(cls.ranges, [2]rune{lo, hi})
+	}
+
+	return &cls
+}
+
+// parseRange parses range of characters of ANTLR like `'a'..'z'`.
+func parseRange(text string) (rune, rune, bool) {
+	var bounds = strings.Split(text, "..")
+	if len(bounds) != 2 {
+		return 0, 0, false
+	}
+
+	var runes [2]rune
+	for idx, bound := range bounds {
+		if len(bound) < 3 || bound[0] != '\'' || bound[len(bound)-1] != '\'' {
+			return 0, 0, false
+		}
+		var value = unescape(bound[1 : len(bound)-1])
+		if utf8.RuneCountInString(value) != 1 {
+			return 0, 0, false
+		}
+		runes[idx], _ = utf8.DecodeRuneInString(value)
+	}
+	return runes[0], runes[1], true
+}
+
+// classChar decodes the first character of a class. It returns the character
+// and number of bytes which it takes.
+func classChar(text string) (rune, int) {
+	switch {
+	case strings.HasPrefix(text, "#x"):
+		var end = 2
+		for end < len(text) && strings.IndexByte(hexDigits, text[end]) >= 0 {
+			end++
+		}
+		if code, err := strconv.ParseUint(text[2:end], 16, 32); err == nil {
+			return rune(code), end
+		}
+	case text[0] == '\\' && len(text) > 1:
+		if value, ok := escapes[text[1]]; ok {
+			return rune(value), 2
+		}
+	}
+	return utf8.DecodeRuneInString(text)
+}
+
+const hexDigits = "0123456789abcdefABCDEF"
+
+// contains reports whether a character belongs to a class.
+func (c *class) contains(char rune) bool {
+	for _, bounds := range c.ranges {
+		if char >= bounds[0] && char <= bounds[1] {
+			return !c.negated
+		}
+	}
+	return c.negated
+}
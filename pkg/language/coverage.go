@@ -0,0 +1,168 @@
+package language
+
+import (
+	"sort"
+
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+// Cover recognizes a string like Recognize does and, if the string is derived
+// from a rule, marks alternatives of rules which take part in its derivations.
+// Alternatives are nodes of right-hand sides of rules as they are returned by
+// parser.Alternatives. Ambiguous strings mark alternatives of every
+// derivation. Bounds of repetitions are not taken into account while
+// derivations are traced, so alternatives could be marked excessively for
+// bounded repetitions.
+func (l *Language) Cover(
+	rule, input string, hits map[parser.Node]int,
+) (bool, error) {
+	if !l.Defines(rule) {
+		return false, ErrUndefinedRule
+	}
+
+	var m = newMatcher(l, input)
+	if !contains(m.settle(rule), len(input)) {
+		return false, nil
+	}
+
+	var t = tracer{
+		matcher: m,
+		seen:    make(map[traceKey]bool),
+		used:    make(map[parser.Node]bool),
+	}
+	t.rule(rule, 0, len(input))
+	for alt := range t.used {
+		hits[alt]++
+	}
+	return true, nil
+}
+
+// traceKey identifies a span of input which is matched by a node or by a rule
+// if node is nil.
+type traceKey struct {
+	node       parser.Node
+	rule       string
+	begin, end int
+}
+
+// tracer walks derivations of a recognized string top-down. It relies on
+// memoized results of matcher which has reached fixed point, so every span it
+// checks is already computed.
+type tracer struct {
+	*matcher
+	seen map[traceKey]bool
+	used map[parser.Node]bool
+}
+
+func (t *tracer) visit(key traceKey) bool {
+	if t.seen[key] {
+		return false
+	}
+	t.seen[key] = true
+	return true
+}
+
+func (t *tracer) rule(name string, begin, end int) {
+	if !t.visit(traceKey{rule: name, begin: begin, end: end}) {
+		return
+	}
+	for _, alt := range t.lang.rules[name] {
+		if contains(t.match(alt, begin), end) {
+			t.used[alt] = true
+			t.trace(alt, begin, end)
+		}
+	}
+}
+
+// trace descends into children of a node which matches input from begin to
+// end.
+func (t *tracer) trace(node parser.Node, begin, end int) {
+	if node == nil || !t.visit(traceKey{node: node, begin: begin, end: end}) {
+		return
+	}
+
+	switch node := node.(type) {
+	case *parser.NonTerminal:
+		if name := string(node.Name); t.lang.Defines(name) {
+			t.rule(name, begin, end)
+		}
+	case *parser.AlternativeExpression:
+		for _, child := range []parser.Node{node.Left(), node.Right()} {
+			if contains(t.match(child, begin), end) {
+				t.trace(child, begin, end)
+			}
+		}
+	case *parser.CompoundExpression:
+		for _, mid := range t.match(node.Left(), begin) {
+			if contains(t.match(node.Right(), mid), end) {
+				t.trace(node.Left(), begin, mid)
+				t.trace(node.Right(), mid, end)
+			}
+		}
+	case *parser.GroupExpression:
+		if node.Kind == parser.GroupParen {
+			t.trace(node.Left(), begin, end)
+		} else {
+			t.repeat(node.Left(), begin, end)
+		}
+	case *parser.RepetitionExpression:
+		t.repeat(node.Left(), begin, end)
+	case *parser.ExceptionExpression:
+		t.trace(node.Left(), begin, end)
+	case *parser.PredicateExpression:
+		if !node.Negative {
+			for _, stop := range t.match(node.Right(), begin) {
+				t.trace(node.Right(), begin, stop)
+			}
+		}
+	}
+}
+
+// repeat traces every step of repetition of an operand which lies on some
+// path of steps from begin to end.
+func (t *tracer) repeat(operand parser.Node, begin, end int) {
+	// Find positions which are reachable from begin and steps from them.
+	var steps = make(map[int][]int)
+	var queue = []int{begin}
+	for len(queue) > 0 {
+		var pos = queue[0]
+		queue = queue[1:]
+		if _, ok := steps[pos]; ok {
+			continue
+		}
+		steps[pos] = nil
+		for _, next := range t.match(operand, pos) {
+			if next <= end {
+				steps[pos] = append(steps[pos], next)
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	// Find positions which end is reachable from.
+	var positions = make([]int, 0, len(steps))
+	for pos := range steps {
+		positions = append(positions, pos)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(positions)))
+
+	var useful = map[int]bool{end: true}
+	for changed := true; changed; {
+		changed = false
+		for _, pos := range positions {
+			for _, next := range steps[pos] {
+				if useful[next] && !useful[pos] {
+					useful[pos], changed = true, true
+				}
+			}
+		}
+	}
+
+	for _, pos := range positions {
+		for _, next := range steps[pos] {
+			if useful[pos] && useful[next] {
+				t.trace(operand, pos, next)
+			}
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package language
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+var ErrNotExpandable = errors.New("language: symbol could not be expanded")
+
+// Symbol is a symbol of a sentential form. It is either a terminal which could
+// not be expanded or a non-terminal (rule or group of extended notations)
+// which is replaced with one of its alternatives on expansion. Repetitions
+// keep their bounds which decrease as repetitions are unrolled.
+type Symbol struct {
+	node     parser.Node
+	min, max int
+}
+
+func newSymbol(node parser.Node) Symbol {
+	var sym = Symbol{node: node}
+	if rep, ok := node.(*parser.RepetitionExpression); ok {
+		sym.min, sym.max = rep.Min, rep.Max
+	}
+	return sym
+}
+
+// String renders symbol in the same way as it is written in BNF.
+func (s Symbol) String() string {
+	if rep, ok := s.node.(*parser.RepetitionExpression); ok {
+		var bounds = strconv.Itoa(s.min) + ","
+		if s.max >= 0 {
+			bounds += strconv.Itoa(s.max)
+		}
+		var operand = renderExpr(rep.Left())
+		switch rep.Left().(type) {
+		case *parser.AlternativeExpression, *parser.CompoundExpression,
+			*parser.ExceptionExpression:
+			operand = "(" + operand + ")"
+		}
+		return operand + "{" + bounds + "}"
+	}
+	return renderExpr(s.node)
+}
+
+// renderExpr renders an expression of right-hand side in one line.
+func renderExpr(node parser.Node) string {
+	switch node := node.(type) {
+	case nil:
+		return `""`
+	case *parser.AlternativeExpression:
+		var alts []string
+		for _, alt := range parser.Alternatives(node) {
+			alts = append(alts, renderExpr(alt))
+		}
+		return strings.Join(alts, " | ")
+	case *parser.CompoundExpression:
+		return renderExpr(node.Left()) + " " + renderExpr(node.Right())
+	case *parser.GroupExpression:
+		var brackets = [...][2]string{
+			parser.GroupParen:      {"(", ")"},
+			parser.GroupOptional:   {"[", "]"},
+			parser.GroupRepetition: {"{", "}"},
+		}[node.Kind]
+		return brackets[0] + renderExpr(node.Left()) + brackets[1]
+	case *parser.RepetitionExpression:
+		return newSymbol(node).String()
+	case *parser.ExceptionExpression:
+		return renderExpr(node.Left()) + " - " + renderExpr(node.Right())
+	case *parser.PredicateExpression:
+		return string(node.Name) + renderExpr(node.Right())
+	case *parser.Wildcard:
+		return "."
+	case *parser.SpecialSequence:
+		return "?" + string(node.Name) + "?"
+	default:
+		return analysis.RenderTerm(node)
+	}
+}
+
+// Derivation is a derivation of sentential forms from a rule which is built
+// step by step. Any non-terminal of the current form could be expanded with
+// any of its alternatives, so derivations are neither leftmost nor rightmost
+// necessarily.
+type Derivation struct {
+	lang  *Language
+	forms [][]Symbol
+}
+
+// Derive starts derivation of sentential forms from a rule. The first form
+// consists of the rule only.
+func (l *Language) Derive(rule string) (*Derivation, error) {
+	if !l.Defines(rule) {
+		return nil, ErrUndefinedRule
+	}
+	var start = &parser.NonTerminal{Token: parser.Token{Name: []byte(rule)}}
+	return &Derivation{lang: l, forms: [][]Symbol{{newSymbol(start)}}}, nil
+}
+
+// Form returns the current sentential form.
+func (d *Derivation) Form() []Symbol {
+	return d.forms[len(d.forms)-1]
+}
+
+// Forms returns all sentential forms from the first one to the current one.
+func (d *Derivation) Forms() [][]Symbol {
+	return d.forms
+}
+
+// Choices returns alternatives which a symbol of the current form could be
+// replaced with. Every alternative is a possibly empty sequence of symbols.
+// It returns nil if symbol is a terminal.
+func (d *Derivation) Choices(idx int) [][]Symbol {
+	var form = d.Form()
+	if idx < 0 || idx >= len(form) {
+		return nil
+	}
+
+	var sym = form[idx]
+	switch node := sym.node.(type) {
+	case *parser.NonTerminal:
+		var choices [][]Symbol
+		for _, alt := range d.lang.rules[string(node.Name)] {
+			choices = append(choices, symbols(alt))
+		}
+		return choices
+	case *parser.GroupExpression:
+		switch node.Kind {
+		case parser.GroupOptional:
+			return [][]Symbol{nil, symbols(node.Left())}
+		case parser.GroupRepetition:
+			return [][]Symbol{nil, append(symbols(node.Left()), sym)}
+		default:
+			var choices [][]Symbol
+			for _, alt := range parser.Alternatives(node.Left()) {
+				choices = append(choices, symbols(alt))
+			}
+			return choices
+		}
+	case *parser.RepetitionExpression:
+		// Repetition is unrolled once and the rest keeps decreased bounds.
+		var rest = sym
+		if rest.min > 0 {
+			rest.min--
+		}
+		if rest.max > 0 {
+			rest.max--
+		}
+		var once = symbols(node.Left())
+		if rest.max != 0 {
+			once = append(once, rest)
+		}
+		if sym.min > 0 {
+			return [][]Symbol{once}
+		} else if sym.max == 0 {
+			return [][]Symbol{nil}
+		}
+		return [][]Symbol{nil, once}
+	default:
+		return nil
+	}
+}
+
+// Expand replaces a symbol of the current form with one of its alternatives
+// (see Choices) and makes the result the current form.
+func (d *Derivation) Expand(idx, choice int) error {
+	var choices = d.Choices(idx)
+	if choice < 0 || choice >= len(choices) {
+		return ErrNotExpandable
+	}
+
+	var form = d.Form()
+	var next = make([]Symbol, 0, len(form)+len(choices[choice]))
+	next = append(next, form[:idx]...)
+	next = append(next, choices[choice]...)
+	next = append(next, form[idx+1:]...)
+	d.forms = append(d.forms, next)
+	return nil
+}
+
+// Undo reverts the last expansion. It reports false if there is nothing to
+// revert.
+func (d *Derivation) Undo() bool {
+	if len(d.forms) == 1 {
+		return false
+	}
+	d.forms = d.forms[:len(d.forms)-1]
+	return true
+}
+
+// symbols splits an alternative into symbols. Empty alternatives have no
+// symbols at all while groups of a single alternative are unwrapped.
+func symbols(node parser.Node) []Symbol {
+	switch node := node.(type) {
+	case nil, *parser.Epsilon:
+		return nil
+	case *parser.CompoundExpression:
+		return append(symbols(node.Left()), symbols(node.Right())...)
+	case *parser.GroupExpression:
+		// Parentheses around a sequence are of no use in sentential form.
+		var _, choice = node.Left().(*parser.AlternativeExpression)
+		if node.Kind == parser.GroupParen && !choice {
+			return symbols(node.Left())
+		}
+	case *parser.SpecialSequence:
+		if len(node.Name) == 0 || string(node.Name) == "%empty" {
+			return nil
+		}
+	}
+	return []Symbol{newSymbol(node)}
+}
@@ -0,0 +1,201 @@
+package language
+
+import (
+	"errors"
+	"math/rand"
+	"strings"
+
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+var ErrInfiniteRule = errors.New("language: rule derives no finite strings")
+
+// wildcardChars are characters which substitute wildcards and negations.
+const wildcardChars = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+// maxExtraRepeats limits number of optional repetitions of unbounded
+// repetitions.
+const maxExtraRepeats = 2
+
+// maxAttempts limits number of retries of exceptions and negated classes.
+const maxAttempts = 16
+
+// Generate derives a random string from a rule. Depth of derivation tree is
+// limited with depth, so rules are expanded with their shortest alternatives
+// once the limit is reached. Syntactic predicates are not taken into account,
+// so generated strings of PEG should be checked with recognizer.
+func (l *Language) Generate(
+	rule string, rng *rand.Rand, depth int,
+) (string, error) {
+	if !l.Defines(rule) {
+		return "", ErrUndefinedRule
+	} else if l.heights[rule] == infinity {
+		return "", ErrInfiniteRule
+	}
+
+	var gen = generator{lang: l, rng: rng}
+	gen.rule(rule, depth)
+	return gen.builder.String(), nil
+}
+
+type generator struct {
+	lang    *Language
+	rng     *rand.Rand
+	builder strings.Builder
+}
+
+func (g *generator) rule(name string, budget int) {
+	g.choose(g.lang.rules[name], budget-1)
+}
+
+// choose expands one of alternatives which fit into depth budget. The
+// shallowest alternatives are used if none fits.
+func (g *generator) choose(alts []parser.Node, budget int) {
+	var fit []parser.Node
+	var min = infinity
+	for _, alt := range alts {
+		var height = g.lang.height(alt)
+		if height <= budget {
+			fit = append(fit, alt)
+		}
+		if height < min {
+			min = height
+		}
+	}
+
+	if len(fit) == 0 {
+		for _, alt := range alts {
+			if g.lang.height(alt) == min {
+				fit = append(fit, alt)
+			}
+		}
+	}
+
+	if len(fit) > 0 {
+		g.expand(g.sample(fit), budget)
+	}
+}
+
+// sample chooses an alternative at random with probability which is
+// proportional to its weight. Alternatives are equiprobable if all of them
+// have zero weights.
+func (g *generator) sample(alts []parser.Node) parser.Node {
+	var total float64
+	for _, alt := range alts {
+		total += g.lang.weight(alt)
+	}
+	if total <= 0 {
+		return alts[g.rng.Intn(len(alts))]
+	}
+
+	var threshold = g.rng.Float64() * total
+	for _, alt := range alts {
+		if threshold -= g.lang.weight(alt); threshold < 0 {
+			return alt
+		}
+	}
+	return alts[len(alts)-1]
+}
+
+func (g *generator) expand(node parser.Node, budget int) {
+	switch node := node.(type) {
+	case *parser.Terminal:
+		g.builder.WriteString(g.lang.literal(node))
+	case *parser.NonTerminal:
+		if name := string(node.Name); g.lang.Defines(name) {
+			g.rule(name, budget)
+		} else {
+			g.builder.WriteString(name)
+		}
+	case *parser.CharacterClass:
+		g.builder.WriteRune(g.pick(parseClass(string(node.Name))))
+	case *parser.Wildcard:
+		g.builder.WriteByte(wildcardChars[g.rng.Intn(len(wildcardChars))])
+	case *parser.AlternativeExpression:
+		g.choose(parser.Alternatives(node), budget)
+	case *parser.CompoundExpression:
+		g.expand(node.Left(), budget)
+		g.expand(node.Right(), budget)
+	case *parser.GroupExpression:
+		switch node.Kind {
+		case parser.GroupOptional:
+			g.repeat(node.Left(), budget, 0, 1)
+		case parser.GroupRepetition:
+			g.repeat(node.Left(), budget, 0, -1)
+		default:
+			g.expand(node.Left(), budget)
+		}
+	case *parser.RepetitionExpression:
+		g.repeat(node.Left(), budget, node.Min, node.Max)
+	case *parser.ExceptionExpression:
+		g.except(node, budget)
+	}
+}
+
+// repeat expands an operand from min to max times. Number of repetitions is
+// uniform within bounds while unbounded repetitions add at most
+// maxExtraRepeats ones to min. Optional repetitions are skipped if they do not
+// fit into depth budget.
+func (g *generator) repeat(operand parser.Node, budget, min, max int) {
+	var count = min
+	if g.lang.height(operand) <= budget {
+		if max < 0 {
+			count += g.rng.Intn(maxExtraRepeats + 1)
+		} else if max > min {
+			count += g.rng.Intn(max - min + 1)
+		}
+	}
+	for idx := 0; idx < count; idx++ {
+		g.expand(operand, budget)
+	}
+}
+
+// except expands the left operand until result is not matched by the right
+// one. The last attempt is kept if all of them fail.
+func (g *generator) except(node *parser.ExceptionExpression, budget int) {
+	var prefix = g.builder.String()
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		g.builder.Reset()
+		if node.Left() == nil {
+			var idx = g.rng.Intn(len(wildcardChars))
+			g.builder.WriteByte(wildcardChars[idx])
+		} else {
+			g.expand(node.Left(), budget)
+		}
+
+		var value = g.builder.String()
+		var m = newMatcher(g.lang, value)
+		if !contains(m.match(node.Right(), 0), len(value)) {
+			break
+		}
+	}
+
+	var value = g.builder.String()
+	g.builder.Reset()
+	g.builder.WriteString(prefix + value)
+}
+
+// pick chooses a random character of a class. Printable ASCII characters are
+// preferred.
+func (g *generator) pick(cls *class) rune {
+	if cls.negated || len(cls.ranges) == 0 {
+		var char rune
+		for attempt := 0; attempt < maxAttempts; attempt++ {
+			char = rune(wildcardChars[g.rng.Intn(len(wildcardChars))])
+			if cls.contains(char) {
+				break
+			}
+		}
+		return char
+	}
+
+	var bounds = cls.ranges[g.rng.Intn(len(cls.ranges))]
+	var lo, hi = bounds[0], bounds[1]
+	if lo < ' ' && hi >= ' ' {
+		lo = ' '
+	}
+	if hi > '~' && lo <= '~' {
+		hi = '~'
+	}
+	return lo + rune(g.rng.Int63n(int64(hi-lo+1)))
+}
@@ -0,0 +1,170 @@
+// Package language generates strings of a language which is defined with a
+// grammar and recognizes whether strings belong to the language.
+package language
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+var ErrUndefinedRule = errors.New("language: rule is not defined")
+
+// Language is a set of strings which are derived from rules of a grammar.
+// Non-terminals without definitions (e.g. tokens of Yacc) are opaque tokens
+// which stand for their own names. Alternatives of rules are chosen by
+// generator according to their weights.
+type Language struct {
+	dialect parser.Dialect
+	rules   map[string][]parser.Node
+	heights map[string]int
+	weights map[parser.Node]float64
+}
+
+// New creates language of a grammar written in some dialect. Rules with the
+// same name are merged as alternatives. Weights of alternatives are taken
+// from `@weight` annotations of rules.
+func New(dialect parser.Dialect, grammar *analysis.Grammar) *Language {
+	var lang = &Language{
+		dialect: dialect,
+		rules:   make(map[string][]parser.Node),
+		heights: make(map[string]int),
+		weights: make(map[parser.Node]float64),
+	}
+
+	for _, rule := range grammar.Rules {
+		var alts = parser.Alternatives(rule.Statement.Rule.Right())
+		for idx, alt := range alts {
+			if weight := rule.Weight(idx); weight != 1 {
+				lang.weights[alt] = weight
+			}
+		}
+		lang.rules[rule.Name] = append(lang.rules[rule.Name], alts...)
+	}
+
+	lang.computeHeights()
+	return lang
+}
+
+// Defines reports whether there is a rule with the name.
+func (l *Language) Defines(rule string) bool {
+	var _, ok = l.rules[rule]
+	return ok
+}
+
+// weight returns weight of an alternative. It is 1 unless alternative is
+// annotated.
+func (l *Language) weight(alt parser.Node) float64 {
+	if weight, ok := l.weights[alt]; ok {
+		return weight
+	}
+	return 1
+}
+
+// infinity is a height of rules which derive no finite strings.
+const infinity = 1 << 30
+
+// computeHeights finds minimal depth of derivation tree for every rule. It is
+// used to terminate derivations during generation.
+func (l *Language) computeHeights() {
+	for name := range l.rules {
+		l.heights[name] = infinity
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for name, alts := range l.rules {
+			for _, alt := range alts {
+				if height := l.height(alt); height < l.heights[name] {
+					l.heights[name] = height
+					changed = true
+				}
+			}
+		}
+	}
+}
+
+// height returns minimal depth of derivation tree of an expression.
+func (l *Language) height(node parser.Node) int {
+	switch node := node.(type) {
+	case *parser.NonTerminal:
+		if height, ok := l.heights[string(node.Name)]; !ok {
+			return 0
+		} else if height == infinity {
+			return infinity
+		} else {
+			return height + 1
+		}
+	case *parser.AlternativeExpression:
+		var left, right = l.height(node.Left()), l.height(node.Right())
+		if left < right {
+			return left
+		}
+		return right
+	case *parser.CompoundExpression:
+		var left, right = l.height(node.Left()), l.height(node.Right())
+		if left > right {
+			return left
+		}
+		return right
+	case *parser.GroupExpression:
+		if node.Kind != parser.GroupParen {
+			return 0
+		}
+		return l.height(node.Left())
+	case *parser.RepetitionExpression:
+		if node.Min == 0 {
+			return 0
+		}
+		return l.height(node.Left())
+	case *parser.ExceptionExpression:
+		return l.height(node.Left())
+	default:
+		return 0
+	}
+}
+
+// literal returns value of a terminal. Escape sequences are resolved for
+// dialects with C-like literals. Tokens of Yacc which are declared with
+// identifiers stand for their names.
+func (l *Language) literal(term *parser.Terminal) string {
+	var value = string(term.Name)
+	switch l.dialect {
+	case parser.DialectYacc:
+		// Quoted literal spans two characters more than its value.
+		if term.End-term.Begin != len(term.Name)+2 {
+			return value
+		}
+		return unescape(value)
+	case parser.DialectANTLR, parser.DialectPEG:
+		return unescape(value)
+	default:
+		return value
+	}
+}
+
+// escapes maps characters of escape sequences like in C to their values.
+var escapes = map[byte]byte{
+	'n': '\n', 'r': '\r', 't': '\t', '\\': '\\', '\'': '\'', '"': '"',
+	']': ']', '-': '-', '^': '^',
+}
+
+// unescape resolves escape sequences like in C. Unknown escape sequences are
+// kept as is.
+func unescape(literal string) string {
+	var builder strings.Builder
+	for idx := 0; idx < len(literal); idx++ {
+		var char = literal[idx]
+		if char == '\\' && idx+1 < len(literal) {
+			if value, ok := escapes[literal[idx+1]]; ok {
+				builder.WriteByte(value)
+				idx++
+				continue
+			}
+		}
+		builder.WriteByte(char)
+	}
+	return builder.String()
+}
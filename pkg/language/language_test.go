@@ -0,0 +1,239 @@
+package language
+
+import (
+	"bytes"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+func newLanguage(dialect parser.Dialect, source string) *Language {
+	var lines = bytes.Split([]byte(source), []byte{'\n'})
+	return New(dialect, analysis.NewGrammar(dialect, lines))
+}
+
+func TestRecognize(t *testing.T) {
+	var testCases = []struct {
+		name    string
+		dialect parser.Dialect
+		source  string
+		rule    string
+		valid   []string
+		invalid []string
+	}{
+		{
+			name:    "LeftRecursion",
+			dialect: parser.DialectBNF,
+			source: "<expr> ::= <term> | <expr> \"+\" <term>\n" +
+				"<term> ::= \"x\" | \"(\" <expr> \")\"",
+			rule:    "expr",
+			valid:   []string{"x", "x+x", "(x+x)+x", "((x))"},
+			invalid: []string{"", "x+", "(x", "xx"},
+		},
+		{
+			name:    "Epsilon",
+			dialect: parser.DialectBNF,
+			source: "<list> ::= \"a\" <list> | ε\n" +
+				"<opt> ::= \"b\" | <empty>\n" +
+				"<both> ::= <list> <opt> | \"\"",
+			rule:    "both",
+			valid:   []string{"", "a", "aab", "b"},
+			invalid: []string{"ba", "bb"},
+		},
+		{
+			name:    "Repetition",
+			dialect: parser.DialectW3C,
+			source:  `list ::= [a-z]+ ("," [a-z]+)* ";"?`,
+			rule:    "list",
+			valid:   []string{"a", "ab,c", "a,b;"},
+			invalid: []string{"", ",a", "a,", "A"},
+		},
+		{
+			name:    "Exception",
+			dialect: parser.DialectW3C,
+			source:  `name ::= [a-z]+ - 'if'`,
+			rule:    "name",
+			valid:   []string{"i", "iff", "x"},
+			invalid: []string{"if"},
+		},
+		{
+			name:    "Predicate",
+			dialect: parser.DialectPEG,
+			source:  `Word <- !'\n' [a-c] .`,
+			rule:    "Word",
+			valid:   []string{"ab", "c\n"},
+			invalid: []string{"a", "\nb"},
+		},
+		{
+			name:    "Tokens",
+			dialect: parser.DialectYacc,
+			source:  "%%\nsum : NUM | sum '+' NUM ;",
+			rule:    "sum",
+			valid:   []string{"NUM", "NUM+NUM"},
+			invalid: []string{"1+2"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			var lang = newLanguage(test.dialect, test.source)
+			for _, input := range test.valid {
+				if ok, err := lang.Recognize(test.rule, input); err != nil {
+					t.Fatalf("failed to recognize: %s", err)
+				} else if !ok {
+					t.Errorf("string is not recognized: %q", input)
+				}
+			}
+			for _, input := range test.invalid {
+				if ok, _ := lang.Recognize(test.rule, input); ok {
+					t.Errorf("string is recognized: %q", input)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	var lang = newLanguage(parser.DialectBNF,
+		"<expr> ::= <term> | <expr> \"+\" <term>\n"+
+			"<term> ::= \"x\" | \"(\" <expr> \")\"\n"+
+			"<loop> ::= <loop> \"x\"")
+
+	var rng = rand.New(rand.NewSource(42))
+	for idx := 0; idx < 32; idx++ {
+		var value, err = lang.Generate("expr", rng, 8)
+		if err != nil {
+			t.Fatalf("failed to generate: %s", err)
+		}
+		if ok, _ := lang.Recognize("expr", value); !ok {
+			t.Errorf("generated string is not recognized: %q", value)
+		}
+	}
+
+	if _, err := lang.Generate("loop", rng, 8); err != ErrInfiniteRule {
+		t.Errorf("wrong error for infinite rule: %v", err)
+	}
+	if _, err := lang.Generate("none", rng, 8); err != ErrUndefinedRule {
+		t.Errorf("wrong error for undefined rule: %v", err)
+	}
+}
+
+func TestGenerateWeights(t *testing.T) {
+	var lang = newLanguage(parser.DialectBNF,
+		"; @weight 0\n"+
+			"<s> ::= \"a\"\n"+
+			"<s> ::= \"b\" | \"c\"\n"+
+			"; @weight 1 0\n"+
+			"<t> ::= \"x\" | \"y\"")
+
+	var rng = rand.New(rand.NewSource(42))
+	for idx := 0; idx < 32; idx++ {
+		if value, _ := lang.Generate("s", rng, 8); value == "a" {
+			t.Errorf("alternative of zero weight is chosen")
+		}
+		if value, _ := lang.Generate("t", rng, 8); value != "x" {
+			t.Errorf("alternative of zero weight is chosen: %q", value)
+		}
+	}
+}
+
+func TestGenerateBounds(t *testing.T) {
+	var lang = newLanguage(parser.DialectW3C, `digits ::= [0-9]{3,5}`)
+	var rng = rand.New(rand.NewSource(42))
+	var lengths = make(map[int]bool)
+	for idx := 0; idx < 64; idx++ {
+		var value, err = lang.Generate("digits", rng, 8)
+		if err != nil {
+			t.Fatalf("failed to generate: %s", err)
+		}
+		lengths[len(value)] = true
+	}
+
+	var expected = map[int]bool{3: true, 4: true, 5: true}
+	if !reflect.DeepEqual(lengths, expected) {
+		t.Errorf("wrong lengths of generated strings: %v", lengths)
+	}
+}
+
+func TestCover(t *testing.T) {
+	var source = "<expr> ::= <term> | <expr> \"+\" <term>\n" +
+		"<term> ::= \"x\" | \"(\" <expr> \")\" | \"y\""
+	var lines = bytes.Split([]byte(source), []byte{'\n'})
+	var grammar = analysis.NewGrammar(parser.DialectBNF, lines)
+	var lang = New(parser.DialectBNF, grammar)
+
+	var hits = make(map[parser.Node]int)
+	for _, input := range []string{"x", "x+(x)", "x+x"} {
+		if ok, err := lang.Cover("expr", input, hits); err != nil || !ok {
+			t.Fatalf("failed to cover %q: %v", input, err)
+		}
+	}
+	if ok, _ := lang.Cover("expr", "x+", hits); ok {
+		t.Errorf("invalid input is recognized")
+	}
+
+	var expected = [][]int{{3, 2}, {3, 1, 0}}
+	for idx, counts := range expected {
+		var rhs = grammar.Rules[idx].Statement.Rule.Right()
+		for alt, node := range parser.Alternatives(rhs) {
+			if hits[node] != counts[alt] {
+				t.Errorf("wrong hits of alternative %d of <%s>: %d",
+					alt+1, grammar.Rules[idx].Name, hits[node])
+			}
+		}
+	}
+}
+
+func TestDerive(t *testing.T) {
+	var lang = newLanguage(parser.DialectW3C,
+		"expr ::= term ('+' term)*\n"+
+			"term ::= 'x' | digit{2}\n"+
+			"digit ::= [0-9]")
+
+	var derivation, err = lang.Derive("expr")
+	if err != nil {
+		t.Fatalf("failed to derive: %s", err)
+	}
+
+	var render = func() string {
+		var texts []string
+		for _, symbol := range derivation.Form() {
+			texts = append(texts, symbol.String())
+		}
+		return strings.Join(texts, " ")
+	}
+
+	var steps = []struct {
+		idx, choice int
+		form        string
+	}{
+		{0, 0, `<term> ("+" <term>){0,}`},
+		{1, 1, `<term> "+" <term> ("+" <term>){0,}`},
+		{3, 0, `<term> "+" <term>`},
+		{2, 1, `<term> "+" <digit>{2,2}`},
+		{2, 0, `<term> "+" <digit> <digit>{1,1}`},
+		{3, 0, `<term> "+" <digit> <digit>`},
+	}
+	for _, step := range steps {
+		if err := derivation.Expand(step.idx, step.choice); err != nil {
+			t.Fatalf("failed to expand %d: %s", step.idx, err)
+		}
+		if form := render(); form != step.form {
+			t.Fatalf("wrong form: %s", form)
+		}
+	}
+
+	if err := derivation.Expand(1, 0); err != ErrNotExpandable {
+		t.Errorf("terminal is expanded: %v", err)
+	}
+	if !derivation.Undo() || render() != `<term> "+" <digit> <digit>{1,1}` {
+		t.Errorf("wrong form after undo: %s", render())
+	}
+	if len(derivation.Forms()) != 6 {
+		t.Errorf("wrong number of forms: %d", len(derivation.Forms()))
+	}
+}
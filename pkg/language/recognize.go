@@ -0,0 +1,240 @@
+package language
+
+import (
+	"unicode/utf8"
+
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+// Recognize reports whether a string is derived from a rule. Grammar is
+// treated as context-free one, so ordered choice of PEG is not distinguished
+// from alternative. Left recursion is allowed.
+func (l *Language) Recognize(rule, input string) (bool, error) {
+	if !l.Defines(rule) {
+		return false, ErrUndefinedRule
+	}
+
+	var m = newMatcher(l, input)
+	return contains(m.settle(rule), len(input)), nil
+}
+
+type memoKey struct {
+	rule string
+	pos  int
+}
+
+// matcher finds all positions where an expression which starts at some
+// position could end. Results of rules are memoized and they grow on every
+// pass until fixed point is reached which makes left recursion terminate.
+type matcher struct {
+	lang    *Language
+	input   string
+	memo    map[memoKey][]int
+	visited map[memoKey]bool
+	changed bool
+}
+
+func newMatcher(lang *Language, input string) *matcher {
+	return &matcher{
+		lang:  lang,
+		input: input,
+		memo:  make(map[memoKey][]int),
+	}
+}
+
+// start makes a pass over input from its beginning.
+func (m *matcher) start(rule string) []int {
+	m.visited = make(map[memoKey]bool)
+	m.changed = false
+	return m.rule(rule, 0)
+}
+
+// settle makes passes over input until results of rules do not change and
+// returns positions where a rule which starts at the beginning could end.
+func (m *matcher) settle(rule string) []int {
+	for {
+		var ends = m.start(rule)
+		if !m.changed {
+			return ends
+		}
+	}
+}
+
+func (m *matcher) rule(name string, pos int) []int {
+	var key = memoKey{name, pos}
+	if m.visited[key] {
+		return m.memo[key]
+	}
+	m.visited[key] = true
+
+	var ends []int
+	for _, alt := range m.lang.rules[name] {
+		ends = union(ends, m.match(alt, pos))
+	}
+
+	if ends = union(m.memo[key], ends); len(ends) > len(m.memo[key]) {
+		m.memo[key] = ends
+		m.changed = true
+	}
+	return ends
+}
+
+func (m *matcher) match(node parser.Node, pos int) []int {
+	switch node := node.(type) {
+	case nil:
+		return []int{pos}
+	case *parser.Terminal:
+		return m.literal(m.lang.literal(node), pos)
+	case *parser.NonTerminal:
+		if name := string(node.Name); m.lang.Defines(name) {
+			return m.rule(name, pos)
+		} else {
+			return m.literal(name, pos)
+		}
+	case *parser.CharacterClass:
+		var cls = parseClass(string(node.Name))
+		return m.char(pos, cls.contains)
+	case *parser.Wildcard:
+		return m.char(pos, func(rune) bool { return true })
+	case *parser.Epsilon:
+		return []int{pos}
+	case *parser.SpecialSequence:
+		if len(node.Name) == 0 || string(node.Name) == "%empty" {
+			return []int{pos}
+		}
+		return nil
+	case *parser.AlternativeExpression:
+		return union(m.match(node.Left(), pos), m.match(node.Right(), pos))
+	case *parser.CompoundExpression:
+		var ends []int
+		for _, end := range m.match(node.Left(), pos) {
+			ends = union(ends, m.match(node.Right(), end))
+		}
+		return ends
+	case *parser.GroupExpression:
+		switch node.Kind {
+		case parser.GroupOptional:
+			return m.repeat(node.Left(), pos, 0, 1)
+		case parser.GroupRepetition:
+			return m.repeat(node.Left(), pos, 0, -1)
+		default:
+			return m.match(node.Left(), pos)
+		}
+	case *parser.RepetitionExpression:
+		return m.repeat(node.Left(), pos, node.Min, node.Max)
+	case *parser.ExceptionExpression:
+		return m.except(node, pos)
+	case *parser.PredicateExpression:
+		if matched := len(m.match(node.Right(), pos)) > 0; matched {
+			if !node.Negative {
+				return []int{pos}
+			}
+		} else if node.Negative {
+			return []int{pos}
+		}
+		return nil
+	default:
+		return nil
+	}
+}
+
+func (m *matcher) literal(value string, pos int) []int {
+	var end = pos + len(value)
+	if end <= len(m.input) && m.input[pos:end] == value {
+		return []int{end}
+	}
+	return nil
+}
+
+// char matches a single character which satisfies predicate.
+func (m *matcher) char(pos int, pred func(rune) bool) []int {
+	if pos >= len(m.input) {
+		return nil
+	}
+	var char, size = utf8.DecodeRuneInString(m.input[pos:])
+	if !pred(char) {
+		return nil
+	}
+	return []int{pos + size}
+}
+
+// repeat matches an operand from min to max times. Max is negative if there is
+// no upper bound.
+func (m *matcher) repeat(operand parser.Node, pos, min, max int) []int {
+	var ends []int
+	if min == 0 {
+		ends = []int{pos}
+	}
+
+	var seen = map[int]bool{pos: true}
+	var current = []int{pos}
+	for count := 1; max < 0 || count <= max; count++ {
+		var next []int
+		for _, begin := range current {
+			next = union(next, m.match(operand, begin))
+		}
+
+		if count >= min {
+			ends = union(ends, next)
+		}
+
+		// Positions which are reached again could not produce new matches
+		// once the lower bound is passed.
+		current = current[:0]
+		for _, end := range next {
+			if !seen[end] || count < min {
+				seen[end] = true
+				current = append(current, end)
+			}
+		}
+		if len(current) == 0 {
+			break
+		}
+	}
+	return ends
+}
+
+// except matches strings of the left operand which are not matched by the
+// right one. Negation without left operand matches a single character.
+func (m *matcher) except(node *parser.ExceptionExpression, pos int) []int {
+	var candidates []int
+	if node.Left() == nil {
+		candidates = m.char(pos, func(rune) bool { return true })
+	} else {
+		candidates = m.match(node.Left(), pos)
+	}
+
+	var excluded = m.match(node.Right(), pos)
+	var ends []int
+	for _, end := range candidates {
+		if !contains(excluded, end) {
+			ends = append(ends, end)
+		}
+	}
+	return ends
+}
+
+// union merges two sorted sets of positions.
+func union(a, b []int) []int {
+	var merged = make([]int, 0, len(a)+len(b))
+	for len(a) > 0 || len(b) > 0 {
+		switch {
+		case len(b) == 0 || len(a) > 0 && a[0] < b[0]:
+			merged, a = append(merged, a[0]), a[1:]
+		case len(a) == 0 || b[0] < a[0]:
+			merged, b = append(merged, b[0]), b[1:]
+		default:
+			merged, a, b = append(merged, a[0]), a[1:], b[1:]
+		}
+	}
+	return merged
+}
+
+func contains(positions []int, pos int) bool {
+	for _, p := range positions {
+		if p == pos {
+			return true
+		}
+	}
+	return false
+}
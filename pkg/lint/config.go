@@ -0,0 +1,222 @@
+package lint
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ConfigName is a name of configuration file which is looked up in the
+// directory of a grammar and its parents.
+const ConfigName = ".bnflint.toml"
+
+// Config is a configuration of linter. Start is a name of start rule which is
+// the start symbol of grammar if it is empty. Other options are grouped by
+// rules which they belong to.
+type Config struct {
+	Start    string
+	sections map[string]Options
+}
+
+// NewConfig returns configuration where all rules are enabled with default
+// options.
+func NewConfig() *Config {
+	return &Config{sections: make(map[string]Options)}
+}
+
+// Options returns options of a rule. It is never nil.
+func (c *Config) Options(rule string) Options {
+	if opts, ok := c.sections[rule]; ok {
+		return opts
+	}
+	return Options{}
+}
+
+// Options are values of a section of configuration. Values are strings,
+// integers, booleans, or lists of them.
+type Options map[string]interface{}
+
+// String returns string option or default value if it is not set.
+func (o Options) String(key, def string) string {
+	if value, ok := o[key].(string); ok {
+		return value
+	}
+	return def
+}
+
+// Int returns integer option or default value if it is not set.
+func (o Options) Int(key string, def int) int {
+	if value, ok := o[key].(int); ok {
+		return value
+	}
+	return def
+}
+
+// Bool returns boolean option or default value if it is not set.
+func (o Options) Bool(key string, def bool) bool {
+	if value, ok := o[key].(bool); ok {
+		return value
+	}
+	return def
+}
+
+// FindConfig looks for configuration file in a directory and its parents. It
+// returns empty string if there is no configuration file.
+func FindConfig(dir string) string {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+
+	for {
+		var filename = filepath.Join(dir, ConfigName)
+		if info, err := os.Stat(filename); err == nil && !info.IsDir() {
+			return filename
+		}
+
+		var parent = filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// LoadConfig reads configuration from a file.
+func LoadConfig(filename string) (*Config, error) {
+	var data, err = ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := ParseConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("lint: invalid config %s: %s", filename, err)
+	}
+	return cfg, nil
+}
+
+// ParseConfig parses configuration in TOML. Only a subset of TOML is
+// supported: tables, comments, and key-value pairs where values are strings,
+// integers, booleans, or single-line arrays of them.
+func ParseConfig(data []byte) (*Config, error) {
+	var cfg = NewConfig()
+	var section = ""
+	for idx, line := range bytes.Split(data, []byte{'\n'}) {
+		var text = strings.TrimSpace(stripComment(string(line)))
+		switch {
+		case text == "":
+			continue
+		case strings.HasPrefix(text, "["):
+			if !strings.HasSuffix(text, "]") {
+				return nil, fmt.Errorf("line %d: unclosed table", idx+1)
+			}
+			section = strings.TrimSpace(text[1 : len(text)-1])
+			if section == "" {
+				return nil, fmt.Errorf("line %d: empty table name", idx+1)
+			}
+			continue
+		}
+
+		var eq = strings.IndexByte(text, '=')
+		if eq < 0 {
+			return nil, fmt.Errorf("line %d: '=' is expected", idx+1)
+		}
+
+		var key = strings.Trim(strings.TrimSpace(text[:eq]), `"`)
+		var value, err = parseValue(strings.TrimSpace(text[eq+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", idx+1, err)
+		}
+
+		if cfg.sections[section] == nil {
+			cfg.sections[section] = Options{}
+		}
+		cfg.sections[section][key] = value
+	}
+
+	cfg.Start = cfg.Options("").String("start", "")
+	return cfg, nil
+}
+
+func parseValue(text string) (interface{}, error) {
+	switch {
+	case text == "":
+		return nil, fmt.Errorf("value is expected")
+	case text == "true":
+		return true, nil
+	case text == "false":
+		return false, nil
+	case text[0] == '"':
+		return strconv.Unquote(text)
+	case text[0] == '\'':
+		if len(text) < 2 || text[len(text)-1] != '\'' {
+			return nil, fmt.Errorf("unclosed string: %s", text)
+		}
+		return text[1 : len(text)-1], nil
+	case text[0] == '[':
+		if text[len(text)-1] != ']' {
+			return nil, fmt.Errorf("unclosed array: %s", text)
+		}
+		var values = []interface{}{}
+		for _, item := range splitItems(text[1 : len(text)-1]) {
+			var value, err = parseValue(item)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	}
+
+	var value, err = strconv.Atoi(strings.Replace(text, "_", "", -1))
+	if err != nil {
+		return nil, fmt.Errorf("invalid value: %s", text)
+	}
+	return value, nil
+}
+
+// splitItems splits items of an array by commas which are not quoted. Empty
+// items are dropped, so trailing comma is allowed.
+func splitItems(text string) []string {
+	var items []string
+	var quote byte
+	var begin int
+	for idx := 0; idx <= len(text); idx++ {
+		switch {
+		case idx == len(text) || quote == 0 && text[idx] == ',':
+			if item := strings.TrimSpace(text[begin:idx]); item != "" {
+				items = append(items, item)
+			}
+			begin = idx + 1
+		case quote != 0 && text[idx] == quote:
+			quote = 0
+		case quote == 0 && (text[idx] == '"' || text[idx] == '\''):
+			quote = text[idx]
+		case quote == '"' && text[idx] == '\\':
+			idx++
+		}
+	}
+	return items
+}
+
+// stripComment removes comment which starts with '#' outside of strings.
+func stripComment(line string) string {
+	var quote byte
+	for idx := 0; idx < len(line); idx++ {
+		switch {
+		case quote != 0 && line[idx] == quote:
+			quote = 0
+		case quote == 0 && (line[idx] == '"' || line[idx] == '\''):
+			quote = line[idx]
+		case quote == '"' && line[idx] == '\\':
+			idx++
+		case quote == 0 && line[idx] == '#':
+			return line[:idx]
+		}
+	}
+	return line
+}
@@ -0,0 +1,89 @@
+package lint
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseConfig(t *testing.T) {
+	var data = []byte(`# Lint configuration.
+start = "grammar"
+
+[naming]
+pattern = '^[a-z#]+$' # Names are lowercase.
+
+[max-alternatives]
+limit = 1_000
+enabled = true
+exclude = ["a, b", 'c',]
+`)
+
+	var cfg, err = ParseConfig(data)
+	if err != nil {
+		t.Fatalf("failed to parse config: %s", err)
+	}
+
+	if cfg.Start != "grammar" {
+		t.Errorf("wrong start rule: %q", cfg.Start)
+	}
+
+	if value := cfg.Options("naming").String("pattern", ""); value !=
+		"^[a-z#]+$" {
+		t.Errorf("wrong pattern: %q", value)
+	}
+
+	var opts = cfg.Options("max-alternatives")
+	if limit := opts.Int("limit", 0); limit != 1000 {
+		t.Errorf("wrong limit: %d", limit)
+	}
+	if !opts.Bool("enabled", false) {
+		t.Errorf("rule is not enabled")
+	}
+	if items, ok := opts["exclude"].([]interface{}); !ok || len(items) != 2 {
+		t.Errorf("wrong array: %v", opts["exclude"])
+	} else if items[0] != "a, b" || items[1] != "c" {
+		t.Errorf("wrong items of array: %v", items)
+	}
+
+	if value := cfg.Options("unknown").Int("limit", 7); value != 7 {
+		t.Errorf("default value is not used: %d", value)
+	}
+}
+
+func TestParseConfigError(t *testing.T) {
+	for _, data := range []string{
+		"[naming",
+		"limit",
+		"limit = ",
+		"limit = ten",
+		"pattern = 'abc",
+	} {
+		if _, err := ParseConfig([]byte(data)); err == nil {
+			t.Errorf("error is expected for %q", data)
+		}
+	}
+}
+
+func TestFindConfig(t *testing.T) {
+	var root, err = ioutil.TempDir("", "bnflint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	var dir = filepath.Join(root, "a", "b")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	var filename = filepath.Join(root, "a", ConfigName)
+	if err := ioutil.WriteFile(filename, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if found := FindConfig(dir); found != filename {
+		t.Errorf("wrong config is found: %q", found)
+	}
+}
@@ -0,0 +1,167 @@
+package lint
+
+import (
+	"bytes"
+
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+// Fixer is a lint rule which findings could be fixed automatically. It checks
+// layout of text of a document rather than its grammar, so LintSource calls
+// Fix instead of Check.
+type Fixer interface {
+	Rule
+	Fix(doc *Document, opts Options) []Fix
+}
+
+// Fix is an edit of a document which resolves a finding. Range of edit is
+// absolute byte offsets in the document. Line is zero-based line of the
+// left-hand side of production rule which edit belongs to.
+type Fix struct {
+	Line    int
+	Code    string
+	Message string
+	Edit    parser.TextEdit
+}
+
+// Document is a text of a grammar together with its lossless syntax tree.
+type Document struct {
+	Dialect parser.Dialect
+	Tree    *parser.SyntaxTree
+	Index   parser.LineIndex
+}
+
+// NewDocument parses lines of a document written in some dialect.
+func NewDocument(dialect parser.Dialect, lines [][]byte) *Document {
+	var source, index = parser.JoinLines(lines)
+	return &Document{
+		Dialect: dialect,
+		Tree:    parser.ParseLossless(dialect, source),
+		Index:   index,
+	}
+}
+
+// Line returns zero-based line of an offset.
+func (d *Document) Line(offset int) int {
+	var line, _ = d.Index.Locate(offset)
+	return line
+}
+
+// span is a range of production rule of a document together with lines of
+// its left-hand side and its end.
+type span struct {
+	parser.Range
+	line, last int
+}
+
+// rules returns spans of production rules of a document in order of their
+// appearance.
+func (d *Document) rules() []span {
+	var spans []span
+	for _, stmt := range d.Tree.Statements() {
+		if stmt.Rule == nil {
+			continue
+		}
+		var lhs = d.Tree.Span(stmt.Rule.Left())
+		if lhs.Begin < 0 {
+			continue
+		}
+		var rng = d.Tree.Span(stmt)
+		spans = append(spans, span{rng, d.Line(lhs.Begin), d.Line(rng.End)})
+	}
+	return spans
+}
+
+// walk calls visit for every token which lies on lines of a production rule
+// together with the rule.
+func (d *Document) walk(visit func(rule span, idx int)) {
+	var rules = d.rules()
+	var next = 0
+	for idx, token := range d.Tree.Tokens() {
+		var line = d.Line(token.Range.Begin)
+		for next < len(rules) && rules[next].last < line {
+			next++
+		}
+		if next == len(rules) {
+			return
+		}
+		if line >= rules[next].line {
+			visit(rules[next], idx)
+		}
+	}
+}
+
+// LintSource runs all enabled rules against a grammar in the same way as Lint
+// does. Besides, fixers check text of a document which grammar is built from.
+// Their findings are bound to production rules of grammar.
+func LintSource(
+	dialect parser.Dialect, lines [][]byte, grammar *analysis.Grammar,
+	cfg *Config,
+) []Finding {
+	return lint(grammar, NewDocument(dialect, lines), cfg)
+}
+
+// Apply fixes findings of all enabled fixers. Fixers are applied one after
+// another to a document which is parsed again, so that their edits never
+// overlap. It returns fixed lines and number of applied fixes.
+func Apply(
+	dialect parser.Dialect, lines [][]byte, cfg *Config,
+) ([][]byte, int) {
+	if cfg == nil {
+		cfg = NewConfig()
+	}
+
+	var total = 0
+	for _, rule := range Rules() {
+		var fixer, ok = rule.(Fixer)
+		var opts = cfg.Options(rule.Name())
+		if !ok || !opts.Bool("enabled", true) {
+			continue
+		}
+
+		var doc = NewDocument(dialect, lines)
+		var fixes = fixer.Fix(doc, opts)
+		var edits = make([]parser.TextEdit, len(fixes))
+		for idx, fix := range fixes {
+			edits[idx] = fix.Edit
+		}
+
+		var source, err = parser.ApplyEdits(doc.Tree.Source(), edits)
+		if err != nil || len(fixes) == 0 {
+			continue
+		}
+		lines = bytes.Split(source, []byte{'\n'})
+		total += len(fixes)
+	}
+	return lines, total
+}
+
+// findings converts fixes to findings which are bound to production rules of
+// grammar. Ranges are relative to lines in single-line dialects.
+func (d *Document) findings(
+	grammar *analysis.Grammar, fixes []Fix,
+) []Finding {
+	var rules = make(map[int]*analysis.Rule)
+	for _, rule := range grammar.Rules {
+		rules[rule.Line] = rule
+	}
+
+	var findings []Finding
+	for _, fix := range fixes {
+		var rule, ok = rules[fix.Line]
+		if !ok {
+			continue
+		}
+
+		var finding = newFinding(rule, fix.Code, fix.Message)
+		finding.Range = fix.Edit.Range
+		if !d.Dialect.Multiline() {
+			var begin = d.Index[d.Line(finding.Range.Begin)]
+			finding.Range.Begin -= begin
+			finding.Range.End -= begin
+		}
+		findings = append(findings, finding)
+	}
+	return findings
+}
@@ -0,0 +1,88 @@
+package lint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+func TestApply(t *testing.T) {
+	var cfg, err = ParseConfig([]byte("[quotes]\nstyle = \"double\"\n"))
+	if err != nil {
+		t.Fatalf("failed to parse config: %s", err)
+	}
+
+	var cases = []struct {
+		dialect  parser.Dialect
+		source   string
+		expected string
+		count    int
+	}{
+		{
+			parser.DialectBNF,
+			"<a> ::= 'x'   <b>  \n; comment  \n<b> ::= 'it\"s' | \"y\"",
+			"<a> ::= \"x\" <b>\n; comment  \n<b> ::= 'it\"s' | \"y\"",
+			3,
+		},
+		{
+			parser.DialectEBNF,
+			"a = 'x' ,   b \n  | c ;\nb = \"y\" ;",
+			"a = \"x\" , b\n  | c ;\nb = \"y\" ;",
+			3,
+		},
+		{
+			parser.DialectANTLR,
+			"grammar G;\na : 'x'   b ;",
+			"grammar G;\na : 'x' b ;",
+			1,
+		},
+	}
+
+	for _, c := range cases {
+		var lines = splitSource(c.source)
+		var fixed, count = Apply(c.dialect, lines, cfg)
+		var actual = strings.Join(joinLines(fixed), "\n")
+		if actual != c.expected || count != c.count {
+			t.Errorf("wrong fixes of %q (%d): %q", c.source, count, actual)
+		}
+	}
+}
+
+func TestLintSource(t *testing.T) {
+	var lines = splitSource("<a> ::= <b>  \n<b> ::= \"x\"   'y'")
+	var grammar = analysis.NewGrammar(parser.DialectBNF, lines)
+
+	var codes []string
+	for _, finding := range LintSource(parser.DialectBNF, lines, grammar,
+		nil) {
+		codes = append(codes, finding.Code+":"+finding.Rule.Name)
+	}
+
+	var expected = CodeMultipleSpaces + ":b " + CodeTrailingWhitespace + ":a"
+	if actual := strings.Join(codes, " "); actual != expected {
+		t.Errorf("wrong findings: %s", actual)
+	}
+
+	var findings = LintSource(parser.DialectBNF, lines, grammar, nil)
+	if rng := findings[0].Range; rng.Begin != 11 || rng.End != 14 {
+		t.Errorf("range is not relative to line: %v", rng)
+	}
+}
+
+func splitSource(source string) [][]byte {
+	var lines [][]byte
+	for _, line := range strings.Split(source, "\n") {
+		lines = append(lines, []byte(line))
+	}
+	return lines
+}
+
+func joinLines(lines [][]byte) []string {
+	var result = make([]string, len(lines))
+	for idx, line := range lines {
+		result[idx] = string(line)
+	}
+	return result
+}
@@ -0,0 +1,146 @@
+package lint
+
+import (
+	"bytes"
+
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+	"github.com/daskol/nvim-bnf/pkg/i18n"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+// quoteStyles maps values of option style of rule quotes to quotes.
+var quoteStyles = map[string]byte{"double": '"', "single": '\''}
+
+// quotes checks that terminals are quoted in the same way as option style
+// (double or single) requires. Nothing is checked if style is not set.
+// Terminals which contain the required quote or escape sequences are kept
+// as is. Grammars of ANTLR and Yacc are skipped since kind of quotes matters
+// there.
+type quotes struct{}
+
+func (quotes) Name() string {
+	return "quotes"
+}
+
+func (quotes) Check(
+	grammar *analysis.Grammar, cfg *Config, opts Options,
+) []Finding {
+	return nil
+}
+
+func (quotes) Fix(doc *Document, opts Options) []Fix {
+	var style = opts.String("style", "")
+	var quote, ok = quoteStyles[style]
+	switch doc.Dialect.Base() {
+	case parser.DialectANTLR, parser.DialectYacc:
+		return nil
+	}
+	if !ok {
+		return nil
+	}
+
+	var tokens = doc.Tree.Tokens()
+	var fixes []Fix
+	doc.walk(func(rule span, idx int) {
+		var token = tokens[idx]
+		if _, ok := token.Node.(*parser.Terminal); !ok {
+			return
+		}
+
+		var text = doc.Tree.Text(token)
+		var last = len(text) - 1
+		if last < 1 || text[0] == quote || text[0] != text[last] ||
+			text[0] != '"' && text[0] != '\'' ||
+			bytes.IndexByte(text[1:last], quote) >= 0 ||
+			bytes.IndexByte(text[1:last], '\\') >= 0 {
+			return
+		}
+
+		var quoted = append([]byte{quote}, text[1:last]...)
+		fixes = append(fixes, Fix{
+			Line: rule.line,
+			Code: CodeQuotes,
+			Message: i18n.Sprintf("terminal %s should be quoted with %s "+
+				"quotes", text, style),
+			Edit: parser.TextEdit{
+				Range: token.Range,
+				Text:  append(quoted, quote),
+			},
+		})
+	})
+	return fixes
+}
+
+// trailingWhitespace checks that lines of production rules do not end with
+// spaces or tabs.
+type trailingWhitespace struct{}
+
+func (trailingWhitespace) Name() string {
+	return "trailing-whitespace"
+}
+
+func (trailingWhitespace) Check(
+	grammar *analysis.Grammar, cfg *Config, opts Options,
+) []Finding {
+	return nil
+}
+
+func (trailingWhitespace) Fix(doc *Document, opts Options) []Fix {
+	var tokens = doc.Tree.Tokens()
+	var fixes []Fix
+	doc.walk(func(rule span, idx int) {
+		if tokens[idx].Kind != parser.TokenSpace || idx+1 < len(tokens) &&
+			tokens[idx+1].Kind != parser.TokenNewline {
+			return
+		}
+		fixes = append(fixes, Fix{
+			Line:    rule.line,
+			Code:    CodeTrailingWhitespace,
+			Message: i18n.T("trailing whitespace"),
+			Edit:    parser.TextEdit{Range: tokens[idx].Range},
+		})
+	})
+	return fixes
+}
+
+// multipleSpaces checks that lexemes of production rules are separated with
+// a single space. Indentation and padding around operators are kept since
+// aligned layout relies on them.
+type multipleSpaces struct{}
+
+func (multipleSpaces) Name() string {
+	return "multiple-spaces"
+}
+
+func (multipleSpaces) Check(
+	grammar *analysis.Grammar, cfg *Config, opts Options,
+) []Finding {
+	return nil
+}
+
+func (multipleSpaces) Fix(doc *Document, opts Options) []Fix {
+	var tokens = doc.Tree.Tokens()
+	var fixes []Fix
+	doc.walk(func(rule span, idx int) {
+		var token = tokens[idx]
+		if token.Kind != parser.TokenSpace ||
+			token.Range.End-token.Range.Begin < 2 ||
+			token.Range.Begin <= rule.Begin || token.Range.End >= rule.End ||
+			!separates(tokens[idx-1]) || !separates(tokens[idx+1]) {
+			return
+		}
+		fixes = append(fixes, Fix{
+			Line:    rule.line,
+			Code:    CodeMultipleSpaces,
+			Message: i18n.T("lexemes are separated with several spaces"),
+			Edit:    parser.TextEdit{Range: token.Range, Text: []byte{' '}},
+		})
+	})
+	return fixes
+}
+
+// separates reports whether a token is a lexeme or a punctuation which
+// spaces next to it should be collapsed.
+func separates(token parser.SyntaxToken) bool {
+	return token.Kind == parser.TokenLexeme || token.Kind == parser.TokenPunct
+}
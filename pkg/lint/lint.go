@@ -0,0 +1,113 @@
+// Package lint checks grammars against configurable rules of style like
+// naming conventions or limits on size of rules. Rules are pluggable: a new
+// one is registered with Register and it is configured with a section of
+// .bnflint.toml named after the rule.
+package lint
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+// Rule is a check of a grammar. Name of a rule is the name of section of
+// configuration which sets its options.
+type Rule interface {
+	Name() string
+	Check(grammar *analysis.Grammar, cfg *Config, opts Options) []Finding
+}
+
+// Finding is a diagnostic of a lint rule. It is bound to a production rule
+// of grammar or to grammar as a whole if Rule is nil.
+type Finding struct {
+	Rule *analysis.Rule
+	parser.Diagnostic
+}
+
+// Line returns zero-based line of a finding. Findings about grammar as a
+// whole are placed on the first line.
+func (f Finding) Line() int {
+	if f.Rule == nil {
+		return 0
+	}
+	return f.Rule.Line
+}
+
+var registry = make(map[string]Rule)
+
+var registryGuard sync.RWMutex
+
+// Register adds a lint rule to the set of rules which Lint runs. Rule with
+// the same name is replaced.
+func Register(rule Rule) {
+	registryGuard.Lock()
+	defer registryGuard.Unlock()
+	registry[rule.Name()] = rule
+}
+
+// Rules returns all registered lint rules in lexicographical order of their
+// names.
+func Rules() []Rule {
+	registryGuard.RLock()
+	defer registryGuard.RUnlock()
+
+	var rules = make([]Rule, 0, len(registry))
+	for _, rule := range registry {
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].Name() < rules[j].Name()
+	})
+	return rules
+}
+
+// Lint runs all enabled rules against a grammar. Severity of findings could
+// be overridden with option severity of a rule. Findings are ordered by rules
+// and then by production rules of grammar. Fixers are not run since there is
+// no text of a document (see LintSource).
+func Lint(grammar *analysis.Grammar, cfg *Config) []Finding {
+	return lint(grammar, nil, cfg)
+}
+
+func lint(grammar *analysis.Grammar, doc *Document, cfg *Config) []Finding {
+	if cfg == nil {
+		cfg = NewConfig()
+	}
+
+	var findings []Finding
+	for _, rule := range Rules() {
+		var opts = cfg.Options(rule.Name())
+		if !opts.Bool("enabled", true) {
+			continue
+		}
+
+		var found = rule.Check(grammar, cfg, opts)
+		if fixer, ok := rule.(Fixer); ok && doc != nil {
+			found = doc.findings(grammar, fixer.Fix(doc, opts))
+		}
+
+		var severity, ok = ParseSeverity(opts.String("severity", ""))
+		for _, finding := range found {
+			if ok {
+				finding.Severity = severity
+			}
+			findings = append(findings, finding)
+		}
+	}
+	return findings
+}
+
+// ParseSeverity converts lowercase name of severity to its value.
+func ParseSeverity(name string) (parser.Severity, bool) {
+	for _, severity := range []parser.Severity{
+		parser.SeverityError, parser.SeverityWarning, parser.SeverityInfo,
+		parser.SeverityHint,
+	} {
+		if severity.String() == name {
+			return severity, true
+		}
+	}
+	return 0, false
+}
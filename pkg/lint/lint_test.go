@@ -0,0 +1,72 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+func TestLint(t *testing.T) {
+	var lines = [][]byte{
+		[]byte(`<expr> ::= <term> | <term> "+" <expr> | "(" <expr> ")"`),
+		[]byte(`<term> ::= <digit> | <TermTwo>`),
+		[]byte(`<digit> ::= "0" | "1"`),
+		[]byte(`<TermTwo> ::= "x"`),
+		[]byte(`<space> ::= " "`),
+	}
+	var grammar = analysis.NewGrammar(parser.DialectBNF, lines)
+
+	var cfg, err = ParseConfig([]byte(`
+start = "program"
+
+[naming]
+pattern = "^[a-z]+$"
+
+[max-alternatives]
+limit = 2
+severity = "hint"
+`))
+	if err != nil {
+		t.Fatalf("failed to parse config: %s", err)
+	}
+
+	var codes []string
+	for _, finding := range Lint(grammar, cfg) {
+		var name = "-"
+		if finding.Rule != nil {
+			name = finding.Rule.Name
+		}
+		codes = append(codes, finding.Code+":"+name+":"+
+			finding.Severity.String())
+	}
+
+	var expected = []string{
+		CodeMaxAlternatives + ":expr:hint",
+		CodeMissingStart + ":-:error",
+		CodeNaming + ":TermTwo:warning",
+		CodeUnreferenced + ":space:warning",
+	}
+	if len(codes) != len(expected) {
+		t.Fatalf("wrong findings: %v", codes)
+	}
+	for idx := range codes {
+		if codes[idx] != expected[idx] {
+			t.Errorf("wrong finding #%d: %s", idx, codes[idx])
+		}
+	}
+}
+
+func TestLintDisabled(t *testing.T) {
+	var lines = [][]byte{[]byte(`a b = "x";`)}
+	var grammar = analysis.NewGrammar(parser.DialectEBNF, lines)
+
+	if findings := Lint(grammar, nil); len(findings) != 1 {
+		t.Errorf("wrong number of findings: %v", findings)
+	}
+
+	var cfg, _ = ParseConfig([]byte("[naming]\nenabled = false\n"))
+	if findings := Lint(grammar, cfg); len(findings) != 0 {
+		t.Errorf("disabled rule is run: %v", findings)
+	}
+}
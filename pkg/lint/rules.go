@@ -0,0 +1,176 @@
+package lint
+
+import (
+	"regexp"
+
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+	"github.com/daskol/nvim-bnf/pkg/i18n"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+// Codes of findings of builtin rules.
+const (
+	CodeNaming          = "L001"
+	CodeMaxAlternatives = "L002"
+	CodeUnreferenced    = "L003"
+	CodeMissingStart    = "L004"
+	// Findings of fixers.
+	CodeQuotes             = "L005"
+	CodeTrailingWhitespace = "L006"
+	CodeMultipleSpaces     = "L007"
+)
+
+// DefaultNamingPattern allows names which start with a letter and consist of
+// letters, digits, hyphens, and underscores.
+const DefaultNamingPattern = `^[A-Za-z][A-Za-z0-9_-]*$`
+
+// DefaultMaxAlternatives is a default limit of alternatives of a rule.
+const DefaultMaxAlternatives = 16
+
+func init() {
+	Register(naming{})
+	Register(maxAlternatives{})
+	Register(unreferencedTerminals{})
+	Register(missingStart{})
+	Register(quotes{})
+	Register(trailingWhitespace{})
+	Register(multipleSpaces{})
+}
+
+// newFinding creates a warning about the left-hand side of a rule.
+func newFinding(rule *analysis.Rule, code, msg string) Finding {
+	var addr = rule.Address(-1)
+	return Finding{
+		Rule: rule,
+		Diagnostic: parser.Diagnostic{
+			Severity: parser.SeverityWarning,
+			Range:    parser.Span(rule.Statement.Rule.Left()),
+			Code:     code,
+			Message:  msg,
+			Address:  &addr,
+		},
+	}
+}
+
+// naming checks that names of rules match regular expression of option
+// pattern.
+type naming struct{}
+
+func (naming) Name() string {
+	return "naming"
+}
+
+func (naming) Check(
+	grammar *analysis.Grammar, cfg *Config, opts Options,
+) []Finding {
+	var pattern = opts.String("pattern", DefaultNamingPattern)
+	var re, err = regexp.Compile(pattern)
+	if err != nil {
+		return []Finding{{Diagnostic: parser.Diagnostic{
+			Severity: parser.SeverityError,
+			Code:     CodeNaming,
+			Message:  i18n.Sprintf("naming pattern %q is invalid", pattern),
+		}}}
+	}
+
+	var findings []Finding
+	for _, rule := range grammar.Rules {
+		if !re.MatchString(rule.Name) {
+			findings = append(findings, newFinding(rule, CodeNaming,
+				i18n.Sprintf("name of rule <%s> does not match %q",
+					rule.Name, pattern)))
+		}
+	}
+	return findings
+}
+
+// maxAlternatives checks that rules have at most as many alternatives as
+// option limit allows.
+type maxAlternatives struct{}
+
+func (maxAlternatives) Name() string {
+	return "max-alternatives"
+}
+
+func (maxAlternatives) Check(
+	grammar *analysis.Grammar, cfg *Config, opts Options,
+) []Finding {
+	var limit = opts.Int("limit", DefaultMaxAlternatives)
+	var findings []Finding
+	for _, rule := range grammar.Rules {
+		if noalts := len(rule.AlternativeRanges()); noalts > limit {
+			findings = append(findings, newFinding(rule, CodeMaxAlternatives,
+				i18n.Sprintf("rule <%s> has %d alternatives while at "+
+					"most %d are allowed", rule.Name, noalts, limit)))
+		}
+	}
+	return findings
+}
+
+// unreferencedTerminals reports rules which derive terminals only (e.g.
+// lexer rules of ANTLR) but which are not referenced by any other rule.
+type unreferencedTerminals struct{}
+
+func (unreferencedTerminals) Name() string {
+	return "unreferenced-terminals"
+}
+
+func (unreferencedTerminals) Check(
+	grammar *analysis.Grammar, cfg *Config, opts Options,
+) []Finding {
+	var start = startRule(grammar, cfg)
+	var refs = make(map[string]int)
+	for _, rule := range grammar.Rules {
+		for _, name := range rule.References() {
+			if name != rule.Name {
+				refs[name]++
+			}
+		}
+	}
+
+	var findings []Finding
+	for _, rule := range grammar.Rules {
+		if rule.Name == start || refs[rule.Name] > 0 ||
+			len(rule.References()) > 0 {
+			continue
+		}
+		findings = append(findings, newFinding(rule, CodeUnreferenced,
+			i18n.Sprintf("terminal rule <%s> is never referenced",
+				rule.Name)))
+	}
+	return findings
+}
+
+// missingStart checks that start rule which is set in configuration or
+// declared with `; %start` is defined in grammar.
+type missingStart struct{}
+
+func (missingStart) Name() string {
+	return "missing-start"
+}
+
+func (missingStart) Check(
+	grammar *analysis.Grammar, cfg *Config, opts Options,
+) []Finding {
+	var start = cfg.Start
+	if start == "" {
+		start = grammar.Start
+	}
+	if start == "" || grammar.Defines(start) {
+		return nil
+	}
+	return []Finding{{Diagnostic: parser.Diagnostic{
+		Severity: parser.SeverityError,
+		Code:     CodeMissingStart,
+		Message:  i18n.Sprintf("start rule <%s> is not defined", start),
+	}}}
+}
+
+// startRule returns name of start rule. Configuration takes precedence over
+// start symbol of grammar.
+func startRule(grammar *analysis.Grammar, cfg *Config) string {
+	if cfg.Start != "" {
+		return cfg.Start
+	}
+	return grammar.StartSymbol()
+}
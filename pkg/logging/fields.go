@@ -0,0 +1,134 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Fields are attributes of log record like buffer, event, or duration.
+type Fields map[string]interface{}
+
+// Format encodes how log records are rendered.
+type Format int
+
+const (
+	// FormatText renders record as a message followed by key=value pairs.
+	FormatText Format = iota
+	// FormatJSON renders record as JSON object with level, message, and
+	// fields so that log could be processed with tools like jq.
+	FormatJSON
+)
+
+// SetFormat changes format of log records. It is either text or json.
+func (l *Logger) SetFormat(format string) *Logger {
+	l.guard.Lock()
+	defer l.guard.Unlock()
+	switch format {
+	case "json":
+		l.format = FormatJSON
+	case "text":
+		l.format = FormatText
+	}
+	return l
+}
+
+var levelNames = map[Level]string{
+	Debug:   "debug",
+	Info:    "info",
+	Notice:  "notice",
+	Warning: "warning",
+	Error:   "error",
+}
+
+func (f Format) render(level Level, fields Fields, msg string) string {
+	if f == FormatText {
+		if len(fields) == 0 {
+			return msg
+		}
+		var pairs = make([]string, 0, len(fields))
+		for _, key := range sortedKeys(fields) {
+			pairs = append(pairs, fmt.Sprintf("%s=%v", key, fields[key]))
+		}
+		return msg + " " + strings.Join(pairs, " ")
+	}
+
+	var record = make(map[string]interface{}, len(fields)+3)
+	for key, value := range fields {
+		record[key] = value
+	}
+	record["time"] = time.Now().Format(time.RFC3339Nano)
+	record["level"] = levelNames[level]
+	record["msg"] = msg
+
+	var bytes, err = json.Marshal(record)
+	if err != nil {
+		return fmt.Sprintf(`{"level":"error","msg":%q}`,
+			"failed to encode record: "+err.Error())
+	}
+	return string(bytes)
+}
+
+func sortedKeys(fields Fields) []string {
+	var keys = make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Entry is a logger bound to a set of fields. It is used to tag all records of
+// a single RPC event with the same correlation ID.
+type Entry struct {
+	logger *Logger
+	fields Fields
+}
+
+// WithFields creates entry which attaches fields to every record.
+func (l *Logger) WithFields(fields Fields) *Entry {
+	return &Entry{logger: l, fields: fields}
+}
+
+// WithFields creates entry with fields of both the original entry and the
+// given ones. The latter take precedence.
+func (e *Entry) WithFields(fields Fields) *Entry {
+	var merged = make(Fields, len(e.fields)+len(fields))
+	for key, value := range e.fields {
+		merged[key] = value
+	}
+	for key, value := range fields {
+		merged[key] = value
+	}
+	return &Entry{logger: e.logger, fields: merged}
+}
+
+// WithDuration attaches duration elapsed since start in milliseconds.
+func (e *Entry) WithDuration(start time.Time) *Entry {
+	var elapsed = time.Since(start)
+	return e.WithFields(Fields{
+		"duration": float64(elapsed) / float64(time.Millisecond),
+	})
+}
+
+func (e *Entry) Debugf(format string, args ...interface{}) (int, error) {
+	return e.logger.logf(Debug, e.fields, format, args...)
+}
+
+func (e *Entry) Errorf(format string, args ...interface{}) (int, error) {
+	return e.logger.logf(Error, e.fields, format, args...)
+}
+
+func (e *Entry) Infof(format string, args ...interface{}) (int, error) {
+	return e.logger.logf(Info, e.fields, format, args...)
+}
+
+func (e *Entry) Noticef(format string, args ...interface{}) (int, error) {
+	return e.logger.logf(Notice, e.fields, format, args...)
+}
+
+func (e *Entry) Warnf(format string, args ...interface{}) (int, error) {
+	return e.logger.logf(Warning, e.fields, format, args...)
+}
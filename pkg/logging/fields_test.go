@@ -0,0 +1,70 @@
+package logging
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// memory is a collector which keeps messages in memory.
+type memory struct {
+	messages []string
+}
+
+func (m *memory) Debug(msg string) error   { return m.add(msg) }
+func (m *memory) Info(msg string) error    { return m.add(msg) }
+func (m *memory) Notice(msg string) error  { return m.add(msg) }
+func (m *memory) Warning(msg string) error { return m.add(msg) }
+func (m *memory) Err(msg string) error     { return m.add(msg) }
+func (m *memory) Close() error             { return nil }
+
+func (m *memory) add(msg string) error {
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+func TestTextFields(t *testing.T) {
+	var collector = &memory{}
+	var logger = &Logger{level: Info, collector: collector}
+	var entry = logger.WithFields(Fields{"id": 1, "event": "buf_lines"})
+
+	entry.Debugf("dropped")
+	entry.WithFields(Fields{"buffer": 2}).Infof("buffer %s", "highlighted")
+
+	if len(collector.messages) != 1 {
+		t.Fatalf("wrong number of records: %d", len(collector.messages))
+	}
+
+	var expected = "buffer highlighted buffer=2 event=buf_lines id=1"
+	if collector.messages[0] != expected {
+		t.Errorf("wrong record: %s", collector.messages[0])
+	}
+}
+
+func TestJSONFields(t *testing.T) {
+	var collector = &memory{}
+	var logger = &Logger{level: Info, collector: collector}
+	logger.SetFormat("json").
+		WithFields(Fields{"id": 1, "changedtick": 3}).
+		Warnf("unknown buffer")
+
+	var record map[string]interface{}
+	var data = []byte(collector.messages[0])
+	if err := json.Unmarshal(data, &record); err != nil {
+		t.Fatalf("record is not JSON: %s", err)
+	}
+
+	var expected = map[string]interface{}{
+		"level":       "warning",
+		"msg":         "unknown buffer",
+		"id":          1.0,
+		"changedtick": 3.0,
+	}
+	for key, value := range expected {
+		if record[key] != value {
+			t.Errorf("wrong value of %s: %v", key, record[key])
+		}
+	}
+	if _, ok := record["time"]; !ok {
+		t.Errorf("there is no time in record")
+	}
+}
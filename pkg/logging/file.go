@@ -0,0 +1,154 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Default limits of log file. File is rotated once it grows beyond size limit
+// and only a few rotated files are kept.
+const (
+	DefaultMaxSize = 10 << 20
+	DefaultBackups = 3
+)
+
+// File is a collector which appends messages to a file. The file is rotated
+// when it exceeds size limit, so `nvim-bnf.log` is renamed to `nvim-bnf.log.1`
+// which is renamed to `nvim-bnf.log.2` and so on.
+type File struct {
+	guard    sync.Mutex
+	filename string
+	maxSize  int64
+	backups  int
+	file     *os.File
+	size     int64
+}
+
+// OpenFile opens log file for appending with default limits.
+func OpenFile(filename string) (*File, error) {
+	var file = &File{
+		filename: filename,
+		maxSize:  DefaultMaxSize,
+		backups:  DefaultBackups,
+	}
+	if err := file.open(); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// SetLimits changes maximal size of file in bytes and number of rotated files
+// which are kept.
+func (f *File) SetLimits(maxSize int64, backups int) {
+	f.guard.Lock()
+	defer f.guard.Unlock()
+	f.maxSize = maxSize
+	f.backups = backups
+}
+
+func (f *File) open() error {
+	var flags = os.O_WRONLY | os.O_APPEND | os.O_CREATE
+	var file, err = os.OpenFile(f.filename, flags, 0644)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+// rotate shifts rotated files, drops the oldest one, and starts a new file.
+func (f *File) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+
+	var backup = func(idx int) string {
+		return f.filename + "." + strconv.Itoa(idx)
+	}
+
+	os.Remove(backup(f.backups))
+	for idx := f.backups - 1; idx >= 1; idx-- {
+		os.Rename(backup(idx), backup(idx+1))
+	}
+
+	if f.backups > 0 {
+		if err := os.Rename(f.filename, backup(1)); err != nil {
+			return err
+		}
+	} else if err := os.Remove(f.filename); err != nil {
+		return err
+	}
+
+	return f.open()
+}
+
+func (f *File) write(level, msg string) error {
+	f.guard.Lock()
+	defer f.guard.Unlock()
+
+	if f.file == nil {
+		return os.ErrClosed
+	}
+
+	var line = fmt.Sprintf("%s %s[%d]: %s: %s\n",
+		time.Now().Format(time.RFC3339), tag, os.Getpid(), level, msg)
+
+	if f.size > 0 && f.size+int64(len(line)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return err
+		}
+	}
+
+	var n, err = f.file.WriteString(line)
+	f.size += int64(n)
+	return err
+}
+
+func (f *File) Debug(msg string) error {
+	return f.write("debug", msg)
+}
+
+func (f *File) Info(msg string) error {
+	return f.write("info", msg)
+}
+
+func (f *File) Notice(msg string) error {
+	return f.write("notice", msg)
+}
+
+func (f *File) Warning(msg string) error {
+	return f.write("warning", msg)
+}
+
+func (f *File) Err(msg string) error {
+	return f.write("error", msg)
+}
+
+// Close flushes and closes log file.
+func (f *File) Close() error {
+	f.guard.Lock()
+	defer f.guard.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+
+	var file = f.file
+	f.file = nil
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,58 @@
+package logging
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileRotation(t *testing.T) {
+	var dir, err = ioutil.TempDir("", "nvim-bnf")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	var filename = filepath.Join(dir, "nvim-bnf.log")
+	file, err := OpenFile(filename)
+	if err != nil {
+		t.Fatalf("failed to open log file: %s", err)
+	}
+
+	// Every line takes more than a half of limit, so every message starts a
+	// new file and only two rotated files are kept.
+	file.SetLimits(128, 2)
+	for _, msg := range []string{"first", "second", "third", "fourth"} {
+		if err := file.Info(msg + strings.Repeat(".", 32)); err != nil {
+			t.Fatalf("failed to write message: %s", err)
+		}
+	}
+
+	if err := file.Close(); err != nil {
+		t.Fatalf("failed to close log file: %s", err)
+	}
+
+	var expected = map[string]string{
+		filename:        "fourth",
+		filename + ".1": "third",
+		filename + ".2": "second",
+	}
+	for name, msg := range expected {
+		var content, err = ioutil.ReadFile(name)
+		if err != nil {
+			t.Fatalf("failed to read %s: %s", name, err)
+		}
+		if lines := strings.Count(string(content), "\n"); lines != 1 {
+			t.Errorf("wrong number of lines in %s: %d", name, lines)
+		}
+		if !strings.Contains(string(content), "info: "+msg) {
+			t.Errorf("wrong content of %s: %s", name, content)
+		}
+	}
+
+	if _, err := os.Stat(filename + ".3"); !os.IsNotExist(err) {
+		t.Errorf("the oldest file is not removed")
+	}
+}
@@ -4,7 +4,8 @@ package logging
 import (
 	"fmt"
 	"log"
-	"log/syslog"
+	"os"
+	"path/filepath"
 	"sync"
 )
 
@@ -41,67 +42,79 @@ const (
 	Error
 )
 
-// Logger is a wrapper over built-in SysLog writer. It provides API similar to
-// Logger type in standard library.
+// tag identifies messages of the plugin among other ones.
+const tag = "nvim-bnf"
+
+// Collector is a destination of log messages. It is implemented with syslog
+// writer and with rotating log file.
+type Collector interface {
+	Debug(msg string) error
+	Info(msg string) error
+	Notice(msg string) error
+	Warning(msg string) error
+	Err(msg string) error
+	Close() error
+}
+
+// Logger is a wrapper over collector of log messages. It provides API similar
+// to Logger type in standard library.
 type Logger struct {
 	guard     sync.RWMutex
 	level     Level
-	collector *syslog.Writer
+	format    Format
+	collector Collector
 }
 
+// NewLogger creates logger which writes to file from environment variable
+// NVIM_BNF_LOG if it is set or to syslog otherwise. If syslog is not available
+// (e.g. on Windows) then messages are written to file in temporary directory.
+// Format of messages is taken from environment variable NVIM_BNF_LOG_FORMAT.
 func NewLogger() (*Logger, error) {
-	var priority = syslog.LOG_USER
-	var tag = "nvim-bnf"
+	var collector Collector
+	var err error
+	if filename := os.Getenv("NVIM_BNF_LOG"); filename != "" {
+		collector, err = OpenFile(filename)
+	} else if collector, err = newSyslog(); err != nil {
+		var filename = filepath.Join(os.TempDir(), tag+".log")
+		collector, err = OpenFile(filename)
+	}
 
-	if ptr, err := syslog.New(priority, tag); err != nil {
+	if err != nil {
 		return nil, err
-	} else {
-		return &Logger{level: Info, collector: ptr}, nil
 	}
+	var l = &Logger{level: Info, collector: collector}
+	return l.SetFormat(os.Getenv("NVIM_BNF_LOG_FORMAT")), nil
 }
 
 func (l *Logger) Close() error {
+	l.guard.Lock()
+	defer l.guard.Unlock()
 	return l.collector.Close()
 }
 
+// SetCollector replaces collector of messages and closes the previous one.
+func (l *Logger) SetCollector(collector Collector) error {
+	l.guard.Lock()
+	defer l.guard.Unlock()
+	var prev = l.collector
+	l.collector = collector
+	return prev.Close()
+}
+
 func (l *Logger) Debugf(format string, args ...interface{}) (int, error) {
-	l.guard.RLock()
-	defer l.guard.RUnlock()
-	if l.level > Debug {
-		return 0, nil
-	}
-	var msg = fmt.Sprintf(format, args...)
-	return len(msg), l.collector.Debug(msg)
+	return l.logf(Debug, nil, format, args...)
 }
 
 func (l *Logger) Errorf(format string, args ...interface{}) (int, error) {
-	l.guard.RLock()
-	defer l.guard.RUnlock()
-	if l.level > Error {
-		return 0, nil
-	}
-	var msg = fmt.Sprintf(format, args...)
-	return len(msg), l.collector.Err(msg)
+	return l.logf(Error, nil, format, args...)
 }
 
 func (l *Logger) Infof(format string, args ...interface{}) (int, error) {
-	l.guard.RLock()
-	defer l.guard.RUnlock()
-	if l.level > Info {
-		return 0, nil
-	}
-	var msg = fmt.Sprintf(format, args...)
-	return len(msg), l.collector.Info(msg)
+	return l.logf(Info, nil, format, args...)
 }
 
 func (l *Logger) Noticef(format string, args ...interface{}) (int, error) {
-	l.guard.RLock()
-	defer l.guard.RUnlock()
-	if l.level > Notice {
-		return 0, nil
-	}
-	var msg = fmt.Sprintf(format, args...)
-	return len(msg), l.collector.Notice(msg)
+	return l.logf(Notice, nil, format, args...)
 }
 
 func (l *Logger) SetLevel(level string) *Logger {
@@ -123,11 +136,30 @@ func (l *Logger) SetLevel(level string) *Logger {
 }
 
 func (l *Logger) Warnf(format string, args ...interface{}) (int, error) {
+	return l.logf(Warning, nil, format, args...)
+}
+
+// logf formats message with its fields and passes it to collector.
+func (l *Logger) logf(
+	level Level, fields Fields, format string, args ...interface{},
+) (int, error) {
 	l.guard.RLock()
 	defer l.guard.RUnlock()
-	if l.level > Warning {
+	if l.level > level {
 		return 0, nil
 	}
-	var msg = fmt.Sprintf(format, args...)
-	return len(msg), l.collector.Warning(msg)
+
+	var msg = l.format.render(level, fields, fmt.Sprintf(format, args...))
+	switch level {
+	case Debug:
+		return len(msg), l.collector.Debug(msg)
+	case Info:
+		return len(msg), l.collector.Info(msg)
+	case Notice:
+		return len(msg), l.collector.Notice(msg)
+	case Warning:
+		return len(msg), l.collector.Warning(msg)
+	default:
+		return len(msg), l.collector.Err(msg)
+	}
 }
@@ -0,0 +1,12 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package logging
+
+import (
+	"log/syslog"
+)
+
+func newSyslog() (Collector, error) {
+	return syslog.New(syslog.LOG_USER, tag)
+}
@@ -0,0 +1,617 @@
+package parser
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+)
+
+// antlrKeywords are words which introduce grammar level declarations or
+// modify rules. They could not be rule names.
+var antlrKeywords = map[string]bool{
+	"catch":     true,
+	"channels":  true,
+	"finally":   true,
+	"fragment":  true,
+	"grammar":   true,
+	"import":    true,
+	"lexer":     true,
+	"locals":    true,
+	"mode":      true,
+	"options":   true,
+	"parser":    true,
+	"private":   true,
+	"protected": true,
+	"public":    true,
+	"returns":   true,
+	"throws":    true,
+	"tokens":    true,
+}
+
+// ANTLRParser performs semantic parsing of ANTLR4 grammars. Both parser rules
+// (lowercase names) and lexer rules (uppercase names) are production rules
+// and references to them are non-terminals. Grammar declarations, options,
+// named actions, rule arguments, and embedded actions are skipped.
+//
+// Negation `~` is an exception expression without minuend and ranges like
+// `'a'..'z'` as well as sets `[a-z]` are character classes. Lexer commands
+// (`-> skip`) and alternative labels (`# Label`) are special sequences. Empty
+// alternative is presented with an empty special sequence.
+type ANTLRParser struct {
+	documentParser
+}
+
+func NewANTLRParser(reader io.Reader) *ANTLRParser {
+	var p = &ANTLRParser{documentParser: newDocumentParser(reader)}
+	p.comment, p.tokenize = p.parseOptCComment, p.parseLineLexemes
+	return p
+}
+
+// ParseANTLR parses ANTLR4 grammar. Likewise Parse, it falls back to lexical
+// parsing on error.
+func ParseANTLR(source []byte) (*AST, error) {
+	var ast, errSem = NewANTLRParser(bytes.NewReader(source)).Parse()
+	if errSem == nil {
+		return ast, nil
+	}
+
+	var lemmes, errSyn = NewANTLRParser(bytes.NewReader(source)).parseLexemes()
+	if errSyn != nil {
+		return nil, errSyn
+	}
+
+	return &AST{err: errSem, lemmes: lemmes, source: source}, nil
+}
+
+func (p *ANTLRParser) Parse() (*AST, error) {
+	if bytes, err := ioutil.ReadAll(p.Reader); err != nil {
+		return nil, err
+	} else {
+		p.buf = bytes
+		p.pos = skipBOM(p.buf)
+	}
+
+	var rules, err = p.parseSyntax()
+
+	switch err := err.(type) {
+	case *DescError:
+		return nil, locate(err, p.buf)
+	case error:
+		return nil, locate(&Error{err: err, pos: p.pos}, p.buf)
+	default:
+		return &AST{rules: rules, semantic: true, source: p.buf}, nil
+	}
+}
+
+func (p *ANTLRParser) parseSyntax() ([]*Statement, error) {
+	var stmts []*Statement
+
+	for {
+		if err := p.parseGap(); err != nil {
+			return nil, err
+		}
+
+		stmts = p.flushComments(stmts)
+
+		if err := p.eof(); err != nil {
+			return stmts, nil
+		}
+
+		if ok, err := p.parseDeclaration(); err != nil {
+			return nil, err
+		} else if ok {
+			continue
+		}
+
+		if stmt, err := p.parseRule(); err != nil {
+			return nil, err
+		} else {
+			stmts = append(stmts, stmt)
+			stmts = p.flushComments(stmts)
+		}
+	}
+}
+
+// parseDeclaration skips grammar level declaration like `grammar X;`,
+// `options { ... }`, or `@header { ... }`. It reports whether there was a
+// declaration.
+func (p *ANTLRParser) parseDeclaration() (bool, error) {
+	var offset = p.pos
+
+	if p.lookingAt("@") {
+		var idx = bytes.IndexByte(p.buf[p.pos:], '{')
+		if idx < 0 {
+			return false, NewDescError(io.EOF, len(p.buf), "'{'")
+		}
+		p.pos += idx
+		return true, p.skipBlock('{', '}')
+	}
+
+	switch string(p.parseWord()) {
+	case "grammar", "lexer", "parser", "import", "mode":
+		var idx = bytes.IndexByte(p.buf[p.pos:], ';')
+		if idx < 0 {
+			return false, NewDescError(io.EOF, len(p.buf), "';'")
+		}
+		p.pos += idx + 1
+		return true, nil
+	case "options", "tokens", "channels":
+		if err := p.parseGap(); err != nil {
+			return false, err
+		}
+		if !p.lookingAt("{") {
+			return false, NewDescError(p.failure(), p.pos, "'{'")
+		}
+		return true, p.skipBlock('{', '}')
+	default:
+		p.pos = offset
+		return false, nil
+	}
+}
+
+func (p *ANTLRParser) parseRule() (*Statement, error) {
+	var err error
+	var expr = new(AssignmentExpression)
+
+	// Skip rule modifiers like `fragment`.
+	for {
+		var offset = p.pos
+		if word := p.parseWord(); len(word) == 0 {
+			break
+		} else if !antlrKeywords[string(word)] {
+			p.pos = offset
+			break
+		} else if err = p.parseGap(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.eof() != nil || !isLetter(p.buf[p.pos]) {
+		return nil, NewDescError(p.failure(), p.pos, "rule name")
+	} else {
+		var begin = p.pos
+		var name = p.parseWord()
+		expr.LeftChild = &NonTerminal{Token{name, begin, p.pos}}
+	}
+
+	if err = p.parseRulePrequel(); err != nil {
+		return nil, err
+	}
+
+	if !p.lookingAt(":") {
+		return nil, NewDescError(p.failure(), p.pos, "':'")
+	} else {
+		expr.Token = Token{Name: []byte{':'}, Begin: p.pos, End: p.pos + 1}
+		p.pos++
+	}
+
+	if err = p.parseGap(); err != nil {
+		return nil, err
+	}
+
+	if expr.RightChild, err = p.parseAlternatives(); err != nil {
+		return nil, err
+	}
+
+	if !p.lookingAt(";") {
+		return nil, NewDescError(p.failure(), p.pos, "'|' or ';'")
+	} else {
+		p.pos++
+	}
+
+	if err = p.parseExceptionHandlers(); err != nil {
+		return nil, err
+	}
+
+	return &Statement{Rule: expr}, nil
+}
+
+// parseRulePrequel skips everything between rule name and colon like
+// arguments, return values, local variables, options, and named actions.
+func (p *ANTLRParser) parseRulePrequel() error {
+	for {
+		if err := p.parseGap(); err != nil {
+			return err
+		}
+
+		var err error
+		switch {
+		case p.eof() != nil:
+			return nil
+		case p.lookingAt("["):
+			err = p.skipBlock('[', ']')
+		case p.lookingAt("{"):
+			err = p.skipBlock('{', '}')
+		case p.lookingAt("@"):
+			p.pos++
+			p.parseWord()
+		case isLetter(p.buf[p.pos]):
+			var offset = p.pos
+			if !antlrKeywords[string(p.parseWord())] {
+				p.pos = offset
+				return nil
+			}
+		default:
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// parseExceptionHandlers skips `catch [...] { ... }` and `finally { ... }`
+// clauses after a rule.
+func (p *ANTLRParser) parseExceptionHandlers() error {
+	for {
+		if err := p.parseGap(); err != nil {
+			return err
+		}
+
+		var offset = p.pos
+		switch string(p.parseWord()) {
+		case "catch", "finally":
+			if err := p.parseRulePrequel(); err != nil {
+				return err
+			}
+		default:
+			p.pos = offset
+			return nil
+		}
+	}
+}
+
+// parseAlternatives parses alternatives separated with `|` into
+// right-recursive chain of AlternativeExpression.
+func (p *ANTLRParser) parseAlternatives() (Node, error) {
+	var seqs []Node
+	var bars []Token
+
+	for {
+		if seq, err := p.parseSequence(); err != nil {
+			return nil, err
+		} else {
+			seqs = append(seqs, seq)
+		}
+
+		if !p.lookingAt("|") {
+			break
+		}
+
+		bars = append(bars, Token{
+			Name:  []byte{'|'},
+			Begin: p.pos,
+			End:   p.pos + 1,
+		})
+		p.pos++
+
+		if err := p.parseGap(); err != nil {
+			return nil, err
+		}
+	}
+
+	var node = seqs[len(seqs)-1]
+	for idx := len(bars) - 1; idx >= 0; idx-- {
+		node = &AlternativeExpression{Expression{
+			Token:      bars[idx],
+			LeftChild:  seqs[idx],
+			RightChild: node,
+		}}
+	}
+
+	return node, nil
+}
+
+// parseSequence parses elements of an alternative into right-recursive chain
+// of CompoundExpression. Actions and predicates are skipped.
+func (p *ANTLRParser) parseSequence() (Node, error) {
+	var terms []Node
+	var begin = p.pos
+
+loop:
+	for p.eof() == nil {
+		var offset = p.pos
+
+		switch char := p.buf[p.pos]; {
+		case char == '{':
+			if err := p.skipBlock('{', '}'); err != nil {
+				return nil, err
+			}
+			if p.lookingAt("?") {
+				p.pos++
+			}
+		case char == '<':
+			if _, err := p.parseQuoted('>'); err != nil {
+				return nil, err
+			}
+		case p.lookingAt("->") || char == '#':
+			var node = p.parseSuffix()
+			terms = append(terms, node)
+		case char == '|' || char == ';' || char == ')':
+			break loop
+		default:
+			if node, err := p.parseElement(); err != nil {
+				return nil, err
+			} else {
+				terms = append(terms, node)
+			}
+		}
+
+		if p.pos == offset {
+			break
+		}
+
+		if err := p.parseGap(); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(terms) == 0 {
+		return &SpecialSequence{Token{nil, begin, begin}}, nil
+	}
+
+	var node = terms[len(terms)-1]
+	for idx := len(terms) - 2; idx >= 0; idx-- {
+		var span = Span(terms[idx])
+		node = &CompoundExpression{Expression{
+			Token:      Token{Begin: span.Begin, End: span.End},
+			LeftChild:  terms[idx],
+			RightChild: node,
+		}}
+	}
+
+	return node, nil
+}
+
+// parseSuffix parses lexer commands like `-> skip` or alternative label like
+// `# Label` until the end of alternative.
+func (p *ANTLRParser) parseSuffix() Node {
+	var begin = p.pos
+	var end = p.pos
+
+	for p.eof() == nil {
+		var char = p.buf[p.pos]
+		if char == '|' || char == ';' || char == ')' {
+			break
+		} else if char == '(' {
+			p.skipBlock('(', ')')
+		} else {
+			p.pos++
+		}
+
+		if !isWhitespace(char) {
+			end = p.pos
+		}
+	}
+
+	p.pos = end
+	return &SpecialSequence{Token{p.buf[begin:end], begin, end}}
+}
+
+// parseElement parses an atom with optional label, negation, and postfix
+// repetition operators.
+func (p *ANTLRParser) parseElement() (Node, error) {
+	p.parseLabel()
+
+	var node Node
+	var err error
+
+	if p.lookingAt("~") {
+		var expr = &ExceptionExpression{Expression{
+			Token: Token{Name: []byte{'~'}, Begin: p.pos, End: p.pos + 1},
+		}}
+		p.pos++
+
+		if err = p.parseGap(); err != nil {
+			return nil, err
+		} else if expr.RightChild, err = p.parseAtom(); err != nil {
+			return nil, err
+		}
+
+		node = expr
+	} else if node, err = p.parseAtom(); err != nil {
+		return nil, err
+	}
+
+	for p.eof() == nil {
+		var min, max int
+		switch p.buf[p.pos] {
+		case '?':
+			min, max = 0, 1
+		case '*':
+			min, max = 0, -1
+		case '+':
+			min, max = 1, -1
+		default:
+			return node, nil
+		}
+
+		var begin = p.pos
+		if p.pos++; p.lookingAt("?") {
+			p.pos++ // Non-greedy operator.
+		}
+
+		node = &RepetitionExpression{
+			Expression: Expression{
+				Token: Token{
+					Name:  p.buf[begin:p.pos],
+					Begin: begin,
+					End:   p.pos,
+				},
+				LeftChild: node,
+			},
+			Min: min,
+			Max: max,
+		}
+	}
+
+	return node, nil
+}
+
+// parseLabel skips element label like `left=expr` or `ids+=ID`.
+func (p *ANTLRParser) parseLabel() {
+	var offset = p.pos
+	if len(p.parseWord()) == 0 {
+		return
+	}
+
+	for p.eof() == nil && (p.buf[p.pos] == ' ' || p.buf[p.pos] == '\t') {
+		p.pos++
+	}
+
+	if p.lookingAt("+=") {
+		p.pos += 2
+	} else if p.lookingAt("=") {
+		p.pos++
+	} else {
+		p.pos = offset
+		return
+	}
+
+	for p.eof() == nil && isWhitespace(p.buf[p.pos]) {
+		p.pos++
+	}
+}
+
+func (p *ANTLRParser) parseAtom() (Node, error) {
+	if err := p.eof(); err != nil {
+		return nil, NewDescError(err, p.pos, "element")
+	}
+
+	var begin = p.pos
+
+	switch char := p.buf[p.pos]; {
+	case char == '(':
+		return p.parseGroup()
+	case char == '[':
+		if err := p.skipCharacterSet(); err != nil {
+			return nil, err
+		}
+		var token = Token{p.buf[begin+1 : p.pos-1], begin, p.pos}
+		return &CharacterClass{token}, nil
+	case char == '\'':
+		if name, err := p.parseCLiteral(char); err != nil {
+			return nil, err
+		} else if !p.lookingAt("..") {
+			return &Terminal{Token{name, begin, p.pos}}, nil
+		}
+
+		// Range of characters like 'a'..'z'.
+		p.pos += 2
+		if !p.lookingAt("'") {
+			return nil, NewDescError(p.failure(), p.pos, "literal")
+		} else if _, err := p.parseCLiteral('\''); err != nil {
+			return nil, err
+		}
+		var token = Token{p.buf[begin:p.pos], begin, p.pos}
+		return &CharacterClass{token}, nil
+	case char == '.':
+		p.pos++
+		return &Wildcard{Token{p.buf[begin:p.pos], begin, p.pos}}, nil
+	case isLetter(char):
+		var name = p.parseWord()
+		return &NonTerminal{Token{name, begin, p.pos}}, nil
+	default:
+		return nil, NewDescError(ErrUnexpectedChar, p.pos, "element")
+	}
+}
+
+func (p *ANTLRParser) parseGroup() (Node, error) {
+	var err error
+	var group = &GroupExpression{Kind: GroupParen}
+	group.Begin = p.pos
+	p.pos++
+
+	if err = p.parseGap(); err != nil {
+		return nil, err
+	}
+
+	if group.LeftChild, err = p.parseAlternatives(); err != nil {
+		return nil, err
+	}
+
+	if !p.lookingAt(")") {
+		return nil, NewDescError(p.failure(), p.pos, "')'")
+	}
+
+	p.pos++
+	group.End = p.pos
+	return group, nil
+}
+
+// parseWord parses identifier which consists of letters, digits, and
+// underscores.
+func (p *ANTLRParser) parseWord() []byte {
+	var begin = p.pos
+	for p.eof() == nil && (isMetaIdentifierChar(p.buf[p.pos]) &&
+		p.buf[p.pos] != '-') {
+		p.pos++
+	}
+	return p.buf[begin:p.pos]
+}
+
+func (p *ANTLRParser) parseLineLexemes() []Node {
+	var tokens []Node
+
+	for p.pos < len(p.buf) {
+		var begin = p.pos
+		var char = p.buf[p.pos]
+		var token = Token{[]byte{char}, begin, begin + 1}
+
+		switch {
+		case p.lookingAt("/*") || p.lookingAt("//"):
+			p.parseCComment()
+			tokens = append(tokens, &Comment{Token{nil, begin, p.pos}})
+		case p.lookingAt("->"):
+			p.pos += 2
+			tokens = append(tokens, &SpecialSequence{Token{
+				p.buf[begin:p.pos], begin, p.pos,
+			}})
+		case char == '#' || char == '@':
+			p.pos++
+			p.parseWord()
+			token = Token{p.buf[begin:p.pos], begin, p.pos}
+			tokens = append(tokens, &SpecialSequence{token})
+		case char == '{':
+			// Action could continue on the next lines.
+			if p.skipBlock('{', '}') != nil {
+				p.pos = len(p.buf)
+			}
+		case char == '\'' || char == '[':
+			if node, err := p.parseAtom(); err != nil {
+				p.pos = begin + 1
+			} else {
+				tokens = append(tokens, node)
+			}
+		case char == ':':
+			var expr = Expression{Token: token}
+			tokens = append(tokens, &AssignmentExpression{expr})
+			p.pos++
+		case char == '|':
+			var expr = Expression{Token: token}
+			tokens = append(tokens, &AlternativeExpression{expr})
+			p.pos++
+		case char == '~':
+			var expr = Expression{Token: token}
+			tokens = append(tokens, &ExceptionExpression{expr})
+			p.pos++
+		case char == '?' || char == '*' || char == '+':
+			var expr = Expression{Token: token}
+			tokens = append(tokens, &RepetitionExpression{Expression: expr})
+			p.pos++
+		case isLetter(char):
+			var name = p.parseWord()
+			token = Token{name, begin, p.pos}
+			if antlrKeywords[string(name)] {
+				tokens = append(tokens, &SpecialSequence{token})
+			} else {
+				tokens = append(tokens, &NonTerminal{token})
+			}
+		default:
+			p.pos++
+		}
+	}
+
+	return tokens
+}
@@ -0,0 +1,85 @@
+package parser
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestANTLRParser(t *testing.T) {
+	t.Run("Rule", func(t *testing.T) {
+		var content = []byte(`ws : [ \t]+ -> skip | ~'x'*? ;`)
+		var parser = NewANTLRParser(bytes.NewBuffer(content))
+		var ast, err = parser.Parse()
+
+		if err != nil {
+			t.Fatalf("failed to parse grammar: %s", err)
+		}
+
+		if length := ast.NoRules(); length != 1 {
+			t.Fatalf("wrong number of statements: %d", length)
+		}
+
+		var alts = Alternatives(ast.rules[0].Rule.Right())
+		if len(alts) != 2 {
+			t.Fatalf("wrong number of alternatives: %d", len(alts))
+		}
+
+		var compound, ok = alts[0].(*CompoundExpression)
+		if !ok {
+			t.Fatalf("wrong type of the first alternative: %T", alts[0])
+		} else if _, ok := compound.Right().(*SpecialSequence); !ok {
+			t.Errorf("lexer command is not special: %T", compound.Right())
+		}
+
+		if rep, ok := alts[1].(*RepetitionExpression); !ok {
+			t.Errorf("wrong type of the second alternative: %T", alts[1])
+		} else if name := string(rep.Name); name != "*?" {
+			t.Errorf("wrong repetition operator: %s", name)
+		} else if _, ok := rep.Left().(*ExceptionExpression); !ok {
+			t.Errorf("wrong type of repeated element: %T", rep.Left())
+		}
+
+		if span := Span(alts[1]); span.Begin != 22 || span.End != 28 {
+			t.Errorf("wrong span of the second alternative: %v", span)
+		}
+	})
+
+	t.Run("Unterminated", func(t *testing.T) {
+		var content = []byte("expr : INT | ID\n")
+		var parser = NewANTLRParser(bytes.NewBuffer(content))
+		if _, err := parser.Parse(); err == nil {
+			t.Errorf("error is expected for rule without semicolon")
+		}
+	})
+
+	t.Run("Expr", func(t *testing.T) {
+		var content = readBNFFile(t, "expr.g4")
+		var parser = NewANTLRParser(bytes.NewBuffer(content))
+		var ast, err = parser.Parse()
+
+		if err != nil {
+			t.Fatalf("failed to parse grammar: %s", err)
+		}
+
+		var names []string
+		for _, stmt := range ast.rules {
+			if stmt.Rule != nil {
+				var lhs = stmt.Rule.Left().(*NonTerminal)
+				names = append(names, string(lhs.Name))
+			}
+		}
+
+		var expected = []string{
+			"prog", "stat", "expr", "ID", "INT", "NEWLINE", "WS", "COMMENT",
+			"LETTER", "DIGIT",
+		}
+		if len(names) != len(expected) {
+			t.Fatalf("wrong rules: %v", names)
+		}
+		for idx := range names {
+			if names[idx] != expected[idx] {
+				t.Errorf("wrong rule #%d: %s", idx, names[idx])
+			}
+		}
+	})
+}
@@ -15,17 +15,26 @@ type Node interface {
 }
 
 // Token represents terminal, non-terminal, or any other lexemes. It is a base
-// struct which is embedded below.
+// struct which is embedded below. Tokens keep byte offsets only in order to
+// keep trees compact. Line and column are derived from offsets on demand with
+// AST.Position or AST.Locate.
 type Token struct {
 	Name []byte
-	// Begin encodes position where token begins. The possition is relative to
-	// begin position of parent token.
+	// Begin encodes byte offset where token begins. The offset is relative to
+	// the beginning of parsed source.
 	Begin int
-	// End encodes position where token ends. The position is relateive as well
+	// End encodes byte offset where token ends. The offset is relative as well
 	// as in case of begin.
 	End int
 }
 
+// Columns returns positions where token begins and ends in characters rather
+// than in bytes. They differ for non-ASCII text. Source is a line or a
+// document which offsets of token refer to.
+func (t *Token) Columns(source []byte) (int, int) {
+	return Column(source, t.Begin), Column(source, t.End)
+}
+
 // Left does not return any node by default.
 func (t *Token) Left() Node {
 	return nil
@@ -89,19 +98,40 @@ func (t *Terminal) String() string {
 	return t.stringFromPositionAndName("Terminal")
 }
 
+// Epsilon is an explicit empty alternative which matches empty string. It is
+// written as empty literal `""`, as `ε`, or as `<empty>`.
+type Epsilon struct {
+	Token
+}
+
+func (t *Epsilon) String() string {
+	return t.stringFromPosition("Epsilon")
+}
+
 // Statement represents a BNF statement which could be empty (blank line) or
 // not. In any case its right child points to comment. However, the left child
-// is either nil or assignment expression.
+// is either nil or assignment expression. Doc is documentation of a rule,
+// i.e. text of comment lines right above it, which is attached on analysis.
 type Statement struct {
 	Rule    *AssignmentExpression
 	Comment *Comment
+	Doc     string
 }
 
+// Left returns assignment expression if statement is not a blank or comment
+// line. Nil pointer is not wrapped into interface in order to simplify checks.
 func (s *Statement) Left() Node {
+	if s.Rule == nil {
+		return nil
+	}
 	return s.Rule
 }
 
+// Right returns comment of statement if there is any.
 func (s *Statement) Right() Node {
+	if s.Comment == nil {
+		return nil
+	}
 	return s.Comment
 }
 
@@ -173,3 +203,189 @@ type CompoundExpression struct {
 func (e *CompoundExpression) String() string {
 	return e.stringFromPosition("CompoundExpression")
 }
+
+// GroupKind distinguishes kinds of bracketed expressions.
+type GroupKind int
+
+const (
+	// GroupParen is a plain grouping like `( ... )`.
+	GroupParen GroupKind = iota
+	// GroupOptional is an optional sequence like `[ ... ]`.
+	GroupOptional
+	// GroupRepetition is a repeated sequence like `{ ... }`.
+	GroupRepetition
+)
+
+// GroupExpression is a bracketed part of right-hand side of a production rule.
+// Its token spans the whole group including brackets.
+//
+// The left child is the enclosed expression which is one of
+// AlternativeExpression, CompoundExpression, GroupExpression, NonTerminal, or
+// Terminal. There is no right child.
+type GroupExpression struct {
+	Expression
+	Kind GroupKind
+}
+
+func (e *GroupExpression) String() string {
+	return e.stringFromPosition("GroupExpression")
+}
+
+// SpecialSequence is an implementation-defined terminal like `? ... ?` in ISO
+// EBNF. Its name is content of the sequence without delimiters.
+type SpecialSequence struct {
+	Token
+}
+
+func (t *SpecialSequence) String() string {
+	return t.stringFromPositionAndName("SpecialSequence")
+}
+
+// RepetitionExpression repeats its operand from Min to Max times. Max is
+// negative if there is no upper bound. Token of the expression is a
+// repetition operator like `*`, `+`, or `?`.
+//
+// The left child is the operand and there is no right child.
+type RepetitionExpression struct {
+	Expression
+	Min int
+	Max int
+}
+
+func (e *RepetitionExpression) String() string {
+	return e.stringFromPositionAndName("RepetitionExpression")
+}
+
+// ExceptionExpression matches anything which matches its left child but does
+// not match its right child. Token of the expression is operator `-`. Negation
+// like `~x` in ANTLR is an exception expression without left child.
+type ExceptionExpression struct {
+	Expression
+}
+
+func (e *ExceptionExpression) String() string {
+	return e.stringFromPositionAndName("ExceptionExpression")
+}
+
+// CharacterClass is a terminal which matches a single character from a set
+// like `[a-z]` or `[^"]`. Its name is content of the class without brackets.
+type CharacterClass struct {
+	Token
+}
+
+func (t *CharacterClass) String() string {
+	return t.stringFromPositionAndName("CharacterClass")
+}
+
+// PredicateExpression is a syntactic predicate of PEG which succeeds if its
+// operand matches (`&`) or does not match (`!`) without consuming any input.
+// Token of the expression is the predicate operator.
+//
+// The right child is the operand and there is no left child.
+type PredicateExpression struct {
+	Expression
+	Negative bool
+}
+
+func (e *PredicateExpression) String() string {
+	return e.stringFromPositionAndName("PredicateExpression")
+}
+
+// Wildcard is a terminal which matches any single character like `.` in PEG
+// or ANTLR.
+type Wildcard struct {
+	Token
+}
+
+func (t *Wildcard) String() string {
+	return t.stringFromPosition("Wildcard")
+}
+
+// Alternatives flattens top-level chain of alternative expressions of a
+// right-hand side into a list of alternatives.
+func Alternatives(rhs Node) []Node {
+	var alts []Node
+	for {
+		if alt, ok := rhs.(*AlternativeExpression); ok {
+			alts = append(alts, alt.LeftChild)
+			rhs = alt.RightChild
+		} else {
+			return append(alts, rhs)
+		}
+	}
+}
+
+// Span returns byte range which is covered by a subtree.
+func Span(node Node) Range {
+	var span = Range{-1, -1}
+	var extend = func(begin, end int) {
+		if span.Begin < 0 || begin < span.Begin {
+			span.Begin = begin
+		}
+		if end > span.End {
+			span.End = end
+		}
+	}
+
+	// Operators of syntactic parse tree have no operands, so they cover
+	// their own tokens.
+	var operator = func(expr *Expression) {
+		if expr.LeftChild == nil && expr.RightChild == nil {
+			extend(expr.Begin, expr.End)
+		}
+	}
+
+	var visit func(Node)
+	visit = func(node Node) {
+		switch node := node.(type) {
+		case nil:
+		case *Statement:
+			if node.Rule != nil {
+				visit(node.Rule)
+			}
+			if node.Comment != nil {
+				visit(node.Comment)
+			}
+		case *Comment:
+			extend(node.Begin, node.End)
+		case *AssignmentExpression:
+			visit(node.LeftChild)
+			visit(node.RightChild)
+			operator(&node.Expression)
+		case *AlternativeExpression:
+			visit(node.LeftChild)
+			visit(node.RightChild)
+			operator(&node.Expression)
+		case *CompoundExpression:
+			visit(node.LeftChild)
+			visit(node.RightChild)
+		case *ExceptionExpression:
+			visit(node.LeftChild)
+			visit(node.RightChild)
+			extend(node.Begin, node.End)
+		case *PredicateExpression:
+			visit(node.RightChild)
+			extend(node.Begin, node.End)
+		case *RepetitionExpression:
+			visit(node.LeftChild)
+			extend(node.Begin, node.End)
+		case *GroupExpression:
+			extend(node.Begin, node.End)
+		case *CharacterClass:
+			extend(node.Begin, node.End)
+		case *NonTerminal:
+			extend(node.Begin, node.End)
+		case *Terminal:
+			extend(node.Begin, node.End)
+		case *SpecialSequence:
+			extend(node.Begin, node.End)
+		case *Wildcard:
+			extend(node.Begin, node.End)
+		case *Epsilon:
+			extend(node.Begin, node.End)
+		}
+	}
+
+	visit(node)
+	return span
+}
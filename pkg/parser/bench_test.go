@@ -0,0 +1,77 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+)
+
+// generateGrammar generates a BNF grammar of n rules where every rule refers
+// to a few of the following ones.
+func generateGrammar(n int) []byte {
+	var buf bytes.Buffer
+	for idx := 0; idx < n; idx++ {
+		fmt.Fprintf(&buf, "<rule-%d> ::= <rule-%d> \"a%d\" | <rule-%d> | "+
+			"\"b\" <rule-%d> \"c\"\n", idx, (idx+1)%n, idx, (idx+2)%n,
+			(idx+3)%n)
+	}
+	return buf.Bytes()
+}
+
+func benchmarkParser(b *testing.B, parse func(source []byte) error) {
+	for _, size := range []int{10, 100, 1000} {
+		var source = generateGrammar(size)
+		b.Run(fmt.Sprintf("rules=%d", size), func(b *testing.B) {
+			b.SetBytes(int64(len(source)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := parse(source); err != nil {
+					b.Fatalf("failed to parse grammar: %s", err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkSemanticParser(b *testing.B) {
+	benchmarkParser(b, func(source []byte) error {
+		var _, err = NewSemanticParser(bytes.NewReader(source)).Parse()
+		return err
+	})
+}
+
+func BenchmarkSyntacticParser(b *testing.B) {
+	benchmarkParser(b, func(source []byte) error {
+		var _, err = NewSyntacticParser(bytes.NewReader(source)).Parse()
+		return err
+	})
+}
+
+// BenchmarkParseLines measures parsing of a grammar line by line as it is
+// done on highlighting.
+func BenchmarkParseLines(b *testing.B) {
+	benchmarkParser(b, func(source []byte) error {
+		for _, line := range bytes.Split(source, []byte("\n")) {
+			if _, err := ParseDialect(DialectBNF, line); err != nil &&
+				err != ErrNoStatements {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// BenchmarkStream measures reading of a grammar rule by rule with stream.
+func BenchmarkStream(b *testing.B) {
+	benchmarkParser(b, func(source []byte) error {
+		var stream = NewStream(bytes.NewReader(source))
+		for {
+			if _, err := stream.Next(); err == io.EOF {
+				return nil
+			} else if err != nil {
+				return err
+			}
+		}
+	})
+}
@@ -0,0 +1,405 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"unicode/utf8"
+)
+
+// ErrOverlappingEdits is returned if edits of a source overlap.
+var ErrOverlappingEdits = errors.New("bnf: edits overlap")
+
+// TokenKind is a kind of token of lossless syntax tree.
+type TokenKind int
+
+const (
+	// TokenLexeme is a leaf of AST like a terminal or a non-terminal.
+	TokenLexeme TokenKind = iota
+	// TokenOperator is an operator of an expression like `::=`, `|`, or
+	// `*`.
+	TokenOperator
+	// TokenPunct is text which AST does not keep like brackets of groups,
+	// terminators of rules, or lines which could not be parsed.
+	TokenPunct
+	// TokenComment is a comment.
+	TokenComment
+	// TokenSpace is a run of spaces and tabs.
+	TokenSpace
+	// TokenNewline is a line break.
+	TokenNewline
+)
+
+var tokenKinds = []string{
+	"lexeme", "operator", "punct", "comment", "space", "newline",
+}
+
+func (k TokenKind) String() string {
+	if k < 0 || int(k) >= len(tokenKinds) {
+		return "unknown"
+	}
+	return tokenKinds[k]
+}
+
+// Trivia reports whether tokens of a kind do not affect grammar, i.e. they are
+// whitespaces or comments.
+func (k TokenKind) Trivia() bool {
+	return k == TokenComment || k == TokenSpace || k == TokenNewline
+}
+
+// SyntaxToken is a piece of source of lossless syntax tree. Range of token is
+// absolute offsets in the source. Node is a node of AST which token belongs
+// to. It is nil for punctuation and whitespaces.
+type SyntaxToken struct {
+	Kind  TokenKind
+	Range Range
+	Node  Node
+}
+
+// SyntaxTree is a lossless (concrete) syntax tree of a document. Unlike AST,
+// it keeps every byte of a source: tokens cover the source without gaps, so
+// the source is restored by concatenation of tokens. Transformations could
+// edit text of nodes in place and keep layout and comments of the rest of
+// a document intact.
+//
+// Documents in single-line dialects are parsed line by line, so offsets of
+// nodes of statements are relative to their lines. Use Span in order to get
+// absolute ones.
+type SyntaxTree struct {
+	source     []byte
+	tokens     []SyntaxToken
+	statements []*Statement
+	bases      map[Node]int
+	err        error
+}
+
+// ParseLossless parses a document into lossless syntax tree. A tree is built
+// even if some statements could not be parsed: their text becomes
+// punctuation. Error of the first statement which could not be parsed is
+// available with (*SyntaxTree).Error.
+func ParseLossless(dialect Dialect, source []byte) *SyntaxTree {
+	var tree = &SyntaxTree{source: source, bases: make(map[Node]int)}
+	if dialect.Multiline() {
+		tree.parse(dialect, source, 0)
+	} else {
+		var begin = 0
+		for idx, line := range bytes.Split(source, []byte{'\n'}) {
+			var err = tree.parse(dialect, bytes.TrimSuffix(line, []byte{'\r'}),
+				begin)
+			if err != nil && tree.err == nil {
+				tree.err = fmt.Errorf("line %d: %s", idx+1, err)
+			}
+			begin += len(line) + 1
+		}
+	}
+
+	sort.SliceStable(tree.tokens, func(i, j int) bool {
+		return tree.tokens[i].Range.Begin < tree.tokens[j].Range.Begin
+	})
+	tree.fill()
+	return tree
+}
+
+// parse parses a chunk of source which starts at offset base and collects
+// tokens of its nodes.
+func (t *SyntaxTree) parse(dialect Dialect, chunk []byte, base int) error {
+	// Lines without lexemes like blank ones are not errors.
+	var ast, err = ParseDialect(dialect, chunk)
+	if err == nil && len(Diagnostics(ast)) > 0 {
+		err = ast.Error()
+	}
+	if err != nil && t.err == nil && dialect.Multiline() {
+		t.err = err
+	}
+	if ast == nil {
+		return err
+	}
+
+	t.statements = append(t.statements, ast.Statements()...)
+	ast.Walk(func(cursor *Cursor) error {
+		var node = cursor.Node
+		t.bases[node] = base
+		if kind, token, ok := syntaxToken(node); ok && token.Begin < token.End {
+			t.tokens = append(t.tokens, SyntaxToken{
+				Kind:  kind,
+				Range: Range{base + token.Begin, base + token.End},
+				Node:  node,
+			})
+		}
+		return nil
+	}, nil)
+	return err
+}
+
+// syntaxToken returns token of a node which covers its own text rather than
+// text of its children.
+func syntaxToken(node Node) (TokenKind, *Token, bool) {
+	switch node := node.(type) {
+	case *Comment:
+		return TokenComment, &node.Token, true
+	case *NonTerminal:
+		return TokenLexeme, &node.Token, true
+	case *Terminal:
+		return TokenLexeme, &node.Token, true
+	case *Epsilon:
+		return TokenLexeme, &node.Token, true
+	case *SpecialSequence:
+		return TokenLexeme, &node.Token, true
+	case *CharacterClass:
+		return TokenLexeme, &node.Token, true
+	case *Wildcard:
+		return TokenLexeme, &node.Token, true
+	case *Token:
+		return TokenLexeme, node, true
+	case *AssignmentExpression:
+		return TokenOperator, &node.Token, true
+	case *AlternativeExpression:
+		return TokenOperator, &node.Token, true
+	case *RepetitionExpression:
+		return TokenOperator, &node.Token, true
+	case *ExceptionExpression:
+		return TokenOperator, &node.Token, true
+	case *PredicateExpression:
+		return TokenOperator, &node.Token, true
+	default:
+		return 0, nil, false
+	}
+}
+
+// fill drops tokens which overlap preceding ones and covers gaps between
+// tokens with whitespaces and punctuation.
+func (t *SyntaxTree) fill() {
+	var tokens []SyntaxToken
+	var pos = 0
+	for _, token := range t.tokens {
+		if token.Range.Begin < pos || token.Range.End > len(t.source) {
+			continue
+		}
+		tokens = t.gap(tokens, pos, token.Range.Begin)
+		tokens = append(tokens, token)
+		pos = token.Range.End
+	}
+	t.tokens = t.gap(tokens, pos, len(t.source))
+}
+
+// gap splits text between tokens into runs of spaces, line breaks, and
+// punctuation. Line break is either LF or CRLF.
+func (t *SyntaxTree) gap(tokens []SyntaxToken, begin, end int) []SyntaxToken {
+	var newline = func(pos int) int {
+		switch {
+		case t.source[pos] == '\n':
+			return 1
+		case t.source[pos] == '\r' && pos+1 < end && t.source[pos+1] == '\n':
+			return 2
+		default:
+			return 0
+		}
+	}
+
+	// blank returns size of a whitespace at position which does not break a
+	// line or zero.
+	var blank = func(pos int) int {
+		var char, size = utf8.DecodeRune(t.source[pos:end])
+		if t.source[pos] == '\r' || isBlank(char) {
+			return size
+		}
+		return 0
+	}
+
+	for pos := begin; pos < end; {
+		var kind = TokenPunct
+		var next = pos + newline(pos)
+		switch {
+		case next > pos:
+			kind = TokenNewline
+		case blank(pos) > 0:
+			kind = TokenSpace
+			for next = pos + blank(pos); next < end && newline(next) == 0 &&
+				blank(next) > 0; next += blank(next) {
+			}
+		default:
+			for next = pos + 1; next < end && newline(next) == 0 &&
+				blank(next) == 0; next++ {
+			}
+		}
+		tokens = append(tokens, SyntaxToken{kind, Range{pos, next}, nil})
+		pos = next
+	}
+	return tokens
+}
+
+// Error returns error of the first statement which could not be parsed. Line
+// number of a statement is prepended to an error in single-line dialects.
+func (t *SyntaxTree) Error() error {
+	return t.err
+}
+
+// Source returns a source which tree is parsed from.
+func (t *SyntaxTree) Source() []byte {
+	return t.source
+}
+
+// Tokens returns tokens of a tree in order of their appearance.
+func (t *SyntaxTree) Tokens() []SyntaxToken {
+	return t.tokens
+}
+
+// Statements returns statements of a document in order of their appearance.
+func (t *SyntaxTree) Statements() []*Statement {
+	return t.statements
+}
+
+// Text returns source text of a token.
+func (t *SyntaxTree) Text(token SyntaxToken) []byte {
+	return t.source[token.Range.Begin:token.Range.End]
+}
+
+// Bytes concatenates text of all tokens. It is always equal to the source.
+func (t *SyntaxTree) Bytes() []byte {
+	var buf bytes.Buffer
+	for _, token := range t.tokens {
+		buf.Write(t.Text(token))
+	}
+	return buf.Bytes()
+}
+
+// Span returns absolute byte range which is covered by a node of a tree.
+// Unlike parser.Span, statements and assignments are covered entirely. Range
+// is {-1, -1} if node does not belong to the tree.
+func (t *SyntaxTree) Span(node Node) Range {
+	var base, ok = t.bases[node]
+	if !ok {
+		return Range{-1, -1}
+	}
+
+	var span Range
+	switch node := node.(type) {
+	case *Statement:
+		span = Range{-1, -1}
+		for _, child := range []Node{node.Left(), node.Right()} {
+			if child != nil {
+				span = union(span, t.Span(child))
+			}
+		}
+		return span
+	case *AssignmentExpression:
+		span = union(Span(node.Left()), Span(node.Right()))
+		span = union(span, Range{node.Begin, node.End})
+	default:
+		span = Span(node)
+	}
+
+	if span.Begin < 0 {
+		return span
+	}
+	return Range{base + span.Begin, base + span.End}
+}
+
+// union returns the smallest range which covers both ranges. Range with
+// negative beginning is empty.
+func union(a, b Range) Range {
+	switch {
+	case a.Begin < 0:
+		return b
+	case b.Begin < 0:
+		return a
+	}
+	if b.Begin < a.Begin {
+		a.Begin = b.Begin
+	}
+	if b.End > a.End {
+		a.End = b.End
+	}
+	return a
+}
+
+// TokenAt returns index of token which contains a byte offset or -1 if offset
+// is out of source.
+func (t *SyntaxTree) TokenAt(offset int) int {
+	var idx = sort.Search(len(t.tokens), func(i int) bool {
+		return t.tokens[i].Range.End > offset
+	})
+	if idx == len(t.tokens) || offset < 0 {
+		return -1
+	}
+	return idx
+}
+
+// Leading returns trivia which precedes a token back to the previous token
+// which is not trivia or the end of the previous line.
+func (t *SyntaxTree) Leading(idx int) []SyntaxToken {
+	var begin = idx
+	for begin > 0 && t.tokens[begin-1].Kind.Trivia() {
+		begin--
+		if t.tokens[begin].Kind == TokenNewline {
+			begin++
+			break
+		}
+	}
+	return t.tokens[begin:idx]
+}
+
+// Trailing returns trivia which follows a token up to the next token which is
+// not trivia or the end of the line inclusively.
+func (t *SyntaxTree) Trailing(idx int) []SyntaxToken {
+	var end = idx + 1
+	for end < len(t.tokens) && t.tokens[end].Kind.Trivia() {
+		end++
+		if t.tokens[end-1].Kind == TokenNewline {
+			break
+		}
+	}
+	return t.tokens[idx+1 : end]
+}
+
+// TextEdit replaces absolute byte range of a source with text. Empty range
+// inserts text at its beginning.
+type TextEdit struct {
+	Range Range
+	Text  []byte
+}
+
+// Replace returns an edit which replaces text of a node with new text. The
+// rest of a document including surrounding whitespaces and comments is kept
+// as is.
+func (t *SyntaxTree) Replace(node Node, text []byte) TextEdit {
+	return TextEdit{Range: t.Span(node), Text: text}
+}
+
+// Remove returns an edit which removes a node together with spaces which
+// follow it on the same line, so that no double spaces are left behind.
+func (t *SyntaxTree) Remove(node Node) TextEdit {
+	var span = t.Span(node)
+	if span.Begin < 0 {
+		return TextEdit{Range: span}
+	}
+	if idx := t.TokenAt(span.End); idx >= 0 && span.End > span.Begin &&
+		t.tokens[idx].Kind == TokenSpace {
+		span.End = t.tokens[idx].Range.End
+	}
+	return TextEdit{Range: span}
+}
+
+// ApplyEdits applies edits to a source. Edits could be given in any order but
+// they must not overlap.
+func ApplyEdits(source []byte, edits []TextEdit) ([]byte, error) {
+	var sorted = append([]TextEdit{}, edits...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Range.Begin < sorted[j].Range.Begin
+	})
+
+	var buf bytes.Buffer
+	var pos = 0
+	for _, edit := range sorted {
+		var begin, end = edit.Range.Begin, edit.Range.End
+		if begin < pos || end < begin || end > len(source) {
+			return nil, ErrOverlappingEdits
+		}
+		buf.Write(source[pos:begin])
+		buf.Write(edit.Text)
+		pos = end
+	}
+	buf.Write(source[pos:])
+	return buf.Bytes(), nil
+}
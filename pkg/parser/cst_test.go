@@ -0,0 +1,97 @@
+package parser
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseLossless(t *testing.T) {
+	var filenames, err = filepath.Glob("testdata/*")
+	if err != nil {
+		t.Fatalf("failed to list test data: %s", err)
+	}
+
+	for _, filename := range filenames {
+		t.Run(filepath.Base(filename), func(t *testing.T) {
+			var source, err = ioutil.ReadFile(filename)
+			if err != nil {
+				t.Fatalf("failed to read source: %s", err)
+			}
+
+			var tree = ParseLossless(DetectDialect(filename), source)
+			if actual := tree.Bytes(); string(actual) != string(source) {
+				t.Fatalf("source is not restored:\n%s", actual)
+			}
+
+			var pos = 0
+			for _, token := range tree.Tokens() {
+				if token.Range.Begin != pos || token.Range.End <= pos {
+					t.Fatalf("wrong range of token: %v", token.Range)
+				}
+				pos = token.Range.End
+			}
+		})
+	}
+}
+
+func TestSyntaxTree(t *testing.T) {
+	var source = "; doc\r\n<a> ::= <b>  \"c\"  \n\n<b>::=\"x\"|<a>\n"
+	var tree = ParseLossless(DialectBNF, []byte(source))
+
+	t.Run("Tokens", func(t *testing.T) {
+		var kinds []string
+		for _, token := range tree.Tokens()[:12] {
+			kinds = append(kinds, token.Kind.String())
+		}
+		var expected = "comment newline lexeme space operator space lexeme " +
+			"space lexeme space newline newline"
+		if actual := strings.Join(kinds, " "); actual != expected {
+			t.Errorf("wrong kinds of tokens: %s", actual)
+		}
+	})
+
+	t.Run("Trivia", func(t *testing.T) {
+		var idx = tree.TokenAt(strings.Index(source, `"c"`))
+		var leading, trailing = tree.Leading(idx), tree.Trailing(idx)
+		if len(leading) != 1 || string(tree.Text(leading[0])) != "  " {
+			t.Errorf("wrong leading trivia: %v", leading)
+		}
+		if len(trailing) != 2 || trailing[1].Kind != TokenNewline {
+			t.Errorf("wrong trailing trivia: %v", trailing)
+		}
+	})
+
+	t.Run("Span", func(t *testing.T) {
+		var stmts = tree.Statements()
+		if len(stmts) != 2 {
+			t.Fatalf("wrong number of statements: %d", len(stmts))
+		}
+		var span = tree.Span(stmts[1])
+		if text := source[span.Begin:span.End]; text != `<b>::="x"|<a>` {
+			t.Errorf("wrong span of statement: %q", text)
+		}
+	})
+
+	t.Run("Edits", func(t *testing.T) {
+		var rhs = tree.Statements()[0].Rule.Right().(*CompoundExpression)
+		var edits = []TextEdit{
+			tree.Replace(tree.Statements()[1].Rule.Left(), []byte("<B>")),
+			tree.Remove(rhs.Left()),
+		}
+		var result, err = ApplyEdits([]byte(source), edits)
+		if err != nil {
+			t.Fatalf("failed to apply edits: %s", err)
+		}
+		var expected = "; doc\r\n<a> ::= \"c\"  \n\n<B>::=\"x\"|<a>\n"
+		if string(result) != expected {
+			t.Errorf("wrong result of edits: %q", result)
+		}
+
+		edits = append(edits, tree.Replace(rhs, nil))
+		if _, err := ApplyEdits([]byte(source), edits); err == nil {
+			t.Errorf("overlapping edits are applied")
+		}
+	})
+}
@@ -0,0 +1,177 @@
+package parser
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/daskol/nvim-bnf/pkg/i18n"
+)
+
+// Severity encodes importance of diagnostic. Values are the same as in
+// Language Server Protocol.
+type Severity int
+
+const (
+	SeverityError Severity = iota + 1
+	SeverityWarning
+	SeverityInfo
+	SeverityHint
+)
+
+// String returns lowercase name of severity.
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	case SeverityInfo:
+		return "info"
+	case SeverityHint:
+		return "hint"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalJSON encodes severity with its name.
+func (s Severity) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// Range is a half-open interval of byte offsets in parsed source.
+type Range struct {
+	Begin int `json:"begin"`
+	End   int `json:"end"`
+}
+
+// Address points to an alternative of a production rule. Index of alternative
+// is zero-based. It is -1 if address refers to a rule as a whole.
+type Address struct {
+	Rule        string `json:"rule"`
+	Alternative int    `json:"alternative"`
+}
+
+// Diagnostic describes an issue found in a source. Start is a line and column
+// where range begins. Address is optional and refers to a production rule
+// where issue is found.
+type Diagnostic struct {
+	Severity Severity `json:"severity"`
+	Range    Range    `json:"range"`
+	Start    Position `json:"start"`
+	Code     string   `json:"code"`
+	Message  string   `json:"message"`
+	Address  *Address `json:"address,omitempty"`
+}
+
+// Codes of diagnostics produced by parser.
+const (
+	CodeUnknown        = "E000"
+	CodeUnexpectedChar = "E001"
+	CodeUnexpectedEOL  = "E002"
+	CodeEmptyRule      = "E003"
+	CodeNoStatements   = "E004"
+	CodeInvalidBounds  = "E005"
+	CodeUnclosedGroup  = "E006"
+)
+
+// Diagnostics returns structured diagnostics of a parse tree. Parse tree
+// without any lexemes has no diagnostics even if semantic parsing failed.
+// Address of diagnostic is resolved only for single-line sources.
+func Diagnostics(ast *AST) []Diagnostic {
+	if ast.err == nil || ast.noLexemes() == 0 {
+		return nil
+	}
+
+	var diag = NewDiagnostic(ast.err)
+	if !ast.semantic && len(ast.lemmes) == 1 {
+		diag.Address = addressOf(ast.lemmes[0], diag.Range.Begin)
+	}
+	return []Diagnostic{diag}
+}
+
+// addressOf finds alternative of a rule which contains byte offset among
+// lexemes of a line. It returns nil if there is no rule on the line.
+func addressOf(lemmes []Node, offset int) *Address {
+	var addr *Address
+
+	for idx, node := range lemmes {
+		switch node := node.(type) {
+		case *AssignmentExpression:
+			if addr != nil || idx == 0 {
+				break
+			}
+
+			if lhs, ok := lemmes[idx-1].(*NonTerminal); ok {
+				addr = &Address{Rule: string(lhs.Name), Alternative: -1}
+			}
+
+			if addr != nil && node.End <= offset {
+				addr.Alternative = 0
+			}
+		case *AlternativeExpression:
+			if addr != nil && addr.Alternative >= 0 && node.End <= offset {
+				addr.Alternative++
+			}
+		}
+	}
+
+	return addr
+}
+
+// NewDiagnostic converts parsing error to diagnostic. Message of diagnostic
+// is translated according to the current message catalog.
+func NewDiagnostic(err error) Diagnostic {
+	var diag = Diagnostic{
+		Severity: SeverityError,
+		Code:     codeOf(err),
+		Message:  i18n.T(err.Error()),
+	}
+
+	switch err := err.(type) {
+	case *DescError:
+		diag.Range = Range{err.Pos(), err.Pos() + 1}
+		diag.Start = err.Position()
+		diag.Message = i18n.Sprintf("%s is expected", i18n.T(err.desc))
+	case *Error:
+		diag.Range = Range{err.Pos(), err.Pos() + 1}
+		diag.Start = err.Position()
+		diag.Message = i18n.T(err.err.Error())
+	}
+
+	return diag
+}
+
+func codeOf(err error) string {
+	switch {
+	case errors.Is(err, ErrUnexpectedChar):
+		return CodeUnexpectedChar
+	case errors.Is(err, io.EOF):
+		return CodeUnexpectedEOL
+	case errors.Is(err, ErrEmptyRule):
+		return CodeEmptyRule
+	case errors.Is(err, ErrNoStatements):
+		return CodeNoStatements
+	case errors.Is(err, ErrInvalidBounds):
+		return CodeInvalidBounds
+	case errors.Is(err, ErrUnclosedGroup):
+		return CodeUnclosedGroup
+	default:
+		return CodeUnknown
+	}
+}
+
+// noLexemes returns total number of lexemes in syntactic parse tree or number
+// of statements in semantic one.
+func (ast *AST) noLexemes() int {
+	if ast.semantic {
+		return len(ast.rules)
+	}
+
+	var count = 0
+	for _, lemmes := range ast.lemmes {
+		count += len(lemmes)
+	}
+	return count
+}
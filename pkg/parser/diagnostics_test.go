@@ -0,0 +1,71 @@
+package parser
+
+import (
+	"testing"
+)
+
+func TestDiagnostics(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		var ast, err = Parse([]byte(`<a> ::= <b> "c"`))
+		if err != nil {
+			t.Fatalf("failed to parse: %s", err)
+		}
+
+		if diags := Diagnostics(ast); len(diags) != 0 {
+			t.Errorf("no diagnostics are expected: %v", diags)
+		}
+	})
+
+	t.Run("Blank", func(t *testing.T) {
+		var ast, err = Parse([]byte(`   `))
+		if err != nil {
+			t.Fatalf("failed to parse: %s", err)
+		}
+
+		if diags := Diagnostics(ast); len(diags) != 0 {
+			t.Errorf("no diagnostics are expected: %v", diags)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		var ast, err = Parse([]byte(`<a> ::= <b> "c`))
+		if err != nil {
+			t.Fatalf("failed to parse: %s", err)
+		}
+
+		var diags = Diagnostics(ast)
+		if len(diags) != 1 {
+			t.Fatalf("exactly one diagnostic is expected: %v", diags)
+		}
+
+		var diag = diags[0]
+		if diag.Severity != SeverityError {
+			t.Errorf("wrong severity: %s", diag.Severity)
+		}
+		if diag.Code != CodeUnexpectedChar {
+			t.Errorf("wrong code: %s", diag.Code)
+		}
+		if diag.Range != (Range{12, 13}) {
+			t.Errorf("wrong range: %v", diag.Range)
+		}
+	})
+
+	t.Run("Address", func(t *testing.T) {
+		var ast, err = Parse([]byte(`<a> ::= "b" | <c> | <d> "e`))
+		if err != nil {
+			t.Fatalf("failed to parse: %s", err)
+		}
+
+		var diags = Diagnostics(ast)
+		if len(diags) != 1 {
+			t.Fatalf("exactly one diagnostic is expected: %v", diags)
+		}
+
+		var expected = Address{Rule: "a", Alternative: 2}
+		if addr := diags[0].Address; addr == nil {
+			t.Errorf("address is missing")
+		} else if *addr != expected {
+			t.Errorf("wrong address: %v", *addr)
+		}
+	})
+}
@@ -0,0 +1,133 @@
+package parser
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+var ErrUnknownDialect = errors.New("bnf: unknown dialect")
+
+// Dialect is a name of notation in which a grammar is written.
+type Dialect string
+
+const (
+	// DialectBNF is a classic Backus-Naur form.
+	DialectBNF Dialect = "bnf"
+	// DialectEBNF is Extended BNF as defined in ISO/IEC 14977.
+	DialectEBNF Dialect = "ebnf"
+	// DialectW3C is EBNF notation used in W3C specifications.
+	DialectW3C Dialect = "w3c"
+	// DialectANTLR is ANTLR4 grammar notation.
+	DialectANTLR Dialect = "antlr4"
+	// DialectYacc is a rules section of Yacc or Bison grammar file.
+	DialectYacc Dialect = "yacc"
+	// DialectPEG is Parsing Expression Grammar.
+	DialectPEG Dialect = "peg"
+)
+
+// ParseFunc parses a source written in some dialect.
+type ParseFunc func(source []byte) (*AST, error)
+
+// dialects maps dialects to their parsers and file extensions. Dialect is
+// multiline if its rules could span several lines. Builtins are symbols which
+// are predefined by a dialect.
+var dialects = map[Dialect]struct {
+	parse      ParseFunc
+	extensions []string
+	multiline  bool
+	builtins   []string
+}{
+	DialectANTLR: {ParseANTLR, []string{".g4"}, true, []string{"EOF"}},
+	DialectBNF:   {Parse, []string{".bnf"}, false, nil},
+	DialectEBNF:  {ParseEBNF, []string{".ebnf"}, true, nil},
+	DialectPEG:   {ParsePEG, []string{".peg"}, true, nil},
+	DialectW3C:   {ParseW3C, []string{".w3c"}, true, nil},
+	DialectYacc:  {ParseYacc, []string{".y", ".yy"}, true, []string{"error"}},
+}
+
+// Multiline reports whether rules of a dialect could span several lines. Such
+// grammars should be parsed as a whole rather than line by line.
+func (d Dialect) Multiline() bool {
+	return dialects[d].multiline
+}
+
+// Builtin reports whether a symbol is predefined by a dialect like EOF in
+// ANTLR or error token in Yacc. Such symbols are terminals although they are
+// referenced in the same way as rules are.
+func (d Dialect) Builtin(name string) bool {
+	for _, builtin := range dialects[d].builtins {
+		if builtin == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Dialects returns names of all supported dialects in lexicographical order.
+func Dialects() []Dialect {
+	var names = make([]Dialect, 0, len(dialects))
+	for name := range dialects {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// LookupDialect returns dialect by its name if it is supported.
+func LookupDialect(name string) (Dialect, bool) {
+	var dialect = Dialect(strings.ToLower(name))
+	if _, ok := notation(Dialect(name)); ok {
+		return Dialect(name), true
+	}
+	var _, ok = dialects[dialect]
+	return dialect, ok
+}
+
+// DetectDialect guesses dialect by file extension. It falls back to classic
+// BNF if extension is unknown.
+func DetectDialect(filename string) Dialect {
+	if dialect, ok := LookupExtension(filename); ok {
+		return dialect
+	}
+	return DialectBNF
+}
+
+// LookupExtension returns dialect which is associated with file extension. It
+// reports false if file is not a known grammar file.
+func LookupExtension(filename string) (Dialect, bool) {
+	var ext = strings.ToLower(filepath.Ext(filename))
+	for dialect, desc := range dialects {
+		for _, extension := range desc.extensions {
+			if ext == extension {
+				return dialect, true
+			}
+		}
+	}
+	return "", false
+}
+
+// ParseDialect parses a source written in specified dialect.
+func ParseDialect(dialect Dialect, source []byte) (*AST, error) {
+	if desc, ok := dialects[dialect]; ok {
+		return desc.parse(source)
+	} else if ops, ok := notation(dialect); ok {
+		return ParseOperators(source, *ops)
+	} else {
+		return nil, ErrUnknownDialect
+	}
+}
+
+// ParseFile reads a grammar from file and parses it. Dialect of the grammar is
+// detected by file extension.
+func ParseFile(filename string) (*AST, error) {
+	if source, err := ioutil.ReadFile(filename); err != nil {
+		return nil, err
+	} else {
+		return ParseDialect(DetectDialect(filename), source)
+	}
+}
@@ -0,0 +1,32 @@
+// Package parser contains parsers of BNF metalanguage and its dialects. It
+// does not depend on editor code, so other programs can import it in order to
+// read grammars.
+//
+// The simplest entry points are Parse, which reads classic BNF, ParseDialect,
+// which reads a source in one of supported dialects, and ParseFile, which
+// reads a file and guesses its dialect by extension.
+//
+//	var ast, err = parser.ParseFile("grammar.bnf")
+//	if err != nil {
+//		return err
+//	}
+//	for _, stmt := range ast.Statements() {
+//		if stmt.Rule != nil {
+//			fmt.Println(stmt.Rule.LeftChild)
+//		}
+//	}
+//
+// Every parser produces an AST. Rules of a grammar are available with
+// Statements and lexemes of every line are available with Traverse. Walk
+// visits nodes with enter and leave callbacks which have access to ancestors
+// of a node and which could skip subtrees or stop traversal. Parsers
+// of classic BNF fall back to syntactic parsing on semantic errors, so AST
+// could be returned along with a saved error which is accessible with
+// (*AST).Error. Diagnostics converts both kinds of errors into a list of
+// positioned messages.
+//
+// ParseLossless builds a lossless syntax tree which keeps whitespaces,
+// comments, and punctuation along with lexemes. Its tokens cover a source
+// without gaps, so transformations could replace text of nodes with
+// TextEdit and ApplyEdits while the rest of a document is kept byte for byte.
+package parser
@@ -0,0 +1,72 @@
+package parser
+
+import "io"
+
+// documentParser is a base of parsers of multiline dialects whose rules span
+// several lines. Comments between rules are recognized with comment function
+// of a dialect and they are accumulated in order to be flushed as separate
+// statements later. Lexemes of lines are recognized with tokenize function of
+// a dialect when a document could not be parsed semantically.
+type documentParser struct {
+	SyntacticParser
+
+	comments []*Comment
+
+	// comment parses a comment at the current position. It returns nil
+	// comment without error if there is no comment.
+	comment func() (*Comment, error)
+
+	// tokenize splits the current line into lexemes without syntax checks.
+	tokenize func() []Node
+}
+
+func newDocumentParser(reader io.Reader) documentParser {
+	return documentParser{SyntacticParser: *NewSyntacticParser(reader)}
+}
+
+// parseGap skips whitespaces and comments.
+func (p *documentParser) parseGap() error {
+	for p.pos < len(p.buf) {
+		if isWhitespace(p.buf[p.pos]) {
+			p.pos++
+			continue
+		}
+
+		var comment, err = p.comment()
+		if err != nil {
+			return err
+		} else if comment == nil {
+			return nil
+		}
+		p.comments = append(p.comments, comment)
+	}
+	return nil
+}
+
+// parseOptCComment parses comment like in C if there is one at the current
+// position.
+func (p *documentParser) parseOptCComment() (*Comment, error) {
+	if !p.lookingAt("/*") && !p.lookingAt("//") {
+		return nil, nil
+	}
+	return p.parseCComment()
+}
+
+// flushComments appends accumulated comments to statements.
+func (p *documentParser) flushComments(stmts []*Statement) []*Statement {
+	for _, comment := range p.comments {
+		stmts = append(stmts, &Statement{Comment: comment})
+	}
+	p.comments = p.comments[:0]
+	return stmts
+}
+
+// parseLexemes splits every line of input into lexemes without syntax checks.
+// It is used for highlighting lines which could not be parsed semantically.
+func (p *documentParser) parseLexemes() ([][]Node, error) {
+	return parseLines(p.Reader, func(line []byte) ([]Node, error) {
+		p.buf = line
+		p.pos = skipBOM(p.buf)
+		return p.tokenize(), nil
+	})
+}
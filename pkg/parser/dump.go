@@ -0,0 +1,78 @@
+package parser
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Dump writes parse tree in human-readable form. Every node is written on its
+// own line with indentation by depth, kind, name, and one-based positions
+// where it begins and ends.
+func (ast *AST) Dump(w io.Writer) error {
+	return ast.DumpLines(w, 0, -1)
+}
+
+// DumpLines writes the same as Dump but only for statements or lines of
+// lexemes which begin within zero-based range of lines [first, last]. Negative
+// last line means the end of source.
+func (ast *AST) DumpLines(w io.Writer, first, last int) error {
+	var out = bufio.NewWriter(w)
+	var inRange = func(line int) bool {
+		return line >= first && (last < 0 || line <= last)
+	}
+
+	var tree = ast.tree()
+	for _, stmt := range tree.Statements {
+		if line, ok := stmt.line(); ok && inRange(line) {
+			stmt.dump(out, 0)
+		}
+	}
+
+	for _, nodes := range tree.Lines {
+		if len(nodes) == 0 {
+			continue
+		}
+		var line, ok = nodes[0].line()
+		if !ok || !inRange(line) {
+			continue
+		}
+		out.WriteString("Line " + strconv.Itoa(line+1) + "\n")
+		for _, node := range nodes {
+			node.dump(out, 1)
+		}
+	}
+
+	return out.Flush()
+}
+
+// line returns zero-based line where a subtree begins.
+func (n *jsonNode) line() (int, bool) {
+	var line, ok = 0, false
+	if n.Begin != nil {
+		line, ok = n.Begin.Line, true
+	}
+	for _, child := range n.Children {
+		if begin, found := child.line(); found && (!ok || begin < line) {
+			line, ok = begin, true
+		}
+	}
+	return line, ok
+}
+
+func (n *jsonNode) dump(out *bufio.Writer, depth int) {
+	out.WriteString(strings.Repeat("  ", depth))
+	out.WriteString(n.Kind)
+	if n.Name != nil {
+		out.WriteString(" " + strconv.Quote(*n.Name))
+	}
+	if n.Begin != nil && n.End != nil {
+		out.WriteString(" " + n.Begin.String() + "-" + n.End.String())
+	}
+	out.WriteString("\n")
+
+	for idx := range n.Children {
+		n.Children[idx].dump(out, depth+1)
+	}
+}
@@ -0,0 +1,31 @@
+package parser
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDump(t *testing.T) {
+	var ast, _ = Parse([]byte("<a> ::= \"b\"\n<c> ::= <a> <a>"))
+
+	var out bytes.Buffer
+	if err := ast.DumpLines(&out, 1, 1); err != nil {
+		t.Fatalf("failed to dump: %s", err)
+	}
+
+	var expected = "Statement\n" +
+		"  AssignmentExpression \"::=\" 2:5-2:8\n" +
+		"    NonTerminal \"c\" 2:1-2:4\n" +
+		"    CompoundExpression\n" +
+		"      NonTerminal \"a\" 2:9-2:12\n" +
+		"      NonTerminal \"a\" 2:13-2:16\n"
+	if actual := out.String(); actual != expected {
+		t.Errorf("wrong dump of the second line:\n%s", actual)
+	}
+
+	out.Reset()
+	ast.Dump(&out)
+	if lines := bytes.Count(out.Bytes(), []byte{'\n'}); lines != 10 {
+		t.Errorf("wrong number of lines in dump: %d", lines)
+	}
+}
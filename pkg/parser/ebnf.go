@@ -0,0 +1,484 @@
+package parser
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strconv"
+)
+
+// EBNFParser performs semantic parsing of grammars written in Extended BNF as
+// defined in ISO/IEC 14977.
+//
+// Comments could be placed anywhere between lexemes. Each of them is stored
+// as a separate statement without rule right after the rule it belongs to.
+type EBNFParser struct {
+	documentParser
+}
+
+func NewEBNFParser(reader io.Reader) *EBNFParser {
+	var p = &EBNFParser{documentParser: newDocumentParser(reader)}
+	p.comment, p.tokenize = p.parseOptComment, p.parseLineLexemes
+	return p
+}
+
+// ParseEBNF parses grammar written in ISO EBNF. Likewise Parse, it falls back
+// to lexical parsing on error.
+func ParseEBNF(source []byte) (*AST, error) {
+	var ast, errSem = NewEBNFParser(bytes.NewReader(source)).Parse()
+	if errSem == nil {
+		return ast, nil
+	}
+
+	var lemmes, errSyn = NewEBNFParser(bytes.NewReader(source)).parseLexemes()
+	if errSyn != nil {
+		return nil, errSyn
+	}
+
+	return &AST{err: errSem, lemmes: lemmes, source: source}, nil
+}
+
+func (p *EBNFParser) Parse() (*AST, error) {
+	if bytes, err := ioutil.ReadAll(p.Reader); err != nil {
+		return nil, err
+	} else {
+		p.buf = bytes
+		p.pos = skipBOM(p.buf)
+	}
+
+	var rules, err = p.parseSyntax()
+
+	switch err := err.(type) {
+	case *DescError:
+		return nil, locate(err, p.buf)
+	case error:
+		return nil, locate(&Error{err: err, pos: p.pos}, p.buf)
+	default:
+		return &AST{rules: rules, semantic: true, source: p.buf}, nil
+	}
+}
+
+func (p *EBNFParser) parseSyntax() ([]*Statement, error) {
+	var stmts []*Statement
+
+	for {
+		if err := p.parseGap(); err != nil {
+			return nil, err
+		}
+
+		stmts = p.flushComments(stmts)
+
+		if err := p.eof(); err != nil {
+			return stmts, nil
+		}
+
+		if stmt, err := p.parseRule(); err != nil {
+			return nil, err
+		} else {
+			stmts = append(stmts, stmt)
+			stmts = p.flushComments(stmts)
+		}
+	}
+}
+
+func (p *EBNFParser) parseRule() (*Statement, error) {
+	var err error
+	var expr = new(AssignmentExpression)
+
+	if expr.LeftChild, err = p.parseMetaIdentifier(); err != nil {
+		return nil, err
+	}
+
+	if err = p.parseGap(); err != nil {
+		return nil, err
+	}
+
+	if !p.lookingAt("=") {
+		return nil, NewDescError(p.failure(), p.pos, "'='")
+	} else {
+		expr.Token = Token{Name: []byte{'='}, Begin: p.pos, End: p.pos + 1}
+		p.pos++
+	}
+
+	if err = p.parseGap(); err != nil {
+		return nil, err
+	}
+
+	if expr.RightChild, err = p.parseDefinitionsList(); err != nil {
+		return nil, err
+	}
+
+	if !p.lookingAt(";") && !p.lookingAt(".") {
+		return nil, NewDescError(p.failure(), p.pos, "',' or '|' or ';'")
+	} else {
+		p.pos++
+	}
+
+	return &Statement{Rule: expr}, nil
+}
+
+// parseDefinitionsList parses alternatives separated with `|` and builds
+// right-recursive chain of AlternativeExpression as semantic parser of BNF
+// does.
+func (p *EBNFParser) parseDefinitionsList() (Node, error) {
+	var defs []Node
+	var bars []Token
+
+	for {
+		if def, err := p.parseSingleDefinition(); err != nil {
+			return nil, err
+		} else {
+			defs = append(defs, def)
+		}
+
+		if p.eof() != nil || !isDefinitionSeparator(p.buf[p.pos]) {
+			break
+		}
+
+		var name = []byte{p.buf[p.pos]}
+		bars = append(bars, Token{Name: name, Begin: p.pos, End: p.pos + 1})
+		p.pos++
+
+		if err := p.parseGap(); err != nil {
+			return nil, err
+		}
+	}
+
+	var node = defs[len(defs)-1]
+	for idx := len(bars) - 1; idx >= 0; idx-- {
+		node = &AlternativeExpression{Expression{
+			Token:      bars[idx],
+			LeftChild:  defs[idx],
+			RightChild: node,
+		}}
+	}
+
+	return node, nil
+}
+
+// parseSingleDefinition parses terms separated with `,` and builds
+// right-recursive chain of CompoundExpression. Token of every compound
+// expression is a comma between its children.
+func (p *EBNFParser) parseSingleDefinition() (Node, error) {
+	var terms []Node
+	var commas []Token
+
+	for {
+		if term, err := p.parseTerm(); err != nil {
+			return nil, err
+		} else {
+			terms = append(terms, term)
+		}
+
+		if !p.lookingAt(",") {
+			break
+		}
+
+		commas = append(commas, Token{
+			Name:  []byte{','},
+			Begin: p.pos,
+			End:   p.pos + 1,
+		})
+		p.pos++
+
+		if err := p.parseGap(); err != nil {
+			return nil, err
+		}
+	}
+
+	var node = terms[len(terms)-1]
+	for idx := len(commas) - 1; idx >= 0; idx-- {
+		node = &CompoundExpression{Expression{
+			Token:      commas[idx],
+			LeftChild:  terms[idx],
+			RightChild: node,
+		}}
+	}
+
+	return node, nil
+}
+
+// parseTerm parses a factor which is optionally followed by exception `-` and
+// another factor. Exception becomes ExceptionExpression which token is `-`.
+func (p *EBNFParser) parseTerm() (Node, error) {
+	var left, err = p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.parseGap(); err != nil {
+		return nil, err
+	}
+
+	if !p.lookingAt("-") {
+		return left, nil
+	}
+
+	var expr = &ExceptionExpression{Expression{
+		Token:     Token{Name: []byte{'-'}, Begin: p.pos, End: p.pos + 1},
+		LeftChild: left,
+	}}
+	p.pos++
+
+	if err := p.parseGap(); err != nil {
+		return nil, err
+	}
+
+	if expr.RightChild, err = p.parseFactor(); err != nil {
+		return nil, err
+	}
+
+	if err := p.parseGap(); err != nil {
+		return nil, err
+	}
+
+	return expr, nil
+}
+
+// parseFactor parses a primary which is optionally preceded by repetition
+// factor. Factor `3 *` repeats a primary exactly three times. As an extension,
+// factor `2*4` repeats it from two to four times. Both of them become
+// RepetitionExpression which token is a factor.
+func (p *EBNFParser) parseFactor() (Node, error) {
+	if p.eof() != nil || !isDigit(p.buf[p.pos]) {
+		return p.parsePrimary()
+	}
+
+	var begin = p.pos
+	var min, err = p.parseInteger()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.parseGap(); err != nil {
+		return nil, err
+	}
+	if !p.lookingAt("*") {
+		return nil, NewDescError(p.failure(), p.pos, "'*'")
+	}
+	p.pos++
+
+	var name = strconv.Itoa(min) + "*"
+	var end, max = p.pos, min
+	if err := p.parseGap(); err != nil {
+		return nil, err
+	}
+	if p.eof() == nil && isDigit(p.buf[p.pos]) {
+		if max, err = p.parseInteger(); err != nil {
+			return nil, err
+		}
+		name += strconv.Itoa(max)
+		end = p.pos
+		if err := p.parseGap(); err != nil {
+			return nil, err
+		}
+	}
+	if max < min {
+		p.pos = begin
+		return nil, ErrInvalidBounds
+	}
+
+	var primary, errPrimary = p.parsePrimary()
+	if errPrimary != nil {
+		return nil, errPrimary
+	}
+	return &RepetitionExpression{
+		Expression: Expression{
+			Token:     Token{Name: []byte(name), Begin: begin, End: end},
+			LeftChild: primary,
+		},
+		Min: min,
+		Max: max,
+	}, nil
+}
+
+func (p *EBNFParser) parsePrimary() (Node, error) {
+	if err := p.eof(); err != nil {
+		return nil, NewDescError(err, p.pos, "term")
+	}
+
+	var begin = p.pos
+
+	switch char := p.buf[p.pos]; {
+	case char == '(' && !p.lookingAt("(*"):
+		return p.parseGroup(GroupParen, ')')
+	case char == '[':
+		return p.parseGroup(GroupOptional, ']')
+	case char == '{':
+		return p.parseGroup(GroupRepetition, '}')
+	case char == '"' || char == '\'':
+		if name, err := p.parseQuoted(char); err != nil {
+			return nil, err
+		} else {
+			return &Terminal{Token{name, begin, p.pos}}, nil
+		}
+	case char == '?':
+		if name, err := p.parseQuoted(char); err != nil {
+			return nil, err
+		} else {
+			return &SpecialSequence{Token{name, begin, p.pos}}, nil
+		}
+	case isLetter(char):
+		return p.parseMetaIdentifier()
+	default:
+		return nil, NewDescError(ErrUnexpectedChar, p.pos, "term")
+	}
+}
+
+func (p *EBNFParser) parseGroup(kind GroupKind, closing byte) (Node, error) {
+	var err error
+	var group = &GroupExpression{Kind: kind}
+	group.Begin = p.pos
+	p.pos++
+
+	if err = p.parseGap(); err != nil {
+		return nil, err
+	}
+
+	if group.LeftChild, err = p.parseDefinitionsList(); err != nil {
+		return nil, err
+	}
+
+	if !p.lookingAt(string(closing)) {
+		var desc = "'" + string(closing) + "'"
+		return nil, NewDescError(p.failure(), p.pos, desc)
+	}
+
+	p.pos++
+	group.End = p.pos
+	return group, nil
+}
+
+// parseMetaIdentifier parses name of a rule. Since spaces inside of meta
+// identifier are insignificant, they are collapsed into single one.
+func (p *EBNFParser) parseMetaIdentifier() (Node, error) {
+	if p.eof() != nil || !isLetter(p.buf[p.pos]) {
+		return nil, NewDescError(p.failure(), p.pos, "meta identifier")
+	}
+
+	var name []byte
+	var begin, end = p.pos, p.pos
+
+	for p.pos < len(p.buf) {
+		if char := p.buf[p.pos]; isMetaIdentifierChar(char) {
+			name = append(name, char)
+			p.pos++
+			end = p.pos
+			continue
+		} else if char != ' ' && char != '\t' {
+			break
+		}
+
+		// Look ahead whether identifier continues after spaces.
+		var next = p.pos
+		for next < len(p.buf) && (p.buf[next] == ' ' || p.buf[next] == '\t') {
+			next++
+		}
+
+		if next == len(p.buf) || !isMetaIdentifierChar(p.buf[next]) {
+			break
+		}
+
+		name = append(name, ' ')
+		p.pos = next
+	}
+
+	p.pos = end
+	return &NonTerminal{Token{name, begin, end}}, nil
+}
+
+// parseOptComment parses comment `(* ... *)` if there is one at the current
+// position.
+func (p *EBNFParser) parseOptComment() (*Comment, error) {
+	if !p.lookingAt("(*") {
+		return nil, nil
+	}
+	return p.parseBlockComment()
+}
+
+func (p *EBNFParser) parseBlockComment() (*Comment, error) {
+	var token = Token{Begin: p.pos}
+	var length = bytes.Index(p.buf[p.pos+2:], []byte("*)"))
+
+	if length < 0 {
+		p.pos = len(p.buf)
+		return nil, NewDescError(io.EOF, p.pos, "'*)'")
+	}
+
+	p.pos += length + 4
+	token.End = p.pos
+	return &Comment{token}, nil
+}
+
+func (p *EBNFParser) parseLineLexemes() []Node {
+	var tokens []Node
+
+	for p.pos < len(p.buf) {
+		var begin = p.pos
+		var char = p.buf[p.pos]
+
+		switch {
+		case p.lookingAt("(*"):
+			var length = bytes.Index(p.buf[p.pos+2:], []byte("*)"))
+			if length < 0 {
+				p.pos = len(p.buf)
+			} else {
+				p.pos += length + 4
+			}
+			tokens = append(tokens, &Comment{Token{nil, begin, p.pos}})
+		case char == '"' || char == '\'' || char == '?':
+			if name, err := p.parseQuoted(char); err != nil {
+				p.pos = begin + 1
+			} else if char == '?' {
+				var token = Token{name, begin, p.pos}
+				tokens = append(tokens, &SpecialSequence{token})
+			} else {
+				tokens = append(tokens, &Terminal{Token{name, begin, p.pos}})
+			}
+		case char == '=':
+			var token = Token{[]byte{char}, begin, begin + 1}
+			tokens = append(tokens, &AssignmentExpression{Expression{
+				Token: token,
+			}})
+			p.pos++
+		case isDefinitionSeparator(char):
+			var token = Token{[]byte{char}, begin, begin + 1}
+			tokens = append(tokens, &AlternativeExpression{Expression{
+				Token: token,
+			}})
+			p.pos++
+		case char == '-':
+			var token = Token{[]byte{char}, begin, begin + 1}
+			tokens = append(tokens, &ExceptionExpression{Expression{
+				Token: token,
+			}})
+			p.pos++
+		case isLetter(char):
+			var node, _ = p.parseMetaIdentifier()
+			tokens = append(tokens, node)
+		default:
+			p.pos++
+		}
+	}
+
+	return tokens
+}
+
+func isDefinitionSeparator(char byte) bool {
+	return char == '|' || char == '/' || char == '!'
+}
+
+func isDigit(char byte) bool {
+	return char >= '0' && char <= '9'
+}
+
+func isLetter(char byte) bool {
+	return (char >= 'A' && char <= 'Z') || (char >= 'a' && char <= 'z')
+}
+
+func isMetaIdentifierChar(char byte) bool {
+	return isLetter(char) || isDigit(char) || char == '_'
+}
+
+func isWhitespace(char byte) bool {
+	return char == ' ' || char == '\t' || char == '\n' || char == '\r'
+}
@@ -0,0 +1,164 @@
+package parser
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEBNFParser(t *testing.T) {
+	t.Run("Rule", func(t *testing.T) {
+		var content = []byte(`digits = digit, {digit} | "0" (* zero *);`)
+		var parser = NewEBNFParser(bytes.NewBuffer(content))
+		var ast, err = parser.Parse()
+
+		if err != nil {
+			t.Fatalf("failed to parse grammar: %s", err)
+		}
+
+		if length := ast.NoRules(); length != 2 {
+			t.Fatalf("rule and comment statements are expected: %d", length)
+		}
+
+		var rule = ast.rules[0].Rule
+		if lhs, ok := rule.Left().(*NonTerminal); !ok {
+			t.Fatalf("wrong type of lhs: %T", rule.Left())
+		} else if name := string(lhs.Name); name != "digits" {
+			t.Errorf("wrong rule name: %s", name)
+		}
+
+		var alt, ok = rule.Right().(*AlternativeExpression)
+		if !ok {
+			t.Fatalf("wrong type of rhs: %T", rule.Right())
+		}
+
+		if compound, ok := alt.Left().(*CompoundExpression); !ok {
+			t.Errorf("wrong type of the first alternative: %T", alt.Left())
+		} else if group, ok := compound.Right().(*GroupExpression); !ok {
+			t.Errorf("wrong type of repetition: %T", compound.Right())
+		} else if group.Kind != GroupRepetition {
+			t.Errorf("wrong kind of group: %d", group.Kind)
+		}
+
+		if term, ok := alt.Right().(*Terminal); !ok {
+			t.Errorf("wrong type of the second alternative: %T", alt.Right())
+		} else if term.Begin != 26 || term.End != 29 {
+			t.Errorf("wrong position of terminal: %s", term)
+		}
+
+		if comment := ast.rules[1].Comment; comment == nil {
+			t.Errorf("comment is missing")
+		}
+	})
+
+	t.Run("RepetitionFactor", func(t *testing.T) {
+		var bounds = map[string][2]int{
+			`hex = 4 * digit;`:   {4, 4},
+			`hex = 2*4 digit;`:   {2, 4},
+			`hex = 1 * 1"x";`:    {1, 1},
+			`hex = 0*3 (a|b);`:   {0, 3},
+			`hex = 2 * 2 digit;`: {2, 2},
+		}
+		for source, bound := range bounds {
+			var ast, err = ParseEBNF([]byte(source))
+			if err != nil || ast.Error() != nil {
+				t.Fatalf("failed to parse %q: %s %s", source, err,
+					ast.Error())
+			}
+			var rhs = ast.rules[0].Rule.Right()
+			if rep, ok := rhs.(*RepetitionExpression); !ok {
+				t.Errorf("wrong type of rhs of %q: %T", source, rhs)
+			} else if rep.Min != bound[0] || rep.Max != bound[1] {
+				t.Errorf("wrong bounds of %q: %d %d", source, rep.Min,
+					rep.Max)
+			}
+		}
+	})
+
+	t.Run("InvalidRepetitionFactor", func(t *testing.T) {
+		var ast, err = ParseEBNF([]byte(`hex = "x", 4*2 digit;`))
+		if err != nil {
+			t.Fatalf("failed to parse grammar: %s", err)
+		}
+		var diags = Diagnostics(ast)
+		if len(diags) != 1 || diags[0].Code != CodeInvalidBounds {
+			t.Fatalf("wrong diagnostics: %v", diags)
+		} else if diags[0].Range.Begin != 11 {
+			t.Errorf("wrong range of diagnostic: %v", diags[0].Range)
+		}
+	})
+
+	t.Run("Exception", func(t *testing.T) {
+		var sources = map[string]string{
+			`r = letter - "x";`:         "NonTerminal",
+			`r = (a | b) - c;`:          "GroupExpression",
+			`r = {a} - c;`:              "GroupExpression",
+			`r = ? any char ? - "x";`:   "SpecialSequence",
+			`r = "a", letter - "x", b;`: "NonTerminal",
+		}
+		for source, kind := range sources {
+			var ast, err = ParseEBNF([]byte(source))
+			if err != nil || ast.Error() != nil {
+				t.Fatalf("failed to parse %q: %s %s", source, err,
+					ast.Error())
+			}
+
+			var rhs = ast.rules[0].Rule.Right()
+			if compound, ok := rhs.(*CompoundExpression); ok {
+				rhs = compound.Right().(*CompoundExpression).Left()
+			}
+			var pos = bytes.IndexByte([]byte(source), '-')
+			var except, ok = rhs.(*ExceptionExpression)
+			if !ok {
+				t.Errorf("wrong type of rhs of %q: %T", source, rhs)
+			} else if left := Kind(except.Left()); left != kind {
+				t.Errorf("wrong left child of %q: %s", source, left)
+			} else if except.Right() == nil {
+				t.Errorf("missing right child of %q", source)
+			} else if except.Begin != pos {
+				t.Errorf("wrong position of %q: %d", source, except.Begin)
+			}
+		}
+	})
+
+	t.Run("MetaIdentifierWithSpaces", func(t *testing.T) {
+		var ast, err = ParseEBNF([]byte(`syntax  rule = meta identifier;`))
+		if err != nil || ast.Error() != nil {
+			t.Fatalf("failed to parse grammar: %s %s", err, ast.Error())
+		}
+
+		var lhs = ast.rules[0].Rule.Left().(*NonTerminal)
+		if name := string(lhs.Name); name != "syntax rule" {
+			t.Errorf("wrong rule name: %q", name)
+		}
+	})
+
+	t.Run("Fallback", func(t *testing.T) {
+		var ast, err = ParseEBNF([]byte(`letter = "a" | "b"`))
+		if err != nil {
+			t.Fatalf("failed to parse grammar: %s", err)
+		}
+
+		if ast.Error() == nil {
+			t.Fatalf("missing terminator is not reported")
+		}
+
+		if numb := len(ast.lemmes[0]); numb != 5 {
+			t.Errorf("wrong number of lexemes: %d", numb)
+		}
+	})
+
+	t.Run("EBNF", func(t *testing.T) {
+		var content = readBNFFile(t, "ebnf.ebnf")
+		var parser = NewEBNFParser(bytes.NewBuffer(content))
+		var ast, err = parser.Parse()
+
+		if err != nil {
+			t.Fatalf("failed to parse grammar: %s", err)
+		}
+
+		// There are 15 rules and 2 comments.
+		if length := ast.NoRules(); length != 17 {
+			t.Errorf("wrong number of statements: %d", length)
+		}
+	})
+}
@@ -6,7 +6,10 @@ import (
 )
 
 var ErrEmptyRule = errors.New("bnf: rule is empty")
+var ErrInvalidBounds = errors.New("bnf: lower bound of repetition exceeds " +
+	"upper one")
 var ErrNoStatements = errors.New("bnf: there is no production statements")
+var ErrUnclosedGroup = errors.New("bnf: unclosed '('")
 var ErrNotImplemented = errors.New("bnf: not implemented")
 var ErrUnexpectedChar = errors.New("bnf: unexpected character")
 
@@ -19,10 +22,26 @@ type Stringer interface {
 type Error struct {
 	err error
 	pos int
+	at  Position
 }
 
 func (e *Error) Error() string {
-	return e.err.Error() + " at position " + strconv.Itoa(e.pos)
+	return e.err.Error() + " at position " + strconv.Itoa(e.pos+1)
+}
+
+// Pos returns zero-based byte offset where parsing failed.
+func (e *Error) Pos() int {
+	return e.pos
+}
+
+// Position returns line and column where parsing failed.
+func (e *Error) Position() Position {
+	return e.at
+}
+
+// Unwrap returns underlying error in order to support errors.Is.
+func (e *Error) Unwrap() error {
+	return e.err
 }
 
 // DescError represents error which is occured during semantic parsing. It is
@@ -48,3 +67,34 @@ func (e *DescError) String() string {
 func (e *DescError) Error() string {
 	return e.Base.Error()
 }
+
+// Pos returns zero-based byte offset where parsing failed.
+func (e *DescError) Pos() int {
+	return e.Base.Pos()
+}
+
+// Position returns line and column where parsing failed.
+func (e *DescError) Position() Position {
+	return e.Base.Position()
+}
+
+// Unwrap returns underlying error in order to support errors.Is.
+func (e *DescError) Unwrap() error {
+	return e.Base.err
+}
+
+// locate resolves line and column of parsing error in a source. Other errors
+// are returned as is.
+func locate(err error, source []byte) error {
+	var base *Error
+	switch err := err.(type) {
+	case *Error:
+		base = err
+	case *DescError:
+		base = &err.Base
+	default:
+		return err
+	}
+	base.at = NewLineIndex(source).Position(source, base.pos)
+	return err
+}
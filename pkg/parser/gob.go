@@ -0,0 +1,193 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+)
+
+// gobNode is a serializable representation of a node for binary caches.
+// Unlike JSON representation, it keeps children in their slots, so a tree
+// could be restored exactly.
+type gobNode struct {
+	Kind     string
+	Name     []byte
+	Begin    int
+	End      int
+	Group    GroupKind
+	Min      int
+	Max      int
+	Negative bool
+	Left     *gobNode
+	Right    *gobNode
+}
+
+func newGobNode(node Node) *gobNode {
+	if node == nil {
+		return nil
+	}
+
+	var res = &gobNode{Kind: Kind(node)}
+	switch node := node.(type) {
+	case *GroupExpression:
+		res.Group = node.Kind
+	case *RepetitionExpression:
+		res.Min, res.Max = node.Min, node.Max
+	case *PredicateExpression:
+		res.Negative = node.Negative
+	}
+
+	if token := tokenOf(node); token != nil {
+		res.Name, res.Begin, res.End = token.Name, token.Begin, token.End
+	}
+
+	if _, ok := node.(*Statement); !ok {
+		res.Left, res.Right = newGobNode(node.Left()), newGobNode(node.Right())
+	}
+	return res
+}
+
+// tokenOf returns token of a node or nil if node has no token (e.g.
+// statement).
+func tokenOf(node Node) *Token {
+	switch node := node.(type) {
+	case *Token:
+		return node
+	case *Comment:
+		return &node.Token
+	case *NonTerminal:
+		return &node.Token
+	case *Terminal:
+		return &node.Token
+	case *Epsilon:
+		return &node.Token
+	case *SpecialSequence:
+		return &node.Token
+	case *CharacterClass:
+		return &node.Token
+	case *Wildcard:
+		return &node.Token
+	case *AlternativeExpression:
+		return &node.Token
+	case *AssignmentExpression:
+		return &node.Token
+	case *CompoundExpression:
+		return &node.Token
+	case *ExceptionExpression:
+		return &node.Token
+	case *GroupExpression:
+		return &node.Token
+	case *RepetitionExpression:
+		return &node.Token
+	case *PredicateExpression:
+		return &node.Token
+	default:
+		return nil
+	}
+}
+
+// node restores a node from its serializable representation.
+func (n *gobNode) node() (Node, error) {
+	if n == nil {
+		return nil, nil
+	}
+
+	var left, err = n.Left.node()
+	if err != nil {
+		return nil, err
+	}
+
+	right, err := n.Right.node()
+	if err != nil {
+		return nil, err
+	}
+
+	var token = Token{Name: n.Name, Begin: n.Begin, End: n.End}
+	var expr = Expression{Token: token, LeftChild: left, RightChild: right}
+	switch n.Kind {
+	case "Token":
+		return &token, nil
+	case "Comment":
+		return &Comment{token}, nil
+	case "NonTerminal":
+		return &NonTerminal{token}, nil
+	case "Terminal":
+		return &Terminal{token}, nil
+	case "Epsilon":
+		return &Epsilon{token}, nil
+	case "SpecialSequence":
+		return &SpecialSequence{token}, nil
+	case "CharacterClass":
+		return &CharacterClass{token}, nil
+	case "Wildcard":
+		return &Wildcard{token}, nil
+	case "AlternativeExpression":
+		return &AlternativeExpression{expr}, nil
+	case "AssignmentExpression":
+		return &AssignmentExpression{expr}, nil
+	case "CompoundExpression":
+		return &CompoundExpression{expr}, nil
+	case "ExceptionExpression":
+		return &ExceptionExpression{expr}, nil
+	case "GroupExpression":
+		return &GroupExpression{expr, n.Group}, nil
+	case "RepetitionExpression":
+		return &RepetitionExpression{expr, n.Min, n.Max}, nil
+	case "PredicateExpression":
+		return &PredicateExpression{expr, n.Negative}, nil
+	default:
+		return nil, fmt.Errorf("bnf: unknown kind of node: %q", n.Kind)
+	}
+}
+
+// gobStatement is a serializable representation of a statement.
+type gobStatement struct {
+	Rule    *gobNode
+	Comment *gobNode
+	Doc     string
+}
+
+// GobEncode serializes a statement, so parse trees could be cached on disk.
+func (s *Statement) GobEncode() ([]byte, error) {
+	var stmt = gobStatement{Doc: s.Doc}
+	if s.Rule != nil {
+		stmt.Rule = newGobNode(s.Rule)
+	}
+	if s.Comment != nil {
+		stmt.Comment = newGobNode(s.Comment)
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&stmt); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GobDecode restores a statement which is serialized with GobEncode.
+func (s *Statement) GobDecode(data []byte) error {
+	var stmt gobStatement
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&stmt); err != nil {
+		return err
+	}
+
+	*s = Statement{Doc: stmt.Doc}
+	if rule, err := stmt.Rule.node(); err != nil {
+		return err
+	} else if rule != nil {
+		var ok bool
+		if s.Rule, ok = rule.(*AssignmentExpression); !ok {
+			return fmt.Errorf("bnf: statement has no assignment")
+		}
+	}
+
+	if comment, err := stmt.Comment.node(); err != nil {
+		return err
+	} else if comment != nil {
+		var ok bool
+		if s.Comment, ok = comment.(*Comment); !ok {
+			return fmt.Errorf("bnf: statement has wrong comment")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/gob"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGobStatements(t *testing.T) {
+	for dialect, filename := range map[Dialect]string{
+		DialectEBNF:  "ebnf.ebnf",
+		DialectPEG:   "peg.peg",
+		DialectYacc:  "calc.y",
+		DialectANTLR: "expr.g4",
+	} {
+		var source, err = ioutil.ReadFile(filepath.Join("testdata", filename))
+		if err != nil {
+			t.Fatalf("failed to read %s: %s", filename, err)
+		}
+
+		ast, err := ParseDialect(dialect, source)
+		if err != nil {
+			t.Fatalf("failed to parse %s: %s", filename, err)
+		}
+
+		var stmts = ast.Statements()
+		stmts[0].Doc = "Documentation of the first statement."
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(stmts); err != nil {
+			t.Fatalf("failed to encode %s: %s", filename, err)
+		}
+
+		var decoded []*Statement
+		if err := gob.NewDecoder(&buf).Decode(&decoded); err != nil {
+			t.Fatalf("failed to decode %s: %s", filename, err)
+		}
+
+		if !reflect.DeepEqual(stmts, decoded) {
+			t.Errorf("statements of %s are not restored", filename)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package parser
+
+import "encoding/json"
+
+// jsonNode is a serializable representation of a node. Range is a byte range
+// of token of a node. Positions are set only if a node is serialized as a part
+// of AST since a node does not know its source.
+type jsonNode struct {
+	Kind     string     `json:"kind"`
+	Name     *string    `json:"name,omitempty"`
+	Range    Range      `json:"range"`
+	Begin    *Position  `json:"begin,omitempty"`
+	End      *Position  `json:"end,omitempty"`
+	Group    string     `json:"group,omitempty"`
+	Min      *int       `json:"min,omitempty"`
+	Max      *int       `json:"max,omitempty"`
+	Negative bool       `json:"negative,omitempty"`
+	Children []jsonNode `json:"children,omitempty"`
+}
+
+// jsonAST is a serializable representation of AST. Semantic parse tree has
+// statements while syntactic one has lexemes for every line.
+type jsonAST struct {
+	Semantic   bool         `json:"semantic"`
+	Error      string       `json:"error,omitempty"`
+	Statements []jsonNode   `json:"statements,omitempty"`
+	Lines      [][]jsonNode `json:"lines,omitempty"`
+}
+
+var groupNames = map[GroupKind]string{
+	GroupParen:      "paren",
+	GroupOptional:   "optional",
+	GroupRepetition: "repetition",
+}
+
+// newJSONNode converts a subtree to serializable representation. Function
+// locate converts byte offsets to positions if it is not nil.
+func newJSONNode(node Node, locate func(int) Position) jsonNode {
+	var res = jsonNode{Kind: Kind(node)}
+	var token *Token
+
+	switch node := node.(type) {
+	case *Statement:
+	case *Comment:
+		token = &node.Token
+	case *NonTerminal:
+		token = &node.Token
+	case *Terminal:
+		token = &node.Token
+	case *Epsilon:
+		token = &node.Token
+	case *SpecialSequence:
+		token = &node.Token
+	case *CharacterClass:
+		token = &node.Token
+	case *Wildcard:
+		token = &node.Token
+	case *AlternativeExpression:
+		token = &node.Token
+	case *AssignmentExpression:
+		token = &node.Token
+	case *CompoundExpression:
+		// Compound expression has no token in source.
+	case *ExceptionExpression:
+		token = &node.Token
+	case *GroupExpression:
+		token = &node.Token
+		res.Group = groupNames[node.Kind]
+	case *RepetitionExpression:
+		token = &node.Token
+		res.Min, res.Max = &node.Min, &node.Max
+	case *PredicateExpression:
+		token = &node.Token
+		res.Negative = node.Negative
+	case *Token:
+		token = node
+	}
+
+	if token != nil {
+		if token.Name != nil {
+			var name = string(token.Name)
+			res.Name = &name
+		}
+		res.Range = Range{token.Begin, token.End}
+	} else {
+		res.Range = Range{-1, -1}
+	}
+
+	if locate != nil && res.Range.Begin >= 0 {
+		var begin, end = locate(res.Range.Begin), locate(res.Range.End)
+		res.Begin, res.End = &begin, &end
+	}
+
+	for _, child := range []Node{node.Left(), node.Right()} {
+		if child != nil {
+			res.Children = append(res.Children, newJSONNode(child, locate))
+		}
+	}
+
+	return res
+}
+
+// MarshalJSON serializes parse tree with positions of all nodes.
+func (ast *AST) MarshalJSON() ([]byte, error) {
+	return json.Marshal(ast.tree())
+}
+
+// tree converts parse tree to serializable representation. Syntactic parse
+// tree is converted line by line.
+func (ast *AST) tree() jsonAST {
+	var res = jsonAST{Semantic: ast.semantic}
+	if ast.err != nil {
+		res.Error = ast.err.Error()
+	}
+
+	for _, stmt := range ast.rules {
+		res.Statements = append(res.Statements,
+			newJSONNode(stmt, ast.Position))
+	}
+
+	for _, lemmes := range ast.lemmes {
+		var nodes = []jsonNode{}
+		for _, node := range lemmes {
+			nodes = append(nodes, newJSONNode(node, ast.Position))
+		}
+		res.Lines = append(res.Lines, nodes)
+	}
+
+	return res
+}
+
+func (s *Statement) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newJSONNode(s, nil))
+}
+
+func (c *Comment) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newJSONNode(c, nil))
+}
+
+func (t *NonTerminal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newJSONNode(t, nil))
+}
+
+func (t *Terminal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newJSONNode(t, nil))
+}
+
+func (t *Epsilon) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newJSONNode(t, nil))
+}
+
+func (t *SpecialSequence) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newJSONNode(t, nil))
+}
+
+func (t *CharacterClass) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newJSONNode(t, nil))
+}
+
+func (t *Wildcard) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newJSONNode(t, nil))
+}
+
+func (e *AlternativeExpression) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newJSONNode(e, nil))
+}
+
+func (e *AssignmentExpression) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newJSONNode(e, nil))
+}
+
+func (e *CompoundExpression) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newJSONNode(e, nil))
+}
+
+func (e *ExceptionExpression) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newJSONNode(e, nil))
+}
+
+func (e *GroupExpression) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newJSONNode(e, nil))
+}
+
+func (e *RepetitionExpression) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newJSONNode(e, nil))
+}
+
+func (e *PredicateExpression) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newJSONNode(e, nil))
+}
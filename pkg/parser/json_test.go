@@ -0,0 +1,75 @@
+package parser
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	t.Run("Semantic", func(t *testing.T) {
+		var ast, _ = Parse([]byte("<a> ::= \"b\" | <c>"))
+		var bytes, err = json.Marshal(ast)
+		if err != nil {
+			t.Fatalf("failed to marshal: %s", err)
+		}
+
+		var res jsonAST
+		if err := json.Unmarshal(bytes, &res); err != nil {
+			t.Fatalf("failed to unmarshal: %s", err)
+		}
+		if !res.Semantic || len(res.Statements) != 1 {
+			t.Fatalf("wrong tree: %s", bytes)
+		}
+
+		var rule = res.Statements[0].Children[0]
+		if rule.Kind != "AssignmentExpression" || len(rule.Children) != 2 {
+			t.Fatalf("wrong rule: %+v", rule)
+		}
+
+		var alt = rule.Children[1]
+		if alt.Kind != "AlternativeExpression" {
+			t.Fatalf("wrong kind of rhs: %s", alt.Kind)
+		}
+
+		var term = alt.Children[0]
+		if term.Kind != "Terminal" || *term.Name != "b" {
+			t.Errorf("wrong terminal: %+v", term)
+		}
+		if term.Begin == nil || *term.Begin != (Position{0, 8, 8}) {
+			t.Errorf("wrong position of terminal: %v", term.Begin)
+		}
+	})
+
+	t.Run("Syntactic", func(t *testing.T) {
+		var ast, _ = Parse([]byte("<a> ::= <b\n<c> ::= \"d\""))
+		var bytes, err = json.Marshal(ast)
+		if err != nil {
+			t.Fatalf("failed to marshal: %s", err)
+		}
+
+		var res jsonAST
+		json.Unmarshal(bytes, &res)
+		if res.Semantic || res.Error == "" || len(res.Lines) != 2 {
+			t.Fatalf("wrong tree: %s", bytes)
+		}
+
+		var lhs = res.Lines[1][0]
+		if lhs.Begin == nil || *lhs.Begin != (Position{1, 0, 11}) {
+			t.Errorf("wrong position of lexeme: %+v", lhs)
+		}
+	})
+
+	t.Run("Node", func(t *testing.T) {
+		var node = &RepetitionExpression{Min: 1, Max: -1}
+		var bytes, err = json.Marshal(node)
+		if err != nil {
+			t.Fatalf("failed to marshal: %s", err)
+		}
+
+		var expected = `{"kind":"RepetitionExpression",` +
+			`"range":{"begin":0,"end":0},"min":1,"max":-1}`
+		if string(bytes) != expected {
+			t.Errorf("wrong JSON: %s", bytes)
+		}
+	})
+}
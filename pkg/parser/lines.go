@@ -0,0 +1,189 @@
+package parser
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Position is a location in a source. Line and column are zero-based and
+// column is counted in characters. Offset is a byte offset from the beginning
+// of the source. Errors and diagnostics carry positions while nodes of parse
+// trees carry offsets which are converted to positions by AST.
+type Position struct {
+	Line   int `json:"line"`
+	Col    int `json:"col"`
+	Offset int `json:"offset"`
+}
+
+// String returns one-based line and column separated with colon in the same
+// way as compilers do.
+func (p Position) String() string {
+	return strconv.Itoa(p.Line+1) + ":" + strconv.Itoa(p.Col+1)
+}
+
+// bom is byte order mark of UTF-8 which editors on Windows put at the
+// beginning of files.
+var bom = []byte("\ufeff")
+
+// skipBOM returns size of byte order mark at the beginning of a source or
+// zero. Parsers start from the end of mark, so offsets remain relative to the
+// source.
+func skipBOM(source []byte) int {
+	if bytes.HasPrefix(source, bom) {
+		return len(bom)
+	}
+	return 0
+}
+
+// LineIndex maps byte offsets of a document which is joined from lines to
+// line and column numbers. It keeps offsets where every line begins.
+type LineIndex []int
+
+// JoinLines joins lines of a document with new line characters and builds
+// index of line beginnings.
+func JoinLines(lines [][]byte) ([]byte, LineIndex) {
+	var source []byte
+	var index = make(LineIndex, 0, len(lines))
+
+	for idx, line := range lines {
+		if idx > 0 {
+			source = append(source, '\n')
+		}
+		index = append(index, len(source))
+		source = append(source, line...)
+	}
+
+	return source, index
+}
+
+// NewLineIndex builds index of line beginnings of a source where lines are
+// separated with new line characters.
+func NewLineIndex(source []byte) LineIndex {
+	var index = LineIndex{0}
+	for idx, char := range source {
+		if char == '\n' {
+			index = append(index, idx+1)
+		}
+	}
+	return index
+}
+
+// Locate returns zero-based line and column (in bytes) of an offset.
+func (idx LineIndex) Locate(offset int) (int, int) {
+	if len(idx) == 0 {
+		return 0, offset
+	}
+
+	var line = sort.Search(len(idx), func(i int) bool {
+		return idx[i] > offset
+	}) - 1
+
+	if line < 0 {
+		line = 0
+	}
+
+	return line, offset - idx[line]
+}
+
+// Position converts byte offset to position in a source which the index is
+// built for.
+func (idx LineIndex) Position(source []byte, offset int) Position {
+	var line, col = idx.Locate(offset)
+	if begin := offset - col; begin >= 0 && begin <= len(source) {
+		col = Column(source[begin:], col)
+	}
+	return Position{Line: line, Col: col, Offset: offset}
+}
+
+// Column converts byte offset in a line to zero-based column in characters.
+// Invalid UTF-8 sequences are counted byte by byte. Byte order mark is not
+// shown by editors, so it is not counted.
+func Column(line []byte, offset int) int {
+	if size := skipBOM(line); size > 0 && offset >= size {
+		line, offset = line[size:], offset-size
+	}
+	if offset > len(line) {
+		return utf8.RuneCount(line) + offset - len(line)
+	} else if offset < 0 {
+		return offset
+	}
+	return utf8.RuneCount(line[:offset])
+}
+
+// VirtualColumn converts byte offset in a line to zero-based column on screen
+// where tabs are expanded up to the next multiple of tabstop. Tabs are counted
+// as single characters if tabstop is not positive, so it equals to Column.
+func VirtualColumn(line []byte, offset, tabstop int) int {
+	if tabstop <= 0 || offset < 0 {
+		return Column(line, offset)
+	} else if size := skipBOM(line); size > 0 && offset >= size {
+		line, offset = line[size:], offset-size
+	}
+
+	var col, tail = 0, 0
+	if offset > len(line) {
+		offset, tail = len(line), offset-len(line)
+	}
+	for _, char := range string(line[:offset]) {
+		if char == '\t' {
+			col += tabstop - col%tabstop
+		} else {
+			col++
+		}
+	}
+	return col + tail
+}
+
+// parseLines splits input into lines and parses every line on its own. Lines
+// are passed without line breaks, so offsets of their nodes are relative to
+// lines. They are moved to the beginning of input afterwards. Lines which
+// could not be parsed are skipped.
+func parseLines(
+	reader io.Reader, parse func(line []byte) ([]Node, error),
+) ([][]Node, error) {
+	var source, err = ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines [][]Node
+	for begin := 0; begin < len(source); {
+		var line, next = source[begin:], len(source)
+		if end := bytes.IndexByte(line, '\n'); end >= 0 {
+			line, next = line[:end], begin+end+1
+		}
+		line = bytes.TrimSuffix(line, []byte{'\r'})
+		if nodes, err := parse(line); err == nil {
+			lines = append(lines, shiftNodes(nodes, begin))
+		}
+		begin = next
+	}
+
+	return lines, nil
+}
+
+// shiftNodes moves tokens of nodes and their subtrees by offset.
+func shiftNodes(nodes []Node, offset int) []Node {
+	if offset == 0 {
+		return nodes
+	}
+
+	var shift = func(cursor *Cursor) error {
+		if _, ok := cursor.Node.(*CompoundExpression); ok {
+			return nil // Compound expression has no token in source.
+		}
+		if token := tokenOf(cursor.Node); token != nil {
+			token.Begin += offset
+			token.End += offset
+		}
+		return nil
+	}
+	for _, node := range nodes {
+		Walk(node, shift, nil)
+	}
+	return nodes
+}
@@ -0,0 +1,139 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// Operators are spellings of operators of classic BNF. Lexer of BNF is driven
+// by them, so grammars in home-grown notations like `<a> -> <b> / <c>` could
+// be parsed in the same way as classic ones.
+type Operators struct {
+	// Definition is spellings of operator which separates non-terminal from
+	// its right-hand side like `::=`.
+	Definition []string
+	// Alternation is spellings of operator which separates alternatives like
+	// `|`.
+	Alternation []string
+}
+
+// DefaultOperators are operators of classic BNF.
+var DefaultOperators = Operators{
+	Definition:  []string{"::="},
+	Alternation: []string{"|"},
+}
+
+// notations are dialects of BNF with custom operators which are registered
+// on demand by Notation.
+var notations = struct {
+	sync.RWMutex
+	operators map[Dialect]*Operators
+}{operators: make(map[Dialect]*Operators)}
+
+// Notation returns dialect of classic BNF which is written with custom
+// operators. It is DialectBNF if operators are default ones or if there are
+// no operators at all. Otherwise, a new dialect is registered on the first
+// call. It behaves in the same way as DialectBNF does except that its lexer
+// recognizes given operators.
+func Notation(ops Operators) Dialect {
+	ops = Operators{
+		Definition:  spellings(ops.Definition, DefaultOperators.Definition),
+		Alternation: spellings(ops.Alternation, DefaultOperators.Alternation),
+	}
+	var dialect = notationName(ops)
+	if dialect == notationName(DefaultOperators) {
+		return DialectBNF
+	}
+
+	notations.Lock()
+	defer notations.Unlock()
+	if _, ok := notations.operators[dialect]; !ok {
+		notations.operators[dialect] = &ops
+	}
+	return dialect
+}
+
+func notationName(ops Operators) Dialect {
+	return Dialect(fmt.Sprintf("%s%q%q", DialectBNF, ops.Definition,
+		ops.Alternation))
+}
+
+// spellings removes empty and repeated spellings and orders them from the
+// longest to the shortest one, so that the longest operator wins. Defaults
+// are used if there is no spellings.
+func spellings(values, defaults []string) []string {
+	var result []string
+	var seen = make(map[string]bool)
+	for _, value := range values {
+		if value != "" && !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	if len(result) == 0 {
+		return defaults
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return len(result[i]) > len(result[j])
+	})
+	return result
+}
+
+// notation returns operators of a dialect which is registered by Notation.
+func notation(dialect Dialect) (*Operators, bool) {
+	notations.RLock()
+	defer notations.RUnlock()
+	var ops, ok = notations.operators[dialect]
+	return ops, ok
+}
+
+// Operators returns operators of a dialect of classic BNF. It reports false
+// if dialect is not BNF or its notation.
+func (d Dialect) Operators() (Operators, bool) {
+	if d == DialectBNF {
+		return DefaultOperators, true
+	} else if ops, ok := notation(d); ok {
+		return *ops, true
+	}
+	return Operators{}, false
+}
+
+// Base returns dialect which notation is derived from, i.e. DialectBNF for
+// notations with custom operators and dialect itself otherwise.
+func (d Dialect) Base() Dialect {
+	if _, ok := notation(d); ok {
+		return DialectBNF
+	}
+	return d
+}
+
+// ParseOperators parses BNF grammar which is written with custom operators.
+// See Parse for details.
+func ParseOperators(source []byte, ops Operators) (*AST, error) {
+	var origin bytes.Buffer
+	var replica = io.TeeReader(bytes.NewBuffer(source), &origin)
+	var semantic = NewSemanticParser(replica)
+	semantic.ops = &ops
+	var astSem, errSem = semantic.Parse()
+
+	if errSem == nil {
+		return astSem, nil
+	}
+
+	// Fallback to syntactic parser on error.
+	var syntactic = NewSyntacticParser(&origin)
+	syntactic.ops = &ops
+	var astSyn, errSyn = syntactic.Parse()
+
+	if errSyn != nil {
+		return nil, errSyn
+	}
+
+	astSyn.err = errSem
+	astSyn.source = source
+	return astSyn, nil
+}
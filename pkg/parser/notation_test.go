@@ -0,0 +1,55 @@
+package parser
+
+import "testing"
+
+func TestNotation(t *testing.T) {
+	if dialect := Notation(Operators{}); dialect != DialectBNF {
+		t.Errorf("default notation is not bnf: %s", dialect)
+	}
+	if dialect := Notation(DefaultOperators); dialect != DialectBNF {
+		t.Errorf("default notation is not bnf: %s", dialect)
+	}
+
+	var dialect = Notation(Operators{
+		Definition:  []string{":=", "::=", ""},
+		Alternation: []string{"/"},
+	})
+	if dialect.Base() != DialectBNF || dialect.Multiline() {
+		t.Errorf("wrong base of notation: %s", dialect.Base())
+	}
+	if found, ok := LookupDialect(string(dialect)); !ok || found != dialect {
+		t.Errorf("notation is not found: %s", found)
+	}
+
+	var ops, ok = dialect.Operators()
+	if !ok || len(ops.Definition) != 2 || ops.Definition[0] != "::=" {
+		t.Errorf("wrong operators of notation: %v", ops)
+	}
+	if _, ok := DialectEBNF.Operators(); ok {
+		t.Errorf("ebnf has operators of bnf")
+	}
+
+	for _, line := range []string{
+		`<a> ::= <b> / "c"`,
+		`<a> := <b> / "c"`,
+	} {
+		var ast, err = ParseDialect(dialect, []byte(line))
+		if err == nil {
+			err = ast.Error()
+		}
+		if err != nil {
+			t.Fatalf("failed to parse %s: %s", line, err)
+		}
+
+		var rule = ast.Statements()[0].Rule
+		if alts := Alternatives(rule.Right()); len(alts) != 2 {
+			t.Errorf("wrong number of alternatives of %s: %d", line,
+				len(alts))
+		}
+	}
+
+	var ast, _ = ParseDialect(dialect, []byte(`<a> ::= <b> | <c>`))
+	if ast.Error() == nil {
+		t.Errorf("alternation of classic bnf is accepted")
+	}
+}
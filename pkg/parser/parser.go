@@ -1,10 +1,8 @@
-// Package bnf contains parser for BNF metalanguage.
 package parser
 
 import (
-	"bytes"
-	"io"
 	"strconv"
+	"sync"
 )
 
 // AST type corresponds parsed BNF grammar. We use the same AST type for both
@@ -20,6 +18,11 @@ type AST struct {
 	rules []*Statement
 	// True if the AST was produced be semantic parser.
 	semantic bool
+	// Source which the AST is parsed from and index of its lines which is
+	// built on demand.
+	source    []byte
+	index     LineIndex
+	indexOnce sync.Once
 }
 
 // Error provides access to saved semantic parsing errors.
@@ -27,6 +30,24 @@ func (ast *AST) Error() error {
 	return ast.err
 }
 
+// Semantic reports whether the tree is produced by semantic parser. Otherwise,
+// semantic parser failed and lexemes are recognized by syntactic one.
+func (ast *AST) Semantic() bool {
+	return ast.semantic
+}
+
+// Prefix returns length of valid prefix of parsed source, i.e. byte offset
+// where semantic parsing failed. It is length of the source if there is no
+// error. Lexemes of syntactic parse tree which end after the prefix are
+// recognized by fallback only.
+func (ast *AST) Prefix() int {
+	var err, ok = ast.err.(interface{ Pos() int })
+	if ast.semantic || !ok || err.Pos() > len(ast.source) {
+		return len(ast.source)
+	}
+	return err.Pos()
+}
+
 // NoRules gets the number of parsed rules.
 func (ast *AST) NoRules() int {
 	if ast.semantic {
@@ -36,6 +57,32 @@ func (ast *AST) NoRules() int {
 	}
 }
 
+// Statements returns production rules of semantic parse tree. Syntactic parse
+// tree has no statements at all.
+func (ast *AST) Statements() []*Statement {
+	return ast.rules
+}
+
+// Position converts byte offset in parsed source to line and column. Offsets
+// of nodes of both semantic and syntactic parse trees are relative to the
+// beginning of source.
+func (ast *AST) Position(offset int) Position {
+	return ast.lineIndex().Position(ast.source, offset)
+}
+
+func (ast *AST) lineIndex() LineIndex {
+	ast.indexOnce.Do(func() {
+		ast.index = NewLineIndex(ast.source)
+	})
+	return ast.index
+}
+
+// Locate returns positions where a node begins and ends in parsed source.
+func (ast *AST) Locate(node Node) (Position, Position) {
+	var span = Span(node)
+	return ast.Position(span.Begin), ast.Position(span.End)
+}
+
 // String returns textua representation of an object.
 func (ast *AST) String() string {
 	var norules = ast.NoRules()
@@ -54,14 +101,23 @@ func (ast *AST) Traverse(visitor VisitorFunc) (int, error) {
 }
 
 func (ast *AST) traverseSemanticTree(visitor VisitorFunc) (int, error) {
-	// TODO(@daskol): Remove this tests in the future!
 	if len(ast.rules) == 0 {
 		return 0, ErrNoStatements
-	} else if ast.rules[0] == nil {
-		return 0, ErrEmptyRule
-	} else {
-		return ast.visit(ast.rules[0], visitor)
 	}
+
+	var counter = 0
+	for _, stmt := range ast.rules {
+		if stmt == nil {
+			return counter, ErrEmptyRule
+		}
+
+		var nonodes, err = ast.visit(stmt, visitor)
+		if counter += nonodes; err != nil {
+			return counter, err
+		}
+	}
+
+	return counter, nil
 }
 
 func (ast *AST) traverseSyntacticTree(visitor VisitorFunc) (int, error) {
@@ -118,22 +174,5 @@ func (ast *AST) visit(root Node, f VisitorFunc) (int, error) {
 
 // Parse parses BNF grammar.
 func Parse(source []byte) (*AST, error) {
-	var origin bytes.Buffer
-	var replica = io.TeeReader(bytes.NewBuffer(source), &origin)
-	var astSem, errSem = NewSemanticParser(replica).Parse()
-
-	if errSem == nil {
-		return astSem, nil
-	}
-
-	// Fallback to syntactic parser on error.
-	var astSyn, errSyn = NewSyntacticParser(&origin).Parse()
-
-	if errSyn != nil {
-		return nil, errSyn
-	} else {
-		astSyn.err = errSem
-	}
-
-	return astSyn, nil
+	return ParseOperators(source, DefaultOperators)
 }
@@ -0,0 +1,167 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseFile(t *testing.T) {
+	var filenames, _ = filepath.Glob("testdata/*")
+	for _, filename := range filenames {
+		var dialect, ok = LookupExtension(filename)
+		if !ok {
+			continue
+		}
+		var ast, err = ParseFile(filename)
+		if err != nil {
+			t.Errorf("failed to parse %s as %s: %s", filename, dialect, err)
+		} else if ast.NoRules() == 0 {
+			t.Errorf("no rules are parsed from %s", filename)
+		}
+	}
+
+	if dialect := DetectDialect("testdata/xml.w3c"); dialect != DialectW3C {
+		t.Errorf("wrong dialect of W3C grammar: %s", dialect)
+	}
+
+	if _, err := ParseFile("testdata/missing.bnf"); !os.IsNotExist(err) {
+		t.Errorf("wrong error on missing file: %v", err)
+	}
+}
+
+func ExampleParse() {
+	var ast, err = Parse([]byte(`<digit> ::= "0" | "1"`))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, stmt := range ast.Statements() {
+		var name = stmt.Rule.Left().(*NonTerminal).Name
+		var alts = Alternatives(stmt.Rule.Right())
+		fmt.Printf("%s has %d alternatives\n", name, len(alts))
+	}
+	// Output: digit has 2 alternatives
+}
+
+func TestPosition(t *testing.T) {
+	var source = []byte("a = \"б\" ;\nb = \"в\", c ;")
+	var ast, err = ParseEBNF(source)
+	if err != nil {
+		t.Fatalf("failed to parse grammar: %s", err)
+	}
+
+	var stmt = ast.Statements()[1]
+	var begin, end = ast.Locate(stmt.Rule.Right())
+	if begin != (Position{Line: 1, Col: 4, Offset: 15}) {
+		t.Errorf("wrong begin of rule: %+v", begin)
+	}
+	if end != (Position{Line: 1, Col: 10, Offset: 22}) {
+		t.Errorf("wrong end of rule: %+v", end)
+	}
+	if str := begin.String(); str != "2:5" {
+		t.Errorf("wrong textual representation: %s", str)
+	}
+
+	source = []byte("a = b ;\nc = \"d ;")
+	_, err = NewEBNFParser(bytes.NewReader(source)).Parse()
+	var diag = NewDiagnostic(err)
+	// Unterminated terminal is reported at the end of the second line.
+	var col = len(source) - bytes.IndexByte(source, '\n') - 1
+	if diag.Start.Line != 1 || diag.Start.Col != col {
+		t.Errorf("wrong start of diagnostic: %+v", diag)
+	}
+}
+
+func TestPositionSyntactic(t *testing.T) {
+	var ast, err = Parse([]byte("<a> ::= <b\r\n<c> ::= \"d\""))
+	if err != nil {
+		t.Fatalf("failed to parse grammar: %s", err)
+	} else if ast.Semantic() || len(ast.lemmes) != 2 {
+		t.Fatalf("wrong syntactic parse tree: %+v", ast.lemmes)
+	}
+
+	var name = ast.lemmes[1][0]
+	if begin, end := ast.Locate(name); begin.String() != "2:1" ||
+		end.String() != "2:4" || begin.Offset != 12 {
+		t.Errorf("wrong position of rule name: %+v-%+v", begin, end)
+	}
+
+	var assign = ast.lemmes[1][1]
+	if begin, end := ast.Locate(assign); begin.String() != "2:5" ||
+		end.String() != "2:8" {
+		t.Errorf("wrong position of operator: %+v-%+v", begin, end)
+	}
+
+	ast, _ = Parse([]byte("<a> ::= \"b\"\n<c> ::= \"d\""))
+	var stmt = ast.Statements()[1]
+	if begin, end := ast.Locate(stmt); begin.String() != "2:1" ||
+		end.String() != "2:12" {
+		t.Errorf("wrong position of statement: %+v-%+v", begin, end)
+	}
+}
+
+func TestVirtualColumn(t *testing.T) {
+	var line = []byte("\tab\t\"ф\"\t")
+	var cases = []struct {
+		offset, tabstop, col int
+	}{
+		{0, 8, 0},
+		{1, 8, 8},
+		{3, 8, 10},
+		{4, 8, 16},
+		{4, 4, 8},
+		{8, 4, 11},
+		{9, 4, 12},
+		{11, 4, 14},
+		{4, 0, 4},
+		{8, 0, 7},
+	}
+	for _, c := range cases {
+		if col := VirtualColumn(line, c.offset, c.tabstop); col != c.col {
+			t.Errorf("wrong column at %d with tabstop %d: %d", c.offset,
+				c.tabstop, col)
+		}
+	}
+}
+
+func TestParseDialectBOM(t *testing.T) {
+	var sources = map[Dialect]string{
+		DialectBNF:   "<a> ::= <b>",
+		DialectEBNF:  "a = b ;",
+		DialectW3C:   "a ::= b",
+		DialectANTLR: "grammar g;\r\na : b ;\r\n",
+		DialectYacc:  "%%\r\na : b ;\r\n",
+		DialectPEG:   "a <- b\r\n",
+	}
+	for dialect, source := range sources {
+		var ast, err = ParseDialect(dialect, []byte("\ufeff"+source))
+		if err != nil {
+			t.Errorf("%s: failed to parse grammar: %s", dialect, err)
+		} else if !ast.Semantic() || len(ast.Statements()) != 1 {
+			t.Errorf("%s: byte order mark is not skipped", dialect)
+		}
+	}
+}
+
+func TestPrefix(t *testing.T) {
+	var cases = []struct {
+		source string
+		prefix int
+	}{
+		{`<a> ::= <b> | "c"`, 17},
+		{`<a> ::= <b> ) <c>`, 12},
+		{`; comment`, 0},
+	}
+	for _, c := range cases {
+		var ast, err = Parse([]byte(c.source))
+		if err != nil {
+			t.Fatalf("failed to parse %q: %s", c.source, err)
+		}
+		if prefix := ast.Prefix(); prefix != c.prefix {
+			t.Errorf("wrong prefix of %q: %d", c.source, prefix)
+		}
+	}
+}
@@ -0,0 +1,412 @@
+package parser
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+)
+
+// PEGParser performs semantic parsing of Parsing Expression Grammars in
+// notation of B. Ford. Rules like `Name <- Expression` are not terminated
+// explicitly, so a rule lasts until the next rule definition.
+//
+// Ordered choice `/` is an alternative expression and predicates `&` and `!`
+// are predicate expressions. Literals are terminals, classes like `[a-z]` are
+// character classes, and `.` is a wildcard. Comments start with `#` and last
+// until the end of line. Empty sequence is presented with an empty special
+// sequence.
+type PEGParser struct {
+	documentParser
+}
+
+func NewPEGParser(reader io.Reader) *PEGParser {
+	var p = &PEGParser{documentParser: newDocumentParser(reader)}
+	p.comment, p.tokenize = p.parseOptComment, p.parseLineLexemes
+	return p
+}
+
+// ParsePEG parses Parsing Expression Grammar. Likewise Parse, it falls back to
+// lexical parsing on error.
+func ParsePEG(source []byte) (*AST, error) {
+	var ast, errSem = NewPEGParser(bytes.NewReader(source)).Parse()
+	if errSem == nil {
+		return ast, nil
+	}
+
+	var lemmes, errSyn = NewPEGParser(bytes.NewReader(source)).parseLexemes()
+	if errSyn != nil {
+		return nil, errSyn
+	}
+
+	return &AST{err: errSem, lemmes: lemmes, source: source}, nil
+}
+
+func (p *PEGParser) Parse() (*AST, error) {
+	if bytes, err := ioutil.ReadAll(p.Reader); err != nil {
+		return nil, err
+	} else {
+		p.buf = bytes
+		p.pos = skipBOM(p.buf)
+	}
+
+	var rules, err = p.parseSyntax()
+
+	switch err := err.(type) {
+	case *DescError:
+		return nil, locate(err, p.buf)
+	case error:
+		return nil, locate(&Error{err: err, pos: p.pos}, p.buf)
+	default:
+		return &AST{rules: rules, semantic: true, source: p.buf}, nil
+	}
+}
+
+func (p *PEGParser) parseSyntax() ([]*Statement, error) {
+	var stmts []*Statement
+
+	for {
+		p.parseGap()
+		stmts = p.flushComments(stmts)
+
+		if err := p.eof(); err != nil {
+			return stmts, nil
+		}
+
+		if stmt, err := p.parseRule(); err != nil {
+			return nil, err
+		} else {
+			stmts = append(stmts, stmt)
+			stmts = p.flushComments(stmts)
+		}
+	}
+}
+
+func (p *PEGParser) parseRule() (*Statement, error) {
+	var err error
+	var expr = new(AssignmentExpression)
+
+	if expr.LeftChild, err = p.parseIdentifier(); err != nil {
+		return nil, err
+	}
+
+	p.parseGap()
+
+	if !p.lookingAt("<-") {
+		return nil, NewDescError(p.failure(), p.pos, "'<-'")
+	} else {
+		expr.Token = Token{Name: []byte("<-"), Begin: p.pos, End: p.pos + 2}
+		p.pos += 2
+	}
+
+	p.parseGap()
+
+	if expr.RightChild, err = p.parseChoice(); err != nil {
+		return nil, err
+	}
+
+	// Rule ends either with input or with the next rule.
+	if p.eof() == nil && !p.lookingAtRule() {
+		return nil, NewDescError(ErrUnexpectedChar, p.pos, "'/' or term")
+	}
+
+	return &Statement{Rule: expr}, nil
+}
+
+// parseChoice parses ordered choice of sequences separated with `/` into
+// right-recursive chain of AlternativeExpression.
+func (p *PEGParser) parseChoice() (Node, error) {
+	var seqs []Node
+	var bars []Token
+
+	for {
+		if seq, err := p.parseSequence(); err != nil {
+			return nil, err
+		} else {
+			seqs = append(seqs, seq)
+		}
+
+		if !p.lookingAt("/") {
+			break
+		}
+
+		bars = append(bars, Token{
+			Name:  []byte{'/'},
+			Begin: p.pos,
+			End:   p.pos + 1,
+		})
+		p.pos++
+		p.parseGap()
+	}
+
+	var node = seqs[len(seqs)-1]
+	for idx := len(bars) - 1; idx >= 0; idx-- {
+		node = &AlternativeExpression{Expression{
+			Token:      bars[idx],
+			LeftChild:  seqs[idx],
+			RightChild: node,
+		}}
+	}
+
+	return node, nil
+}
+
+// parseSequence parses prefixed terms into right-recursive chain of
+// CompoundExpression. Sequence stops before the next rule definition.
+func (p *PEGParser) parseSequence() (Node, error) {
+	var terms []Node
+	var begin = p.pos
+
+	for p.lookingAtTerm() && !p.lookingAtRule() {
+		if term, err := p.parsePrefix(); err != nil {
+			return nil, err
+		} else {
+			terms = append(terms, term)
+		}
+		p.parseGap()
+	}
+
+	if len(terms) == 0 {
+		return &SpecialSequence{Token{nil, begin, begin}}, nil
+	}
+
+	var node = terms[len(terms)-1]
+	for idx := len(terms) - 2; idx >= 0; idx-- {
+		var span = Span(terms[idx])
+		node = &CompoundExpression{Expression{
+			Token:      Token{Begin: span.Begin, End: span.End},
+			LeftChild:  terms[idx],
+			RightChild: node,
+		}}
+	}
+
+	return node, nil
+}
+
+// parsePrefix parses a term with optional predicate operator.
+func (p *PEGParser) parsePrefix() (Node, error) {
+	if !p.lookingAt("&") && !p.lookingAt("!") {
+		return p.parseSuffix()
+	}
+
+	var err error
+	var expr = &PredicateExpression{
+		Expression: Expression{Token: Token{
+			Name:  []byte{p.buf[p.pos]},
+			Begin: p.pos,
+			End:   p.pos + 1,
+		}},
+		Negative: p.buf[p.pos] == '!',
+	}
+
+	p.pos++
+	p.parseGap()
+
+	if expr.RightChild, err = p.parseSuffix(); err != nil {
+		return nil, err
+	}
+
+	return expr, nil
+}
+
+func (p *PEGParser) parseSuffix() (Node, error) {
+	var node, err = p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.eof() == nil {
+		var min, max int
+		switch p.buf[p.pos] {
+		case '?':
+			min, max = 0, 1
+		case '*':
+			min, max = 0, -1
+		case '+':
+			min, max = 1, -1
+		default:
+			return node, nil
+		}
+
+		node = &RepetitionExpression{
+			Expression: Expression{
+				Token: Token{
+					Name:  []byte{p.buf[p.pos]},
+					Begin: p.pos,
+					End:   p.pos + 1,
+				},
+				LeftChild: node,
+			},
+			Min: min,
+			Max: max,
+		}
+		p.pos++
+	}
+
+	return node, nil
+}
+
+func (p *PEGParser) parsePrimary() (Node, error) {
+	if err := p.eof(); err != nil {
+		return nil, NewDescError(err, p.pos, "term")
+	}
+
+	var begin = p.pos
+
+	switch char := p.buf[p.pos]; {
+	case char == '(':
+		return p.parseGroup()
+	case char == '[':
+		if err := p.skipCharacterSet(); err != nil {
+			return nil, err
+		}
+		var token = Token{p.buf[begin+1 : p.pos-1], begin, p.pos}
+		return &CharacterClass{token}, nil
+	case char == '.':
+		p.pos++
+		return &Wildcard{Token{p.buf[begin:p.pos], begin, p.pos}}, nil
+	case char == '"' || char == '\'':
+		if name, err := p.parseCLiteral(char); err != nil {
+			return nil, err
+		} else {
+			return &Terminal{Token{name, begin, p.pos}}, nil
+		}
+	case isNameStartChar(char):
+		return p.parseIdentifier()
+	default:
+		return nil, NewDescError(ErrUnexpectedChar, p.pos, "term")
+	}
+}
+
+func (p *PEGParser) parseGroup() (Node, error) {
+	var err error
+	var group = &GroupExpression{Kind: GroupParen}
+	group.Begin = p.pos
+	p.pos++
+	p.parseGap()
+
+	if group.LeftChild, err = p.parseChoice(); err != nil {
+		return nil, err
+	}
+
+	if !p.lookingAt(")") {
+		return nil, NewDescError(p.failure(), p.pos, "')'")
+	}
+
+	p.pos++
+	group.End = p.pos
+	return group, nil
+}
+
+func (p *PEGParser) parseIdentifier() (Node, error) {
+	if p.eof() != nil || !isNameStartChar(p.buf[p.pos]) {
+		return nil, NewDescError(p.failure(), p.pos, "identifier")
+	}
+
+	var begin = p.pos
+	for p.eof() == nil && (isNameStartChar(p.buf[p.pos]) ||
+		isDigit(p.buf[p.pos])) {
+		p.pos++
+	}
+
+	var name = p.buf[begin:p.pos]
+	return &NonTerminal{Token{name, begin, p.pos}}, nil
+}
+
+// parseOptComment parses comment which starts with `#` if there is one at the
+// current position.
+func (p *PEGParser) parseOptComment() (*Comment, error) {
+	if p.buf[p.pos] != '#' {
+		return nil, nil
+	}
+	return p.parseLineComment(), nil
+}
+
+// parseLineComment parses comment which starts with `#` and lasts until the
+// end of line.
+func (p *PEGParser) parseLineComment() *Comment {
+	var token = Token{Begin: p.pos}
+	if idx := bytes.IndexByte(p.buf[p.pos:], '\n'); idx < 0 {
+		p.pos = len(p.buf)
+	} else {
+		p.pos += idx
+	}
+	token.End = p.pos
+	return &Comment{token}
+}
+
+// lookingAtRule checks whether a rule definition starts at the current
+// position, i.e. there is an identifier followed by `<-`.
+func (p *PEGParser) lookingAtRule() bool {
+	var offset = p.pos
+	defer func() {
+		p.pos = offset
+	}()
+
+	if _, err := p.parseIdentifier(); err != nil {
+		return false
+	}
+
+	for p.eof() == nil && isWhitespace(p.buf[p.pos]) {
+		p.pos++
+	}
+
+	return p.lookingAt("<-")
+}
+
+func (p *PEGParser) lookingAtTerm() bool {
+	if p.eof() != nil {
+		return false
+	}
+
+	switch char := p.buf[p.pos]; char {
+	case '(', '[', '.', '"', '\'', '&', '!':
+		return true
+	default:
+		return isNameStartChar(char)
+	}
+}
+
+func (p *PEGParser) parseLineLexemes() []Node {
+	var tokens []Node
+
+	for p.pos < len(p.buf) {
+		var begin = p.pos
+		var char = p.buf[p.pos]
+		var token = Token{[]byte{char}, begin, begin + 1}
+
+		switch {
+		case char == '#':
+			tokens = append(tokens, p.parseLineComment())
+		case p.lookingAt("<-"):
+			token.Name, token.End = []byte("<-"), begin+2
+			var expr = Expression{Token: token}
+			tokens = append(tokens, &AssignmentExpression{expr})
+			p.pos += 2
+		case char == '/':
+			var expr = Expression{Token: token}
+			tokens = append(tokens, &AlternativeExpression{expr})
+			p.pos++
+		case char == '&' || char == '!':
+			var expr = PredicateExpression{Expression: Expression{
+				Token: token,
+			}}
+			tokens = append(tokens, &expr)
+			p.pos++
+		case char == '?' || char == '*' || char == '+':
+			var expr = Expression{Token: token}
+			tokens = append(tokens, &RepetitionExpression{Expression: expr})
+			p.pos++
+		case char == '.' || char == '[' || char == '"' || char == '\'' ||
+			isNameStartChar(char):
+			if node, err := p.parsePrimary(); err != nil {
+				p.pos = begin + 1
+			} else {
+				tokens = append(tokens, node)
+			}
+		default:
+			p.pos++
+		}
+	}
+
+	return tokens
+}
@@ -0,0 +1,82 @@
+package parser
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPEGParser(t *testing.T) {
+	t.Run("Rule", func(t *testing.T) {
+		var content = []byte(`Primary <- Ident !'<-' / '(' Expr ')' / . / `)
+		var parser = NewPEGParser(bytes.NewBuffer(content))
+		var ast, err = parser.Parse()
+
+		if err != nil {
+			t.Fatalf("failed to parse grammar: %s", err)
+		}
+
+		if length := ast.NoRules(); length != 1 {
+			t.Fatalf("wrong number of statements: %d", length)
+		}
+
+		var alts = Alternatives(ast.rules[0].Rule.Right())
+		if len(alts) != 4 {
+			t.Fatalf("wrong number of alternatives: %d", len(alts))
+		}
+
+		var compound, ok = alts[0].(*CompoundExpression)
+		if !ok {
+			t.Fatalf("wrong type of the first alternative: %T", alts[0])
+		}
+
+		if pred, ok := compound.Right().(*PredicateExpression); !ok {
+			t.Errorf("wrong type of predicate: %T", compound.Right())
+		} else if !pred.Negative {
+			t.Errorf("predicate is expected to be negative")
+		} else if _, ok := pred.Right().(*Terminal); !ok {
+			t.Errorf("wrong type of predicate operand: %T", pred.Right())
+		}
+
+		if span := Span(compound.Right()); span.Begin != 17 || span.End != 22 {
+			t.Errorf("wrong span of predicate: %v", span)
+		}
+
+		if _, ok := alts[2].(*Wildcard); !ok {
+			t.Errorf("wrong type of the third alternative: %T", alts[2])
+		}
+
+		if _, ok := alts[3].(*SpecialSequence); !ok {
+			t.Errorf("wrong type of empty alternative: %T", alts[3])
+		}
+	})
+
+	t.Run("Unexpected", func(t *testing.T) {
+		var content = []byte("Expr <- Term )\n")
+		var parser = NewPEGParser(bytes.NewBuffer(content))
+		if _, err := parser.Parse(); err == nil {
+			t.Errorf("error is expected for unbalanced parenthesis")
+		}
+	})
+
+	t.Run("PEG", func(t *testing.T) {
+		var content = readBNFFile(t, "peg.peg")
+		var parser = NewPEGParser(bytes.NewBuffer(content))
+		var ast, err = parser.Parse()
+
+		if err != nil {
+			t.Fatalf("failed to parse grammar: %s", err)
+		}
+
+		if length := ast.NoRules(); length != 32 {
+			t.Errorf("wrong number of rules: %d", length)
+		}
+
+		var stmt = ast.rules[len(ast.rules)-1]
+		if stmt.Rule == nil {
+			t.Fatalf("the last statement is not a rule")
+		} else if lhs := stmt.Rule.Left().(*NonTerminal); string(lhs.Name) !=
+			"EndOfFile" {
+			t.Errorf("wrong name of the last rule: %s", lhs.Name)
+		}
+	})
+}
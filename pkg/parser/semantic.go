@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"errors"
 	"io"
 	"io/ioutil"
 )
@@ -20,18 +21,20 @@ func (p *SemanticParser) Parse() (*AST, error) {
 		return nil, err
 	} else {
 		p.buf = bytes
-		p.pos = 0
+		p.pos = skipBOM(p.buf)
 	}
 
 	var rules, err = p.parseSyntax()
 
 	switch err := err.(type) {
 	case *DescError:
-		return nil, err
+		return nil, locate(err, p.buf)
+	case *Error:
+		return nil, locate(err, p.buf)
 	case error:
-		return nil, &Error{err, p.pos + 1}
+		return nil, locate(&Error{err: err, pos: p.pos}, p.buf)
 	default:
-		return &AST{rules: rules, semantic: true}, nil
+		return &AST{rules: rules, semantic: true, source: p.buf}, nil
 	}
 }
 
@@ -45,28 +48,23 @@ func (p *SemanticParser) eof() error {
 
 func (p *SemanticParser) parseSyntax() ([]*Statement, error) {
 	var result []*Statement
-	var stmt, err = p.parseRule()
 
-	// Try to parse the first statement if there is any.
-	switch {
-	case err == io.EOF && stmt == nil:
-		return nil, nil
-	case err == io.EOF && stmt != nil:
-		result = append(result, stmt)
-		return result, nil
-	case err != nil:
-		return nil, err
-	}
-
-	// Recursively parse the rest.
-	if stmts, err := p.parseSyntax(); err != nil {
-		result = append(result, stmt)
-	} else {
+	// Parse statements one by one until the end of input. Statements which
+	// follow a malformed one are dropped while the preceding ones are kept.
+	for {
+		var stmt, err = p.parseRule()
+		switch {
+		case err == io.EOF && stmt == nil:
+			return result, nil
+		case err == io.EOF && stmt != nil:
+			return append(result, stmt), nil
+		case err != nil && len(result) == 0:
+			return nil, err
+		case err != nil:
+			return result, nil
+		}
 		result = append(result, stmt)
-		result = append(result, stmts...)
 	}
-
-	return result, nil
 }
 
 func (p *SemanticParser) parseRule() (*Statement, error) {
@@ -120,7 +118,6 @@ func (p *SemanticParser) parseExpression() (Node, error) {
 	// Parse single term list at first and back up position.
 	if root.LeftChild, err = p.parseList(); err != nil {
 		return nil, err
-		return nil, NewDescError(err, p.pos, "terminal or non-terminal")
 	} else {
 		offset = p.pos
 	}
@@ -144,6 +141,9 @@ func (p *SemanticParser) parseExpression() (Node, error) {
 	}
 
 	if root.RightChild, err = p.parseExpression(); err != nil {
+		if unclosed(err) {
+			return nil, err
+		}
 		p.pos = offset
 		return root.LeftChild, nil
 	}
@@ -159,7 +159,9 @@ func (p *SemanticParser) parseList() (Node, error) {
 	var node Node
 
 	// Use CompoundExpression to create the first element of lexemme list.
-	if root.LeftChild, err = p.parseAtom(); err != nil {
+	if root.LeftChild, err = p.parseTerm(); unclosed(err) {
+		return nil, err
+	} else if err != nil {
 		return nil, NewDescError(err, p.pos, "terminal or non-terminal")
 	}
 
@@ -171,7 +173,9 @@ func (p *SemanticParser) parseList() (Node, error) {
 			break
 		}
 
-		if node, err = p.parseAtom(); err != nil {
+		if node, err = p.parseTerm(); unclosed(err) {
+			return nil, err
+		} else if err != nil {
 			break
 		}
 
@@ -204,3 +208,37 @@ func (p *SemanticParser) parseList() (Node, error) {
 	curr.RightChild = last.LeftChild
 	return root, nil
 }
+
+// parseTerm parses either an atom or a parenthesized group like `("x" |
+// "y")`. Group binds tighter than alternation, so alternatives inside of it
+// are alternatives of the group only. Group without closing bracket is
+// reported at its opening one.
+func (p *SemanticParser) parseTerm() (Node, error) {
+	if !p.lookingAt("(") {
+		return p.parseAtom()
+	}
+
+	var group = &GroupExpression{Kind: GroupParen}
+	group.Begin = p.pos
+	p.pos++
+	p.parseOptWhitespace()
+
+	var err error
+	if group.LeftChild, err = p.parseExpression(); err != nil {
+		return nil, err
+	}
+
+	p.parseOptWhitespace()
+	if _, err := p.parseChar(')'); err != nil {
+		return nil, &Error{err: ErrUnclosedGroup, pos: group.Begin}
+	}
+
+	group.End = p.pos
+	return group, nil
+}
+
+// unclosed reports whether error is caused by a group without closing
+// bracket. Such an error is never recovered by backtracking.
+func unclosed(err error) bool {
+	return errors.Is(err, ErrUnclosedGroup)
+}
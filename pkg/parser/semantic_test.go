@@ -3,6 +3,7 @@ package parser
 import (
 	"bytes"
 	"io/ioutil"
+	"strings"
 	"testing"
 )
 
@@ -100,4 +101,231 @@ func TestSemanticParser(t *testing.T) {
 			t.Errorf("too a few production rules: %d", length)
 		}
 	})
+
+	t.Run("ErrorPosition", func(t *testing.T) {
+		var content = []byte(`<rule> ::= <a> "b`)
+		var parser = NewSemanticParser(bytes.NewBuffer(content))
+		var _, err = parser.Parse()
+
+		if err == nil {
+			t.Fatalf("error is expected")
+		} else if err, ok := err.(*DescError); !ok {
+			t.Fatalf("wrong type of error: %T", err)
+		} else if pos := err.Pos(); pos != 15 {
+			t.Errorf("wrong error position: %d", pos)
+		}
+	})
+}
+
+func TestSemanticParserLargeInput(t *testing.T) {
+	const size = 100000
+	var source = generateGrammar(size)
+	var ast, err = NewSemanticParser(bytes.NewReader(source)).Parse()
+	if err != nil {
+		t.Fatalf("failed to parse grammar: %s", err)
+	}
+
+	if length := ast.NoRules(); length != size {
+		t.Fatalf("wrong number of rules: %d", length)
+	}
+
+	var last = ast.rules[size-1].Rule
+	var lhs = last.LeftChild.(*NonTerminal)
+	if name := string(lhs.Name); name != "rule-99999" {
+		t.Errorf("wrong name of the last rule: %s", name)
+	}
+}
+
+func TestSemanticParserMalformedTail(t *testing.T) {
+	var source = append(generateGrammar(3), "<broken> ::=\n"...)
+	source = append(source, generateGrammar(2)...)
+
+	var ast, err = NewSemanticParser(bytes.NewReader(source)).Parse()
+	if err != nil {
+		t.Fatalf("failed to parse grammar: %s", err)
+	}
+
+	// Statements after malformed one are dropped.
+	if length := ast.NoRules(); length != 3 {
+		t.Errorf("wrong number of rules: %d", length)
+	}
+}
+
+func TestSemanticParserUnicode(t *testing.T) {
+	var source = []byte(`<правило> ::= "é" <слово-2> | 'naïve'`)
+	var ast, err = NewSemanticParser(bytes.NewReader(source)).Parse()
+	if err != nil {
+		t.Fatalf("failed to parse grammar: %s", err)
+	}
+
+	var tokens []*Token
+	ast.Traverse(func(node Node) error {
+		switch node := node.(type) {
+		case *NonTerminal:
+			tokens = append(tokens, &node.Token)
+		case *Terminal:
+			tokens = append(tokens, &node.Token)
+		}
+		return nil
+	})
+
+	var expected = []struct {
+		name       string
+		begin, end int
+		columns    [2]int
+	}{
+		{"правило", 0, 16, [2]int{0, 9}},
+		{"é", 21, 25, [2]int{14, 17}},
+		{"слово-2", 26, 40, [2]int{18, 27}},
+		{"naïve", 43, 51, [2]int{30, 37}},
+	}
+
+	if len(tokens) != len(expected) {
+		t.Fatalf("wrong number of tokens: %d", len(tokens))
+	}
+
+	for idx, token := range tokens {
+		var exp = expected[idx]
+		var begin, end = token.Columns(source)
+		switch {
+		case string(token.Name) != exp.name:
+			t.Errorf("wrong name of token #%d: %s", idx, token.Name)
+		case token.Begin != exp.begin || token.End != exp.end:
+			t.Errorf("wrong bytes of %s: [%d, %d)", exp.name, token.Begin,
+				token.End)
+		case begin != exp.columns[0] || end != exp.columns[1]:
+			t.Errorf("wrong columns of %s: [%d, %d)", exp.name, begin, end)
+		}
+	}
+}
+
+func TestSemanticParserWhitespace(t *testing.T) {
+	var source = []byte("\t<a>\t::=\t<b>\u00a0|\u2003\"c\" \t")
+	var ast, err = NewSemanticParser(bytes.NewReader(source)).Parse()
+	if err != nil {
+		t.Fatalf("failed to parse grammar: %s", err)
+	} else if length := ast.NoRules(); length != 1 {
+		t.Fatalf("wrong number of rules: %d", length)
+	}
+
+	var alts = Alternatives(ast.rules[0].Rule.Right())
+	if len(alts) != 2 {
+		t.Fatalf("wrong number of alternatives: %d", len(alts))
+	}
+
+	var spans = []Range{{9, 12}, {18, 21}}
+	for idx, alt := range alts {
+		if span := Span(alt); span != spans[idx] {
+			t.Errorf("wrong span of alternative #%d: %v", idx, span)
+		}
+	}
+
+	var tree = ParseLossless(DialectBNF, source)
+	var kinds = []TokenKind{TokenSpace, TokenLexeme, TokenSpace}
+	for idx, token := range tree.Tokens()[:len(kinds)] {
+		if token.Kind != kinds[idx] {
+			t.Errorf("wrong kind of token #%d: %v", idx, token.Kind)
+		}
+	}
+}
+
+func TestSemanticParserLineEndings(t *testing.T) {
+	var source = []byte("\ufeff<a> ::= <b>\r\n<b> ::= \"c\"\r\n\r\n" +
+		"<c> ::= <a>\r")
+	var ast, err = NewSemanticParser(bytes.NewReader(source)).Parse()
+	if err != nil {
+		t.Fatalf("failed to parse grammar: %s", err)
+	} else if length := ast.NoRules(); length != 3 {
+		t.Fatalf("wrong number of rules: %d", length)
+	}
+
+	var begin, end = ast.rules[0].Rule.Left().(*NonTerminal).Columns(source)
+	if begin != 0 || end != 3 {
+		t.Errorf("byte order mark is counted in columns: [%d, %d)", begin,
+			end)
+	}
+
+	ast, err = ParseDialect(DialectBNF, []byte("\ufeff<a> ::= <b> )"))
+	if err != nil {
+		t.Fatalf("failed to parse grammar: %s", err)
+	}
+	var diags = Diagnostics(ast)
+	if len(diags) != 1 || diags[0].Range.Begin != 15 ||
+		diags[0].Start.Col != 12 {
+		t.Errorf("wrong diagnostics: %+v", diags)
+	}
+}
+
+func TestSemanticParserEpsilon(t *testing.T) {
+	var source = []byte(`<a> ::= <b> | "" | ε | <empty>`)
+	var ast, err = NewSemanticParser(bytes.NewReader(source)).Parse()
+	if err != nil {
+		t.Fatalf("failed to parse grammar: %s", err)
+	}
+
+	var alts = Alternatives(ast.rules[0].Rule.Right())
+	if len(alts) != 4 {
+		t.Fatalf("wrong number of alternatives: %d", len(alts))
+	}
+
+	var spans = []Range{{14, 16}, {19, 21}, {24, 31}}
+	for idx, alt := range alts[1:] {
+		if _, ok := alt.(*Epsilon); !ok {
+			t.Errorf("alternative #%d is not epsilon: %T", idx+1, alt)
+		} else if span := Span(alt); span != spans[idx] {
+			t.Errorf("wrong span of alternative #%d: %v", idx+1, span)
+		}
+	}
+}
+
+func TestSemanticParserGroup(t *testing.T) {
+	var source = []byte(`<a> ::= ( "x" | "y" ) <b> | "z"`)
+	var ast, err = NewSemanticParser(bytes.NewReader(source)).Parse()
+	if err != nil {
+		t.Fatalf("failed to parse grammar: %s", err)
+	}
+
+	// Alternation inside of group does not split alternatives of rule.
+	var alts = Alternatives(ast.rules[0].Rule.Right())
+	if len(alts) != 2 {
+		t.Fatalf("wrong number of alternatives: %d", len(alts))
+	}
+
+	var seq, ok = alts[0].(*CompoundExpression)
+	if !ok {
+		t.Fatalf("first alternative is not sequence: %T", alts[0])
+	}
+	group, ok := seq.Left().(*GroupExpression)
+	if !ok || group.Kind != GroupParen {
+		t.Fatalf("first term is not group: %T", seq.Left())
+	} else if span := Span(group); span != (Range{8, 21}) {
+		t.Errorf("wrong span of group: %v", span)
+	}
+	if alts := Alternatives(group.Left()); len(alts) != 2 {
+		t.Errorf("wrong number of alternatives of group: %d", len(alts))
+	}
+
+	for _, line := range []string{`<a> ::= ("x"`, `<a> ::= ()`} {
+		var _, err = NewSemanticParser(bytes.NewReader([]byte(line))).Parse()
+		if err == nil {
+			t.Errorf("malformed group is parsed: %s", line)
+		}
+	}
+
+	// Unclosed group is reported at its opening bracket.
+	var lines = []string{
+		`<a> ::= "x" ("y" | "z"`,
+		`<a> ::= ("y" | "z" <b>`,
+		`<a> ::= "w" | "x" ("y" | ("z")`,
+	}
+	for _, line := range lines {
+		var _, err = NewSemanticParser(bytes.NewReader([]byte(line))).Parse()
+		var diag = NewDiagnostic(err)
+		var begin = strings.IndexByte(line, '(')
+		if diag.Code != CodeUnclosedGroup {
+			t.Errorf("wrong code of %s: %s", line, diag.Code)
+		} else if diag.Range.Begin != begin {
+			t.Errorf("wrong range of %s: %v", line, diag.Range)
+		}
+	}
 }
@@ -0,0 +1,204 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+)
+
+// Stream parses production rules one at a time, so that generated grammars
+// of hundreds of megabytes are processed with bounded memory. Rules of
+// single-line dialects are read line by line and offsets of their nodes are
+// relative to their lines. Multiline dialects are parsed as a whole on the
+// first call since their rules span several lines, so memory is not bounded
+// for them.
+type Stream struct {
+	reader  *bufio.Reader
+	dialect Dialect
+
+	text   []byte
+	line   int
+	offset int
+	next   int
+
+	ast     *AST
+	broken  *AST
+	pending []*Statement
+	err     error
+}
+
+// NewStream creates stream of production rules of classic BNF.
+func NewStream(reader io.Reader) *Stream {
+	return NewDialectStream(DialectBNF, reader)
+}
+
+// NewDialectStream creates stream of production rules of some dialect.
+func NewDialectStream(dialect Dialect, reader io.Reader) *Stream {
+	return &Stream{reader: bufio.NewReader(reader), dialect: dialect, line: -1}
+}
+
+// Next returns the next production rule. It returns io.EOF once input is
+// exhausted. Lines which could not be parsed are reported with their errors
+// while the next call continues from the following line. Blank and comment
+// lines are skipped. Errors of reader are returned as is and they are kept by
+// Err.
+func (s *Stream) Next() (*Statement, error) {
+	if s.dialect.Multiline() {
+		return s.nextDocument()
+	}
+
+	for len(s.pending) == 0 {
+		if err := s.readLine(); err != nil {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(s.text)) == 0 {
+			continue
+		}
+
+		var ast, err = ParseDialect(s.dialect, s.text)
+		if s.broken = nil; err != nil {
+			return nil, s.relocate(err)
+		} else if err := ast.Error(); err != nil && !comments(ast) {
+			s.broken = ast
+			return nil, s.relocate(err)
+		}
+		s.pending = ast.Statements()
+	}
+
+	var stmt = s.pending[0]
+	s.pending = s.pending[1:]
+	return stmt, nil
+}
+
+// readLine reads the next line without line break.
+func (s *Stream) readLine() error {
+	if s.err != nil {
+		return s.err
+	}
+
+	var text, err = s.reader.ReadBytes('\n')
+	if err != nil && err != io.EOF {
+		s.err = err
+		return err
+	} else if len(text) == 0 {
+		return io.EOF
+	}
+
+	s.line++
+	s.offset, s.next = s.next, s.next+len(text)
+	text = bytes.TrimSuffix(text, []byte{'\n'})
+	s.text = bytes.TrimSuffix(text, []byte{'\r'})
+	return nil
+}
+
+// relocate moves position of an error from the beginning of input to the
+// current line.
+func (s *Stream) relocate(err error) error {
+	var base *Error
+	switch err := err.(type) {
+	case *Error:
+		base = err
+	case *DescError:
+		base = &err.Base
+	default:
+		return err
+	}
+	base.at.Line = s.line
+	base.at.Offset += s.offset
+	return err
+}
+
+// comments reports whether syntactic parse tree of a line consists of
+// comments only.
+func comments(ast *AST) bool {
+	for _, lemmes := range ast.lemmes {
+		for _, node := range lemmes {
+			if _, ok := node.(*Comment); !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// nextDocument reads the whole input on the first call and returns its
+// statements one by one.
+func (s *Stream) nextDocument() (*Statement, error) {
+	if s.err != nil {
+		return nil, s.err
+	} else if s.ast == nil {
+		var source, err = ioutil.ReadAll(s.reader)
+		if err != nil {
+			s.err = err
+			return nil, err
+		}
+		if s.ast, err = ParseDialect(s.dialect, source); err != nil {
+			s.ast = &AST{source: source}
+			return nil, err
+		}
+		s.pending = s.ast.Statements()
+		if err := s.ast.Error(); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(s.pending) == 0 {
+		return nil, io.EOF
+	}
+
+	var stmt = s.pending[0]
+	s.pending = s.pending[1:]
+	if stmt.Rule != nil {
+		s.line = s.ast.Position(Span(stmt.Rule.Left()).Begin).Line
+	}
+	return stmt, nil
+}
+
+// Diagnostic converts error of the last call of Next to diagnostic. Range of
+// diagnostic is relative to the line of error like offsets of nodes are while
+// address of a broken rule is resolved in the same way as Diagnostics does.
+func (s *Stream) Diagnostic(err error) Diagnostic {
+	var diag = NewDiagnostic(err)
+	if s.broken != nil && !s.broken.semantic && len(s.broken.lemmes) == 1 {
+		diag.Address = addressOf(s.broken.lemmes[0], diag.Range.Begin)
+	}
+	return diag
+}
+
+// Err returns the first error of reader other than io.EOF.
+func (s *Stream) Err() error {
+	return s.err
+}
+
+// Line returns zero-based line of the last statement or error.
+func (s *Stream) Line() int {
+	return s.line
+}
+
+// Position converts offset of a node of the last statement to position in
+// input.
+func (s *Stream) Position(offset int) Position {
+	if s.ast != nil {
+		return s.ast.Position(offset)
+	}
+	return Position{
+		Line:   s.line,
+		Col:    Column(s.text, offset),
+		Offset: s.offset + offset,
+	}
+}
+
+// Dump writes a statement of stream in the same way as AST.Dump does.
+func (s *Stream) Dump(w io.Writer, stmt *Statement) error {
+	var out = bufio.NewWriter(w)
+	var node = newJSONNode(stmt, s.Position)
+	node.dump(out, 0)
+	return out.Flush()
+}
+
+// Marshal serializes a statement of stream with positions of all nodes.
+func (s *Stream) Marshal(stmt *Statement) ([]byte, error) {
+	return json.Marshal(newJSONNode(stmt, s.Position))
+}
@@ -0,0 +1,78 @@
+package parser
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestStream(t *testing.T) {
+	var source = "; comment\n<a> ::= <b> | \"c\"\r\n\n<b> ::= <c> )\n" +
+		"<c> ::= \"d\""
+	var stream = NewStream(strings.NewReader(source))
+
+	var stmt, err = stream.Next()
+	if err != nil {
+		t.Fatalf("failed to read the first rule: %s", err)
+	} else if line := stream.Line(); line != 1 {
+		t.Errorf("wrong line of the first rule: %d", line)
+	}
+	var name = stmt.Rule.Left().(*NonTerminal)
+	if string(name.Name) != "a" {
+		t.Errorf("wrong name of the first rule: %s", name.Name)
+	}
+	if pos := stream.Position(name.Begin); pos != (Position{1, 0, 10}) {
+		t.Errorf("wrong position of the first rule: %+v", pos)
+	}
+
+	_, err = stream.Next()
+	if err == nil {
+		t.Fatalf("broken line is not reported")
+	}
+	var diag = stream.Diagnostic(err)
+	if diag.Start.Line != 3 || diag.Start.Col != 12 {
+		t.Errorf("wrong position of error: %+v", diag)
+	} else if diag.Range.Begin != 12 {
+		t.Errorf("wrong range of error: %+v", diag.Range)
+	} else if addr := diag.Address; addr == nil || addr.Rule != "b" {
+		t.Errorf("wrong address of error: %+v", addr)
+	}
+
+	stmt, err = stream.Next()
+	if err != nil {
+		t.Fatalf("failed to read rule after broken line: %s", err)
+	} else if line := stream.Line(); line != 4 {
+		t.Errorf("wrong line of the last rule: %d", line)
+	}
+
+	var buf bytes.Buffer
+	stream.Dump(&buf, stmt)
+	if dump := buf.String(); !strings.Contains(dump, `"c" 5:1-5:4`) {
+		t.Errorf("wrong dump of the last rule:\n%s", dump)
+	}
+
+	if _, err = stream.Next(); err != io.EOF {
+		t.Errorf("end of input is not reported: %v", err)
+	}
+}
+
+func TestStreamMultiline(t *testing.T) {
+	var source = "a = b ;\nb = \"c\"\n  | \"d\" ;\n"
+	var stream = NewDialectStream(DialectEBNF, strings.NewReader(source))
+
+	var lines []int
+	for {
+		var _, err = stream.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("failed to read rule: %s", err)
+		}
+		lines = append(lines, stream.Line())
+	}
+
+	if len(lines) != 2 || lines[0] != 0 || lines[1] != 1 {
+		t.Errorf("wrong lines of rules: %v", lines)
+	}
+}
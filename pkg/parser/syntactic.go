@@ -1,8 +1,11 @@
 package parser
 
 import (
-	"bufio"
+	"bytes"
 	"io"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
 )
 
 // SyntacticParser performs lexical parsing of the input according definition
@@ -12,6 +15,7 @@ type SyntacticParser struct {
 
 	buf []byte
 	pos int
+	ops *Operators
 }
 
 func NewSyntacticParser(reader io.Reader) *SyntacticParser {
@@ -20,7 +24,7 @@ func NewSyntacticParser(reader io.Reader) *SyntacticParser {
 
 func (p *SyntacticParser) Parse() (*AST, error) {
 	if lemmes, err := p.parseSyntax(); err != nil {
-		return nil, &Error{err, p.pos + 1}
+		return nil, locate(&Error{err: err, pos: p.pos}, p.buf)
 	} else {
 		return &AST{lemmes: lemmes, semantic: false}, nil
 	}
@@ -34,22 +38,28 @@ func (p *SyntacticParser) eof() error {
 	}
 }
 
-func (p *SyntacticParser) parseSyntax() ([][]Node, error) {
-	var rules [][]Node
-	var scanner = bufio.NewScanner(p.Reader)
-
-	for scanner.Scan() {
-		// Reset parser state with the new line.
-		p.buf = []byte(scanner.Text())
-		p.pos = 0
-
-		// Parse every single line and ignore parsing errors.
-		if rule, err := p.parseRule(); err == nil {
-			rules = append(rules, rule)
-		}
+// failure returns io.EOF if the whole input is consumed or ErrUnexpectedChar
+// otherwise.
+func (p *SyntacticParser) failure() error {
+	if err := p.eof(); err != nil {
+		return err
 	}
+	return ErrUnexpectedChar
+}
 
-	return rules, scanner.Err()
+// lookingAt checks whether input at the current position starts with prefix.
+func (p *SyntacticParser) lookingAt(prefix string) bool {
+	return bytes.HasPrefix(p.buf[p.pos:], []byte(prefix))
+}
+
+func (p *SyntacticParser) parseSyntax() ([][]Node, error) {
+	// Parse every single line and ignore parsing errors.
+	return parseLines(p.Reader, func(line []byte) ([]Node, error) {
+		// Reset parser state with the new line.
+		p.buf = line
+		p.pos = skipBOM(p.buf)
+		return p.parseRule()
+	})
 }
 
 func (p *SyntacticParser) parseComment() (*Comment, error) {
@@ -64,7 +74,8 @@ func (p *SyntacticParser) parseComment() (*Comment, error) {
 	}
 
 	for token.End = p.pos + 1; token.End != len(p.buf); token.End++ {
-		if p.buf[token.End] == '\n' || p.buf[token.End] == byte(0) {
+		if p.buf[token.End] == '\n' || p.buf[token.End] == byte(0) ||
+			bytes.HasPrefix(p.buf[token.End:], []byte("\r\n")) {
 			break
 		}
 	}
@@ -110,18 +121,126 @@ func (p *SyntacticParser) parseRule() ([]Node, error) {
 	return tokens, nil
 }
 
+// parseQuoted parses a sequence of characters enclosed in quotes and returns
+// content of the sequence.
+func (p *SyntacticParser) parseQuoted(quote byte) ([]byte, error) {
+	var begin = p.pos + 1
+	var length = bytes.IndexByte(p.buf[begin:], quote)
+
+	if length < 0 {
+		p.pos = len(p.buf)
+		return nil, NewDescError(io.EOF, p.pos, "closing "+string(quote))
+	}
+
+	p.pos = begin + length + 1
+	return p.buf[begin : begin+length], nil
+}
+
+// parseCLiteral parses character or string literal with escape sequences
+// like in C. Literal could not span several lines.
+func (p *SyntacticParser) parseCLiteral(quote byte) ([]byte, error) {
+	var begin = p.pos + 1
+
+	for p.pos = begin; p.pos < len(p.buf); p.pos++ {
+		switch p.buf[p.pos] {
+		case '\\':
+			p.pos++
+		case '\n':
+			return nil, NewDescError(ErrUnexpectedChar, p.pos,
+				"closing "+string(quote))
+		case quote:
+			p.pos++
+			return p.buf[begin : p.pos-1], nil
+		}
+	}
+
+	p.pos = len(p.buf)
+	return nil, NewDescError(io.EOF, p.pos, "closing "+string(quote))
+}
+
+// skipBlock skips a block enclosed in brackets like semantic action in braces
+// `{ ... }`. Nested blocks are skipped as well while brackets inside of
+// literals and comments are not counted.
+func (p *SyntacticParser) skipBlock(open, closing byte) error {
+	var depth = 0
+
+	for p.pos < len(p.buf) {
+		switch char := p.buf[p.pos]; {
+		case char == open:
+			depth++
+			p.pos++
+		case char == closing:
+			depth--
+			p.pos++
+			if depth == 0 {
+				return nil
+			}
+		case char == '"' || char == '\'':
+			// Unpaired quotes are possible in code, e.g. in comments, so
+			// errors are ignored.
+			if _, err := p.parseCLiteral(char); err != nil && p.eof() == nil {
+				p.pos++
+			}
+		case p.lookingAt("/*") || p.lookingAt("//"):
+			p.parseCComment()
+		default:
+			p.pos++
+		}
+	}
+
+	return NewDescError(io.EOF, p.pos, "'"+string(closing)+"'")
+}
+
+// parseCComment parses either block or line comment like in C.
+func (p *SyntacticParser) parseCComment() (*Comment, error) {
+	var token = Token{Begin: p.pos}
+
+	if p.lookingAt("//") {
+		if idx := bytes.IndexByte(p.buf[p.pos:], '\n'); idx < 0 {
+			p.pos = len(p.buf)
+		} else {
+			p.pos += idx
+		}
+	} else if idx := bytes.Index(p.buf[p.pos+2:], []byte("*/")); idx < 0 {
+		p.pos = len(p.buf)
+		return nil, NewDescError(io.EOF, p.pos, "'*/'")
+	} else {
+		p.pos += idx + 4
+	}
+
+	token.End = p.pos
+	return &Comment{token}, nil
+}
+
+// skipCharacterSet skips character set like `[a-z\]]` which could contain
+// escaped closing bracket.
+func (p *SyntacticParser) skipCharacterSet() error {
+	for p.pos++; p.pos < len(p.buf); p.pos++ {
+		switch p.buf[p.pos] {
+		case '\\':
+			p.pos++
+		case '\n':
+			return NewDescError(ErrUnexpectedChar, p.pos, "']'")
+		case ']':
+			p.pos++
+			return nil
+		}
+	}
+	return NewDescError(io.EOF, p.pos, "']'")
+}
+
 func (p *SyntacticParser) parseRuleName() ([]byte, error) {
 	var ruleName []byte
 
 	if letter, err := p.parseLetter(); err != nil {
 		return nil, err
 	} else {
-		ruleName = append(ruleName, letter)
+		ruleName = append(ruleName, letter...)
 	}
 
 	for {
 		if char, err := p.parseRuleChar(); err == nil {
-			ruleName = append(ruleName, char)
+			ruleName = append(ruleName, char...)
 		} else {
 			break
 		}
@@ -130,100 +249,137 @@ func (p *SyntacticParser) parseRuleName() ([]byte, error) {
 	return ruleName, nil
 }
 
-func (p *SyntacticParser) parseRuleChar() (byte, error) {
+// parseRuleChar parses a character of rule name. Character is returned as a
+// sequence of bytes since letters could take several bytes in UTF-8.
+func (p *SyntacticParser) parseRuleChar() ([]byte, error) {
 	if letter, err := p.parseLetter(); err == nil {
 		return letter, nil
 	}
 
 	if digit, err := p.parseDigit(); err == nil {
-		return digit, nil
+		return []byte{digit}, nil
 	}
 
 	if hyphen, err := p.parseHyphen(); err == nil {
-		return hyphen, nil
+		return []byte{hyphen}, nil
 	}
 
-	return byte(0), ErrUnexpectedChar
+	return nil, ErrUnexpectedChar
 }
 
-func (p *SyntacticParser) parseCharacter() (byte, error) {
+// parseCharacter parses a character of literal. Besides letters, digits, and
+// ASCII symbols, any printable non-ASCII character is allowed.
+func (p *SyntacticParser) parseCharacter() ([]byte, error) {
 	if letter, err := p.parseLetter(); err == nil {
 		return letter, nil
 	}
 
 	if digit, err := p.parseDigit(); err == nil {
-		return digit, nil
+		return []byte{digit}, nil
 	}
 
 	if symbol, err := p.parseSymbol(); err == nil {
-		return symbol, nil
+		return []byte{symbol}, nil
 	}
 
-	return byte(0), ErrUnexpectedChar
+	if char, err := p.parseGraphic(); err == nil {
+		return char, nil
+	}
+
+	return nil, ErrUnexpectedChar
 }
 
-func (p *SyntacticParser) parseCharacterAndQuote() (byte, error) {
+func (p *SyntacticParser) parseCharacterAndQuote() ([]byte, error) {
 	if quote, err := p.parseQuote(); err == nil {
-		return quote, err
+		return []byte{quote}, err
 	} else {
 		return p.parseCharacter()
 	}
 }
 
-func (p *SyntacticParser) parseCharacterAndDoubleQuote() (byte, error) {
+func (p *SyntacticParser) parseCharacterAndDoubleQuote() ([]byte, error) {
 	if quote, err := p.parseDoubleQuote(); err == nil {
-		return quote, err
+		return []byte{quote}, err
 	} else {
 		return p.parseCharacter()
 	}
 }
 
 func (p *SyntacticParser) parseDefinitionSimbol() (*Token, error) {
-	const name = "::="
-	var token = Token{Name: []byte(name), Begin: p.pos, End: p.pos + 3}
-
-	// Out of buffer check.
-	if p.pos+len(name) >= len(p.buf) {
-		return nil, io.EOF
+	var token, err = p.parseOperator(p.operators().Definition)
+	if err != nil {
+		return nil, err
 	}
 
-	// Is there expected characters.
-	if string(p.buf[p.pos:p.pos+3]) != name {
-		return nil, ErrUnexpectedChar
-	} else {
-		p.pos += 3
-		return &token, nil
+	// Definition could not be the last lexeme of a line.
+	if token.End >= len(p.buf) {
+		p.pos = token.Begin
+		return nil, io.EOF
 	}
+	return token, nil
 }
 
 func (p *SyntacticParser) parseDisjunction() (*Token, error) {
-	if _, err := p.parseVerticalBar(); err != nil {
+	return p.parseOperator(p.operators().Alternation)
+}
+
+// parseOperator parses the first of spellings of an operator which input
+// continues with. Spellings are ordered from the longest to the shortest.
+func (p *SyntacticParser) parseOperator(spellings []string) (*Token, error) {
+	if err := p.eof(); err != nil {
 		return nil, err
-	} else {
-		return &Token{
-			Name:  []byte{'|'},
-			Begin: p.pos - 1,
-			End:   p.pos,
-		}, nil
 	}
+
+	for _, spelling := range spellings {
+		if spelling != "" && p.lookingAt(spelling) {
+			var begin = p.pos
+			p.pos += len(spelling)
+			return &Token{[]byte(spelling), begin, p.pos}, nil
+		}
+	}
+	return nil, ErrUnexpectedChar
+}
+
+// operators returns spellings of operators which parser recognizes.
+func (p *SyntacticParser) operators() *Operators {
+	if p.ops == nil {
+		return &DefaultOperators
+	}
+	return p.ops
 }
 
 func (p *SyntacticParser) parseAtom() (Node, error) {
 	var begin = p.pos
 
-	// Parse terminal literal.
+	// Parse terminal literal. Empty literal stands for empty alternative.
 	if literal, err := p.parseLiteral(); err == nil {
+		if len(literal) == 0 {
+			return &Epsilon{Token{literal, begin, p.pos}}, nil
+		}
 		return &Terminal{Token{literal, begin, p.pos}}, nil
 	}
 
-	// Parse non-terminal.
+	// Parse epsilon written as Greek letter.
+	if p.lookingAt(epsilon) {
+		p.pos += len(epsilon)
+		return &Epsilon{Token{p.buf[begin:p.pos], begin, p.pos}}, nil
+	}
+
+	// Parse non-terminal. Non-terminal <empty> is a conventional name of
+	// empty alternative.
 	if nonTerminal, err := p.parseNonTerminal(); err == nil {
+		if token := nonTerminal.(*NonTerminal); string(token.Name) == "empty" {
+			return &Epsilon{token.Token}, nil
+		}
 		return nonTerminal, nil
 	} else {
 		return nil, err
 	}
 }
 
+// epsilon is a Greek letter which denotes empty string.
+const epsilon = "ε"
+
 func (p *SyntacticParser) parseLiteral() ([]byte, error) {
 	if err := p.eof(); err != nil {
 		return nil, err
@@ -241,7 +397,7 @@ func (p *SyntacticParser) parseLiteral() ([]byte, error) {
 			if char, err := p.parseCharacterAndQuote(); err != nil {
 				break
 			} else {
-				literal = append(literal, char)
+				literal = append(literal, char...)
 			}
 		}
 
@@ -257,7 +413,7 @@ func (p *SyntacticParser) parseLiteral() ([]byte, error) {
 			if char, err := p.parseCharacterAndDoubleQuote(); err != nil {
 				break
 			} else {
-				literal = append(literal, char)
+				literal = append(literal, char...)
 			}
 		}
 
@@ -310,18 +466,33 @@ func (p *SyntacticParser) parseLineEnd() error {
 	return nil
 }
 
+// parseOptWhitespace skips spaces, tabs, and other Unicode whitespaces except
+// line breaks.
 func (p *SyntacticParser) parseOptWhitespace() error {
 	for p.pos < len(p.buf) {
-		if p.buf[p.pos] == ' ' {
-			p.pos++
-		} else {
+		var char, size = utf8.DecodeRune(p.buf[p.pos:])
+		if !isBlank(char) {
 			break
 		}
+		p.pos += size
 	}
 	return nil
 }
 
+// isBlank reports whether a character is a whitespace which does not break a
+// line.
+func isBlank(char rune) bool {
+	return char != '\n' && char != '\r' && unicode.IsSpace(char)
+}
+
+// parseEOL parses line break which is either LF or CRLF. Carriage return at
+// the end of input is skipped as well since it is left from CRLF when a
+// source is split into lines.
 func (p *SyntacticParser) parseEOL() (byte, error) {
+	if p.lookingAt("\r") &&
+		(p.pos+1 == len(p.buf) || p.buf[p.pos+1] == '\n') {
+		p.pos++
+	}
 	return p.parseChar('\n')
 }
 
@@ -345,22 +516,38 @@ func (p *SyntacticParser) parseDoubleQuote() (byte, error) {
 	return p.parseChar('"')
 }
 
-func (p *SyntacticParser) parseVerticalBar() (byte, error) {
-	return p.parseChar('|')
+// parseLetter parses a letter of any script. Letter is returned with all
+// bytes of its UTF-8 encoding.
+func (p *SyntacticParser) parseLetter() ([]byte, error) {
+	if err := p.eof(); err != nil {
+		return nil, err
+	}
+
+	var char, size = utf8.DecodeRune(p.buf[p.pos:])
+
+	if char != utf8.RuneError && unicode.IsLetter(char) {
+		p.pos += size
+		return p.buf[p.pos-size : p.pos], nil
+	} else {
+		return nil, ErrUnexpectedChar
+	}
 }
 
-func (p *SyntacticParser) parseLetter() (byte, error) {
+// parseGraphic parses a printable non-ASCII character like a letter with
+// diacritic or a punctuation mark.
+func (p *SyntacticParser) parseGraphic() ([]byte, error) {
 	if err := p.eof(); err != nil {
-		return byte(0), err
+		return nil, err
 	}
 
-	var char = p.buf[p.pos]
+	var char, size = utf8.DecodeRune(p.buf[p.pos:])
 
-	if (char >= 0x41 && char <= 0x5a) || (char >= 0x61 && char <= 0x7a) {
-		p.pos++
-		return char, nil
+	if char >= utf8.RuneSelf && char != utf8.RuneError &&
+		unicode.IsGraphic(char) {
+		p.pos += size
+		return p.buf[p.pos-size : p.pos], nil
 	} else {
-		return byte(0), ErrUnexpectedChar
+		return nil, ErrUnexpectedChar
 	}
 }
 
@@ -377,6 +564,23 @@ func (p *SyntacticParser) parseDigit() (byte, error) {
 	}
 }
 
+// parseInteger parses a decimal number like a bound of repetition.
+func (p *SyntacticParser) parseInteger() (int, error) {
+	var begin = p.pos
+	for p.pos < len(p.buf) && isDigit(p.buf[p.pos]) {
+		p.pos++
+	}
+	if p.pos == begin {
+		return 0, NewDescError(p.failure(), p.pos, "integer")
+	}
+
+	var value, err = strconv.Atoi(string(p.buf[begin:p.pos]))
+	if err != nil {
+		return 0, NewDescError(ErrUnexpectedChar, begin, "integer")
+	}
+	return value, nil
+}
+
 func (p *SyntacticParser) parseSymbol() (byte, error) {
 	if err := p.eof(); err != nil {
 		return byte(0), err
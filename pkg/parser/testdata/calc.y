@@ -0,0 +1,41 @@
+/* Infix notation calculator from Bison manual. */
+%{
+  #include <math.h>
+  int yylex (void);
+  void yyerror (char const *);
+%}
+
+%define api.value.type {double}
+%token NUM
+%left '-' '+'
+%left '*' '/'
+%precedence NEG   /* negation--unary minus */
+%right '^'        /* exponentiation */
+
+%% /* The grammar follows. */
+input:
+  %empty
+| input line
+;
+
+line:
+  '\n'
+| exp '\n'  { printf ("\t%.10g\n", $1); }
+;
+
+exp:
+  NUM
+| exp '+' exp        { $$ = $1 + $3;      }
+| exp '-' exp        { $$ = $1 - $3;      }
+| exp '*' exp        { $$ = $1 * $3;      }
+| exp '/' exp        { $$ = $1 / $3;      }
+| '-' exp  %prec NEG { $$ = -$2;          }
+| exp '^' exp        { $$ = pow ($1, $3); }
+| '(' exp ')'        { $$ = $2;           }
+;
+
+// Bison allows to omit semicolon at the end of a rule.
+opt_exp: %empty | exp
+%%
+
+int main (void) { return yyparse (); }
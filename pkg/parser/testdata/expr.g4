@@ -0,0 +1,38 @@
+/** Grammar of arithmetic expressions. */
+grammar Expr;
+
+options { language = Go; }
+
+@header {
+import "strconv"
+}
+
+prog
+    : stat+ EOF
+    ;
+
+stat
+    : expr NEWLINE              # printExpr
+    | ID '=' expr NEWLINE       # assign
+    | NEWLINE                   # blank
+    ;
+
+expr
+    : <assoc=right> expr '^' expr
+    | left=expr op=('*'|'/') right=expr
+    | expr ('+'|'-') expr
+    | INT
+    | ID
+    | '(' expr ')'
+    | {isValid()}? ~NEWLINE
+    ;
+
+// Lexer rules.
+ID      : LETTER (LETTER | DIGIT)* ;
+INT     : DIGIT+ ;
+NEWLINE : '\r'? '\n' ;
+WS      : [ \t]+ -> skip ;
+COMMENT : '/*' .*? '*/' -> channel(HIDDEN) ;
+
+fragment LETTER : 'a'..'z' | 'A'..'Z' | '_' ;
+fragment DIGIT  : [0-9] ;
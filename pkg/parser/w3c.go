@@ -0,0 +1,541 @@
+package parser
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"unicode/utf8"
+)
+
+// W3CParser performs semantic parsing of grammars written in EBNF notation
+// which is used in W3C specifications like XML or SPARQL. Rules are not
+// terminated explicitly, so a rule lasts until the next rule definition.
+//
+// Precedence of operators from the highest to the lowest is postfix
+// repetition (`?`, `*`, `+`, or bounds like `{2,4}`), exception (`-`),
+// concatenation, and alternation (`|`). Optional rule numbers like `[1]` and
+// comments `/* ... */` are stored as separate statements with comments only.
+type W3CParser struct {
+	documentParser
+}
+
+func NewW3CParser(reader io.Reader) *W3CParser {
+	var p = &W3CParser{documentParser: newDocumentParser(reader)}
+	p.comment, p.tokenize = p.parseOptComment, p.parseLineLexemes
+	return p
+}
+
+// ParseW3C parses grammar written in W3C EBNF notation. Likewise Parse, it
+// falls back to lexical parsing on error.
+func ParseW3C(source []byte) (*AST, error) {
+	var ast, errSem = NewW3CParser(bytes.NewReader(source)).Parse()
+	if errSem == nil {
+		return ast, nil
+	}
+
+	var lemmes, errSyn = NewW3CParser(bytes.NewReader(source)).parseLexemes()
+	if errSyn != nil {
+		return nil, errSyn
+	}
+
+	return &AST{err: errSem, lemmes: lemmes, source: source}, nil
+}
+
+func (p *W3CParser) Parse() (*AST, error) {
+	if bytes, err := ioutil.ReadAll(p.Reader); err != nil {
+		return nil, err
+	} else {
+		p.buf = bytes
+		p.pos = skipBOM(p.buf)
+	}
+
+	var rules, err = p.parseSyntax()
+
+	switch err := err.(type) {
+	case *DescError:
+		return nil, locate(err, p.buf)
+	case error:
+		return nil, locate(&Error{err: err, pos: p.pos}, p.buf)
+	default:
+		return &AST{rules: rules, semantic: true, source: p.buf}, nil
+	}
+}
+
+func (p *W3CParser) parseSyntax() ([]*Statement, error) {
+	var stmts []*Statement
+
+	for {
+		if err := p.parseGap(); err != nil {
+			return nil, err
+		}
+
+		if p.lookingAtRuleNumber() {
+			var begin = p.pos
+			p.pos += bytes.IndexByte(p.buf[p.pos:], ']') + 1
+			p.comments = append(p.comments, &Comment{Token{
+				Begin: begin,
+				End:   p.pos,
+			}})
+			continue
+		}
+
+		stmts = p.flushComments(stmts)
+
+		if err := p.eof(); err != nil {
+			return stmts, nil
+		}
+
+		if stmt, err := p.parseRule(); err != nil {
+			return nil, err
+		} else {
+			stmts = append(stmts, stmt)
+			stmts = p.flushComments(stmts)
+		}
+	}
+}
+
+func (p *W3CParser) parseRule() (*Statement, error) {
+	var err error
+	var expr = new(AssignmentExpression)
+
+	if expr.LeftChild, err = p.parseName(); err != nil {
+		return nil, err
+	}
+
+	if err = p.parseGap(); err != nil {
+		return nil, err
+	}
+
+	if token, err := p.parseDefinitionSimbol(); err != nil {
+		return nil, NewDescError(p.failure(), p.pos, "'::='")
+	} else {
+		expr.Token = *token
+	}
+
+	if err = p.parseGap(); err != nil {
+		return nil, err
+	}
+
+	if expr.RightChild, err = p.parseChoice(); err != nil {
+		return nil, err
+	}
+
+	// Rule ends either with input or with the next rule.
+	if p.eof() == nil && !p.lookingAtRule() && !p.lookingAtRuleNumber() {
+		return nil, NewDescError(ErrUnexpectedChar, p.pos, "'|' or term")
+	}
+
+	return &Statement{Rule: expr}, nil
+}
+
+// parseChoice parses alternatives separated with `|` into right-recursive
+// chain of AlternativeExpression.
+func (p *W3CParser) parseChoice() (Node, error) {
+	var seqs []Node
+	var bars []Token
+
+	for {
+		if seq, err := p.parseSequence(); err != nil {
+			return nil, err
+		} else {
+			seqs = append(seqs, seq)
+		}
+
+		if !p.lookingAt("|") {
+			break
+		}
+
+		bars = append(bars, Token{
+			Name:  []byte{'|'},
+			Begin: p.pos,
+			End:   p.pos + 1,
+		})
+		p.pos++
+
+		if err := p.parseGap(); err != nil {
+			return nil, err
+		}
+	}
+
+	var node = seqs[len(seqs)-1]
+	for idx := len(bars) - 1; idx >= 0; idx-- {
+		node = &AlternativeExpression{Expression{
+			Token:      bars[idx],
+			LeftChild:  seqs[idx],
+			RightChild: node,
+		}}
+	}
+
+	return node, nil
+}
+
+// parseSequence parses juxtaposed terms into right-recursive chain of
+// CompoundExpression. Sequence stops before the next rule definition.
+func (p *W3CParser) parseSequence() (Node, error) {
+	var terms []Node
+
+	for {
+		if term, err := p.parseException(); err != nil {
+			return nil, err
+		} else {
+			terms = append(terms, term)
+		}
+
+		if err := p.parseGap(); err != nil {
+			return nil, err
+		}
+
+		if !p.lookingAtTerm() || p.lookingAtRule() {
+			break
+		}
+	}
+
+	var node = terms[len(terms)-1]
+	for idx := len(terms) - 2; idx >= 0; idx-- {
+		var span = Span(terms[idx])
+		node = &CompoundExpression{Expression{
+			Token:      Token{Begin: span.Begin, End: span.End},
+			LeftChild:  terms[idx],
+			RightChild: node,
+		}}
+	}
+
+	return node, nil
+}
+
+func (p *W3CParser) parseException() (Node, error) {
+	var left, err = p.parsePostfix()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.parseGap(); err != nil {
+		return nil, err
+	}
+
+	if !p.lookingAt("-") {
+		return left, nil
+	}
+
+	var expr = &ExceptionExpression{Expression{
+		Token:     Token{Name: []byte{'-'}, Begin: p.pos, End: p.pos + 1},
+		LeftChild: left,
+	}}
+	p.pos++
+
+	if err := p.parseGap(); err != nil {
+		return nil, err
+	}
+
+	if expr.RightChild, err = p.parsePostfix(); err != nil {
+		return nil, err
+	}
+
+	return expr, nil
+}
+
+func (p *W3CParser) parsePostfix() (Node, error) {
+	var node, err = p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.eof() == nil {
+		var min, max int
+		switch p.buf[p.pos] {
+		case '?':
+			min, max = 0, 1
+		case '*':
+			min, max = 0, -1
+		case '+':
+			min, max = 1, -1
+		case '{':
+			if node, err = p.parseBounds(node); err != nil {
+				return nil, err
+			}
+			continue
+		default:
+			return node, nil
+		}
+
+		node = &RepetitionExpression{
+			Expression: Expression{
+				Token: Token{
+					Name:  []byte{p.buf[p.pos]},
+					Begin: p.pos,
+					End:   p.pos + 1,
+				},
+				LeftChild: node,
+			},
+			Min: min,
+			Max: max,
+		}
+		p.pos++
+	}
+
+	return node, nil
+}
+
+// parseBounds parses bounded repetition of an operand as an extension: `{n}`
+// repeats it exactly n times, `{m,n}` repeats it from m to n times, and `{m,}`
+// repeats it at least m times.
+func (p *W3CParser) parseBounds(operand Node) (Node, error) {
+	var begin = p.pos
+	p.pos++
+
+	var min, err = p.parseInteger()
+	if err != nil {
+		return nil, err
+	}
+
+	var max = min
+	if p.lookingAt(",") {
+		p.pos++
+		if max = -1; p.eof() == nil && isDigit(p.buf[p.pos]) {
+			if max, err = p.parseInteger(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if !p.lookingAt("}") {
+		return nil, NewDescError(p.failure(), p.pos, "'}'")
+	}
+	p.pos++
+
+	if max >= 0 && max < min {
+		p.pos = begin
+		return nil, ErrInvalidBounds
+	}
+	return &RepetitionExpression{
+		Expression: Expression{
+			Token: Token{
+				Name:  p.buf[begin:p.pos],
+				Begin: begin,
+				End:   p.pos,
+			},
+			LeftChild: operand,
+		},
+		Min: min,
+		Max: max,
+	}, nil
+}
+
+func (p *W3CParser) parsePrimary() (Node, error) {
+	if err := p.eof(); err != nil {
+		return nil, NewDescError(err, p.pos, "term")
+	}
+
+	var begin = p.pos
+
+	switch char := p.buf[p.pos]; {
+	case char == '(':
+		return p.parseGroup()
+	case char == '[':
+		if content, err := p.parseQuoted(']'); err != nil {
+			return nil, err
+		} else {
+			return &CharacterClass{Token{content, begin, p.pos}}, nil
+		}
+	case char == '#':
+		return p.parseCodePoint()
+	case char == '"' || char == '\'':
+		if name, err := p.parseQuoted(char); err != nil {
+			return nil, err
+		} else {
+			return &Terminal{Token{name, begin, p.pos}}, nil
+		}
+	case isNameStartChar(char):
+		return p.parseName()
+	default:
+		return nil, NewDescError(ErrUnexpectedChar, p.pos, "term")
+	}
+}
+
+func (p *W3CParser) parseGroup() (Node, error) {
+	var err error
+	var group = &GroupExpression{Kind: GroupParen}
+	group.Begin = p.pos
+	p.pos++
+
+	if err = p.parseGap(); err != nil {
+		return nil, err
+	}
+
+	if group.LeftChild, err = p.parseChoice(); err != nil {
+		return nil, err
+	}
+
+	if !p.lookingAt(")") {
+		return nil, NewDescError(p.failure(), p.pos, "')'")
+	}
+
+	p.pos++
+	group.End = p.pos
+	return group, nil
+}
+
+// parseCodePoint parses character written as its code point like `#x20` into
+// terminal which name is the character encoded in UTF-8.
+func (p *W3CParser) parseCodePoint() (Node, error) {
+	var begin = p.pos
+	if !p.lookingAt("#x") {
+		return nil, NewDescError(ErrUnexpectedChar, p.pos, "code point")
+	}
+
+	p.pos += 2
+	for p.eof() == nil && isHexDigit(p.buf[p.pos]) {
+		p.pos++
+	}
+
+	var code, err = strconv.ParseUint(string(p.buf[begin+2:p.pos]), 16, 32)
+	if err != nil || !utf8.ValidRune(rune(code)) {
+		return nil, NewDescError(ErrUnexpectedChar, begin, "code point")
+	}
+
+	var name = make([]byte, utf8.RuneLen(rune(code)))
+	utf8.EncodeRune(name, rune(code))
+	return &Terminal{Token{name, begin, p.pos}}, nil
+}
+
+func (p *W3CParser) parseName() (Node, error) {
+	if p.eof() != nil || !isNameStartChar(p.buf[p.pos]) {
+		return nil, NewDescError(p.failure(), p.pos, "name")
+	}
+
+	var begin = p.pos
+	for p.eof() == nil && isNameChar(p.buf[p.pos]) {
+		p.pos++
+	}
+
+	var name = p.buf[begin:p.pos]
+	return &NonTerminal{Token{name, begin, p.pos}}, nil
+}
+
+// parseOptComment parses comment `/* ... */` if there is one at the current
+// position.
+func (p *W3CParser) parseOptComment() (*Comment, error) {
+	if !p.lookingAt("/*") {
+		return nil, nil
+	}
+
+	var token = Token{Begin: p.pos}
+	var length = bytes.Index(p.buf[p.pos+2:], []byte("*/"))
+	if length < 0 {
+		p.pos = len(p.buf)
+		return nil, NewDescError(io.EOF, p.pos, "'*/'")
+	}
+	p.pos += length + 4
+	token.End = p.pos
+	return &Comment{token}, nil
+}
+
+// lookingAtRule checks whether a rule definition starts at the current
+// position, i.e. there is a name followed by `::=`.
+func (p *W3CParser) lookingAtRule() bool {
+	var offset = p.pos
+	defer func() {
+		p.pos = offset
+	}()
+
+	if _, err := p.parseName(); err != nil {
+		return false
+	}
+
+	for p.eof() == nil && isWhitespace(p.buf[p.pos]) {
+		p.pos++
+	}
+
+	return p.lookingAt("::=")
+}
+
+// lookingAtRuleNumber checks whether there is a rule number like `[42]`.
+func (p *W3CParser) lookingAtRuleNumber() bool {
+	if !p.lookingAt("[") {
+		return false
+	}
+
+	var idx = p.pos + 1
+	for idx < len(p.buf) && isDigit(p.buf[idx]) {
+		idx++
+	}
+
+	return idx > p.pos+1 && idx < len(p.buf) && p.buf[idx] == ']'
+}
+
+func (p *W3CParser) lookingAtTerm() bool {
+	if p.eof() != nil {
+		return false
+	}
+
+	switch char := p.buf[p.pos]; {
+	case char == '(' || char == '[' || char == '#':
+		return !p.lookingAtRuleNumber()
+	case char == '"' || char == '\'':
+		return true
+	default:
+		return isNameStartChar(char)
+	}
+}
+
+func (p *W3CParser) parseLineLexemes() []Node {
+	var tokens []Node
+
+	for p.pos < len(p.buf) {
+		var begin = p.pos
+		var char = p.buf[p.pos]
+
+		switch {
+		case p.lookingAt("/*"):
+			var length = bytes.Index(p.buf[p.pos+2:], []byte("*/"))
+			if length < 0 {
+				p.pos = len(p.buf)
+			} else {
+				p.pos += length + 4
+			}
+			tokens = append(tokens, &Comment{Token{nil, begin, p.pos}})
+		case p.lookingAtRuleNumber():
+			p.pos += bytes.IndexByte(p.buf[p.pos:], ']') + 1
+			tokens = append(tokens, &Comment{Token{nil, begin, p.pos}})
+		case p.lookingAt("::="):
+			var token = Token{[]byte("::="), begin, begin + 3}
+			tokens = append(tokens, &AssignmentExpression{Expression{
+				Token: token,
+			}})
+			p.pos += 3
+		case char == '|':
+			var token = Token{[]byte{char}, begin, begin + 1}
+			tokens = append(tokens, &AlternativeExpression{Expression{
+				Token: token,
+			}})
+			p.pos++
+		case char == '"' || char == '\'' || char == '[' || char == '#':
+			if node, err := p.parsePrimary(); err != nil {
+				p.pos = begin + 1
+			} else {
+				tokens = append(tokens, node)
+			}
+		case isNameStartChar(char):
+			var node, _ = p.parseName()
+			tokens = append(tokens, node)
+		default:
+			p.pos++
+		}
+	}
+
+	return tokens
+}
+
+func isHexDigit(char byte) bool {
+	return isDigit(char) || (char >= 'a' && char <= 'f') ||
+		(char >= 'A' && char <= 'F')
+}
+
+func isNameStartChar(char byte) bool {
+	return isLetter(char) || char == '_'
+}
+
+func isNameChar(char byte) bool {
+	return isNameStartChar(char) || isDigit(char) || char == '.'
+}
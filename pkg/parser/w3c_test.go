@@ -0,0 +1,105 @@
+package parser
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestW3CParser(t *testing.T) {
+	t.Run("Rule", func(t *testing.T) {
+		var content = []byte(`S ::= (#x20 | [a-z])+ 'x'? - "y"`)
+		var parser = NewW3CParser(bytes.NewBuffer(content))
+		var ast, err = parser.Parse()
+
+		if err != nil {
+			t.Fatalf("failed to parse grammar: %s", err)
+		}
+
+		if length := ast.NoRules(); length != 1 {
+			t.Fatalf("wrong number of statements: %d", length)
+		}
+
+		var compound, ok = ast.rules[0].Rule.Right().(*CompoundExpression)
+		if !ok {
+			t.Fatalf("wrong type of rhs: %T", ast.rules[0].Rule.Right())
+		}
+
+		if rep, ok := compound.Left().(*RepetitionExpression); !ok {
+			t.Errorf("wrong type of the first term: %T", compound.Left())
+		} else if rep.Min != 1 || rep.Max != -1 {
+			t.Errorf("wrong bounds of repetition: %d %d", rep.Min, rep.Max)
+		}
+
+		if exc, ok := compound.Right().(*ExceptionExpression); !ok {
+			t.Errorf("wrong type of the second term: %T", compound.Right())
+		} else if _, ok := exc.Left().(*RepetitionExpression); !ok {
+			t.Errorf("wrong type of minuend: %T", exc.Left())
+		}
+	})
+
+	t.Run("Bounds", func(t *testing.T) {
+		var bounds = map[string][2]int{
+			`A ::= B{4}`:       {4, 4},
+			`A ::= B{2,4}`:     {2, 4},
+			`A ::= B{2,}`:      {2, -1},
+			`A ::= [0-9]{1,3}`: {1, 3},
+		}
+		for source, bound := range bounds {
+			var ast, err = ParseW3C([]byte(source))
+			if err != nil || ast.Error() != nil {
+				t.Fatalf("failed to parse %q: %s %s", source, err,
+					ast.Error())
+			}
+			var rhs = ast.rules[0].Rule.Right()
+			if rep, ok := rhs.(*RepetitionExpression); !ok {
+				t.Errorf("wrong type of rhs of %q: %T", source, rhs)
+			} else if rep.Min != bound[0] || rep.Max != bound[1] {
+				t.Errorf("wrong bounds of %q: %d %d", source, rep.Min,
+					rep.Max)
+			}
+		}
+
+		var ast, err = ParseW3C([]byte(`A ::= B{4,2}`))
+		if err != nil {
+			t.Fatalf("failed to parse grammar: %s", err)
+		}
+		var diags = Diagnostics(ast)
+		if len(diags) != 1 || diags[0].Code != CodeInvalidBounds {
+			t.Errorf("wrong diagnostics: %v", diags)
+		}
+	})
+
+	t.Run("CodePoint", func(t *testing.T) {
+		var ast, err = ParseW3C([]byte(`A ::= #x41`))
+		if err != nil || ast.Error() != nil {
+			t.Fatalf("failed to parse grammar: %s %s", err, ast.Error())
+		}
+
+		if term, ok := ast.rules[0].Rule.Right().(*Terminal); !ok {
+			t.Errorf("wrong type of rhs: %T", ast.rules[0].Rule.Right())
+		} else if name := string(term.Name); name != "A" {
+			t.Errorf("wrong character: %q", name)
+		}
+	})
+
+	t.Run("XML", func(t *testing.T) {
+		var content = readBNFFile(t, "xml.w3c")
+		var parser = NewW3CParser(bytes.NewBuffer(content))
+		var ast, err = parser.Parse()
+
+		if err != nil {
+			t.Fatalf("failed to parse grammar: %s", err)
+		}
+
+		var norules = 0
+		for _, stmt := range ast.rules {
+			if stmt.Rule != nil {
+				norules++
+			}
+		}
+
+		if norules != 8 {
+			t.Errorf("wrong number of rules: %d", norules)
+		}
+	})
+}
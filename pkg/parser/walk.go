@@ -0,0 +1,152 @@
+package parser
+
+import "errors"
+
+// SkipChildren is returned from enter callback of Walk in order to skip
+// children of the current node. Leave callback is called for the node anyway.
+var SkipChildren = errors.New("bnf: skip children")
+
+// StopWalk is returned from callbacks of Walk in order to stop traversal
+// without error.
+var StopWalk = errors.New("bnf: stop walk")
+
+// Cursor describes the current node of traversal. It is reused between calls
+// of callbacks, so it should not be retained.
+type Cursor struct {
+	// Node is the current node.
+	Node Node
+	// Parents is a chain of ancestors of the current node from the root of
+	// traversal to the immediate parent.
+	Parents []Node
+}
+
+// Depth returns depth of the current node. Root of traversal has zero depth.
+func (c *Cursor) Depth() int {
+	return len(c.Parents)
+}
+
+// Parent returns immediate parent of the current node or nil for the root.
+func (c *Cursor) Parent() Node {
+	if len(c.Parents) == 0 {
+		return nil
+	}
+	return c.Parents[len(c.Parents)-1]
+}
+
+// Kind returns kind of the current node.
+func (c *Cursor) Kind() string {
+	return Kind(c.Node)
+}
+
+// WalkFunc is a callback of Walk.
+type WalkFunc func(cursor *Cursor) error
+
+// Walk traverses a tree in depth-first order. Callback enter is called before
+// children of a node are visited and callback leave is called after that. Any
+// of callbacks could be nil. Left child is visited before the right one, so
+// chains of alternatives or compound expressions are visited as nested nodes.
+// Walk stops on the first error and returns it unless it is StopWalk.
+func Walk(root Node, enter, leave WalkFunc) error {
+	var cursor Cursor
+	if err := walk(&cursor, root, enter, leave); err != StopWalk {
+		return err
+	}
+	return nil
+}
+
+func walk(cursor *Cursor, node Node, enter, leave WalkFunc) error {
+	if node == nil {
+		return nil
+	}
+
+	cursor.Node = node
+	var err error
+	if enter != nil {
+		err = enter(cursor)
+	}
+
+	switch err {
+	case nil:
+		cursor.Parents = append(cursor.Parents, node)
+		for _, child := range []Node{node.Left(), node.Right()} {
+			if err := walk(cursor, child, enter, leave); err != nil {
+				return err
+			}
+		}
+		cursor.Parents = cursor.Parents[:len(cursor.Parents)-1]
+		cursor.Node = node
+	case SkipChildren:
+	default:
+		return err
+	}
+
+	if leave != nil {
+		return leave(cursor)
+	}
+	return nil
+}
+
+// Walk traverses every statement of semantic parse tree or every lexeme of
+// syntactic one. See Walk for details.
+func (ast *AST) Walk(enter, leave WalkFunc) error {
+	var roots []Node
+	if ast.semantic {
+		for _, stmt := range ast.rules {
+			roots = append(roots, stmt)
+		}
+	} else {
+		for _, lemmes := range ast.lemmes {
+			roots = append(roots, lemmes...)
+		}
+	}
+
+	var cursor Cursor
+	for _, root := range roots {
+		if err := walk(&cursor, root, enter, leave); err == StopWalk {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Kind returns name of node type like NonTerminal or AlternativeExpression.
+func Kind(node Node) string {
+	switch node.(type) {
+	case *AlternativeExpression:
+		return "AlternativeExpression"
+	case *AssignmentExpression:
+		return "AssignmentExpression"
+	case *CharacterClass:
+		return "CharacterClass"
+	case *Comment:
+		return "Comment"
+	case *CompoundExpression:
+		return "CompoundExpression"
+	case *Epsilon:
+		return "Epsilon"
+	case *ExceptionExpression:
+		return "ExceptionExpression"
+	case *GroupExpression:
+		return "GroupExpression"
+	case *NonTerminal:
+		return "NonTerminal"
+	case *PredicateExpression:
+		return "PredicateExpression"
+	case *RepetitionExpression:
+		return "RepetitionExpression"
+	case *SpecialSequence:
+		return "SpecialSequence"
+	case *Statement:
+		return "Statement"
+	case *Terminal:
+		return "Terminal"
+	case *Token:
+		return "Token"
+	case *Wildcard:
+		return "Wildcard"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,89 @@
+package parser
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWalk(t *testing.T) {
+	var ast, err = Parse([]byte(`<a> ::= <b> "c" | <d>`))
+	if err != nil {
+		t.Fatalf("failed to parse grammar: %s", err)
+	}
+
+	t.Run("Order", func(t *testing.T) {
+		var events []string
+		var enter = func(c *Cursor) error {
+			events = append(events, "+"+c.Kind())
+			return nil
+		}
+		var leave = func(c *Cursor) error {
+			events = append(events, "-"+c.Kind())
+			return nil
+		}
+		if err := ast.Walk(enter, leave); err != nil {
+			t.Fatalf("failed to walk: %s", err)
+		}
+
+		var expected = "+Statement +AssignmentExpression +NonTerminal " +
+			"-NonTerminal +AlternativeExpression +CompoundExpression " +
+			"+NonTerminal -NonTerminal +Terminal -Terminal " +
+			"-CompoundExpression +NonTerminal -NonTerminal " +
+			"-AlternativeExpression -AssignmentExpression -Statement"
+		if actual := strings.Join(events, " "); actual != expected {
+			t.Errorf("wrong order of events: %s", actual)
+		}
+	})
+
+	t.Run("Parents", func(t *testing.T) {
+		var depths []int
+		var enter = func(c *Cursor) error {
+			if term, ok := c.Node.(*Terminal); ok {
+				if _, ok := c.Parent().(*CompoundExpression); !ok {
+					t.Errorf("wrong parent of %s: %T", term, c.Parent())
+				}
+				depths = append(depths, c.Depth())
+			}
+			return nil
+		}
+		ast.Walk(enter, nil)
+		if len(depths) != 1 || depths[0] != 4 {
+			t.Errorf("wrong depths of terminals: %v", depths)
+		}
+	})
+
+	t.Run("SkipChildren", func(t *testing.T) {
+		var count int
+		var enter = func(c *Cursor) error {
+			count++
+			if _, ok := c.Node.(*AlternativeExpression); ok {
+				return SkipChildren
+			}
+			return nil
+		}
+		ast.Walk(enter, nil)
+		if count != 4 {
+			t.Errorf("wrong number of visited nodes: %d", count)
+		}
+	})
+
+	t.Run("StopWalk", func(t *testing.T) {
+		var count int
+		var leave = func(c *Cursor) error {
+			count++
+			return StopWalk
+		}
+		if err := ast.Walk(nil, leave); err != nil || count != 1 {
+			t.Errorf("walk is not stopped: count=%d err=%v", count, err)
+		}
+
+		var errStop = errors.New("stop")
+		var enter = func(c *Cursor) error {
+			return errStop
+		}
+		if err := ast.Walk(enter, nil); err != errStop {
+			t.Errorf("wrong error: %v", err)
+		}
+	})
+}
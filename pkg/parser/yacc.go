@@ -0,0 +1,449 @@
+package parser
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+)
+
+// yaccTokenDirectives are declarations which introduce token names.
+var yaccTokenDirectives = map[string]bool{
+	"left":       true,
+	"nonassoc":   true,
+	"precedence": true,
+	"right":      true,
+	"token":      true,
+}
+
+// yaccArgDirectives are directives in rules which take single argument.
+var yaccArgDirectives = map[string]bool{
+	"dprec":     true,
+	"expect":    true,
+	"expect-rr": true,
+	"merge":     true,
+	"prec":      true,
+}
+
+// YaccParser performs semantic parsing of rules section of Yacc or Bison
+// grammar files. Declarations, prologue blocks `%{ ... %}`, epilogue, and
+// semantic actions in braces are skipped. If there is no section separator
+// `%%` then the whole input is treated as rules section.
+//
+// Identifiers declared with %token, %left, %right, %nonassoc, or %precedence
+// are terminals and the rest of identifiers are non-terminals. Directives in
+// rules like `%prec X` are special sequences. Empty alternative is presented
+// with an empty special sequence.
+type YaccParser struct {
+	documentParser
+
+	tokens map[string]bool
+}
+
+func NewYaccParser(reader io.Reader) *YaccParser {
+	var p = &YaccParser{
+		documentParser: newDocumentParser(reader),
+		tokens:         make(map[string]bool),
+	}
+	p.comment, p.tokenize = p.parseOptCComment, p.parseLineLexemes
+	return p
+}
+
+// ParseYacc parses rules section of Yacc or Bison grammar file. Likewise
+// Parse, it falls back to lexical parsing on error.
+func ParseYacc(source []byte) (*AST, error) {
+	var ast, errSem = NewYaccParser(bytes.NewReader(source)).Parse()
+	if errSem == nil {
+		return ast, nil
+	}
+
+	var lemmes, errSyn = NewYaccParser(bytes.NewReader(source)).parseLexemes()
+	if errSyn != nil {
+		return nil, errSyn
+	}
+
+	return &AST{err: errSem, lemmes: lemmes, source: source}, nil
+}
+
+func (p *YaccParser) Parse() (*AST, error) {
+	if bytes, err := ioutil.ReadAll(p.Reader); err != nil {
+		return nil, err
+	} else {
+		p.buf = bytes
+		p.pos = skipBOM(p.buf)
+	}
+
+	var rules, err = p.parseSyntax()
+
+	switch err := err.(type) {
+	case *DescError:
+		return nil, locate(err, p.buf)
+	case error:
+		return nil, locate(&Error{err: err, pos: p.pos}, p.buf)
+	default:
+		return &AST{rules: rules, semantic: true, source: p.buf}, nil
+	}
+}
+
+func (p *YaccParser) parseSyntax() ([]*Statement, error) {
+	// Restrict input to rules section. Offsets are kept intact since the
+	// beginning of input is not cut off.
+	if begin := p.findSeparator(p.pos); begin >= 0 {
+		p.parseDeclarations(begin)
+		p.pos = begin + 2
+		if end := p.findSeparator(p.pos); end >= 0 {
+			p.buf = p.buf[:end]
+		}
+	}
+
+	var stmts []*Statement
+
+	for {
+		if err := p.parseGap(); err != nil {
+			return nil, err
+		}
+
+		stmts = p.flushComments(stmts)
+
+		if err := p.eof(); err != nil {
+			return stmts, nil
+		}
+
+		if stmt, err := p.parseRule(); err != nil {
+			return nil, err
+		} else {
+			stmts = append(stmts, stmt)
+			stmts = p.flushComments(stmts)
+		}
+	}
+}
+
+// findSeparator returns offset of section separator `%%` which is placed at
+// the beginning of a line or -1 if there is no one.
+func (p *YaccParser) findSeparator(from int) int {
+	for from < len(p.buf) {
+		var idx = bytes.Index(p.buf[from:], []byte("%%"))
+		if idx < 0 {
+			return -1
+		}
+
+		if idx += from; idx == skipBOM(p.buf) || p.buf[idx-1] == '\n' {
+			return idx
+		}
+
+		from = idx + 2
+	}
+	return -1
+}
+
+// parseDeclarations collects token names from declarations section which
+// ends at the given offset.
+func (p *YaccParser) parseDeclarations(end int) {
+	var collecting = false
+
+	for p.pos < end {
+		switch char := p.buf[p.pos]; {
+		case p.lookingAt("%{"):
+			if idx := bytes.Index(p.buf[p.pos:], []byte("%}")); idx < 0 {
+				p.pos = end
+			} else {
+				p.pos += idx + 2
+			}
+			collecting = false
+		case p.lookingAt("/*") || p.lookingAt("//"):
+			p.parseCComment()
+		case char == '%':
+			p.pos++
+			collecting = yaccTokenDirectives[string(p.parseWord())]
+		case char == '{':
+			p.skipBlock('{', '}')
+		case char == '<':
+			if idx := bytes.IndexByte(p.buf[p.pos:], '>'); idx < 0 {
+				p.pos = end
+			} else {
+				p.pos += idx + 1
+			}
+		case char == '"' || char == '\'':
+			p.parseCLiteral(char)
+		case isNameStartChar(char):
+			var name = p.parseWord()
+			if collecting {
+				p.tokens[string(name)] = true
+			}
+		default:
+			p.pos++
+		}
+	}
+}
+
+func (p *YaccParser) parseRule() (*Statement, error) {
+	var err error
+	var expr = new(AssignmentExpression)
+
+	if p.eof() != nil || !isNameStartChar(p.buf[p.pos]) {
+		return nil, NewDescError(p.failure(), p.pos, "rule name")
+	} else {
+		expr.LeftChild = p.parseSymbol()
+	}
+
+	if err = p.parseGap(); err != nil {
+		return nil, err
+	}
+
+	if !p.lookingAt(":") {
+		return nil, NewDescError(p.failure(), p.pos, "':'")
+	} else {
+		expr.Token = Token{Name: []byte{':'}, Begin: p.pos, End: p.pos + 1}
+		p.pos++
+	}
+
+	if err = p.parseGap(); err != nil {
+		return nil, err
+	}
+
+	if expr.RightChild, err = p.parseAlternatives(); err != nil {
+		return nil, err
+	}
+
+	// Semicolon is optional in Bison, so a rule could end with the next one.
+	if p.lookingAt(";") {
+		p.pos++
+	} else if p.eof() == nil && !p.lookingAtRule() {
+		return nil, NewDescError(ErrUnexpectedChar, p.pos, "'|' or ';'")
+	}
+
+	return &Statement{Rule: expr}, nil
+}
+
+// parseAlternatives parses alternatives separated with `|` into
+// right-recursive chain of AlternativeExpression.
+func (p *YaccParser) parseAlternatives() (Node, error) {
+	var seqs []Node
+	var bars []Token
+
+	for {
+		if seq, err := p.parseSequence(); err != nil {
+			return nil, err
+		} else {
+			seqs = append(seqs, seq)
+		}
+
+		if !p.lookingAt("|") {
+			break
+		}
+
+		bars = append(bars, Token{
+			Name:  []byte{'|'},
+			Begin: p.pos,
+			End:   p.pos + 1,
+		})
+		p.pos++
+
+		if err := p.parseGap(); err != nil {
+			return nil, err
+		}
+	}
+
+	var node = seqs[len(seqs)-1]
+	for idx := len(bars) - 1; idx >= 0; idx-- {
+		node = &AlternativeExpression{Expression{
+			Token:      bars[idx],
+			LeftChild:  seqs[idx],
+			RightChild: node,
+		}}
+	}
+
+	return node, nil
+}
+
+// parseSequence parses symbols of an alternative into right-recursive chain
+// of CompoundExpression. Semantic actions are skipped.
+func (p *YaccParser) parseSequence() (Node, error) {
+	var terms []Node
+	var begin = p.pos
+
+loop:
+	for p.eof() == nil && !p.lookingAtRule() {
+		var offset = p.pos
+
+		switch char := p.buf[p.pos]; {
+		case char == '{':
+			if err := p.skipBlock('{', '}'); err != nil {
+				return nil, err
+			}
+		case char == '%':
+			if node, err := p.parseDirective(); err != nil {
+				return nil, err
+			} else {
+				terms = append(terms, node)
+			}
+		case char == '"' || char == '\'':
+			if name, err := p.parseCLiteral(char); err != nil {
+				return nil, err
+			} else {
+				var token = Token{name, offset, p.pos}
+				terms = append(terms, &Terminal{token})
+			}
+		case isNameStartChar(char):
+			terms = append(terms, p.parseSymbol())
+		default:
+			break loop
+		}
+
+		if err := p.parseGap(); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(terms) == 0 {
+		return &SpecialSequence{Token{nil, begin, begin}}, nil
+	}
+
+	var node = terms[len(terms)-1]
+	for idx := len(terms) - 2; idx >= 0; idx-- {
+		var span = Span(terms[idx])
+		node = &CompoundExpression{Expression{
+			Token:      Token{Begin: span.Begin, End: span.End},
+			LeftChild:  terms[idx],
+			RightChild: node,
+		}}
+	}
+
+	return node, nil
+}
+
+// parseDirective parses directive in rules like `%empty` or `%prec X`
+// together with its argument.
+func (p *YaccParser) parseDirective() (Node, error) {
+	var begin = p.pos
+	p.pos++
+
+	if p.lookingAt("?{") {
+		p.pos++
+		if err := p.skipBlock('{', '}'); err != nil {
+			return nil, err
+		}
+	} else if name := p.parseWord(); len(name) == 0 {
+		return nil, NewDescError(p.failure(), p.pos, "directive")
+	} else if yaccArgDirectives[string(name)] {
+		for p.eof() == nil && (p.buf[p.pos] == ' ' || p.buf[p.pos] == '\t') {
+			p.pos++
+		}
+
+		if p.lookingAt("<") {
+			if _, err := p.parseQuoted('>'); err != nil {
+				return nil, err
+			}
+		} else if p.eof() == nil && (p.buf[p.pos] == '\'' ||
+			p.buf[p.pos] == '"') {
+			if _, err := p.parseCLiteral(p.buf[p.pos]); err != nil {
+				return nil, err
+			}
+		} else if len(p.parseWord()) == 0 {
+			return nil, NewDescError(p.failure(), p.pos, "argument")
+		}
+	}
+
+	var token = Token{p.buf[begin:p.pos], begin, p.pos}
+	return &SpecialSequence{token}, nil
+}
+
+// parseSymbol parses identifier which is either terminal or non-terminal
+// together with optional named reference like `expr[left]`.
+func (p *YaccParser) parseSymbol() Node {
+	var begin = p.pos
+	var token = Token{Name: p.parseWord(), Begin: begin, End: p.pos}
+
+	if p.lookingAt("[") {
+		if idx := bytes.IndexByte(p.buf[p.pos:], ']'); idx >= 0 {
+			p.pos += idx + 1
+		}
+	}
+
+	if p.tokens[string(token.Name)] {
+		return &Terminal{token}
+	} else {
+		return &NonTerminal{token}
+	}
+}
+
+// parseWord parses identifier, number, or name of directive.
+func (p *YaccParser) parseWord() []byte {
+	var begin = p.pos
+	for p.eof() == nil && (isNameChar(p.buf[p.pos]) || p.buf[p.pos] == '-') {
+		p.pos++
+	}
+	return p.buf[begin:p.pos]
+}
+
+// lookingAtRule checks whether a rule definition starts at the current
+// position, i.e. there is an identifier followed by `:`.
+func (p *YaccParser) lookingAtRule() bool {
+	var offset = p.pos
+	defer func() {
+		p.pos = offset
+	}()
+
+	if p.eof() != nil || !isNameStartChar(p.buf[p.pos]) {
+		return false
+	}
+
+	p.parseSymbol()
+	for p.eof() == nil && isWhitespace(p.buf[p.pos]) {
+		p.pos++
+	}
+
+	return p.lookingAt(":")
+}
+
+func (p *YaccParser) parseLineLexemes() []Node {
+	var tokens []Node
+
+	for p.pos < len(p.buf) {
+		var begin = p.pos
+		var char = p.buf[p.pos]
+
+		switch {
+		case p.lookingAt("/*") || p.lookingAt("//"):
+			p.parseCComment()
+			tokens = append(tokens, &Comment{Token{nil, begin, p.pos}})
+		case char == '%':
+			p.pos++
+			if p.lookingAt("%") || p.lookingAt("{") || p.lookingAt("}") {
+				p.pos++
+			} else {
+				p.parseWord()
+			}
+			var token = Token{p.buf[begin:p.pos], begin, p.pos}
+			tokens = append(tokens, &SpecialSequence{token})
+		case char == '{':
+			// Action could continue on the next lines.
+			if p.skipBlock('{', '}') != nil {
+				p.pos = len(p.buf)
+			}
+		case char == '"' || char == '\'':
+			if name, err := p.parseCLiteral(char); err != nil {
+				p.pos = begin + 1
+			} else {
+				tokens = append(tokens, &Terminal{Token{name, begin, p.pos}})
+			}
+		case char == ':':
+			var token = Token{[]byte{char}, begin, begin + 1}
+			tokens = append(tokens, &AssignmentExpression{Expression{
+				Token: token,
+			}})
+			p.pos++
+		case char == '|':
+			var token = Token{[]byte{char}, begin, begin + 1}
+			tokens = append(tokens, &AlternativeExpression{Expression{
+				Token: token,
+			}})
+			p.pos++
+		case isNameStartChar(char):
+			tokens = append(tokens, p.parseSymbol())
+		default:
+			p.pos++
+		}
+	}
+
+	return tokens
+}
@@ -0,0 +1,105 @@
+package parser
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestYaccParser(t *testing.T) {
+	t.Run("Rule", func(t *testing.T) {
+		var content = []byte(`expr: expr '+' term { $$ = $1 + $3; } | term ;`)
+		var parser = NewYaccParser(bytes.NewBuffer(content))
+		var ast, err = parser.Parse()
+
+		if err != nil {
+			t.Fatalf("failed to parse grammar: %s", err)
+		}
+
+		if length := ast.NoRules(); length != 1 {
+			t.Fatalf("wrong number of statements: %d", length)
+		}
+
+		var alts = Alternatives(ast.rules[0].Rule.Right())
+		if len(alts) != 2 {
+			t.Fatalf("wrong number of alternatives: %d", len(alts))
+		}
+
+		if span := Span(alts[0]); span.Begin != 6 || span.End != 19 {
+			t.Errorf("wrong span of the first alternative: %v", span)
+		}
+	})
+
+	t.Run("Tokens", func(t *testing.T) {
+		var content = []byte("%token NUM\n%%\nexp: NUM | var;\n")
+		var ast, err = ParseYacc(content)
+		if err != nil || ast.Error() != nil {
+			t.Fatalf("failed to parse grammar: %s %s", err, ast.Error())
+		}
+
+		var alts = Alternatives(ast.rules[0].Rule.Right())
+		if _, ok := alts[0].(*Terminal); !ok {
+			t.Errorf("declared token is not a terminal: %T", alts[0])
+		}
+		if _, ok := alts[1].(*NonTerminal); !ok {
+			t.Errorf("undeclared symbol is not a non-terminal: %T", alts[1])
+		}
+	})
+
+	t.Run("UnterminatedAction", func(t *testing.T) {
+		var content = []byte("exp: NUM { $$ = $1;\n")
+		var parser = NewYaccParser(bytes.NewBuffer(content))
+		if _, err := parser.Parse(); err == nil {
+			t.Errorf("error is expected for unterminated action")
+		}
+	})
+
+	t.Run("Calc", func(t *testing.T) {
+		var content = readBNFFile(t, "calc.y")
+		var parser = NewYaccParser(bytes.NewBuffer(content))
+		var ast, err = parser.Parse()
+
+		if err != nil {
+			t.Fatalf("failed to parse grammar: %s", err)
+		}
+
+		var names []string
+		for _, stmt := range ast.rules {
+			if stmt.Rule != nil {
+				var lhs = stmt.Rule.Left().(*NonTerminal)
+				names = append(names, string(lhs.Name))
+			}
+		}
+
+		var expected = []string{"input", "line", "exp", "opt_exp"}
+		if len(names) != len(expected) {
+			t.Fatalf("wrong rules: %v", names)
+		}
+		for idx := range names {
+			if names[idx] != expected[idx] {
+				t.Errorf("wrong rule #%d: %s", idx, names[idx])
+			}
+		}
+
+		if alts := Alternatives(ast.rules[3].Rule.Right()); len(alts) != 8 {
+			t.Errorf("wrong number of alternatives of exp: %d", len(alts))
+		}
+	})
+}
+
+func TestLineIndex(t *testing.T) {
+	var lines = [][]byte{[]byte("ab"), []byte(""), []byte("cde")}
+	var source, index = JoinLines(lines)
+
+	if string(source) != "ab\n\ncde" {
+		t.Fatalf("wrong source: %q", source)
+	}
+
+	for offset, expected := range [][2]int{
+		{0, 0}, {0, 1}, {0, 2}, {1, 0}, {2, 0}, {2, 1}, {2, 2},
+	} {
+		var line, col = index.Locate(offset)
+		if line != expected[0] || col != expected[1] {
+			t.Errorf("wrong location of %d: %d:%d", offset, line, col)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+// Package workspace manages grammar files which are included by documents
+// with `; %include "common.bnf"` directives. Files are loaded lazily and
+// they are reloaded as soon as they are changed on disk, so symbols of
+// included files are always up to date.
+package workspace
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/daskol/nvim-bnf/pkg/analysis"
+	"github.com/daskol/nvim-bnf/pkg/cache"
+	"github.com/daskol/nvim-bnf/pkg/parser"
+)
+
+// maxDepth limits nesting of includes.
+const maxDepth = 16
+
+// File is a grammar file which is loaded from disk. Rules of its grammar
+// refer to the file.
+type File struct {
+	Path    string
+	Dialect parser.Dialect
+	Lines   [][]byte
+	Grammar *analysis.Grammar
+
+	modTime time.Time
+	size    int64
+}
+
+// Includes returns absolute paths of files which the file includes.
+func (f *File) Includes() []string {
+	return resolvePaths(filepath.Dir(f.Path), f.Lines)
+}
+
+// Workspace is a cache of loaded files. It is safe for concurrent use.
+// Grammars of files are persisted on disk if workspace has cache.
+type Workspace struct {
+	files map[string]*File
+	cache *cache.Cache
+	guard sync.Mutex
+}
+
+// New creates an empty workspace.
+func New() *Workspace {
+	return &Workspace{files: make(map[string]*File)}
+}
+
+// SetCache sets on-disk cache of grammars. Nil cache disables it.
+func (w *Workspace) SetCache(c *cache.Cache) {
+	w.guard.Lock()
+	defer w.guard.Unlock()
+	w.cache = c
+}
+
+// Load returns a file by its path. File is read from disk if it is not
+// loaded yet or if it is changed since it was loaded.
+func (w *Workspace) Load(path string) (*File, error) {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+
+	var info, err = os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	w.guard.Lock()
+	var file, ok = w.files[path]
+	w.guard.Unlock()
+	if ok && file.modTime.Equal(info.ModTime()) && file.size == info.Size() {
+		return file, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file = &File{
+		Path:    path,
+		Dialect: parser.DetectDialect(path),
+		Lines:   bytes.Split(data, []byte{'\n'}),
+		modTime: info.ModTime(),
+		size:    info.Size(),
+	}
+	file.Grammar = w.parse(path, file.Dialect, data, file.Lines)
+	for _, rule := range file.Grammar.Rules {
+		rule.File = path
+	}
+
+	w.guard.Lock()
+	w.files[path] = file
+	w.guard.Unlock()
+	return file, nil
+}
+
+// parse builds grammar of a file or restores it from on-disk cache. Cache is
+// best effort, so failure to store an entry is ignored.
+func (w *Workspace) parse(
+	path string, dialect parser.Dialect, data []byte, lines [][]byte,
+) *analysis.Grammar {
+	w.guard.Lock()
+	var c = w.cache
+	w.guard.Unlock()
+
+	if c != nil {
+		if grammar, ok := c.Load(path, dialect, data); ok {
+			return grammar
+		}
+	}
+
+	var grammar = analysis.NewGrammar(dialect, lines)
+	if c != nil {
+		c.Store(path, dialect, data, grammar)
+	}
+	return grammar
+}
+
+// Invalidate drops a file from cache, so it is read again on the next load.
+// It reports whether the file was loaded.
+func (w *Workspace) Invalidate(path string) bool {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+
+	w.guard.Lock()
+	defer w.guard.Unlock()
+	var _, ok = w.files[path]
+	delete(w.files, path)
+	return ok
+}
+
+// Includes returns files which are included by lines of a document located
+// in a directory. Includes are followed transitively, every file is returned
+// once and cycles are broken. Files which could not be loaded are skipped and
+// the first error is returned together with the rest files.
+func (w *Workspace) Includes(dir string, lines [][]byte) ([]*File, error) {
+	var files []*File
+	var visited = make(map[string]bool)
+	var firstErr error
+
+	var visit func(paths []string, depth int)
+	visit = func(paths []string, depth int) {
+		for _, path := range paths {
+			if visited[path] {
+				continue
+			}
+			visited[path] = true
+
+			if depth >= maxDepth {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("workspace: includes are nested "+
+						"too deep: %s", path)
+				}
+				continue
+			}
+
+			var file, err = w.Load(path)
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+
+			files = append(files, file)
+			visit(file.Includes(), depth+1)
+		}
+	}
+
+	visit(resolvePaths(dir, lines), 0)
+	return files, firstErr
+}
+
+// Rules returns rules of all files which are included by lines of a document
+// located in a directory (see Includes).
+func (w *Workspace) Rules(
+	dir string, lines [][]byte,
+) ([]*analysis.Rule, error) {
+	var files, err = w.Includes(dir, lines)
+	var rules []*analysis.Rule
+	for _, file := range files {
+		rules = append(rules, file.Grammar.Rules...)
+	}
+	return rules, err
+}
+
+// resolvePaths returns absolute paths of includes of lines. Relative paths
+// are relative to a directory.
+func resolvePaths(dir string, lines [][]byte) []string {
+	var paths = analysis.IncludeDirectives(lines)
+	for idx, path := range paths {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+		paths[idx] = path
+	}
+	return paths
+}
@@ -0,0 +1,95 @@
+package workspace
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %s", path, err)
+	}
+}
+
+func TestIncludes(t *testing.T) {
+	var root, err = ioutil.TempDir("", "workspace")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %s", err)
+	}
+	defer os.RemoveAll(root)
+
+	if err := os.Mkdir(filepath.Join(root, "lib"), 0755); err != nil {
+		t.Fatalf("failed to create directory: %s", err)
+	}
+
+	writeFile(t, filepath.Join(root, "common.bnf"),
+		"; %include \"lib/digits.bnf\"\n"+
+			"<number> ::= <digit> | <digit> <number>\n")
+	writeFile(t, filepath.Join(root, "lib", "digits.bnf"),
+		"; %include \"../common.bnf\"\n<digit> ::= \"0\" | \"1\"\n")
+
+	var lines = [][]byte{
+		[]byte(`; %include "common.bnf"`),
+		[]byte(`; %include "missing.bnf"`),
+		[]byte(`<expr> ::= <number>`),
+	}
+
+	var ws = New()
+	files, err := ws.Includes(root, lines)
+	if err == nil {
+		t.Errorf("missing file is not reported")
+	}
+	if len(files) != 2 {
+		t.Fatalf("wrong number of included files: %d", len(files))
+	}
+
+	rules, _ := ws.Rules(root, lines)
+	var names []string
+	for _, rule := range rules {
+		names = append(names, rule.Name)
+		if rule.File == "" {
+			t.Errorf("rule <%s> does not refer to file", rule.Name)
+		}
+	}
+	if len(names) != 2 || names[0] != "number" || names[1] != "digit" {
+		t.Errorf("wrong included rules: %v", names)
+	}
+}
+
+func TestLoadReloadsChangedFile(t *testing.T) {
+	var root, err = ioutil.TempDir("", "workspace")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %s", err)
+	}
+	defer os.RemoveAll(root)
+
+	var path = filepath.Join(root, "common.bnf")
+	writeFile(t, path, "<a> ::= \"a\"\n")
+
+	var ws = New()
+	first, err := ws.Load(path)
+	if err != nil {
+		t.Fatalf("failed to load file: %s", err)
+	}
+
+	if second, _ := ws.Load(path); second != first {
+		t.Errorf("unchanged file is loaded again")
+	}
+
+	writeFile(t, path, "<b> ::= \"b\"\n<c> ::= \"c\"\n")
+	var future = time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, future, future); err != nil {
+		t.Fatalf("failed to change time of file: %s", err)
+	}
+
+	third, err := ws.Load(path)
+	if err != nil {
+		t.Fatalf("failed to load file: %s", err)
+	}
+	if third == first || third.Grammar.NoRules() != 2 {
+		t.Errorf("changed file is not reloaded")
+	}
+}
@@ -7,16 +7,64 @@ else
 endif
 
 function! s:RequireHost(host) abort
-  return jobstart(['nvim-bnf'], {'rpc': v:true})
+  let l:args = ['nvim-bnf'] + get(g:, 'bnf_host_args', [])
+  return jobstart(l:args, {'rpc': v:true})
 endfunction
 
 " Register tast-specific plugin host and register plugin.
 call remote#host#Register('nvim-bnf', 'x', function('s:RequireHost'))
+
+" Start plugin host eagerly if there are custom file patterns so that it could
+" register autocommands for them.
+if !empty(get(g:, 'bnf_file_patterns', []))
+  autocmd VimEnter * call remote#host#Require('nvim-bnf')
+endif
+
 call remote#host#RegisterPlugin('nvim-bnf', '0', [
-\ {'type': 'autocmd', 'name': 'BufNewFile', 'sync': 0, 'opts': {'eval': 'expand("<afile>")', 'group': 'nvim-bnf', 'pattern': '*.bnf'}},
-\ {'type': 'autocmd', 'name': 'BufRead', 'sync': 0, 'opts': {'eval': 'expand("<afile>")', 'group': 'nvim-bnf', 'pattern': '*.bnf'}},
-\ {'type': 'function', 'name': 'BNFNcm2OnComplete', 'sync': 0, 'opts': {}},
-\ {'type': 'function', 'name': 'BNFNcm2OnWarmup', 'sync': 0, 'opts': {}},
+\ {'type': 'autocmd', 'name': 'BufNewFile', 'sync': 0, 'opts': {'eval': 'expand("<afile>")', 'group': 'nvim-bnf', 'pattern': '*.bnf,*.ebnf,*.g4,*.peg,*.w3c,*.y,*.yy'}},
+\ {'type': 'autocmd', 'name': 'BufRead', 'sync': 0, 'opts': {'eval': 'expand("<afile>")', 'group': 'nvim-bnf', 'pattern': '*.bnf,*.ebnf,*.g4,*.peg,*.w3c,*.y,*.yy'}},
+\ {'type': 'autocmd', 'name': 'BufWritePost', 'sync': 0, 'opts': {'eval': '{"buffer": str2nr(expand("<abuf>")), "filename": expand("<afile>:p")}', 'group': 'nvim-bnf', 'pattern': '*.bnf,*.ebnf,*.g4,*.peg,*.w3c,*.y,*.yy'}},
+\ {'type': 'autocmd', 'name': 'CursorHold', 'sync': 0, 'opts': {'eval': '{"buffer": str2nr(expand("<abuf>")), "row": line(".") - 1, "col": col(".") - 1}', 'group': 'nvim-bnf', 'pattern': '*.bnf,*.ebnf,*.g4,*.peg,*.w3c,*.y,*.yy'}},
+\ {'type': 'autocmd', 'name': 'InsertLeave', 'sync': 0, 'opts': {'group': 'nvim-bnf', 'pattern': '*.bnf,*.ebnf,*.g4,*.peg,*.w3c,*.y,*.yy'}},
+\ {'type': 'autocmd', 'name': 'TextChangedI', 'sync': 0, 'opts': {'eval': '{"buffer": str2nr(expand("<abuf>")), "line": getline("."), "col": col(".") - 1}', 'group': 'nvim-bnf', 'pattern': '*.bnf,*.ebnf,*.g4,*.peg,*.w3c,*.y,*.yy'}},
+\ {'type': 'command', 'name': 'BNFAttach', 'sync': 1, 'opts': {}},
+\ {'type': 'command', 'name': 'BNFBlameRule', 'sync': 1, 'opts': {'bang': '', 'nargs': '?'}},
+\ {'type': 'command', 'name': 'BNFCompareRules', 'sync': 1, 'opts': {'nargs': '+'}},
+\ {'type': 'command', 'name': 'BNFConvert', 'sync': 1, 'opts': {'bang': '', 'nargs': '1'}},
+\ {'type': 'command', 'name': 'BNFDefineUndefined', 'sync': 1, 'opts': {}},
+\ {'type': 'command', 'name': 'BNFDerive', 'sync': 1, 'opts': {'nargs': '?'}},
+\ {'type': 'command', 'name': 'BNFDetach', 'sync': 1, 'opts': {}},
+\ {'type': 'command', 'name': 'BNFFix', 'sync': 1, 'opts': {}},
+\ {'type': 'command', 'name': 'BNFFormat', 'sync': 1, 'opts': {}},
+\ {'type': 'command', 'name': 'BNFGotoDefinition', 'sync': 1, 'opts': {}},
+\ {'type': 'command', 'name': 'BNFHighlight', 'sync': 1, 'opts': {}},
+\ {'type': 'command', 'name': 'BNFHighlightRange', 'sync': 1, 'opts': {'range': ''}},
+\ {'type': 'command', 'name': 'BNFHighlightToggle', 'sync': 1, 'opts': {}},
+\ {'type': 'command', 'name': 'BNFHover', 'sync': 1, 'opts': {}},
+\ {'type': 'command', 'name': 'BNFLeftFactor', 'sync': 1, 'opts': {'bang': ''}},
+\ {'type': 'command', 'name': 'BNFLineInfo', 'sync': 1, 'opts': {}},
+\ {'type': 'command', 'name': 'BNFNewRule', 'sync': 1, 'opts': {'nargs': '1'}},
+\ {'type': 'command', 'name': 'BNFQuickfix', 'sync': 1, 'opts': {}},
+\ {'type': 'command', 'name': 'BNFQuiz', 'sync': 1, 'opts': {'nargs': '?'}},
+\ {'type': 'command', 'name': 'BNFRename', 'sync': 1, 'opts': {'bang': '', 'nargs': '1'}},
+\ {'type': 'command', 'name': 'BNFShowTree', 'sync': 1, 'opts': {'range': ''}},
+\ {'type': 'command', 'name': 'BNFSimplify', 'sync': 1, 'opts': {'bang': ''}},
+\ {'type': 'command', 'name': 'BNFSortRules', 'sync': 1, 'opts': {'nargs': '?'}},
+\ {'type': 'command', 'name': 'BNFStats', 'sync': 1, 'opts': {}},
+\ {'type': 'command', 'name': 'BNFToggleConceal', 'sync': 1, 'opts': {}},
+\ {'type': 'command', 'name': 'BNFToggleHints', 'sync': 1, 'opts': {}},
+\ {'type': 'command', 'name': 'BNFTrend', 'sync': 1, 'opts': {}},
+\ {'type': 'command', 'name': 'BNFUsageReport', 'sync': 1, 'opts': {}},
+\ {'type': 'command', 'name': 'BNFView', 'sync': 1, 'opts': {}},
+\ {'type': 'function', 'name': 'BNFApplyAction', 'sync': 1, 'opts': {}},
+\ {'type': 'function', 'name': 'BNFCodeActions', 'sync': 1, 'opts': {}},
+\ {'type': 'function', 'name': 'BNFDeriveExpand', 'sync': 1, 'opts': {}},
+\ {'type': 'function', 'name': 'BNFDeriveUndo', 'sync': 1, 'opts': {}},
+\ {'type': 'function', 'name': 'BNFHealthCheck', 'sync': 1, 'opts': {}},
+\ {'type': 'function', 'name': 'BNFNcm2OnComplete', 'sync': 1, 'opts': {}},
+\ {'type': 'function', 'name': 'BNFNcm2OnWarmup', 'sync': 1, 'opts': {}},
+\ {'type': 'function', 'name': 'BNFStatus', 'sync': 1, 'opts': {}},
+\ {'type': 'function', 'name': 'BNFWorkspaceSymbols', 'sync': 1, 'opts': {}},
 \ ])
 
 au User Ncm2Plugin call bnf#init()